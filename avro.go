@@ -0,0 +1,225 @@
+//go:build !tinygo && !nounsafe
+
+package serializer
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/hamba/avro/v2"
+)
+
+// ErrAvroValidation is returned when a value does not match the schema of an
+// AvroSerializer
+var ErrAvroValidation = errors.New("avro: value does not match schema")
+
+// AvroSchemaRegistry looks up Avro schemas by their schema registry ID.
+// *registry.Client from github.com/hamba/avro/v2/registry, a client for the
+// Confluent schema registry, satisfies it.
+type AvroSchemaRegistry interface {
+	GetSchema(ctx context.Context, id int) (avro.Schema, error)
+}
+
+// avroMagicByte starts every payload in the schema registry wire format, followed
+// by the big-endian schema ID and the Avro data
+const avroMagicByte = 0
+
+const avroHeaderSize = 5
+
+// AvroSerializer implements Serializer using Avro binary encoding against a fixed
+// schema. Struct fields are named by their avro tags. Avro data does not describe
+// itself, so values are checked against the schema when they are serialized and
+// failures wrap ErrAvroValidation.
+type AvroSerializer struct {
+	schema avro.Schema
+	opts   options
+
+	// registry is set for serializers using the schema registry wire format
+	registry AvroSchemaRegistry
+	schemaID int
+	// readers caches, by writer schema ID, the schemas that resolve payloads
+	// written with other versions of the schema into this one
+	readers sync.Map // int -> avro.Schema
+}
+
+// NewAvroSerializer creates an Avro serializer for schema, given in its JSON form
+func NewAvroSerializer(schema string, opts ...Option) (*AvroSerializer, error) {
+	parsed, err := avro.Parse(schema)
+	if err != nil {
+		return nil, fmt.Errorf("invalid avro schema: %w", err)
+	}
+	s := &AvroSerializer{schema: parsed, opts: newOptions(opts)}
+	s.opts.bindLogger(Avro)
+	return s, nil
+}
+
+// NewAvroRegistrySerializer creates an Avro serializer for the schema registered
+// under schemaID, using the schema registry wire format that Kafka clients use:
+// a zero byte and the 4-byte schema ID ahead of the Avro data.
+//
+// Payloads written with other schema IDs, such as by producers on an older
+// version of the schema, are decoded by resolving their schema against this one,
+// so fields are matched by name and defaults fill in missing ones. Schemas are
+// fetched from registry once per ID.
+func NewAvroRegistrySerializer(registry AvroSchemaRegistry, schemaID int, opts ...Option) (*AvroSerializer, error) {
+	if registry == nil {
+		return nil, errors.New("schema registry is nil")
+	}
+	schema, err := registry.GetSchema(context.Background(), schemaID)
+	if err != nil {
+		return nil, fmt.Errorf("avro schema %d: %w", schemaID, err)
+	}
+	s := &AvroSerializer{schema: schema, opts: newOptions(opts), registry: registry, schemaID: schemaID}
+	s.opts.bindLogger(Avro)
+	s.readers.Store(schemaID, schema)
+	return s, nil
+}
+
+// Schema returns the schema values are written with
+func (s *AvroSerializer) Schema() avro.Schema {
+	return s.schema
+}
+
+// SchemaID returns the schema registry ID of the schema, or 0 for serializers
+// created with NewAvroSerializer
+func (s *AvroSerializer) SchemaID() int {
+	return s.schemaID
+}
+
+func (s *AvroSerializer) Serialize(v any) ([]byte, error) {
+	if v == nil {
+		return nil, errors.New("cannot serialize nil value")
+	}
+	data, err := avro.Marshal(s.schema, v)
+	if err != nil {
+		err = fmt.Errorf("%w: %w", ErrAvroValidation, err)
+		s.opts.logFailure("serialize", err)
+		return nil, err
+	}
+	if s.registry != nil {
+		framed := make([]byte, avroHeaderSize+len(data))
+		framed[0] = avroMagicByte
+		binary.BigEndian.PutUint32(framed[1:avroHeaderSize], uint32(s.schemaID))
+		copy(framed[avroHeaderSize:], data)
+		data = framed
+	}
+	s.opts.logSize("serialize", len(data))
+	return data, nil
+}
+
+func (s *AvroSerializer) Deserialize(data []byte, v any) error {
+	if data == nil {
+		return errors.New("data is nil")
+	}
+	if v == nil {
+		return errors.New("output parameter is nil")
+	}
+	s.opts.logSize("deserialize", len(data))
+	err := s.opts.checkInputSize(len(data))
+	if err == nil {
+		err = s.decode(data, v)
+	}
+	s.opts.logFailure("deserialize", err)
+	return err
+}
+
+// decode reads data into v, resolving the writer schema of framed payloads
+func (s *AvroSerializer) decode(data []byte, v any) error {
+	schema := s.schema
+	if s.registry != nil {
+		if len(data) < avroHeaderSize || data[0] != avroMagicByte {
+			return errors.New("avro: payload is not in the schema registry wire format")
+		}
+		var err error
+		schema, err = s.readerSchema(int(binary.BigEndian.Uint32(data[1:avroHeaderSize])))
+		if err != nil {
+			return err
+		}
+		data = data[avroHeaderSize:]
+	}
+	if err := avro.Unmarshal(schema, data, v); err != nil {
+		return err
+	}
+	return s.opts.validate(v)
+}
+
+// readerSchema returns the schema that decodes payloads written with the schema
+// registered as id into values of this serializer's schema
+func (s *AvroSerializer) readerSchema(id int) (avro.Schema, error) {
+	if schema, ok := s.readers.Load(id); ok {
+		return schema.(avro.Schema), nil
+	}
+	writer, err := s.registry.GetSchema(context.Background(), id)
+	if err != nil {
+		return nil, fmt.Errorf("avro writer schema %d: %w", id, err)
+	}
+	resolved, err := avro.NewSchemaCompatibility().Resolve(s.schema, writer)
+	if err != nil {
+		return nil, fmt.Errorf("avro writer schema %d is incompatible with schema %d: %w", id, s.schemaID, err)
+	}
+	s.readers.Store(id, resolved)
+	return resolved, nil
+}
+
+// DeserializeString implements StringDeserializer interface
+// Uses unsafe string-to-bytes conversion to avoid allocation; decoded strings and
+// bytes are copied, so nothing aliases data
+func (s *AvroSerializer) DeserializeString(data string, v any) error {
+	if data == "" {
+		return errors.New("data is empty")
+	}
+	return s.Deserialize(stringToReadOnlyBytes(data), v)
+}
+
+func (s *AvroSerializer) SerializeTo(w io.Writer, v any) error {
+	if w == nil {
+		return errors.New("writer is nil")
+	}
+	data, err := s.Serialize(v)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	s.opts.logFailure("serialize_to", err)
+	return err
+}
+
+// DeserializeFrom reads r to EOF and decodes it.
+// Avro data does not carry its length, so r must contain exactly one value.
+func (s *AvroSerializer) DeserializeFrom(r io.Reader, v any) error {
+	if r == nil {
+		return errors.New("reader is nil")
+	}
+	limited, limitErr := s.opts.limitInput(r)
+	data, err := io.ReadAll(limited)
+	if err != nil {
+		err = limitErr(err)
+		s.opts.logFailure("deserialize_from", err)
+		return err
+	}
+	return s.Deserialize(data, v)
+}
+
+// NewStreamEncoder implements StreamingSerializer with a *FrameWriter. Avro
+// values do not carry their length, so each is written with a varint length prefix.
+func (s *AvroSerializer) NewStreamEncoder(w io.Writer) StreamEncoder {
+	return NewFrameWriter(w, s)
+}
+
+// NewStreamDecoder implements StreamingSerializer with a *FrameReader reading
+// values written by NewStreamEncoder
+func (s *AvroSerializer) NewStreamDecoder(r io.Reader) StreamDecoder {
+	return NewFrameReader(r, s)
+}
+
+func (s *AvroSerializer) maxInputSize() int64 {
+	return s.opts.maxInputSize
+}
+
+func (s *AvroSerializer) ContentType() string {
+	return "application/avro"
+}
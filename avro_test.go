@@ -0,0 +1,157 @@
+//go:build !tinygo && !nounsafe
+
+package serializer
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/hamba/avro/v2"
+	"github.com/hamba/avro/v2/registry"
+)
+
+var _ AvroSchemaRegistry = (*registry.Client)(nil)
+
+const (
+	userSchemaV1 = `{"type":"record","name":"User","namespace":"acme","fields":[
+		{"name":"id","type":"string"},
+		{"name":"age","type":"int"}]}`
+	userSchemaV2 = `{"type":"record","name":"User","namespace":"acme","fields":[
+		{"name":"id","type":"string"},
+		{"name":"age","type":"int"},
+		{"name":"email","type":"string","default":""}]}`
+)
+
+type avroUser struct {
+	ID    string `avro:"id"`
+	Age   int    `avro:"age"`
+	Email string `avro:"email"`
+}
+
+// staticAvroRegistry serves schemas from memory and counts lookups
+type staticAvroRegistry struct {
+	schemas map[int]string
+	lookups int
+}
+
+func (r *staticAvroRegistry) GetSchema(_ context.Context, id int) (avro.Schema, error) {
+	r.lookups++
+	schema, ok := r.schemas[id]
+	if !ok {
+		return nil, fmt.Errorf("schema %d not found", id)
+	}
+	return avro.Parse(schema)
+}
+
+func TestAvroSerializer(t *testing.T) {
+	s, err := NewAvroSerializer(userSchemaV2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := avroUser{ID: "u1", Age: 30, Email: "u1@example.com"}
+	data, err := s.Serialize(want)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got avroUser
+	if err := s.Deserialize(data, &got); err != nil || got != want {
+		t.Errorf("got %+v, %v", got, err)
+	}
+
+	got = avroUser{}
+	if err := s.DeserializeString(string(data), &got); err != nil || got != want {
+		t.Errorf("DeserializeString: got %+v, %v", got, err)
+	}
+
+	var buf bytes.Buffer
+	if err := s.SerializeTo(&buf, map[string]any{"id": "u2", "age": 5, "email": ""}); err != nil {
+		t.Fatal(err)
+	}
+	var generic map[string]any
+	if err := s.DeserializeFrom(&buf, &generic); err != nil || generic["id"] != "u2" {
+		t.Errorf("DeserializeFrom: got %v, %v", generic, err)
+	}
+
+	if _, err := NewAvroSerializer(`{"type":"record"}`); err == nil {
+		t.Error("invalid schema was accepted")
+	}
+}
+
+func TestAvroSerializerValidation(t *testing.T) {
+	s, err := NewAvroSerializer(userSchemaV1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	invalid := []any{
+		map[string]any{"id": "u1"},
+		map[string]any{"id": 7, "age": 1},
+		"text",
+	}
+	for _, v := range invalid {
+		if _, err := s.Serialize(v); !errors.Is(err, ErrAvroValidation) {
+			t.Errorf("Serialize(%v): got %v, want ErrAvroValidation", v, err)
+		}
+	}
+	if _, err := s.Serialize(nil); err == nil {
+		t.Error("nil value was accepted")
+	}
+}
+
+func TestAvroRegistrySerializer(t *testing.T) {
+	reg := &staticAvroRegistry{schemas: map[int]string{1: userSchemaV1, 2: userSchemaV2}}
+	v1, err := NewAvroRegistrySerializer(reg, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	v2, err := NewAvroRegistrySerializer(reg, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v2.SchemaID() != 2 {
+		t.Errorf("SchemaID = %d", v2.SchemaID())
+	}
+
+	old, err := v1.Serialize(avroUser{ID: "u1", Age: 30})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(old[:5], []byte{0, 0, 0, 0, 1}) {
+		t.Errorf("unexpected header % x", old[:5])
+	}
+
+	// A payload written with v1 is resolved into v2, with the default filling in
+	lookups := reg.lookups
+	for i := 0; i < 2; i++ {
+		got := avroUser{Email: "stale"}
+		if err := v2.Deserialize(old, &got); err != nil || got != (avroUser{ID: "u1", Age: 30}) {
+			t.Errorf("resolved decode: got %+v, %v", got, err)
+		}
+	}
+	if reg.lookups != lookups+1 {
+		t.Errorf("writer schema fetched %d times, want once", reg.lookups-lookups)
+	}
+
+	current, err := v2.Serialize(avroUser{ID: "u2", Age: 4, Email: "e"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got avroUser
+	if err := v2.Deserialize(current, &got); err != nil || got.Email != "e" {
+		t.Errorf("current decode: got %+v, %v", got, err)
+	}
+
+	if err := v2.Deserialize(current[5:], &got); err == nil {
+		t.Error("unframed payload was accepted")
+	}
+	unknown := append([]byte{0, 0, 0, 0, 9}, current[5:]...)
+	if err := v2.Deserialize(unknown, &got); err == nil {
+		t.Error("unknown schema ID was accepted")
+	}
+	if _, err := NewAvroRegistrySerializer(reg, 9); err == nil {
+		t.Error("unknown schema ID was accepted by the constructor")
+	}
+}
@@ -0,0 +1,240 @@
+package serializer
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsoncodec"
+	"go.mongodb.org/mongo-driver/bson/bsonrw"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+)
+
+// bsonMaxDocumentSize is the largest document DeserializeFrom accepts, matching
+// the MongoDB server limit
+const bsonMaxDocumentSize = 16 << 20
+
+// bsonRegistry decodes documents and arrays held in interfaces as map[string]any
+// and []any, like the other serializers, instead of bson.D and bson.A
+var bsonRegistry = func() *bsoncodec.Registry {
+	r := bson.NewRegistry()
+	r.RegisterTypeMapEntry(bsontype.EmbeddedDocument, reflect.TypeFor[map[string]any]())
+	r.RegisterTypeMapEntry(bsontype.Array, reflect.TypeFor[[]any]())
+	return r
+}()
+
+// BSONSerializer implements Serializer using BSON, the document format of MongoDB.
+// Struct fields are named by their bson tags, falling back to their json tags, and
+// values must encode as documents: structs, maps, bson.D or bson.Raw.
+type BSONSerializer struct {
+	bufferPool *pooledBufferPool
+	opts       options
+}
+
+// NewBSONSerializer creates a new BSON serializer
+func NewBSONSerializer(opts ...Option) Serializer {
+	s := &BSONSerializer{
+		bufferPool: newPooledBufferPool(MAX_BUF_CAP),
+		opts:       newOptions(opts),
+	}
+	s.opts.bindLogger(BSON)
+	s.bufferPool.observe = s.opts.poolObserver("bson_buffer", MAX_BUF_CAP)
+	return s
+}
+
+// encode writes the document for v to w
+func (s *BSONSerializer) encode(w io.Writer, v any) error {
+	if v == nil {
+		return errors.New("cannot serialize nil value")
+	}
+	vw, err := bsonrw.NewBSONValueWriter(w)
+	if err != nil {
+		return err
+	}
+	enc, err := bson.NewEncoder(vw)
+	if err != nil {
+		return err
+	}
+	enc.SetRegistry(bsonRegistry)
+	enc.UseJSONStructTags()
+	if err := enc.Encode(v); err != nil {
+		return fmt.Errorf("bson: cannot encode %T: %w", v, err)
+	}
+	return nil
+}
+
+// decode reads the document in data into v
+func (s *BSONSerializer) decode(data []byte, v any) error {
+	if v == nil {
+		return errors.New("output parameter is nil")
+	}
+	if err := s.opts.checkInputSize(len(data)); err != nil {
+		return err
+	}
+	dec, err := bson.NewDecoder(bsonrw.NewBSONDocumentReader(data))
+	if err != nil {
+		return err
+	}
+	dec.SetRegistry(bsonRegistry)
+	dec.UseJSONStructTags()
+	if err := dec.Decode(v); err != nil {
+		return err
+	}
+	return s.opts.validate(v)
+}
+
+func (s *BSONSerializer) Serialize(v any) ([]byte, error) {
+	buf := s.bufferPool.Get()
+	defer s.bufferPool.Put(buf)
+
+	if err := s.encode(buf, v); err != nil {
+		s.opts.logFailure("serialize", err)
+		return nil, err
+	}
+
+	data := make([]byte, buf.Len())
+	copy(data, buf.Bytes())
+	s.opts.logSize("serialize", len(data))
+	return data, nil
+}
+
+func (s *BSONSerializer) Deserialize(data []byte, v any) error {
+	if data == nil {
+		return errors.New("data is nil")
+	}
+	s.opts.logSize("deserialize", len(data))
+	err := s.decode(data, v)
+	s.opts.logFailure("deserialize", err)
+	return err
+}
+
+// DeserializeString implements StringDeserializer interface
+// Uses unsafe string-to-bytes conversion to avoid allocation. Strings and binary
+// values are copied out of data, but bson.Raw and bson.RawValue targets refer to
+// it and must not be modified.
+func (s *BSONSerializer) DeserializeString(data string, v any) error {
+	if data == "" {
+		return errors.New("data is empty")
+	}
+	s.opts.logSize("deserialize_string", len(data))
+	err := s.decode(stringToReadOnlyBytes(data), v)
+	s.opts.logFailure("deserialize_string", err)
+	return err
+}
+
+// SerializeWithTypeInfo implements TypedSerializer interface. BSON needs no type
+// information to encode, so it is the same as Serialize.
+func (s *BSONSerializer) SerializeWithTypeInfo(v any, typeInfo TypeInfo) ([]byte, error) {
+	data, err := s.Serialize(v)
+	if err != nil {
+		return nil, fmt.Errorf("bson serialization failed for type %s: %w", typeInfo.TypeName, err)
+	}
+	return data, nil
+}
+
+// DeserializeWithTypeInfo implements TypedSerializer interface. It decodes into a
+// new value of typeInfo.Type and returns that value.
+func (s *BSONSerializer) DeserializeWithTypeInfo(data []byte, typeInfo TypeInfo) (any, error) {
+	target, err := newTypedTarget(typeInfo)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.Deserialize(data, target.Interface()); err != nil {
+		return nil, fmt.Errorf("bson deserialization failed for type %s: %w", typeInfo.TypeName, err)
+	}
+	return target.Elem().Interface(), nil
+}
+
+// SerializeTo writes the document for v to w in a single Write
+func (s *BSONSerializer) SerializeTo(w io.Writer, v any) error {
+	if w == nil {
+		return errors.New("writer is nil")
+	}
+	buf := s.bufferPool.Get()
+	defer s.bufferPool.Put(buf)
+
+	err := s.encode(buf, v)
+	if err == nil {
+		_, err = buf.WriteTo(w)
+	}
+	s.opts.logFailure("serialize_to", err)
+	return err
+}
+
+// DeserializeFrom reads one document from r. BSON documents start with their
+// length, so nothing past the document is read and back-to-back documents, as in
+// a mongodump file, can be read with repeated calls. It returns io.EOF when r is
+// already at its end and io.ErrUnexpectedEOF when it ends inside a document.
+func (s *BSONSerializer) DeserializeFrom(r io.Reader, v any) error {
+	if r == nil {
+		return errors.New("reader is nil")
+	}
+	buf := s.bufferPool.Get()
+	defer s.bufferPool.Put(buf)
+
+	err := readBSONDocument(r, buf, s.opts.maxInputSize)
+	if err == nil {
+		s.opts.logSize("deserialize_from", buf.Len())
+		err = s.decode(buf.Bytes(), v)
+	}
+	if err != io.EOF {
+		s.opts.logFailure("deserialize_from", err)
+	}
+	return err
+}
+
+// readBSONDocument reads the next length-prefixed document from r into buf. A
+// document longer than a positive maxSize is rejected before its body is read.
+func readBSONDocument(r io.Reader, buf *bytes.Buffer, maxSize int64) error {
+	var header [4]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return err
+	}
+	n := int(int32(binary.LittleEndian.Uint32(header[:])))
+	// The smallest document is the length and the terminating zero
+	if n < 5 || n > bsonMaxDocumentSize {
+		return fmt.Errorf("bson: invalid document length %d", n)
+	}
+	if maxSize > 0 && int64(n) > maxSize {
+		return inputTooLarge(maxSize)
+	}
+	buf.Grow(n)
+	doc := append(buf.AvailableBuffer(), header[:]...)[:n]
+	if _, err := io.ReadFull(r, doc[len(header):]); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return err
+	}
+	_, err := buf.Write(doc)
+	return err
+}
+
+func (s *BSONSerializer) maxInputSize() int64 {
+	return s.opts.maxInputSize
+}
+
+// PoolStats implements PoolStatsProvider for this serializer's buffer pool
+func (s *BSONSerializer) PoolStats() PoolStats {
+	return s.bufferPool.stats.snapshot()
+}
+
+// NewStreamEncoder implements StreamingSerializer. BSON documents start with
+// their length, so they are written back to back with SerializeTo.
+func (s *BSONSerializer) NewStreamEncoder(w io.Writer) StreamEncoder {
+	return &valueStreamEncoder{s: s, w: w}
+}
+
+// NewStreamDecoder implements StreamingSerializer, reading back-to-back
+// documents with DeserializeFrom
+func (s *BSONSerializer) NewStreamDecoder(r io.Reader) StreamDecoder {
+	return &valueStreamDecoder{s: s, r: newStreamReader(r)}
+}
+
+func (s *BSONSerializer) ContentType() string {
+	return "application/bson"
+}
@@ -0,0 +1,153 @@
+package serializer
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"reflect"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type bsonOrder struct {
+	ID       primitive.ObjectID `bson:"_id"`
+	Customer string             `json:"customer"`
+	Items    []string           `bson:"items"`
+	Total    float64            `bson:"total,omitempty"`
+	Placed   time.Time          `bson:"placed"`
+	Meta     map[string]any     `bson:"meta"`
+}
+
+func TestBSONSerializer(t *testing.T) {
+	s := NewBSONSerializer()
+	if got := s.ContentType(); got != "application/bson" {
+		t.Errorf("ContentType = %q", got)
+	}
+
+	want := bsonOrder{
+		ID:       primitive.NewObjectID(),
+		Customer: "c-1",
+		Items:    []string{"a", "b"},
+		// BSON datetimes have millisecond precision
+		Placed: time.Date(2024, 5, 1, 12, 0, 0, int(5*time.Millisecond), time.UTC),
+		Meta:   map[string]any{"source": "web", "tags": []any{"x"}, "nested": map[string]any{"n": int32(1)}},
+	}
+	data, err := s.Serialize(want)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got bsonOrder
+	if err := s.Deserialize(data, &got); err != nil {
+		t.Fatal(err)
+	}
+	if !got.Placed.Equal(want.Placed) {
+		t.Errorf("time mismatch: %v", got.Placed)
+	}
+	got.Placed = want.Placed
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %#v\nwant %#v", got, want)
+	}
+
+	got = bsonOrder{}
+	if err := s.(StringDeserializer).DeserializeString(string(data), &got); err != nil || got.Customer != "c-1" {
+		t.Errorf("DeserializeString: got %+v, %v", got, err)
+	}
+
+	// Field names come from bson tags, then json tags
+	var generic map[string]any
+	if err := s.Deserialize(data, &generic); err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range []string{"_id", "customer", "items", "placed", "meta"} {
+		if _, ok := generic[key]; !ok {
+			t.Errorf("missing key %q in %v", key, generic)
+		}
+	}
+	if _, ok := generic["total"]; ok {
+		t.Error("omitempty field was written")
+	}
+	if _, ok := generic["meta"].(map[string]any); !ok {
+		t.Errorf("nested document decoded as %T", generic["meta"])
+	}
+	if _, ok := generic["items"].([]any); !ok {
+		t.Errorf("array decoded as %T", generic["items"])
+	}
+
+	typed, err := Unmarshal[*bsonOrder](s, data)
+	if err != nil || typed.ID != want.ID {
+		t.Errorf("Unmarshal: got %+v, %v", typed, err)
+	}
+}
+
+func TestBSONStream(t *testing.T) {
+	s := NewBSONSerializer()
+
+	var buf bytes.Buffer
+	for i := 0; i < 3; i++ {
+		if err := s.SerializeTo(&buf, streamEvent{Seq: i, Kind: "created"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	log := buf.Bytes()
+
+	r := bytes.NewReader(log)
+	for i := 0; i < 3; i++ {
+		var ev streamEvent
+		if err := s.DeserializeFrom(r, &ev); err != nil || ev.Seq != i || ev.Kind != "created" {
+			t.Fatalf("event %d: got %+v, %v", i, ev, err)
+		}
+	}
+	var ev streamEvent
+	if err := s.DeserializeFrom(r, &ev); err != io.EOF {
+		t.Errorf("end of stream: got %v, want io.EOF", err)
+	}
+
+	r = bytes.NewReader(log[:len(log)-1])
+	var err error
+	for err == nil {
+		err = s.DeserializeFrom(r, &ev)
+	}
+	if !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("truncated stream: got %v, want io.ErrUnexpectedEOF", err)
+	}
+
+	if err := s.DeserializeFrom(bytes.NewReader([]byte{0xff, 0xff, 0xff, 0x7f}), &ev); err == nil {
+		t.Error("oversized document length was accepted")
+	}
+}
+
+func TestBSONSerializerErrors(t *testing.T) {
+	s := NewBSONSerializer()
+	if _, err := s.Serialize(nil); err == nil {
+		t.Error("nil value was accepted")
+	}
+	if _, err := s.Serialize(42); err == nil {
+		t.Error("non-document value was accepted")
+	}
+	var v map[string]any
+	if err := s.Deserialize(nil, &v); err == nil {
+		t.Error("nil data was accepted")
+	}
+	if err := s.Deserialize([]byte{5, 0, 0}, &v); err == nil {
+		t.Error("truncated data was accepted")
+	}
+	if err := s.(StringDeserializer).DeserializeString("", &v); err == nil {
+		t.Error("empty string was accepted")
+	}
+}
+
+func TestBSONRegistered(t *testing.T) {
+	s, err := DefaultRegistry.New(BSON)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := s.(*BSONSerializer); !ok {
+		t.Errorf("DefaultRegistry BSON serializer is %T", s)
+	}
+	if format, ok := FormatForPath("dump/orders.bson"); !ok || format != BSON {
+		t.Errorf("FormatForPath = %q, %t", format, ok)
+	}
+}
@@ -0,0 +1,31 @@
+package serializer
+
+// Canonical makes equal values encode to identical bytes, as hashing, signing and
+// deduplication need. Map keys are sorted as with WithSortedKeys, and numbers get
+// one encoding each:
+//
+//   - JSON writes floats in their shortest round-tripping form, as encoding/json
+//     and RFC 8785 do, writes negative zero as 0 and omits the trailing newline.
+//     Float normalization requires the jsoniter backend; encoding/json and the
+//     lite backend format floats the same way apart from negative zero.
+//   - MessagePack writes every integer in its smallest form, negative zero as
+//     zero and every NaN as the same quiet NaN.
+//   - CBOR uses the core deterministic encoding of RFC 8949, with floats in
+//     their shortest exact form.
+//
+// Struct fields keep their declaration order, so both sides must share the
+// struct definitions.
+func Canonical() Option {
+	return func(o *options) {
+		o.canonical = true
+		o.sortMapKeys = true
+		o.jsonOmitNewline = true
+	}
+}
+
+// NewCanonicalJSONSerializer creates a JSON serializer with Canonical output.
+// Options are applied after Canonical, so WithTrailingNewline(true) restores the
+// newline.
+func NewCanonicalJSONSerializer(opts ...Option) Serializer {
+	return NewJSONSerializer(maxBufferSize, append([]Option{Canonical()}, opts...)...)
+}
@@ -0,0 +1,106 @@
+package serializer
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"testing"
+)
+
+type canonicalRecord struct {
+	ID     int64             `json:"id" msgpack:"id"`
+	Score  float64           `json:"score" msgpack:"score"`
+	Counts map[string]int    `json:"counts" msgpack:"counts"`
+	Extra  map[string]any    `json:"extra" msgpack:"extra"`
+	Ratios map[int32]float32 `json:"ratios" msgpack:"ratios"`
+}
+
+// newCanonicalRecord builds equal records whose maps were filled in different orders
+func newCanonicalRecord(reverse bool) canonicalRecord {
+	r := canonicalRecord{ID: 7, Score: 1e-7, Counts: map[string]int{}, Extra: map[string]any{}, Ratios: map[int32]float32{}}
+	for i := 0; i < 16; i++ {
+		j := i
+		if reverse {
+			j = 15 - i
+		}
+		r.Counts[fmt.Sprintf("k%02d", j)] = j
+		r.Extra[fmt.Sprintf("x%02d", j)] = map[string]float64{"b": float64(j), "a": 0.1}
+		r.Ratios[int32(j*100)] = float32(j) / 4
+	}
+	return r
+}
+
+func TestCanonical(t *testing.T) {
+	js := NewCanonicalJSONSerializer()
+	serializers := []struct {
+		name string
+		s    Serializer
+		// negZero is whether negative zero encodes like zero
+		negZero bool
+	}{
+		{"JSON", js, js.(*JSONSerializer).Backend() == JSONBackendJSONIter},
+		{"MsgPack", NewMsgpackSerializer(Canonical()), true},
+		{"CBOR", NewCBORSerializer(Canonical()), false},
+	}
+	for _, tc := range serializers {
+		t.Run(tc.name, func(t *testing.T) {
+			first, err := tc.s.Serialize(newCanonicalRecord(false))
+			if err != nil {
+				t.Fatal(err)
+			}
+			for i := 0; i < 5; i++ {
+				data, err := tc.s.Serialize(newCanonicalRecord(i%2 == 0))
+				if err != nil || !bytes.Equal(data, first) {
+					t.Fatalf("encoding %d differs: %v", i, err)
+				}
+			}
+
+			var got canonicalRecord
+			if err := tc.s.Deserialize(first, &got); err != nil || got.Counts["k03"] != 3 || got.Ratios[500] != 1.25 {
+				t.Errorf("round trip: %+v, %v", got, err)
+			}
+
+			pos, _ := tc.s.Serialize(map[string]float64{"z": 0})
+			neg, _ := tc.s.Serialize(map[string]float64{"z": math.Copysign(0, -1)})
+			if tc.negZero && !bytes.Equal(pos, neg) {
+				t.Errorf("-0 encoded as %x, 0 as %x", neg, pos)
+			}
+		})
+	}
+}
+
+func TestCanonicalJSONFloats(t *testing.T) {
+	s := NewCanonicalJSONSerializer()
+	if s.(*JSONSerializer).Backend() != JSONBackendJSONIter {
+		t.Skip("float normalization needs the jsoniter backend")
+	}
+	data, err := s.Serialize([]any{1e-7, 1e21, 123456.789, float32(0.1), 5.0, math.Copysign(0, -1)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "[1e-7,1e+21,123456.789,0.1,5,0]"; string(data) != want {
+		t.Errorf("Serialize = %s, want %s", data, want)
+	}
+	if _, err := s.Serialize(math.NaN()); err == nil {
+		t.Error("NaN was accepted")
+	}
+
+	// The newline can be turned back on
+	data, _ = NewCanonicalJSONSerializer(WithTrailingNewline(true)).Serialize(1)
+	if string(data) != "1\n" {
+		t.Errorf("WithTrailingNewline(true) = %q", data)
+	}
+}
+
+func TestCanonicalMsgpackNumbers(t *testing.T) {
+	s := NewMsgpackSerializer(Canonical())
+	small, _ := s.Serialize(int64(5))
+	if !bytes.Equal(small, []byte{5}) {
+		t.Errorf("int64(5) = %x, want the positive fixint", small)
+	}
+	a, _ := s.Serialize(math.NaN())
+	b, _ := s.Serialize(math.Float64frombits(0x7ff0000000000001))
+	if !bytes.Equal(a, b) {
+		t.Errorf("NaNs encoded as %x and %x", a, b)
+	}
+}
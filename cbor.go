@@ -0,0 +1,335 @@
+package serializer
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+
+	"github.com/fxamacker/cbor/v2"
+)
+
+// cborEncOptions write times as tagged RFC 3339 strings with nanoseconds, so they
+// keep their precision and zone offset and decode as time.Time into interfaces
+var cborEncOptions = cbor.EncOptions{
+	Time:    cbor.TimeRFC3339Nano,
+	TimeTag: cbor.EncTagRequired,
+}
+
+var (
+	cborEncMode       = newCBOREncMode(cbor.SortNone)
+	cborSortedEncMode = newCBOREncMode(cbor.SortBytewiseLexical)
+
+	// cborCanonicalEncMode adds the float rules of core deterministic encoding
+	cborCanonicalEncMode = func() cbor.EncMode {
+		opts := cborEncOptions
+		opts.Sort = cbor.SortCoreDeterministic
+		opts.ShortestFloat = cbor.ShortestFloat16
+		opts.NaNConvert = cbor.NaNConvert7e00
+		opts.InfConvert = cbor.InfConvertFloat16
+		em, err := opts.EncMode()
+		if err != nil {
+			panic(err)
+		}
+		return em
+	}()
+)
+
+// newCBOREncMode returns the encoding mode writing map keys in sort order
+func newCBOREncMode(sort cbor.SortMode) cbor.EncMode {
+	opts := cborEncOptions
+	opts.Sort = sort
+	em, err := opts.EncMode()
+	if err != nil {
+		panic(err)
+	}
+	return em
+}
+
+// cborDecMode decodes maps held in interfaces as map[string]any, like the JSON and
+// MessagePack serializers do
+var cborDecMode = newCBORDecMode(0)
+
+// Levels the CBOR decoder can be limited to
+const (
+	cborMinNestedLevels = 4
+	cborMaxNestedLevels = 65535
+)
+
+// newCBORDecMode returns the decoding mode allowing maxDepth levels of nesting,
+// or the library's default of 32 for maxDepth <= 0
+func newCBORDecMode(maxDepth int) cbor.DecMode {
+	opts := cbor.DecOptions{DefaultMapType: reflect.TypeFor[map[string]any]()}
+	if maxDepth > 0 {
+		opts.MaxNestedLevels = cborNestedLevels(maxDepth)
+	}
+	dm, err := opts.DecMode()
+	if err != nil {
+		panic(err)
+	}
+	return dm
+}
+
+// cborNestedLevels returns the limit the decoder applies for WithMaxDepth(maxDepth)
+func cborNestedLevels(maxDepth int) int {
+	return min(max(maxDepth, cborMinNestedLevels), cborMaxNestedLevels)
+}
+
+// CBORSerializer implements Serializer using CBOR (RFC 8949) encoding.
+// Struct fields are named by their cbor tags, falling back to their json tags.
+type CBORSerializer struct {
+	opts    options
+	decMode cbor.DecMode
+}
+
+// NewCBORSerializer creates a new CBOR serializer. Maps decoded into interfaces
+// are map[string]any, so payloads with non-string map keys must be decoded into
+// typed maps.
+func NewCBORSerializer(opts ...Option) Serializer {
+	s := &CBORSerializer{opts: newOptions(opts), decMode: cborDecMode}
+	s.opts.bindLogger(CBOR)
+	if s.opts.maxDepth > 0 {
+		s.decMode = newCBORDecMode(s.opts.maxDepth)
+	}
+	return s
+}
+
+func (s *CBORSerializer) Serialize(v any) ([]byte, error) {
+	if v == nil {
+		return nil, errors.New("cannot serialize nil value")
+	}
+	data, err := s.marshal(v)
+	if err != nil {
+		s.opts.logFailure("serialize", err)
+		return nil, err
+	}
+	s.opts.logSize("serialize", len(data))
+	return data, nil
+}
+
+// SerializeWithOptions implements OptionsSerializer
+func (s *CBORSerializer) SerializeWithOptions(v any, opts ...Option) ([]byte, error) {
+	if len(opts) == 0 {
+		return s.Serialize(v)
+	}
+	c := &CBORSerializer{opts: s.opts.withCallOptions(opts, CBOR), decMode: s.decMode}
+	return c.Serialize(v)
+}
+
+// encMode returns the encoding mode for this serializer's settings
+func (s *CBORSerializer) encMode() cbor.EncMode {
+	if s.opts.canonical {
+		return cborCanonicalEncMode
+	}
+	if s.opts.sortMapKeys {
+		return cborSortedEncMode
+	}
+	return cborEncMode
+}
+
+// marshal encodes v after checking its depth
+func (s *CBORSerializer) marshal(v any) ([]byte, error) {
+	if err := checkDepth(v, s.opts.maxDepth); err != nil {
+		return nil, err
+	}
+	return s.encMode().Marshal(v)
+}
+
+// encode writes v to enc after checking its depth
+func (s *CBORSerializer) encode(enc *cbor.Encoder, v any) error {
+	if err := checkDepth(v, s.opts.maxDepth); err != nil {
+		return err
+	}
+	return enc.Encode(v)
+}
+
+// unmarshal decodes data into v and validates it
+func (s *CBORSerializer) unmarshal(data []byte, v any) error {
+	if err := s.opts.checkInputSize(len(data)); err != nil {
+		return err
+	}
+	if err := s.decMode.Unmarshal(data, v); err != nil {
+		return s.decodeErr(err)
+	}
+	return s.opts.validate(v)
+}
+
+// decode reads the next value from dec into v and validates it
+func (s *CBORSerializer) decode(dec *cbor.Decoder, v any) error {
+	if err := dec.Decode(v); err != nil {
+		return s.decodeErr(err)
+	}
+	return s.opts.validate(v)
+}
+
+// decodeErr reports running into the WithMaxDepth limit as a *MaxDepthError
+func (s *CBORSerializer) decodeErr(err error) error {
+	var nested *cbor.MaxNestedLevelError
+	if s.opts.maxDepth > 0 && errors.As(err, &nested) {
+		return &MaxDepthError{Limit: cborNestedLevels(s.opts.maxDepth), Offset: -1}
+	}
+	return err
+}
+
+func (s *CBORSerializer) Deserialize(data []byte, v any) error {
+	if data == nil {
+		return errors.New("data is nil")
+	}
+	if v == nil {
+		return errors.New("output parameter is nil")
+	}
+	s.opts.logSize("deserialize", len(data))
+	err := s.unmarshal(data, v)
+	s.opts.logFailure("deserialize", err)
+	return err
+}
+
+// DeserializeString implements StringDeserializer interface
+// Uses unsafe string-to-bytes conversion to avoid allocation
+func (s *CBORSerializer) DeserializeString(data string, v any) error {
+	if data == "" {
+		return errors.New("data is empty")
+	}
+	if v == nil {
+		return errors.New("output parameter is nil")
+	}
+	s.opts.logSize("deserialize_string", len(data))
+	// Decoded strings and byte strings are copied, so nothing aliases data
+	err := s.unmarshal(stringToReadOnlyBytes(data), v)
+	s.opts.logFailure("deserialize_string", err)
+	return err
+}
+
+// SerializeWithTypeInfo implements TypedSerializer interface. CBOR needs no type
+// information to encode, so it is the same as Serialize.
+func (s *CBORSerializer) SerializeWithTypeInfo(v any, typeInfo TypeInfo) ([]byte, error) {
+	data, err := s.Serialize(v)
+	if err != nil {
+		return nil, fmt.Errorf("cbor serialization failed for type %s: %w", typeInfo.TypeName, err)
+	}
+	return data, nil
+}
+
+// DeserializeWithTypeInfo implements TypedSerializer interface. It decodes into a
+// new value of typeInfo.Type and returns that value.
+func (s *CBORSerializer) DeserializeWithTypeInfo(data []byte, typeInfo TypeInfo) (any, error) {
+	target, err := newTypedTarget(typeInfo)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.Deserialize(data, target.Interface()); err != nil {
+		return nil, fmt.Errorf("cbor deserialization failed for type %s: %w", typeInfo.TypeName, err)
+	}
+	return target.Elem().Interface(), nil
+}
+
+func (s *CBORSerializer) SerializeTo(w io.Writer, v any) error {
+	if w == nil {
+		return errors.New("writer is nil")
+	}
+	if v == nil {
+		return errors.New("cannot serialize nil value")
+	}
+	err := s.encode(s.encMode().NewEncoder(w), v)
+	s.opts.logFailure("serialize_to", err)
+	return err
+}
+
+// DeserializeFrom reads one value from r. The decoder reads ahead of the value, so
+// use a CBORStreamDecoder to read several values from one stream.
+func (s *CBORSerializer) DeserializeFrom(r io.Reader, v any) error {
+	if r == nil {
+		return errors.New("reader is nil")
+	}
+	if v == nil {
+		return errors.New("output parameter is nil")
+	}
+	limited, limitErr := s.opts.limitInput(r)
+	err := limitErr(s.decode(s.decMode.NewDecoder(limited), v))
+	s.opts.logFailure("deserialize_from", err)
+	return err
+}
+
+func (s *CBORSerializer) maxInputSize() int64 {
+	return s.opts.maxInputSize
+}
+
+func (s *CBORSerializer) ContentType() string {
+	return "application/cbor"
+}
+
+var (
+	_ StreamingSerializer = (*CBORSerializer)(nil)
+	_ OptionsSerializer   = (*CBORSerializer)(nil)
+	_ StreamEncoder       = (*CBORStreamEncoder)(nil)
+	_ StreamDecoder       = (*CBORStreamDecoder)(nil)
+)
+
+// CBORStreamEncoder writes back-to-back CBOR values to one stream, as in a CBOR
+// sequence (RFC 8742). A CBORStreamEncoder is not safe for concurrent use.
+type CBORStreamEncoder struct {
+	s   *CBORSerializer
+	enc *cbor.Encoder
+}
+
+// NewStreamEncoder implements StreamingSerializer with a *CBORStreamEncoder
+// writing to w with this serializer's settings
+func (s *CBORSerializer) NewStreamEncoder(w io.Writer) StreamEncoder {
+	return &CBORStreamEncoder{s: s, enc: s.encMode().NewEncoder(w)}
+}
+
+// Encode writes v to the stream
+func (e *CBORStreamEncoder) Encode(v any) error {
+	if v == nil {
+		return errors.New("cannot serialize nil value")
+	}
+	err := e.s.encode(e.enc, v)
+	e.s.opts.logFailure("stream_encode", err)
+	return err
+}
+
+// Close implements StreamEncoder. Values are not buffered, so there is nothing to flush.
+func (e *CBORStreamEncoder) Close() error {
+	return nil
+}
+
+// CBORStreamDecoder reads back-to-back CBOR values from one stream with a single
+// decoder. It reads ahead of the value being decoded, so nothing else should read
+// from the stream. A CBORStreamDecoder is not safe for concurrent use.
+type CBORStreamDecoder struct {
+	s   *CBORSerializer
+	r   streamReader
+	dec *cbor.Decoder
+}
+
+// NewStreamDecoder implements StreamingSerializer with a *CBORStreamDecoder
+// reading from r with this serializer's settings
+func (s *CBORSerializer) NewStreamDecoder(r io.Reader) StreamDecoder {
+	sr := newStreamReader(r)
+	return &CBORStreamDecoder{s: s, r: sr, dec: s.decMode.NewDecoder(sr)}
+}
+
+// Decode reads the next value into v. It returns io.EOF when the stream ends
+// cleanly between values and io.ErrUnexpectedEOF when it ends in the middle of one.
+func (d *CBORStreamDecoder) Decode(v any) error {
+	err := d.s.decode(d.dec, v)
+	if err != io.EOF {
+		d.s.opts.logFailure("stream_decode", err)
+	}
+	return err
+}
+
+// More implements StreamDecoder
+func (d *CBORStreamDecoder) More() bool {
+	// The decoder may already hold the start of the next value
+	if buffered, ok := d.dec.Buffered().(*bytes.Reader); ok && buffered.Len() > 0 {
+		return true
+	}
+	return peekStream(d.r) == nil
+}
+
+// Close implements StreamDecoder
+func (d *CBORStreamDecoder) Close() error {
+	return nil
+}
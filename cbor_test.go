@@ -0,0 +1,154 @@
+package serializer
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type cborReading struct {
+	Sensor string            `cbor:"sensor"`
+	Value  float64           `json:"value"`
+	At     time.Time         `cbor:"at"`
+	Raw    []byte            `cbor:"raw"`
+	Labels map[string]string `cbor:"labels,omitempty"`
+}
+
+func TestCBORSerializer(t *testing.T) {
+	s := NewCBORSerializer()
+	if got := s.ContentType(); got != "application/cbor" {
+		t.Errorf("ContentType = %q", got)
+	}
+
+	want := cborReading{
+		Sensor: "t1",
+		Value:  21.5,
+		At:     time.Date(2024, 5, 1, 12, 0, 0, 123456789, time.FixedZone("", 2*3600)),
+		Raw:    []byte{0, 1, 2},
+	}
+	data, err := s.Serialize(want)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got cborReading
+	if err := s.Deserialize(data, &got); err != nil {
+		t.Fatal(err)
+	}
+	if !got.At.Equal(want.At) {
+		t.Errorf("time mismatch: %v", got.At)
+	}
+	got.At = want.At
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+
+	got = cborReading{}
+	if err := s.(StringDeserializer).DeserializeString(string(data), &got); err != nil || got.Sensor != "t1" {
+		t.Errorf("DeserializeString: got %+v, %v", got, err)
+	}
+
+	// Generic decoding matches the other formats
+	var generic any
+	if err := s.Deserialize(data, &generic); err != nil {
+		t.Fatal(err)
+	}
+	m, ok := generic.(map[string]any)
+	if !ok {
+		t.Fatalf("decoded %T, want map[string]any", generic)
+	}
+	if _, ok := m["at"].(time.Time); !ok {
+		t.Errorf("time decoded as %T", m["at"])
+	}
+	if m["value"] != 21.5 {
+		t.Errorf("json tag not used: %v", m)
+	}
+
+	typed, err := Unmarshal[*cborReading](s, data)
+	if err != nil || typed.Sensor != "t1" {
+		t.Errorf("Unmarshal: got %+v, %v", typed, err)
+	}
+}
+
+func TestCBORSerializerErrors(t *testing.T) {
+	s := NewCBORSerializer()
+	if _, err := s.Serialize(nil); err == nil {
+		t.Error("nil value was accepted")
+	}
+	var v map[string]any
+	if err := s.Deserialize(nil, &v); err == nil {
+		t.Error("nil data was accepted")
+	}
+	if err := s.Deserialize([]byte{0xa1, 0x61}, &v); err == nil {
+		t.Error("truncated data was accepted")
+	}
+	data, _ := s.Serialize(map[string]int{"a": 1})
+	if err := s.Deserialize(append(data, 0x01), &v); err == nil {
+		t.Error("trailing data was accepted")
+	}
+	if err := s.(StringDeserializer).DeserializeString("", &v); err == nil {
+		t.Error("empty string was accepted")
+	}
+}
+
+func TestCBORStream(t *testing.T) {
+	s := NewCBORSerializer().(*CBORSerializer)
+
+	var buf bytes.Buffer
+	enc := s.NewStreamEncoder(&buf)
+	for i := 0; i < 3; i++ {
+		if err := enc.Encode(streamEvent{Seq: i, Kind: "created"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := enc.Encode(nil); err == nil {
+		t.Error("nil value was accepted")
+	}
+	log := buf.Bytes()
+
+	dec := s.NewStreamDecoder(bytes.NewReader(log))
+	for i := 0; i < 3; i++ {
+		var ev streamEvent
+		if err := dec.Decode(&ev); err != nil || ev.Seq != i {
+			t.Fatalf("event %d: got %+v, %v", i, ev, err)
+		}
+	}
+	var ev streamEvent
+	if err := dec.Decode(&ev); err != io.EOF {
+		t.Errorf("end of stream: got %v, want io.EOF", err)
+	}
+
+	dec = s.NewStreamDecoder(bytes.NewReader(log[:len(log)-1]))
+	var err error
+	for err == nil {
+		err = dec.Decode(&ev)
+	}
+	if !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("truncated stream: got %v, want io.ErrUnexpectedEOF", err)
+	}
+
+	// SerializeTo and DeserializeFrom handle single values
+	buf.Reset()
+	if err := s.SerializeTo(&buf, streamEvent{Seq: 7}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.DeserializeFrom(&buf, &ev); err != nil || ev.Seq != 7 {
+		t.Errorf("DeserializeFrom: got %+v, %v", ev, err)
+	}
+}
+
+func TestCBORRegistered(t *testing.T) {
+	s, err := DefaultRegistry.New(CBOR)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := s.(*CBORSerializer); !ok {
+		t.Errorf("DefaultRegistry CBOR serializer is %T", s)
+	}
+	if format, ok := FormatForPath("readings.CBOR"); !ok || format != CBOR {
+		t.Errorf("FormatForPath = %q, %t", format, ok)
+	}
+}
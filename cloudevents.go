@@ -0,0 +1,270 @@
+package serializer
+
+import (
+	"bytes"
+	"encoding/base64"
+	stdjson "encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/MichaelAJay/go-serializer/internal/contenttype"
+)
+
+const (
+	// CloudEventsSpecVersion is the CloudEvents specification version produced by CloudEventCodec
+	CloudEventsSpecVersion = "1.0"
+
+	// CloudEventsContentType is the content type of a structured-mode JSON CloudEvent
+	CloudEventsContentType = "application/cloudevents+json"
+
+	cloudEventHeaderPrefix = "Ce-"
+)
+
+// CloudEvent holds the context attributes and serialized data of a CloudEvent.
+// Data contains the payload exactly as produced by the codec's serializer.
+type CloudEvent struct {
+	SpecVersion     string
+	ID              string
+	Source          string
+	Type            string
+	Subject         string
+	DataSchema      string
+	Time            time.Time
+	DataContentType string
+	Data            []byte
+
+	// Extensions holds extension context attributes keyed by their lowercase name
+	Extensions map[string]string
+}
+
+// Validate checks that the REQUIRED context attributes are present
+func (e *CloudEvent) Validate() error {
+	var missing []string
+	if e.SpecVersion == "" {
+		missing = append(missing, "specversion")
+	}
+	if e.ID == "" {
+		missing = append(missing, "id")
+	}
+	if e.Source == "" {
+		missing = append(missing, "source")
+	}
+	if e.Type == "" {
+		missing = append(missing, "type")
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("cloudevent missing required attributes: %s", strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// CloudEventCodec wraps payloads produced by a serializer in CloudEvents,
+// setting datacontenttype from the serializer's ContentType.
+type CloudEventCodec struct {
+	s Serializer
+}
+
+// NewCloudEventCodec creates a codec whose event data is serialized with s
+func NewCloudEventCodec(s Serializer) *CloudEventCodec {
+	return &CloudEventCodec{s: s}
+}
+
+// NewEvent serializes v and returns an event carrying it as data
+func (c *CloudEventCodec) NewEvent(id, source, eventType string, v any) (*CloudEvent, error) {
+	data, err := c.s.Serialize(v)
+	if err != nil {
+		return nil, err
+	}
+	return &CloudEvent{
+		SpecVersion:     CloudEventsSpecVersion,
+		ID:              id,
+		Source:          source,
+		Type:            eventType,
+		Time:            time.Now().UTC(),
+		DataContentType: c.s.ContentType(),
+		Data:            data,
+	}, nil
+}
+
+// DecodeData deserializes the event data into v.
+// It fails if the event's datacontenttype does not match the codec's serializer.
+func (c *CloudEventCodec) DecodeData(e *CloudEvent, v any) error {
+	if e == nil {
+		return errors.New("event is nil")
+	}
+	if e.DataContentType != "" && contenttype.MediaType(e.DataContentType) != contenttype.MediaType(c.s.ContentType()) {
+		return fmt.Errorf("cloudevent datacontenttype %q does not match serializer content type %q",
+			e.DataContentType, c.s.ContentType())
+	}
+	return c.s.Deserialize(e.Data, v)
+}
+
+// EncodeStructured renders the event in structured mode using the JSON event format.
+// JSON data, including data without a datacontenttype, which the spec treats as
+// application/json, is embedded as a JSON value; any other data is carried in
+// data_base64.
+func (c *CloudEventCodec) EncodeStructured(e *CloudEvent) ([]byte, error) {
+	if err := e.Validate(); err != nil {
+		return nil, err
+	}
+
+	envelope := make(map[string]any, 8+len(e.Extensions))
+	for name, value := range e.Extensions {
+		envelope[name] = value
+	}
+	envelope["specversion"] = e.SpecVersion
+	envelope["id"] = e.ID
+	envelope["source"] = e.Source
+	envelope["type"] = e.Type
+	if e.Subject != "" {
+		envelope["subject"] = e.Subject
+	}
+	if e.DataSchema != "" {
+		envelope["dataschema"] = e.DataSchema
+	}
+	if !e.Time.IsZero() {
+		envelope["time"] = e.Time.Format(time.RFC3339Nano)
+	}
+	if e.DataContentType != "" {
+		envelope["datacontenttype"] = e.DataContentType
+	}
+	if e.Data != nil {
+		if e.DataContentType == "" || isJSONMediaType(e.DataContentType) {
+			envelope["data"] = stdjson.RawMessage(bytes.TrimSpace(e.Data))
+		} else {
+			envelope["data_base64"] = base64.StdEncoding.EncodeToString(e.Data)
+		}
+	}
+	return json.Marshal(envelope)
+}
+
+// DecodeStructured parses a structured-mode JSON CloudEvent
+func (c *CloudEventCodec) DecodeStructured(data []byte) (*CloudEvent, error) {
+	var envelope map[string]stdjson.RawMessage
+	if err := json.Unmarshal(data, &envelope); err != nil {
+		return nil, fmt.Errorf("invalid structured cloudevent: %w", err)
+	}
+
+	e := &CloudEvent{}
+	for name, raw := range envelope {
+		switch name {
+		case "data":
+			e.Data = []byte(raw)
+			continue
+		case "data_base64":
+			var encoded string
+			if err := json.Unmarshal(raw, &encoded); err != nil {
+				return nil, fmt.Errorf("invalid data_base64: %w", err)
+			}
+			decoded, err := base64.StdEncoding.DecodeString(encoded)
+			if err != nil {
+				return nil, fmt.Errorf("invalid data_base64: %w", err)
+			}
+			e.Data = decoded
+			continue
+		}
+
+		var value string
+		if err := json.Unmarshal(raw, &value); err != nil {
+			// Extension attributes may be booleans or numbers; keep their JSON text
+			value = string(raw)
+		}
+		if err := e.setAttribute(name, value); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := e.Validate(); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// BinaryHeaders returns the HTTP headers carrying the event's context attributes in binary mode.
+// The event data is sent unchanged as the message body.
+func (e *CloudEvent) BinaryHeaders() http.Header {
+	h := make(http.Header)
+	h.Set(cloudEventHeaderPrefix+"Specversion", e.SpecVersion)
+	h.Set(cloudEventHeaderPrefix+"Id", e.ID)
+	h.Set(cloudEventHeaderPrefix+"Source", e.Source)
+	h.Set(cloudEventHeaderPrefix+"Type", e.Type)
+	if e.Subject != "" {
+		h.Set(cloudEventHeaderPrefix+"Subject", e.Subject)
+	}
+	if e.DataSchema != "" {
+		h.Set(cloudEventHeaderPrefix+"Dataschema", e.DataSchema)
+	}
+	if !e.Time.IsZero() {
+		h.Set(cloudEventHeaderPrefix+"Time", e.Time.Format(time.RFC3339Nano))
+	}
+	for name, value := range e.Extensions {
+		h.Set(cloudEventHeaderPrefix+name, value)
+	}
+	if e.DataContentType != "" {
+		h.Set("Content-Type", e.DataContentType)
+	}
+	return h
+}
+
+// CloudEventFromBinary reconstructs a binary-mode event from HTTP headers and body
+func CloudEventFromBinary(h http.Header, body []byte) (*CloudEvent, error) {
+	e := &CloudEvent{
+		DataContentType: h.Get("Content-Type"),
+		Data:            body,
+	}
+	for key, values := range h {
+		if len(values) == 0 || len(key) <= len(cloudEventHeaderPrefix) ||
+			!strings.EqualFold(key[:len(cloudEventHeaderPrefix)], cloudEventHeaderPrefix) {
+			continue
+		}
+		name := strings.ToLower(key[len(cloudEventHeaderPrefix):])
+		if err := e.setAttribute(name, values[0]); err != nil {
+			return nil, err
+		}
+	}
+	if err := e.Validate(); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// setAttribute assigns a context attribute by its CloudEvents name
+func (e *CloudEvent) setAttribute(name, value string) error {
+	switch name {
+	case "specversion":
+		e.SpecVersion = value
+	case "id":
+		e.ID = value
+	case "source":
+		e.Source = value
+	case "type":
+		e.Type = value
+	case "subject":
+		e.Subject = value
+	case "dataschema":
+		e.DataSchema = value
+	case "datacontenttype":
+		e.DataContentType = value
+	case "time":
+		t, err := time.Parse(time.RFC3339Nano, value)
+		if err != nil {
+			return fmt.Errorf("invalid cloudevent time %q: %w", value, err)
+		}
+		e.Time = t
+	default:
+		if e.Extensions == nil {
+			e.Extensions = make(map[string]string)
+		}
+		e.Extensions[name] = value
+	}
+	return nil
+}
+
+// isJSONMediaType reports whether a content type denotes JSON data
+func isJSONMediaType(contentType string) bool {
+	mediaType := contenttype.MediaType(contentType)
+	return mediaType == "application/json" || mediaType == "text/json" || strings.HasSuffix(mediaType, "+json")
+}
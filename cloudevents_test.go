@@ -0,0 +1,136 @@
+package serializer
+
+import (
+	"strings"
+	"testing"
+)
+
+type orderCreated struct {
+	OrderID string  `json:"order_id" msgpack:"order_id"`
+	Total   float64 `json:"total" msgpack:"total"`
+}
+
+func TestCloudEventStructuredRoundTrip(t *testing.T) {
+	serializers := []Serializer{
+		NewJSONSerializer(maxBufferSize),
+		NewMsgpackSerializer(),
+	}
+
+	for _, s := range serializers {
+		t.Run(s.ContentType(), func(t *testing.T) {
+			codec := NewCloudEventCodec(s)
+			event, err := codec.NewEvent("evt-1", "/orders", "com.example.order.created", orderCreated{OrderID: "A1", Total: 9.5})
+			if err != nil {
+				t.Fatalf("NewEvent failed: %v", err)
+			}
+			event.Extensions = map[string]string{"traceparent": "00-abc-def-01"}
+
+			data, err := codec.EncodeStructured(event)
+			if err != nil {
+				t.Fatalf("EncodeStructured failed: %v", err)
+			}
+			if isJSONMediaType(s.ContentType()) != strings.Contains(string(data), `"data":{`) {
+				t.Errorf("unexpected data encoding in envelope: %s", data)
+			}
+
+			decoded, err := codec.DecodeStructured(data)
+			if err != nil {
+				t.Fatalf("DecodeStructured failed: %v", err)
+			}
+			if decoded.DataContentType != s.ContentType() {
+				t.Errorf("expected datacontenttype %q, got %q", s.ContentType(), decoded.DataContentType)
+			}
+			if decoded.Extensions["traceparent"] != "00-abc-def-01" {
+				t.Errorf("extension not preserved: %v", decoded.Extensions)
+			}
+			if !decoded.Time.Equal(event.Time) {
+				t.Errorf("time mismatch: got %v, want %v", decoded.Time, event.Time)
+			}
+
+			var payload orderCreated
+			if err := codec.DecodeData(decoded, &payload); err != nil {
+				t.Fatalf("DecodeData failed: %v", err)
+			}
+			if payload.OrderID != "A1" || payload.Total != 9.5 {
+				t.Errorf("payload mismatch: %+v", payload)
+			}
+		})
+	}
+}
+
+func TestCloudEventBinaryRoundTrip(t *testing.T) {
+	codec := NewCloudEventCodec(NewMsgpackSerializer())
+	event, err := codec.NewEvent("evt-2", "/orders", "com.example.order.created", orderCreated{OrderID: "B2"})
+	if err != nil {
+		t.Fatalf("NewEvent failed: %v", err)
+	}
+	event.Subject = "B2"
+
+	headers := event.BinaryHeaders()
+	if headers.Get("Content-Type") != "application/x-msgpack" {
+		t.Errorf("unexpected Content-Type header %q", headers.Get("Content-Type"))
+	}
+
+	decoded, err := CloudEventFromBinary(headers, event.Data)
+	if err != nil {
+		t.Fatalf("CloudEventFromBinary failed: %v", err)
+	}
+	if decoded.ID != "evt-2" || decoded.Subject != "B2" || decoded.SpecVersion != CloudEventsSpecVersion {
+		t.Errorf("attributes not preserved: %+v", decoded)
+	}
+
+	var payload orderCreated
+	if err := codec.DecodeData(decoded, &payload); err != nil {
+		t.Fatalf("DecodeData failed: %v", err)
+	}
+	if payload.OrderID != "B2" {
+		t.Errorf("payload mismatch: %+v", payload)
+	}
+}
+
+func TestCloudEventStructuredWithoutContentType(t *testing.T) {
+	codec := NewCloudEventCodec(NewJSONSerializer(maxBufferSize))
+	event := &CloudEvent{
+		SpecVersion: CloudEventsSpecVersion,
+		ID:          "evt-3",
+		Source:      "/orders",
+		Type:        "com.example.order.created",
+		Data:        []byte(`{"order_id":"C3"}`),
+	}
+
+	data, err := codec.EncodeStructured(event)
+	if err != nil {
+		t.Fatalf("EncodeStructured failed: %v", err)
+	}
+	if !strings.Contains(string(data), `"data":{"order_id":"C3"}`) || strings.Contains(string(data), "data_base64") {
+		t.Errorf("expected data without datacontenttype to be inlined as JSON: %s", data)
+	}
+
+	decoded, err := codec.DecodeStructured(data)
+	if err != nil {
+		t.Fatalf("DecodeStructured failed: %v", err)
+	}
+	var payload orderCreated
+	if err := codec.DecodeData(decoded, &payload); err != nil {
+		t.Fatalf("DecodeData failed: %v", err)
+	}
+	if payload.OrderID != "C3" {
+		t.Errorf("payload mismatch: %+v", payload)
+	}
+}
+
+func TestCloudEventValidation(t *testing.T) {
+	codec := NewCloudEventCodec(NewJSONSerializer(maxBufferSize))
+	if _, err := codec.EncodeStructured(&CloudEvent{ID: "x"}); err == nil {
+		t.Error("expected error for event missing required attributes")
+	}
+	if _, err := codec.DecodeStructured([]byte(`{"id":"x","source":"/s"}`)); err == nil {
+		t.Error("expected error decoding event without specversion and type")
+	}
+
+	event := &CloudEvent{DataContentType: "application/x-msgpack", Data: []byte{0x80}}
+	var v map[string]any
+	if err := codec.DecodeData(event, &v); err == nil {
+		t.Error("expected error for mismatched datacontenttype")
+	}
+}
@@ -0,0 +1,242 @@
+package main
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"testing"
+	"text/tabwriter"
+
+	"github.com/MichaelAJay/go-serializer"
+)
+
+// config holds what to benchmark
+type config struct {
+	formats     []serializer.Format
+	compressors []string
+	payload     any
+}
+
+func newConfig(formats, compress, benchtime string) (*config, error) {
+	cfg := &config{}
+	for _, name := range splitList(formats) {
+		format := serializer.Format(name)
+		if _, ok := serializer.DefaultRegistry.Get(format); !ok {
+			return nil, usageError{fmt.Sprintf("unknown format %q", name)}
+		}
+		cfg.formats = append(cfg.formats, format)
+	}
+	if len(cfg.formats) == 0 {
+		cfg.formats = serializer.DefaultRegistry.Formats()
+	}
+
+	cfg.compressors = splitList(compress)
+	if len(cfg.compressors) == 0 {
+		cfg.compressors = []string{"none"}
+	}
+	for _, name := range cfg.compressors {
+		if _, err := newCompressor(name); err != nil {
+			return nil, err
+		}
+	}
+
+	// testing.Benchmark reads its duration from the test.benchtime flag
+	f := flag.Lookup("test.benchtime")
+	if f == nil {
+		return nil, fmt.Errorf("testing flags are not registered")
+	}
+	if err := f.Value.Set(benchtime); err != nil {
+		return nil, usageError{fmt.Sprintf("invalid --benchtime %q: %v", benchtime, err)}
+	}
+	return cfg, nil
+}
+
+// loadPayload reads the sample payload and decodes it into a generic value
+func (c *config) loadPayload(path string, format serializer.Format) error {
+	if format == "" {
+		var ok bool
+		if format, ok = serializer.FormatForPath(path); !ok {
+			return usageError{fmt.Sprintf("cannot infer format for %q; pass --from", path)}
+		}
+	}
+	s, err := serializer.DefaultRegistry.New(format)
+	if err != nil {
+		return usageError{err.Error()}
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	if err := s.Deserialize(data, &c.payload); err != nil {
+		return fmt.Errorf("decode %s: %w", path, err)
+	}
+	if c.payload == nil {
+		return fmt.Errorf("%s holds no value", path)
+	}
+	return nil
+}
+
+// result is one row of the comparison table
+type result struct {
+	format      serializer.Format
+	compression string
+	size        int
+	encode      testing.BenchmarkResult
+	decode      testing.BenchmarkResult
+	err         error
+}
+
+// run benchmarks every format and compression combination
+func (c *config) run() []result {
+	var results []result
+	for _, format := range c.formats {
+		s, _ := serializer.DefaultRegistry.Get(format)
+		for _, name := range c.compressors {
+			comp, _ := newCompressor(name)
+			results = append(results, benchmark(s, format, comp, c.payload))
+		}
+	}
+	return results
+}
+
+func benchmark(s serializer.Serializer, format serializer.Format, comp *compressor, payload any) result {
+	r := result{format: format, compression: comp.name}
+
+	data, err := s.Serialize(payload)
+	if err == nil {
+		// The compressor reuses its buffer, which the encode benchmark overwrites
+		data, err = comp.compress(data)
+		data = bytes.Clone(data)
+	}
+	if err == nil {
+		err = decode(s, comp, data, payload)
+	}
+	if err != nil {
+		r.err = err
+		return r
+	}
+	r.size = len(data)
+
+	r.encode = testing.Benchmark(func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			out, err := s.Serialize(payload)
+			if err == nil {
+				_, err = comp.compress(out)
+			}
+			if err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	r.decode = testing.Benchmark(func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if err := decode(s, comp, data, payload); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	return r
+}
+
+// decode decompresses data and decodes it into a new value of the payload's type
+func decode(s serializer.Serializer, comp *compressor, data []byte, payload any) error {
+	raw, err := comp.decompress(data)
+	if err != nil {
+		return err
+	}
+	return s.Deserialize(raw, reflect.New(reflect.TypeOf(payload)).Interface())
+}
+
+// compressor wraps encoded payloads. It reuses its writer, reader and buffers, so
+// results it returns are only valid until the next call.
+type compressor struct {
+	name       string
+	compress   func(data []byte) ([]byte, error)
+	decompress func(data []byte) ([]byte, error)
+}
+
+func newCompressor(name string) (*compressor, error) {
+	var out, in bytes.Buffer
+	switch name {
+	case "none":
+		identity := func(data []byte) ([]byte, error) { return data, nil }
+		return &compressor{name: name, compress: identity, decompress: identity}, nil
+	case "gzip":
+		w := gzip.NewWriter(nil)
+		var r gzip.Reader
+		return &compressor{
+			name: name,
+			compress: func(data []byte) ([]byte, error) {
+				out.Reset()
+				w.Reset(&out)
+				return closeWriter(w, data, &out)
+			},
+			decompress: func(data []byte) ([]byte, error) {
+				if err := r.Reset(bytes.NewReader(data)); err != nil {
+					return nil, err
+				}
+				return readAll(&r, &in)
+			},
+		}, nil
+	case "flate":
+		w, _ := flate.NewWriter(nil, flate.DefaultCompression)
+		r := flate.NewReader(nil)
+		return &compressor{
+			name: name,
+			compress: func(data []byte) ([]byte, error) {
+				out.Reset()
+				w.Reset(&out)
+				return closeWriter(w, data, &out)
+			},
+			decompress: func(data []byte) ([]byte, error) {
+				if err := r.(flate.Resetter).Reset(bytes.NewReader(data), nil); err != nil {
+					return nil, err
+				}
+				return readAll(r, &in)
+			},
+		}, nil
+	}
+	return nil, usageError{fmt.Sprintf("unknown compression %q", name)}
+}
+
+func closeWriter(w io.WriteCloser, data []byte, out *bytes.Buffer) ([]byte, error) {
+	if _, err := w.Write(data); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
+
+func readAll(r io.Reader, buf *bytes.Buffer) ([]byte, error) {
+	buf.Reset()
+	if _, err := buf.ReadFrom(r); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// writeTable prints results as an aligned table, with failures in place of numbers
+func writeTable(w io.Writer, results []result) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "format\tcompression\tsize\tencode ns/op\tencode B/op\tencode allocs/op\tdecode ns/op\tdecode B/op\tdecode allocs/op\t")
+	for _, r := range results {
+		if r.err != nil {
+			fmt.Fprintf(tw, "%s\t%s\terror: %v\t\n", r.format, r.compression, r.err)
+			continue
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%d\t%d\t%d\t%d\t%d\t%d\t%d\t\n",
+			r.format, r.compression, r.size,
+			r.encode.NsPerOp(), r.encode.AllocedBytesPerOp(), r.encode.AllocsPerOp(),
+			r.decode.NsPerOp(), r.decode.AllocedBytesPerOp(), r.decode.AllocsPerOp())
+	}
+	return tw.Flush()
+}
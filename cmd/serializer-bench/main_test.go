@@ -0,0 +1,93 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writePayload(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestRunPrintsTable(t *testing.T) {
+	path := writePayload(t, "order.json", `{"id":1,"tags":["a","b"],"lines":[{"sku":"x","qty":2}]}`)
+
+	var stdout, stderr bytes.Buffer
+	args := []string{"--formats", "json,msgpack", "--compress", "none,gzip,flate", "--benchtime", "5x", path}
+	if code := run(args, &stdout, &stderr); code != 0 {
+		t.Fatalf("exited %d: %s", code, stderr.String())
+	}
+
+	lines := strings.Split(strings.TrimSpace(stdout.String()), "\n")
+	if len(lines) != 7 {
+		t.Fatalf("expected a header and 6 rows, got:\n%s", stdout.String())
+	}
+	if !strings.Contains(lines[0], "encode allocs/op") || !strings.Contains(lines[0], "size") {
+		t.Errorf("unexpected header %q", lines[0])
+	}
+	for i, want := range []string{"json none", "json gzip", "json flate", "msgpack none", "msgpack gzip", "msgpack flate"} {
+		if got := strings.Join(strings.Fields(lines[i+1])[:2], " "); got != want {
+			t.Errorf("row %d starts with %q, want %q", i+1, got, want)
+		}
+		if strings.Contains(lines[i+1], "error") {
+			t.Errorf("row %d failed: %s", i+1, lines[i+1])
+		}
+	}
+}
+
+func TestCompressorsRoundTrip(t *testing.T) {
+	data := bytes.Repeat([]byte("payload "), 100)
+	for _, name := range []string{"none", "gzip", "flate"} {
+		comp, err := newCompressor(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i := 0; i < 2; i++ {
+			packed, err := comp.compress(data)
+			if err != nil {
+				t.Fatalf("%s: compress: %v", name, err)
+			}
+			if name != "none" && len(packed) >= len(data) {
+				t.Errorf("%s: compressed to %d bytes from %d", name, len(packed), len(data))
+			}
+			unpacked, err := comp.decompress(bytes.Clone(packed))
+			if err != nil {
+				t.Fatalf("%s: decompress: %v", name, err)
+			}
+			if !bytes.Equal(unpacked, data) {
+				t.Errorf("%s: round trip changed the data", name)
+			}
+		}
+	}
+}
+
+func TestUsageErrors(t *testing.T) {
+	path := writePayload(t, "p.json", `{"a":1}`)
+	cases := [][]string{
+		nil,
+		{"--formats", "yaml", path},
+		{"--compress", "zstd", path},
+		{"--benchtime", "soon", path},
+		{filepath.Join(t.TempDir(), "payload.unknown")},
+	}
+	for _, args := range cases {
+		var stdout, stderr bytes.Buffer
+		if code := run(args, &stdout, &stderr); code != 2 {
+			t.Errorf("run(%q) exited %d, want 2: %s", args, code, stderr.String())
+		}
+	}
+
+	var stdout, stderr bytes.Buffer
+	bad := writePayload(t, "bad.json", `{"a":`)
+	if code := run([]string{bad}, &stdout, &stderr); code != 1 {
+		t.Errorf("expected exit code 1 for an invalid payload, got %d", code)
+	}
+}
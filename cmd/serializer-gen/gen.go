@@ -0,0 +1,773 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/build"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/MichaelAJay/go-serializer"
+)
+
+// annotation marks a struct for generation
+const annotation = "//serializer:generate"
+
+var errNoTypes = errors.New("no annotated types")
+
+// generator writes codecs for the annotated structs of one package
+type generator struct {
+	json, msgpack bool
+
+	structs map[string]bool
+	imports map[string]bool
+	buf     bytes.Buffer
+
+	// fileImports maps import names to paths in the file being parsed
+	fileImports map[string]string
+}
+
+type typeKind int
+
+const (
+	kindBasic typeKind = iota
+	kindStruct
+	kindPtr
+	kindSlice
+	kindMap
+	kindOther
+)
+
+// fieldType describes a field's type as far as generated code cares
+type fieldType struct {
+	kind typeKind
+	name string // basic type or annotated struct name
+	expr string // Go source of the type
+	elem *fieldType
+
+	// pkgs holds the import paths expr refers to
+	pkgs []string
+}
+
+type structField struct {
+	goName string
+	typ    *fieldType
+
+	jsonName      string
+	jsonSkip      bool
+	jsonOmitEmpty bool
+	jsonOmitZero  bool
+
+	msgpackName      string
+	msgpackSkip      bool
+	msgpackOmitEmpty bool
+}
+
+type structType struct {
+	name   string
+	fields []structField
+}
+
+// generate parses the package in dir, skipping the output file, and returns the
+// formatted source of the codecs
+func (g *generator) generate(dir, output string) ([]byte, error) {
+	pkg, err := build.ImportDir(dir, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	fset := token.NewFileSet()
+	var specs []*ast.TypeSpec
+	specImports := make(map[*ast.TypeSpec]map[string]string)
+	for _, name := range pkg.GoFiles {
+		if name == output {
+			continue
+		}
+		file, err := parser.ParseFile(fset, filepath.Join(dir, name), nil, parser.ParseComments)
+		if err != nil {
+			return nil, err
+		}
+		imports := fileImports(file)
+		for _, decl := range file.Decls {
+			gen, ok := decl.(*ast.GenDecl)
+			if !ok || gen.Tok != token.TYPE {
+				continue
+			}
+			for _, spec := range gen.Specs {
+				ts := spec.(*ast.TypeSpec)
+				doc := ts.Doc
+				if doc == nil && len(gen.Specs) == 1 {
+					doc = gen.Doc
+				}
+				if annotated(doc) {
+					specs = append(specs, ts)
+					specImports[ts] = imports
+				}
+			}
+		}
+	}
+	if len(specs) == 0 {
+		return nil, errNoTypes
+	}
+
+	g.structs = make(map[string]bool)
+	for _, ts := range specs {
+		g.structs[ts.Name.Name] = true
+	}
+	var structs []structType
+	for _, ts := range specs {
+		g.fileImports = specImports[ts]
+		st, err := g.parseStruct(ts)
+		if err != nil {
+			return nil, err
+		}
+		structs = append(structs, st)
+	}
+
+	g.imports = make(map[string]bool)
+	g.buf.Reset()
+	for _, st := range structs {
+		if g.json {
+			g.jsonCodec(st)
+		}
+		if g.msgpack {
+			g.msgpackCodec(st)
+		}
+	}
+
+	var out bytes.Buffer
+	fmt.Fprintf(&out, "// Code generated by serializer-gen. DO NOT EDIT.\n\npackage %s\n\n", pkg.Name)
+	paths := make([]string, 0, len(g.imports))
+	for path := range g.imports {
+		paths = append(paths, path)
+	}
+	// Standard library imports come first, as goimports groups them
+	sort.Slice(paths, func(i, j int) bool {
+		si, sj := !strings.Contains(paths[i], "."), !strings.Contains(paths[j], ".")
+		if si != sj {
+			return si
+		}
+		return paths[i] < paths[j]
+	})
+	out.WriteString("import (\n")
+	for i, path := range paths {
+		if i > 0 && strings.Contains(path, ".") && !strings.Contains(paths[i-1], ".") {
+			out.WriteString("\n")
+		}
+		fmt.Fprintf(&out, "\t%q\n", path)
+	}
+	out.WriteString(")\n")
+	out.Write(g.buf.Bytes())
+
+	src, err := format.Source(out.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("format generated code: %w", err)
+	}
+	return src, nil
+}
+
+// fileImports maps the names file refers to its imports by to their paths
+func fileImports(file *ast.File) map[string]string {
+	imports := make(map[string]string)
+	for _, spec := range file.Imports {
+		path, err := strconv.Unquote(spec.Path.Value)
+		if err != nil {
+			continue
+		}
+		if spec.Name != nil {
+			imports[spec.Name.Name] = path
+			continue
+		}
+		// Assume the package name is the last path element, skipping a major version
+		elems := strings.Split(path, "/")
+		name := elems[len(elems)-1]
+		if len(elems) > 1 && len(name) > 1 && name[0] == 'v' && strings.Trim(name[1:], "0123456789") == "" {
+			name = elems[len(elems)-2]
+		}
+		imports[name] = path
+	}
+	return imports
+}
+
+func annotated(doc *ast.CommentGroup) bool {
+	if doc == nil {
+		return false
+	}
+	for _, c := range doc.List {
+		if strings.TrimSpace(c.Text) == annotation {
+			return true
+		}
+	}
+	return false
+}
+
+func (g *generator) parseStruct(ts *ast.TypeSpec) (structType, error) {
+	st := structType{name: ts.Name.Name}
+	if ts.TypeParams != nil {
+		return st, fmt.Errorf("%s: generic types are not supported", st.name)
+	}
+	s, ok := ts.Type.(*ast.StructType)
+	if !ok {
+		return st, fmt.Errorf("%s: only struct types can be generated", st.name)
+	}
+
+	jsonNames := make(map[string]bool)
+	msgpackNames := make(map[string]bool)
+	for _, f := range s.Fields.List {
+		var tag reflect.StructTag
+		if f.Tag != nil {
+			unquoted, err := strconv.Unquote(f.Tag.Value)
+			if err != nil {
+				return st, fmt.Errorf("%s: bad struct tag %s", st.name, f.Tag.Value)
+			}
+			tag = reflect.StructTag(unquoted)
+		}
+		if len(f.Names) == 0 {
+			return st, fmt.Errorf("%s: embedded field %s is not supported", st.name, types.ExprString(f.Type))
+		}
+		for _, name := range f.Names {
+			if name.Name == "_msgpack" {
+				return st, fmt.Errorf("%s: _msgpack struct options are not supported", st.name)
+			}
+			if !name.IsExported() {
+				continue
+			}
+			field, err := g.parseField(name.Name, f.Type, tag)
+			if err != nil {
+				return st, fmt.Errorf("%s.%s: %w", st.name, name.Name, err)
+			}
+			if !field.jsonSkip {
+				if jsonNames[field.jsonName] {
+					return st, fmt.Errorf("%s: duplicate JSON name %q", st.name, field.jsonName)
+				}
+				jsonNames[field.jsonName] = true
+			}
+			if !field.msgpackSkip {
+				if msgpackNames[field.msgpackName] {
+					return st, fmt.Errorf("%s: duplicate MessagePack name %q", st.name, field.msgpackName)
+				}
+				msgpackNames[field.msgpackName] = true
+			}
+			st.fields = append(st.fields, field)
+		}
+	}
+	return st, nil
+}
+
+func (g *generator) parseField(name string, expr ast.Expr, tag reflect.StructTag) (structField, error) {
+	f := structField{goName: name, typ: g.classify(expr)}
+
+	jsonName, jsonOpts, _ := strings.Cut(tag.Get("json"), ",")
+	f.jsonSkip = jsonName == "-" && jsonOpts == ""
+	f.jsonName = jsonName
+	if jsonName == "" {
+		f.jsonName = name
+	}
+	for _, opt := range strings.Split(jsonOpts, ",") {
+		switch opt {
+		case "omitempty":
+			f.jsonOmitEmpty = true
+		case "omitzero":
+			f.jsonOmitZero = true
+		case "string":
+			if g.json && !f.jsonSkip {
+				return f, errors.New(`the json ",string" option is not supported`)
+			}
+		}
+	}
+
+	msgpackName, msgpackOpts, _ := strings.Cut(tag.Get("msgpack"), ",")
+	f.msgpackSkip = msgpackName == "-"
+	f.msgpackName = msgpackName
+	if msgpackName == "" {
+		f.msgpackName = name
+	}
+	for _, opt := range strings.Split(msgpackOpts, ",") {
+		switch opt {
+		case "omitempty":
+			f.msgpackOmitEmpty = true
+		case "inline", "intern", "alias":
+			if g.msgpack && !f.msgpackSkip {
+				return f, fmt.Errorf("the msgpack %q option is not supported", opt)
+			}
+		}
+	}
+	return f, nil
+}
+
+var basicTypes = map[string]bool{
+	"string": true, "bool": true,
+	"int": true, "int8": true, "int16": true, "int32": true, "int64": true, "rune": true,
+	"uint": true, "uint8": true, "uint16": true, "uint32": true, "uint64": true, "byte": true,
+	"float32": true, "float64": true,
+}
+
+// classify works from syntax alone: builtin names are taken to be the builtin types
+func (g *generator) classify(expr ast.Expr) *fieldType {
+	t := &fieldType{kind: kindOther, expr: types.ExprString(expr)}
+	ast.Inspect(expr, func(n ast.Node) bool {
+		if sel, ok := n.(*ast.SelectorExpr); ok {
+			if id, ok := sel.X.(*ast.Ident); ok {
+				if path, ok := g.fileImports[id.Name]; ok {
+					t.pkgs = append(t.pkgs, path)
+				}
+			}
+		}
+		return true
+	})
+	switch e := expr.(type) {
+	case *ast.Ident:
+		switch {
+		case basicTypes[e.Name]:
+			t.kind, t.name = kindBasic, e.Name
+		case g.structs[e.Name]:
+			t.kind, t.name = kindStruct, e.Name
+		}
+	case *ast.ParenExpr:
+		return g.classify(e.X)
+	case *ast.StarExpr:
+		t.kind, t.elem = kindPtr, g.classify(e.X)
+	case *ast.ArrayType:
+		if e.Len != nil {
+			break
+		}
+		// []byte is base64 in JSON and bin in MessagePack
+		if id, ok := e.Elt.(*ast.Ident); ok && (id.Name == "byte" || id.Name == "uint8") {
+			break
+		}
+		t.kind, t.elem = kindSlice, g.classify(e.Elt)
+	case *ast.MapType:
+		if id, ok := e.Key.(*ast.Ident); ok && id.Name == "string" {
+			t.kind, t.elem = kindMap, g.classify(e.Value)
+		}
+	}
+	return t
+}
+
+// typeExpr returns the Go source of t, importing the packages it refers to
+func (g *generator) typeExpr(t *fieldType) string {
+	for _, path := range t.pkgs {
+		g.imports[path] = true
+	}
+	return t.expr
+}
+
+func (g *generator) printf(format string, args ...any) {
+	fmt.Fprintf(&g.buf, format, args...)
+}
+
+// intBits returns the bit size of an integer or float type, as Go source
+func intBits(name string) string {
+	switch name {
+	case "int", "uint":
+		return "strconv.IntSize"
+	case "int8", "uint8", "byte":
+		return "8"
+	case "int16", "uint16":
+		return "16"
+	case "int32", "uint32", "rune", "float32":
+		return "32"
+	}
+	return "64"
+}
+
+func isSigned(name string) bool {
+	return strings.HasPrefix(name, "int") || name == "rune"
+}
+
+func isFloat(name string) bool {
+	return strings.HasPrefix(name, "float")
+}
+
+// addr returns an expression for a pointer to expr when it is addressable
+func addr(expr string, addressable bool) string {
+	if !addressable {
+		return expr
+	}
+	if strings.HasPrefix(expr, "(*") && strings.HasSuffix(expr, ")") {
+		return expr[2 : len(expr)-1]
+	}
+	return "&" + expr
+}
+
+// jsonCodec writes AppendJSON and ReadJSON for st
+func (g *generator) jsonCodec(st structType) {
+	g.imports["github.com/MichaelAJay/go-serializer"] = true
+
+	g.printf("\n// AppendJSON implements serializer.GeneratedJSONEncoder\n")
+	g.printf("func (x %s) AppendJSON(dst []byte) ([]byte, error) {\n", st.name)
+	g.printf("var err error\ndst = append(dst, '{')\n")
+	// separator is "" before the first field, "," once a field is always written,
+	// and decided at run time while every field so far may have been omitted
+	separator := ""
+	dynamic := false
+	var names []string
+	for _, f := range st.fields {
+		if f.jsonSkip {
+			continue
+		}
+		names = append(names, f.jsonName)
+		expr := "x." + f.goName
+		cond := jsonKeepCond(expr, f.typ, f.jsonOmitEmpty, f.jsonOmitZero)
+		if cond != "" {
+			g.printf("if %s {\n", cond)
+		}
+		key := string(serializer.AppendJSONString(nil, f.jsonName)) + ":"
+		if dynamic {
+			g.printf("if dst[len(dst)-1] != '{' {\ndst = append(dst, ',')\n}\n")
+		}
+		g.printf("dst = append(dst, %s...)\n", strconv.Quote(separator+key))
+		g.jsonEncode(expr, f.typ, true, 0)
+		if cond != "" {
+			g.printf("}\n")
+		}
+		switch {
+		case cond == "" && !dynamic:
+			separator = ","
+		case cond != "" && separator == "":
+			dynamic = true
+		case cond == "" && dynamic:
+			// Later fields follow this one, which is always written
+			dynamic = false
+			separator = ","
+		}
+	}
+	g.printf("dst = append(dst, '}')\nreturn dst, err\n}\n")
+
+	fieldsVar := "jsonFields" + st.name
+	g.printf("\nvar %s = %#v\n", fieldsVar, names)
+	g.printf("\n// ReadJSON implements serializer.GeneratedJSONDecoder\n")
+	g.printf("func (x *%s) ReadJSON(r *serializer.JSONReader) error {\n", st.name)
+	g.printf("return r.ReadObject(%s, func(field int) error {\nswitch field {\n", fieldsVar)
+	i := 0
+	for _, f := range st.fields {
+		if f.jsonSkip {
+			continue
+		}
+		g.printf("case %d:\n", i)
+		g.jsonDecode("x."+f.goName, f.typ, 0)
+		i++
+	}
+	g.printf("}\nreturn nil\n})\n}\n")
+}
+
+// jsonKeepCond returns the condition under which a field tagged omitempty or
+// omitzero is written, or "" if it always is
+func jsonKeepCond(expr string, t *fieldType, omitEmpty, omitZero bool) string {
+	var conds []string
+	if omitEmpty {
+		switch t.kind {
+		case kindBasic:
+			conds = append(conds, basicNonZero(expr, t.name))
+		case kindPtr:
+			conds = append(conds, expr+" != nil")
+		case kindSlice, kindMap:
+			conds = append(conds, "len("+expr+") != 0")
+		case kindOther:
+			conds = append(conds, "!serializer.IsEmptyJSONValue(&"+expr+")")
+		}
+	}
+	if omitZero {
+		switch t.kind {
+		case kindBasic:
+			conds = append(conds, basicNonZero(expr, t.name))
+		case kindPtr, kindSlice, kindMap:
+			conds = append(conds, expr+" != nil")
+		default:
+			conds = append(conds, "!serializer.IsZeroJSONValue(&"+expr+")")
+		}
+	}
+	if len(conds) == 2 && conds[0] == conds[1] {
+		conds = conds[:1]
+	}
+	return strings.Join(conds, " && ")
+}
+
+func basicNonZero(expr, name string) string {
+	switch name {
+	case "string":
+		return expr + ` != ""`
+	case "bool":
+		return expr
+	}
+	return expr + " != 0"
+}
+
+// jsonEncode writes statements appending the JSON encoding of expr to dst
+func (g *generator) jsonEncode(expr string, t *fieldType, addressable bool, depth int) {
+	switch t.kind {
+	case kindBasic:
+		switch {
+		case t.name == "string":
+			g.printf("dst = serializer.AppendJSONString(dst, %s)\n", expr)
+		case t.name == "bool":
+			g.imports["strconv"] = true
+			g.printf("dst = strconv.AppendBool(dst, %s)\n", expr)
+		case isFloat(t.name):
+			g.printf("if dst, err = serializer.AppendJSONFloat(dst, float64(%s), %s); err != nil {\nreturn dst, err\n}\n", expr, intBits(t.name))
+		case isSigned(t.name):
+			g.imports["strconv"] = true
+			g.printf("dst = strconv.AppendInt(dst, int64(%s), 10)\n", expr)
+		default:
+			g.imports["strconv"] = true
+			g.printf("dst = strconv.AppendUint(dst, uint64(%s), 10)\n", expr)
+		}
+	case kindStruct:
+		g.printf("if dst, err = %s.AppendJSON(dst); err != nil {\nreturn dst, err\n}\n", expr)
+	case kindPtr:
+		g.printf("if %s == nil {\ndst = append(dst, \"null\"...)\n} else {\n", expr)
+		g.jsonEncode("(*"+expr+")", t.elem, true, depth)
+		g.printf("}\n")
+	case kindSlice:
+		i := fmt.Sprintf("i%d", depth)
+		g.printf("if %s == nil {\ndst = append(dst, \"null\"...)\n} else {\n", expr)
+		g.printf("dst = append(dst, '[')\nfor %s := range %s {\n", i, expr)
+		g.printf("if %s > 0 {\ndst = append(dst, ',')\n}\n", i)
+		g.jsonEncode(expr+"["+i+"]", t.elem, true, depth+1)
+		g.printf("}\ndst = append(dst, ']')\n}\n")
+	case kindMap:
+		g.imports["sort"] = true
+		i, k, keys := fmt.Sprintf("i%d", depth), fmt.Sprintf("k%d", depth), fmt.Sprintf("keys%d", depth)
+		g.printf("if %s == nil {\ndst = append(dst, \"null\"...)\n} else {\n", expr)
+		g.printf("%s := make([]string, 0, len(%s))\nfor %s := range %s {\n%s = append(%s, %s)\n}\nsort.Strings(%s)\n", keys, expr, k, expr, keys, keys, k, keys)
+		g.printf("dst = append(dst, '{')\nfor %s, %s := range %s {\n", i, k, keys)
+		g.printf("if %s > 0 {\ndst = append(dst, ',')\n}\n", i)
+		g.printf("dst = serializer.AppendJSONString(dst, %s)\ndst = append(dst, ':')\n", k)
+		g.jsonEncode(expr+"["+k+"]", t.elem, false, depth+1)
+		g.printf("}\ndst = append(dst, '}')\n}\n")
+	default:
+		g.printf("if dst, err = serializer.AppendJSONValue(dst, %s); err != nil {\nreturn dst, err\n}\n", addr(expr, addressable))
+	}
+}
+
+// jsonDecode writes statements reading the next value from r into target
+func (g *generator) jsonDecode(target string, t *fieldType, depth int) {
+	v := fmt.Sprintf("v%d", depth)
+	switch t.kind {
+	case kindBasic:
+		g.printf("if !r.ReadNull() {\n")
+		switch {
+		case t.name == "string":
+			g.printf("%s, err := r.ReadString()\n", v)
+		case t.name == "bool":
+			g.printf("%s, err := r.ReadBool()\n", v)
+		case isFloat(t.name):
+			g.printf("%s, err := r.ReadFloat(%s)\n", v, intBits(t.name))
+		case isSigned(t.name):
+			g.printf("%s, err := r.ReadInt(%s)\n", v, intBits(t.name))
+		default:
+			g.printf("%s, err := r.ReadUint(%s)\n", v, intBits(t.name))
+		}
+		if intBits(t.name) == "strconv.IntSize" {
+			g.imports["strconv"] = true
+		}
+		g.printf("if err != nil {\nreturn err\n}\n")
+		if t.name == "string" || t.name == "bool" || t.name == "int64" || t.name == "uint64" || t.name == "float64" {
+			g.printf("%s = %s\n", target, v)
+		} else {
+			g.printf("%s = %s(%s)\n", target, t.name, v)
+		}
+		g.printf("}\n")
+	case kindStruct:
+		g.printf("if err := %s.ReadJSON(r); err != nil {\nreturn err\n}\n", target)
+	case kindPtr:
+		g.printf("if r.ReadNull() {\n%s = nil\n} else {\n", target)
+		g.printf("if %s == nil {\n%s = new(%s)\n}\n", target, target, g.typeExpr(t.elem))
+		g.jsonDecode("(*"+target+")", t.elem, depth)
+		g.printf("}\n")
+	case kindSlice:
+		s, e := fmt.Sprintf("s%d", depth), fmt.Sprintf("e%d", depth)
+		g.printf("if r.ReadNull() {\n%s = nil\n} else {\n", target)
+		g.printf("%s := %s[:0]\nif %s == nil {\n%s = %s{}\n}\n", s, target, s, s, g.typeExpr(t))
+		g.printf("if err := r.ReadArray(func() error {\nvar %s %s\n", e, g.typeExpr(t.elem))
+		g.jsonDecode(e, t.elem, depth+1)
+		g.printf("%s = append(%s, %s)\nreturn nil\n}); err != nil {\nreturn err\n}\n%s = %s\n}\n", s, s, e, target, s)
+	case kindMap:
+		m, k, e := fmt.Sprintf("m%d", depth), fmt.Sprintf("k%d", depth), fmt.Sprintf("e%d", depth)
+		g.printf("if r.ReadNull() {\n%s = nil\n} else {\n", target)
+		g.printf("if %s == nil {\n%s = %s{}\n}\n%s := %s\n", target, target, g.typeExpr(t), m, target)
+		g.printf("if err := r.ReadMap(func(%s string) error {\nvar %s %s\n", k, e, g.typeExpr(t.elem))
+		g.jsonDecode(e, t.elem, depth+1)
+		g.printf("%s[%s] = %s\nreturn nil\n}); err != nil {\nreturn err\n}\n}\n", m, k, e)
+	default:
+		g.printf("if err := r.ReadValue(%s); err != nil {\nreturn err\n}\n", addr(target, true))
+	}
+}
+
+// msgpackCodec writes WriteMsgpack and ReadMsgpack for st
+func (g *generator) msgpackCodec(st structType) {
+	g.imports["github.com/vmihailenco/msgpack/v5"] = true
+
+	g.printf("\n// WriteMsgpack implements serializer.GeneratedMsgpackEncoder\n")
+	g.printf("func (x %s) WriteMsgpack(enc *msgpack.Encoder) error {\n", st.name)
+	count := 0
+	var optional []string
+	for _, f := range st.fields {
+		if f.msgpackSkip {
+			continue
+		}
+		if cond := msgpackKeepCond("x."+f.goName, f.typ, f.msgpackOmitEmpty); cond != "" {
+			if strings.Contains(cond, "serializer.") {
+				g.imports["github.com/MichaelAJay/go-serializer"] = true
+			}
+			optional = append(optional, cond)
+		} else {
+			count++
+		}
+	}
+	if len(optional) == 0 {
+		g.printf("if err := enc.EncodeMapLen(%d); err != nil {\nreturn err\n}\n", count)
+	} else {
+		g.printf("n := %d\n", count)
+		for _, cond := range optional {
+			g.printf("if %s {\nn++\n}\n", cond)
+		}
+		g.printf("if err := enc.EncodeMapLen(n); err != nil {\nreturn err\n}\n")
+	}
+	for _, f := range st.fields {
+		if f.msgpackSkip {
+			continue
+		}
+		expr := "x." + f.goName
+		cond := msgpackKeepCond(expr, f.typ, f.msgpackOmitEmpty)
+		if cond != "" {
+			g.printf("if %s {\n", cond)
+		}
+		g.printf("if err := enc.EncodeString(%s); err != nil {\nreturn err\n}\n", strconv.Quote(f.msgpackName))
+		g.msgpackEncode(expr, f.typ, true, 0)
+		if cond != "" {
+			g.printf("}\n")
+		}
+	}
+	g.printf("return nil\n}\n")
+
+	g.printf("\n// ReadMsgpack implements serializer.GeneratedMsgpackDecoder\n")
+	g.printf("func (x *%s) ReadMsgpack(dec *msgpack.Decoder) error {\n", st.name)
+	g.printf("n, err := dec.DecodeMapLen()\nif err != nil {\nreturn err\n}\n")
+	g.imports["github.com/MichaelAJay/go-serializer"] = true
+	g.printf("var key []byte\nfor i := 0; i < n; i++ {\nif key, err = serializer.DecodeMsgpackKey(dec, key); err != nil {\nreturn err\n}\nswitch string(key) {\n")
+	for _, f := range st.fields {
+		if f.msgpackSkip {
+			continue
+		}
+		g.printf("case %s:\n", strconv.Quote(f.msgpackName))
+		g.msgpackDecode("x."+f.goName, f.typ, 0)
+	}
+	g.printf("default:\nif err := dec.Skip(); err != nil {\nreturn err\n}\n}\n}\nreturn nil\n}\n")
+}
+
+// msgpackKeepCond returns the condition under which a field tagged omitempty is
+// written, or "" if it always is
+func msgpackKeepCond(expr string, t *fieldType, omitEmpty bool) string {
+	if !omitEmpty {
+		return ""
+	}
+	switch t.kind {
+	case kindBasic:
+		return basicNonZero(expr, t.name)
+	case kindPtr:
+		return expr + " != nil"
+	case kindSlice, kindMap:
+		return "len(" + expr + ") != 0"
+	}
+	return "!serializer.IsEmptyMsgpackValue(&" + expr + ")"
+}
+
+// msgpackMethods names the Encoder and Decoder methods for each basic type, which
+// match what msgpack's reflection uses for fields of that type
+var msgpackMethods = map[string][2]string{
+	"string":  {"EncodeString", "DecodeString"},
+	"bool":    {"EncodeBool", "DecodeBool"},
+	"int":     {"EncodeInt", "DecodeInt"},
+	"int8":    {"EncodeInt8", "DecodeInt8"},
+	"int16":   {"EncodeInt16", "DecodeInt16"},
+	"int32":   {"EncodeInt32", "DecodeInt32"},
+	"rune":    {"EncodeInt32", "DecodeInt32"},
+	"int64":   {"EncodeInt64", "DecodeInt64"},
+	"uint":    {"EncodeUint", "DecodeUint"},
+	"uint8":   {"EncodeUint8", "DecodeUint8"},
+	"byte":    {"EncodeUint8", "DecodeUint8"},
+	"uint16":  {"EncodeUint16", "DecodeUint16"},
+	"uint32":  {"EncodeUint32", "DecodeUint32"},
+	"uint64":  {"EncodeUint64", "DecodeUint64"},
+	"float32": {"EncodeFloat32", "DecodeFloat32"},
+	"float64": {"EncodeFloat64", "DecodeFloat64"},
+}
+
+// msgpackEncode writes statements encoding expr with enc
+func (g *generator) msgpackEncode(expr string, t *fieldType, addressable bool, depth int) {
+	switch t.kind {
+	case kindBasic:
+		arg := expr
+		switch t.name {
+		case "int":
+			arg = "int64(" + expr + ")"
+		case "uint":
+			arg = "uint64(" + expr + ")"
+		}
+		g.printf("if err := enc.%s(%s); err != nil {\nreturn err\n}\n", msgpackMethods[t.name][0], arg)
+	case kindStruct:
+		g.printf("if err := %s.WriteMsgpack(enc); err != nil {\nreturn err\n}\n", expr)
+	case kindPtr:
+		g.printf("if %s == nil {\nif err := enc.EncodeNil(); err != nil {\nreturn err\n}\n} else {\n", expr)
+		g.msgpackEncode("(*"+expr+")", t.elem, true, depth)
+		g.printf("}\n")
+	case kindSlice:
+		i := fmt.Sprintf("i%d", depth)
+		g.printf("if %s == nil {\nif err := enc.EncodeNil(); err != nil {\nreturn err\n}\n} else {\n", expr)
+		g.printf("if err := enc.EncodeArrayLen(len(%s)); err != nil {\nreturn err\n}\nfor %s := range %s {\n", expr, i, expr)
+		g.msgpackEncode(expr+"["+i+"]", t.elem, true, depth+1)
+		g.printf("}\n}\n")
+	case kindMap:
+		k, v := fmt.Sprintf("k%d", depth), fmt.Sprintf("v%d", depth)
+		g.printf("if %s == nil {\nif err := enc.EncodeNil(); err != nil {\nreturn err\n}\n} else {\n", expr)
+		g.printf("if err := enc.EncodeMapLen(len(%s)); err != nil {\nreturn err\n}\nfor %s, %s := range %s {\n", expr, k, v, expr)
+		g.printf("if err := enc.EncodeString(%s); err != nil {\nreturn err\n}\n", k)
+		g.msgpackEncode(v, t.elem, false, depth+1)
+		g.printf("}\n}\n")
+	default:
+		g.printf("if err := enc.Encode(%s); err != nil {\nreturn err\n}\n", addr(expr, addressable))
+	}
+}
+
+// msgpackDecode writes statements decoding the next value from dec into target
+func (g *generator) msgpackDecode(target string, t *fieldType, depth int) {
+	switch t.kind {
+	case kindBasic:
+		v := fmt.Sprintf("v%d", depth)
+		g.printf("{\n%s, err := dec.%s()\nif err != nil {\nreturn err\n}\n%s = %s\n}\n", v, msgpackMethods[t.name][1], target, v)
+	case kindStruct:
+		g.printf("if err := %s.ReadMsgpack(dec); err != nil {\nreturn err\n}\n", target)
+	case kindPtr:
+		g.imports["github.com/MichaelAJay/go-serializer"] = true
+		isNil := fmt.Sprintf("isNil%d", depth)
+		g.printf("if %s, err := serializer.DecodeMsgpackNil(dec); err != nil {\nreturn err\n} else if %s {\n%s = nil\n} else {\n", isNil, isNil, target)
+		g.printf("if %s == nil {\n%s = new(%s)\n}\n", target, target, g.typeExpr(t.elem))
+		g.msgpackDecode("(*"+target+")", t.elem, depth)
+		g.printf("}\n")
+	case kindSlice:
+		n, s, i := fmt.Sprintf("n%d", depth), fmt.Sprintf("s%d", depth), fmt.Sprintf("i%d", depth)
+		g.printf("{\n%s, err := dec.DecodeArrayLen()\nif err != nil {\nreturn err\n}\n", n)
+		g.printf("if %s == -1 {\n%s = nil\n} else {\n%s := make(%s, %s)\nfor %s := range %s {\n", n, target, s, g.typeExpr(t), n, i, s)
+		g.msgpackDecode(s+"["+i+"]", t.elem, depth+1)
+		g.printf("}\n%s = %s\n}\n}\n", target, s)
+	case kindMap:
+		n, m, i, k, e := fmt.Sprintf("n%d", depth), fmt.Sprintf("m%d", depth), fmt.Sprintf("i%d", depth), fmt.Sprintf("k%d", depth), fmt.Sprintf("e%d", depth)
+		g.printf("{\n%s, err := dec.DecodeMapLen()\nif err != nil {\nreturn err\n}\n", n)
+		g.printf("if %s == -1 {\n%s = nil\n} else {\n%s := make(%s, %s)\nfor %s := 0; %s < %s; %s++ {\n", n, target, m, g.typeExpr(t), n, i, i, n, i)
+		g.printf("%s, err := dec.DecodeString()\nif err != nil {\nreturn err\n}\nvar %s %s\n", k, e, g.typeExpr(t.elem))
+		g.msgpackDecode(e, t.elem, depth+1)
+		g.printf("%s[%s] = %s\n}\n%s = %s\n}\n}\n", m, k, e, target, m)
+	default:
+		g.printf("if err := dec.Decode(%s); err != nil {\nreturn err\n}\n", addr(target, true))
+	}
+}
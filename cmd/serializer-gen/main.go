@@ -0,0 +1,82 @@
+// Command serializer-gen writes reflection-free JSON and MessagePack codecs for
+// the structs of a package that carry a //serializer:generate comment. The JSON
+// and MessagePack serializers detect the generated methods and use them in place
+// of reflection, so call sites do not change.
+//
+// Usage:
+//
+//	serializer-gen [--formats json,msgpack] [--output serializer_gen.go] [dir]
+//
+// It is usually run through go generate:
+//
+//	//go:generate go run github.com/MichaelAJay/go-serializer/cmd/serializer-gen
+//
+//	//serializer:generate
+//	type User struct { ... }
+//
+// Fields of builtin types, annotated structs, and pointers, slices and string-keyed
+// maps of them get generated code; other fields (time.Time, interfaces, types from
+// other packages) are handed to encoding/json or msgpack's reflection.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const usage = `usage:
+  serializer-gen [--formats json,msgpack] [--output serializer_gen.go] [dir]
+`
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+// run executes the CLI and returns the process exit code
+func run(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("serializer-gen", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	formats := fs.String("formats", "json,msgpack", "comma-separated formats to generate")
+	output := fs.String("output", "serializer_gen.go", "output file, relative to the package directory")
+	if err := fs.Parse(args); err != nil || fs.NArg() > 1 {
+		fmt.Fprint(stderr, usage)
+		return 2
+	}
+	dir := "."
+	if fs.NArg() == 1 {
+		dir = fs.Arg(0)
+	}
+
+	g := generator{}
+	for _, format := range strings.Split(*formats, ",") {
+		switch strings.TrimSpace(format) {
+		case "json":
+			g.json = true
+		case "msgpack":
+			g.msgpack = true
+		default:
+			fmt.Fprintf(stderr, "serializer-gen: unknown format %q\n", format)
+			fmt.Fprint(stderr, usage)
+			return 2
+		}
+	}
+
+	src, err := g.generate(dir, filepath.Base(*output))
+	if errors.Is(err, errNoTypes) {
+		fmt.Fprintf(stdout, "serializer-gen: no //serializer:generate types in %s\n", dir)
+		return 0
+	}
+	if err == nil {
+		err = os.WriteFile(filepath.Join(dir, *output), src, 0o644)
+	}
+	if err != nil {
+		fmt.Fprintf(stderr, "serializer-gen: %v\n", err)
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// The generated code checked in under internal/gentest must match what the
+// generator writes now
+func TestGeneratedCodeIsUpToDate(t *testing.T) {
+	dir := filepath.Join("..", "..", "internal", "gentest")
+	want, err := os.ReadFile(filepath.Join(dir, "serializer_gen.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	g := generator{json: true, msgpack: true}
+	got, err := g.generate(dir, "serializer_gen.go")
+	if err != nil {
+		t.Fatalf("generate failed: %v", err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Error("internal/gentest/serializer_gen.go is stale; run go generate ./internal/gentest")
+	}
+}
+
+func TestRunErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want string
+	}{
+		{"embedded field", "type T struct {\n\tU\n}\ntype U struct{}", "embedded field U"},
+		{"json string option", "type T struct {\n\tN int `json:\",string\"`\n}", `",string" option`},
+		{"msgpack inline", "type T struct {\n\tN int `msgpack:\",inline\"`\n}", `"inline" option`},
+		{"not a struct", "type T []int", "only struct types"},
+		{"generic", "type T[V any] struct {\n\tV V\n}", "generic types"},
+		{"duplicate name", "type T struct {\n\tA int `json:\"x\"`\n\tB int `json:\"x\"`\n}", `duplicate JSON name "x"`},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := t.TempDir()
+			src := "package p\n\n//serializer:generate\n" + tc.src + "\n"
+			if err := os.WriteFile(filepath.Join(dir, "p.go"), []byte(src), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			var stdout, stderr bytes.Buffer
+			if code := run([]string{dir}, &stdout, &stderr); code != 1 {
+				t.Fatalf("exit code %d, want 1", code)
+			}
+			if !strings.Contains(stderr.String(), tc.want) {
+				t.Errorf("stderr %q does not mention %q", stderr.String(), tc.want)
+			}
+			if _, err := os.Stat(filepath.Join(dir, "serializer_gen.go")); !os.IsNotExist(err) {
+				t.Error("output was written despite the error")
+			}
+		})
+	}
+}
+
+func TestRunWritesOutput(t *testing.T) {
+	dir := t.TempDir()
+	src := "package p\n\n//serializer:generate\ntype T struct {\n\tName string `json:\"name\"`\n}\n\ntype Skipped struct{}\n"
+	if err := os.WriteFile(filepath.Join(dir, "p.go"), []byte(src), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"--formats", "json", "--output", "codecs.go", dir}, &stdout, &stderr); code != 0 {
+		t.Fatalf("exit code %d: %s", code, stderr.String())
+	}
+	out, err := os.ReadFile(filepath.Join(dir, "codecs.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(out), "func (x T) AppendJSON") || strings.Contains(string(out), "WriteMsgpack") ||
+		strings.Contains(string(out), "Skipped") {
+		t.Errorf("unexpected output:\n%s", out)
+	}
+
+	// The previous output is ignored when generating again
+	if code := run([]string{"--formats", "json", "--output", "codecs.go", dir}, &stdout, &stderr); code != 0 {
+		t.Fatalf("second run exit code %d: %s", code, stderr.String())
+	}
+}
+
+func TestRunUsage(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"--formats", "xml"}, &stdout, &stderr); code != 2 {
+		t.Errorf("exit code %d, want 2", code)
+	}
+	if code := run([]string{"a", "b"}, &stdout, &stderr); code != 2 {
+		t.Errorf("exit code %d, want 2", code)
+	}
+
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "p.go"), []byte("package p\n\ntype T struct{}\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	stdout.Reset()
+	if code := run([]string{dir}, &stdout, &stderr); code != 0 || !strings.Contains(stdout.String(), "no //serializer:generate types") {
+		t.Errorf("exit code %d, stdout %q", code, stdout.String())
+	}
+}
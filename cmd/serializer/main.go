@@ -0,0 +1,199 @@
+// Command serializer converts, pretty-prints and validates payloads in the
+// formats registered with go-serializer.
+//
+// Usage:
+//
+//	serializer convert [--from fmt] [--to fmt] <in> <out>
+//	serializer print [--from fmt] <in>
+//	serializer validate [--from fmt] <in>
+//
+// Formats are inferred from file extensions when --from/--to are omitted.
+// Use "-" to read from stdin or write to stdout.
+package main
+
+import (
+	"encoding/gob"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/MichaelAJay/go-serializer"
+)
+
+const commands = `usage:
+  serializer convert [--from fmt] [--to fmt] <in> <out>
+  serializer print [--from fmt] <in>
+  serializer validate [--from fmt] <in>
+`
+
+// usage returns the help text, listing the formats of the default registry
+func usage() string {
+	formats := serializer.DefaultRegistry.Formats()
+	names := make([]string, len(formats))
+	for i, format := range formats {
+		names[i] = string(format)
+	}
+	return commands + "\nformats: " + strings.Join(names, ", ") + "\n"
+}
+
+func init() {
+	// Generic values decoded from other formats hold these container types
+	gob.Register(map[string]any{})
+	gob.Register([]any{})
+}
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+// run executes the CLI and returns the process exit code
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		fmt.Fprint(stderr, usage())
+		return 2
+	}
+
+	var err error
+	switch args[0] {
+	case "convert":
+		err = convert(args[1:], stdin, stdout)
+	case "print":
+		err = printPayload(args[1:], stdin, stdout)
+	case "validate":
+		err = validate(args[1:], stdin, stdout)
+	case "help", "-h", "--help":
+		fmt.Fprint(stdout, usage())
+		return 0
+	default:
+		err = usageError{fmt.Sprintf("unknown command %q", args[0])}
+	}
+
+	if err != nil {
+		fmt.Fprintf(stderr, "serializer: %v\n", err)
+		var uerr usageError
+		if errors.As(err, &uerr) {
+			fmt.Fprint(stderr, usage())
+			return 2
+		}
+		return 1
+	}
+	return 0
+}
+
+// usageError marks errors caused by invalid command-line arguments
+type usageError struct{ msg string }
+
+func (e usageError) Error() string { return e.msg }
+
+func convert(args []string, stdin io.Reader, stdout io.Writer) error {
+	fs := flag.NewFlagSet("convert", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	from := fs.String("from", "", "input format")
+	to := fs.String("to", "", "output format")
+	if err := fs.Parse(args); err != nil {
+		return usageError{err.Error()}
+	}
+	if fs.NArg() != 2 {
+		return usageError{"convert requires <in> and <out>"}
+	}
+	in, out := fs.Arg(0), fs.Arg(1)
+
+	value, err := decodeInput(in, serializer.Format(*from), stdin)
+	if err != nil {
+		return err
+	}
+
+	s, err := serializerFor(out, serializer.Format(*to))
+	if err != nil {
+		return err
+	}
+	data, err := s.Serialize(value)
+	if err != nil {
+		return fmt.Errorf("encode %s: %w", out, err)
+	}
+	if out == "-" {
+		_, err = stdout.Write(data)
+		return err
+	}
+	return os.WriteFile(out, data, 0o644)
+}
+
+func printPayload(args []string, stdin io.Reader, stdout io.Writer) error {
+	fs := flag.NewFlagSet("print", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	from := fs.String("from", "", "input format")
+	if err := fs.Parse(args); err != nil {
+		return usageError{err.Error()}
+	}
+	if fs.NArg() != 1 {
+		return usageError{"print requires <in>"}
+	}
+
+	value, err := decodeInput(fs.Arg(0), serializer.Format(*from), stdin)
+	if err != nil {
+		return err
+	}
+	pretty, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		return fmt.Errorf("render %s as JSON: %w", fs.Arg(0), err)
+	}
+	_, err = fmt.Fprintf(stdout, "%s\n", pretty)
+	return err
+}
+
+func validate(args []string, stdin io.Reader, stdout io.Writer) error {
+	fs := flag.NewFlagSet("validate", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	from := fs.String("from", "", "input format")
+	if err := fs.Parse(args); err != nil {
+		return usageError{err.Error()}
+	}
+	if fs.NArg() != 1 {
+		return usageError{"validate requires <in>"}
+	}
+
+	if _, err := decodeInput(fs.Arg(0), serializer.Format(*from), stdin); err != nil {
+		return err
+	}
+	_, err := fmt.Fprintf(stdout, "%s: ok\n", fs.Arg(0))
+	return err
+}
+
+// decodeInput reads a whole payload and decodes it into a generic value
+func decodeInput(path string, format serializer.Format, stdin io.Reader) (any, error) {
+	s, err := serializerFor(path, format)
+	if err != nil {
+		return nil, err
+	}
+
+	var data []byte
+	if path == "-" {
+		data, err = io.ReadAll(stdin)
+	} else {
+		data, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var value any
+	if err := s.Deserialize(data, &value); err != nil {
+		return nil, fmt.Errorf("decode %s: %w", path, err)
+	}
+	return value, nil
+}
+
+// serializerFor resolves the serializer for a path, preferring an explicit format
+func serializerFor(path string, format serializer.Format) (serializer.Serializer, error) {
+	if format == "" {
+		var ok bool
+		if format, ok = serializer.FormatForPath(path); !ok {
+			return nil, usageError{fmt.Sprintf("cannot infer format for %q; pass --from/--to", path)}
+		}
+	}
+	return serializer.DefaultRegistry.New(format)
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/MichaelAJay/go-serializer"
+)
+
+func TestConvertJSONToMsgpackAndBack(t *testing.T) {
+	dir := t.TempDir()
+	in := filepath.Join(dir, "in.json")
+	bin := filepath.Join(dir, "out.bin")
+	if err := os.WriteFile(in, []byte(`{"name":"test","tags":["a","b"]}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"convert", "--to", "msgpack", in, bin}, nil, &stdout, &stderr); code != 0 {
+		t.Fatalf("convert exited %d: %s", code, stderr.String())
+	}
+
+	stdout.Reset()
+	if code := run([]string{"convert", "--from", "msgpack", "--to", "json", bin, "-"}, nil, &stdout, &stderr); code != 0 {
+		t.Fatalf("convert back exited %d: %s", code, stderr.String())
+	}
+	if !strings.Contains(stdout.String(), `"name":"test"`) {
+		t.Errorf("unexpected JSON output: %s", stdout.String())
+	}
+}
+
+func TestPrintAndValidate(t *testing.T) {
+	stdin := strings.NewReader(`{"a":1}`)
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"print", "--from", "json", "-"}, stdin, &stdout, &stderr); code != 0 {
+		t.Fatalf("print exited %d: %s", code, stderr.String())
+	}
+	if stdout.String() != "{\n  \"a\": 1\n}\n" {
+		t.Errorf("unexpected pretty output: %q", stdout.String())
+	}
+
+	stdout.Reset()
+	if code := run([]string{"validate", "--from", "json", "-"}, strings.NewReader(`{"a":`), &stdout, &stderr); code != 1 {
+		t.Errorf("expected exit code 1 for invalid payload, got %d", code)
+	}
+}
+
+func TestUsageErrors(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	if code := run(nil, nil, &stdout, &stderr); code != 2 {
+		t.Errorf("expected exit code 2 without arguments, got %d", code)
+	}
+	if code := run([]string{"convert", "in.unknown", "out.json"}, nil, &stdout, &stderr); code != 2 {
+		t.Errorf("expected exit code 2 when format cannot be inferred, got %d", code)
+	}
+	if code := run([]string{"bogus"}, nil, &stdout, &stderr); code != 2 {
+		t.Errorf("expected exit code 2 for unknown command, got %d", code)
+	}
+}
+
+func TestUsageListsRegisteredFormats(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"help"}, nil, &stdout, &stderr); code != 0 {
+		t.Fatalf("help exited %d", code)
+	}
+	for _, format := range serializer.DefaultRegistry.Formats() {
+		if !strings.Contains(stdout.String(), string(format)) {
+			t.Errorf("usage does not list %s:\n%s", format, stdout.String())
+		}
+	}
+}
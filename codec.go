@@ -0,0 +1,140 @@
+package serializer
+
+import (
+	"reflect"
+	"sync"
+)
+
+// Codec is the minimal marshaler contract used by most cache libraries.
+// Adapters for go-cache, ristretto wrappers and gocache typically only need these two methods.
+type Codec interface {
+	// Encode converts a value to bytes
+	Encode(v any) ([]byte, error)
+
+	// Decode converts bytes back to a value
+	// v must be a pointer to the type you want to decode into
+	Decode(data []byte, v any) error
+}
+
+// SerializerCodec adapts a Serializer to the Codec interface.
+// It also exposes Marshal/Unmarshal for libraries that use that naming,
+// and the embedded Serializer remains available for streaming use.
+type SerializerCodec struct {
+	Serializer
+}
+
+var _ Codec = (*SerializerCodec)(nil)
+
+// NewCodec wraps a serializer as a Codec
+func NewCodec(s Serializer) *SerializerCodec {
+	return &SerializerCodec{Serializer: s}
+}
+
+// CodecFor returns a Codec backed by the DefaultRegistry serializer for format
+func CodecFor(format Format) (*SerializerCodec, error) {
+	s, err := DefaultRegistry.New(format)
+	if err != nil {
+		return nil, err
+	}
+	return NewCodec(s), nil
+}
+
+// Encode implements Codec
+func (c *SerializerCodec) Encode(v any) ([]byte, error) {
+	return c.Serialize(v)
+}
+
+// Decode implements Codec
+func (c *SerializerCodec) Decode(data []byte, v any) error {
+	return c.Deserialize(data, v)
+}
+
+// Marshal is an alias of Encode
+func (c *SerializerCodec) Marshal(v any) ([]byte, error) {
+	return c.Serialize(v)
+}
+
+// Unmarshal is an alias of Decode
+func (c *SerializerCodec) Unmarshal(data []byte, v any) error {
+	return c.Deserialize(data, v)
+}
+
+// TypedCodec encodes and decodes values of type T. It builds the TypeInfo for T
+// once and passes it to serializers implementing TypedSerializer, so callers do not.
+type TypedCodec[T any] struct {
+	s     Serializer
+	typed TypedSerializer
+	info  TypeInfo
+}
+
+// NewTypedCodec wraps a serializer as a codec for values of type T
+func NewTypedCodec[T any](s Serializer) *TypedCodec[T] {
+	c := &TypedCodec[T]{s: s, info: typeInfoFor[T]()}
+	c.typed, _ = s.(TypedSerializer)
+	return c
+}
+
+// Encode converts a value to bytes
+func (c *TypedCodec[T]) Encode(v T) ([]byte, error) {
+	return encodeTyped(c.s, c.typed, c.info, v)
+}
+
+// Decode converts bytes back to a value of type T
+func (c *TypedCodec[T]) Decode(data []byte) (T, error) {
+	return decodeTyped[T](c.s, c.typed, c.info, data)
+}
+
+// Marshal serializes v with s, passing the type information for T to serializers
+// implementing TypedSerializer. Use a TypedCodec when encoding many values of one type.
+func Marshal[T any](s Serializer, v T) ([]byte, error) {
+	typed, _ := s.(TypedSerializer)
+	return encodeTyped(s, typed, typeInfoFor[T](), v)
+}
+
+// Unmarshal deserializes data with s into a new value of type T and returns it,
+// so callers need neither declare a target nor pass a pointer to it:
+//
+//	user, err := serializer.Unmarshal[User](s, data)
+func Unmarshal[T any](s Serializer, data []byte) (T, error) {
+	typed, _ := s.(TypedSerializer)
+	return decodeTyped[T](s, typed, typeInfoFor[T](), data)
+}
+
+// typeInfos caches the TypeInfo built for each type by typeInfoFor
+var typeInfos sync.Map // reflect.Type -> TypeInfo
+
+// typeInfoFor returns the TypeInfo for T
+func typeInfoFor[T any]() TypeInfo {
+	t := reflect.TypeFor[T]()
+	if info, ok := typeInfos.Load(t); ok {
+		return info.(TypeInfo)
+	}
+	info := TypeInfo{Type: t, TypeName: t.String()}
+	typeInfos.Store(t, info)
+	return info
+}
+
+func encodeTyped[T any](s Serializer, typed TypedSerializer, info TypeInfo, v T) ([]byte, error) {
+	if typed != nil {
+		return typed.SerializeWithTypeInfo(v, info)
+	}
+	return s.Serialize(v)
+}
+
+func decodeTyped[T any](s Serializer, typed TypedSerializer, info TypeInfo, data []byte) (T, error) {
+	var v T
+	if typed != nil {
+		decoded, err := typed.DeserializeWithTypeInfo(data, info)
+		if err != nil {
+			return v, err
+		}
+		// A nil interface value does not assert to T, but zero is right for it
+		v, _ = decoded.(T)
+		return v, nil
+	}
+	if err := s.Deserialize(data, &v); err != nil {
+		var zero T
+		return zero, err
+	}
+	return v, nil
+}
@@ -0,0 +1,167 @@
+package serializer_test
+
+import (
+	"testing"
+
+	"github.com/MichaelAJay/go-serializer"
+)
+
+func TestCodecRoundTrip(t *testing.T) {
+	type session struct {
+		UserID string `json:"user_id" msgpack:"user_id"`
+		Admin  bool   `json:"admin" msgpack:"admin"`
+	}
+
+	for _, format := range append([]serializer.Format{serializer.JSON, serializer.Msgpack}, gobFormats()...) {
+		t.Run(string(format), func(t *testing.T) {
+			var codec serializer.Codec
+			codec, err := serializer.CodecFor(format)
+			if err != nil {
+				t.Fatalf("CodecFor failed: %v", err)
+			}
+
+			data, err := codec.Encode(session{UserID: "u1", Admin: true})
+			if err != nil {
+				t.Fatalf("Encode failed: %v", err)
+			}
+
+			var got session
+			if err := codec.Decode(data, &got); err != nil {
+				t.Fatalf("Decode failed: %v", err)
+			}
+			if got.UserID != "u1" || !got.Admin {
+				t.Errorf("round trip mismatch: %+v", got)
+			}
+		})
+	}
+}
+
+func TestCodecMarshalAliases(t *testing.T) {
+	codec := serializer.NewCodec(serializer.NewMsgpackSerializer())
+
+	data, err := codec.Marshal(map[string]int{"a": 1})
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+
+	var got map[string]int
+	if err := codec.Unmarshal(data, &got); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if got["a"] != 1 {
+		t.Errorf("expected a=1, got %v", got)
+	}
+	if codec.ContentType() != "application/x-msgpack" {
+		t.Errorf("embedded serializer not exposed, got content type %q", codec.ContentType())
+	}
+}
+
+func TestCodecForUnknownFormat(t *testing.T) {
+	if _, err := serializer.CodecFor("unknown"); err == nil {
+		t.Error("expected error for unregistered format")
+	}
+}
+
+func TestTypedCodec(t *testing.T) {
+	type session struct {
+		UserID string `json:"user_id" msgpack:"user_id"`
+		Admin  bool   `json:"admin" msgpack:"admin"`
+	}
+
+	for _, format := range append([]serializer.Format{serializer.JSON, serializer.Msgpack}, gobFormats()...) {
+		t.Run(string(format), func(t *testing.T) {
+			s, err := serializer.DefaultRegistry.New(format)
+			if err != nil {
+				t.Fatalf("New failed: %v", err)
+			}
+
+			codec := serializer.NewTypedCodec[session](s)
+			data, err := codec.Encode(session{UserID: "u1", Admin: true})
+			if err != nil {
+				t.Fatalf("Encode failed: %v", err)
+			}
+			got, err := codec.Decode(data)
+			if err != nil {
+				t.Fatalf("Decode failed: %v", err)
+			}
+			if got != (session{UserID: "u1", Admin: true}) {
+				t.Errorf("round trip mismatch: %+v", got)
+			}
+
+			ptrCodec := serializer.NewTypedCodec[*session](s)
+			data, err = ptrCodec.Encode(&session{UserID: "u2"})
+			if err != nil {
+				t.Fatalf("Encode failed: %v", err)
+			}
+			ptr, err := ptrCodec.Decode(data)
+			if err != nil || ptr == nil || ptr.UserID != "u2" {
+				t.Errorf("pointer round trip mismatch: %+v %v", ptr, err)
+			}
+
+			if _, err := codec.Decode([]byte{0xc1}); err == nil {
+				t.Error("expected an error for invalid data")
+			}
+		})
+	}
+}
+
+// plainSerializer hides the TypedSerializer methods of the serializer it wraps
+type plainSerializer struct {
+	serializer.Serializer
+}
+
+func TestTypedCodecPlainSerializer(t *testing.T) {
+	codec := serializer.NewTypedCodec[map[string]int](plainSerializer{serializer.NewMsgpackSerializer()})
+	data, err := codec.Encode(map[string]int{"a": 1})
+	if err != nil {
+		t.Fatalf("Encode failed: %v", err)
+	}
+	got, err := codec.Decode(data)
+	if err != nil || got["a"] != 1 {
+		t.Errorf("round trip mismatch: %v %v", got, err)
+	}
+}
+
+func TestMarshalUnmarshal(t *testing.T) {
+	type point struct {
+		X, Y int
+	}
+
+	for _, format := range append([]serializer.Format{serializer.JSON, serializer.Msgpack}, gobFormats()...) {
+		t.Run(string(format), func(t *testing.T) {
+			s, err := serializer.DefaultRegistry.New(format)
+			if err != nil {
+				t.Fatalf("New failed: %v", err)
+			}
+
+			data, err := serializer.Marshal(s, point{X: 1, Y: 2})
+			if err != nil {
+				t.Fatalf("Marshal failed: %v", err)
+			}
+			got, err := serializer.Unmarshal[point](s, data)
+			if err != nil || got != (point{X: 1, Y: 2}) {
+				t.Errorf("round trip mismatch: %+v %v", got, err)
+			}
+
+			// The helpers and a TypedCodec for the same type are interchangeable
+			fromCodec, err := serializer.NewTypedCodec[point](s).Decode(data)
+			if err != nil || fromCodec != got {
+				t.Errorf("codec decode mismatch: %+v %v", fromCodec, err)
+			}
+
+			if got, err := serializer.Unmarshal[point](s, []byte{0xc1}); err == nil {
+				t.Errorf("expected an error for invalid data, got %+v", got)
+			}
+		})
+	}
+
+	s := plainSerializer{serializer.NewJSONSerializer(0)}
+	data, err := serializer.Marshal(s, []string{"a", "b"})
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	got, err := serializer.Unmarshal[[]string](s, data)
+	if err != nil || len(got) != 2 || got[1] != "b" {
+		t.Errorf("plain serializer round trip mismatch: %v %v", got, err)
+	}
+}
@@ -0,0 +1,271 @@
+package serializer
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/klauspost/compress/s2"
+	"github.com/klauspost/compress/zstd"
+	"github.com/pierrec/lz4/v4"
+)
+
+// CompressionAlgo selects the algorithm NewCompressedSerializer compresses with
+type CompressionAlgo byte
+
+const (
+	CompressionGzip CompressionAlgo = iota + 1
+	CompressionZstd
+	// CompressionSnappy writes the Snappy framing format
+	CompressionSnappy
+	// CompressionLZ4 writes the LZ4 frame format
+	CompressionLZ4
+)
+
+var compressionAlgoNames = [...]string{"none", "gzip", "zstd", "snappy", "lz4"}
+
+func (a CompressionAlgo) String() string {
+	if int(a) >= len(compressionAlgoNames) {
+		return fmt.Sprintf("CompressionAlgo(%d)", a)
+	}
+	return compressionAlgoNames[a]
+}
+
+// ErrUnknownCompression is returned when a compressed payload names an algorithm
+// this package does not know
+var ErrUnknownCompression = errors.New("unknown compression algorithm")
+
+// compressionMagic starts every compressed payload, followed by a byte holding the
+// CompressionAlgo. 0xc1 is never used in MessagePack, cannot start JSON or a gob
+// stream, and together with the next two bytes is unlikely to start anything else.
+const compressionMagic = "\xc1SZ"
+
+const compressionHeaderSize = len(compressionMagic) + 1
+
+// NewCompressedSerializer wraps inner so Serialize and SerializeTo compress what it
+// writes with algo. Compressed payloads start with a 4-byte header naming their
+// algorithm, and Deserialize and DeserializeFrom pass data without the header to
+// inner unchanged, so stores holding a mix of compressed and uncompressed values,
+// or values compressed with different algorithms, can be read with one serializer.
+// The content type is inner's.
+func NewCompressedSerializer(inner Serializer, algo CompressionAlgo) Serializer {
+	return &compressedSerializer{inner: inner, algo: algo}
+}
+
+// compressedSerializer is a Serializer decorator that compresses payloads
+type compressedSerializer struct {
+	inner Serializer
+	algo  CompressionAlgo
+}
+
+func (s *compressedSerializer) Serialize(v any) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := s.SerializeTo(&buf, v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (s *compressedSerializer) SerializeTo(w io.Writer, v any) error {
+	if w == nil {
+		return errors.New("writer is nil")
+	}
+	c, err := compressorFor(s.algo)
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(append([]byte(compressionMagic), byte(s.algo))); err != nil {
+		return err
+	}
+	cw := c.writer(w)
+	defer c.putWriter(cw)
+	if err := s.inner.SerializeTo(cw, v); err != nil {
+		return err
+	}
+	return cw.Close()
+}
+
+func (s *compressedSerializer) Deserialize(data []byte, v any) error {
+	if !hasCompressionHeader(data) {
+		return s.inner.Deserialize(data, v)
+	}
+	c, err := compressorFor(CompressionAlgo(data[len(compressionMagic)]))
+	if err != nil {
+		return err
+	}
+	cr, err := c.reader(bytes.NewReader(data[compressionHeaderSize:]))
+	if err != nil {
+		return fmt.Errorf("%s: %w", c.algo, err)
+	}
+	defer c.putReader(cr)
+	// Let inner see the whole payload, as Deserialize requires, but stop
+	// decompressing once it is larger than inner accepts
+	var maxSize int64
+	if l, ok := s.inner.(inputLimited); ok {
+		maxSize = l.maxInputSize()
+	}
+	limited, limitErr := limitReader(cr, maxSize)
+	plain, err := io.ReadAll(limited)
+	if err != nil {
+		if err = limitErr(err); errors.Is(err, ErrInputTooLarge) {
+			return err
+		}
+		return fmt.Errorf("%s: %w", c.algo, err)
+	}
+	return s.inner.Deserialize(plain, v)
+}
+
+// DeserializeString implements StringDeserializer interface
+func (s *compressedSerializer) DeserializeString(data string, v any) error {
+	if data == "" {
+		return errors.New("data is empty")
+	}
+	return s.Deserialize(stringToReadOnlyBytes(data), v)
+}
+
+// DeserializeFrom may read past the end of the value, since the header is looked
+// for through a buffered reader
+func (s *compressedSerializer) DeserializeFrom(r io.Reader, v any) error {
+	if r == nil {
+		return errors.New("reader is nil")
+	}
+	br := bufio.NewReader(r)
+	head, err := br.Peek(compressionHeaderSize)
+	if !hasCompressionHeader(head) {
+		if err != nil && err != io.EOF {
+			return err
+		}
+		return s.inner.DeserializeFrom(br, v)
+	}
+	c, err := compressorFor(CompressionAlgo(head[len(compressionMagic)]))
+	if err != nil {
+		return err
+	}
+	br.Discard(compressionHeaderSize)
+	cr, err := c.reader(br)
+	if err != nil {
+		return fmt.Errorf("%s: %w", c.algo, err)
+	}
+	defer c.putReader(cr)
+	return s.inner.DeserializeFrom(cr, v)
+}
+
+func (s *compressedSerializer) ContentType() string {
+	return s.inner.ContentType()
+}
+
+// hasCompressionHeader reports whether data starts with the compressed payload header
+func hasCompressionHeader(data []byte) bool {
+	return len(data) >= compressionHeaderSize && string(data[:len(compressionMagic)]) == compressionMagic
+}
+
+// resettableWriter is a compressing writer that can be reused for another stream
+type resettableWriter interface {
+	io.WriteCloser
+	Reset(w io.Writer)
+}
+
+// resettableReader is a decompressing reader that can be reused for another stream
+type resettableReader interface {
+	io.Reader
+	Reset(r io.Reader) error
+}
+
+// compressor pools the writers and readers of one algorithm
+type compressor struct {
+	algo      CompressionAlgo
+	newWriter func() resettableWriter
+	newReader func() resettableReader
+	writers   sync.Pool
+	readers   sync.Pool
+}
+
+func (c *compressor) writer(w io.Writer) resettableWriter {
+	cw, ok := c.writers.Get().(resettableWriter)
+	if !ok {
+		cw = c.newWriter()
+	}
+	cw.Reset(w)
+	return cw
+}
+
+func (c *compressor) putWriter(cw resettableWriter) {
+	// Drop the reference to the destination
+	cw.Reset(nil)
+	c.writers.Put(cw)
+}
+
+func (c *compressor) reader(r io.Reader) (resettableReader, error) {
+	cr, ok := c.readers.Get().(resettableReader)
+	if !ok {
+		cr = c.newReader()
+	}
+	if err := cr.Reset(r); err != nil {
+		c.readers.Put(cr)
+		return nil, err
+	}
+	return cr, nil
+}
+
+func (c *compressor) putReader(cr resettableReader) {
+	c.readers.Put(cr)
+}
+
+// compressors is indexed by CompressionAlgo
+var compressors = [...]*compressor{
+	CompressionGzip: {
+		algo:      CompressionGzip,
+		newWriter: func() resettableWriter { return gzip.NewWriter(nil) },
+		newReader: func() resettableReader { return new(gzip.Reader) },
+	},
+	CompressionZstd: {
+		algo: CompressionZstd,
+		newWriter: func() resettableWriter {
+			// Only fails on invalid options
+			enc, _ := zstd.NewWriter(nil, zstd.WithEncoderConcurrency(1))
+			return enc
+		},
+		newReader: func() resettableReader {
+			dec, _ := zstd.NewReader(nil, zstd.WithDecoderConcurrency(1))
+			return dec
+		},
+	},
+	CompressionSnappy: {
+		algo:      CompressionSnappy,
+		newWriter: func() resettableWriter { return s2.NewWriter(nil, s2.WriterSnappyCompat(), s2.WriterConcurrency(1)) },
+		newReader: func() resettableReader { return s2Reader{s2.NewReader(nil)} },
+	},
+	CompressionLZ4: {
+		algo:      CompressionLZ4,
+		newWriter: func() resettableWriter { return lz4.NewWriter(nil) },
+		newReader: func() resettableReader { return lz4Reader{lz4.NewReader(nil)} },
+	},
+}
+
+// compressorFor returns the compressor for algo
+func compressorFor(algo CompressionAlgo) (*compressor, error) {
+	if int(algo) >= len(compressors) || compressors[algo] == nil {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownCompression, algo)
+	}
+	return compressors[algo], nil
+}
+
+// s2Reader adapts *s2.Reader, whose Reset cannot fail, to resettableReader
+type s2Reader struct{ *s2.Reader }
+
+func (r s2Reader) Reset(src io.Reader) error {
+	r.Reader.Reset(src)
+	return nil
+}
+
+// lz4Reader adapts *lz4.Reader, whose Reset cannot fail, to resettableReader
+type lz4Reader struct{ *lz4.Reader }
+
+func (r lz4Reader) Reset(src io.Reader) error {
+	r.Reader.Reset(src)
+	return nil
+}
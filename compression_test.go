@@ -0,0 +1,92 @@
+package serializer
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestCompressedSerializer(t *testing.T) {
+	type record struct {
+		Name string   `json:"name" msgpack:"name"`
+		Tags []string `json:"tags" msgpack:"tags"`
+	}
+	want := record{Name: strings.Repeat("compressible ", 100), Tags: []string{"a", "b"}}
+
+	for _, algo := range []CompressionAlgo{CompressionGzip, CompressionZstd, CompressionSnappy, CompressionLZ4} {
+		t.Run(algo.String(), func(t *testing.T) {
+			inner := NewMsgpackSerializer()
+			s := NewCompressedSerializer(inner, algo)
+			if s.ContentType() != inner.ContentType() {
+				t.Errorf("ContentType = %q", s.ContentType())
+			}
+
+			data, err := s.Serialize(want)
+			if err != nil {
+				t.Fatal(err)
+			}
+			plain, _ := inner.Serialize(want)
+			if len(data) >= len(plain) {
+				t.Errorf("compressed to %d bytes from %d", len(data), len(plain))
+			}
+			var got record
+			if err := s.Deserialize(data, &got); err != nil || got.Name != want.Name {
+				t.Errorf("Deserialize: %v", err)
+			}
+
+			// Payloads written without compression still read
+			got = record{}
+			if err := s.Deserialize(plain, &got); err != nil || got.Name != want.Name {
+				t.Errorf("Deserialize uncompressed: %v", err)
+			}
+
+			var buf bytes.Buffer
+			for i := 0; i < 2; i++ {
+				if err := s.SerializeTo(&buf, want); err != nil {
+					t.Fatal(err)
+				}
+				got = record{}
+				if err := s.DeserializeFrom(&buf, &got); err != nil || got.Name != want.Name {
+					t.Errorf("DeserializeFrom: %v", err)
+				}
+				buf.Reset()
+			}
+			got = record{}
+			if err := s.DeserializeFrom(bytes.NewReader(plain), &got); err != nil || got.Name != want.Name {
+				t.Errorf("DeserializeFrom uncompressed: %v", err)
+			}
+
+			got = record{}
+			if err := s.(StringDeserializer).DeserializeString(string(data), &got); err != nil || got.Name != want.Name {
+				t.Errorf("DeserializeString: %v", err)
+			}
+
+			if err := s.Deserialize(data[:len(data)/2], &got); err == nil {
+				t.Error("truncated payload was accepted")
+			}
+		})
+	}
+}
+
+func TestCompressedSerializerMixedAlgorithms(t *testing.T) {
+	gz := NewCompressedSerializer(NewJSONSerializer(MAX_BUF_CAP), CompressionGzip)
+	lz := NewCompressedSerializer(NewJSONSerializer(MAX_BUF_CAP), CompressionLZ4)
+
+	data, err := gz.Serialize(map[string]int{"n": 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got map[string]int
+	if err := lz.Deserialize(data, &got); err != nil || got["n"] != 1 {
+		t.Errorf("got %v, %v", got, err)
+	}
+
+	data[len(compressionMagic)] = 99
+	if err := lz.Deserialize(data, &got); !errors.Is(err, ErrUnknownCompression) {
+		t.Errorf("unknown algorithm: got %v", err)
+	}
+	if _, err := NewCompressedSerializer(NewJSONSerializer(MAX_BUF_CAP), 0).Serialize(1); !errors.Is(err, ErrUnknownCompression) {
+		t.Errorf("zero algorithm: got %v", err)
+	}
+}
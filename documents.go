@@ -0,0 +1,201 @@
+package serializer
+
+import (
+	"bufio"
+	"bytes"
+	stdjson "encoding/json"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// recordSeparator starts each text in an RFC 7464 JSON text sequence
+const recordSeparator = 0x1E
+
+var (
+	_ StreamEncoder = (*DocumentWriter)(nil)
+	_ StreamDecoder = (*DocumentReader)(nil)
+)
+
+// Delimiter selects how consecutive JSON documents are separated in one stream
+type Delimiter int
+
+const (
+	// DelimiterNewline ends each document with '\n' (NDJSON / JSON Lines)
+	DelimiterNewline Delimiter = iota
+	// DelimiterRecordSeparator writes an RFC 7464 json-seq stream
+	// (application/json-seq): each document is preceded by RS (0x1E) and followed by '\n'
+	DelimiterRecordSeparator
+	// DelimiterNone concatenates documents with nothing in between. Readers can
+	// still split objects, arrays and strings, but adjacent numbers run together.
+	DelimiterNone
+)
+
+// DocumentWriter writes a stream of JSON documents separated by a Delimiter.
+// A DocumentWriter is not safe for concurrent use.
+type DocumentWriter struct {
+	w     io.Writer
+	s     Serializer
+	delim Delimiter
+	buf   []byte
+}
+
+// NewDocumentWriter creates a DocumentWriter that serializes values with s and
+// writes them to w. s must produce single-line JSON for the newline and record
+// separator delimiters.
+func NewDocumentWriter(w io.Writer, s Serializer, delim Delimiter) *DocumentWriter {
+	return &DocumentWriter{w: w, s: s, delim: delim}
+}
+
+// Encode serializes v and writes it along with its delimiter in a single write
+func (dw *DocumentWriter) Encode(v any) error {
+	if dw.w == nil {
+		return errors.New("writer is nil")
+	}
+	data, err := dw.s.Serialize(v)
+	if err != nil {
+		return err
+	}
+	data = bytes.TrimRight(data, "\n")
+
+	dw.buf = dw.buf[:0]
+	if dw.delim == DelimiterRecordSeparator {
+		dw.buf = append(dw.buf, recordSeparator)
+	}
+	dw.buf = append(dw.buf, data...)
+	if dw.delim != DelimiterNone {
+		dw.buf = append(dw.buf, '\n')
+	}
+	_, err = dw.w.Write(dw.buf)
+	return err
+}
+
+// Close implements StreamEncoder. Documents are not buffered, so there is nothing to flush.
+func (dw *DocumentWriter) Close() error {
+	return nil
+}
+
+// DocumentReader reads a stream of JSON documents written with the given Delimiter.
+// A DocumentReader is not safe for concurrent use.
+type DocumentReader struct {
+	r               *bufio.Reader
+	s               Serializer
+	delim           Delimiter
+	maxDocumentSize int
+	buf             []byte
+	dec             *stdjson.Decoder
+}
+
+// NewDocumentReader creates a DocumentReader that reads documents from r and
+// deserializes them with s
+func NewDocumentReader(r io.Reader, s Serializer, delim Delimiter) *DocumentReader {
+	br, ok := r.(*bufio.Reader)
+	if !ok && r != nil {
+		br = bufio.NewReader(r)
+	}
+	return &DocumentReader{
+		r:               br,
+		s:               s,
+		delim:           delim,
+		maxDocumentSize: DefaultMaxFrameSize,
+	}
+}
+
+// SetMaxDocumentSize sets the largest document the reader will buffer with the
+// newline and record separator delimiters. If n <= 0, documents are not limited.
+func (dr *DocumentReader) SetMaxDocumentSize(n int) {
+	dr.maxDocumentSize = n
+}
+
+// Decode reads the next document and deserializes it into v. Blank lines and
+// empty records are skipped. It returns io.EOF when the stream ends cleanly.
+func (dr *DocumentReader) Decode(v any) error {
+	if dr.r == nil {
+		return errors.New("reader is nil")
+	}
+	if dr.delim == DelimiterNone {
+		return dr.decodeConcatenated(v)
+	}
+
+	sep := byte('\n')
+	if dr.delim == DelimiterRecordSeparator {
+		sep = recordSeparator
+	}
+	for {
+		record, err := dr.readUntil(sep)
+		if err != nil && err != io.EOF {
+			return err
+		}
+		if doc := bytes.Trim(record, " \t\r\n"); len(doc) > 0 {
+			return dr.s.Deserialize(doc, v)
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// More implements StreamDecoder. It skips the whitespace and separators ahead of
+// the next document.
+func (dr *DocumentReader) More() bool {
+	if dr.r == nil {
+		return false
+	}
+	if dr.dec != nil {
+		return dr.dec.More()
+	}
+	for {
+		next, err := dr.r.Peek(1)
+		if err != nil {
+			return false
+		}
+		switch next[0] {
+		case ' ', '\t', '\r', '\n', recordSeparator:
+			dr.r.Discard(1)
+		default:
+			return true
+		}
+	}
+}
+
+// Close implements StreamDecoder. It does not close the underlying reader.
+func (dr *DocumentReader) Close() error {
+	return nil
+}
+
+// decodeConcatenated splits back-to-back documents with a JSON tokenizer
+func (dr *DocumentReader) decodeConcatenated(v any) error {
+	if dr.dec == nil {
+		dr.dec = stdjson.NewDecoder(dr.r)
+	}
+	var raw stdjson.RawMessage
+	if err := dr.dec.Decode(&raw); err != nil {
+		return err
+	}
+	return dr.s.Deserialize(raw, v)
+}
+
+// readUntil reads up to and excluding the next sep into the reader's scratch buffer.
+// At the end of the stream it returns what is left along with io.EOF.
+func (dr *DocumentReader) readUntil(sep byte) ([]byte, error) {
+	dr.buf = dr.buf[:0]
+	for {
+		chunk, err := dr.r.ReadSlice(sep)
+		dr.buf = append(dr.buf, chunk...)
+		size := len(dr.buf)
+		if err == nil {
+			size-- // the separator itself
+		}
+		if dr.maxDocumentSize > 0 && size > dr.maxDocumentSize {
+			return nil, fmt.Errorf("%w: more than %d bytes", ErrFrameTooLarge, dr.maxDocumentSize)
+		}
+		switch err {
+		case bufio.ErrBufferFull:
+			continue
+		case nil:
+			return dr.buf[:len(dr.buf)-1], nil
+		default:
+			return dr.buf, err
+		}
+	}
+}
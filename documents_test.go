@@ -0,0 +1,95 @@
+package serializer
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func TestDocumentStreamRoundTrip(t *testing.T) {
+	type event struct {
+		ID   int    `json:"id"`
+		Name string `json:"name"`
+	}
+
+	wants := map[Delimiter]string{
+		DelimiterNewline:         "{\"id\":0,\"name\":\"a\\nb\"}\n{\"id\":1,\"name\":\"a\\nb\"}\n",
+		DelimiterRecordSeparator: "\x1e{\"id\":0,\"name\":\"a\\nb\"}\n\x1e{\"id\":1,\"name\":\"a\\nb\"}\n",
+		DelimiterNone:            "{\"id\":0,\"name\":\"a\\nb\"}{\"id\":1,\"name\":\"a\\nb\"}",
+	}
+
+	for delim, want := range wants {
+		var stream bytes.Buffer
+		dw := NewDocumentWriter(&stream, NewJSONSerializer(maxBufferSize), delim)
+		for i := 0; i < 2; i++ {
+			if err := dw.Encode(event{ID: i, Name: "a\nb"}); err != nil {
+				t.Fatalf("delimiter %d: Encode failed: %v", delim, err)
+			}
+		}
+		if stream.String() != want {
+			t.Errorf("delimiter %d: expected %q, got %q", delim, want, stream.String())
+		}
+
+		dr := NewDocumentReader(iotest.OneByteReader(&stream), NewJSONSerializer(maxBufferSize), delim)
+		for i := 0; i < 2; i++ {
+			var got event
+			if err := dr.Decode(&got); err != nil {
+				t.Fatalf("delimiter %d: Decode %d failed: %v", delim, i, err)
+			}
+			if got.ID != i || got.Name != "a\nb" {
+				t.Errorf("delimiter %d: document %d mismatch: %+v", delim, i, got)
+			}
+		}
+		var extra event
+		if err := dr.Decode(&extra); err != io.EOF {
+			t.Errorf("delimiter %d: expected io.EOF, got %v", delim, err)
+		}
+	}
+}
+
+func TestDocumentReaderTolerance(t *testing.T) {
+	s := NewJSONSerializer(maxBufferSize)
+	inputs := map[Delimiter]string{
+		// Blank lines, CRLF and a missing final newline
+		DelimiterNewline: "1\r\n\n  \n2\r\n3",
+		// Empty records and a pretty-printed text
+		DelimiterRecordSeparator: "\x1e\x1e1\n\x1e\n2\n\x1e{\n\"n\": 3\n}\n",
+		DelimiterNone:            "1 2\n{\"n\":3}",
+	}
+	for delim, input := range inputs {
+		dr := NewDocumentReader(strings.NewReader(input), s, delim)
+		count := 0
+		for {
+			var v any
+			err := dr.Decode(&v)
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				t.Fatalf("delimiter %d: Decode failed: %v", delim, err)
+			}
+			count++
+		}
+		if count != 3 {
+			t.Errorf("delimiter %d: expected 3 documents, got %d", delim, count)
+		}
+	}
+}
+
+func TestDocumentReaderMaxSize(t *testing.T) {
+	dr := NewDocumentReader(strings.NewReader(`"`+strings.Repeat("x", 100)+"\"\n"), NewJSONSerializer(maxBufferSize), DelimiterNewline)
+	dr.SetMaxDocumentSize(64)
+	var v string
+	if err := dr.Decode(&v); !errors.Is(err, ErrFrameTooLarge) {
+		t.Errorf("expected ErrFrameTooLarge, got %v", err)
+	}
+
+	dr = NewDocumentReader(strings.NewReader("\"abc\"\n"), NewJSONSerializer(maxBufferSize), DelimiterNewline)
+	dr.SetMaxDocumentSize(5)
+	if err := dr.Decode(&v); err != nil || v != "abc" {
+		t.Errorf("document at the limit should decode, got %q, %v", v, err)
+	}
+}
@@ -0,0 +1,235 @@
+package serializer
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// ErrInvalidEnvelope is returned when a payload has no valid envelope header, or
+// its header does not match the serializer reading it
+var ErrInvalidEnvelope = errors.New("invalid envelope")
+
+// EnvelopeFlags describe how an enveloped payload was written
+type EnvelopeFlags uint8
+
+const (
+	// EnvelopeCompressed marks payloads written through NewCompressedSerializer
+	EnvelopeCompressed EnvelopeFlags = 1 << iota
+)
+
+// knownEnvelopeFlags holds every flag this version understands; payloads with
+// other flags set were written by a newer version and are rejected
+const knownEnvelopeFlags = EnvelopeCompressed
+
+// EnvelopeHeader is the header NewEnvelopeSerializer writes ahead of each payload
+type EnvelopeHeader struct {
+	Format        Format
+	SchemaVersion uint16
+	Flags         EnvelopeFlags
+}
+
+// envelopeMagic starts every enveloped payload. It is followed by the format ID,
+// the big-endian schema version and the flags.
+const envelopeMagic = "\xc1SE"
+
+const envelopeHeaderSize = len(envelopeMagic) + 4
+
+// envelopeFormats maps formats to the IDs that stand for them in headers, and back
+var envelopeFormats = struct {
+	sync.RWMutex
+	ids     map[Format]byte
+	formats map[byte]Format
+}{
+	ids: map[Format]byte{JSON: 1, Binary: 2, Msgpack: 3, CBOR: 4, Protobuf: 5, BSON: 6, Avro: 7, NDJSON: 8},
+}
+
+func init() {
+	envelopeFormats.formats = make(map[byte]Format, len(envelopeFormats.ids))
+	for format, id := range envelopeFormats.ids {
+		envelopeFormats.formats[id] = format
+	}
+}
+
+// RegisterEnvelopeFormat assigns id to a third-party format so its payloads can be
+// enveloped. The built-in formats use IDs 1 to 127; IDs from 128 up are left for
+// other formats. IDs are written into stored payloads, so they must never change.
+func RegisterEnvelopeFormat(format Format, id byte) error {
+	if id < 128 {
+		return fmt.Errorf("envelope format ID %d is reserved", id)
+	}
+	envelopeFormats.Lock()
+	defer envelopeFormats.Unlock()
+	if existing, ok := envelopeFormats.formats[id]; ok {
+		return fmt.Errorf("envelope format ID %d already registered for %s", id, existing)
+	}
+	if existing, ok := envelopeFormats.ids[format]; ok {
+		return fmt.Errorf("format %s already has envelope ID %d", format, existing)
+	}
+	envelopeFormats.ids[format] = id
+	envelopeFormats.formats[id] = format
+	return nil
+}
+
+// ReadEnvelopeHeader parses the envelope header at the start of data and returns
+// it with the payload that follows
+func ReadEnvelopeHeader(data []byte) (EnvelopeHeader, []byte, error) {
+	if len(data) < envelopeHeaderSize || string(data[:len(envelopeMagic)]) != envelopeMagic {
+		return EnvelopeHeader{}, nil, fmt.Errorf("%w: missing header", ErrInvalidEnvelope)
+	}
+	id := data[len(envelopeMagic)]
+	envelopeFormats.RLock()
+	format, ok := envelopeFormats.formats[id]
+	envelopeFormats.RUnlock()
+	if !ok {
+		return EnvelopeHeader{}, nil, fmt.Errorf("%w: unknown format ID %d", ErrInvalidEnvelope, id)
+	}
+	header := EnvelopeHeader{
+		Format:        format,
+		SchemaVersion: binary.BigEndian.Uint16(data[len(envelopeMagic)+1:]),
+		Flags:         EnvelopeFlags(data[envelopeHeaderSize-1]),
+	}
+	if unknown := header.Flags &^ knownEnvelopeFlags; unknown != 0 {
+		return EnvelopeHeader{}, nil, fmt.Errorf("%w: unknown flags %#x", ErrInvalidEnvelope, uint8(unknown))
+	}
+	return header, data[envelopeHeaderSize:], nil
+}
+
+// NewEnvelopeSerializer wraps inner, which writes format, so every payload starts
+// with a 7-byte header naming the format, schemaVersion and flags. Stored blobs then
+// describe themselves: ReadEnvelopeHeader or Registry.DeserializeEnvelope can find
+// the serializer for a payload during a move between formats.
+//
+// Deserialize and DeserializeFrom reject payloads without a header, payloads of
+// another format, and payloads written with a schema version newer than
+// schemaVersion, with errors wrapping ErrInvalidEnvelope. Older versions are
+// accepted. Format must be built in or registered with RegisterEnvelopeFormat.
+func NewEnvelopeSerializer(inner Serializer, format Format, schemaVersion uint16) (Serializer, error) {
+	envelopeFormats.RLock()
+	id, ok := envelopeFormats.ids[format]
+	envelopeFormats.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("format %s has no envelope ID", format)
+	}
+	var flags EnvelopeFlags
+	if _, ok := inner.(*compressedSerializer); ok {
+		flags |= EnvelopeCompressed
+	}
+	header := []byte(envelopeMagic + "\x00\x00\x00\x00")
+	header[len(envelopeMagic)] = id
+	binary.BigEndian.PutUint16(header[len(envelopeMagic)+1:], schemaVersion)
+	header[envelopeHeaderSize-1] = byte(flags)
+	return &envelopeSerializer{inner: inner, format: format, schemaVersion: schemaVersion, header: header}, nil
+}
+
+// envelopeSerializer is a Serializer decorator that writes and checks envelope headers
+type envelopeSerializer struct {
+	inner         Serializer
+	format        Format
+	schemaVersion uint16
+	// header is written ahead of every payload
+	header []byte
+}
+
+func (s *envelopeSerializer) Serialize(v any) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := s.SerializeTo(&buf, v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (s *envelopeSerializer) SerializeTo(w io.Writer, v any) error {
+	if w == nil {
+		return errors.New("writer is nil")
+	}
+	if v == nil {
+		return errors.New("cannot serialize nil value")
+	}
+	if _, err := w.Write(s.header); err != nil {
+		return err
+	}
+	return s.inner.SerializeTo(w, v)
+}
+
+func (s *envelopeSerializer) Deserialize(data []byte, v any) error {
+	if data == nil {
+		return errors.New("data is nil")
+	}
+	header, payload, err := ReadEnvelopeHeader(data)
+	if err != nil {
+		return err
+	}
+	if err := s.check(header); err != nil {
+		return err
+	}
+	return s.inner.Deserialize(payload, v)
+}
+
+// DeserializeString implements StringDeserializer interface
+func (s *envelopeSerializer) DeserializeString(data string, v any) error {
+	if data == "" {
+		return errors.New("data is empty")
+	}
+	return s.Deserialize(stringToReadOnlyBytes(data), v)
+}
+
+// DeserializeFrom reads the header from r, then lets the wrapped serializer read
+// the payload
+func (s *envelopeSerializer) DeserializeFrom(r io.Reader, v any) error {
+	if r == nil {
+		return errors.New("reader is nil")
+	}
+	var head [envelopeHeaderSize]byte
+	if _, err := io.ReadFull(r, head[:]); err != nil {
+		if err == io.ErrUnexpectedEOF {
+			return fmt.Errorf("%w: truncated header", ErrInvalidEnvelope)
+		}
+		return err
+	}
+	header, _, err := ReadEnvelopeHeader(head[:])
+	if err != nil {
+		return err
+	}
+	if err := s.check(header); err != nil {
+		return err
+	}
+	return s.inner.DeserializeFrom(r, v)
+}
+
+// check validates a payload's header against the serializer
+func (s *envelopeSerializer) check(header EnvelopeHeader) error {
+	if header.Format != s.format {
+		return fmt.Errorf("%w: payload is %s, not %s", ErrInvalidEnvelope, header.Format, s.format)
+	}
+	if header.SchemaVersion > s.schemaVersion {
+		return fmt.Errorf("%w: payload schema version %d is newer than %d", ErrInvalidEnvelope, header.SchemaVersion, s.schemaVersion)
+	}
+	return nil
+}
+
+func (s *envelopeSerializer) ContentType() string {
+	return s.inner.ContentType()
+}
+
+// DeserializeEnvelope decodes an enveloped payload with the serializer registered
+// for the format named in its header, decompressing it first when it is flagged as
+// compressed. The schema version is not checked.
+func (r *Registry) DeserializeEnvelope(data []byte, v any) error {
+	header, payload, err := ReadEnvelopeHeader(data)
+	if err != nil {
+		return err
+	}
+	s, ok := r.Get(header.Format)
+	if !ok {
+		return fmt.Errorf("serializer for format %s not found", header.Format)
+	}
+	if header.Flags&EnvelopeCompressed != 0 {
+		// The compression header names the algorithm
+		s = &compressedSerializer{inner: s}
+	}
+	return s.Deserialize(payload, v)
+}
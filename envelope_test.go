@@ -0,0 +1,142 @@
+package serializer
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"sync"
+	"testing"
+)
+
+func TestEnvelopeSerializer(t *testing.T) {
+	s, err := NewEnvelopeSerializer(NewMsgpackSerializer(), Msgpack, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]any{"id": "a1"}
+	data, err := s.Serialize(want)
+	if err != nil {
+		t.Fatal(err)
+	}
+	header, payload, err := ReadEnvelopeHeader(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if header != (EnvelopeHeader{Format: Msgpack, SchemaVersion: 3}) {
+		t.Errorf("header = %+v", header)
+	}
+	if plain, _ := NewMsgpackSerializer().Serialize(want); !bytes.Equal(payload, plain) {
+		t.Errorf("payload = % x, want % x", payload, plain)
+	}
+
+	var got map[string]any
+	if err := s.Deserialize(data, &got); err != nil || got["id"] != "a1" {
+		t.Errorf("Deserialize: got %v, %v", got, err)
+	}
+	got = nil
+	if err := s.(StringDeserializer).DeserializeString(string(data), &got); err != nil || got["id"] != "a1" {
+		t.Errorf("DeserializeString: got %v, %v", got, err)
+	}
+
+	// Values read from a stream leave the rest of it in place
+	var buf bytes.Buffer
+	for i := 0; i < 2; i++ {
+		if err := s.SerializeTo(&buf, want); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for i := 0; i < 2; i++ {
+		got = nil
+		if err := s.DeserializeFrom(&buf, &got); err != nil || got["id"] != "a1" {
+			t.Errorf("DeserializeFrom %d: got %v, %v", i, got, err)
+		}
+	}
+	if err := s.DeserializeFrom(&buf, &got); err != io.EOF {
+		t.Errorf("DeserializeFrom at end: got %v, want io.EOF", err)
+	}
+
+	// Older schema versions are accepted, newer ones are not
+	older, _ := NewEnvelopeSerializer(NewMsgpackSerializer(), Msgpack, 2)
+	if err := older.Deserialize(data, &got); !errors.Is(err, ErrInvalidEnvelope) {
+		t.Errorf("newer version: got %v", err)
+	}
+	newer, _ := NewEnvelopeSerializer(NewMsgpackSerializer(), Msgpack, 4)
+	if err := newer.Deserialize(data, &got); err != nil {
+		t.Errorf("older version: %v", err)
+	}
+}
+
+func TestEnvelopeSerializerInvalid(t *testing.T) {
+	s, err := NewEnvelopeSerializer(NewJSONSerializer(0), JSON, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := s.Serialize(map[string]int{"n": 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got map[string]int
+	invalid := map[string][]byte{
+		"no header":      []byte(`{"n":1}`),
+		"truncated":      data[:envelopeHeaderSize-1],
+		"other format":   append([]byte(envelopeMagic+"\x03\x00\x01\x00"), data[envelopeHeaderSize:]...),
+		"unknown format": append([]byte(envelopeMagic+"\x7f\x00\x01\x00"), data[envelopeHeaderSize:]...),
+		"unknown flags":  append([]byte(envelopeMagic+"\x01\x00\x01\x80"), data[envelopeHeaderSize:]...),
+	}
+	for name, data := range invalid {
+		if err := s.Deserialize(data, &got); !errors.Is(err, ErrInvalidEnvelope) {
+			t.Errorf("%s: Deserialize got %v", name, err)
+		}
+		if err := s.DeserializeFrom(bytes.NewReader(data), &got); !errors.Is(err, ErrInvalidEnvelope) {
+			t.Errorf("%s: DeserializeFrom got %v", name, err)
+		}
+	}
+
+	if _, err := NewEnvelopeSerializer(NewJSONSerializer(0), "yaml", 1); err == nil {
+		t.Error("format without an ID was accepted")
+	}
+	if err := RegisterEnvelopeFormat("yaml", 7); err == nil {
+		t.Error("reserved ID was accepted")
+	}
+}
+
+// registerTestEnvelope registers a format once, however often the tests run
+var registerTestEnvelope = sync.OnceValue(func() error {
+	return RegisterEnvelopeFormat("test-envelope", 200)
+})
+
+func TestRegistryDeserializeEnvelope(t *testing.T) {
+	if err := registerTestEnvelope(); err != nil {
+		t.Fatal(err)
+	}
+	if err := RegisterEnvelopeFormat("test-envelope-2", 200); err == nil {
+		t.Error("duplicate ID was accepted")
+	}
+
+	r := NewRegistry()
+	r.Register(JSON, NewJSONSerializer(0))
+	r.Register(Msgpack, NewMsgpackSerializer())
+	r.Register("test-envelope", NewCBORSerializer())
+
+	writers := map[string]Serializer{}
+	writers["json"], _ = NewEnvelopeSerializer(NewJSONSerializer(0), JSON, 1)
+	writers["compressed msgpack"], _ = NewEnvelopeSerializer(NewCompressedSerializer(NewMsgpackSerializer(), CompressionZstd), Msgpack, 2)
+	writers["registered"], _ = NewEnvelopeSerializer(NewCBORSerializer(), "test-envelope", 1)
+	for name, w := range writers {
+		data, err := w.Serialize(map[string]string{"k": "v"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got map[string]string
+		if err := r.DeserializeEnvelope(data, &got); err != nil || got["k"] != "v" {
+			t.Errorf("%s: got %v, %v", name, got, err)
+		}
+	}
+
+	data, _ := writers["compressed msgpack"].Serialize(1)
+	header, _, _ := ReadEnvelopeHeader(data)
+	if header.Flags != EnvelopeCompressed {
+		t.Errorf("flags = %#x", header.Flags)
+	}
+}
@@ -1,7 +1,8 @@
+//go:build !tinygo
+
 package main
 
 import (
-	"encoding/gob"
 	"fmt"
 	"time"
 
@@ -13,17 +14,13 @@ const (
 )
 
 func main() {
-	// Register types with gob to allow serialization
-	gob.Register(time.Time{})
-	gob.Register(map[string]int{})
-	gob.Register(map[string]any{})
-
 	// Create a registry
 	registry := serializer.NewRegistry()
 
 	// Register serializers
 	registry.Register(serializer.JSON, serializer.NewJSONSerializer(maxBufferSize))
-	registry.Register(serializer.Binary, serializer.NewGobSerializer())
+	// Gob needs the types held in the map's interface values registered
+	registry.Register(serializer.Binary, serializer.NewGobSerializerWithTypes(time.Time{}, map[string]int{}, map[string]any{}))
 	registry.Register(serializer.Msgpack, serializer.NewMsgpackSerializer())
 
 	// Test data with various types
@@ -0,0 +1,85 @@
+package serializer
+
+import (
+	"expvar"
+	"sync"
+	"time"
+)
+
+// ExpvarStats publishes operation and pool statistics through expvar,
+// making them visible at /debug/vars for services without Prometheus.
+//
+// The published value is a map keyed by format, for example:
+//
+//	{"json": {"serialize": 10, "serialize_errors": 0, "serialize_bytes": 2048,
+//	          "pool": {"Gets": 10, "Misses": 1, "Puts": 10, "Discards": 0}}}
+type ExpvarStats struct {
+	root *expvar.Map
+
+	mu      sync.Mutex
+	formats map[Format]*expvar.Map
+}
+
+var _ OperationObserver = (*ExpvarStats)(nil)
+
+// PublishExpvar publishes serializer statistics under name.
+// Calling it again with the same name returns the already published stats.
+func PublishExpvar(name string) *ExpvarStats {
+	publishMu.Lock()
+	defer publishMu.Unlock()
+
+	if stats, ok := publishedStats[name]; ok {
+		return stats
+	}
+	stats := &ExpvarStats{
+		root:    expvar.NewMap(name),
+		formats: make(map[Format]*expvar.Map),
+	}
+	publishedStats[name] = stats
+	return stats
+}
+
+var (
+	publishMu      sync.Mutex
+	publishedStats = make(map[string]*ExpvarStats)
+)
+
+// Wrap returns a serializer whose operations are counted under format.
+// If s pools buffers, its pool statistics are published as well.
+func (e *ExpvarStats) Wrap(format Format, s Serializer) Serializer {
+	if provider, ok := s.(PoolStatsProvider); ok {
+		e.WatchPool(format, provider)
+	}
+	return Observe(s, format, e)
+}
+
+// WatchPool publishes the pool statistics of provider under format
+func (e *ExpvarStats) WatchPool(format Format, provider PoolStatsProvider) {
+	e.formatMap(format).Set("pool", expvar.Func(func() any {
+		return provider.PoolStats()
+	}))
+}
+
+// ObserveOperation implements OperationObserver
+func (e *ExpvarStats) ObserveOperation(format Format, op string, bytes int, _ time.Duration, err error) {
+	m := e.formatMap(format)
+	m.Add(op, 1)
+	m.Add(op+"_bytes", int64(bytes))
+	if err != nil {
+		m.Add(op+"_errors", 1)
+	}
+}
+
+// formatMap returns the per-format map, creating it on first use
+func (e *ExpvarStats) formatMap(format Format) *expvar.Map {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	m, ok := e.formats[format]
+	if !ok {
+		m = new(expvar.Map).Init()
+		e.formats[format] = m
+		e.root.Set(string(format), m)
+	}
+	return m
+}
@@ -0,0 +1,37 @@
+package serializer
+
+import (
+	"expvar"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestExpvarStats(t *testing.T) {
+	stats := PublishExpvar("serializer_test_stats")
+	if PublishExpvar("serializer_test_stats") != stats {
+		t.Fatal("publishing the same name twice should return the same stats")
+	}
+
+	s := stats.Wrap(JSON, NewJSONSerializer(maxBufferSize))
+	data, err := s.Serialize(map[string]int{"a": 1})
+	if err != nil {
+		t.Fatalf("Serialize failed: %v", err)
+	}
+	var v map[string]int
+	if err := s.Deserialize([]byte("{"), &v); err == nil {
+		t.Fatal("expected deserialize error")
+	}
+
+	published := expvar.Get("serializer_test_stats").String()
+	for _, want := range []string{
+		`"serialize": 1`,
+		`"serialize_bytes": ` + strconv.Itoa(len(data)),
+		`"deserialize_errors": 1`,
+		`"pool": {"Gets":1`,
+	} {
+		if !strings.Contains(published, want) {
+			t.Errorf("published stats missing %s: %s", want, published)
+		}
+	}
+}
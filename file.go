@@ -0,0 +1,112 @@
+package serializer
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// extensionFormats maps file extensions to the format used for them
+var extensionFormats = map[string]Format{
+	".json":    JSON,
+	".msgpack": Msgpack,
+	".mpk":     Msgpack,
+	".msgp":    Msgpack,
+	".gob":     Binary,
+	".cbor":    CBOR,
+	".pb":      Protobuf,
+	".binpb":   Protobuf,
+	".bson":    BSON,
+	".ndjson":  NDJSON,
+	".jsonl":   NDJSON,
+}
+
+// FormatForPath infers the serialization format from a file extension
+func FormatForPath(path string) (Format, bool) {
+	providers.RLock()
+	defer providers.RUnlock()
+	format, ok := extensionFormats[strings.ToLower(filepath.Ext(path))]
+	return format, ok
+}
+
+// SaveToFile atomically writes v to path using the given format.
+// The value is written to a temporary file in the same directory, synced,
+// and renamed over path, so readers never observe a partially written file.
+// If format is empty it is inferred from the file extension.
+func SaveToFile(path string, v any, format Format, perm os.FileMode) (err error) {
+	s, err := fileSerializer(path, format)
+	if err != nil {
+		return err
+	}
+
+	dir, base := filepath.Split(path)
+	if dir == "" {
+		dir = "."
+	}
+	tmp, err := os.CreateTemp(dir, "."+base+".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tmp.Close()
+			os.Remove(tmp.Name())
+		}
+	}()
+
+	w := bufio.NewWriter(tmp)
+	if err = s.SerializeTo(w, v); err != nil {
+		return fmt.Errorf("save %s: %w", path, err)
+	}
+	if err = w.Flush(); err != nil {
+		return err
+	}
+	if err = tmp.Sync(); err != nil {
+		return err
+	}
+	if err = tmp.Chmod(perm); err != nil {
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// LoadFromFile reads path into v, inferring the format from the file extension
+func LoadFromFile(path string, v any) error {
+	return LoadFromFileWithFormat(path, v, "")
+}
+
+// LoadFromFileWithFormat reads path into v using the given format.
+// If format is empty it is inferred from the file extension.
+func LoadFromFileWithFormat(path string, v any, format Format) error {
+	s, err := fileSerializer(path, format)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if err := s.DeserializeFrom(bufio.NewReader(f), v); err != nil {
+		return fmt.Errorf("load %s: %w", path, err)
+	}
+	return nil
+}
+
+// fileSerializer resolves the serializer for a file operation
+func fileSerializer(path string, format Format) (Serializer, error) {
+	if format == "" {
+		var ok bool
+		if format, ok = FormatForPath(path); !ok {
+			return nil, fmt.Errorf("cannot infer format from file extension %q", filepath.Ext(path))
+		}
+	}
+	return DefaultRegistry.New(format)
+}
@@ -0,0 +1,99 @@
+package serializer_test
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/MichaelAJay/go-serializer"
+)
+
+type fileSnapshot struct {
+	Version int            `json:"version" msgpack:"version"`
+	Counts  map[string]int `json:"counts" msgpack:"counts"`
+}
+
+func TestSaveAndLoadFile(t *testing.T) {
+	dir := t.TempDir()
+	want := fileSnapshot{Version: 3, Counts: map[string]int{"a": 1, "b": 2}}
+
+	names := []string{"state.json", "state.msgpack"}
+	for range gobFormats() {
+		names = append(names, "state.gob")
+	}
+	for _, name := range names {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(dir, name)
+			if err := serializer.SaveToFile(path, want, "", 0o600); err != nil {
+				t.Fatalf("SaveToFile failed: %v", err)
+			}
+
+			info, err := os.Stat(path)
+			if err != nil {
+				t.Fatalf("Stat failed: %v", err)
+			}
+			if info.Mode().Perm() != 0o600 {
+				t.Errorf("expected mode 0600, got %v", info.Mode().Perm())
+			}
+
+			var got fileSnapshot
+			if err := serializer.LoadFromFile(path, &got); err != nil {
+				t.Fatalf("LoadFromFile failed: %v", err)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("round trip mismatch: got %+v, want %+v", got, want)
+			}
+		})
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("ReadDir failed: %v", err)
+	}
+	if len(entries) != len(names) {
+		t.Errorf("expected only the saved files to remain, found %d entries", len(entries))
+	}
+}
+
+func TestSaveToFileExplicitFormat(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.dat")
+	if err := serializer.SaveToFile(path, "hello", serializer.Msgpack, 0o644); err != nil {
+		t.Fatalf("SaveToFile failed: %v", err)
+	}
+
+	var got string
+	if err := serializer.LoadFromFile(path, &got); err == nil {
+		t.Error("expected error inferring format from unknown extension")
+	}
+	if err := serializer.LoadFromFileWithFormat(path, &got, serializer.Msgpack); err != nil {
+		t.Fatalf("LoadFromFileWithFormat failed: %v", err)
+	}
+	if got != "hello" {
+		t.Errorf("expected hello, got %q", got)
+	}
+}
+
+func TestSaveToFileFailureKeepsOriginal(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "state.json")
+	if err := os.WriteFile(path, []byte(`{"version":1}`), 0o644); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+
+	if err := serializer.SaveToFile(path, make(chan int), "", 0o644); err == nil {
+		t.Fatal("expected error serializing a channel")
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile failed: %v", err)
+	}
+	if string(data) != `{"version":1}` {
+		t.Errorf("original file was modified: %s", data)
+	}
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 1 {
+		t.Errorf("temporary file was not cleaned up, found %d entries", len(entries))
+	}
+}
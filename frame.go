@@ -0,0 +1,171 @@
+package serializer
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+)
+
+const (
+	// DefaultMaxFrameSize is the default upper bound for a single frame payload
+	DefaultMaxFrameSize = 16 << 20 // 16MB
+)
+
+// ErrFrameTooLarge is returned when a frame exceeds the configured maximum size
+var ErrFrameTooLarge = errors.New("frame exceeds maximum size")
+
+var (
+	_ StreamEncoder = (*FrameWriter)(nil)
+	_ StreamDecoder = (*FrameReader)(nil)
+)
+
+// FrameWriter writes serialized values as varint-length-prefixed frames.
+// It is intended for raw stream transports such as TCP or Unix sockets.
+// A FrameWriter is not safe for concurrent use.
+type FrameWriter struct {
+	w            io.Writer
+	s            Serializer
+	maxFrameSize int
+	buf          []byte
+}
+
+// NewFrameWriter creates a FrameWriter that serializes values with s and writes them to w
+func NewFrameWriter(w io.Writer, s Serializer) *FrameWriter {
+	return &FrameWriter{
+		w:            w,
+		s:            s,
+		maxFrameSize: DefaultMaxFrameSize,
+	}
+}
+
+// SetMaxFrameSize sets the largest payload the writer will emit.
+// If n <= 0, frames are not limited.
+func (fw *FrameWriter) SetMaxFrameSize(n int) {
+	fw.maxFrameSize = n
+}
+
+// Encode serializes v and writes it as a single frame.
+// The length prefix and payload are written with one call to the underlying writer.
+func (fw *FrameWriter) Encode(v any) error {
+	if fw.w == nil {
+		return errors.New("writer is nil")
+	}
+	data, err := fw.s.Serialize(v)
+	if err != nil {
+		return err
+	}
+	if fw.maxFrameSize > 0 && len(data) > fw.maxFrameSize {
+		return fmt.Errorf("%w: %d > %d bytes", ErrFrameTooLarge, len(data), fw.maxFrameSize)
+	}
+
+	fw.buf = binary.AppendUvarint(fw.buf[:0], uint64(len(data)))
+	fw.buf = append(fw.buf, data...)
+	_, err = fw.w.Write(fw.buf)
+	return err
+}
+
+// Close implements StreamEncoder. Frames are not buffered, so there is nothing to flush.
+func (fw *FrameWriter) Close() error {
+	return nil
+}
+
+// FrameReader reads varint-length-prefixed frames written by FrameWriter.
+// A FrameReader is not safe for concurrent use.
+type FrameReader struct {
+	r            *bufio.Reader
+	s            Serializer
+	maxFrameSize int
+	buf          []byte
+}
+
+// NewFrameReader creates a FrameReader that reads frames from r and deserializes them with s
+func NewFrameReader(r io.Reader, s Serializer) *FrameReader {
+	br, ok := r.(*bufio.Reader)
+	if !ok && r != nil {
+		br = bufio.NewReader(r)
+	}
+	return &FrameReader{
+		r:            br,
+		s:            s,
+		maxFrameSize: DefaultMaxFrameSize,
+	}
+}
+
+// SetMaxFrameSize sets the largest payload the reader will accept.
+// If n <= 0, frames are not limited.
+func (fr *FrameReader) SetMaxFrameSize(n int) {
+	fr.maxFrameSize = n
+}
+
+// Decode reads the next frame and deserializes it into v.
+// It returns io.EOF when the stream ends cleanly between frames and
+// io.ErrUnexpectedEOF when it ends in the middle of a frame.
+func (fr *FrameReader) Decode(v any) error {
+	data, err := fr.next()
+	if err != nil {
+		return err
+	}
+	return fr.s.Deserialize(data, v)
+}
+
+// More implements StreamDecoder
+func (fr *FrameReader) More() bool {
+	if fr.r == nil {
+		return false
+	}
+	_, err := fr.r.Peek(1)
+	return err == nil
+}
+
+// Close implements StreamDecoder. It does not close the underlying reader.
+func (fr *FrameReader) Close() error {
+	return nil
+}
+
+// next reads one frame payload into the reader's scratch buffer
+func (fr *FrameReader) next() ([]byte, error) {
+	if fr.r == nil {
+		return nil, errors.New("reader is nil")
+	}
+	size, err := binary.ReadUvarint(fr.r)
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, io.EOF
+		}
+		return nil, err
+	}
+	if fr.maxFrameSize > 0 && size > uint64(fr.maxFrameSize) {
+		return nil, fmt.Errorf("%w: %d > %d bytes", ErrFrameTooLarge, size, fr.maxFrameSize)
+	}
+
+	if size > math.MaxInt64 {
+		return nil, fmt.Errorf("%w: %d bytes", ErrFrameTooLarge, size)
+	}
+
+	if uint64(cap(fr.buf)) >= size {
+		fr.buf = fr.buf[:size]
+		if _, err := io.ReadFull(fr.r, fr.buf); err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil, io.ErrUnexpectedEOF
+			}
+			return nil, err
+		}
+		return fr.buf, nil
+	}
+
+	// The length prefix comes from the peer, so the buffer grows only as the
+	// payload arrives rather than being allocated up front
+	buf := bytes.NewBuffer(fr.buf[:0])
+	if _, err := io.CopyN(buf, fr.r, int64(size)); err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+	fr.buf = buf.Bytes()
+	return fr.buf, nil
+}
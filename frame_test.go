@@ -0,0 +1,116 @@
+package serializer
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"runtime"
+	"testing"
+	"testing/iotest"
+)
+
+func TestFrameRoundTrip(t *testing.T) {
+	type message struct {
+		ID   int    `json:"id" msgpack:"id"`
+		Body string `json:"body" msgpack:"body"`
+	}
+
+	serializers := append([]Serializer{
+		NewJSONSerializer(maxBufferSize),
+		NewMsgpackSerializer(),
+	}, gobTestSerializers()...)
+
+	for _, s := range serializers {
+		t.Run(s.ContentType(), func(t *testing.T) {
+			var stream bytes.Buffer
+			fw := NewFrameWriter(&stream, s)
+			for i := 0; i < 3; i++ {
+				if err := fw.Encode(message{ID: i, Body: "hello"}); err != nil {
+					t.Fatalf("Encode failed: %v", err)
+				}
+			}
+
+			// Deliver the stream one byte at a time to exercise partial reads
+			fr := NewFrameReader(iotest.OneByteReader(&stream), s)
+			for i := 0; i < 3; i++ {
+				var got message
+				if err := fr.Decode(&got); err != nil {
+					t.Fatalf("Decode %d failed: %v", i, err)
+				}
+				if got.ID != i || got.Body != "hello" {
+					t.Errorf("frame %d mismatch: %+v", i, got)
+				}
+			}
+
+			var extra message
+			if err := fr.Decode(&extra); err != io.EOF {
+				t.Errorf("expected io.EOF at end of stream, got %v", err)
+			}
+		})
+	}
+}
+
+func TestFrameTruncated(t *testing.T) {
+	s := NewMsgpackSerializer()
+	var stream bytes.Buffer
+	if err := NewFrameWriter(&stream, s).Encode("payload"); err != nil {
+		t.Fatalf("Encode failed: %v", err)
+	}
+
+	truncated := stream.Bytes()[:stream.Len()-2]
+	var got string
+	err := NewFrameReader(bytes.NewReader(truncated), s).Decode(&got)
+	if err != io.ErrUnexpectedEOF {
+		t.Errorf("expected io.ErrUnexpectedEOF, got %v", err)
+	}
+}
+
+func TestFrameMaxSize(t *testing.T) {
+	s := NewMsgpackSerializer()
+	large := string(make([]byte, 1024))
+
+	var stream bytes.Buffer
+	fw := NewFrameWriter(&stream, s)
+	fw.SetMaxFrameSize(100)
+	if err := fw.Encode(large); !errors.Is(err, ErrFrameTooLarge) {
+		t.Errorf("expected ErrFrameTooLarge from writer, got %v", err)
+	}
+	if stream.Len() != 0 {
+		t.Errorf("expected nothing written for rejected frame, got %d bytes", stream.Len())
+	}
+
+	fw.SetMaxFrameSize(0)
+	if err := fw.Encode(large); err != nil {
+		t.Fatalf("Encode without limit failed: %v", err)
+	}
+
+	fr := NewFrameReader(&stream, s)
+	fr.SetMaxFrameSize(100)
+	var got string
+	if err := fr.Decode(&got); !errors.Is(err, ErrFrameTooLarge) {
+		t.Errorf("expected ErrFrameTooLarge from reader, got %v", err)
+	}
+}
+
+func TestFrameUnlimitedHeaderDoesNotAllocate(t *testing.T) {
+	// A header claiming 1 GiB followed by a few bytes of payload
+	header := binary.AppendUvarint(nil, 1<<30)
+	stream := append(header, "short"...)
+
+	fr := NewFrameReader(bytes.NewReader(stream), NewMsgpackSerializer())
+	fr.SetMaxFrameSize(0)
+
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	var got string
+	err := fr.Decode(&got)
+	runtime.ReadMemStats(&after)
+
+	if err != io.ErrUnexpectedEOF {
+		t.Errorf("expected io.ErrUnexpectedEOF, got %v", err)
+	}
+	if allocated := after.TotalAlloc - before.TotalAlloc; allocated > 1<<20 {
+		t.Errorf("reading a truncated frame allocated %d bytes", allocated)
+	}
+}
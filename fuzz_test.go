@@ -0,0 +1,39 @@
+package serializer_test
+
+import (
+	"testing"
+	"time"
+
+	serializer "github.com/MichaelAJay/go-serializer"
+	"github.com/MichaelAJay/go-serializer/serializertest"
+)
+
+// fuzzRecord has a field of each kind the decoders treat differently
+type fuzzRecord struct {
+	ID      int64             `json:"id" msgpack:"id"`
+	Name    string            `json:"name" msgpack:"name"`
+	Score   float64           `json:"score" msgpack:"score"`
+	Active  bool              `json:"active" msgpack:"active"`
+	Tags    []string          `json:"tags" msgpack:"tags"`
+	Labels  map[string]string `json:"labels" msgpack:"labels"`
+	Data    []byte            `json:"data" msgpack:"data"`
+	When    time.Time         `json:"when" msgpack:"when"`
+	Next    *fuzzRecord       `json:"next" msgpack:"next"`
+	Payload any               `json:"payload" msgpack:"payload"`
+}
+
+func fuzzTargets() []func() any {
+	return []func() any{
+		func() any { return new(any) },
+		func() any { return new(map[string]any) },
+		func() any { return new(fuzzRecord) },
+	}
+}
+
+func FuzzJSONDeserialize(f *testing.F) {
+	serializertest.FuzzDeserialize(f, serializer.NewJSONSerializer(0), fuzzTargets()...)
+}
+
+func FuzzMsgpackDeserialize(f *testing.F) {
+	serializertest.FuzzDeserialize(f, serializer.NewMsgpackSerializer(), fuzzTargets()...)
+}
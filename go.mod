@@ -5,8 +5,21 @@ go 1.23.3
 require github.com/vmihailenco/msgpack/v5 v5.4.1
 
 require (
+	github.com/fxamacker/cbor/v2 v2.9.2
+	github.com/hamba/avro/v2 v2.27.0
 	github.com/json-iterator/go v1.1.12
-	github.com/modern-go/concurrent v0.0.0-20180228061459-e0a39a4cb421 // indirect
-	github.com/modern-go/reflect2 v1.0.2 // indirect
+	github.com/klauspost/compress v1.18.0
+	github.com/modern-go/reflect2 v1.0.2
+	github.com/pierrec/lz4/v4 v4.1.22
+	go.mongodb.org/mongo-driver v1.17.6
+	google.golang.org/protobuf v1.36.5
+)
+
+require (
+	github.com/google/go-cmp v0.7.0 // indirect
+	github.com/mitchellh/mapstructure v1.5.0 // indirect
+	github.com/modern-go/concurrent v0.0.0-20180306012644-bacd9c7ef1dd // indirect
+	github.com/stretchr/testify v1.10.0 // indirect
 	github.com/vmihailenco/tagparser/v2 v2.0.0 // indirect
+	github.com/x448/float16 v0.8.4 // indirect
 )
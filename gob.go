@@ -1,3 +1,5 @@
+//go:build !tinygo
+
 package serializer
 
 import (
@@ -17,21 +19,114 @@ var (
 	registrationMu  sync.RWMutex
 )
 
+// gobBufferPool holds the buffers handed out by GobSerializer.SerializePooled
+var gobBufferPool = newPooledBufferPool(MAX_BUF_CAP)
+
+var _ PooledSerializer = (*GobSerializer)(nil)
+
 // GobSerializer implements Serializer using Gob encoding
-type GobSerializer struct{}
+type GobSerializer struct {
+	opts options
+}
 
 // NewGobSerializer creates a new Gob serializer
 func NewGobSerializer() Serializer {
 	return &GobSerializer{}
 }
 
+// registerDefaultGob adds gob to DefaultRegistry as the Binary format
+func registerDefaultGob() {
+	DefaultRegistry.Register(Binary, NewGobSerializer())
+}
+
+// NewGobSerializerWithTypes creates a Gob serializer after registering types, as
+// PreRegisterTypes does, in place of gob.Register calls at startup. Samples of the
+// types values held in interfaces may have belong here, e.g. time.Time{} or
+// map[string]any{}.
+func NewGobSerializerWithTypes(types ...any) Serializer {
+	PreRegisterTypes(types...)
+	return NewGobSerializer()
+}
+
+// NewGobEnvelopeSerializer creates a Gob serializer that writes the name a value's
+// type was registered under with RegisterGobType ahead of the value, so readers
+// can decode payloads with DeserializeAny without knowing their type in advance.
+// Serializing a value of an unregistered type fails with ErrUnknownType.
+func NewGobEnvelopeSerializer() Serializer {
+	return NewGobSerializerWithOptions(WithGobEnvelope())
+}
+
+// encode writes v with enc, preceded by its type name in envelope mode
+func (s *GobSerializer) encode(enc *gob.Encoder, v any) error {
+	if s.opts.gobEnvelope {
+		name, ok := gobTypes.NameOf(v)
+		if !ok && s.opts.gobTypeNamer != nil && !s.opts.gobStrict {
+			registerTypeIfNeeded(reflect.TypeOf(v), s.opts.gobTypeNamer)
+			name, ok = gobTypes.NameOf(v)
+		}
+		if !ok {
+			return fmt.Errorf("%w: %T", ErrUnknownType, v)
+		}
+		if err := enc.Encode(name); err != nil {
+			return err
+		}
+	}
+	return enc.Encode(v)
+}
+
+// decode reads v with dec, skipping the type name in envelope mode
+func (s *GobSerializer) decode(dec *gob.Decoder, v any) error {
+	if s.opts.gobEnvelope {
+		var name string
+		if err := dec.Decode(&name); err != nil {
+			return err
+		}
+	}
+	if err := dec.Decode(v); err != nil {
+		return err
+	}
+	return s.opts.validate(v)
+}
+
+// DeserializeAny decodes an envelope written by a NewGobEnvelopeSerializer
+// serializer into a new value of the type registered under its name, and returns
+// that value (not a pointer to it, unless a pointer sample was registered)
+func (s *GobSerializer) DeserializeAny(data []byte) (any, error) {
+	if !s.opts.gobEnvelope {
+		return nil, errors.New("DeserializeAny requires a serializer created with NewGobEnvelopeSerializer")
+	}
+	if data == nil {
+		return nil, errors.New("data is nil")
+	}
+	var name string
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&name); err != nil {
+		return nil, err
+	}
+	t, ok := gobTypes.TypeOf(name)
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownType, name)
+	}
+	target := reflect.New(t)
+	if err := s.decodeData(data, target.Interface()); err != nil {
+		return nil, fmt.Errorf("gob deserialization failed for type %q: %w", name, err)
+	}
+	return target.Elem().Interface(), nil
+}
+
 func (s *GobSerializer) Serialize(v any) ([]byte, error) {
 	if v == nil {
 		return nil, errors.New("cannot serialize nil value")
 	}
+	if err := s.prepare(v); err != nil {
+		return nil, err
+	}
 	var buf bytes.Buffer
+	buf.Grow(s.opts.gobBufferSize)
 	encoder := gob.NewEncoder(&buf)
-	err := encoder.Encode(v)
+	err := s.encode(encoder, v)
+	if err == nil && s.opts.gobDeterministic {
+		return canonicalGob(buf.Bytes())
+	}
 	return buf.Bytes(), err
 }
 
@@ -39,25 +134,33 @@ func (s *GobSerializer) Deserialize(data []byte, v any) error {
 	if data == nil {
 		return errors.New("data is nil")
 	}
-	buf := bytes.NewBuffer(data)
-	decoder := gob.NewDecoder(buf)
-	return decoder.Decode(v)
+	return s.decodeData(data, v)
 }
 
 func (s *GobSerializer) SerializeTo(w io.Writer, v any) error {
 	if w == nil {
 		return errors.New("writer is nil")
 	}
+	if s.opts.gobDeterministic {
+		data, err := s.Serialize(v)
+		if err == nil {
+			_, err = w.Write(data)
+		}
+		return err
+	}
+	if err := s.prepare(v); err != nil {
+		return err
+	}
 	encoder := gob.NewEncoder(w)
-	return encoder.Encode(v)
+	return s.encode(encoder, v)
 }
 
 func (s *GobSerializer) DeserializeFrom(r io.Reader, v any) error {
 	if r == nil {
 		return errors.New("reader is nil")
 	}
-	decoder := gob.NewDecoder(r)
-	return decoder.Decode(v)
+	limited, limitErr := s.opts.limitInput(r)
+	return limitErr(s.decodeReader(limited, v))
 }
 
 // DeserializeString implements StringDeserializer interface
@@ -66,9 +169,52 @@ func (s *GobSerializer) DeserializeString(data string, v any) error {
 	if data == "" {
 		return errors.New("data is empty")
 	}
-	buf := bytes.NewBuffer(stringToReadOnlyBytes(data))
-	decoder := gob.NewDecoder(buf)
-	return decoder.Decode(v)
+	return s.decodeData(stringToReadOnlyBytes(data), v)
+}
+
+// SerializePooled implements PooledSerializer. It encodes v into a buffer from a
+// pool shared by all gob serializers and hands that buffer out without copying it.
+// The caller must call Release on the returned PooledBuf when done with its bytes.
+func (s *GobSerializer) SerializePooled(v any) (*PooledBuf, error) {
+	if v == nil {
+		return nil, errors.New("cannot serialize nil value")
+	}
+	if err := s.prepare(v); err != nil {
+		return nil, err
+	}
+
+	buf := gobBufferPool.Get()
+	buf.Grow(s.opts.gobBufferSize)
+	err := s.encode(gob.NewEncoder(buf), v)
+	if err == nil && s.opts.gobDeterministic {
+		var canonical []byte
+		if canonical, err = canonicalGob(buf.Bytes()); err == nil {
+			buf.Reset()
+			buf.Write(canonical)
+		}
+	}
+	if err != nil {
+		gobBufferPool.Put(buf)
+		return nil, err
+	}
+	return &PooledBuf{buf: buf, pool: gobBufferPool}, nil
+}
+
+// DeserializeFromPooled implements PooledSerializer. It decodes the bytes of pb
+// without copying them; pb is not released.
+func (s *GobSerializer) DeserializeFromPooled(pb *PooledBuf, v any) error {
+	if pb == nil {
+		return errors.New("PooledBuf is nil")
+	}
+	data := pb.Bytes()
+	if data == nil {
+		return errors.New("PooledBuf contains no data")
+	}
+	return s.decodeData(data, v)
+}
+
+func (s *GobSerializer) maxInputSize() int64 {
+	return s.opts.maxInputSize
 }
 
 func (s *GobSerializer) ContentType() string {
@@ -84,21 +230,38 @@ func (s *GobSerializer) SerializeWithTypeInfo(v any, typeInfo TypeInfo) ([]byte,
 	
 	// Automatically register the type with gob
 	if typeInfo.Type != nil {
-		registerTypeIfNeeded(typeInfo.Type)
+		registerTypeIfNeeded(typeInfo.Type, s.opts.gobTypeNamer)
+	}
+	if err := s.prepare(v); err != nil {
+		return nil, fmt.Errorf("gob serialization failed for type %s: %w", typeInfo.TypeName, err)
+	}
+	
+	if c := s.typeCodec(typeInfo.Type); c != nil {
+		data, err := c.record(v, s.opts.gobDeterministic)
+		if err != nil {
+			return nil, fmt.Errorf("gob serialization failed for type %s: %w", typeInfo.TypeName, err)
+		}
+		return data, nil
 	}
 	
 	var buf bytes.Buffer
+	buf.Grow(s.opts.gobBufferSize)
 	encoder := gob.NewEncoder(&buf)
-	err := encoder.Encode(v)
+	err := s.encode(encoder, v)
 	if err != nil {
 		return nil, fmt.Errorf("gob serialization failed for type %s: %w", typeInfo.TypeName, err)
 	}
+	if s.opts.gobDeterministic {
+		return canonicalGob(buf.Bytes())
+	}
 	return buf.Bytes(), nil
 }
 
-// registerTypeIfNeeded ensures the type is registered with gob
+// registerTypeIfNeeded ensures the type is registered with gob, along with the
+// types reachable through its fields, elements and map keys.
 // We register based on the base type to avoid pointer/value conflicts
-func registerTypeIfNeeded(t reflect.Type) {
+// A non-nil namer picks the name each newly registered type is given.
+func registerTypeIfNeeded(t reflect.Type, namer func(reflect.Type) string) {
 	// Get the base type (element type for pointers)
 	baseType := t
 	if t.Kind() == reflect.Ptr {
@@ -114,18 +277,298 @@ func registerTypeIfNeeded(t reflect.Type) {
 
 	registrationMu.Lock()
 	defer registrationMu.Unlock()
-	
-	// Double-check after acquiring write lock
-	if registeredTypes[baseType] {
+	registerTypeTree(baseType, namer)
+}
+
+// registerTypeTree registers t and walks the types it contains. Types are marked
+// before their contents are walked, so recursive types terminate.
+// Callers hold registrationMu.
+func registerTypeTree(t reflect.Type, namer func(reflect.Type) string) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if registeredTypes[t] {
 		return
 	}
-	
+	registeredTypes[t] = true
+
+	switch t.Kind() {
+	case reflect.Interface, reflect.Func, reflect.Chan, reflect.UnsafePointer:
+		// Interfaces are resolved from values; the others cannot be encoded
+		return
+	}
+	// gob registers the predeclared types itself
+	if t.PkgPath() != "" || t.Name() == "" {
+		registerGobType(t, namer)
+	}
+	registerTypeContents(t, namer)
+}
+
+// registerTypeContents registers the types of t's exported fields, elements and
+// map keys. Callers hold registrationMu.
+func registerTypeContents(t reflect.Type, namer func(reflect.Type) string) {
+	switch t.Kind() {
+	case reflect.Struct:
+		for i := 0; i < t.NumField(); i++ {
+			if f := t.Field(i); f.IsExported() {
+				registerTypeTree(f.Type, namer)
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		registerTypeTree(t.Elem(), namer)
+	case reflect.Map:
+		registerTypeTree(t.Key(), namer)
+		registerTypeTree(t.Elem(), namer)
+	}
+}
+
+// registerGobType registers the value form of t with gob, under the name namer
+// gives it if any. gob panics when a type was already registered under another
+// name, e.g. by the application through gob.RegisterName; that registration is kept.
+func registerGobType(t reflect.Type, namer func(reflect.Type) string) {
 	// Register the base type (as a value) - gob can handle both pointer and value forms
 	// when the value type is registered
-	zeroValue := reflect.New(baseType).Elem().Interface()
-	gob.Register(zeroValue)
-	
-	registeredTypes[baseType] = true
+	sample := reflect.New(t).Elem().Interface()
+	if namer != nil {
+		if name := namer(t); name != "" {
+			if registerGobName(name, sample) == nil {
+				_ = gobTypes.Register(name, sample)
+			}
+			return
+		}
+	}
+	defer func() { _ = recover() }()
+	gob.Register(sample)
+}
+
+// gobTypes holds the names given to types through RegisterGobType
+var gobTypes = NewTypeRegistry()
+
+// RegisterGobType registers the type of sample with gob under a stable name, which
+// gob writes instead of the Go package path when a value of the type is held in an
+// interface. Stored data then survives refactors that move or rename packages, as
+// long as the name stays registered. Types it contains are registered as well.
+//
+// Like gob.RegisterName, registrations are process-wide. Register during program
+// initialization, before values of the type are encoded or decoded: gob keeps the
+// first name it sees for a type, and automatic registration uses the package path.
+func RegisterGobType(name string, sample any) error {
+	if name == "" || sample == nil {
+		return errors.New("gob type name and sample are required")
+	}
+
+	registrationMu.Lock()
+	defer registrationMu.Unlock()
+	// gob rejects every conflict the registry would, and also names that clash
+	// with its own registrations, so it goes first
+	if err := registerGobName(name, sample); err != nil {
+		return err
+	}
+	if err := gobTypes.Register(name, sample); err != nil {
+		return err
+	}
+	t := reflect.TypeOf(sample)
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if !registeredTypes[t] {
+		registeredTypes[t] = true
+		registerTypeContents(t, nil)
+	}
+	return nil
+}
+
+// MustRegisterGobType is like RegisterGobType but panics on error
+func MustRegisterGobType(name string, sample any) {
+	if err := RegisterGobType(name, sample); err != nil {
+		panic(err)
+	}
+}
+
+// registerGobRegistry registers every type in r with gob under its registry name
+func registerGobRegistry(r *TypeRegistry) {
+	r.mu.RLock()
+	types := make([]reflect.Type, 0, len(r.byType))
+	for t := range r.byType {
+		types = append(types, t)
+	}
+	r.mu.RUnlock()
+	for _, t := range types {
+		registerRegistryType(r, t)
+	}
+}
+
+// registerRegistryType registers t with gob under its name in r, unless t was
+// registered already, and reports whether r names t
+func registerRegistryType(r *TypeRegistry, t reflect.Type) bool {
+	name, ok := r.nameOfType(t)
+	if !ok {
+		return false
+	}
+	base := derefType(t)
+	registrationMu.RLock()
+	done := registeredTypes[base]
+	registrationMu.RUnlock()
+	if done {
+		return true
+	}
+
+	registrationMu.Lock()
+	defer registrationMu.Unlock()
+	if registeredTypes[base] {
+		return true
+	}
+	// Register the form the registry holds, so readers decode values or pointers as it does
+	registered, _ := r.TypeOf(name)
+	_ = registerGobName(name, reflect.Zero(registered).Interface())
+	registeredTypes[base] = true
+	registerTypeContents(base, nil)
+	return true
+}
+
+// explicitTypes holds the base types registered through PreRegisterTypes, which
+// strict serializers accept. Guarded by registrationMu.
+var explicitTypes = make(map[reflect.Type]bool)
+
+// PreRegisterTypes registers with gob the types of values, the types they contain,
+// and the dynamic types of the interface values found in them, so nothing is left
+// to register on the first request. gob cannot discover which types implement an
+// interface, so pass a sample of each type stored in interface fields, either on
+// its own or set in a root value. Call it during program initialization; nil
+// values are skipped.
+func PreRegisterTypes(values ...any) {
+	for _, v := range values {
+		if v == nil {
+			continue
+		}
+		markExplicitType(reflect.TypeOf(v))
+		_ = visitInterfaceTypes(v, func(t reflect.Type) error {
+			markExplicitType(t)
+			return nil
+		})
+	}
+}
+
+// markExplicitType registers t and records it as explicitly registered
+func markExplicitType(t reflect.Type) {
+	registerTypeIfNeeded(t, nil)
+	registrationMu.Lock()
+	explicitTypes[derefType(t)] = true
+	registrationMu.Unlock()
+}
+
+// registerGobName calls gob.RegisterName, turning its panics into errors
+func registerGobName(name string, sample any) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("registering gob type %q: %v", name, r)
+		}
+	}()
+	gob.RegisterName(name, sample)
+	return nil
+}
+
+// gobInterfaceHolders caches, per type, whether its values can hold interfaces
+var gobInterfaceHolders sync.Map
+
+// visitInterfaceTypes calls visit with the dynamic type of every interface value
+// reachable from v, which gob needs registered before it can encode them, and
+// stops at the first error. Values whose type cannot hold an interface are not walked.
+func visitInterfaceTypes(v any, visit func(reflect.Type) error) error {
+	if v == nil {
+		return nil
+	}
+	rv := reflect.ValueOf(v)
+	if !canHoldInterface(rv.Type()) {
+		return nil
+	}
+	return walkInterfaceValues(rv, map[uintptr]bool{}, visit)
+}
+
+// canHoldInterface reports, from a cache, whether values of t can hold interfaces
+func canHoldInterface(t reflect.Type) bool {
+	holds, ok := gobInterfaceHolders.Load(t)
+	if !ok {
+		holds = holdsInterface(t, map[reflect.Type]bool{})
+		gobInterfaceHolders.Store(t, holds)
+	}
+	return holds.(bool)
+}
+
+// holdsInterface reports whether values of t can contain interface values
+func holdsInterface(t reflect.Type, seen map[reflect.Type]bool) bool {
+	if seen[t] {
+		return false
+	}
+	seen[t] = true
+	switch t.Kind() {
+	case reflect.Interface:
+		return true
+	case reflect.Ptr, reflect.Slice, reflect.Array:
+		return holdsInterface(t.Elem(), seen)
+	case reflect.Map:
+		return holdsInterface(t.Key(), seen) || holdsInterface(t.Elem(), seen)
+	case reflect.Struct:
+		for i := 0; i < t.NumField(); i++ {
+			if f := t.Field(i); f.IsExported() && holdsInterface(f.Type, seen) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// walkInterfaceValues calls visit with the dynamic types of interface values in v.
+// seen guards against pointer cycles.
+func walkInterfaceValues(v reflect.Value, seen map[uintptr]bool, visit func(reflect.Type) error) error {
+	switch v.Kind() {
+	case reflect.Interface:
+		if v.IsNil() {
+			return nil
+		}
+		if err := visit(v.Elem().Type()); err != nil {
+			return err
+		}
+		return walkInterfaceValues(v.Elem(), seen, visit)
+	case reflect.Ptr:
+		if v.IsNil() || seen[v.Pointer()] {
+			return nil
+		}
+		seen[v.Pointer()] = true
+		return walkInterfaceValues(v.Elem(), seen, visit)
+	case reflect.Slice, reflect.Array:
+		if isScalarKind(v.Type().Elem().Kind()) {
+			return nil
+		}
+		for i := 0; i < v.Len(); i++ {
+			if err := walkInterfaceValues(v.Index(i), seen, visit); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		if isScalarKind(v.Type().Key().Kind()) && isScalarKind(v.Type().Elem().Kind()) {
+			return nil
+		}
+		iter := v.MapRange()
+		for iter.Next() {
+			if err := walkInterfaceValues(iter.Key(), seen, visit); err != nil {
+				return err
+			}
+			if err := walkInterfaceValues(iter.Value(), seen, visit); err != nil {
+				return err
+			}
+		}
+	case reflect.Struct:
+		t := v.Type()
+		for i := 0; i < v.NumField(); i++ {
+			if t.Field(i).IsExported() {
+				if err := walkInterfaceValues(v.Field(i), seen, visit); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
 }
 
 // DeserializeWithTypeInfo implements TypedSerializer interface
@@ -135,44 +578,27 @@ func (s *GobSerializer) DeserializeWithTypeInfo(data []byte, typeInfo TypeInfo)
 		return nil, errors.New("data is nil")
 	}
 	
-	if typeInfo.Type == nil {
-		return nil, errors.New("typeInfo.Type is nil")
+	// Create a new instance of the target type
+	// This gives gob the concrete type it needs for deserialization
+	targetValue, err := newTypedTarget(typeInfo)
+	if err != nil {
+		return nil, err
 	}
 	
 	// Automatically register the type with gob
-	registerTypeIfNeeded(typeInfo.Type)
-	
-	// Create a new instance of the target type
-	// This gives gob the concrete type it needs for deserialization
-	targetValue := reflect.New(typeInfo.Type)
+	registerTypeIfNeeded(typeInfo.Type, s.opts.gobTypeNamer)
 	
-	// Handle pointer types
-	var deserializeTarget any
-	if typeInfo.Type.Kind() == reflect.Ptr {
-		// For pointer types, we need to create the underlying type
-		elemType := typeInfo.Type.Elem()
-		elemValue := reflect.New(elemType)
-		targetValue.Elem().Set(elemValue)
-		deserializeTarget = targetValue.Interface()
+	// Deserialize using the concrete type, behind its definitions for records
+	// written without them
+	if c := s.typeCodec(typeInfo.Type); c != nil && !startsWithGobDefinition(data) {
+		err = s.decodeReader(io.MultiReader(bytes.NewReader(c.header), bytes.NewReader(data)), targetValue.Interface())
 	} else {
-		// For non-pointer types, use the pointer to the new instance
-		deserializeTarget = targetValue.Interface()
+		err = s.decodeData(data, targetValue.Interface())
 	}
-	
-	// Deserialize using the concrete type
-	buf := bytes.NewBuffer(data)
-	decoder := gob.NewDecoder(buf)
-	err := decoder.Decode(deserializeTarget)
 	if err != nil {
 		return nil, fmt.Errorf("gob deserialization failed for type %s: %w (hint: check for pointer/value type mismatches)", typeInfo.TypeName, err)
 	}
 	
-	// Return the correct value based on the original type
-	if typeInfo.Type.Kind() == reflect.Ptr {
-		// For pointer types, return the pointer
-		return targetValue.Elem().Interface(), nil
-	} else {
-		// For non-pointer types, return the dereferenced value
-		return targetValue.Elem().Interface(), nil
-	}
+	// Pointer types get the pointer back, value types the dereferenced value
+	return targetValue.Elem().Interface(), nil
 }
@@ -0,0 +1,90 @@
+//go:build !tinygo
+
+package serializer_test
+
+import (
+	"testing"
+
+	"github.com/MichaelAJay/go-serializer"
+)
+
+// BenchmarkGobDeserializeString benchmarks Gob StringDeserializer performance
+func BenchmarkGobDeserializeString(b *testing.B) {
+	gobSerializer := serializer.NewGobSerializer()
+	stringDeser := gobSerializer.(serializer.StringDeserializer)
+
+	for _, bd := range benchmarkData {
+		b.Run(bd.name, func(b *testing.B) {
+			// Serialize once
+			data, err := gobSerializer.Serialize(bd.data)
+			if err != nil {
+				b.Fatalf("Serialize failed: %v", err)
+			}
+			dataString := string(data)
+
+			// Prepare result variable
+			var result any
+			switch bd.data.(type) {
+			case string:
+				var v string
+				result = &v
+			case testStruct:
+				var v testStruct
+				result = &v
+			default:
+				result = &bd.data
+			}
+
+			b.ResetTimer()
+			b.ReportAllocs()
+
+			for i := 0; i < b.N; i++ {
+				err := stringDeser.DeserializeString(dataString, result)
+				if err != nil {
+					b.Fatalf("DeserializeString failed: %v", err)
+				}
+			}
+		})
+	}
+}
+
+// BenchmarkGobDeserializeBytes benchmarks Gob traditional byte-based deserialization
+func BenchmarkGobDeserializeBytes(b *testing.B) {
+	gobSerializer := serializer.NewGobSerializer()
+
+	for _, bd := range benchmarkData {
+		b.Run(bd.name, func(b *testing.B) {
+			// Serialize once
+			data, err := gobSerializer.Serialize(bd.data)
+			if err != nil {
+				b.Fatalf("Serialize failed: %v", err)
+			}
+			dataString := string(data)
+
+			// Prepare result variable
+			var result any
+			switch bd.data.(type) {
+			case string:
+				var v string
+				result = &v
+			case testStruct:
+				var v testStruct
+				result = &v
+			default:
+				result = &bd.data
+			}
+
+			b.ResetTimer()
+			b.ReportAllocs()
+
+			for i := 0; i < b.N; i++ {
+				// Simulate string->[]byte conversion that happens in real usage
+				dataBytes := []byte(dataString)
+				err := gobSerializer.Deserialize(dataBytes, result)
+				if err != nil {
+					b.Fatalf("Deserialize failed: %v", err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,15 @@
+//go:build !tinygo
+
+package serializer
+
+// gobTestSerializers returns the gob serializers that tests shared with TinyGo
+// builds run against. TinyGo builds leave gob out and get none.
+func gobTestSerializers() []Serializer {
+	return []Serializer{NewGobSerializer()}
+}
+
+// gobTestConstructors returns the gob constructors that option tests shared with
+// TinyGo builds run against
+func gobTestConstructors() []func(opts ...Option) Serializer {
+	return []func(opts ...Option) Serializer{NewGobSerializerWithOptions}
+}
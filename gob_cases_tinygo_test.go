@@ -0,0 +1,13 @@
+//go:build tinygo
+
+package serializer
+
+// gobTestSerializers returns no serializers, as TinyGo builds leave gob out
+func gobTestSerializers() []Serializer {
+	return nil
+}
+
+// gobTestConstructors returns no constructors, as TinyGo builds leave gob out
+func gobTestConstructors() []func(opts ...Option) Serializer {
+	return nil
+}
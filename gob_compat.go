@@ -0,0 +1,119 @@
+//go:build !tinygo
+
+package serializer
+
+import (
+	"bytes"
+	"encoding/gob"
+	"errors"
+	"reflect"
+)
+
+// GobCompatibility describes a schema change between two versions of a type as
+// gob sees it. Fields are matched by name, nested structs are compared field by
+// field and reported with dotted paths such as "Address.Zip".
+type GobCompatibility struct {
+	// Added lists fields only in the new version; decoding old data leaves them zero
+	Added []string
+	// Removed lists fields only in the old version; decoding old data drops them
+	Removed []string
+	// Changed lists fields present in both versions with different types. gob
+	// converts between sizes of the same kind, e.g. int32 to int64, so a change
+	// is not necessarily breaking.
+	Changed []string
+	// Err is why gob cannot decode data of the old version into the new one,
+	// or nil if it can
+	Err error
+}
+
+// Compatible reports whether data written with the old version decodes into the new one
+func (c GobCompatibility) Compatible() bool {
+	return c.Err == nil
+}
+
+// CheckGobCompatibility reports whether gob data encoded from values of old's type
+// can be decoded into values of next's type, and which fields differ. It is meant
+// for tests that guard stored data against schema changes, e.g. comparing a frozen
+// copy of a cached struct with the current one:
+//
+//	if c := serializer.CheckGobCompatibility(v1.Session{}, Session{}); !c.Compatible() {
+//		t.Fatal(c.Err)
+//	}
+func CheckGobCompatibility(old, next any) GobCompatibility {
+	var c GobCompatibility
+	if old == nil || next == nil {
+		c.Err = errors.New("old and next samples are required")
+		return c
+	}
+	oldType, nextType := reflect.TypeOf(old), reflect.TypeOf(next)
+	diffGobFields(derefType(oldType), derefType(nextType), "", &c, map[[2]reflect.Type]bool{})
+
+	// gob checks the whole wire type when it compiles a decoder, so one zero
+	// value exercises every field
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(reflect.New(derefType(oldType)).Interface()); err != nil {
+		c.Err = err
+		return c
+	}
+	c.Err = gob.NewDecoder(&buf).Decode(reflect.New(derefType(nextType)).Interface())
+	return c
+}
+
+// CheckPayload reports whether data, as stored by this serializer, decodes into a
+// value of sample's type. sample itself is not modified.
+func (s *GobSerializer) CheckPayload(data []byte, sample any) error {
+	if data == nil {
+		return errors.New("data is nil")
+	}
+	if sample == nil {
+		return errors.New("sample is nil")
+	}
+	target := reflect.New(derefType(reflect.TypeOf(sample))).Interface()
+	return s.decode(gob.NewDecoder(bytes.NewReader(data)), target)
+}
+
+// diffGobFields records the exported fields that differ between two struct types.
+// Other types have no fields to list; Err covers them.
+func diffGobFields(old, next reflect.Type, prefix string, c *GobCompatibility, seen map[[2]reflect.Type]bool) {
+	if old.Kind() != reflect.Struct || next.Kind() != reflect.Struct || old == next || seen[[2]reflect.Type{old, next}] {
+		return
+	}
+	seen[[2]reflect.Type{old, next}] = true
+
+	for i := 0; i < old.NumField(); i++ {
+		of := old.Field(i)
+		if !of.IsExported() {
+			continue
+		}
+		nf, ok := next.FieldByName(of.Name)
+		if !ok || !nf.IsExported() || len(nf.Index) != 1 {
+			c.Removed = append(c.Removed, prefix+of.Name)
+			continue
+		}
+		ot, nt := derefType(of.Type), derefType(nf.Type)
+		switch {
+		case ot == nt:
+		case ot.Kind() == reflect.Struct && nt.Kind() == reflect.Struct:
+			diffGobFields(ot, nt, prefix+of.Name+".", c, seen)
+		default:
+			c.Changed = append(c.Changed, prefix+of.Name)
+		}
+	}
+	for i := 0; i < next.NumField(); i++ {
+		nf := next.Field(i)
+		if !nf.IsExported() {
+			continue
+		}
+		if of, ok := old.FieldByName(nf.Name); !ok || !of.IsExported() || len(of.Index) != 1 {
+			c.Added = append(c.Added, prefix+nf.Name)
+		}
+	}
+}
+
+// derefType strips pointer indirections, which gob does not put on the wire
+func derefType(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t
+}
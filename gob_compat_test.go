@@ -0,0 +1,95 @@
+//go:build !tinygo
+
+package serializer
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+type compatAddressV1 struct {
+	Street string
+	Zip    int32
+}
+
+type compatAddressV2 struct {
+	Street string
+	Zip    string
+}
+
+type compatSessionV1 struct {
+	ID      string
+	Hits    int32
+	Legacy  bool
+	Home    compatAddressV1
+	private int
+}
+
+type compatSessionV2 struct {
+	ID      string
+	Hits    int64
+	Home    *compatAddressV1
+	Created int64
+}
+
+type compatSessionV3 struct {
+	ID   string
+	Home compatAddressV2
+}
+
+type compatUnrelated struct {
+	Other string
+}
+
+func TestCheckGobCompatibility(t *testing.T) {
+	c := CheckGobCompatibility(compatSessionV1{}, &compatSessionV2{})
+	if !c.Compatible() {
+		t.Fatalf("expected compatible, got %v", c.Err)
+	}
+	if !reflect.DeepEqual(c.Added, []string{"Created"}) {
+		t.Errorf("unexpected Added %v", c.Added)
+	}
+	if !reflect.DeepEqual(c.Removed, []string{"Legacy"}) {
+		t.Errorf("unexpected Removed %v", c.Removed)
+	}
+	if !reflect.DeepEqual(c.Changed, []string{"Hits"}) {
+		t.Errorf("unexpected Changed %v", c.Changed)
+	}
+
+	c = CheckGobCompatibility(compatSessionV1{}, compatSessionV3{})
+	if c.Compatible() {
+		t.Error("expected int32 to string to be incompatible")
+	}
+	if !reflect.DeepEqual(c.Changed, []string{"Home.Zip"}) {
+		t.Errorf("unexpected Changed %v", c.Changed)
+	}
+
+	if c := CheckGobCompatibility(compatSessionV1{}, compatUnrelated{}); c.Compatible() {
+		t.Error("expected no matching fields to be incompatible")
+	}
+	if c := CheckGobCompatibility(nil, compatUnrelated{}); c.Compatible() {
+		t.Error("expected an error for a nil sample")
+	}
+}
+
+func TestGobCheckPayload(t *testing.T) {
+	s := NewGobSerializer().(*GobSerializer)
+	data, err := s.Serialize(compatSessionV1{ID: "s-1", Hits: 3})
+	if err != nil {
+		t.Fatalf("Serialize failed: %v", err)
+	}
+	sample := &compatSessionV2{ID: "keep"}
+	if err := s.CheckPayload(data, sample); err != nil {
+		t.Errorf("expected payload to decode, got %v", err)
+	}
+	if sample.ID != "keep" {
+		t.Error("CheckPayload modified the sample")
+	}
+	if err := s.CheckPayload(data, compatSessionV3{}); err == nil {
+		t.Error("expected an error for an incompatible type")
+	}
+	if err := s.CheckPayload(bytes.Repeat([]byte{0xff}, 4), compatSessionV1{}); err == nil {
+		t.Error("expected an error for a corrupt payload")
+	}
+}
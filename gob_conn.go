@@ -0,0 +1,187 @@
+//go:build !tinygo
+
+package serializer
+
+import (
+	"bufio"
+	"encoding/gob"
+	"errors"
+	"io"
+	"net"
+	"sync"
+)
+
+// ErrConnClosed is returned by GobConn operations after Close
+var ErrConnClosed = errors.New("connection closed")
+
+// Dialer opens a new connection for GobConn reconnects
+type Dialer func() (net.Conn, error)
+
+// GobConn is a long-lived gob codec over a net.Conn.
+// It keeps one gob encoder and decoder per connection, so type descriptors are
+// transmitted once per connection instead of once per message. gob's own message
+// framing delimits values on the stream.
+//
+// When created with DialGobConn, a broken connection is re-dialed on the next
+// Send or Receive with a fresh encoder/decoder pair; a Send that fails on a broken
+// connection is retried once on the new connection.
+//
+// Send and Receive may be called concurrently with each other, but each must only
+// be used by one goroutine at a time.
+type GobConn struct {
+	dial Dialer
+
+	mu     sync.Mutex
+	conn   net.Conn
+	bw     *bufio.Writer
+	enc    *gob.Encoder
+	dec    *gob.Decoder
+	gen    uint64
+	closed bool
+
+	sendMu sync.Mutex
+	recvMu sync.Mutex
+}
+
+// NewGobConn wraps an established connection. It does not reconnect.
+func NewGobConn(conn net.Conn) *GobConn {
+	c := &GobConn{}
+	c.attach(conn)
+	return c
+}
+
+// DialGobConn dials a connection and returns a GobConn that re-dials when the connection breaks
+func DialGobConn(dial Dialer) (*GobConn, error) {
+	if dial == nil {
+		return nil, errors.New("dialer is nil")
+	}
+	conn, err := dial()
+	if err != nil {
+		return nil, err
+	}
+	c := &GobConn{dial: dial}
+	c.attach(conn)
+	return c, nil
+}
+
+// attach binds a new connection and encoder/decoder pair. Callers hold c.mu or own c exclusively.
+func (c *GobConn) attach(conn net.Conn) {
+	c.conn = conn
+	c.bw = bufio.NewWriter(conn)
+	c.enc = gob.NewEncoder(c.bw)
+	c.dec = gob.NewDecoder(bufio.NewReader(conn))
+	c.gen++
+}
+
+// current returns the live connection state, re-dialing if it is broken
+func (c *GobConn) current() (*gob.Encoder, *bufio.Writer, *gob.Decoder, uint64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.closed {
+		return nil, nil, nil, 0, ErrConnClosed
+	}
+	if c.conn == nil {
+		if c.dial == nil {
+			return nil, nil, nil, 0, ErrConnClosed
+		}
+		conn, err := c.dial()
+		if err != nil {
+			return nil, nil, nil, 0, err
+		}
+		c.attach(conn)
+	}
+	return c.enc, c.bw, c.dec, c.gen, nil
+}
+
+// markBroken closes the connection of generation gen so the next call re-dials
+func (c *GobConn) markBroken(gen uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.gen == gen && c.conn != nil {
+		c.conn.Close()
+		c.conn = nil
+	}
+}
+
+// Send encodes v on the connection and flushes it
+func (c *GobConn) Send(v any) error {
+	if v == nil {
+		return errors.New("cannot serialize nil value")
+	}
+
+	c.sendMu.Lock()
+	defer c.sendMu.Unlock()
+
+	err := c.send(v)
+	if err != nil && c.dial != nil && !errors.Is(err, ErrConnClosed) && isConnError(err) {
+		// Retry once on a fresh connection; the peer sees a new gob stream
+		err = c.send(v)
+	}
+	return err
+}
+
+func (c *GobConn) send(v any) error {
+	enc, bw, _, gen, err := c.current()
+	if err != nil {
+		return err
+	}
+	if err := enc.Encode(v); err != nil {
+		if isConnError(err) {
+			c.markBroken(gen)
+		}
+		return err
+	}
+	if err := bw.Flush(); err != nil {
+		c.markBroken(gen)
+		return err
+	}
+	return nil
+}
+
+// Receive decodes the next value from the connection into v
+func (c *GobConn) Receive(v any) error {
+	c.recvMu.Lock()
+	defer c.recvMu.Unlock()
+
+	_, _, dec, gen, err := c.current()
+	if err != nil {
+		return err
+	}
+	if err := dec.Decode(v); err != nil {
+		if isConnError(err) {
+			c.markBroken(gen)
+		}
+		return err
+	}
+	return nil
+}
+
+// Close closes the connection and disables reconnects
+func (c *GobConn) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.closed {
+		return nil
+	}
+	c.closed = true
+	if c.conn == nil {
+		return nil
+	}
+	err := c.conn.Close()
+	c.conn = nil
+	return err
+}
+
+// isConnError reports whether err came from the transport rather than from gob type checking.
+// gob reports its own problems as plain errors prefixed with "gob:", while transport
+// failures surface as net errors or io errors.
+func isConnError(err error) bool {
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return true
+	}
+	return errors.Is(err, net.ErrClosed) || errors.Is(err, io.EOF) ||
+		errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, io.ErrClosedPipe)
+}
@@ -0,0 +1,142 @@
+//go:build !tinygo
+
+package serializer
+
+import (
+	"bytes"
+	"encoding/gob"
+	"errors"
+	"net"
+	"sync"
+	"testing"
+)
+
+type gobRPCRequest struct {
+	Method string
+	Args   []int
+}
+
+// countingConn counts bytes written to the underlying connection
+type countingConn struct {
+	net.Conn
+	mu      sync.Mutex
+	written int
+}
+
+func (c *countingConn) Write(p []byte) (int, error) {
+	n, err := c.Conn.Write(p)
+	c.mu.Lock()
+	c.written += n
+	c.mu.Unlock()
+	return n, err
+}
+
+func TestGobConnSendReceive(t *testing.T) {
+	client, server := net.Pipe()
+	counted := &countingConn{Conn: client}
+	sender := NewGobConn(counted)
+	receiver := NewGobConn(server)
+	defer sender.Close()
+	defer receiver.Close()
+
+	const n = 5
+	done := make(chan error, 1)
+	go func() {
+		for i := 0; i < n; i++ {
+			if err := sender.Send(gobRPCRequest{Method: "sum", Args: []int{i, i}}); err != nil {
+				done <- err
+				return
+			}
+		}
+		done <- nil
+	}()
+
+	for i := 0; i < n; i++ {
+		var req gobRPCRequest
+		if err := receiver.Receive(&req); err != nil {
+			t.Fatalf("Receive %d failed: %v", i, err)
+		}
+		if req.Method != "sum" || req.Args[1] != i {
+			t.Errorf("unexpected request %+v", req)
+		}
+	}
+	if err := <-done; err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+
+	// A fresh encoder per message would retransmit the type descriptor every time
+	var single bytes.Buffer
+	if err := gob.NewEncoder(&single).Encode(gobRPCRequest{Method: "sum", Args: []int{0, 0}}); err != nil {
+		t.Fatal(err)
+	}
+	if counted.written >= n*single.Len() {
+		t.Errorf("expected type descriptors to be sent once: wrote %d bytes, %d per standalone message", counted.written, single.Len())
+	}
+}
+
+func TestGobConnReconnects(t *testing.T) {
+	var mu sync.Mutex
+	var servers []net.Conn
+	dials := 0
+	dial := func() (net.Conn, error) {
+		client, server := net.Pipe()
+		mu.Lock()
+		dials++
+		servers = append(servers, server)
+		mu.Unlock()
+		return client, nil
+	}
+
+	c, err := DialGobConn(dial)
+	if err != nil {
+		t.Fatalf("DialGobConn failed: %v", err)
+	}
+	defer c.Close()
+
+	// Break the first connection from the server side
+	mu.Lock()
+	servers[0].Close()
+	mu.Unlock()
+
+	received := make(chan gobRPCRequest, 1)
+	go func() {
+		for {
+			mu.Lock()
+			if len(servers) > 1 {
+				server := servers[1]
+				mu.Unlock()
+				var req gobRPCRequest
+				if err := gob.NewDecoder(server).Decode(&req); err == nil {
+					received <- req
+				}
+				return
+			}
+			mu.Unlock()
+		}
+	}()
+
+	if err := c.Send(gobRPCRequest{Method: "ping"}); err != nil {
+		t.Fatalf("Send after broken connection failed: %v", err)
+	}
+	if req := <-received; req.Method != "ping" {
+		t.Errorf("unexpected request on new connection %+v", req)
+	}
+	if dials != 2 {
+		t.Errorf("expected one reconnect, dialed %d times", dials)
+	}
+}
+
+func TestGobConnClosed(t *testing.T) {
+	client, _ := net.Pipe()
+	c := NewGobConn(client)
+	if err := c.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	if err := c.Send(gobRPCRequest{}); !errors.Is(err, ErrConnClosed) {
+		t.Errorf("expected ErrConnClosed, got %v", err)
+	}
+	var req gobRPCRequest
+	if err := c.Receive(&req); !errors.Is(err, ErrConnClosed) {
+		t.Errorf("expected ErrConnClosed, got %v", err)
+	}
+}
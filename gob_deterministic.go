@@ -0,0 +1,694 @@
+//go:build !tinygo
+
+package serializer
+
+import (
+	"bytes"
+	"errors"
+	"slices"
+)
+
+// Predeclared gob type ids; user types are numbered from gobFirstUserType
+const (
+	gobTypeBool      = 1
+	gobTypeInt       = 2
+	gobTypeUint      = 3
+	gobTypeFloat     = 4
+	gobTypeBytes     = 5
+	gobTypeString    = 6
+	gobTypeComplex   = 7
+	gobTypeInterface = 8
+	gobFirstUserType = 64
+)
+
+// Kinds of gob type definitions, numbered as the fields of gob's wireType
+const (
+	gobWireArray = iota
+	gobWireSlice
+	gobWireStruct
+	gobWireMap
+	gobWireGobEncoder
+	gobWireBinaryMarshaler
+	gobWireTextMarshaler
+)
+
+var errMalformedGob = errors.New("gob: malformed data")
+
+// gobWire is a gob type definition
+type gobWire struct {
+	kind   int
+	name   string
+	key    int // map key type
+	elem   int // array, slice and map element type
+	length int // array length
+	fields []gobWireField
+}
+
+type gobWireField struct {
+	name string
+	id   int
+}
+
+type gobNodeKind int
+
+const (
+	gobScalar gobNodeKind = iota
+	gobStruct
+	gobSequence
+	gobMap
+	gobInterface
+)
+
+// gobNode is a decoded gob value, kept in wire form
+type gobNode struct {
+	kind gobNodeKind
+	// raw is the encoded payload of a scalar
+	raw []byte
+	// kids are struct fields, sequence elements, or map keys and elements in turn
+	kids []*gobNode
+	// fields holds the field number of each kid of a struct
+	fields []int
+	// name and id identify the concrete type of a non-nil interface value, whose
+	// value is kids[0]
+	name string
+	id   int
+	// sortKey caches the encoding maps are ordered by
+	sortKey []byte
+}
+
+// canonicalGob rewrites gob encoder output into a canonical form that decodes to
+// the same values: map entries are sorted by their encoding, user type ids are
+// renumbered in order of first use, and all type definitions precede the value
+// that first needs them. gob numbers types process-wide in the order they are
+// first encoded, so the ids alone would differ between processes.
+func canonicalGob(data []byte) ([]byte, error) {
+	p := &gobParser{data: data, wires: make(map[int]*gobWire)}
+	type message struct {
+		id   int
+		node *gobNode
+	}
+	var values []message
+	for p.pos < len(p.data) {
+		id, err := p.typeSequence(false)
+		if err != nil {
+			return nil, err
+		}
+		node, err := p.topValue(id)
+		if err != nil {
+			return nil, err
+		}
+		if p.pos != p.end {
+			return nil, errMalformedGob
+		}
+		values = append(values, message{id, node})
+	}
+
+	w := &gobWriter{wires: p.wires, ids: make(map[int]int), next: gobFirstUserType}
+	out := make([]byte, 0, len(data))
+	sent := 0
+	for _, v := range values {
+		id, err := w.mapID(v.id)
+		if err != nil {
+			return nil, err
+		}
+		body := appendGobInt(nil, int64(id))
+		if body, err = w.appendTopValue(body, v.id, v.node); err != nil {
+			return nil, err
+		}
+		for ; sent < len(w.order); sent++ {
+			out = appendGobMessage(out, w.appendWire(nil, w.order[sent]))
+		}
+		out = appendGobMessage(out, body)
+	}
+	return out, nil
+}
+
+// gobParser reads a gob stream. Values may continue across messages after the
+// type definitions for interface values, as they do for gob's decoder.
+type gobParser struct {
+	data  []byte
+	pos   int
+	end   int // end of the current message
+	wires map[int]*gobWire
+}
+
+func (p *gobParser) uint() (uint64, error) {
+	if p.pos >= p.end {
+		return 0, errMalformedGob
+	}
+	b := p.data[p.pos]
+	if b < 0x80 {
+		p.pos++
+		return uint64(b), nil
+	}
+	n := -int(int8(b))
+	if n > 8 || p.pos+1+n > p.end {
+		return 0, errMalformedGob
+	}
+	var x uint64
+	for _, c := range p.data[p.pos+1 : p.pos+1+n] {
+		x = x<<8 | uint64(c)
+	}
+	p.pos += 1 + n
+	return x, nil
+}
+
+func (p *gobParser) int() (int64, error) {
+	u, err := p.uint()
+	if u&1 != 0 {
+		return ^int64(u >> 1), err
+	}
+	return int64(u >> 1), err
+}
+
+// count reads a length, which cannot exceed the bytes left in the message
+func (p *gobParser) count() (int, error) {
+	n, err := p.uint()
+	if err != nil {
+		return 0, err
+	}
+	if n > uint64(p.end-p.pos) {
+		return 0, errMalformedGob
+	}
+	return int(n), nil
+}
+
+func (p *gobParser) bytes() ([]byte, error) {
+	n, err := p.count()
+	if err != nil {
+		return nil, err
+	}
+	p.pos += n
+	return p.data[p.pos-n : p.pos], nil
+}
+
+func (p *gobParser) typeID() (int, error) {
+	id, err := p.int()
+	if err != nil {
+		return 0, err
+	}
+	if id < 0 || id > 1<<31 {
+		return 0, errMalformedGob
+	}
+	return int(id), nil
+}
+
+func (p *gobParser) nextMessage() error {
+	p.end = len(p.data)
+	n, err := p.count()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return errMalformedGob
+	}
+	p.end = p.pos + n
+	return nil
+}
+
+// typeSequence reads type definitions up to the id of the value that follows
+func (p *gobParser) typeSequence(inInterface bool) (int, error) {
+	for {
+		if p.pos == p.end {
+			if err := p.nextMessage(); err != nil {
+				return 0, err
+			}
+		}
+		id, err := p.int()
+		if err != nil {
+			return 0, err
+		}
+		if id >= 0 {
+			if id > 1<<31 {
+				return 0, errMalformedGob
+			}
+			return int(id), nil
+		}
+		if id < -1<<31 {
+			return 0, errMalformedGob
+		}
+		if err := p.wireType(int(-id)); err != nil {
+			return 0, err
+		}
+		// Inside an interface value the definition may be followed by the
+		// byte count of the value in the same message
+		if p.pos < p.end {
+			if !inInterface {
+				return 0, errMalformedGob
+			}
+			if _, err := p.uint(); err != nil {
+				return 0, err
+			}
+		}
+	}
+}
+
+// structFields calls fn with the number of each field of a struct
+func (p *gobParser) structFields(fn func(field int) error) error {
+	field := -1
+	for {
+		delta, err := p.uint()
+		if err != nil {
+			return err
+		}
+		if delta == 0 {
+			return nil
+		}
+		if delta > 1<<20 {
+			return errMalformedGob
+		}
+		field += int(delta)
+		if err := fn(field); err != nil {
+			return err
+		}
+	}
+}
+
+func (p *gobParser) wireType(id int) error {
+	if id < gobFirstUserType || p.wires[id] != nil {
+		return errMalformedGob
+	}
+	w := &gobWire{kind: -1}
+	err := p.structFields(func(kind int) error {
+		if w.kind >= 0 || kind > gobWireTextMarshaler {
+			return errMalformedGob
+		}
+		w.kind = kind
+		return p.structFields(func(field int) error {
+			return p.wireField(w, field)
+		})
+	})
+	if err != nil {
+		return err
+	}
+	if w.kind < 0 {
+		return errMalformedGob
+	}
+	p.wires[id] = w
+	return nil
+}
+
+// wireField reads one field of the definition of w's kind
+func (p *gobParser) wireField(w *gobWire, field int) error {
+	var err error
+	if field == 0 {
+		// CommonType; the type's own id is renumbered on output
+		return p.structFields(func(f int) error {
+			switch f {
+			case 0:
+				var name []byte
+				name, err = p.bytes()
+				w.name = string(name)
+			case 1:
+				_, err = p.typeID()
+			default:
+				err = errMalformedGob
+			}
+			return err
+		})
+	}
+	switch {
+	case w.kind == gobWireArray && field == 1, w.kind == gobWireSlice && field == 1, w.kind == gobWireMap && field == 2:
+		w.elem, err = p.typeID()
+	case w.kind == gobWireArray && field == 2:
+		var n int64
+		n, err = p.int()
+		w.length = int(n)
+	case w.kind == gobWireMap && field == 1:
+		w.key, err = p.typeID()
+	case w.kind == gobWireStruct && field == 1:
+		var n int
+		if n, err = p.count(); err != nil {
+			return err
+		}
+		w.fields = make([]gobWireField, n)
+		for i := range w.fields {
+			f := &w.fields[i]
+			err = p.structFields(func(g int) error {
+				switch g {
+				case 0:
+					name, err := p.bytes()
+					f.name = string(name)
+					return err
+				case 1:
+					var err error
+					f.id, err = p.typeID()
+					return err
+				}
+				return errMalformedGob
+			})
+			if err != nil {
+				return err
+			}
+		}
+	default:
+		err = errMalformedGob
+	}
+	return err
+}
+
+// topValue reads a value as it is encoded at the top of a message: structs as
+// they are, anything else behind a zero field delta
+func (p *gobParser) topValue(id int) (*gobNode, error) {
+	if w := p.wires[id]; w == nil || w.kind != gobWireStruct {
+		if delta, err := p.uint(); err != nil || delta != 0 {
+			return nil, errMalformedGob
+		}
+	}
+	return p.value(id)
+}
+
+func (p *gobParser) value(id int) (*gobNode, error) {
+	start := p.pos
+	scalar := func(err error) (*gobNode, error) {
+		if err != nil {
+			return nil, err
+		}
+		return &gobNode{kind: gobScalar, raw: p.data[start:p.pos]}, nil
+	}
+	switch id {
+	case gobTypeBool, gobTypeInt, gobTypeUint, gobTypeFloat:
+		_, err := p.uint()
+		return scalar(err)
+	case gobTypeBytes, gobTypeString:
+		_, err := p.bytes()
+		return scalar(err)
+	case gobTypeComplex:
+		_, err := p.uint()
+		if err == nil {
+			_, err = p.uint()
+		}
+		return scalar(err)
+	case gobTypeInterface:
+		return p.interfaceValue()
+	}
+
+	w := p.wires[id]
+	if w == nil {
+		return nil, errMalformedGob
+	}
+	switch w.kind {
+	case gobWireStruct:
+		node := &gobNode{kind: gobStruct}
+		err := p.structFields(func(field int) error {
+			if field >= len(w.fields) {
+				return errMalformedGob
+			}
+			kid, err := p.value(w.fields[field].id)
+			if err != nil {
+				return err
+			}
+			node.kids = append(node.kids, kid)
+			node.fields = append(node.fields, field)
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		return node, nil
+	case gobWireArray, gobWireSlice:
+		n, err := p.count()
+		if err != nil {
+			return nil, err
+		}
+		node := &gobNode{kind: gobSequence, kids: make([]*gobNode, n)}
+		for i := range node.kids {
+			if node.kids[i], err = p.value(w.elem); err != nil {
+				return nil, err
+			}
+		}
+		return node, nil
+	case gobWireMap:
+		n, err := p.count()
+		if err != nil {
+			return nil, err
+		}
+		node := &gobNode{kind: gobMap, kids: make([]*gobNode, 2*n)}
+		for i := 0; i < n; i++ {
+			if node.kids[2*i], err = p.value(w.key); err != nil {
+				return nil, err
+			}
+			if node.kids[2*i+1], err = p.value(w.elem); err != nil {
+				return nil, err
+			}
+		}
+		sortGobMap(node)
+		return node, nil
+	}
+	// Types with their own encoding are opaque bytes
+	_, err := p.bytes()
+	return scalar(err)
+}
+
+func (p *gobParser) interfaceValue() (*gobNode, error) {
+	name, err := p.bytes()
+	if err != nil {
+		return nil, err
+	}
+	node := &gobNode{kind: gobInterface, name: string(name)}
+	if len(name) == 0 {
+		return node, nil
+	}
+	if node.id, err = p.typeSequence(true); err != nil {
+		return nil, err
+	}
+	// The byte count may cover definitions read above, so it is recomputed on output
+	if _, err := p.uint(); err != nil {
+		return nil, err
+	}
+	value, err := p.topValue(node.id)
+	if err != nil {
+		return nil, err
+	}
+	node.kids = []*gobNode{value}
+	return node, nil
+}
+
+// sortGobMap orders the entries of a map by the encoding of their keys, then values
+func sortGobMap(node *gobNode) {
+	type entry struct{ key, elem *gobNode }
+	entries := make([]entry, len(node.kids)/2)
+	for i := range entries {
+		entries[i] = entry{node.kids[2*i], node.kids[2*i+1]}
+	}
+	slices.SortStableFunc(entries, func(a, b entry) int {
+		if c := bytes.Compare(a.key.key(), b.key.key()); c != 0 {
+			return c
+		}
+		return bytes.Compare(a.elem.key(), b.elem.key())
+	})
+	for i, e := range entries {
+		node.kids[2*i], node.kids[2*i+1] = e.key, e.elem
+	}
+}
+
+// key returns the encoding n sorts by: its wire form with the type ids of
+// interface values left out, since names identify their types
+func (n *gobNode) key() []byte {
+	if n.sortKey == nil {
+		n.sortKey = n.appendKey([]byte{})
+	}
+	return n.sortKey
+}
+
+func (n *gobNode) appendKey(b []byte) []byte {
+	switch n.kind {
+	case gobScalar:
+		return append(b, n.raw...)
+	case gobStruct:
+		for i, kid := range n.kids {
+			b = appendGobUint(b, uint64(n.fields[i]+1))
+			b = append(b, kid.key()...)
+		}
+		return appendGobUint(b, 0)
+	case gobSequence, gobMap:
+		b = appendGobUint(b, uint64(len(n.kids)))
+		for _, kid := range n.kids {
+			b = append(b, kid.key()...)
+		}
+		return b
+	}
+	b = appendGobUint(b, uint64(len(n.name)))
+	b = append(b, n.name...)
+	if len(n.kids) > 0 {
+		inner := n.kids[0].key()
+		b = appendGobUint(b, uint64(len(inner)))
+		b = append(b, inner...)
+	}
+	return b
+}
+
+// gobWriter writes parsed values with renumbered type ids
+type gobWriter struct {
+	wires map[int]*gobWire
+	ids   map[int]int
+	next  int
+	order []int // original ids in their new order
+}
+
+// mapID returns the new id of a type, numbering it and the types its definition
+// refers to on first use
+func (w *gobWriter) mapID(id int) (int, error) {
+	if id < gobFirstUserType {
+		return id, nil
+	}
+	if n, ok := w.ids[id]; ok {
+		return n, nil
+	}
+	wire := w.wires[id]
+	if wire == nil {
+		return 0, errMalformedGob
+	}
+	n := w.next
+	w.next++
+	w.ids[id] = n
+	w.order = append(w.order, id)
+
+	refs := []int{wire.key, wire.elem}
+	for _, f := range wire.fields {
+		refs = append(refs, f.id)
+	}
+	for _, ref := range refs {
+		if ref != 0 {
+			if _, err := w.mapID(ref); err != nil {
+				return 0, err
+			}
+		}
+	}
+	return n, nil
+}
+
+// appendTopValue appends a value as it is encoded at the top of a message
+func (w *gobWriter) appendTopValue(b []byte, id int, n *gobNode) ([]byte, error) {
+	if wire := w.wires[id]; wire == nil || wire.kind != gobWireStruct {
+		b = appendGobUint(b, 0)
+	}
+	return w.appendValue(b, n)
+}
+
+func (w *gobWriter) appendValue(b []byte, n *gobNode) ([]byte, error) {
+	var err error
+	switch n.kind {
+	case gobScalar:
+		return append(b, n.raw...), nil
+	case gobStruct:
+		prev := -1
+		for i, kid := range n.kids {
+			b = appendGobUint(b, uint64(n.fields[i]-prev))
+			prev = n.fields[i]
+			if b, err = w.appendValue(b, kid); err != nil {
+				return nil, err
+			}
+		}
+		return appendGobUint(b, 0), nil
+	case gobSequence, gobMap:
+		count := len(n.kids)
+		if n.kind == gobMap {
+			count /= 2
+		}
+		b = appendGobUint(b, uint64(count))
+		for _, kid := range n.kids {
+			if b, err = w.appendValue(b, kid); err != nil {
+				return nil, err
+			}
+		}
+		return b, nil
+	}
+	b = appendGobUint(b, uint64(len(n.name)))
+	b = append(b, n.name...)
+	if n.name == "" {
+		return b, nil
+	}
+	id, err := w.mapID(n.id)
+	if err != nil {
+		return nil, err
+	}
+	inner, err := w.appendTopValue(nil, n.id, n.kids[0])
+	if err != nil {
+		return nil, err
+	}
+	b = appendGobInt(b, int64(id))
+	b = appendGobUint(b, uint64(len(inner)))
+	return append(b, inner...), nil
+}
+
+// appendWire appends the definition message body of a type. The types it refers
+// to are numbered already.
+func (w *gobWriter) appendWire(b []byte, id int) []byte {
+	wire := w.wires[id]
+	b = appendGobInt(b, -int64(w.ids[id]))
+	b = appendGobUint(b, uint64(wire.kind+1))
+
+	// CommonType
+	b = appendGobUint(b, 1)
+	if wire.name != "" {
+		b = appendGobUint(b, 1)
+		b = appendGobBytes(b, wire.name)
+		b = appendGobUint(b, 1)
+	} else {
+		b = appendGobUint(b, 2)
+	}
+	b = appendGobInt(b, int64(w.ids[id]))
+	b = appendGobUint(b, 0)
+
+	ref := func(id int) int64 {
+		if id < gobFirstUserType {
+			return int64(id)
+		}
+		return int64(w.ids[id])
+	}
+	switch wire.kind {
+	case gobWireArray:
+		b = appendGobInt(appendGobUint(b, 1), ref(wire.elem))
+		if wire.length != 0 {
+			b = appendGobInt(appendGobUint(b, 1), int64(wire.length))
+		}
+	case gobWireSlice:
+		b = appendGobInt(appendGobUint(b, 1), ref(wire.elem))
+	case gobWireStruct:
+		if len(wire.fields) > 0 {
+			b = appendGobUint(appendGobUint(b, 1), uint64(len(wire.fields)))
+			for _, f := range wire.fields {
+				b = appendGobUint(b, 1)
+				b = appendGobBytes(b, f.name)
+				b = appendGobInt(appendGobUint(b, 1), ref(f.id))
+				b = appendGobUint(b, 0)
+			}
+		}
+	case gobWireMap:
+		b = appendGobInt(appendGobUint(b, 1), ref(wire.key))
+		b = appendGobInt(appendGobUint(b, 1), ref(wire.elem))
+	}
+	// End of the kind's struct, then of wireType
+	return appendGobUint(appendGobUint(b, 0), 0)
+}
+
+func appendGobUint(b []byte, x uint64) []byte {
+	if x < 0x80 {
+		return append(b, byte(x))
+	}
+	n := 0
+	for y := x; y > 0; y >>= 8 {
+		n++
+	}
+	b = append(b, byte(-n))
+	for i := n - 1; i >= 0; i-- {
+		b = append(b, byte(x>>(8*i)))
+	}
+	return b
+}
+
+func appendGobInt(b []byte, i int64) []byte {
+	if i < 0 {
+		return appendGobUint(b, uint64(^i<<1)|1)
+	}
+	return appendGobUint(b, uint64(i<<1))
+}
+
+func appendGobBytes(b []byte, s string) []byte {
+	return append(appendGobUint(b, uint64(len(s))), s...)
+}
+
+func appendGobMessage(b, msg []byte) []byte {
+	return append(appendGobUint(b, uint64(len(msg))), msg...)
+}
@@ -0,0 +1,169 @@
+//go:build !tinygo
+
+package serializer
+
+import (
+	"bytes"
+	"encoding/gob"
+	"fmt"
+	"math/big"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type detInner struct {
+	Scores map[string]float64
+	Tags   []string
+}
+
+type detRecord struct {
+	ID       int
+	Name     string
+	Attrs    map[string]any
+	Inner    map[int]detInner
+	Nested   map[string]map[string]int
+	Extra    any
+	Items    []any
+	Fixed    [3]uint8
+	When     time.Time
+	Amount   *big.Int
+	Empty    map[string]int
+	Complex  complex128
+	Nil      any
+	Pointers map[string]*detInner
+}
+
+type detScores struct {
+	Scores map[string]float64
+}
+
+func newDetRecord(n int) detRecord {
+	r := detRecord{
+		ID:       n,
+		Name:     "record",
+		Attrs:    map[string]any{},
+		Inner:    map[int]detInner{},
+		Nested:   map[string]map[string]int{},
+		Fixed:    [3]uint8{1, 2, 3},
+		When:     time.Date(2024, 1, 2, 3, 4, 5, 6, time.UTC),
+		Amount:   big.NewInt(1 << 40),
+		Complex:  complex(1, -2),
+		Pointers: map[string]*detInner{},
+	}
+	for i := 0; i < n; i++ {
+		key := fmt.Sprintf("k%02d", i)
+		r.Attrs[key] = i
+		r.Inner[i] = detInner{Scores: map[string]float64{key: float64(i) / 3, "z": -1}, Tags: []string{key}}
+		r.Nested[key] = map[string]int{"a": i, "b": -i, "c": i * i}
+		r.Pointers[key] = &detInner{Tags: []string{"p", key}}
+	}
+	r.Attrs["inner"] = detInner{Scores: map[string]float64{"x": 1, "y": 2, "w": 3}}
+	r.Extra = map[string]any{"one": 1, "two": "2", "three": detInner{Tags: []string{"t"}}}
+	r.Items = []any{map[string]int{"q": 1, "r": 2, "s": 3}, "s", 3.5, detInner{}}
+	return r
+}
+
+func TestWithGobDeterministic(t *testing.T) {
+	gob.Register(detInner{})
+	gob.Register(map[string]int{})
+	gob.Register(map[string]any{})
+
+	s := NewGobSerializerWithOptions(WithGobDeterministic())
+	want := newDetRecord(20)
+	first, err := s.Serialize(want)
+	if err != nil {
+		t.Fatalf("Serialize failed: %v", err)
+	}
+	for i := 0; i < 20; i++ {
+		again, err := s.Serialize(newDetRecord(20))
+		if err != nil {
+			t.Fatalf("Serialize failed: %v", err)
+		}
+		if !bytes.Equal(first, again) {
+			t.Fatal("expected identical output for equal values")
+		}
+	}
+
+	var got detRecord
+	if err := s.Deserialize(first, &got); err != nil {
+		t.Fatalf("Deserialize failed: %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("round trip mismatch:\n got %#v\nwant %#v", got, want)
+	}
+	var plain detRecord
+	if err := NewGobSerializer().Deserialize(first, &plain); err != nil {
+		t.Errorf("plain gob failed to decode deterministic output: %v", err)
+	}
+
+	// Type ids are renumbered from the first user id, whatever gob assigned
+	if p := (&gobParser{data: first, wires: map[int]*gobWire{}}); p.nextMessage() != nil {
+		t.Fatal("expected a message")
+	} else if id, _ := p.int(); id != -gobFirstUserType {
+		t.Errorf("expected the first definition to be of type %d, got %d", gobFirstUserType, -id)
+	}
+
+	// Canonical output is already canonical
+	again, err := canonicalGob(first)
+	if err != nil || !bytes.Equal(again, first) {
+		t.Errorf("expected canonicalGob to be idempotent, err %v", err)
+	}
+
+	var w bytes.Buffer
+	if err := s.SerializeTo(&w, want); err != nil || !bytes.Equal(w.Bytes(), first) {
+		t.Errorf("expected SerializeTo to match Serialize, err %v", err)
+	}
+	typed, err := s.(TypedSerializer).SerializeWithTypeInfo(want, TypeInfo{Type: reflect.TypeOf(want)})
+	if err != nil || !bytes.Equal(typed, first) {
+		t.Errorf("expected SerializeWithTypeInfo to match Serialize, err %v", err)
+	}
+}
+
+func TestWithGobDeterministicScalars(t *testing.T) {
+	s := NewGobSerializerWithOptions(WithGobDeterministic())
+	for _, v := range []any{42, "text", []byte("raw"), map[int]string{3: "c", 1: "a", 2: "b"}, []int{3, 1}, true} {
+		data, err := s.Serialize(v)
+		if err != nil {
+			t.Fatalf("Serialize(%#v) failed: %v", v, err)
+		}
+		got := reflect.New(reflect.TypeOf(v))
+		if err := s.Deserialize(data, got.Interface()); err != nil {
+			t.Fatalf("Deserialize(%#v) failed: %v", v, err)
+		}
+		if !reflect.DeepEqual(got.Elem().Interface(), v) {
+			t.Errorf("round trip mismatch: %#v != %#v", got.Elem().Interface(), v)
+		}
+	}
+}
+
+func TestCanonicalGobEnvelope(t *testing.T) {
+	MustRegisterGobType("det.Scores", detScores{})
+	s := NewGobSerializerWithOptions(WithGobEnvelope(), WithGobDeterministic()).(*GobSerializer)
+	v := detScores{Scores: map[string]float64{"a": 1, "b": 2, "c": 3, "d": 4}}
+	first, err := s.Serialize(v)
+	if err != nil {
+		t.Fatalf("Serialize failed: %v", err)
+	}
+	for i := 0; i < 10; i++ {
+		if again, _ := s.Serialize(v); !bytes.Equal(first, again) {
+			t.Fatal("expected identical output for equal values")
+		}
+	}
+	got, err := s.DeserializeAny(first)
+	if err != nil || !reflect.DeepEqual(got, v) {
+		t.Errorf("round trip mismatch: %#v %v", got, err)
+	}
+}
+
+func TestCanonicalGobMalformed(t *testing.T) {
+	data, err := NewGobSerializer().Serialize(map[string]int{"a": 1})
+	if err != nil {
+		t.Fatalf("Serialize failed: %v", err)
+	}
+	for i := 0; i < len(data); i++ {
+		if _, err := canonicalGob(data[:i]); err == nil && i > 0 {
+			t.Errorf("expected an error for data truncated to %d bytes", i)
+		}
+	}
+}
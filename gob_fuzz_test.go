@@ -0,0 +1,14 @@
+//go:build !tinygo
+
+package serializer_test
+
+import (
+	"testing"
+
+	serializer "github.com/MichaelAJay/go-serializer"
+	"github.com/MichaelAJay/go-serializer/serializertest"
+)
+
+func FuzzGobDeserialize(f *testing.F) {
+	serializertest.FuzzDeserialize(f, serializer.NewGobSerializer(), fuzzTargets()...)
+}
@@ -0,0 +1,167 @@
+//go:build !tinygo
+
+package serializer
+
+import (
+	"bytes"
+	"encoding/gob"
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// gobTypeCodec writes values of one type as records: gob messages without the
+// type definitions, which are kept as the header
+type gobTypeCodec struct {
+	// header holds the canonical definitions of the type and the types it contains
+	header []byte
+	// id is the type's id under header
+	id   int
+	pool sync.Pool
+}
+
+// gobRecordEncoder is an encoder that has sent the definitions for its type
+type gobRecordEncoder struct {
+	buf bytes.Buffer
+	enc *gob.Encoder
+}
+
+// gobTypeCodecs caches a *gobTypeCodec per type
+var gobTypeCodecs sync.Map
+
+// typeCodec returns the record codec for t, or nil when type headers are off or
+// do not apply to t
+func (s *GobSerializer) typeCodec(t reflect.Type) *gobTypeCodec {
+	if !s.opts.gobTypeHeaders || s.opts.gobEnvelope || t == nil {
+		return nil
+	}
+	c, err := gobTypeCodecFor(t)
+	if err != nil {
+		// The full encoding path reports the error
+		return nil
+	}
+	return c
+}
+
+// TypeHeader returns the gob type definitions that WithGobTypeHeaders leaves out
+// of payloads for values of type t. Headers are canonical: equal across processes
+// for the same type definition, and different when its fields change.
+func (s *GobSerializer) TypeHeader(t reflect.Type) ([]byte, error) {
+	if t == nil {
+		return nil, fmt.Errorf("type is nil")
+	}
+	c, err := gobTypeCodecFor(t)
+	if err != nil {
+		return nil, err
+	}
+	if c == nil {
+		return nil, fmt.Errorf("gob type header unavailable: values of %s can hold interfaces", t)
+	}
+	return bytes.Clone(c.header), nil
+}
+
+// gobTypeCodecFor returns the record codec for t, or nil when values of t can
+// hold interfaces, since the definitions they need depend on the values
+func gobTypeCodecFor(t reflect.Type) (*gobTypeCodec, error) {
+	t = derefType(t)
+	if c, ok := gobTypeCodecs.Load(t); ok {
+		return c.(*gobTypeCodec), nil
+	}
+	if canHoldInterface(t) {
+		return nil, nil
+	}
+
+	zero := reflect.New(t).Elem().Interface()
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(zero); err != nil {
+		return nil, err
+	}
+	canonical, err := canonicalGob(buf.Bytes())
+	if err != nil {
+		return nil, err
+	}
+	last, id, err := lastGobMessage(canonical)
+	if err != nil {
+		return nil, err
+	}
+	c := &gobTypeCodec{header: canonical[:last], id: id}
+	c.pool.New = func() any {
+		re := &gobRecordEncoder{}
+		re.enc = gob.NewEncoder(&re.buf)
+		// Send the definitions once, ahead of the first record
+		_ = re.enc.Encode(zero)
+		return re
+	}
+	actual, _ := gobTypeCodecs.LoadOrStore(t, c)
+	return actual.(*gobTypeCodec), nil
+}
+
+// record encodes v as a single message under the header's type ids
+func (c *gobTypeCodec) record(v any, deterministic bool) ([]byte, error) {
+	re := c.pool.Get().(*gobRecordEncoder)
+	re.buf.Reset()
+	if err := re.enc.Encode(v); err != nil {
+		// The encoder may be left mid-message, so it is not reused
+		return nil, err
+	}
+	p := gobParser{data: re.buf.Bytes()}
+	if err := p.nextMessage(); err != nil {
+		return nil, err
+	}
+	if _, err := p.int(); err != nil {
+		return nil, err
+	}
+	if p.end != len(p.data) {
+		return nil, errMalformedGob
+	}
+	body := appendGobInt(nil, int64(c.id))
+	body = append(body, p.data[p.pos:p.end]...)
+	rec := appendGobMessage(make([]byte, 0, len(body)+9), body)
+	c.pool.Put(re)
+
+	if deterministic {
+		canonical, err := canonicalGob(append(bytes.Clone(c.header), rec...))
+		if err != nil {
+			return nil, err
+		}
+		if !bytes.HasPrefix(canonical, c.header) {
+			return nil, errMalformedGob
+		}
+		rec = canonical[len(c.header):]
+	}
+	return rec, nil
+}
+
+// lastGobMessage returns the offset of the last message in data and the type id
+// at its start
+func lastGobMessage(data []byte) (int, int, error) {
+	p := gobParser{data: data}
+	last, id := -1, 0
+	for p.pos < len(data) {
+		start := p.pos
+		if err := p.nextMessage(); err != nil {
+			return 0, 0, err
+		}
+		n, err := p.int()
+		if err != nil {
+			return 0, 0, err
+		}
+		last, id = start, int(n)
+		p.pos = p.end
+	}
+	if last < 0 || id < 0 {
+		return 0, 0, errMalformedGob
+	}
+	return last, id, nil
+}
+
+// startsWithGobDefinition reports whether data opens with a type definition,
+// i.e. is a full payload rather than a record
+func startsWithGobDefinition(data []byte) bool {
+	p := gobParser{data: data}
+	if p.nextMessage() != nil {
+		return false
+	}
+	id, err := p.int()
+	return err == nil && id < 0
+}
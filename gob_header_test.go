@@ -0,0 +1,146 @@
+//go:build !tinygo
+
+package serializer
+
+import (
+	"bytes"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+type headerRecord struct {
+	ID     int64
+	Name   string
+	Labels map[string]string
+	Points []headerPoint
+}
+
+type headerPoint struct {
+	X, Y float64
+}
+
+type headerDynamic struct {
+	Value any
+}
+
+func TestWithGobTypeHeaders(t *testing.T) {
+	info := TypeInfo{Type: reflect.TypeOf(headerRecord{}), TypeName: "headerRecord"}
+	s := NewGobSerializerWithOptions(WithGobTypeHeaders()).(*GobSerializer)
+	full := NewGobSerializer().(*GobSerializer)
+	in := headerRecord{ID: 1, Name: "a", Labels: map[string]string{"k": "v"}, Points: []headerPoint{{1, 2}}}
+
+	fullData, err := full.SerializeWithTypeInfo(in, info)
+	if err != nil {
+		t.Fatalf("SerializeWithTypeInfo failed: %v", err)
+	}
+	var records [][]byte
+	for i := 0; i < 3; i++ {
+		rec, err := s.SerializeWithTypeInfo(in, info)
+		if err != nil {
+			t.Fatalf("SerializeWithTypeInfo failed: %v", err)
+		}
+		records = append(records, rec)
+	}
+	if len(records[0]) >= len(fullData)/2 {
+		t.Errorf("expected records to leave out type definitions: %d vs %d bytes", len(records[0]), len(fullData))
+	}
+	if !bytes.Equal(records[0], records[2]) {
+		t.Error("expected identical records for equal values")
+	}
+
+	for _, data := range [][]byte{records[0], fullData} {
+		got, err := s.DeserializeWithTypeInfo(data, info)
+		if err != nil {
+			t.Fatalf("DeserializeWithTypeInfo failed: %v", err)
+		}
+		if !reflect.DeepEqual(got, in) {
+			t.Errorf("round trip mismatch: %#v", got)
+		}
+	}
+	ptr, err := s.DeserializeWithTypeInfo(records[0], TypeInfo{Type: reflect.TypeOf(&headerRecord{})})
+	if err != nil || !reflect.DeepEqual(ptr, &in) {
+		t.Errorf("pointer round trip mismatch: %#v %v", ptr, err)
+	}
+
+	// The header and a record decode as a plain gob payload
+	header, err := s.TypeHeader(info.Type)
+	if err != nil {
+		t.Fatalf("TypeHeader failed: %v", err)
+	}
+	var plain headerRecord
+	if err := full.Deserialize(append(header, records[0]...), &plain); err != nil || !reflect.DeepEqual(plain, in) {
+		t.Errorf("header and record did not decode: %#v %v", plain, err)
+	}
+
+	if _, err := s.TypeHeader(reflect.TypeOf(headerDynamic{})); err == nil {
+		t.Error("expected no header for a type holding interfaces")
+	}
+}
+
+func TestWithGobTypeHeadersFallback(t *testing.T) {
+	info := TypeInfo{Type: reflect.TypeOf(headerDynamic{})}
+	s := NewGobSerializerWithOptions(WithGobTypeHeaders()).(*GobSerializer)
+	data, err := s.SerializeWithTypeInfo(headerDynamic{Value: headerPoint{X: 1}}, info)
+	if err != nil {
+		t.Fatalf("SerializeWithTypeInfo failed: %v", err)
+	}
+	if !startsWithGobDefinition(data) {
+		t.Error("expected a full payload for a type holding interfaces")
+	}
+	got, err := s.DeserializeWithTypeInfo(data, info)
+	if err != nil || got.(headerDynamic).Value != (headerPoint{X: 1}) {
+		t.Errorf("round trip mismatch: %#v %v", got, err)
+	}
+}
+
+func TestWithGobTypeHeadersConcurrent(t *testing.T) {
+	info := TypeInfo{Type: reflect.TypeOf(headerPoint{})}
+	s := NewGobSerializerWithOptions(WithGobTypeHeaders(), WithGobDeterministic()).(*GobSerializer)
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				in := headerPoint{X: float64(i), Y: float64(j)}
+				rec, err := s.SerializeWithTypeInfo(in, info)
+				if err != nil {
+					t.Errorf("SerializeWithTypeInfo failed: %v", err)
+					return
+				}
+				got, err := s.DeserializeWithTypeInfo(rec, info)
+				if err != nil || got != in {
+					t.Errorf("round trip mismatch: %#v %v", got, err)
+					return
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+}
+
+func BenchmarkGobSerializeWithTypeInfo(b *testing.B) {
+	info := TypeInfo{Type: reflect.TypeOf(headerRecord{}), TypeName: "headerRecord"}
+	in := headerRecord{ID: 1, Name: "a", Labels: map[string]string{"k": "v"}, Points: []headerPoint{{1, 2}}}
+	for _, bc := range []struct {
+		name string
+		s    TypedSerializer
+	}{
+		{"full", NewGobSerializer().(TypedSerializer)},
+		{"headers", NewGobSerializerWithOptions(WithGobTypeHeaders()).(TypedSerializer)},
+	} {
+		b.Run(bc.name, func(b *testing.B) {
+			b.ReportAllocs()
+			var size int
+			for i := 0; i < b.N; i++ {
+				data, err := bc.s.SerializeWithTypeInfo(in, info)
+				if err != nil {
+					b.Fatal(err)
+				}
+				size = len(data)
+			}
+			b.ReportMetric(float64(size), "bytes/record")
+		})
+	}
+}
@@ -0,0 +1,45 @@
+//go:build !tinygo
+
+package serializer
+
+import (
+	"bytes"
+	"encoding/gob"
+	stdjson "encoding/json"
+	"errors"
+	"fmt"
+)
+
+// GobToJSON decodes a gob payload into a new value of the type registered under
+// typeName with RegisterGobType and returns it as indented JSON, for inspecting
+// stored blobs while debugging. An empty typeName reads the name from the payload,
+// as written by NewGobEnvelopeSerializer. Values held in interfaces need their
+// types registered with gob as for any other decode; unexported fields are not
+// carried by gob and do not appear.
+func GobToJSON(data []byte, typeName string) ([]byte, error) {
+	if data == nil {
+		return nil, errors.New("data is nil")
+	}
+	dec := gob.NewDecoder(bytes.NewReader(data))
+	if typeName == "" {
+		if err := dec.Decode(&typeName); err != nil {
+			return nil, fmt.Errorf("reading gob envelope type name: %w", err)
+		}
+	}
+	v, err := gobTypes.New(typeName)
+	if err != nil {
+		return nil, err
+	}
+	if err := dec.Decode(v); err != nil {
+		return nil, fmt.Errorf("gob deserialization failed for type %q: %w", typeName, err)
+	}
+
+	var out bytes.Buffer
+	enc := stdjson.NewEncoder(&out)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
@@ -0,0 +1,73 @@
+//go:build !tinygo
+
+package serializer
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+type gobDebugOrder struct {
+	ID    string
+	Lines []gobDebugLine
+	Notes map[string]string
+}
+
+type gobDebugLine struct {
+	SKU string
+	Qty int
+}
+
+func TestGobToJSON(t *testing.T) {
+	MustRegisterGobType("debug.Order", gobDebugOrder{})
+	order := gobDebugOrder{
+		ID:    "o-<1>",
+		Lines: []gobDebugLine{{SKU: "a", Qty: 2}},
+		Notes: map[string]string{"gift": "yes"},
+	}
+	want := `{
+  "ID": "o-<1>",
+  "Lines": [
+    {
+      "SKU": "a",
+      "Qty": 2
+    }
+  ],
+  "Notes": {
+    "gift": "yes"
+  }
+}
+`
+
+	data, err := NewGobSerializer().Serialize(order)
+	if err != nil {
+		t.Fatalf("Serialize failed: %v", err)
+	}
+	got, err := GobToJSON(data, "debug.Order")
+	if err != nil {
+		t.Fatalf("GobToJSON failed: %v", err)
+	}
+	if string(got) != want {
+		t.Errorf("unexpected JSON:\n%s", got)
+	}
+
+	enveloped, err := NewGobEnvelopeSerializer().Serialize(order)
+	if err != nil {
+		t.Fatalf("Serialize failed: %v", err)
+	}
+	got, err = GobToJSON(enveloped, "")
+	if err != nil {
+		t.Fatalf("GobToJSON failed for an envelope: %v", err)
+	}
+	if string(got) != want {
+		t.Errorf("unexpected JSON:\n%s", got)
+	}
+
+	if _, err := GobToJSON(data, "debug.Missing"); !errors.Is(err, ErrUnknownType) {
+		t.Errorf("expected ErrUnknownType, got %v", err)
+	}
+	if _, err := GobToJSON(data[:len(data)/2], "debug.Order"); err == nil || !strings.Contains(err.Error(), "debug.Order") {
+		t.Errorf("expected a decode error naming the type, got %v", err)
+	}
+}
@@ -0,0 +1,160 @@
+//go:build !tinygo
+
+package serializer
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// NewGobSerializerWithOptions creates a Gob serializer configured by opts
+func NewGobSerializerWithOptions(opts ...Option) Serializer {
+	s := &GobSerializer{opts: newOptions(opts)}
+	s.opts.bindLogger(Binary)
+	if s.opts.gobRegistry != nil {
+		registerGobRegistry(s.opts.gobRegistry)
+	}
+	return s
+}
+
+// WithGobEnvelope writes each value's registered type name ahead of it, as
+// NewGobEnvelopeSerializer does, so DeserializeAny can decode it
+func WithGobEnvelope() Option {
+	return func(o *options) {
+		o.gobEnvelope = true
+	}
+}
+
+// WithGobStrict turns off automatic registration: serializing fails with
+// ErrUnknownType when a value held in an interface has a type that was not
+// registered with RegisterGobType or PreRegisterTypes. Predeclared types such as
+// int and string need no registration. Types registered directly with
+// gob.Register are not visible to the check, so register them with one of those instead.
+func WithGobStrict() Option {
+	return func(o *options) {
+		o.gobStrict = true
+	}
+}
+
+// WithGobBufferSize preallocates n bytes for each value Serialize encodes, which
+// saves regrowing the buffer when typical payloads are known to be large
+func WithGobBufferSize(n int) Option {
+	return func(o *options) {
+		o.gobBufferSize = max(n, 0)
+	}
+}
+
+// WithGobTypeNamer names the types the serializer registers automatically, in
+// place of gob's default of package path and type name. Returning "" keeps the
+// default for a type. Named types are also recorded for DeserializeAny.
+// Registrations are process-wide, so the first name a type gets is kept.
+func WithGobTypeNamer(namer func(t reflect.Type) string) Option {
+	return func(o *options) {
+		o.gobTypeNamer = namer
+	}
+}
+
+// WithGobUnknownType sets a callback for decoding values held in interfaces whose
+// type name is not registered, e.g. types retired since the data was written. It
+// is called once per unknown name with that name and returns a sample of a type
+// to decode those values into, which is registered with gob under the name; nil
+// fails the decode as before. gob only decodes into compatible types, so a struct
+// needs a substitute struct, which may declare just the fields worth keeping, and
+// a map can be read into a generic map type. As with gob.RegisterName, a type can
+// stand in for one name only.
+//
+// Decoding restarts after each resolution. DeserializeFrom keeps what it reads
+// from the stream to do so; stream decoders do not resolve unknown types.
+func WithGobUnknownType(resolve func(name string) any) Option {
+	return func(o *options) {
+		o.gobUnknown = resolve
+	}
+}
+
+// WithGobDeterministic makes equal values serialize to identical bytes, so
+// payloads can be deduplicated and content-hashed: map entries are written in a
+// sorted order, and type ids, which gob assigns in the order a process first
+// encodes types, are renumbered. The rewrite costs an extra pass over the output.
+// Values that encode themselves, through GobEncode or MarshalBinary, are written
+// as they produce them. Stream encoders are not affected.
+func WithGobDeterministic() Option {
+	return func(o *options) {
+		o.gobDeterministic = true
+	}
+}
+
+// WithGobTypeHeaders leaves gob's type definitions out of the payloads
+// SerializeWithTypeInfo writes for types that cannot hold interfaces, which for
+// small records are often larger than the value itself. DeserializeWithTypeInfo
+// supplies the definitions again from the target type, so reader and writer must
+// agree on the type's fields; TypeHeader returns the definitions to store with a
+// batch of records or to compare across versions. Payloads with definitions still
+// decode. Envelope serializers are not affected.
+func WithGobTypeHeaders() Option {
+	return func(o *options) {
+		o.gobTypeHeaders = true
+	}
+}
+
+// WithGobTypeRegistry names the types of values held in interfaces, such as the
+// elements of []any and map[string]any, after types, or DefaultTypeRegistry if
+// types is nil. Writers register those types with gob under their registry names,
+// and readers resolve the names back through the registry, so a reader sharing the
+// registry decodes mixed collections without registering each type with gob.
+// Strict serializers accept registry types. Register types with gob through the
+// registry only: gob keeps the first name a type is given, so a type already
+// encoded under its default name keeps that name.
+func WithGobTypeRegistry(types *TypeRegistry) Option {
+	if types == nil {
+		types = DefaultTypeRegistry
+	}
+	return func(o *options) {
+		o.gobRegistry = types
+	}
+}
+
+// prepare registers the concrete types of the interface values in v, or in strict
+// mode checks that they are registered already
+func (s *GobSerializer) prepare(v any) error {
+	if r := s.opts.gobRegistry; r != nil {
+		return visitInterfaceTypes(v, func(t reflect.Type) error {
+			if registerRegistryType(r, t) {
+				return nil
+			}
+			if s.opts.gobStrict {
+				return checkGobTypeRegistered(t)
+			}
+			registerTypeIfNeeded(t, s.opts.gobTypeNamer)
+			return nil
+		})
+	}
+	if s.opts.gobStrict {
+		return visitInterfaceTypes(v, checkGobTypeRegistered)
+	}
+	return visitInterfaceTypes(v, func(t reflect.Type) error {
+		registerTypeIfNeeded(t, s.opts.gobTypeNamer)
+		return nil
+	})
+}
+
+// checkGobTypeRegistered fails unless t is predeclared or registered with
+// RegisterGobType or PreRegisterTypes
+func checkGobTypeRegistered(t reflect.Type) error {
+	base := derefType(t)
+	if base.PkgPath() == "" && base.Name() != "" {
+		return nil
+	}
+	registrationMu.RLock()
+	explicit := explicitTypes[base]
+	registrationMu.RUnlock()
+	if explicit {
+		return nil
+	}
+	if _, ok := gobTypes.nameOfType(t); ok {
+		return nil
+	}
+	if _, ok := gobTypes.nameOfType(base); ok {
+		return nil
+	}
+	return fmt.Errorf("%w: %s held in an interface", ErrUnknownType, t)
+}
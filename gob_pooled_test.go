@@ -0,0 +1,22 @@
+//go:build !tinygo
+
+package serializer
+
+import (
+	"testing"
+)
+
+func TestPooledGobSerializers(t *testing.T) {
+	serializers := []struct {
+		name string
+		s    Serializer
+	}{
+		{"Gob", NewGobSerializer()},
+		{"GobDeterministic", NewGobSerializerWithOptions(WithGobDeterministic())},
+	}
+	for _, tc := range serializers {
+		t.Run(tc.name, func(t *testing.T) {
+			testPooledSerializer(t, tc.s)
+		})
+	}
+}
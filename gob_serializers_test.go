@@ -0,0 +1,18 @@
+//go:build !tinygo
+
+package serializer_test
+
+import (
+	"github.com/MichaelAJay/go-serializer"
+)
+
+// gobSerializers returns the gob serializers that tests shared with TinyGo builds
+// run against. TinyGo builds leave gob out and get none.
+func gobSerializers() []serializer.Serializer {
+	return []serializer.Serializer{serializer.NewGobSerializer()}
+}
+
+// gobFormats returns the DefaultRegistry formats served by gob
+func gobFormats() []serializer.Format {
+	return []serializer.Format{serializer.Binary}
+}
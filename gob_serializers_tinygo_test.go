@@ -0,0 +1,17 @@
+//go:build tinygo
+
+package serializer_test
+
+import (
+	"github.com/MichaelAJay/go-serializer"
+)
+
+// gobSerializers returns no serializers, as TinyGo builds leave gob out
+func gobSerializers() []serializer.Serializer {
+	return nil
+}
+
+// gobFormats returns no formats, as TinyGo builds register no gob serializer
+func gobFormats() []serializer.Format {
+	return nil
+}
@@ -0,0 +1,97 @@
+//go:build !tinygo
+
+package serializer
+
+import (
+	"encoding/gob"
+	"errors"
+	"io"
+)
+
+// GobStreamEncoder writes many values to one stream with a single gob encoder, so
+// each type's descriptor is sent once per stream instead of once per value as
+// with SerializeTo. Read the stream back with a GobStreamDecoder.
+// A GobStreamEncoder is not safe for concurrent use.
+type GobStreamEncoder struct {
+	s   *GobSerializer
+	enc *gob.Encoder
+}
+
+var (
+	_ StreamingSerializer = (*GobSerializer)(nil)
+	_ StreamEncoder       = (*GobStreamEncoder)(nil)
+	_ StreamDecoder       = (*GobStreamDecoder)(nil)
+)
+
+// NewGobStreamEncoder creates a stream encoder writing to w
+func NewGobStreamEncoder(w io.Writer) *GobStreamEncoder {
+	return (&GobSerializer{}).newStreamEncoder(w)
+}
+
+// NewStreamEncoder implements StreamingSerializer with a *GobStreamEncoder
+func (s *GobSerializer) NewStreamEncoder(w io.Writer) StreamEncoder {
+	return s.newStreamEncoder(w)
+}
+
+// newStreamEncoder creates a stream encoder writing to w with this serializer's settings
+func (s *GobSerializer) newStreamEncoder(w io.Writer) *GobStreamEncoder {
+	return &GobStreamEncoder{s: s, enc: gob.NewEncoder(w)}
+}
+
+// Encode writes v to the stream. Each value reaches the writer in a single Write.
+func (e *GobStreamEncoder) Encode(v any) error {
+	if v == nil {
+		return errors.New("cannot serialize nil value")
+	}
+	if err := e.s.prepare(v); err != nil {
+		return err
+	}
+	return e.s.encode(e.enc, v)
+}
+
+// Close implements StreamEncoder. Values are not buffered, so there is nothing to flush.
+func (e *GobStreamEncoder) Close() error {
+	return nil
+}
+
+// GobStreamDecoder reads the values written by a GobStreamEncoder with a single
+// gob decoder, which remembers the type descriptors already received.
+// A GobStreamDecoder is not safe for concurrent use.
+type GobStreamDecoder struct {
+	s   *GobSerializer
+	r   streamReader
+	dec *gob.Decoder
+}
+
+// NewGobStreamDecoder creates a stream decoder reading from r. Unless r implements
+// io.ByteScanner, gob reads ahead of the value being decoded.
+func NewGobStreamDecoder(r io.Reader) *GobStreamDecoder {
+	return (&GobSerializer{}).newStreamDecoder(r)
+}
+
+// NewStreamDecoder implements StreamingSerializer with a *GobStreamDecoder
+func (s *GobSerializer) NewStreamDecoder(r io.Reader) StreamDecoder {
+	return s.newStreamDecoder(r)
+}
+
+// newStreamDecoder creates a stream decoder reading from r with this serializer's settings
+func (s *GobSerializer) newStreamDecoder(r io.Reader) *GobStreamDecoder {
+	sr := newStreamReader(r)
+	return &GobStreamDecoder{s: s, r: sr, dec: gob.NewDecoder(sr)}
+}
+
+// Decode reads the next value into v. It returns io.EOF when the stream ends
+// cleanly between values.
+func (d *GobStreamDecoder) Decode(v any) error {
+	return d.s.decode(d.dec, v)
+}
+
+// More implements StreamDecoder
+func (d *GobStreamDecoder) More() bool {
+	return peekStream(d.r) == nil
+}
+
+// Close implements StreamDecoder
+func (d *GobStreamDecoder) Close() error {
+	return nil
+}
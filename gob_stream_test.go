@@ -0,0 +1,67 @@
+//go:build !tinygo
+
+package serializer
+
+import (
+	"bytes"
+	"io"
+	"testing"
+)
+
+func TestGobStream(t *testing.T) {
+	var stream bytes.Buffer
+	enc := NewGobStreamEncoder(&stream)
+	for i := 0; i < 10; i++ {
+		if err := enc.Encode(gobRPCRequest{Method: "sum", Args: []int{i, i}}); err != nil {
+			t.Fatalf("Encode failed: %v", err)
+		}
+	}
+	if err := enc.Encode(nil); err == nil {
+		t.Error("expected error encoding nil")
+	}
+
+	// Type descriptors are sent once, so the stream is far smaller than
+	// separately serialized values
+	single, err := NewGobSerializer().Serialize(gobRPCRequest{Method: "sum", Args: []int{0, 0}})
+	if err != nil {
+		t.Fatalf("Serialize failed: %v", err)
+	}
+	if stream.Len() >= 10*len(single)/2 {
+		t.Errorf("expected a compact stream, got %d bytes for 10 values (%d each alone)", stream.Len(), len(single))
+	}
+
+	dec := NewGobStreamDecoder(&stream)
+	for i := 0; i < 10; i++ {
+		var req gobRPCRequest
+		if err := dec.Decode(&req); err != nil {
+			t.Fatalf("Decode %d failed: %v", i, err)
+		}
+		if req.Method != "sum" || req.Args[0] != i {
+			t.Errorf("value %d mismatch: %+v", i, req)
+		}
+	}
+	var extra gobRPCRequest
+	if err := dec.Decode(&extra); err != io.EOF {
+		t.Errorf("expected io.EOF, got %v", err)
+	}
+}
+
+func TestGobStreamEnvelope(t *testing.T) {
+	MustRegisterGobType("orders.Placed", gobOrderPlaced{})
+	s := NewGobEnvelopeSerializer().(*GobSerializer)
+
+	var stream bytes.Buffer
+	enc := s.NewStreamEncoder(&stream)
+	for i := 0; i < 3; i++ {
+		if err := enc.Encode(gobOrderPlaced{OrderID: "o", Total: i}); err != nil {
+			t.Fatalf("Encode failed: %v", err)
+		}
+	}
+	dec := s.NewStreamDecoder(&stream)
+	for i := 0; i < 3; i++ {
+		var ev gobOrderPlaced
+		if err := dec.Decode(&ev); err != nil || ev.Total != i {
+			t.Fatalf("Decode %d failed: %+v %v", i, ev, err)
+		}
+	}
+}
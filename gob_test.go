@@ -0,0 +1,525 @@
+//go:build !tinygo
+
+package serializer
+
+import (
+	"bytes"
+	"encoding/gob"
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+type gobLeaf struct {
+	Value int
+}
+
+type gobBranch struct {
+	Name  string
+	Child any
+}
+
+type gobEnvelope struct {
+	Payload any
+	Items   []any
+	ByName  map[string]any
+	Next    *gobEnvelope
+}
+
+type gobStatic struct {
+	Rows  []gobStaticRow
+	Index map[string]*gobStaticRow
+}
+
+type gobStaticRow struct {
+	Cells []gobStaticCell
+}
+
+type gobStaticCell struct {
+	Text string
+}
+
+func TestGobRegistersNestedInterfaceTypes(t *testing.T) {
+	in := gobEnvelope{
+		Payload: gobBranch{Name: "outer", Child: gobLeaf{Value: 1}},
+		Items:   []any{&gobLeaf{Value: 2}, []gobLeaf{{Value: 3}}},
+		ByName:  map[string]any{"k": map[string]gobLeaf{"x": {Value: 4}}},
+	}
+	in.Next = &gobEnvelope{Payload: gobLeaf{Value: 5}}
+
+	s := NewGobSerializer()
+	data, err := s.Serialize(in)
+	if err != nil {
+		t.Fatalf("Serialize failed: %v", err)
+	}
+	var out gobEnvelope
+	if err := s.Deserialize(data, &out); err != nil {
+		t.Fatalf("Deserialize failed: %v", err)
+	}
+	branch, ok := out.Payload.(gobBranch)
+	if !ok || branch.Child != (gobLeaf{Value: 1}) {
+		t.Errorf("unexpected payload %#v", out.Payload)
+	}
+	// gob flattens pointers, so the registered value type comes back
+	if out.Items[0] != (gobLeaf{Value: 2}) || !reflect.DeepEqual(out.Items[1], []gobLeaf{{Value: 3}}) {
+		t.Errorf("unexpected items %#v", out.Items)
+	}
+	if out.ByName["k"].(map[string]gobLeaf)["x"].Value != 4 || out.Next.Payload != (gobLeaf{Value: 5}) {
+		t.Errorf("unexpected nested values %#v", out)
+	}
+}
+
+func TestGobRegisterTypeTree(t *testing.T) {
+	registerTypeIfNeeded(reflect.TypeOf(&gobStatic{}), nil)
+
+	registrationMu.RLock()
+	defer registrationMu.RUnlock()
+	for _, typ := range []reflect.Type{
+		reflect.TypeOf(gobStatic{}),
+		reflect.TypeOf(gobStaticRow{}),
+		reflect.TypeOf(gobStaticCell{}),
+		reflect.TypeOf([]gobStaticCell{}),
+		reflect.TypeOf(map[string]*gobStaticRow{}),
+	} {
+		if !registeredTypes[typ] {
+			t.Errorf("expected %s to be registered", typ)
+		}
+	}
+}
+
+type gobRenamed struct {
+	Amount int
+	Note   gobRenamedNote
+}
+
+type gobRenamedNote struct {
+	Text string
+}
+
+func TestRegisterGobType(t *testing.T) {
+	if err := RegisterGobType("billing.Renamed", gobRenamed{}); err != nil {
+		t.Fatalf("RegisterGobType failed: %v", err)
+	}
+	// Registering the same pair again is a no-op
+	if err := RegisterGobType("billing.Renamed", gobRenamed{}); err != nil {
+		t.Errorf("repeat registration failed: %v", err)
+	}
+	if err := RegisterGobType("billing.Other", gobRenamed{}); err == nil {
+		t.Error("expected error registering a type under a second name")
+	}
+	if err := RegisterGobType("billing.Renamed", gobLeaf{}); err == nil {
+		t.Error("expected error reusing a name for another type")
+	}
+
+	s := NewGobSerializer()
+	data, err := s.Serialize(gobEnvelope{Payload: gobRenamed{Amount: 7, Note: gobRenamedNote{"x"}}})
+	if err != nil {
+		t.Fatalf("Serialize failed: %v", err)
+	}
+	if !bytes.Contains(data, []byte("billing.Renamed")) {
+		t.Error("expected the stable name on the wire")
+	}
+	if bytes.Contains(data, []byte("go-serializer")) {
+		t.Error("expected no package path on the wire")
+	}
+	var out gobEnvelope
+	if err := s.Deserialize(data, &out); err != nil {
+		t.Fatalf("Deserialize failed: %v", err)
+	}
+	if out.Payload != (gobRenamed{Amount: 7, Note: gobRenamedNote{"x"}}) {
+		t.Errorf("unexpected payload %#v", out.Payload)
+	}
+
+	registrationMu.RLock()
+	nested := registeredTypes[reflect.TypeOf(gobRenamedNote{})]
+	registrationMu.RUnlock()
+	if !nested {
+		t.Error("expected contained types to be registered")
+	}
+}
+
+type gobOrderPlaced struct {
+	OrderID string
+	Total   int
+}
+
+type gobOrderShipped struct {
+	OrderID string
+	Carrier string
+}
+
+func TestGobEnvelopeDeserializeAny(t *testing.T) {
+	MustRegisterGobType("orders.Placed", gobOrderPlaced{})
+	MustRegisterGobType("orders.Shipped", &gobOrderShipped{})
+
+	s := NewGobEnvelopeSerializer().(*GobSerializer)
+	placed, err := s.Serialize(gobOrderPlaced{OrderID: "o-1", Total: 42})
+	if err != nil {
+		t.Fatalf("Serialize failed: %v", err)
+	}
+	var shipped bytes.Buffer
+	if err := s.SerializeTo(&shipped, &gobOrderShipped{OrderID: "o-1", Carrier: "ups"}); err != nil {
+		t.Fatalf("SerializeTo failed: %v", err)
+	}
+
+	got, err := s.DeserializeAny(placed)
+	if err != nil {
+		t.Fatalf("DeserializeAny failed: %v", err)
+	}
+	if got != (gobOrderPlaced{OrderID: "o-1", Total: 42}) {
+		t.Errorf("unexpected value %#v", got)
+	}
+	got, err = s.DeserializeAny(shipped.Bytes())
+	if err != nil {
+		t.Fatalf("DeserializeAny failed: %v", err)
+	}
+	if ev, ok := got.(*gobOrderShipped); !ok || ev.Carrier != "ups" {
+		t.Errorf("expected *gobOrderShipped, got %#v", got)
+	}
+
+	// Typed decoding skips the name
+	var typed gobOrderPlaced
+	if err := s.Deserialize(placed, &typed); err != nil || typed.Total != 42 {
+		t.Errorf("Deserialize failed: %+v %v", typed, err)
+	}
+	info, err := s.DeserializeWithTypeInfo(placed, TypeInfo{Type: reflect.TypeOf(gobOrderPlaced{}), TypeName: "orders.Placed"})
+	if err != nil || info.(gobOrderPlaced).OrderID != "o-1" {
+		t.Errorf("DeserializeWithTypeInfo failed: %#v %v", info, err)
+	}
+
+	if _, err := s.Serialize(gobLeaf{}); !errors.Is(err, ErrUnknownType) {
+		t.Errorf("expected ErrUnknownType for an unnamed type, got %v", err)
+	}
+	if _, err := NewGobSerializer().(*GobSerializer).DeserializeAny(placed); err == nil {
+		t.Error("expected DeserializeAny to require envelope mode")
+	}
+
+	var unknown bytes.Buffer
+	enc := gob.NewEncoder(&unknown)
+	_ = enc.Encode("orders.Cancelled")
+	_ = enc.Encode(gobOrderPlaced{})
+	if _, err := s.DeserializeAny(unknown.Bytes()); !errors.Is(err, ErrUnknownType) {
+		t.Errorf("expected ErrUnknownType for an unregistered name, got %v", err)
+	}
+}
+
+type gobStrictPayload struct {
+	ID int
+}
+
+type gobStrictUnnamed struct {
+	ID int
+}
+
+type gobNamedByNamer struct {
+	Label string
+}
+
+type gobNamerHolder struct {
+	Item any
+}
+
+func TestNewGobSerializerWithOptions(t *testing.T) {
+	t.Run("strict", func(t *testing.T) {
+		MustRegisterGobType("strict.Payload", gobStrictPayload{})
+		s := NewGobSerializerWithOptions(WithGobStrict())
+
+		data, err := s.Serialize(gobEnvelope{Payload: gobStrictPayload{ID: 7}})
+		if err != nil {
+			t.Fatalf("Serialize failed for a registered type: %v", err)
+		}
+		var got gobEnvelope
+		if err := s.Deserialize(data, &got); err != nil || got.Payload != (gobStrictPayload{ID: 7}) {
+			t.Errorf("round trip failed: %#v %v", got, err)
+		}
+		if _, err := s.Serialize(gobEnvelope{Payload: 42}); err != nil {
+			t.Errorf("predeclared types need no registration, got %v", err)
+		}
+
+		_, err = s.Serialize(gobEnvelope{Payload: gobStrictUnnamed{ID: 1}})
+		if !errors.Is(err, ErrUnknownType) {
+			t.Errorf("expected ErrUnknownType, got %v", err)
+		}
+		if err := s.SerializeTo(io.Discard, []any{gobStrictUnnamed{}}); !errors.Is(err, ErrUnknownType) {
+			t.Errorf("expected ErrUnknownType from SerializeTo, got %v", err)
+		}
+		if registeredTypes[reflect.TypeOf(gobStrictUnnamed{})] {
+			t.Error("strict mode must not register types")
+		}
+	})
+
+	t.Run("buffer size", func(t *testing.T) {
+		s := NewGobSerializerWithOptions(WithGobBufferSize(4096))
+		data, err := s.Serialize("small")
+		if err != nil {
+			t.Fatalf("Serialize failed: %v", err)
+		}
+		if cap(data) < 4096 {
+			t.Errorf("expected a preallocated buffer, got cap %d", cap(data))
+		}
+		var got string
+		if err := s.Deserialize(data, &got); err != nil || got != "small" {
+			t.Errorf("round trip failed: %q %v", got, err)
+		}
+	})
+
+	t.Run("type namer", func(t *testing.T) {
+		namer := func(t reflect.Type) string { return "app." + t.Name() }
+		s := NewGobSerializerWithOptions(WithGobEnvelope(), WithGobTypeNamer(namer)).(*GobSerializer)
+
+		data, err := s.Serialize(gobNamerHolder{Item: gobNamedByNamer{Label: "x"}})
+		if err != nil {
+			t.Fatalf("Serialize failed: %v", err)
+		}
+		if !bytes.Contains(data, []byte("app.gobNamedByNamer")) {
+			t.Error("expected the namer's name on the wire")
+		}
+		if _, ok := gobTypes.TypeOf("app.gobNamedByNamer"); !ok {
+			t.Error("expected the named type to be recorded")
+		}
+		got, err := s.DeserializeAny(data)
+		if err != nil {
+			t.Fatalf("DeserializeAny failed: %v", err)
+		}
+		if h, ok := got.(gobNamerHolder); !ok || h.Item != (gobNamedByNamer{Label: "x"}) {
+			t.Errorf("unexpected value %#v", got)
+		}
+	})
+}
+
+type gobPreRoot struct {
+	Items []gobPreItem
+	Extra any
+}
+
+type gobPreItem struct {
+	Meta map[string]gobPreMeta
+}
+
+type gobPreMeta struct {
+	Note string
+}
+
+type gobPreImpl struct {
+	Code int
+}
+
+func TestPreRegisterTypes(t *testing.T) {
+	PreRegisterTypes(gobPreRoot{Extra: &gobPreImpl{}}, nil)
+
+	registrationMu.RLock()
+	for _, typ := range []reflect.Type{
+		reflect.TypeOf(gobPreRoot{}),
+		reflect.TypeOf(gobPreItem{}),
+		reflect.TypeOf(gobPreMeta{}),
+		reflect.TypeOf(gobPreImpl{}),
+	} {
+		if !registeredTypes[typ] {
+			t.Errorf("expected %s to be registered", typ)
+		}
+	}
+	registrationMu.RUnlock()
+
+	// Strict serializers accept pre-registered types in interfaces
+	s := NewGobSerializerWithOptions(WithGobStrict())
+	data, err := s.Serialize(gobEnvelope{Payload: gobPreRoot{Extra: &gobPreImpl{Code: 5}}})
+	if err != nil {
+		t.Fatalf("Serialize failed: %v", err)
+	}
+	var got gobEnvelope
+	if err := s.Deserialize(data, &got); err != nil {
+		t.Fatalf("Deserialize failed: %v", err)
+	}
+	if root, ok := got.Payload.(gobPreRoot); !ok || root.Extra != (gobPreImpl{Code: 5}) {
+		t.Errorf("unexpected value %#v", got.Payload)
+	}
+}
+
+type gobRetiredEvent struct {
+	Kind  string
+	Count int
+}
+
+type gobRetiredSubstitute struct {
+	Kind string
+}
+
+type gobRetiredAttrs map[string]uint16
+
+type gobRetiredUnresolved struct {
+	ID int
+}
+
+// retiredPayload encodes v under a name that is registered while encoding, then
+// renames it on the wire to an unregistered one of the same length
+func retiredPayload(t *testing.T, registered, unregistered string, v any) []byte {
+	t.Helper()
+	gob.RegisterName(registered, v)
+	data, err := NewGobSerializer().Serialize(gobBranch{Name: "root", Child: v})
+	if err != nil {
+		t.Fatalf("Serialize failed: %v", err)
+	}
+	return bytes.Replace(data, []byte(registered), []byte(unregistered), 1)
+}
+
+func TestWithGobUnknownType(t *testing.T) {
+	event := retiredPayload(t, "retired.Evenx", "retired.Event", gobRetiredEvent{Kind: "signup", Count: 3})
+	attrs := retiredPayload(t, "retired.Attrx", "retired.Attrs", gobRetiredAttrs{"a": 1})
+
+	var plain gobBranch
+	if err := NewGobSerializer().Deserialize(event, &plain); err == nil {
+		t.Fatal("expected the renamed type to be unknown")
+	}
+
+	var asked []string
+	s := NewGobSerializerWithOptions(WithGobUnknownType(func(name string) any {
+		asked = append(asked, name)
+		switch name {
+		case "retired.Event":
+			return gobRetiredSubstitute{}
+		case "retired.Attrs":
+			return map[string]uint16{}
+		}
+		return nil
+	}))
+
+	var got gobBranch
+	if err := s.Deserialize(event, &got); err != nil {
+		t.Fatalf("Deserialize failed: %v", err)
+	}
+	if got.Name != "root" || got.Child != (gobRetiredSubstitute{Kind: "signup"}) {
+		t.Errorf("unexpected value %#v", got)
+	}
+
+	got = gobBranch{}
+	if err := s.DeserializeFrom(iotest.OneByteReader(bytes.NewReader(attrs)), &got); err != nil {
+		t.Fatalf("DeserializeFrom failed: %v", err)
+	}
+	if m, ok := got.Child.(map[string]uint16); !ok || m["a"] != 1 {
+		t.Errorf("unexpected value %#v", got.Child)
+	}
+
+	// Resolved names stay registered
+	if err := NewGobSerializer().Deserialize(event, &got); err != nil {
+		t.Errorf("expected the substitute to stay registered, got %v", err)
+	}
+	if !reflect.DeepEqual(asked, []string{"retired.Event", "retired.Attrs"}) {
+		t.Errorf("unexpected callback calls %v", asked)
+	}
+
+	unresolved := retiredPayload(t, "retired.Othex", "retired.Other", gobRetiredUnresolved{})
+	if err := s.Deserialize(unresolved, &got); err == nil {
+		t.Error("expected a decode error when the callback returns nil")
+	}
+}
+
+type gobWithTypesItem struct {
+	At int64
+}
+
+func TestNewGobSerializerWithTypes(t *testing.T) {
+	s := NewGobSerializerWithTypes(gobWithTypesItem{}, map[string]gobWithTypesItem{})
+
+	registrationMu.RLock()
+	registered := registeredTypes[reflect.TypeOf(gobWithTypesItem{})]
+	registrationMu.RUnlock()
+	if !registered {
+		t.Fatal("expected the type to be registered at construction")
+	}
+
+	data, err := s.Serialize(gobEnvelope{Payload: map[string]gobWithTypesItem{"a": {At: 1}}})
+	if err != nil {
+		t.Fatalf("Serialize failed: %v", err)
+	}
+	var got gobEnvelope
+	if err := s.Deserialize(data, &got); err != nil {
+		t.Fatalf("Deserialize failed: %v", err)
+	}
+	if m, ok := got.Payload.(map[string]gobWithTypesItem); !ok || m["a"].At != 1 {
+		t.Errorf("unexpected value %#v", got.Payload)
+	}
+}
+
+type heteroCircle struct {
+	Radius float64
+}
+
+type heteroSquare struct {
+	Side int
+}
+
+type heteroTriangle struct {
+	Side int
+}
+
+type heteroTriangleV0 struct {
+	Side int
+}
+
+func TestWithGobTypeRegistry(t *testing.T) {
+	types := NewTypeRegistry()
+	types.MustRegister("shapes.Circle", heteroCircle{})
+	types.MustRegister("shapes.Square", &heteroSquare{})
+
+	s := NewGobSerializerWithOptions(WithGobTypeRegistry(types))
+	in := []any{heteroCircle{Radius: 1.5}, &heteroSquare{Side: 2}, "label", map[string]any{"c": heteroCircle{Radius: 3}}}
+	data, err := s.Serialize(in)
+	if err != nil {
+		t.Fatalf("Serialize failed: %v", err)
+	}
+	if !bytes.Contains(data, []byte("shapes.Circle")) || !bytes.Contains(data, []byte("shapes.Square")) {
+		t.Error("expected registry names on the wire")
+	}
+	var got []any
+	if err := s.Deserialize(data, &got); err != nil {
+		t.Fatalf("Deserialize failed: %v", err)
+	}
+	if got[0] != (heteroCircle{Radius: 1.5}) {
+		t.Errorf("unexpected element %#v", got[0])
+	}
+	if sq, ok := got[1].(*heteroSquare); !ok || sq.Side != 2 {
+		t.Errorf("expected *heteroSquare as registered, got %#v", got[1])
+	}
+	if m, ok := got[3].(map[string]any); !ok || m["c"] != (heteroCircle{Radius: 3}) {
+		t.Errorf("unexpected element %#v", got[3])
+	}
+
+	// Strict serializers accept registry types
+	strict := NewGobSerializerWithOptions(WithGobStrict(), WithGobTypeRegistry(types))
+	if _, err := strict.Serialize([]any{heteroCircle{}}); err != nil {
+		t.Errorf("expected registry types to pass strict mode, got %v", err)
+	}
+}
+
+func TestWithGobTypeRegistryReader(t *testing.T) {
+	// Written by a process that named the type differently, renamed on the wire
+	// to a name gob has not seen
+	data := retiredPayload(t, "hetero.TrianglX", "hetero.Triangle", heteroTriangleV0{Side: 4})
+
+	types := NewTypeRegistry()
+	types.MustRegister("hetero.Triangle", heteroTriangle{})
+	var got gobBranch
+	if err := NewGobSerializerWithOptions(WithGobTypeRegistry(types)).Deserialize(data, &got); err != nil {
+		t.Fatalf("Deserialize failed: %v", err)
+	}
+	if got.Child != (heteroTriangle{Side: 4}) {
+		t.Errorf("expected the registry type, got %#v", got.Child)
+	}
+}
+
+func TestSerializeWithOptionsFallback(t *testing.T) {
+	s := NewGobSerializer()
+	if _, ok := s.(OptionsSerializer); ok {
+		t.Skip("gob serializer accepts options per call")
+	}
+	data, err := SerializeWithOptions(s, "value", WithIndent("  "))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want, _ := s.Serialize("value"); !bytes.Equal(data, want) || strings.Contains(string(data), "  ") {
+		t.Errorf("fallback = %q, want %q", data, want)
+	}
+}
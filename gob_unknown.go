@@ -0,0 +1,91 @@
+//go:build !tinygo
+
+package serializer
+
+import (
+	"bytes"
+	"encoding/gob"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// decodeData decodes data into v
+func (s *GobSerializer) decodeData(data []byte, v any) error {
+	if err := s.opts.checkInputSize(len(data)); err != nil {
+		return err
+	}
+	return s.resolveUnknown(v, func() error {
+		return s.decode(gob.NewDecoder(bytes.NewReader(data)), v)
+	})
+}
+
+// decodeReader decodes the next value in r into v. When unknown types can be
+// resolved, the bytes read are kept so the decode can restart.
+func (s *GobSerializer) decodeReader(r io.Reader, v any) error {
+	if s.opts.gobUnknown == nil && s.opts.gobRegistry == nil {
+		return s.decode(gob.NewDecoder(r), v)
+	}
+	var read bytes.Buffer
+	rest := io.TeeReader(r, &read)
+	return s.resolveUnknown(v, func() error {
+		src := io.MultiReader(bytes.NewReader(read.Bytes()), rest)
+		return s.decode(gob.NewDecoder(src), v)
+	})
+}
+
+// resolveUnknown runs decode, and while it fails on an interface type name that
+// is not registered, registers the type the registry or the WithGobUnknownType
+// callback gives for it and runs decode again on a cleared v
+func (s *GobSerializer) resolveUnknown(v any, decode func() error) error {
+	err := decode()
+	if s.opts.gobUnknown == nil && s.opts.gobRegistry == nil {
+		return err
+	}
+	resolved := make(map[string]bool)
+	for err != nil {
+		name, ok := unregisteredGobName(err)
+		if !ok || resolved[name] {
+			return err
+		}
+		resolved[name] = true
+		sample := s.unknownTypeSample(name)
+		if sample == nil {
+			return err
+		}
+		if regErr := registerGobName(name, sample); regErr != nil {
+			return regErr
+		}
+		if rv := reflect.ValueOf(v); rv.Kind() == reflect.Ptr && !rv.IsNil() {
+			rv.Elem().SetZero()
+		}
+		err = decode()
+	}
+	return nil
+}
+
+// unknownTypeSample returns a sample of the type to decode values named name
+// into, from the type registry or else the WithGobUnknownType callback
+func (s *GobSerializer) unknownTypeSample(name string) any {
+	if r := s.opts.gobRegistry; r != nil {
+		if t, ok := r.TypeOf(name); ok {
+			return reflect.Zero(t).Interface()
+		}
+	}
+	if s.opts.gobUnknown != nil {
+		return s.opts.gobUnknown(name)
+	}
+	return nil
+}
+
+// unregisteredGobName extracts the type name from gob's error for an interface
+// value whose name is not registered
+func unregisteredGobName(err error) (string, bool) {
+	_, quoted, ok := strings.Cut(err.Error(), "name not registered for interface: ")
+	if !ok {
+		return "", false
+	}
+	name, uerr := strconv.Unquote(quoted)
+	return name, uerr == nil
+}
@@ -0,0 +1,264 @@
+// Package httpserializer reads and writes net/http bodies with the serializers
+// of a serializer.Registry, negotiating the format from the Accept and
+// Content-Type headers and compressing responses the client accepts compressed.
+//
+//	func getUser(w http.ResponseWriter, r *http.Request) {
+//		if err := httpserializer.WriteResponse(w, r, user); err != nil {
+//			http.Error(w, err.Error(), httpserializer.StatusCode(err))
+//		}
+//	}
+//
+//	func createUser(w http.ResponseWriter, r *http.Request) {
+//		var req CreateUser
+//		if err := httpserializer.ReadRequest(r, &req); err != nil {
+//			http.Error(w, err.Error(), httpserializer.StatusCode(err))
+//			return
+//		}
+//	}
+package httpserializer
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/MichaelAJay/go-serializer"
+	"github.com/MichaelAJay/go-serializer/internal/contenttype"
+	"github.com/klauspost/compress/zstd"
+)
+
+// DefaultMinCompressSize is the smallest response body compressed by default
+const DefaultMinCompressSize = 1024
+
+// ErrNotAcceptable is returned when no registered serializer satisfies the
+// Accept header
+var ErrNotAcceptable = serializer.ErrNotAcceptable
+
+var (
+	// ErrUnsupportedMediaType is returned when the request Content-Type has no
+	// registered serializer
+	ErrUnsupportedMediaType = errors.New("unsupported request content type")
+	// ErrUnsupportedEncoding is returned for request bodies in a Content-Encoding
+	// other than gzip, zstd or identity
+	ErrUnsupportedEncoding = errors.New("unsupported request content encoding")
+
+	// errResponse wraps the errors of writing a negotiated response, which are
+	// the server's fault
+	errResponse = errors.New("cannot write response")
+)
+
+// Codec reads requests and writes responses with the serializers of Registry.
+// The zero value uses serializer.DefaultRegistry and is ready to use.
+type Codec struct {
+	// Registry holds the serializers; nil means serializer.DefaultRegistry
+	Registry *serializer.Registry
+	// MinCompressSize is the smallest response body that is compressed. Zero
+	// means DefaultMinCompressSize; a negative value disables compression.
+	MinCompressSize int
+}
+
+// defaultCodec serves the package-level functions
+var defaultCodec Codec
+
+// WriteResponse writes v with status 200 OK using Codec{}
+func WriteResponse(w http.ResponseWriter, r *http.Request, v any) error {
+	return defaultCodec.WriteResponse(w, r, v)
+}
+
+// ReadRequest decodes the body of r into v using Codec{}
+func ReadRequest(r *http.Request, v any) error {
+	return defaultCodec.ReadRequest(r, v)
+}
+
+func (c *Codec) registry() *serializer.Registry {
+	if c.Registry != nil {
+		return c.Registry
+	}
+	return serializer.DefaultRegistry
+}
+
+// WriteResponse writes v with status 200 OK, as WriteResponseCode does
+func (c *Codec) WriteResponse(w http.ResponseWriter, r *http.Request, v any) error {
+	return c.WriteResponseCode(w, r, http.StatusOK, v)
+}
+
+// WriteResponseCode serializes v with the registered serializer preferred by the
+// request's Accept header and writes it with status code, setting Content-Type
+// and Content-Length. Bodies of at least MinCompressSize bytes are compressed
+// with zstd or gzip when the Accept-Encoding header allows it.
+//
+// The value is serialized before anything is written, so on error, including
+// ErrNotAcceptable, the handler can still write an error response.
+func (c *Codec) WriteResponseCode(w http.ResponseWriter, r *http.Request, code int, v any) error {
+	s, _, err := c.registry().Negotiate(r.Header.Get("Accept"))
+	if err != nil {
+		return err
+	}
+	data, err := s.Serialize(v)
+	if err != nil {
+		return fmt.Errorf("%w: %w", errResponse, err)
+	}
+
+	header := w.Header()
+	header.Add("Vary", "Accept")
+	header.Add("Vary", "Accept-Encoding")
+	header.Set("Content-Type", s.ContentType())
+	if coding := c.encoding(r, len(data)); coding != "" {
+		data, err = compress(coding, data)
+		if err != nil {
+			return fmt.Errorf("%w: %w", errResponse, err)
+		}
+		header.Set("Content-Encoding", coding)
+	}
+	header.Set("Content-Length", strconv.Itoa(len(data)))
+	w.WriteHeader(code)
+	if r.Method == http.MethodHead {
+		return nil
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+// encoding returns the content coding for a response body of n bytes, or "" to
+// send it uncompressed
+func (c *Codec) encoding(r *http.Request, n int) string {
+	minSize := c.MinCompressSize
+	if minSize == 0 {
+		minSize = DefaultMinCompressSize
+	}
+	if minSize < 0 || n < minSize {
+		return ""
+	}
+	coding, _ := contenttype.NegotiateEncoding(r.Header.Get("Accept-Encoding"), []string{"zstd", "gzip"})
+	if coding == "identity" {
+		return ""
+	}
+	return coding
+}
+
+// ReadRequest decodes the body of r into v with the registered serializer for its
+// Content-Type, decompressing gzip and zstd bodies. It fails with
+// ErrUnsupportedMediaType or ErrUnsupportedEncoding when it cannot read the body.
+func (c *Codec) ReadRequest(r *http.Request, v any) error {
+	if r.Body == nil || r.Body == http.NoBody {
+		return errors.New("request has no body")
+	}
+	ct := r.Header.Get("Content-Type")
+	s, ok := c.registry().GetByContentType(ct)
+	if !ok {
+		return fmt.Errorf("%w: %q", ErrUnsupportedMediaType, ct)
+	}
+	body, err := decompressBody(r.Header.Get("Content-Encoding"), r.Body)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+	return s.DeserializeFrom(body, v)
+}
+
+// DecompressRequests is middleware that replaces gzip and zstd request bodies
+// with their decompressed form and removes the Content-Encoding header, so
+// handlers that read the body themselves see plain content. Bodies in other
+// encodings are answered with 415 Unsupported Media Type.
+func DecompressRequests(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ce := r.Header.Get("Content-Encoding")
+		if ce == "" || r.Body == nil || r.Body == http.NoBody {
+			next.ServeHTTP(w, r)
+			return
+		}
+		body, err := decompressBody(ce, r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), StatusCode(err))
+			return
+		}
+		r = r.Clone(r.Context())
+		r.Body = body
+		r.Header.Del("Content-Encoding")
+		r.ContentLength = -1
+		next.ServeHTTP(w, r)
+	})
+}
+
+// StatusCode maps the errors of this package to HTTP status codes: 406 for
+// ErrNotAcceptable, 415 for ErrUnsupportedMediaType and ErrUnsupportedEncoding,
+// 500 when WriteResponse cannot serialize or compress the value, and 400 for
+// anything else, such as a request body that does not decode
+func StatusCode(err error) int {
+	switch {
+	case errors.Is(err, ErrNotAcceptable):
+		return http.StatusNotAcceptable
+	case errors.Is(err, ErrUnsupportedMediaType), errors.Is(err, ErrUnsupportedEncoding):
+		return http.StatusUnsupportedMediaType
+	case errors.Is(err, errResponse):
+		return http.StatusInternalServerError
+	}
+	return http.StatusBadRequest
+}
+
+// zstdEncoder compresses whole response bodies; EncodeAll is safe for concurrent use
+var zstdEncoder, _ = zstd.NewWriter(nil)
+
+var gzipWriters = sync.Pool{New: func() any { return gzip.NewWriter(nil) }}
+
+// compress returns data compressed with coding
+func compress(coding string, data []byte) ([]byte, error) {
+	if coding == "zstd" {
+		return zstdEncoder.EncodeAll(data, nil), nil
+	}
+	var buf bytes.Buffer
+	zw := gzipWriters.Get().(*gzip.Writer)
+	defer gzipWriters.Put(zw)
+	zw.Reset(&buf)
+	if _, err := zw.Write(data); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// decompressBody returns a reader of body decoded from the Content-Encoding ce.
+// Closing it closes body.
+func decompressBody(ce string, body io.ReadCloser) (io.ReadCloser, error) {
+	switch strings.ToLower(strings.TrimSpace(ce)) {
+	case "", "identity":
+		return body, nil
+	case "gzip", "x-gzip":
+		zr, err := gzip.NewReader(body)
+		if err != nil {
+			return nil, fmt.Errorf("gzip request body: %w", err)
+		}
+		return readCloser{zr, func() error {
+			zr.Close()
+			return body.Close()
+		}}, nil
+	case "zstd":
+		zr, err := zstd.NewReader(body, zstd.WithDecoderConcurrency(1))
+		if err != nil {
+			return nil, fmt.Errorf("zstd request body: %w", err)
+		}
+		return readCloser{zr, func() error {
+			zr.Close()
+			return body.Close()
+		}}, nil
+	}
+	return nil, fmt.Errorf("%w: %q", ErrUnsupportedEncoding, ce)
+}
+
+// readCloser pairs a decompressing reader with the function that releases it
+type readCloser struct {
+	io.Reader
+	close func() error
+}
+
+func (rc readCloser) Close() error {
+	return rc.close()
+}
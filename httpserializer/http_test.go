@@ -0,0 +1,193 @@
+package httpserializer
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/MichaelAJay/go-serializer"
+	"github.com/klauspost/compress/zstd"
+)
+
+type user struct {
+	Name string `json:"name" msgpack:"name"`
+	Bio  string `json:"bio" msgpack:"bio"`
+}
+
+func newCodec() *Codec {
+	r := serializer.NewRegistry()
+	r.Register(serializer.JSON, serializer.NewJSONSerializer(32*1024))
+	r.Register(serializer.Msgpack, serializer.NewMsgpackSerializer())
+	return &Codec{Registry: r}
+}
+
+func TestWriteResponse(t *testing.T) {
+	c := newCodec()
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Accept", "application/x-msgpack, application/json;q=0.5")
+
+	w := httptest.NewRecorder()
+	if err := c.WriteResponseCode(w, req, http.StatusCreated, user{Name: "ada"}); err != nil {
+		t.Fatal(err)
+	}
+	if w.Code != http.StatusCreated {
+		t.Errorf("status = %d", w.Code)
+	}
+	if ct := w.Header().Get("Content-Type"); ct != "application/x-msgpack" {
+		t.Errorf("Content-Type = %q", ct)
+	}
+	if ce := w.Header().Get("Content-Encoding"); ce != "" {
+		t.Errorf("small body was compressed with %s", ce)
+	}
+	var got user
+	if err := serializer.NewMsgpackSerializer().Deserialize(w.Body.Bytes(), &got); err != nil || got.Name != "ada" {
+		t.Errorf("body decoded to %+v, %v", got, err)
+	}
+
+	req.Header.Set("Accept", "text/html")
+	w = httptest.NewRecorder()
+	err := c.WriteResponse(w, req, user{})
+	if !errors.Is(err, ErrNotAcceptable) || StatusCode(err) != http.StatusNotAcceptable {
+		t.Errorf("got %v", err)
+	}
+	if w.Body.Len() != 0 || len(w.Header()) != 0 {
+		t.Error("response was written for an unacceptable request")
+	}
+
+	// A value that cannot be serialized is the server's fault
+	req.Header.Set("Accept", "application/json")
+	w = httptest.NewRecorder()
+	err = c.WriteResponse(w, req, map[string]any{"ch": make(chan int)})
+	if err == nil || StatusCode(err) != http.StatusInternalServerError {
+		t.Errorf("got %v", err)
+	}
+	if w.Body.Len() != 0 {
+		t.Error("response was written for a value that does not serialize")
+	}
+}
+
+func TestWriteResponseCompression(t *testing.T) {
+	c := newCodec()
+	large := user{Name: "ada", Bio: strings.Repeat("analytical engine ", 200)}
+
+	decoders := map[string]func(io.Reader) (io.Reader, error){
+		"gzip": func(r io.Reader) (io.Reader, error) { return gzip.NewReader(r) },
+		"zstd": func(r io.Reader) (io.Reader, error) { return zstd.NewReader(r) },
+	}
+	for acceptEncoding, want := range map[string]string{"gzip": "gzip", "gzip, zstd": "zstd", "br": ""} {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("Accept-Encoding", acceptEncoding)
+		w := httptest.NewRecorder()
+		if err := c.WriteResponse(w, req, large); err != nil {
+			t.Fatal(err)
+		}
+		if ce := w.Header().Get("Content-Encoding"); ce != want {
+			t.Errorf("%s: Content-Encoding = %q, want %q", acceptEncoding, ce, want)
+			continue
+		}
+		if vary := w.Header().Values("Vary"); len(vary) != 2 {
+			t.Errorf("Vary = %v", vary)
+		}
+		var body io.Reader = w.Body
+		if want != "" {
+			var err error
+			if body, err = decoders[want](body); err != nil {
+				t.Fatal(err)
+			}
+		}
+		var got user
+		if err := serializer.NewJSONSerializer(0).DeserializeFrom(body, &got); err != nil || got != large {
+			t.Errorf("%s: body decoded to %v", acceptEncoding, err)
+		}
+	}
+
+	c.MinCompressSize = -1
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	w := httptest.NewRecorder()
+	if err := c.WriteResponse(w, req, large); err != nil {
+		t.Fatal(err)
+	}
+	if ce := w.Header().Get("Content-Encoding"); ce != "" {
+		t.Errorf("compression disabled, got %q", ce)
+	}
+}
+
+func TestReadRequest(t *testing.T) {
+	c := newCodec()
+	payload, _ := serializer.NewMsgpackSerializer().Serialize(user{Name: "ada"})
+
+	var gz bytes.Buffer
+	zw := gzip.NewWriter(&gz)
+	zw.Write(payload)
+	zw.Close()
+
+	bodies := map[string][]byte{"": payload, "gzip": gz.Bytes()}
+	for ce, body := range bodies {
+		req := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/x-msgpack")
+		req.Header.Set("Content-Encoding", ce)
+		var got user
+		if err := c.ReadRequest(req, &got); err != nil || got.Name != "ada" {
+			t.Errorf("Content-Encoding %q: got %+v, %v", ce, got, err)
+		}
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader("name: ada"))
+	req.Header.Set("Content-Type", "application/yaml")
+	var got user
+	if err := c.ReadRequest(req, &got); StatusCode(err) != http.StatusUnsupportedMediaType {
+		t.Errorf("unknown content type: got %v", err)
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(payload))
+	req.Header.Set("Content-Type", "application/x-msgpack")
+	req.Header.Set("Content-Encoding", "br")
+	if err := c.ReadRequest(req, &got); !errors.Is(err, ErrUnsupportedEncoding) {
+		t.Errorf("unknown content encoding: got %v", err)
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"name":`))
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	if err := c.ReadRequest(req, &got); err == nil || StatusCode(err) != http.StatusBadRequest {
+		t.Errorf("malformed body: got %v", err)
+	}
+}
+
+func TestDecompressRequests(t *testing.T) {
+	var zs bytes.Buffer
+	zw, _ := zstd.NewWriter(&zs)
+	zw.Write([]byte(`{"name":"ada"}`))
+	zw.Close()
+
+	var got user
+	handler := DecompressRequests(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if ce := r.Header.Get("Content-Encoding"); ce != "" {
+			t.Errorf("Content-Encoding = %q", ce)
+		}
+		if err := ReadRequest(r, &got); err != nil {
+			t.Error(err)
+		}
+	}))
+
+	req := httptest.NewRequest(http.MethodPost, "/", &zs)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Content-Encoding", "zstd")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+	if got.Name != "ada" {
+		t.Errorf("got %+v", got)
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/", strings.NewReader("x"))
+	req.Header.Set("Content-Encoding", "compress")
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	if w.Code != http.StatusUnsupportedMediaType {
+		t.Errorf("status = %d", w.Code)
+	}
+}
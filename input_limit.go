@@ -0,0 +1,90 @@
+package serializer
+
+import (
+	"errors"
+	"fmt"
+	"io"
+)
+
+// ErrInputTooLarge is returned when a payload is larger than WithMaxInputSize allows
+var ErrInputTooLarge = errors.New("input exceeds maximum size")
+
+// WithMaxInputSize rejects payloads larger than n bytes, for services that
+// deserialize untrusted data. Deserialize and DeserializeString check the length
+// before decoding, and DeserializeFrom fails as soon as it has read more than n
+// bytes, so an oversized body is never buffered whole. A compressed serializer
+// wrapping the serializer applies the limit to the decompressed payload, which
+// stops decompression bombs. If n <= 0, input is not limited (the default).
+func WithMaxInputSize(n int64) Option {
+	return func(o *options) {
+		o.maxInputSize = n
+	}
+}
+
+// inputLimited is implemented by serializers that can be configured with
+// WithMaxInputSize, so decorators can enforce the limit before inner sees the data
+type inputLimited interface {
+	maxInputSize() int64
+}
+
+// checkInputSize returns an error wrapping ErrInputTooLarge if a payload of size
+// bytes is over the limit
+func (o *options) checkInputSize(size int) error {
+	if o.maxInputSize <= 0 || int64(size) <= o.maxInputSize {
+		return nil
+	}
+	return inputTooLarge(o.maxInputSize)
+}
+
+func inputTooLarge(max int64) error {
+	return fmt.Errorf("%w: more than %d bytes", ErrInputTooLarge, max)
+}
+
+// limitInput prepares r for a decode of at most WithMaxInputSize bytes. Decoders
+// may report a failed read as an unexpected EOF or a syntax error, so readErr
+// replaces the error of a decode that ran into the limit.
+func (o *options) limitInput(r io.Reader) (limited io.Reader, readErr func(error) error) {
+	return limitReader(r, o.maxInputSize)
+}
+
+// limitReader is limitInput for a limit of max bytes
+func limitReader(r io.Reader, max int64) (limited io.Reader, readErr func(error) error) {
+	if max <= 0 {
+		return r, func(err error) error { return err }
+	}
+	lr := &inputLimitReader{r: r, remaining: max, max: max}
+	return lr, func(err error) error {
+		if err != nil && lr.err != nil {
+			return lr.err
+		}
+		return err
+	}
+}
+
+// inputLimitReader fails once more than max bytes have been read from r
+type inputLimitReader struct {
+	r         io.Reader
+	remaining int64
+	max       int64
+	err       error
+}
+
+func (lr *inputLimitReader) Read(p []byte) (int, error) {
+	if lr.err != nil {
+		return 0, lr.err
+	}
+	// Read one byte more than allowed to tell a payload of exactly max bytes
+	// from a longer one
+	if int64(len(p)) > lr.remaining+1 {
+		p = p[:lr.remaining+1]
+	}
+	n, err := lr.r.Read(p)
+	if int64(n) <= lr.remaining {
+		lr.remaining -= int64(n)
+		return n, err
+	}
+	n = int(lr.remaining)
+	lr.remaining = 0
+	lr.err = inputTooLarge(lr.max)
+	return n, lr.err
+}
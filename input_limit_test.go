@@ -0,0 +1,105 @@
+package serializer
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+func TestWithMaxInputSize(t *testing.T) {
+	value := map[string]string{"name": strings.Repeat("x", 100)}
+	newMap := func() any { return new(map[string]string) }
+	tests := []struct {
+		name   string
+		new    func(opts ...Option) Serializer
+		value  any
+		target func() any
+	}{
+		{"json", func(opts ...Option) Serializer { return NewJSONSerializer(0, opts...) }, value, newMap},
+		{"ndjson", func(opts ...Option) Serializer { return NewNDJSONSerializer(0, opts...) }, value, newMap},
+		{"msgpack", NewMsgpackSerializer, value, newMap},
+		{"cbor", NewCBORSerializer, value, newMap},
+		{"bson", NewBSONSerializer, value, newMap},
+		{"protobuf", NewProtoSerializer, wrapperspb.String(strings.Repeat("x", 100)), func() any { return new(wrapperspb.StringValue) }},
+	}
+	for _, newGob := range gobTestConstructors() {
+		tests = append(tests, struct {
+			name   string
+			new    func(opts ...Option) Serializer
+			value  any
+			target func() any
+		}{"gob", newGob, value, newMap})
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := tt.new().Serialize(tt.value)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			exact := tt.new(WithMaxInputSize(int64(len(data))))
+			if err := exact.Deserialize(data, tt.target()); err != nil {
+				t.Errorf("Deserialize at the limit: %v", err)
+			}
+			if err := exact.DeserializeFrom(bytes.NewReader(data), tt.target()); err != nil {
+				t.Errorf("DeserializeFrom at the limit: %v", err)
+			}
+
+			s := tt.new(WithMaxInputSize(int64(len(data) / 2)))
+			if err := s.Deserialize(data, tt.target()); !errors.Is(err, ErrInputTooLarge) {
+				t.Errorf("Deserialize = %v, want ErrInputTooLarge", err)
+			}
+			if err := s.(StringDeserializer).DeserializeString(string(data), tt.target()); !errors.Is(err, ErrInputTooLarge) {
+				t.Errorf("DeserializeString = %v, want ErrInputTooLarge", err)
+			}
+			// One byte at a time, so decoders see the limit mid-value
+			r := iotest.OneByteReader(bytes.NewReader(data))
+			if err := s.DeserializeFrom(r, tt.target()); !errors.Is(err, ErrInputTooLarge) {
+				t.Errorf("DeserializeFrom = %v, want ErrInputTooLarge", err)
+			}
+		})
+	}
+}
+
+func TestWithMaxInputSizeDecompression(t *testing.T) {
+	inner := NewJSONSerializer(0, WithMaxInputSize(64<<10))
+	bomb, err := NewCompressedSerializer(NewJSONSerializer(0), CompressionGzip).Serialize(strings.Repeat("a", 1<<20))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(bomb) > 64<<10 {
+		t.Fatalf("compressed payload is %d bytes", len(bomb))
+	}
+
+	s := NewCompressedSerializer(inner, CompressionGzip)
+	var got string
+	if err := s.Deserialize(bomb, &got); !errors.Is(err, ErrInputTooLarge) {
+		t.Errorf("Deserialize = %v, want ErrInputTooLarge", err)
+	}
+	if err := s.DeserializeFrom(bytes.NewReader(bomb), &got); !errors.Is(err, ErrInputTooLarge) {
+		t.Errorf("DeserializeFrom = %v, want ErrInputTooLarge", err)
+	}
+
+	small, _ := s.Serialize("ok")
+	if err := s.Deserialize(small, &got); err != nil || got != "ok" {
+		t.Errorf("Deserialize = %q, %v", got, err)
+	}
+}
+
+func TestInputLimitReader(t *testing.T) {
+	r, readErr := limitReader(strings.NewReader("abcdef"), 4)
+	data, err := io.ReadAll(r)
+	if string(data) != "abcd" || !errors.Is(readErr(err), ErrInputTooLarge) {
+		t.Errorf("ReadAll = %q, %v", data, err)
+	}
+
+	r, readErr = limitReader(strings.NewReader("abcd"), 4)
+	if data, err := io.ReadAll(r); string(data) != "abcd" || readErr(err) != nil {
+		t.Errorf("ReadAll at the limit = %q, %v", data, err)
+	}
+}
@@ -60,11 +60,6 @@ func TestInterfaceDetection(t *testing.T) {
 			serializer:            serializer.NewMsgpackSerializer(),
 			implementsStringDeser: true,
 		},
-		{
-			name:                  "Gob_implements_StringDeserializer",
-			serializer:            serializer.NewGobSerializer(),
-			implementsStringDeser: true,
-		},
 		{
 			name:                  "Mock_does_not_implement_StringDeserializer",
 			serializer:            &mockSerializer{},
@@ -76,6 +71,13 @@ func TestInterfaceDetection(t *testing.T) {
 			implementsStringDeser: true,
 		},
 	}
+	for _, s := range gobSerializers() {
+		tests = append(tests, struct {
+			name                  string
+			serializer            serializer.Serializer
+			implementsStringDeser bool
+		}{"Gob_implements_StringDeserializer", s, true})
+	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -105,10 +107,10 @@ func TestTypeAssertionSafety(t *testing.T) {
 	serializers := []serializer.Serializer{
 		serializer.NewJSONSerializer(maxBufferSize),
 		serializer.NewMsgpackSerializer(),
-		serializer.NewGobSerializer(),
 		&mockSerializer{},
 		&mockStringSerializer{mockSerializer: &mockSerializer{}},
 	}
+	serializers = append(serializers, gobSerializers()...)
 
 	for _, s := range serializers {
 		t.Run(s.ContentType(), func(t *testing.T) {
@@ -138,11 +140,10 @@ func TestFallbackBehavior(t *testing.T) {
 	testData := "test string"
 
 	// Test with serializers that implement StringDeserializer
-	realSerializers := []serializer.Serializer{
+	realSerializers := append([]serializer.Serializer{
 		serializer.NewJSONSerializer(maxBufferSize),
 		serializer.NewMsgpackSerializer(),
-		serializer.NewGobSerializer(),
-	}
+	}, gobSerializers()...)
 
 	for _, s := range realSerializers {
 		t.Run("WithStringDeser_"+s.ContentType(), func(t *testing.T) {
@@ -207,11 +208,14 @@ func TestRegistryWithStringDeserializer(t *testing.T) {
 	// Register all serializers
 	registry.Register("json", serializer.NewJSONSerializer(maxBufferSize))
 	registry.Register("msgpack", serializer.NewMsgpackSerializer())
-	registry.Register("gob", serializer.NewGobSerializer())
 	registry.Register("mock", &mockSerializer{})
 	registry.Register("mockstring", &mockStringSerializer{mockSerializer: &mockSerializer{}})
 
-	formats := []serializer.Format{"json", "msgpack", "gob", "mock", "mockstring"}
+	formats := []serializer.Format{"json", "msgpack", "mock", "mockstring"}
+	for _, s := range gobSerializers() {
+		registry.Register("gob", s)
+		formats = append(formats, "gob")
+	}
 	for _, format := range formats {
 		t.Run(string(format), func(t *testing.T) {
 			// Get serializer from registry
@@ -251,11 +255,10 @@ func TestRegistryWithStringDeserializer(t *testing.T) {
 
 // TestConcurrentInterfaceDetection tests interface detection under concurrent access
 func TestConcurrentInterfaceDetection(t *testing.T) {
-	serializers := []serializer.Serializer{
+	serializers := append([]serializer.Serializer{
 		serializer.NewJSONSerializer(maxBufferSize),
 		serializer.NewMsgpackSerializer(),
-		serializer.NewGobSerializer(),
-	}
+	}, gobSerializers()...)
 
 	const numGoroutines = 10
 	const numIterations = 100
@@ -310,3 +313,22 @@ func TestNilSerializerHandling(t *testing.T) {
 		t.Error("StringDeserializer should be nil for nil serializer")
 	}
 }
+
+// TestRegistryFormats tests that registered formats are listed in sorted order
+func TestRegistryFormats(t *testing.T) {
+	registry := serializer.NewRegistry()
+	registry.Register(serializer.Msgpack, serializer.NewMsgpackSerializer())
+	registry.Register(serializer.JSON, serializer.NewJSONSerializer(maxBufferSize))
+	registry.Register(serializer.CBOR, serializer.NewCBORSerializer())
+
+	formats := registry.Formats()
+	expected := []serializer.Format{serializer.CBOR, serializer.JSON, serializer.Msgpack}
+	if len(formats) != len(expected) {
+		t.Fatalf("Expected %d formats, got %d", len(expected), len(formats))
+	}
+	for i := range expected {
+		if formats[i] != expected[i] {
+			t.Errorf("Expected format %s at index %d, got %s", expected[i], i, formats[i])
+		}
+	}
+}
@@ -0,0 +1,147 @@
+// Package contenttype parses media types and negotiates HTTP Accept headers.
+package contenttype
+
+import (
+	"strconv"
+	"strings"
+)
+
+// MediaType strips parameters from a content type and lowercases it
+func MediaType(contentType string) string {
+	if i := strings.IndexByte(contentType, ';'); i >= 0 {
+		contentType = contentType[:i]
+	}
+	return strings.ToLower(strings.TrimSpace(contentType))
+}
+
+// acceptRange is one media range of an Accept header
+type acceptRange struct {
+	typ, subtype string
+	q            float64
+}
+
+// parseAccept splits an Accept header into media ranges with their q-values
+func parseAccept(accept string) []acceptRange {
+	var ranges []acceptRange
+	for _, part := range strings.Split(accept, ",") {
+		params := strings.Split(part, ";")
+		mediaType := strings.ToLower(strings.TrimSpace(params[0]))
+		if mediaType == "" {
+			continue
+		}
+		typ, subtype, ok := strings.Cut(mediaType, "/")
+		if !ok {
+			if mediaType != "*" {
+				continue
+			}
+			typ, subtype = "*", "*"
+		}
+
+		ranges = append(ranges, acceptRange{typ: typ, subtype: subtype, q: parseQ(params[1:])})
+	}
+	return ranges
+}
+
+// parseQ returns the q-value among the parameters of a header element, 1 if none is given
+func parseQ(params []string) float64 {
+	q := 1.0
+	for _, param := range params {
+		key, value, ok := strings.Cut(strings.TrimSpace(param), "=")
+		if !ok || strings.ToLower(strings.TrimSpace(key)) != "q" {
+			continue
+		}
+		if parsed, err := strconv.ParseFloat(strings.TrimSpace(value), 64); err == nil && parsed >= 0 && parsed <= 1 {
+			q = parsed
+		}
+	}
+	return q
+}
+
+// quality returns the q-value the Accept ranges assign to a media type and the
+// specificity of the range that assigned it: 2 for an exact match, 1 for type/*
+// and 0 for */*. The most specific matching range wins; -1 means no range matched.
+func quality(ranges []acceptRange, mediaType string) (q float64, specificity int) {
+	typ, subtype, _ := strings.Cut(mediaType, "/")
+	q, specificity = -1.0, -1
+	for _, r := range ranges {
+		var s int
+		switch {
+		case r.typ == typ && r.subtype == subtype:
+			s = 2
+		case r.typ == typ && r.subtype == "*":
+			s = 1
+		case r.typ == "*" && r.subtype == "*":
+			s = 0
+		default:
+			continue
+		}
+		if s > specificity {
+			q, specificity = r.q, s
+		}
+	}
+	return q, specificity
+}
+
+// Negotiate returns the index of the offered content type the Accept header prefers.
+// Offers with equal q-values are ranked by the specificity of the range they
+// matched, exact over type/* over */* (RFC 9110 §12.5.1), and then by the order
+// of offered. An empty Accept header accepts the first offer.
+// ok is false when no offer is acceptable.
+func Negotiate(accept string, offered []string) (index int, ok bool) {
+	if len(offered) == 0 {
+		return -1, false
+	}
+	if strings.TrimSpace(accept) == "" {
+		return 0, true
+	}
+
+	ranges := parseAccept(accept)
+	best, bestQ, bestSpecificity := -1, 0.0, -1
+	for i, offer := range offered {
+		q, specificity := quality(ranges, MediaType(offer))
+		if q > bestQ || (q == bestQ && q > 0 && specificity > bestSpecificity) {
+			best, bestQ, bestSpecificity = i, q, specificity
+		}
+	}
+	return best, best >= 0
+}
+
+// NegotiateEncoding returns the content coding of offered that an Accept-Encoding
+// header prefers, with ties broken by the order of offered. It returns "identity"
+// when the header is empty or accepts none of offered, and ok is false when the
+// header rules out identity as well.
+func NegotiateEncoding(acceptEncoding string, offered []string) (coding string, ok bool) {
+	if strings.TrimSpace(acceptEncoding) == "" {
+		return "identity", true
+	}
+
+	codings := make(map[string]float64)
+	for _, part := range strings.Split(acceptEncoding, ",") {
+		params := strings.Split(part, ";")
+		if name := strings.ToLower(strings.TrimSpace(params[0])); name != "" {
+			codings[name] = parseQ(params[1:])
+		}
+	}
+	qualityOf := func(coding string) (float64, bool) {
+		if q, ok := codings[coding]; ok {
+			return q, true
+		}
+		q, ok := codings["*"]
+		return q, ok
+	}
+
+	best, bestQ := "", 0.0
+	for _, offer := range offered {
+		if q, _ := qualityOf(strings.ToLower(offer)); q > bestQ {
+			best, bestQ = offer, q
+		}
+	}
+	if best != "" {
+		return best, true
+	}
+	// identity is acceptable unless it is excluded by name or by *;q=0
+	if q, listed := qualityOf("identity"); listed && q == 0 {
+		return "", false
+	}
+	return "identity", true
+}
@@ -0,0 +1,65 @@
+package contenttype
+
+import "testing"
+
+func TestNegotiate(t *testing.T) {
+	offered := []string{"application/json", "application/x-msgpack", "application/x-gob"}
+
+	tests := []struct {
+		accept string
+		want   int
+		ok     bool
+	}{
+		{"", 0, true},
+		{"*/*", 0, true},
+		{"application/x-msgpack", 1, true},
+		{"application/json;q=0.5, application/x-msgpack", 1, true},
+		{"application/*;q=0.2, application/x-gob;q=0.9", 2, true},
+		{"application/x-msgpack;q=0, */*;q=0.1", 0, true},
+		{"*/*, application/x-gob", 2, true},
+		{"application/*, application/x-msgpack", 1, true},
+		{"*/*;q=0.5, application/x-gob;q=0.5", 2, true},
+		{"text/html", -1, false},
+		{"application/json;q=0", -1, false},
+	}
+	for _, tt := range tests {
+		got, ok := Negotiate(tt.accept, offered)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("Negotiate(%q) = %d, %v; want %d, %v", tt.accept, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestNegotiateEncoding(t *testing.T) {
+	offered := []string{"zstd", "gzip"}
+
+	tests := []struct {
+		acceptEncoding string
+		want           string
+		ok             bool
+	}{
+		{"", "identity", true},
+		{"gzip", "gzip", true},
+		{"gzip, deflate, br, zstd", "zstd", true},
+		{"zstd;q=0.5, GZIP", "gzip", true},
+		{"*", "zstd", true},
+		{"*;q=0.1, zstd;q=0", "gzip", true},
+		{"br", "identity", true},
+		{"gzip;q=0, zstd;q=0", "identity", true},
+		{"br, identity;q=0", "", false},
+		{"*;q=0", "", false},
+		{"*;q=0, identity", "identity", true},
+	}
+	for _, tt := range tests {
+		got, ok := NegotiateEncoding(tt.acceptEncoding, offered)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("NegotiateEncoding(%q) = %q, %v; want %q, %v", tt.acceptEncoding, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestMediaType(t *testing.T) {
+	if got := MediaType(" Application/JSON; charset=utf-8"); got != "application/json" {
+		t.Errorf("MediaType = %q", got)
+	}
+}
@@ -0,0 +1,326 @@
+package gentest
+
+import (
+	"bytes"
+	stdjson "encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	serializer "github.com/MichaelAJay/go-serializer"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// Defined types drop the generated methods, so these encode through reflection
+type (
+	plainUser  User
+	plainEvent Event
+	plainOrder Order
+)
+
+func sampleUser() User {
+	work := Address{Street: "1 Main St", City: "Springfield", Zip: "12345"}
+	return User{
+		ID:       -42,
+		Name:     "Ada <Lovelace> & \"friends\"\n ",
+		Email:    "ada@example.com",
+		Age:      36,
+		Score:    1e21,
+		Ratio:    0.1,
+		Active:   true,
+		Flags:    7,
+		Status:   "ok",
+		Tags:     []string{"a", "", "ü"},
+		Labels:   map[string]string{"env": "prod"},
+		Home:     Address{Street: "2 Side St", City: "Shelbyville"},
+		Work:     &work,
+		Previous: []*Address{nil, {City: "Capital City"}},
+		Created:  time.Date(2024, 5, 6, 7, 8, 9, 10, time.UTC),
+		Extra:    map[string]any{"n": 1.5},
+		Raw:      []byte{0, 1, 2},
+		Password: "secret",
+		Nickname: "ada",
+	}
+}
+
+func sampleEvents() []Event {
+	at := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	return []Event{
+		{},
+		{Count: 3},
+		{Kind: "k", At: &at},
+		{At: &at, Values: map[string][]int{"x": {1, -2}}},
+		{Values: map[string][]int{"nil": nil}},
+	}
+}
+
+func stdlibJSON(t *testing.T, v any) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	enc := stdjson.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		t.Fatalf("encoding/json failed: %v", err)
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n"))
+}
+
+// preciseUser holds values jsoniter's default configuration writes differently
+// from encoding/json
+func preciseUser() User {
+	return User{Name: "line\u2028separator", Score: 3.14159265, Ratio: 1e-7}
+}
+
+func TestGeneratedJSONMatchesReflection(t *testing.T) {
+	cases := []struct {
+		name        string
+		value, want any
+	}{
+		{"zero user", User{}, plainUser{}},
+		{"user", sampleUser(), plainUser(sampleUser())},
+		{"precise user", preciseUser(), plainUser(preciseUser())},
+	}
+	for i, e := range sampleEvents() {
+		cases = append(cases, struct {
+			name        string
+			value, want any
+		}{"event " + string(rune('0'+i)), e, plainEvent(e)})
+	}
+
+	// Adding generated code must not change what a serializer writes, whichever
+	// backend it uses
+	serializers := map[string]serializer.Serializer{
+		"default": serializer.NewJSONSerializer(0),
+		"stdlib":  serializer.NewJSONSerializer(0, serializer.WithStdlibJSON()),
+		"lite":    serializer.NewJSONSerializer(0, serializer.WithJSONBackend(serializer.JSONBackendLite)),
+	}
+	for backend, s := range serializers {
+		for _, tc := range cases {
+			t.Run(backend+"/"+tc.name, func(t *testing.T) {
+				got, err := s.Serialize(tc.value)
+				if err != nil {
+					t.Fatalf("Serialize failed: %v", err)
+				}
+				want, err := s.Serialize(tc.want)
+				if err != nil {
+					t.Fatalf("Serialize of the plain type failed: %v", err)
+				}
+				if !bytes.Equal(got, want) {
+					t.Errorf("generated JSON differs from reflection\ngot:  %s\nwant: %s", got, want)
+				}
+			})
+		}
+	}
+}
+
+func TestGeneratedJSONDecodeMatchesReflection(t *testing.T) {
+	s := serializer.NewJSONSerializer(0, serializer.WithStdlibJSON())
+	inputs := []string{
+		string(stdlibJSON(t, plainUser(sampleUser()))),
+		`{}`,
+		`null`,
+		` { "ID" : 1 , "NAME":"x", "unknown": {"a":[1,{"b":null}]}, "tags": null, "labels": {"a":"b"}, "work": null } `,
+		`{"tags":["a","bé😀\\"],"previous":[null,{"city":"c"}],"age":-0,"score":-1.5e-3}`,
+		`{"home":{"street":"s"},"home":{"city":"c"}}`,
+		`{"extra":[1,"two",{"3":true}],"raw":"AAEC","created":"2001-02-03T04:05:06Z","status":"st"}`,
+	}
+	for _, in := range inputs {
+		var got User
+		var want plainUser
+		if err := s.Deserialize([]byte(in), &got); err != nil {
+			t.Errorf("Deserialize(%s) failed: %v", in, err)
+			continue
+		}
+		if err := stdjson.Unmarshal([]byte(in), &want); err != nil {
+			t.Fatalf("encoding/json rejected %s: %v", in, err)
+		}
+		if !reflect.DeepEqual(got, User(want)) {
+			t.Errorf("Deserialize(%s)\ngot:  %+v\nwant: %+v", in, got, want)
+		}
+	}
+
+	var e Event
+	if err := s.Deserialize([]byte(`{"values":{"a":[1,2],"b":null},"at":"2020-01-01T00:00:00Z"}`), &e); err != nil {
+		t.Fatalf("Deserialize failed: %v", err)
+	}
+	if !reflect.DeepEqual(e.Values, map[string][]int{"a": {1, 2}, "b": nil}) || e.At == nil || e.At.Year() != 2020 {
+		t.Errorf("unexpected event: %+v", e)
+	}
+}
+
+func TestGeneratedJSONRejectsInvalidInput(t *testing.T) {
+	s := serializer.NewJSONSerializer(0, serializer.WithStdlibJSON())
+	inputs := []string{
+		``,
+		`{`,
+		`[]`,
+		`{"id":"1"}`,
+		`{"id":1.5}`,
+		`{"flags":70000}`,
+		`{"flags":-1}`,
+		`{"name":1}`,
+		`{"name":"x"}x`,
+		`{"tags":[1]}`,
+		`{"active":nul}`,
+		`{"score":01}`,
+		`{"name":"\x"}`,
+		`{"home":[]}`,
+		`{"a":1,}`,
+	}
+	for _, in := range inputs {
+		var u User
+		if err := s.Deserialize([]byte(in), &u); err == nil {
+			t.Errorf("Deserialize(%q) succeeded", in)
+		}
+		if err := stdjson.Unmarshal([]byte(in), new(plainUser)); err == nil {
+			t.Errorf("encoding/json accepted %q", in)
+		}
+	}
+}
+
+func TestGeneratedJSONSkippedWhenOptionsChangeOutput(t *testing.T) {
+	u := User{Name: "<b>"}
+	data, err := serializer.NewJSONSerializer(0, serializer.WithEscapeHTML(true)).Serialize(u)
+	if err != nil {
+		t.Fatalf("Serialize failed: %v", err)
+	}
+	if !strings.Contains(string(data), `\u003cb\u003e`) {
+		t.Errorf("HTML was not escaped: %s", data)
+	}
+}
+
+func TestGeneratedMsgpackMatchesReflection(t *testing.T) {
+	s := serializer.NewMsgpackSerializer()
+	cases := []struct {
+		name        string
+		value, want any
+	}{
+		{"zero user", User{}, plainUser{}},
+		{"user", sampleUser(), plainUser(sampleUser())},
+		{"event", sampleEvents()[3], plainEvent(sampleEvents()[3])},
+		{"empty event", Event{}, plainEvent{}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := s.Serialize(tc.value)
+			if err != nil {
+				t.Fatalf("Serialize failed: %v", err)
+			}
+			want, err := msgpack.Marshal(tc.want)
+			if err != nil {
+				t.Fatalf("msgpack.Marshal failed: %v", err)
+			}
+			if !bytes.Equal(got, want) {
+				t.Errorf("generated msgpack differs from reflection\ngot:  %x\nwant: %x", got, want)
+			}
+		})
+	}
+}
+
+func TestGeneratedMsgpackDecodeMatchesReflection(t *testing.T) {
+	s := serializer.NewMsgpackSerializer()
+	values := []any{
+		plainUser(sampleUser()),
+		plainUser{},
+		map[string]any{"id": int8(3), "AGE": 4, "unknown": []any{1, "x"}, "tags": nil, "work": nil},
+	}
+	for _, v := range values {
+		data, err := msgpack.Marshal(v)
+		if err != nil {
+			t.Fatalf("msgpack.Marshal failed: %v", err)
+		}
+		var got User
+		var want plainUser
+		if err := s.Deserialize(data, &got); err != nil {
+			t.Fatalf("Deserialize failed: %v", err)
+		}
+		if err := msgpack.Unmarshal(data, &want); err != nil {
+			t.Fatalf("msgpack.Unmarshal failed: %v", err)
+		}
+		if !reflect.DeepEqual(got, User(want)) {
+			t.Errorf("Deserialize(%v)\ngot:  %+v\nwant: %+v", v, got, want)
+		}
+	}
+
+	for _, e := range sampleEvents() {
+		data, err := s.Serialize(e)
+		if err != nil {
+			t.Fatalf("Serialize failed: %v", err)
+		}
+		var got Event
+		if err := s.Deserialize(data, &got); err != nil {
+			t.Fatalf("Deserialize failed: %v", err)
+		}
+		if !reflect.DeepEqual(got, e) && !(got.At != nil && e.At != nil && got.At.Equal(*e.At)) {
+			t.Errorf("round trip changed %+v to %+v", e, got)
+		}
+	}
+}
+
+func TestGeneratedMsgpackSkippedWhenOptionsChangeOutput(t *testing.T) {
+	u := sampleUser()
+	u.Labels = nil
+	got, err := serializer.NewMsgpackSerializer(serializer.WithMsgpackStructAsArray()).Serialize(u)
+	if err != nil {
+		t.Fatalf("Serialize failed: %v", err)
+	}
+	var buf bytes.Buffer
+	enc := msgpack.NewEncoder(&buf)
+	enc.UseArrayEncodedStructs(true)
+	if err := enc.Encode(plainUser(u)); err != nil {
+		t.Fatalf("Encode failed: %v", err)
+	}
+	if !bytes.Equal(got, buf.Bytes()) {
+		t.Errorf("struct-as-array output differs from reflection\ngot:  %x\nwant: %x", got, buf.Bytes())
+	}
+}
+
+func sampleOrder() Order {
+	return Order{
+		ID:       "order-1",
+		Customer: Address{Street: "1 Main St", City: "Springfield", Zip: "12345"},
+		Lines: []OrderLine{
+			{SKU: "A-1", Quantity: 2, Price: 9.99},
+			{SKU: "B-22", Quantity: 1, Price: 120},
+			{SKU: "C-333", Quantity: 12, Price: 0.5},
+		},
+		Total: 145.98,
+		Paid:  true,
+		Notes: map[string]string{"gift": "yes"},
+	}
+}
+
+func BenchmarkGeneratedJSON(b *testing.B) {
+	benchmarkCodec(b, serializer.NewJSONSerializer(0, serializer.WithStdlibJSON()))
+}
+
+func BenchmarkGeneratedMsgpack(b *testing.B) {
+	benchmarkCodec(b, serializer.NewMsgpackSerializer())
+}
+
+func benchmarkCodec(b *testing.B, s serializer.Serializer) {
+	b.Run("generated", func(b *testing.B) {
+		benchmarkRoundTrip[Order](b, s, sampleOrder())
+	})
+	b.Run("reflection", func(b *testing.B) {
+		benchmarkRoundTrip[plainOrder](b, s, plainOrder(sampleOrder()))
+	})
+}
+
+func benchmarkRoundTrip[T any](b *testing.B, s serializer.Serializer, v T) {
+	data, err := s.Serialize(v)
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		var out T
+		if _, err := s.Serialize(v); err != nil {
+			b.Fatal(err)
+		}
+		if err := s.Deserialize(data, &out); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,1618 @@
+// Code generated by serializer-gen. DO NOT EDIT.
+
+package gentest
+
+import (
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/MichaelAJay/go-serializer"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// AppendJSON implements serializer.GeneratedJSONEncoder
+func (x Address) AppendJSON(dst []byte) ([]byte, error) {
+	var err error
+	dst = append(dst, '{')
+	dst = append(dst, "\"street\":"...)
+	dst = serializer.AppendJSONString(dst, x.Street)
+	dst = append(dst, ",\"city\":"...)
+	dst = serializer.AppendJSONString(dst, x.City)
+	if x.Zip != "" {
+		dst = append(dst, ",\"zip\":"...)
+		dst = serializer.AppendJSONString(dst, x.Zip)
+	}
+	dst = append(dst, '}')
+	return dst, err
+}
+
+var jsonFieldsAddress = []string{"street", "city", "zip"}
+
+// ReadJSON implements serializer.GeneratedJSONDecoder
+func (x *Address) ReadJSON(r *serializer.JSONReader) error {
+	return r.ReadObject(jsonFieldsAddress, func(field int) error {
+		switch field {
+		case 0:
+			if !r.ReadNull() {
+				v0, err := r.ReadString()
+				if err != nil {
+					return err
+				}
+				x.Street = v0
+			}
+		case 1:
+			if !r.ReadNull() {
+				v0, err := r.ReadString()
+				if err != nil {
+					return err
+				}
+				x.City = v0
+			}
+		case 2:
+			if !r.ReadNull() {
+				v0, err := r.ReadString()
+				if err != nil {
+					return err
+				}
+				x.Zip = v0
+			}
+		}
+		return nil
+	})
+}
+
+// WriteMsgpack implements serializer.GeneratedMsgpackEncoder
+func (x Address) WriteMsgpack(enc *msgpack.Encoder) error {
+	n := 2
+	if x.Zip != "" {
+		n++
+	}
+	if err := enc.EncodeMapLen(n); err != nil {
+		return err
+	}
+	if err := enc.EncodeString("street"); err != nil {
+		return err
+	}
+	if err := enc.EncodeString(x.Street); err != nil {
+		return err
+	}
+	if err := enc.EncodeString("city"); err != nil {
+		return err
+	}
+	if err := enc.EncodeString(x.City); err != nil {
+		return err
+	}
+	if x.Zip != "" {
+		if err := enc.EncodeString("zip"); err != nil {
+			return err
+		}
+		if err := enc.EncodeString(x.Zip); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ReadMsgpack implements serializer.GeneratedMsgpackDecoder
+func (x *Address) ReadMsgpack(dec *msgpack.Decoder) error {
+	n, err := dec.DecodeMapLen()
+	if err != nil {
+		return err
+	}
+	var key []byte
+	for i := 0; i < n; i++ {
+		if key, err = serializer.DecodeMsgpackKey(dec, key); err != nil {
+			return err
+		}
+		switch string(key) {
+		case "street":
+			{
+				v0, err := dec.DecodeString()
+				if err != nil {
+					return err
+				}
+				x.Street = v0
+			}
+		case "city":
+			{
+				v0, err := dec.DecodeString()
+				if err != nil {
+					return err
+				}
+				x.City = v0
+			}
+		case "zip":
+			{
+				v0, err := dec.DecodeString()
+				if err != nil {
+					return err
+				}
+				x.Zip = v0
+			}
+		default:
+			if err := dec.Skip(); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// AppendJSON implements serializer.GeneratedJSONEncoder
+func (x User) AppendJSON(dst []byte) ([]byte, error) {
+	var err error
+	dst = append(dst, '{')
+	dst = append(dst, "\"id\":"...)
+	dst = strconv.AppendInt(dst, int64(x.ID), 10)
+	dst = append(dst, ",\"name\":"...)
+	dst = serializer.AppendJSONString(dst, x.Name)
+	if x.Email != "" {
+		dst = append(dst, ",\"email\":"...)
+		dst = serializer.AppendJSONString(dst, x.Email)
+	}
+	dst = append(dst, ",\"age\":"...)
+	dst = strconv.AppendInt(dst, int64(x.Age), 10)
+	dst = append(dst, ",\"score\":"...)
+	if dst, err = serializer.AppendJSONFloat(dst, float64(x.Score), 64); err != nil {
+		return dst, err
+	}
+	if x.Ratio != 0 {
+		dst = append(dst, ",\"ratio\":"...)
+		if dst, err = serializer.AppendJSONFloat(dst, float64(x.Ratio), 32); err != nil {
+			return dst, err
+		}
+	}
+	dst = append(dst, ",\"active\":"...)
+	dst = strconv.AppendBool(dst, x.Active)
+	dst = append(dst, ",\"flags\":"...)
+	dst = strconv.AppendUint(dst, uint64(x.Flags), 10)
+	dst = append(dst, ",\"status\":"...)
+	if dst, err = serializer.AppendJSONValue(dst, &x.Status); err != nil {
+		return dst, err
+	}
+	dst = append(dst, ",\"tags\":"...)
+	if x.Tags == nil {
+		dst = append(dst, "null"...)
+	} else {
+		dst = append(dst, '[')
+		for i0 := range x.Tags {
+			if i0 > 0 {
+				dst = append(dst, ',')
+			}
+			dst = serializer.AppendJSONString(dst, x.Tags[i0])
+		}
+		dst = append(dst, ']')
+	}
+	if len(x.Labels) != 0 {
+		dst = append(dst, ",\"labels\":"...)
+		if x.Labels == nil {
+			dst = append(dst, "null"...)
+		} else {
+			keys0 := make([]string, 0, len(x.Labels))
+			for k0 := range x.Labels {
+				keys0 = append(keys0, k0)
+			}
+			sort.Strings(keys0)
+			dst = append(dst, '{')
+			for i0, k0 := range keys0 {
+				if i0 > 0 {
+					dst = append(dst, ',')
+				}
+				dst = serializer.AppendJSONString(dst, k0)
+				dst = append(dst, ':')
+				dst = serializer.AppendJSONString(dst, x.Labels[k0])
+			}
+			dst = append(dst, '}')
+		}
+	}
+	dst = append(dst, ",\"home\":"...)
+	if dst, err = x.Home.AppendJSON(dst); err != nil {
+		return dst, err
+	}
+	if x.Work != nil {
+		dst = append(dst, ",\"work\":"...)
+		if x.Work == nil {
+			dst = append(dst, "null"...)
+		} else {
+			if dst, err = (*x.Work).AppendJSON(dst); err != nil {
+				return dst, err
+			}
+		}
+	}
+	dst = append(dst, ",\"previous\":"...)
+	if x.Previous == nil {
+		dst = append(dst, "null"...)
+	} else {
+		dst = append(dst, '[')
+		for i0 := range x.Previous {
+			if i0 > 0 {
+				dst = append(dst, ',')
+			}
+			if x.Previous[i0] == nil {
+				dst = append(dst, "null"...)
+			} else {
+				if dst, err = (*x.Previous[i0]).AppendJSON(dst); err != nil {
+					return dst, err
+				}
+			}
+		}
+		dst = append(dst, ']')
+	}
+	dst = append(dst, ",\"created\":"...)
+	if dst, err = serializer.AppendJSONValue(dst, &x.Created); err != nil {
+		return dst, err
+	}
+	if !serializer.IsZeroJSONValue(&x.Deleted) {
+		dst = append(dst, ",\"deleted\":"...)
+		if dst, err = serializer.AppendJSONValue(dst, &x.Deleted); err != nil {
+			return dst, err
+		}
+	}
+	if !serializer.IsEmptyJSONValue(&x.Extra) {
+		dst = append(dst, ",\"extra\":"...)
+		if dst, err = serializer.AppendJSONValue(dst, &x.Extra); err != nil {
+			return dst, err
+		}
+	}
+	dst = append(dst, ",\"raw\":"...)
+	if dst, err = serializer.AppendJSONValue(dst, &x.Raw); err != nil {
+		return dst, err
+	}
+	dst = append(dst, ",\"Nickname\":"...)
+	dst = serializer.AppendJSONString(dst, x.Nickname)
+	dst = append(dst, '}')
+	return dst, err
+}
+
+var jsonFieldsUser = []string{"id", "name", "email", "age", "score", "ratio", "active", "flags", "status", "tags", "labels", "home", "work", "previous", "created", "deleted", "extra", "raw", "Nickname"}
+
+// ReadJSON implements serializer.GeneratedJSONDecoder
+func (x *User) ReadJSON(r *serializer.JSONReader) error {
+	return r.ReadObject(jsonFieldsUser, func(field int) error {
+		switch field {
+		case 0:
+			if !r.ReadNull() {
+				v0, err := r.ReadInt(64)
+				if err != nil {
+					return err
+				}
+				x.ID = v0
+			}
+		case 1:
+			if !r.ReadNull() {
+				v0, err := r.ReadString()
+				if err != nil {
+					return err
+				}
+				x.Name = v0
+			}
+		case 2:
+			if !r.ReadNull() {
+				v0, err := r.ReadString()
+				if err != nil {
+					return err
+				}
+				x.Email = v0
+			}
+		case 3:
+			if !r.ReadNull() {
+				v0, err := r.ReadInt(strconv.IntSize)
+				if err != nil {
+					return err
+				}
+				x.Age = int(v0)
+			}
+		case 4:
+			if !r.ReadNull() {
+				v0, err := r.ReadFloat(64)
+				if err != nil {
+					return err
+				}
+				x.Score = v0
+			}
+		case 5:
+			if !r.ReadNull() {
+				v0, err := r.ReadFloat(32)
+				if err != nil {
+					return err
+				}
+				x.Ratio = float32(v0)
+			}
+		case 6:
+			if !r.ReadNull() {
+				v0, err := r.ReadBool()
+				if err != nil {
+					return err
+				}
+				x.Active = v0
+			}
+		case 7:
+			if !r.ReadNull() {
+				v0, err := r.ReadUint(16)
+				if err != nil {
+					return err
+				}
+				x.Flags = uint16(v0)
+			}
+		case 8:
+			if err := r.ReadValue(&x.Status); err != nil {
+				return err
+			}
+		case 9:
+			if r.ReadNull() {
+				x.Tags = nil
+			} else {
+				s0 := x.Tags[:0]
+				if s0 == nil {
+					s0 = []string{}
+				}
+				if err := r.ReadArray(func() error {
+					var e0 string
+					if !r.ReadNull() {
+						v1, err := r.ReadString()
+						if err != nil {
+							return err
+						}
+						e0 = v1
+					}
+					s0 = append(s0, e0)
+					return nil
+				}); err != nil {
+					return err
+				}
+				x.Tags = s0
+			}
+		case 10:
+			if r.ReadNull() {
+				x.Labels = nil
+			} else {
+				if x.Labels == nil {
+					x.Labels = map[string]string{}
+				}
+				m0 := x.Labels
+				if err := r.ReadMap(func(k0 string) error {
+					var e0 string
+					if !r.ReadNull() {
+						v1, err := r.ReadString()
+						if err != nil {
+							return err
+						}
+						e0 = v1
+					}
+					m0[k0] = e0
+					return nil
+				}); err != nil {
+					return err
+				}
+			}
+		case 11:
+			if err := x.Home.ReadJSON(r); err != nil {
+				return err
+			}
+		case 12:
+			if r.ReadNull() {
+				x.Work = nil
+			} else {
+				if x.Work == nil {
+					x.Work = new(Address)
+				}
+				if err := (*x.Work).ReadJSON(r); err != nil {
+					return err
+				}
+			}
+		case 13:
+			if r.ReadNull() {
+				x.Previous = nil
+			} else {
+				s0 := x.Previous[:0]
+				if s0 == nil {
+					s0 = []*Address{}
+				}
+				if err := r.ReadArray(func() error {
+					var e0 *Address
+					if r.ReadNull() {
+						e0 = nil
+					} else {
+						if e0 == nil {
+							e0 = new(Address)
+						}
+						if err := (*e0).ReadJSON(r); err != nil {
+							return err
+						}
+					}
+					s0 = append(s0, e0)
+					return nil
+				}); err != nil {
+					return err
+				}
+				x.Previous = s0
+			}
+		case 14:
+			if err := r.ReadValue(&x.Created); err != nil {
+				return err
+			}
+		case 15:
+			if err := r.ReadValue(&x.Deleted); err != nil {
+				return err
+			}
+		case 16:
+			if err := r.ReadValue(&x.Extra); err != nil {
+				return err
+			}
+		case 17:
+			if err := r.ReadValue(&x.Raw); err != nil {
+				return err
+			}
+		case 18:
+			if !r.ReadNull() {
+				v0, err := r.ReadString()
+				if err != nil {
+					return err
+				}
+				x.Nickname = v0
+			}
+		}
+		return nil
+	})
+}
+
+// WriteMsgpack implements serializer.GeneratedMsgpackEncoder
+func (x User) WriteMsgpack(enc *msgpack.Encoder) error {
+	n := 13
+	if x.Email != "" {
+		n++
+	}
+	if x.Ratio != 0 {
+		n++
+	}
+	if len(x.Labels) != 0 {
+		n++
+	}
+	if x.Work != nil {
+		n++
+	}
+	if !serializer.IsEmptyMsgpackValue(&x.Deleted) {
+		n++
+	}
+	if !serializer.IsEmptyMsgpackValue(&x.Extra) {
+		n++
+	}
+	if err := enc.EncodeMapLen(n); err != nil {
+		return err
+	}
+	if err := enc.EncodeString("id"); err != nil {
+		return err
+	}
+	if err := enc.EncodeInt64(x.ID); err != nil {
+		return err
+	}
+	if err := enc.EncodeString("name"); err != nil {
+		return err
+	}
+	if err := enc.EncodeString(x.Name); err != nil {
+		return err
+	}
+	if x.Email != "" {
+		if err := enc.EncodeString("email"); err != nil {
+			return err
+		}
+		if err := enc.EncodeString(x.Email); err != nil {
+			return err
+		}
+	}
+	if err := enc.EncodeString("age"); err != nil {
+		return err
+	}
+	if err := enc.EncodeInt(int64(x.Age)); err != nil {
+		return err
+	}
+	if err := enc.EncodeString("score"); err != nil {
+		return err
+	}
+	if err := enc.EncodeFloat64(x.Score); err != nil {
+		return err
+	}
+	if x.Ratio != 0 {
+		if err := enc.EncodeString("ratio"); err != nil {
+			return err
+		}
+		if err := enc.EncodeFloat32(x.Ratio); err != nil {
+			return err
+		}
+	}
+	if err := enc.EncodeString("active"); err != nil {
+		return err
+	}
+	if err := enc.EncodeBool(x.Active); err != nil {
+		return err
+	}
+	if err := enc.EncodeString("flags"); err != nil {
+		return err
+	}
+	if err := enc.EncodeUint16(x.Flags); err != nil {
+		return err
+	}
+	if err := enc.EncodeString("status"); err != nil {
+		return err
+	}
+	if err := enc.Encode(&x.Status); err != nil {
+		return err
+	}
+	if err := enc.EncodeString("tags"); err != nil {
+		return err
+	}
+	if x.Tags == nil {
+		if err := enc.EncodeNil(); err != nil {
+			return err
+		}
+	} else {
+		if err := enc.EncodeArrayLen(len(x.Tags)); err != nil {
+			return err
+		}
+		for i0 := range x.Tags {
+			if err := enc.EncodeString(x.Tags[i0]); err != nil {
+				return err
+			}
+		}
+	}
+	if len(x.Labels) != 0 {
+		if err := enc.EncodeString("labels"); err != nil {
+			return err
+		}
+		if x.Labels == nil {
+			if err := enc.EncodeNil(); err != nil {
+				return err
+			}
+		} else {
+			if err := enc.EncodeMapLen(len(x.Labels)); err != nil {
+				return err
+			}
+			for k0, v0 := range x.Labels {
+				if err := enc.EncodeString(k0); err != nil {
+					return err
+				}
+				if err := enc.EncodeString(v0); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	if err := enc.EncodeString("home"); err != nil {
+		return err
+	}
+	if err := x.Home.WriteMsgpack(enc); err != nil {
+		return err
+	}
+	if x.Work != nil {
+		if err := enc.EncodeString("work"); err != nil {
+			return err
+		}
+		if x.Work == nil {
+			if err := enc.EncodeNil(); err != nil {
+				return err
+			}
+		} else {
+			if err := (*x.Work).WriteMsgpack(enc); err != nil {
+				return err
+			}
+		}
+	}
+	if err := enc.EncodeString("previous"); err != nil {
+		return err
+	}
+	if x.Previous == nil {
+		if err := enc.EncodeNil(); err != nil {
+			return err
+		}
+	} else {
+		if err := enc.EncodeArrayLen(len(x.Previous)); err != nil {
+			return err
+		}
+		for i0 := range x.Previous {
+			if x.Previous[i0] == nil {
+				if err := enc.EncodeNil(); err != nil {
+					return err
+				}
+			} else {
+				if err := (*x.Previous[i0]).WriteMsgpack(enc); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	if err := enc.EncodeString("created"); err != nil {
+		return err
+	}
+	if err := enc.Encode(&x.Created); err != nil {
+		return err
+	}
+	if !serializer.IsEmptyMsgpackValue(&x.Deleted) {
+		if err := enc.EncodeString("deleted"); err != nil {
+			return err
+		}
+		if err := enc.Encode(&x.Deleted); err != nil {
+			return err
+		}
+	}
+	if !serializer.IsEmptyMsgpackValue(&x.Extra) {
+		if err := enc.EncodeString("extra"); err != nil {
+			return err
+		}
+		if err := enc.Encode(&x.Extra); err != nil {
+			return err
+		}
+	}
+	if err := enc.EncodeString("raw"); err != nil {
+		return err
+	}
+	if err := enc.Encode(&x.Raw); err != nil {
+		return err
+	}
+	if err := enc.EncodeString("Nickname"); err != nil {
+		return err
+	}
+	if err := enc.EncodeString(x.Nickname); err != nil {
+		return err
+	}
+	return nil
+}
+
+// ReadMsgpack implements serializer.GeneratedMsgpackDecoder
+func (x *User) ReadMsgpack(dec *msgpack.Decoder) error {
+	n, err := dec.DecodeMapLen()
+	if err != nil {
+		return err
+	}
+	var key []byte
+	for i := 0; i < n; i++ {
+		if key, err = serializer.DecodeMsgpackKey(dec, key); err != nil {
+			return err
+		}
+		switch string(key) {
+		case "id":
+			{
+				v0, err := dec.DecodeInt64()
+				if err != nil {
+					return err
+				}
+				x.ID = v0
+			}
+		case "name":
+			{
+				v0, err := dec.DecodeString()
+				if err != nil {
+					return err
+				}
+				x.Name = v0
+			}
+		case "email":
+			{
+				v0, err := dec.DecodeString()
+				if err != nil {
+					return err
+				}
+				x.Email = v0
+			}
+		case "age":
+			{
+				v0, err := dec.DecodeInt()
+				if err != nil {
+					return err
+				}
+				x.Age = v0
+			}
+		case "score":
+			{
+				v0, err := dec.DecodeFloat64()
+				if err != nil {
+					return err
+				}
+				x.Score = v0
+			}
+		case "ratio":
+			{
+				v0, err := dec.DecodeFloat32()
+				if err != nil {
+					return err
+				}
+				x.Ratio = v0
+			}
+		case "active":
+			{
+				v0, err := dec.DecodeBool()
+				if err != nil {
+					return err
+				}
+				x.Active = v0
+			}
+		case "flags":
+			{
+				v0, err := dec.DecodeUint16()
+				if err != nil {
+					return err
+				}
+				x.Flags = v0
+			}
+		case "status":
+			if err := dec.Decode(&x.Status); err != nil {
+				return err
+			}
+		case "tags":
+			{
+				n0, err := dec.DecodeArrayLen()
+				if err != nil {
+					return err
+				}
+				if n0 == -1 {
+					x.Tags = nil
+				} else {
+					s0 := make([]string, n0)
+					for i0 := range s0 {
+						{
+							v1, err := dec.DecodeString()
+							if err != nil {
+								return err
+							}
+							s0[i0] = v1
+						}
+					}
+					x.Tags = s0
+				}
+			}
+		case "labels":
+			{
+				n0, err := dec.DecodeMapLen()
+				if err != nil {
+					return err
+				}
+				if n0 == -1 {
+					x.Labels = nil
+				} else {
+					m0 := make(map[string]string, n0)
+					for i0 := 0; i0 < n0; i0++ {
+						k0, err := dec.DecodeString()
+						if err != nil {
+							return err
+						}
+						var e0 string
+						{
+							v1, err := dec.DecodeString()
+							if err != nil {
+								return err
+							}
+							e0 = v1
+						}
+						m0[k0] = e0
+					}
+					x.Labels = m0
+				}
+			}
+		case "home":
+			if err := x.Home.ReadMsgpack(dec); err != nil {
+				return err
+			}
+		case "work":
+			if isNil0, err := serializer.DecodeMsgpackNil(dec); err != nil {
+				return err
+			} else if isNil0 {
+				x.Work = nil
+			} else {
+				if x.Work == nil {
+					x.Work = new(Address)
+				}
+				if err := (*x.Work).ReadMsgpack(dec); err != nil {
+					return err
+				}
+			}
+		case "previous":
+			{
+				n0, err := dec.DecodeArrayLen()
+				if err != nil {
+					return err
+				}
+				if n0 == -1 {
+					x.Previous = nil
+				} else {
+					s0 := make([]*Address, n0)
+					for i0 := range s0 {
+						if isNil1, err := serializer.DecodeMsgpackNil(dec); err != nil {
+							return err
+						} else if isNil1 {
+							s0[i0] = nil
+						} else {
+							if s0[i0] == nil {
+								s0[i0] = new(Address)
+							}
+							if err := (*s0[i0]).ReadMsgpack(dec); err != nil {
+								return err
+							}
+						}
+					}
+					x.Previous = s0
+				}
+			}
+		case "created":
+			if err := dec.Decode(&x.Created); err != nil {
+				return err
+			}
+		case "deleted":
+			if err := dec.Decode(&x.Deleted); err != nil {
+				return err
+			}
+		case "extra":
+			if err := dec.Decode(&x.Extra); err != nil {
+				return err
+			}
+		case "raw":
+			if err := dec.Decode(&x.Raw); err != nil {
+				return err
+			}
+		case "Nickname":
+			{
+				v0, err := dec.DecodeString()
+				if err != nil {
+					return err
+				}
+				x.Nickname = v0
+			}
+		default:
+			if err := dec.Skip(); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// AppendJSON implements serializer.GeneratedJSONEncoder
+func (x Event) AppendJSON(dst []byte) ([]byte, error) {
+	var err error
+	dst = append(dst, '{')
+	if x.Kind != "" {
+		dst = append(dst, "\"kind\":"...)
+		dst = serializer.AppendJSONString(dst, x.Kind)
+	}
+	if x.Count != 0 {
+		if dst[len(dst)-1] != '{' {
+			dst = append(dst, ',')
+		}
+		dst = append(dst, "\"count\":"...)
+		dst = strconv.AppendUint(dst, uint64(x.Count), 10)
+	}
+	if x.At != nil {
+		if dst[len(dst)-1] != '{' {
+			dst = append(dst, ',')
+		}
+		dst = append(dst, "\"at\":"...)
+		if x.At == nil {
+			dst = append(dst, "null"...)
+		} else {
+			if dst, err = serializer.AppendJSONValue(dst, x.At); err != nil {
+				return dst, err
+			}
+		}
+	}
+	if len(x.Values) != 0 {
+		if dst[len(dst)-1] != '{' {
+			dst = append(dst, ',')
+		}
+		dst = append(dst, "\"values\":"...)
+		if x.Values == nil {
+			dst = append(dst, "null"...)
+		} else {
+			keys0 := make([]string, 0, len(x.Values))
+			for k0 := range x.Values {
+				keys0 = append(keys0, k0)
+			}
+			sort.Strings(keys0)
+			dst = append(dst, '{')
+			for i0, k0 := range keys0 {
+				if i0 > 0 {
+					dst = append(dst, ',')
+				}
+				dst = serializer.AppendJSONString(dst, k0)
+				dst = append(dst, ':')
+				if x.Values[k0] == nil {
+					dst = append(dst, "null"...)
+				} else {
+					dst = append(dst, '[')
+					for i1 := range x.Values[k0] {
+						if i1 > 0 {
+							dst = append(dst, ',')
+						}
+						dst = strconv.AppendInt(dst, int64(x.Values[k0][i1]), 10)
+					}
+					dst = append(dst, ']')
+				}
+			}
+			dst = append(dst, '}')
+		}
+	}
+	dst = append(dst, '}')
+	return dst, err
+}
+
+var jsonFieldsEvent = []string{"kind", "count", "at", "values"}
+
+// ReadJSON implements serializer.GeneratedJSONDecoder
+func (x *Event) ReadJSON(r *serializer.JSONReader) error {
+	return r.ReadObject(jsonFieldsEvent, func(field int) error {
+		switch field {
+		case 0:
+			if !r.ReadNull() {
+				v0, err := r.ReadString()
+				if err != nil {
+					return err
+				}
+				x.Kind = v0
+			}
+		case 1:
+			if !r.ReadNull() {
+				v0, err := r.ReadUint(strconv.IntSize)
+				if err != nil {
+					return err
+				}
+				x.Count = uint(v0)
+			}
+		case 2:
+			if r.ReadNull() {
+				x.At = nil
+			} else {
+				if x.At == nil {
+					x.At = new(time.Time)
+				}
+				if err := r.ReadValue(x.At); err != nil {
+					return err
+				}
+			}
+		case 3:
+			if r.ReadNull() {
+				x.Values = nil
+			} else {
+				if x.Values == nil {
+					x.Values = map[string][]int{}
+				}
+				m0 := x.Values
+				if err := r.ReadMap(func(k0 string) error {
+					var e0 []int
+					if r.ReadNull() {
+						e0 = nil
+					} else {
+						s1 := e0[:0]
+						if s1 == nil {
+							s1 = []int{}
+						}
+						if err := r.ReadArray(func() error {
+							var e1 int
+							if !r.ReadNull() {
+								v2, err := r.ReadInt(strconv.IntSize)
+								if err != nil {
+									return err
+								}
+								e1 = int(v2)
+							}
+							s1 = append(s1, e1)
+							return nil
+						}); err != nil {
+							return err
+						}
+						e0 = s1
+					}
+					m0[k0] = e0
+					return nil
+				}); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+}
+
+// WriteMsgpack implements serializer.GeneratedMsgpackEncoder
+func (x Event) WriteMsgpack(enc *msgpack.Encoder) error {
+	n := 0
+	if x.Kind != "" {
+		n++
+	}
+	if x.Count != 0 {
+		n++
+	}
+	if x.At != nil {
+		n++
+	}
+	if len(x.Values) != 0 {
+		n++
+	}
+	if err := enc.EncodeMapLen(n); err != nil {
+		return err
+	}
+	if x.Kind != "" {
+		if err := enc.EncodeString("kind"); err != nil {
+			return err
+		}
+		if err := enc.EncodeString(x.Kind); err != nil {
+			return err
+		}
+	}
+	if x.Count != 0 {
+		if err := enc.EncodeString("count"); err != nil {
+			return err
+		}
+		if err := enc.EncodeUint(uint64(x.Count)); err != nil {
+			return err
+		}
+	}
+	if x.At != nil {
+		if err := enc.EncodeString("at"); err != nil {
+			return err
+		}
+		if x.At == nil {
+			if err := enc.EncodeNil(); err != nil {
+				return err
+			}
+		} else {
+			if err := enc.Encode(x.At); err != nil {
+				return err
+			}
+		}
+	}
+	if len(x.Values) != 0 {
+		if err := enc.EncodeString("values"); err != nil {
+			return err
+		}
+		if x.Values == nil {
+			if err := enc.EncodeNil(); err != nil {
+				return err
+			}
+		} else {
+			if err := enc.EncodeMapLen(len(x.Values)); err != nil {
+				return err
+			}
+			for k0, v0 := range x.Values {
+				if err := enc.EncodeString(k0); err != nil {
+					return err
+				}
+				if v0 == nil {
+					if err := enc.EncodeNil(); err != nil {
+						return err
+					}
+				} else {
+					if err := enc.EncodeArrayLen(len(v0)); err != nil {
+						return err
+					}
+					for i1 := range v0 {
+						if err := enc.EncodeInt(int64(v0[i1])); err != nil {
+							return err
+						}
+					}
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// ReadMsgpack implements serializer.GeneratedMsgpackDecoder
+func (x *Event) ReadMsgpack(dec *msgpack.Decoder) error {
+	n, err := dec.DecodeMapLen()
+	if err != nil {
+		return err
+	}
+	var key []byte
+	for i := 0; i < n; i++ {
+		if key, err = serializer.DecodeMsgpackKey(dec, key); err != nil {
+			return err
+		}
+		switch string(key) {
+		case "kind":
+			{
+				v0, err := dec.DecodeString()
+				if err != nil {
+					return err
+				}
+				x.Kind = v0
+			}
+		case "count":
+			{
+				v0, err := dec.DecodeUint()
+				if err != nil {
+					return err
+				}
+				x.Count = v0
+			}
+		case "at":
+			if isNil0, err := serializer.DecodeMsgpackNil(dec); err != nil {
+				return err
+			} else if isNil0 {
+				x.At = nil
+			} else {
+				if x.At == nil {
+					x.At = new(time.Time)
+				}
+				if err := dec.Decode(x.At); err != nil {
+					return err
+				}
+			}
+		case "values":
+			{
+				n0, err := dec.DecodeMapLen()
+				if err != nil {
+					return err
+				}
+				if n0 == -1 {
+					x.Values = nil
+				} else {
+					m0 := make(map[string][]int, n0)
+					for i0 := 0; i0 < n0; i0++ {
+						k0, err := dec.DecodeString()
+						if err != nil {
+							return err
+						}
+						var e0 []int
+						{
+							n1, err := dec.DecodeArrayLen()
+							if err != nil {
+								return err
+							}
+							if n1 == -1 {
+								e0 = nil
+							} else {
+								s1 := make([]int, n1)
+								for i1 := range s1 {
+									{
+										v2, err := dec.DecodeInt()
+										if err != nil {
+											return err
+										}
+										s1[i1] = v2
+									}
+								}
+								e0 = s1
+							}
+						}
+						m0[k0] = e0
+					}
+					x.Values = m0
+				}
+			}
+		default:
+			if err := dec.Skip(); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// AppendJSON implements serializer.GeneratedJSONEncoder
+func (x Order) AppendJSON(dst []byte) ([]byte, error) {
+	var err error
+	dst = append(dst, '{')
+	dst = append(dst, "\"id\":"...)
+	dst = serializer.AppendJSONString(dst, x.ID)
+	dst = append(dst, ",\"customer\":"...)
+	if dst, err = x.Customer.AppendJSON(dst); err != nil {
+		return dst, err
+	}
+	dst = append(dst, ",\"lines\":"...)
+	if x.Lines == nil {
+		dst = append(dst, "null"...)
+	} else {
+		dst = append(dst, '[')
+		for i0 := range x.Lines {
+			if i0 > 0 {
+				dst = append(dst, ',')
+			}
+			if dst, err = x.Lines[i0].AppendJSON(dst); err != nil {
+				return dst, err
+			}
+		}
+		dst = append(dst, ']')
+	}
+	dst = append(dst, ",\"total\":"...)
+	if dst, err = serializer.AppendJSONFloat(dst, float64(x.Total), 64); err != nil {
+		return dst, err
+	}
+	dst = append(dst, ",\"paid\":"...)
+	dst = strconv.AppendBool(dst, x.Paid)
+	if len(x.Notes) != 0 {
+		dst = append(dst, ",\"notes\":"...)
+		if x.Notes == nil {
+			dst = append(dst, "null"...)
+		} else {
+			keys0 := make([]string, 0, len(x.Notes))
+			for k0 := range x.Notes {
+				keys0 = append(keys0, k0)
+			}
+			sort.Strings(keys0)
+			dst = append(dst, '{')
+			for i0, k0 := range keys0 {
+				if i0 > 0 {
+					dst = append(dst, ',')
+				}
+				dst = serializer.AppendJSONString(dst, k0)
+				dst = append(dst, ':')
+				dst = serializer.AppendJSONString(dst, x.Notes[k0])
+			}
+			dst = append(dst, '}')
+		}
+	}
+	dst = append(dst, '}')
+	return dst, err
+}
+
+var jsonFieldsOrder = []string{"id", "customer", "lines", "total", "paid", "notes"}
+
+// ReadJSON implements serializer.GeneratedJSONDecoder
+func (x *Order) ReadJSON(r *serializer.JSONReader) error {
+	return r.ReadObject(jsonFieldsOrder, func(field int) error {
+		switch field {
+		case 0:
+			if !r.ReadNull() {
+				v0, err := r.ReadString()
+				if err != nil {
+					return err
+				}
+				x.ID = v0
+			}
+		case 1:
+			if err := x.Customer.ReadJSON(r); err != nil {
+				return err
+			}
+		case 2:
+			if r.ReadNull() {
+				x.Lines = nil
+			} else {
+				s0 := x.Lines[:0]
+				if s0 == nil {
+					s0 = []OrderLine{}
+				}
+				if err := r.ReadArray(func() error {
+					var e0 OrderLine
+					if err := e0.ReadJSON(r); err != nil {
+						return err
+					}
+					s0 = append(s0, e0)
+					return nil
+				}); err != nil {
+					return err
+				}
+				x.Lines = s0
+			}
+		case 3:
+			if !r.ReadNull() {
+				v0, err := r.ReadFloat(64)
+				if err != nil {
+					return err
+				}
+				x.Total = v0
+			}
+		case 4:
+			if !r.ReadNull() {
+				v0, err := r.ReadBool()
+				if err != nil {
+					return err
+				}
+				x.Paid = v0
+			}
+		case 5:
+			if r.ReadNull() {
+				x.Notes = nil
+			} else {
+				if x.Notes == nil {
+					x.Notes = map[string]string{}
+				}
+				m0 := x.Notes
+				if err := r.ReadMap(func(k0 string) error {
+					var e0 string
+					if !r.ReadNull() {
+						v1, err := r.ReadString()
+						if err != nil {
+							return err
+						}
+						e0 = v1
+					}
+					m0[k0] = e0
+					return nil
+				}); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+}
+
+// WriteMsgpack implements serializer.GeneratedMsgpackEncoder
+func (x Order) WriteMsgpack(enc *msgpack.Encoder) error {
+	n := 5
+	if len(x.Notes) != 0 {
+		n++
+	}
+	if err := enc.EncodeMapLen(n); err != nil {
+		return err
+	}
+	if err := enc.EncodeString("id"); err != nil {
+		return err
+	}
+	if err := enc.EncodeString(x.ID); err != nil {
+		return err
+	}
+	if err := enc.EncodeString("customer"); err != nil {
+		return err
+	}
+	if err := x.Customer.WriteMsgpack(enc); err != nil {
+		return err
+	}
+	if err := enc.EncodeString("lines"); err != nil {
+		return err
+	}
+	if x.Lines == nil {
+		if err := enc.EncodeNil(); err != nil {
+			return err
+		}
+	} else {
+		if err := enc.EncodeArrayLen(len(x.Lines)); err != nil {
+			return err
+		}
+		for i0 := range x.Lines {
+			if err := x.Lines[i0].WriteMsgpack(enc); err != nil {
+				return err
+			}
+		}
+	}
+	if err := enc.EncodeString("total"); err != nil {
+		return err
+	}
+	if err := enc.EncodeFloat64(x.Total); err != nil {
+		return err
+	}
+	if err := enc.EncodeString("paid"); err != nil {
+		return err
+	}
+	if err := enc.EncodeBool(x.Paid); err != nil {
+		return err
+	}
+	if len(x.Notes) != 0 {
+		if err := enc.EncodeString("notes"); err != nil {
+			return err
+		}
+		if x.Notes == nil {
+			if err := enc.EncodeNil(); err != nil {
+				return err
+			}
+		} else {
+			if err := enc.EncodeMapLen(len(x.Notes)); err != nil {
+				return err
+			}
+			for k0, v0 := range x.Notes {
+				if err := enc.EncodeString(k0); err != nil {
+					return err
+				}
+				if err := enc.EncodeString(v0); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// ReadMsgpack implements serializer.GeneratedMsgpackDecoder
+func (x *Order) ReadMsgpack(dec *msgpack.Decoder) error {
+	n, err := dec.DecodeMapLen()
+	if err != nil {
+		return err
+	}
+	var key []byte
+	for i := 0; i < n; i++ {
+		if key, err = serializer.DecodeMsgpackKey(dec, key); err != nil {
+			return err
+		}
+		switch string(key) {
+		case "id":
+			{
+				v0, err := dec.DecodeString()
+				if err != nil {
+					return err
+				}
+				x.ID = v0
+			}
+		case "customer":
+			if err := x.Customer.ReadMsgpack(dec); err != nil {
+				return err
+			}
+		case "lines":
+			{
+				n0, err := dec.DecodeArrayLen()
+				if err != nil {
+					return err
+				}
+				if n0 == -1 {
+					x.Lines = nil
+				} else {
+					s0 := make([]OrderLine, n0)
+					for i0 := range s0 {
+						if err := s0[i0].ReadMsgpack(dec); err != nil {
+							return err
+						}
+					}
+					x.Lines = s0
+				}
+			}
+		case "total":
+			{
+				v0, err := dec.DecodeFloat64()
+				if err != nil {
+					return err
+				}
+				x.Total = v0
+			}
+		case "paid":
+			{
+				v0, err := dec.DecodeBool()
+				if err != nil {
+					return err
+				}
+				x.Paid = v0
+			}
+		case "notes":
+			{
+				n0, err := dec.DecodeMapLen()
+				if err != nil {
+					return err
+				}
+				if n0 == -1 {
+					x.Notes = nil
+				} else {
+					m0 := make(map[string]string, n0)
+					for i0 := 0; i0 < n0; i0++ {
+						k0, err := dec.DecodeString()
+						if err != nil {
+							return err
+						}
+						var e0 string
+						{
+							v1, err := dec.DecodeString()
+							if err != nil {
+								return err
+							}
+							e0 = v1
+						}
+						m0[k0] = e0
+					}
+					x.Notes = m0
+				}
+			}
+		default:
+			if err := dec.Skip(); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// AppendJSON implements serializer.GeneratedJSONEncoder
+func (x OrderLine) AppendJSON(dst []byte) ([]byte, error) {
+	var err error
+	dst = append(dst, '{')
+	dst = append(dst, "\"sku\":"...)
+	dst = serializer.AppendJSONString(dst, x.SKU)
+	dst = append(dst, ",\"quantity\":"...)
+	dst = strconv.AppendInt(dst, int64(x.Quantity), 10)
+	dst = append(dst, ",\"price\":"...)
+	if dst, err = serializer.AppendJSONFloat(dst, float64(x.Price), 64); err != nil {
+		return dst, err
+	}
+	dst = append(dst, '}')
+	return dst, err
+}
+
+var jsonFieldsOrderLine = []string{"sku", "quantity", "price"}
+
+// ReadJSON implements serializer.GeneratedJSONDecoder
+func (x *OrderLine) ReadJSON(r *serializer.JSONReader) error {
+	return r.ReadObject(jsonFieldsOrderLine, func(field int) error {
+		switch field {
+		case 0:
+			if !r.ReadNull() {
+				v0, err := r.ReadString()
+				if err != nil {
+					return err
+				}
+				x.SKU = v0
+			}
+		case 1:
+			if !r.ReadNull() {
+				v0, err := r.ReadInt(strconv.IntSize)
+				if err != nil {
+					return err
+				}
+				x.Quantity = int(v0)
+			}
+		case 2:
+			if !r.ReadNull() {
+				v0, err := r.ReadFloat(64)
+				if err != nil {
+					return err
+				}
+				x.Price = v0
+			}
+		}
+		return nil
+	})
+}
+
+// WriteMsgpack implements serializer.GeneratedMsgpackEncoder
+func (x OrderLine) WriteMsgpack(enc *msgpack.Encoder) error {
+	if err := enc.EncodeMapLen(3); err != nil {
+		return err
+	}
+	if err := enc.EncodeString("sku"); err != nil {
+		return err
+	}
+	if err := enc.EncodeString(x.SKU); err != nil {
+		return err
+	}
+	if err := enc.EncodeString("quantity"); err != nil {
+		return err
+	}
+	if err := enc.EncodeInt(int64(x.Quantity)); err != nil {
+		return err
+	}
+	if err := enc.EncodeString("price"); err != nil {
+		return err
+	}
+	if err := enc.EncodeFloat64(x.Price); err != nil {
+		return err
+	}
+	return nil
+}
+
+// ReadMsgpack implements serializer.GeneratedMsgpackDecoder
+func (x *OrderLine) ReadMsgpack(dec *msgpack.Decoder) error {
+	n, err := dec.DecodeMapLen()
+	if err != nil {
+		return err
+	}
+	var key []byte
+	for i := 0; i < n; i++ {
+		if key, err = serializer.DecodeMsgpackKey(dec, key); err != nil {
+			return err
+		}
+		switch string(key) {
+		case "sku":
+			{
+				v0, err := dec.DecodeString()
+				if err != nil {
+					return err
+				}
+				x.SKU = v0
+			}
+		case "quantity":
+			{
+				v0, err := dec.DecodeInt()
+				if err != nil {
+					return err
+				}
+				x.Quantity = v0
+			}
+		case "price":
+			{
+				v0, err := dec.DecodeFloat64()
+				if err != nil {
+					return err
+				}
+				x.Price = v0
+			}
+		default:
+			if err := dec.Skip(); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,79 @@
+// Package gentest holds structs with codecs generated by serializer-gen, used to
+// check them against the reflection-based encoders
+package gentest
+
+//go:generate go run ../../cmd/serializer-gen
+
+import (
+	"time"
+)
+
+// Status is a named basic type
+type Status string
+
+// Address is a nested annotated struct
+//
+//serializer:generate
+type Address struct {
+	Street string `json:"street" msgpack:"street"`
+	City   string `json:"city" msgpack:"city"`
+	Zip    string `json:"zip,omitempty" msgpack:"zip,omitempty"`
+}
+
+// User covers the field kinds serializer-gen generates code for
+//
+//serializer:generate
+type User struct {
+	ID       int64             `json:"id" msgpack:"id"`
+	Name     string            `json:"name" msgpack:"name"`
+	Email    string            `json:"email,omitempty" msgpack:"email,omitempty"`
+	Age      int               `json:"age" msgpack:"age"`
+	Score    float64           `json:"score" msgpack:"score"`
+	Ratio    float32           `json:"ratio,omitempty" msgpack:"ratio,omitempty"`
+	Active   bool              `json:"active" msgpack:"active"`
+	Flags    uint16            `json:"flags" msgpack:"flags"`
+	Status   Status            `json:"status" msgpack:"status"`
+	Tags     []string          `json:"tags" msgpack:"tags"`
+	Labels   map[string]string `json:"labels,omitempty" msgpack:"labels,omitempty"`
+	Home     Address           `json:"home" msgpack:"home"`
+	Work     *Address          `json:"work,omitempty" msgpack:"work,omitempty"`
+	Previous []*Address        `json:"previous" msgpack:"previous"`
+	Created  time.Time         `json:"created" msgpack:"created"`
+	Deleted  time.Time         `json:"deleted,omitzero" msgpack:"deleted,omitempty"`
+	Extra    any               `json:"extra,omitempty" msgpack:"extra,omitempty"`
+	Raw      []byte            `json:"raw" msgpack:"raw"`
+	Password string            `json:"-" msgpack:"-"`
+	Nickname string
+	internal int
+}
+
+// Event has only optional fields, so its separators are chosen at run time
+//
+//serializer:generate
+type Event struct {
+	Kind   string           `json:"kind,omitempty" msgpack:"kind,omitempty"`
+	Count  uint             `json:"count,omitempty" msgpack:"count,omitempty"`
+	At     *time.Time       `json:"at,omitempty" msgpack:"at,omitempty"`
+	Values map[string][]int `json:"values,omitempty" msgpack:"values,omitempty"`
+}
+
+// Order only has fields with generated code
+//
+//serializer:generate
+type Order struct {
+	ID       string            `json:"id" msgpack:"id"`
+	Customer Address           `json:"customer" msgpack:"customer"`
+	Lines    []OrderLine       `json:"lines" msgpack:"lines"`
+	Total    float64           `json:"total" msgpack:"total"`
+	Paid     bool              `json:"paid" msgpack:"paid"`
+	Notes    map[string]string `json:"notes,omitempty" msgpack:"notes,omitempty"`
+}
+
+// OrderLine is an element of Order.Lines
+//
+//serializer:generate
+type OrderLine struct {
+	SKU      string  `json:"sku" msgpack:"sku"`
+	Quantity int     `json:"quantity" msgpack:"quantity"`
+	Price    float64 `json:"price" msgpack:"price"`
+}
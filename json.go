@@ -2,55 +2,86 @@ package serializer
 
 import (
 	"bytes"
+	stdjson "encoding/json"
 	"errors"
+	"fmt"
 	"io"
 	"sync"
-
-	jsoniter "github.com/json-iterator/go"
 )
 
-var json = jsoniter.ConfigFastest
-
 type pooledBufferPool struct {
 	pool          sync.Pool
 	maxBufferSize int
+
+	// observe, if set, is called with every get, put and discard
+	observe func(event PoolEvent, capacity int)
+
+	stats poolCounters
 }
 
 func newPooledBufferPool(maxSize int) *pooledBufferPool {
-	return &pooledBufferPool{
-		pool: sync.Pool{
-			New: func() any {
-				return new(bytes.Buffer)
-			},
-		},
-		maxBufferSize: maxSize,
-	}
+	return &pooledBufferPool{maxBufferSize: maxSize}
 }
 
 func (p *pooledBufferPool) Get() *bytes.Buffer {
-	return p.pool.Get().(*bytes.Buffer)
+	p.stats.gets.Add(1)
+	p.stats.inUse.Add(1)
+	buf, ok := p.pool.Get().(*bytes.Buffer)
+	if !ok {
+		p.stats.misses.Add(1)
+		p.notify(PoolMiss, 0)
+		return new(bytes.Buffer)
+	}
+	p.notify(PoolHit, buf.Cap())
+	return buf
 }
 
 func (p *pooledBufferPool) Put(buf *bytes.Buffer) {
+	p.stats.inUse.Add(-1)
 	if p.maxBufferSize > 0 && buf.Cap() > p.maxBufferSize {
+		p.stats.discards.Add(1)
+		p.notify(PoolDiscard, buf.Cap())
 		return
 	}
 
 	buf.Reset() // ensure no data lingers in memory
+	p.stats.puts.Add(1)
+	p.notify(PoolPut, buf.Cap())
 	p.pool.Put(buf)
 }
 
+func (p *pooledBufferPool) notify(event PoolEvent, capacity int) {
+	if p.observe != nil {
+		p.observe(event, capacity)
+	}
+}
+
+var (
+	_ IndentSerializer  = (*JSONSerializer)(nil)
+	_ PooledSerializer  = (*JSONSerializer)(nil)
+	_ OptionsSerializer = (*JSONSerializer)(nil)
+)
+
 // JSONSerializer implements Serializer using JSON encoding
 type JSONSerializer struct {
 	bufferPool *pooledBufferPool
+	opts       options
+	backend    JSONBackend
+	engine     jsonEngine
 }
 
 // NewJSONSerializer creates a new JSON serializer
 // If maxBufferSize <= 0, buffers are never capped.
-func NewJSONSerializer(maxBufferSize int) Serializer {
-	return &JSONSerializer{
+// With WithIndent, everything it writes is indented.
+func NewJSONSerializer(maxBufferSize int, opts ...Option) Serializer {
+	s := &JSONSerializer{
 		bufferPool: newPooledBufferPool(maxBufferSize),
+		opts:       newOptions(opts),
 	}
+	s.backend, s.engine = resolveJSONEngine(&s.opts)
+	s.opts.bindLogger(JSON)
+	s.bufferPool.observe = s.opts.poolObserver("json_buffer", maxBufferSize)
+	return s
 }
 
 func (s *JSONSerializer) Serialize(v any) ([]byte, error) {
@@ -61,40 +92,123 @@ func (s *JSONSerializer) Serialize(v any) ([]byte, error) {
 	buf := s.bufferPool.Get()
 	defer s.bufferPool.Put(buf)
 
-	enc := json.NewEncoder(buf)
-	enc.SetEscapeHTML(false)
+	if err := s.engine.encode(buf, v); err != nil {
+		s.opts.logFailure("serialize", err)
+		return nil, err
+	}
+
+	data := make([]byte, buf.Len())
+	copy(data, buf.Bytes())
+	s.opts.logSize("serialize", len(data))
+
+	return data, nil
+}
+
+// SerializeWithOptions implements OptionsSerializer. Options that need
+// json-iterator extensions, such as WithOmitEmpty(true) or WithTimeLayout, build a
+// fresh codec cache on every call, so hot paths should use a serializer created
+// with them instead.
+func (s *JSONSerializer) SerializeWithOptions(v any, opts ...Option) ([]byte, error) {
+	return s.withCallOptions(opts).Serialize(v)
+}
+
+// withCallOptions returns a serializer sharing s's buffer pool with opts applied
+// over s's options
+func (s *JSONSerializer) withCallOptions(opts []Option) *JSONSerializer {
+	if len(opts) == 0 {
+		return s
+	}
+	c := &JSONSerializer{bufferPool: s.bufferPool, opts: s.opts.withCallOptions(opts, JSON)}
+	c.backend, c.engine = resolveJSONEngine(&c.opts)
+	return c
+}
+
+// SerializeIndent is like Serialize but indents the output, with each line
+// starting with prefix followed by one or more copies of indent
+func (s *JSONSerializer) SerializeIndent(v any, prefix, indent string) ([]byte, error) {
+	if v == nil {
+		return nil, errors.New("cannot serialize nil value")
+	}
+
+	buf := s.bufferPool.Get()
+	defer s.bufferPool.Put(buf)
 
-	if err := enc.Encode(v); err != nil {
+	if err := s.encodeIndent(buf, v, prefix, indent); err != nil {
+		s.opts.logFailure("serialize_indent", err)
 		return nil, err
 	}
 
 	data := make([]byte, buf.Len())
 	copy(data, buf.Bytes())
+	s.opts.logSize("serialize_indent", len(data))
 
 	return data, nil
 }
 
+// SerializeIndentTo writes the indented encoding of v to w
+func (s *JSONSerializer) SerializeIndentTo(w io.Writer, v any, prefix, indent string) error {
+	if w == nil {
+		return errors.New("writer is nil")
+	}
+
+	buf := s.bufferPool.Get()
+	defer s.bufferPool.Put(buf)
+
+	err := s.encodeIndent(buf, v, prefix, indent)
+	if err == nil {
+		_, err = buf.WriteTo(w)
+	}
+	s.opts.logFailure("serialize_indent_to", err)
+	return err
+}
+
+// encodeIndent encodes v compactly into a pooled scratch buffer and indents it into dst.
+// Indenting afterwards works the same for every backend and keeps prefix support,
+// which jsoniter's own indentation lacks.
+func (s *JSONSerializer) encodeIndent(dst *bytes.Buffer, v any, prefix, indent string) error {
+	src := s.bufferPool.Get()
+	defer s.bufferPool.Put(src)
+
+	if err := s.engine.encode(src, v); err != nil {
+		return err
+	}
+	return stdjson.Indent(dst, src.Bytes(), prefix, indent)
+}
+
 func (s *JSONSerializer) Deserialize(data []byte, v any) error {
 	if data == nil {
 		return errors.New("data is nil")
 	}
-	return json.Unmarshal(data, v)
+	s.opts.logSize("deserialize", len(data))
+	err := s.opts.checkInputSize(len(data))
+	if err == nil {
+		data, err = stripBOM(data)
+	}
+	if err == nil {
+		err = s.engine.unmarshal(data, v)
+	}
+	s.opts.logFailure("deserialize", err)
+	return err
 }
 
 func (s *JSONSerializer) SerializeTo(w io.Writer, v any) error {
 	if w == nil {
 		return errors.New("writer is nil")
 	}
-	enc := json.NewEncoder(w)
-	enc.SetEscapeHTML(false)
-	return enc.Encode(v)
+	err := s.engine.encode(w, v)
+	s.opts.logFailure("serialize_to", err)
+	return err
 }
 
 func (s *JSONSerializer) DeserializeFrom(r io.Reader, v any) error {
 	if r == nil {
 		return errors.New("reader is nil")
 	}
-	return json.NewDecoder(r).Decode(v)
+	limited, limitErr := s.opts.limitInput(r)
+	stream, readErr := s.streamReader(limited)
+	err := limitErr(readErr(s.engine.decode(stream, v)))
+	s.opts.logFailure("deserialize_from", err)
+	return err
 }
 
 // DeserializeString implements StringDeserializer interface
@@ -103,7 +217,86 @@ func (s *JSONSerializer) DeserializeString(data string, v any) error {
 	if data == "" {
 		return errors.New("data is empty")
 	}
-	return json.Unmarshal(stringToReadOnlyBytes(data), v)
+	s.opts.logSize("deserialize_string", len(data))
+	err := s.opts.checkInputSize(len(data))
+	var b []byte
+	if err == nil {
+		b, err = stripBOM(stringToReadOnlyBytes(data))
+	}
+	if err == nil {
+		err = s.engine.unmarshal(b, v)
+	}
+	s.opts.logFailure("deserialize_string", err)
+	return err
+}
+
+// SerializePooled implements PooledSerializer. It encodes v into a buffer from the
+// serializer's pool and hands that buffer out without copying it. The caller must
+// call Release on the returned PooledBuf when done with its bytes.
+func (s *JSONSerializer) SerializePooled(v any) (*PooledBuf, error) {
+	if v == nil {
+		return nil, errors.New("cannot serialize nil value")
+	}
+
+	buf := s.bufferPool.Get()
+	if err := s.engine.encode(buf, v); err != nil {
+		s.bufferPool.Put(buf)
+		s.opts.logFailure("serialize_pooled", err)
+		return nil, err
+	}
+	s.opts.logSize("serialize_pooled", buf.Len())
+
+	return &PooledBuf{buf: buf, pool: s.bufferPool}, nil
+}
+
+// DeserializeFromPooled implements PooledSerializer. It decodes the bytes of pb
+// without copying them; pb is not released.
+func (s *JSONSerializer) DeserializeFromPooled(pb *PooledBuf, v any) error {
+	if pb == nil {
+		return errors.New("PooledBuf is nil")
+	}
+	data := pb.Bytes()
+	if data == nil {
+		return errors.New("PooledBuf contains no data")
+	}
+	return s.Deserialize(data, v)
+}
+
+// SerializeWithTypeInfo implements TypedSerializer interface. JSON needs no type
+// information to encode, so it is the same as Serialize.
+func (s *JSONSerializer) SerializeWithTypeInfo(v any, typeInfo TypeInfo) ([]byte, error) {
+	data, err := s.Serialize(v)
+	if err != nil {
+		return nil, fmt.Errorf("json serialization failed for type %s: %w", typeInfo.TypeName, err)
+	}
+	return data, nil
+}
+
+// DeserializeWithTypeInfo implements TypedSerializer interface. It decodes into a
+// new value of typeInfo.Type and returns that value.
+func (s *JSONSerializer) DeserializeWithTypeInfo(data []byte, typeInfo TypeInfo) (any, error) {
+	target, err := newTypedTarget(typeInfo)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.Deserialize(data, target.Interface()); err != nil {
+		return nil, fmt.Errorf("json deserialization failed for type %s: %w", typeInfo.TypeName, err)
+	}
+	return target.Elem().Interface(), nil
+}
+
+// PoolStats implements PoolStatsProvider for this serializer's buffer pool
+func (s *JSONSerializer) PoolStats() PoolStats {
+	return s.bufferPool.stats.snapshot()
+}
+
+func (s *JSONSerializer) maxInputSize() int64 {
+	return s.opts.maxInputSize
+}
+
+// Backend reports the JSON implementation in use
+func (s *JSONSerializer) Backend() JSONBackend {
+	return s.backend
 }
 
 func (s *JSONSerializer) ContentType() string {
@@ -0,0 +1,76 @@
+package serializer
+
+import (
+	stdjson "encoding/json"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// Element is one element of a JSON array being streamed by DecodeArrayStream.
+// Its raw bytes are only valid until the callback returns.
+type Element struct {
+	// Index is the zero-based position of the element in the array
+	Index int
+
+	raw stdjson.RawMessage
+	s   *JSONSerializer
+}
+
+// Decode decodes the element into v using the serializer's backend
+func (e Element) Decode(v any) error {
+	return e.s.engine.unmarshal(e.raw, v)
+}
+
+// Raw returns the element's undecoded JSON
+func (e Element) Raw() []byte {
+	return e.raw
+}
+
+// DecodeArrayStream reads a top-level JSON array from r and calls fn for each
+// element in order, holding only one element in memory at a time. It stops at
+// the first error returned by fn and returns that error unchanged.
+func (s *JSONSerializer) DecodeArrayStream(r io.Reader, fn func(Element) error) error {
+	if r == nil {
+		return errors.New("reader is nil")
+	}
+	if fn == nil {
+		return errors.New("element callback is nil")
+	}
+
+	stream, readErr := s.streamReader(r)
+	err := readErr(s.decodeArrayStream(stream, fn))
+	s.opts.logFailure("decode_array_stream", err)
+	return err
+}
+
+func (s *JSONSerializer) decodeArrayStream(r io.Reader, fn func(Element) error) error {
+	if s.opts.lenientJSON() {
+		r = &jsonFilterReader{r: r, f: s.opts.newJSONFilter()}
+	}
+	dec := stdjson.NewDecoder(r)
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	if delim, ok := tok.(stdjson.Delim); !ok || delim != '[' {
+		return fmt.Errorf("expected JSON array, found %v", tok)
+	}
+
+	elem := Element{s: s}
+	for ; dec.More(); elem.Index++ {
+		elem.raw = elem.raw[:0]
+		if err := dec.Decode(&elem.raw); err != nil {
+			return fmt.Errorf("array element %d: %w", elem.Index, err)
+		}
+		if err := fn(elem); err != nil {
+			return err
+		}
+	}
+
+	// Consume the closing bracket so truncated input is reported
+	if _, err := dec.Token(); err != nil {
+		return err
+	}
+	return nil
+}
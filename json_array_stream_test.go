@@ -0,0 +1,116 @@
+package serializer_test
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/MichaelAJay/go-serializer"
+)
+
+func TestDecodeArrayStream(t *testing.T) {
+	type row struct {
+		ID   int    `json:"id"`
+		Name string `json:"name"`
+	}
+
+	s := serializer.NewJSONSerializer(maxBufferSize).(*serializer.JSONSerializer)
+	input := `[{"id":1,"name":"a"}, {"id":2,"name":"b"},
+		{"id":3,"name":"c"}]`
+
+	var rows []row
+	err := s.DecodeArrayStream(strings.NewReader(input), func(e serializer.Element) error {
+		if e.Index != len(rows) {
+			t.Errorf("expected index %d, got %d", len(rows), e.Index)
+		}
+		var r row
+		if err := e.Decode(&r); err != nil {
+			return err
+		}
+		rows = append(rows, r)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("DecodeArrayStream failed: %v", err)
+	}
+	if len(rows) != 3 || rows[2].Name != "c" {
+		t.Errorf("unexpected rows %+v", rows)
+	}
+}
+
+func TestDecodeArrayStreamEmpty(t *testing.T) {
+	s := serializer.NewJSONSerializer(maxBufferSize).(*serializer.JSONSerializer)
+	calls := 0
+	err := s.DecodeArrayStream(strings.NewReader(" [ ] "), func(serializer.Element) error {
+		calls++
+		return nil
+	})
+	if err != nil || calls != 0 {
+		t.Errorf("expected no calls and no error, got %d calls, %v", calls, err)
+	}
+}
+
+func TestDecodeArrayStreamStopsOnCallbackError(t *testing.T) {
+	s := serializer.NewJSONSerializer(maxBufferSize).(*serializer.JSONSerializer)
+	stop := errors.New("stop")
+	calls := 0
+	err := s.DecodeArrayStream(strings.NewReader(`[1,2,3]`), func(serializer.Element) error {
+		calls++
+		return stop
+	})
+	if !errors.Is(err, stop) || calls != 1 {
+		t.Errorf("expected stop after 1 call, got %d calls, %v", calls, err)
+	}
+}
+
+func TestDecodeArrayStreamErrors(t *testing.T) {
+	s := serializer.NewJSONSerializer(maxBufferSize).(*serializer.JSONSerializer)
+	noop := func(serializer.Element) error { return nil }
+
+	if err := s.DecodeArrayStream(strings.NewReader(`{"a":1}`), noop); err == nil {
+		t.Error("expected error for non-array input")
+	}
+	if err := s.DecodeArrayStream(strings.NewReader(`[1,2`), noop); err == nil {
+		t.Error("expected error for truncated array")
+	}
+	if err := s.DecodeArrayStream(strings.NewReader(`[1,}`), noop); err == nil {
+		t.Error("expected error for malformed element")
+	}
+}
+
+// TestDecodeArrayStreamLarge checks elements are consumed incrementally from the reader
+func TestDecodeArrayStreamLarge(t *testing.T) {
+	const n = 10000
+	pr, pw := io.Pipe()
+	go func() {
+		pw.Write([]byte("["))
+		for i := 0; i < n; i++ {
+			if i > 0 {
+				pw.Write([]byte(","))
+			}
+			pw.Write([]byte(`{"value":"` + strings.Repeat("x", 100) + `"}`))
+		}
+		pw.Write([]byte("]"))
+		pw.Close()
+	}()
+
+	s := serializer.NewJSONSerializer(maxBufferSize).(*serializer.JSONSerializer)
+	count := 0
+	err := s.DecodeArrayStream(pr, func(e serializer.Element) error {
+		var v struct {
+			Value string `json:"value"`
+		}
+		if err := e.Decode(&v); err != nil {
+			return err
+		}
+		count++
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("DecodeArrayStream failed: %v", err)
+	}
+	if count != n {
+		t.Errorf("expected %d elements, got %d", n, count)
+	}
+}
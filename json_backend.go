@@ -0,0 +1,403 @@
+package serializer
+
+import (
+	"bytes"
+	stdjson "encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"sync"
+)
+
+// JSONBackend names the JSON implementation used by the JSON serializer
+type JSONBackend string
+
+const (
+	// JSONBackendJSONIter uses json-iterator (the default).
+	// It is not available when built with the nojsoniter or nounsafe tags or with TinyGo.
+	JSONBackendJSONIter JSONBackend = "jsoniter"
+	// JSONBackendStdlib uses encoding/json.
+	// It is the default when built with the nojsoniter or nounsafe tags.
+	JSONBackendStdlib JSONBackend = "stdlib"
+	// JSONBackendV2 uses the experimental encoding/json/v2 package.
+	// It is only available when built with GOEXPERIMENT=jsonv2.
+	JSONBackendV2 JSONBackend = "jsonv2"
+	// JSONBackendLite encodes strings, numbers, bools, []any, []string,
+	// map[string]any and map[string]string without reflection and uses encoding/json
+	// for everything else, including decoding. It is the default when built with TinyGo.
+	JSONBackendLite JSONBackend = "lite"
+)
+
+// jsonEngine is the encoding layer behind JSONSerializer.
+// encode writes v followed by a newline, like json.Encoder.Encode; the newline
+// is dropped by a wrapping engine when WithTrailingNewline(false) is set.
+type jsonEngine interface {
+	encode(w io.Writer, v any) error
+	unmarshal(data []byte, v any) error
+	decode(r io.Reader, v any) error
+}
+
+var scratchBufferPool = sync.Pool{New: func() any { return new(bytes.Buffer) }}
+
+// encodeBuffered lets engine wrappers rewrite encoded output before it reaches w.
+// Serialize hands engines its pooled buffer, which is used directly; other writers
+// get a scratch buffer. encode must only change bytes it appended itself.
+func encodeBuffered(w io.Writer, encode func(buf *bytes.Buffer) error) error {
+	if buf, ok := w.(*bytes.Buffer); ok {
+		return encode(buf)
+	}
+
+	buf := scratchBufferPool.Get().(*bytes.Buffer)
+	defer func() {
+		buf.Reset()
+		scratchBufferPool.Put(buf)
+	}()
+	if err := encode(buf); err != nil {
+		return err
+	}
+	_, err := buf.WriteTo(w)
+	return err
+}
+
+// jsonEngines holds constructors for the backends compiled into this binary.
+// Build-tag gated backends are contributed through taggedJSONEngines so they are
+// in place before any package-level serializer (such as DefaultRegistry) is built.
+var jsonEngines = func() map[JSONBackend]func(o *options) jsonEngine {
+	engines := map[JSONBackend]func(o *options) jsonEngine{
+		JSONBackendStdlib: func(o *options) jsonEngine { return stdlibEngine{escapeHTML: o.jsonEscapeHTML} },
+		JSONBackendLite:   func(o *options) jsonEngine { return liteEngine{escapeHTML: o.jsonEscapeHTML} },
+	}
+	for backend, newEngine := range taggedJSONEngines {
+		engines[backend] = newEngine
+	}
+	return engines
+}()
+
+// JSONBackendAvailable reports whether backend is compiled into this binary
+func JSONBackendAvailable(backend JSONBackend) bool {
+	_, ok := jsonEngines[backend]
+	return ok
+}
+
+// DefaultJSONBackend returns the backend used when none is selected
+func DefaultJSONBackend() JSONBackend {
+	return defaultJSONBackend
+}
+
+// WithJSONBackend selects the JSON implementation.
+// Unavailable backends fall back to DefaultJSONBackend; check JSONBackendAvailable
+// or JSONSerializer.Backend when the choice matters, e.g. in benchmarks.
+func WithJSONBackend(backend JSONBackend) Option {
+	return func(o *options) {
+		o.jsonBackend = backend
+	}
+}
+
+// WithStdlibJSON makes the JSON serializer use encoding/json.
+// To keep json-iterator out of the binary entirely, build with -tags nojsoniter.
+func WithStdlibJSON() Option {
+	return WithJSONBackend(JSONBackendStdlib)
+}
+
+// JSONConfig selects one of json-iterator's predefined configurations
+type JSONConfig int
+
+const (
+	// JSONConfigFastest is jsoniter.ConfigFastest (the default). It marshals
+	// floats with at most 6 digits after the decimal point.
+	JSONConfigFastest JSONConfig = iota
+	// JSONConfigDefault is jsoniter.ConfigDefault, which keeps full float precision
+	JSONConfigDefault
+	// JSONConfigCompatible is jsoniter.ConfigCompatibleWithStandardLibrary, which
+	// matches encoding/json output including sorted map keys
+	JSONConfigCompatible
+)
+
+// WithJSONConfig selects the json-iterator configuration used by the jsoniter backend.
+// Use JSONConfigDefault or JSONConfigCompatible when float precision matters.
+// Other backends ignore it.
+func WithJSONConfig(config JSONConfig) Option {
+	return func(o *options) {
+		o.jsonConfig = config
+	}
+}
+
+// WithEscapeHTML controls whether <, > and & in strings are escaped as \u003c,
+// \u003e and \u0026. Escaping is off by default; turn it on for payloads embedded
+// in HTML documents or templates.
+func WithEscapeHTML(escape bool) Option {
+	return func(o *options) {
+		o.jsonEscapeHTML = escape
+	}
+}
+
+// WithCaseSensitiveFields controls whether object keys must match struct field
+// names (or tags) exactly. json-iterator matches case-insensitively by default, as
+// encoding/json does; pass true for strict matching. The stdlib backend always
+// matches case-insensitively and ignores this option.
+func WithCaseSensitiveFields(caseSensitive bool) Option {
+	return func(o *options) {
+		o.jsonCaseSensitive = &caseSensitive
+	}
+}
+
+// WithInt64AsString encodes int64 and uint64 values as JSON strings so they survive
+// JavaScript consumers, whose numbers lose precision above 2^53. int and uint are
+// written as strings too on platforms where they are 64 bits wide. Decoding accepts
+// both strings and numbers. Types with their own MarshalJSON/MarshalText are left
+// untouched. Only the jsoniter backend supports it; `json:",string"` tags work with
+// every backend.
+func WithInt64AsString() Option {
+	return func(o *options) {
+		o.jsonInt64AsString = true
+	}
+}
+
+// DurationFormat selects how time.Duration values are written to JSON
+type DurationFormat int
+
+const (
+	// DurationNanoseconds writes durations as integer nanoseconds (the default)
+	DurationNanoseconds DurationFormat = iota
+	// DurationSeconds writes durations as floating-point seconds, e.g. 5400
+	DurationSeconds
+	// DurationString writes durations in time.Duration.String form, e.g. "1h30m0s"
+	DurationString
+)
+
+// WithDurationFormat sets how time.Duration values are encoded. Decoding accepts
+// duration strings in any format time.ParseDuration understands as well as numbers,
+// which are read as seconds with DurationSeconds and as nanoseconds otherwise.
+// It takes precedence over WithInt64AsString for durations. Only the jsoniter
+// backend supports it.
+func WithDurationFormat(format DurationFormat) Option {
+	return func(o *options) {
+		o.jsonDurationFormat = &format
+	}
+}
+
+// BytesFormat selects how []byte values are written to JSON
+type BytesFormat int
+
+const (
+	// BytesBase64 writes bytes as a standard base64 string (the default)
+	BytesBase64 BytesFormat = iota
+	// BytesHex writes bytes as a lowercase hex string
+	BytesHex
+	// BytesArray writes bytes as an array of numbers
+	BytesArray
+)
+
+// WithBytesFormat sets how []byte values are encoded. Decoding accepts all three
+// forms: arrays are read as numbers, and strings are decoded with the configured
+// encoding first, then with the other string encoding. Strings valid in both
+// base64 and hex (such as "cafe") are therefore read as the configured one.
+// Only the jsoniter backend supports it.
+func WithBytesFormat(format BytesFormat) Option {
+	return func(o *options) {
+		o.jsonBytesFormat = &format
+	}
+}
+
+// WithOmitZero skips struct fields that hold their type's zero value, as if every
+// field were tagged omitempty but using zero-ness rather than emptiness: zero
+// structs and types whose IsZero method reports true (such as time.Time) are
+// omitted too, while empty non-nil slices and maps are kept.
+// Only the jsoniter backend supports it.
+func WithOmitZero() Option {
+	return func(o *options) {
+		o.jsonOmitZero = true
+	}
+}
+
+// jsonDiscriminator selects the concrete type for an interface from a key in the JSON object
+type jsonDiscriminator struct {
+	iface reflect.Type
+	key   string
+	types *TypeRegistry
+}
+
+// WithDiscriminator decodes JSON objects into the interface type iface, given as a
+// nil pointer such as (*Shape)(nil), by looking up the string under key (such as
+// "type") in types, or DefaultTypeRegistry if types is nil. The registered type or
+// a pointer to it must implement the interface. Encoding is unchanged, so concrete
+// types should carry the key as a field. Only the jsoniter backend supports it.
+// It panics if iface is not a pointer to an interface type.
+func WithDiscriminator(iface any, key string, types *TypeRegistry) Option {
+	t := reflect.TypeOf(iface)
+	if t == nil || t.Kind() != reflect.Ptr || t.Elem().Kind() != reflect.Interface {
+		panic(fmt.Sprintf("serializer: WithDiscriminator needs a pointer to an interface type, got %v", t))
+	}
+	if types == nil {
+		types = DefaultTypeRegistry
+	}
+	d := jsonDiscriminator{iface: t.Elem(), key: key, types: types}
+	return func(o *options) {
+		o.jsonDiscriminators = append(o.jsonDiscriminators, d)
+	}
+}
+
+// InvalidUTF8Policy selects what happens to strings holding invalid UTF-8 when encoding JSON
+type InvalidUTF8Policy int
+
+const (
+	// InvalidUTF8Replace replaces each invalid byte with U+FFFD, as encoding/json does
+	InvalidUTF8Replace InvalidUTF8Policy = iota
+	// InvalidUTF8Reject fails the encode with ErrInvalidUTF8
+	InvalidUTF8Reject
+	// InvalidUTF8PassThrough copies invalid bytes into the output unchanged, which
+	// strict consumers may refuse to parse
+	InvalidUTF8PassThrough
+)
+
+// WithInvalidUTF8 sets how strings containing invalid UTF-8 are encoded. Without
+// it each backend keeps its own behaviour: json-iterator passes the bytes through,
+// encoding/json replaces them and json/v2 rejects them. The jsoniter backend
+// supports every policy but always replaces when WithEscapeHTML(true) is set;
+// encoding/json always replaces; json/v2 replaces unless rejecting.
+func WithInvalidUTF8(policy InvalidUTF8Policy) Option {
+	return func(o *options) {
+		o.jsonInvalidUTF8 = &policy
+	}
+}
+
+// WithTrailingNewline controls whether encoded JSON ends with a newline. The newline
+// is written by default, as json.Encoder does; disable it when output is used as a
+// cache key, hashed or signed and must match json.Marshal byte for byte. Serialize,
+// SerializeTo and SerializeIndent produce identical bytes either way.
+func WithTrailingNewline(enabled bool) Option {
+	return func(o *options) {
+		o.jsonOmitNewline = !enabled
+	}
+}
+
+// newlineTrimEngine drops the newline its engine writes after each value
+type newlineTrimEngine struct {
+	jsonEngine
+}
+
+func (e newlineTrimEngine) encode(w io.Writer, v any) error {
+	return encodeBuffered(w, func(buf *bytes.Buffer) error {
+		if err := e.jsonEngine.encode(buf, v); err != nil {
+			return err
+		}
+		if n := buf.Len(); n > 0 && buf.Bytes()[n-1] == '\n' {
+			buf.Truncate(n - 1)
+		}
+		return nil
+	})
+}
+
+// resolveJSONEngine returns the engine for the configured backend, falling back to the default
+func resolveJSONEngine(o *options) (JSONBackend, jsonEngine) {
+	backend := o.jsonBackend
+	if _, ok := jsonEngines[backend]; !ok {
+		backend = defaultJSONBackend
+	}
+	engine := withGeneratedJSON(jsonEngines[backend](o), backend, o)
+	if o.maxDepth > 0 {
+		// Inside the lenience filter, so brackets in comments are not counted
+		engine = depthLimitEngine{jsonEngine: engine, maxDepth: o.maxDepth}
+	}
+	engine = withLenience(engine, o)
+	if o.indent != "" || o.indentPrefix != "" {
+		engine = indentEngine{jsonEngine: engine, prefix: o.indentPrefix, indent: o.indent}
+	}
+	if o.jsonOmitNewline {
+		engine = newlineTrimEngine{engine}
+	}
+	if o.validation {
+		engine = validationEngine{jsonEngine: engine, opts: o}
+	}
+	return backend, engine
+}
+
+// indentEngine indents the output of its engine
+type indentEngine struct {
+	jsonEngine
+	prefix, indent string
+}
+
+func (e indentEngine) encode(w io.Writer, v any) error {
+	src := scratchBufferPool.Get().(*bytes.Buffer)
+	defer func() {
+		src.Reset()
+		scratchBufferPool.Put(src)
+	}()
+	if err := e.jsonEngine.encode(src, v); err != nil {
+		return err
+	}
+	return encodeBuffered(w, func(buf *bytes.Buffer) error {
+		return stdjson.Indent(buf, src.Bytes(), e.prefix, e.indent)
+	})
+}
+
+// depthLimitEngine refuses to encode values, or decode input, nested deeper than
+// WithMaxDepth allows
+type depthLimitEngine struct {
+	jsonEngine
+	maxDepth int
+}
+
+func (e depthLimitEngine) encode(w io.Writer, v any) error {
+	if err := checkDepth(v, e.maxDepth); err != nil {
+		return err
+	}
+	return e.jsonEngine.encode(w, v)
+}
+
+func (e depthLimitEngine) unmarshal(data []byte, v any) error {
+	d := jsonDepth{max: e.maxDepth}
+	if _, err := d.scan(data); err != nil {
+		return err
+	}
+	return e.jsonEngine.unmarshal(data, v)
+}
+
+func (e depthLimitEngine) decode(r io.Reader, v any) error {
+	dr := &depthLimitReader{r: r, depth: jsonDepth{max: e.maxDepth}}
+	err := e.jsonEngine.decode(dr, v)
+	if err != nil && dr.err != nil {
+		return dr.err
+	}
+	return err
+}
+
+// validationEngine checks decoded values as WithValidation configures
+type validationEngine struct {
+	jsonEngine
+	opts *options
+}
+
+func (e validationEngine) unmarshal(data []byte, v any) error {
+	if err := e.jsonEngine.unmarshal(data, v); err != nil {
+		return err
+	}
+	return e.opts.validate(v)
+}
+
+func (e validationEngine) decode(r io.Reader, v any) error {
+	if err := e.jsonEngine.decode(r, v); err != nil {
+		return err
+	}
+	return e.opts.validate(v)
+}
+
+// stdlibEngine uses encoding/json
+type stdlibEngine struct {
+	escapeHTML bool
+}
+
+func (e stdlibEngine) encode(w io.Writer, v any) error {
+	enc := stdjson.NewEncoder(w)
+	enc.SetEscapeHTML(e.escapeHTML)
+	return enc.Encode(v)
+}
+
+func (stdlibEngine) unmarshal(data []byte, v any) error {
+	return stdjson.Unmarshal(data, v)
+}
+
+func (stdlibEngine) decode(r io.Reader, v any) error {
+	return stdjson.NewDecoder(r).Decode(v)
+}
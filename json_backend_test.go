@@ -0,0 +1,809 @@
+package serializer_test
+
+import (
+	"bytes"
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/MichaelAJay/go-serializer"
+)
+
+func TestJSONBackendDefault(t *testing.T) {
+	s := serializer.NewJSONSerializer(maxBufferSize).(*serializer.JSONSerializer)
+	if s.Backend() != serializer.DefaultJSONBackend() {
+		t.Errorf("expected default backend %q, got %q", serializer.DefaultJSONBackend(), s.Backend())
+	}
+	if !serializer.JSONBackendAvailable(serializer.JSONBackendStdlib) {
+		t.Error("stdlib backend must always be available")
+	}
+}
+
+func TestJSONBackendFallback(t *testing.T) {
+	s := serializer.NewJSONSerializer(maxBufferSize, serializer.WithJSONBackend("missing")).(*serializer.JSONSerializer)
+	if s.Backend() != serializer.DefaultJSONBackend() {
+		t.Errorf("expected fallback to %q, got %q", serializer.DefaultJSONBackend(), s.Backend())
+	}
+}
+
+func TestJSONBackendsRoundTrip(t *testing.T) {
+	type item struct {
+		Name  string `json:"name"`
+		Count int    `json:"count"`
+	}
+
+	for _, backend := range []serializer.JSONBackend{serializer.JSONBackendJSONIter, serializer.JSONBackendStdlib, serializer.JSONBackendV2, serializer.JSONBackendLite} {
+		t.Run(string(backend), func(t *testing.T) {
+			if !serializer.JSONBackendAvailable(backend) {
+				t.Skipf("backend %q not compiled in", backend)
+			}
+			s := serializer.NewJSONSerializer(maxBufferSize, serializer.WithJSONBackend(backend))
+			in := item{Name: "<a&b>", Count: 3}
+
+			data, err := s.Serialize(in)
+			if err != nil {
+				t.Fatalf("Serialize failed: %v", err)
+			}
+			if want := "{\"name\":\"<a&b>\",\"count\":3}\n"; string(data) != want {
+				t.Errorf("expected %q, got %q", want, data)
+			}
+
+			var out item
+			if err := s.Deserialize(data, &out); err != nil {
+				t.Fatalf("Deserialize failed: %v", err)
+			}
+			if out != in {
+				t.Errorf("expected %+v, got %+v", in, out)
+			}
+
+			var buf bytes.Buffer
+			if err := s.SerializeTo(&buf, in); err != nil {
+				t.Fatalf("SerializeTo failed: %v", err)
+			}
+			out = item{}
+			if err := s.DeserializeFrom(&buf, &out); err != nil {
+				t.Fatalf("DeserializeFrom failed: %v", err)
+			}
+			if out != in {
+				t.Errorf("expected %+v, got %+v", in, out)
+			}
+		})
+	}
+}
+
+func BenchmarkJSONBackends(b *testing.B) {
+	for _, backend := range []serializer.JSONBackend{serializer.JSONBackendJSONIter, serializer.JSONBackendStdlib, serializer.JSONBackendV2, serializer.JSONBackendLite} {
+		if !serializer.JSONBackendAvailable(backend) {
+			continue
+		}
+		s := serializer.NewJSONSerializer(maxBufferSize, serializer.WithJSONBackend(backend))
+		for _, bd := range benchmarkData {
+			b.Run(string(backend)+"/"+bd.name, func(b *testing.B) {
+				b.ReportAllocs()
+				for i := 0; i < b.N; i++ {
+					data, err := s.Serialize(bd.data)
+					if err != nil {
+						b.Fatal(err)
+					}
+					var out any
+					if err := s.Deserialize(data, &out); err != nil {
+						b.Fatal(err)
+					}
+				}
+			})
+		}
+	}
+}
+
+func TestJSONConfigFloatPrecision(t *testing.T) {
+	if !serializer.JSONBackendAvailable(serializer.JSONBackendJSONIter) {
+		t.Skip("jsoniter backend not compiled in")
+	}
+	amount := map[string]float64{"amount": 1234.567891234}
+
+	tests := []struct {
+		name   string
+		config serializer.JSONConfig
+		want   string
+	}{
+		{"fastest", serializer.JSONConfigFastest, "{\"amount\":1234.567891}\n"},
+		{"default", serializer.JSONConfigDefault, "{\"amount\":1234.567891234}\n"},
+		{"compatible", serializer.JSONConfigCompatible, "{\"amount\":1234.567891234}\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := serializer.NewJSONSerializer(maxBufferSize, serializer.WithJSONConfig(tt.config))
+			data, err := s.Serialize(amount)
+			if err != nil {
+				t.Fatalf("Serialize failed: %v", err)
+			}
+			if string(data) != tt.want {
+				t.Errorf("expected %q, got %q", tt.want, data)
+			}
+		})
+	}
+}
+
+func TestWithStdlibJSON(t *testing.T) {
+	s := serializer.NewJSONSerializer(maxBufferSize, serializer.WithStdlibJSON()).(*serializer.JSONSerializer)
+	if s.Backend() != serializer.JSONBackendStdlib {
+		t.Fatalf("expected stdlib backend, got %q", s.Backend())
+	}
+
+	// encoding/json keeps full float precision and sorts map keys
+	data, err := s.Serialize(map[string]any{"b": 1234.567891234, "a": "<tag>"})
+	if err != nil {
+		t.Fatalf("Serialize failed: %v", err)
+	}
+	if want := "{\"a\":\"<tag>\",\"b\":1234.567891234}\n"; string(data) != want {
+		t.Errorf("expected %q, got %q", want, data)
+	}
+}
+
+func TestJSONConfigCompatibleSortsKeys(t *testing.T) {
+	s := serializer.NewJSONSerializer(maxBufferSize, serializer.WithJSONConfig(serializer.JSONConfigCompatible))
+	for i := 0; i < 10; i++ {
+		data, err := s.Serialize(map[string]int{"c": 3, "a": 1, "b": 2})
+		if err != nil {
+			t.Fatalf("Serialize failed: %v", err)
+		}
+		if want := "{\"a\":1,\"b\":2,\"c\":3}\n"; string(data) != want {
+			t.Fatalf("expected %q, got %q", want, data)
+		}
+	}
+}
+
+func TestWithInt64AsString(t *testing.T) {
+	if !serializer.JSONBackendAvailable(serializer.JSONBackendJSONIter) {
+		t.Skip("jsoniter backend not compiled in")
+	}
+	if strconv.IntSize != 64 {
+		t.Skip("int and uint are narrower than 64 bits")
+	}
+
+	type account struct {
+		ID      int64  `json:"id"`
+		Balance uint64 `json:"balance"`
+		Count   int    `json:"count"`
+		Seq     uint   `json:"seq"`
+		Small   int32  `json:"small"`
+		Ref     *int64 `json:"ref"`
+	}
+	ref := int64(-7)
+	in := account{ID: 9007199254740993, Balance: 18446744073709551615, Count: -9007199254740993, Seq: 9007199254740995, Small: 3, Ref: &ref}
+
+	s := serializer.NewJSONSerializer(maxBufferSize, serializer.WithInt64AsString())
+	data, err := s.Serialize(in)
+	if err != nil {
+		t.Fatalf("Serialize failed: %v", err)
+	}
+	want := "{\"id\":\"9007199254740993\",\"balance\":\"18446744073709551615\",\"count\":\"-9007199254740993\",\"seq\":\"9007199254740995\",\"small\":3,\"ref\":\"-7\"}\n"
+	if string(data) != want {
+		t.Errorf("expected %q, got %q", want, data)
+	}
+
+	var out account
+	if err := s.Deserialize(data, &out); err != nil {
+		t.Fatalf("Deserialize failed: %v", err)
+	}
+	if out != (account{ID: in.ID, Balance: in.Balance, Count: in.Count, Seq: in.Seq, Small: in.Small, Ref: out.Ref}) || out.Ref == nil || *out.Ref != ref {
+		t.Errorf("expected %+v, got %+v", in, out)
+	}
+
+	// Plain numbers are still accepted
+	out = account{}
+	if err := s.Deserialize([]byte(`{"id":42,"balance":"1","count":5,"seq":"6"}`), &out); err != nil {
+		t.Fatalf("Deserialize of numeric form failed: %v", err)
+	}
+	if out.ID != 42 || out.Balance != 1 || out.Count != 5 || out.Seq != 6 {
+		t.Errorf("unexpected %+v", out)
+	}
+
+	if err := s.Deserialize([]byte(`{"id":"12abc"}`), &out); err == nil {
+		t.Error("expected error for malformed integer string")
+	}
+
+	// Fields tagged ",string" are quoted once, by the tag
+	type tagged struct {
+		A int64   `json:"a,string"`
+		B *uint64 `json:"b,string"`
+		C int     `json:"c,string,omitempty"`
+	}
+	b := uint64(18446744073709551615)
+	data, err = s.Serialize(tagged{A: 1, B: &b, C: -2})
+	if want := "{\"a\":\"1\",\"b\":\"18446744073709551615\",\"c\":\"-2\"}\n"; err != nil || string(data) != want {
+		t.Errorf("expected %q, got %q, %v", want, data, err)
+	}
+	var back tagged
+	if err := s.Deserialize(data, &back); err != nil || back.A != 1 || back.B == nil || *back.B != b || back.C != -2 {
+		t.Errorf("round trip of ,string fields = %+v, %v", back, err)
+	}
+
+	// Other serializers are unaffected
+	plain, err := serializer.NewJSONSerializer(maxBufferSize).Serialize(in)
+	if err != nil {
+		t.Fatalf("Serialize failed: %v", err)
+	}
+	if want := "{\"id\":9007199254740993,\"balance\":18446744073709551615,\"count\":-9007199254740993,\"seq\":9007199254740995,\"small\":3,\"ref\":-7}\n"; string(plain) != want {
+		t.Errorf("expected %q, got %q", want, plain)
+	}
+}
+
+func TestSerializeIndent(t *testing.T) {
+	type point struct {
+		X int `json:"x"`
+		Y int `json:"y"`
+	}
+	want := "{\n>  \"x\": 1,\n>  \"y\": 2\n>}\n"
+
+	for _, backend := range []serializer.JSONBackend{serializer.JSONBackendJSONIter, serializer.JSONBackendStdlib} {
+		t.Run(string(backend), func(t *testing.T) {
+			if !serializer.JSONBackendAvailable(backend) {
+				t.Skipf("backend %q not compiled in", backend)
+			}
+			s := serializer.NewJSONSerializer(maxBufferSize, serializer.WithJSONBackend(backend)).(serializer.IndentSerializer)
+
+			data, err := s.SerializeIndent(point{1, 2}, ">", "  ")
+			if err != nil {
+				t.Fatalf("SerializeIndent failed: %v", err)
+			}
+			if string(data) != want {
+				t.Errorf("expected %q, got %q", want, data)
+			}
+
+			var buf bytes.Buffer
+			if err := s.SerializeIndentTo(&buf, point{1, 2}, ">", "  "); err != nil {
+				t.Fatalf("SerializeIndentTo failed: %v", err)
+			}
+			if buf.String() != want {
+				t.Errorf("expected %q, got %q", want, buf.String())
+			}
+
+			data, err = s.SerializeIndent(point{1, 2}, "", "\t")
+			if err != nil {
+				t.Fatalf("SerializeIndent failed: %v", err)
+			}
+			var out point
+			if err := s.(serializer.Serializer).Deserialize(data, &out); err != nil || out != (point{1, 2}) {
+				t.Errorf("indented output did not round trip: %+v, %v", out, err)
+			}
+		})
+	}
+
+	s := serializer.NewJSONSerializer(maxBufferSize).(*serializer.JSONSerializer)
+	if _, err := s.SerializeIndent(nil, "", "  "); err == nil {
+		t.Error("expected error for nil value")
+	}
+	if err := s.SerializeIndentTo(nil, point{}, "", "  "); err == nil {
+		t.Error("expected error for nil writer")
+	}
+}
+
+func TestWithIndent(t *testing.T) {
+	value := map[string][]int{"a": {1}}
+	want := "{\n \t\"a\": [\n \t\t1\n \t]\n }\n"
+
+	for _, backend := range []serializer.JSONBackend{serializer.JSONBackendJSONIter, serializer.JSONBackendStdlib, serializer.JSONBackendV2, serializer.JSONBackendLite} {
+		t.Run(string(backend), func(t *testing.T) {
+			if !serializer.JSONBackendAvailable(backend) {
+				t.Skipf("backend %q not compiled in", backend)
+			}
+			s := serializer.NewJSONSerializer(maxBufferSize, serializer.WithJSONBackend(backend),
+				serializer.WithIndent("\t"), serializer.WithIndentPrefix(" "))
+
+			data, err := s.Serialize(value)
+			if err != nil {
+				t.Fatalf("Serialize failed: %v", err)
+			}
+			if string(data) != want {
+				t.Errorf("expected %q, got %q", want, data)
+			}
+
+			var buf bytes.Buffer
+			if err := s.SerializeTo(&bufferWriter{&buf}, value); err != nil || buf.String() != want {
+				t.Errorf("SerializeTo: expected %q, got %q, %v", want, buf.String(), err)
+			}
+
+			var out map[string][]int
+			if err := s.Deserialize(data, &out); err != nil || len(out["a"]) != 1 {
+				t.Errorf("round trip failed: %v, %v", out, err)
+			}
+		})
+	}
+
+	s := serializer.NewJSONSerializer(maxBufferSize, serializer.WithIndent("  "), serializer.WithTrailingNewline(false))
+	if data, err := s.Serialize(map[string]int{"a": 1}); err != nil || string(data) != "{\n  \"a\": 1\n}" {
+		t.Errorf("without newline: got %q, %v", data, err)
+	}
+
+	// SerializeIndent replaces the configured indentation
+	indented, err := s.(serializer.IndentSerializer).SerializeIndent(map[string]int{"a": 1}, "", " ")
+	if err != nil || string(indented) != "{\n \"a\": 1\n}" {
+		t.Errorf("SerializeIndent: got %q, %v", indented, err)
+	}
+
+	// NDJSON records stay on one line
+	data, err := serializer.NewNDJSONSerializer(maxBufferSize, serializer.WithIndent("  ")).Serialize([]map[string]int{{"a": 1}})
+	if err != nil || string(data) != "{\"a\":1}\n" {
+		t.Errorf("NDJSON: got %q, %v", data, err)
+	}
+}
+
+func TestWithEscapeHTML(t *testing.T) {
+	value := map[string]string{"html": "<b>Tom & Jerry</b>"}
+	escaped := "{\"html\":\"\\u003cb\\u003eTom \\u0026 Jerry\\u003c/b\\u003e\"}\n"
+	raw := "{\"html\":\"<b>Tom & Jerry</b>\"}\n"
+
+	for _, backend := range []serializer.JSONBackend{serializer.JSONBackendJSONIter, serializer.JSONBackendStdlib, serializer.JSONBackendV2, serializer.JSONBackendLite} {
+		t.Run(string(backend), func(t *testing.T) {
+			if !serializer.JSONBackendAvailable(backend) {
+				t.Skipf("backend %q not compiled in", backend)
+			}
+			for _, escape := range []bool{true, false} {
+				s := serializer.NewJSONSerializer(maxBufferSize,
+					serializer.WithJSONBackend(backend), serializer.WithEscapeHTML(escape))
+				want := raw
+				if escape {
+					want = escaped
+				}
+
+				data, err := s.Serialize(value)
+				if err != nil {
+					t.Fatalf("Serialize failed: %v", err)
+				}
+				if string(data) != want {
+					t.Errorf("escape=%v: expected %q, got %q", escape, want, data)
+				}
+
+				var buf bytes.Buffer
+				if err := s.SerializeTo(&buf, value); err != nil {
+					t.Fatalf("SerializeTo failed: %v", err)
+				}
+				if buf.String() != want {
+					t.Errorf("escape=%v: SerializeTo expected %q, got %q", escape, want, buf.String())
+				}
+			}
+		})
+	}
+}
+
+func TestWithCaseSensitiveFields(t *testing.T) {
+	type user struct {
+		Name string `json:"name"`
+	}
+	input := []byte(`{"NAME":"alice"}`)
+
+	for _, backend := range []serializer.JSONBackend{serializer.JSONBackendJSONIter, serializer.JSONBackendV2} {
+		t.Run(string(backend), func(t *testing.T) {
+			if !serializer.JSONBackendAvailable(backend) {
+				t.Skipf("backend %q not compiled in", backend)
+			}
+
+			strict := serializer.NewJSONSerializer(maxBufferSize,
+				serializer.WithJSONBackend(backend), serializer.WithCaseSensitiveFields(true))
+			var u user
+			if err := strict.Deserialize(input, &u); err != nil {
+				t.Fatalf("Deserialize failed: %v", err)
+			}
+			if u.Name != "" {
+				t.Errorf("case-sensitive matching should ignore NAME, got %q", u.Name)
+			}
+			u = user{}
+			if err := strict.DeserializeFrom(bytes.NewReader(input), &u); err != nil {
+				t.Fatalf("DeserializeFrom failed: %v", err)
+			}
+			if u.Name != "" {
+				t.Errorf("case-sensitive matching should ignore NAME, got %q", u.Name)
+			}
+
+			lenient := serializer.NewJSONSerializer(maxBufferSize,
+				serializer.WithJSONBackend(backend), serializer.WithCaseSensitiveFields(false))
+			u = user{}
+			if err := lenient.Deserialize(input, &u); err != nil {
+				t.Fatalf("Deserialize failed: %v", err)
+			}
+			if u.Name != "alice" {
+				t.Errorf("case-insensitive matching should fill Name, got %q", u.Name)
+			}
+		})
+	}
+}
+
+func TestWithDurationFormat(t *testing.T) {
+	if !serializer.JSONBackendAvailable(serializer.JSONBackendJSONIter) {
+		t.Skip("jsoniter backend not compiled in")
+	}
+
+	type job struct {
+		Timeout time.Duration  `json:"timeout"`
+		Backoff *time.Duration `json:"backoff"`
+	}
+	backoff := 1500 * time.Millisecond
+	in := job{Timeout: 90 * time.Minute, Backoff: &backoff}
+
+	tests := []struct {
+		name   string
+		format serializer.DurationFormat
+		want   string
+	}{
+		{"nanoseconds", serializer.DurationNanoseconds, "{\"timeout\":5400000000000,\"backoff\":1500000000}\n"},
+		{"seconds", serializer.DurationSeconds, "{\"timeout\":5400,\"backoff\":1.5}\n"},
+		{"string", serializer.DurationString, "{\"timeout\":\"1h30m0s\",\"backoff\":\"1.5s\"}\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := serializer.NewJSONSerializer(maxBufferSize,
+				serializer.WithDurationFormat(tt.format), serializer.WithInt64AsString())
+			data, err := s.Serialize(in)
+			if err != nil {
+				t.Fatalf("Serialize failed: %v", err)
+			}
+			if string(data) != tt.want {
+				t.Errorf("expected %q, got %q", tt.want, data)
+			}
+
+			var out job
+			if err := s.Deserialize(data, &out); err != nil {
+				t.Fatalf("Deserialize failed: %v", err)
+			}
+			if out.Timeout != in.Timeout || out.Backoff == nil || *out.Backoff != backoff {
+				t.Errorf("expected %+v, got %+v", in, out)
+			}
+
+			// Duration strings are always accepted
+			out = job{}
+			if err := s.Deserialize([]byte(`{"timeout":"2m"}`), &out); err != nil {
+				t.Fatalf("Deserialize of string form failed: %v", err)
+			}
+			if out.Timeout != 2*time.Minute {
+				t.Errorf("expected 2m, got %v", out.Timeout)
+			}
+		})
+	}
+
+	s := serializer.NewJSONSerializer(maxBufferSize, serializer.WithDurationFormat(serializer.DurationString))
+	var out job
+	if err := s.Deserialize([]byte(`{"timeout":"soon"}`), &out); err == nil {
+		t.Error("expected error for invalid duration string")
+	}
+}
+
+func TestWithBytesFormat(t *testing.T) {
+	if !serializer.JSONBackendAvailable(serializer.JSONBackendJSONIter) {
+		t.Skip("jsoniter backend not compiled in")
+	}
+
+	type blob struct {
+		Data  []byte `json:"data"`
+		Empty []byte `json:"empty"`
+	}
+	in := blob{Data: []byte{0xde, 0xad, 0xbe, 0xef, 0x01}}
+
+	tests := []struct {
+		name   string
+		format serializer.BytesFormat
+		want   string
+	}{
+		{"base64", serializer.BytesBase64, "{\"data\":\"3q2+7wE=\",\"empty\":null}\n"},
+		{"hex", serializer.BytesHex, "{\"data\":\"deadbeef01\",\"empty\":null}\n"},
+		{"array", serializer.BytesArray, "{\"data\":[222,173,190,239,1],\"empty\":null}\n"},
+	}
+	inputs := []string{
+		`{"data":"3q2+7wE="}`,
+		`{"data":"deadbeef01"}`,
+		`{"data":[222,173,190,239,1]}`,
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := serializer.NewJSONSerializer(maxBufferSize, serializer.WithBytesFormat(tt.format))
+			data, err := s.Serialize(in)
+			if err != nil {
+				t.Fatalf("Serialize failed: %v", err)
+			}
+			if string(data) != tt.want {
+				t.Errorf("expected %q, got %q", tt.want, data)
+			}
+
+			for _, input := range inputs {
+				var out blob
+				if err := s.Deserialize([]byte(input), &out); err != nil {
+					t.Fatalf("Deserialize %s failed: %v", input, err)
+				}
+				if !bytes.Equal(out.Data, in.Data) {
+					t.Errorf("decoding %s: expected %x, got %x", input, in.Data, out.Data)
+				}
+			}
+		})
+	}
+
+	s := serializer.NewJSONSerializer(maxBufferSize, serializer.WithBytesFormat(serializer.BytesHex))
+	var out blob
+	if err := s.Deserialize([]byte(`{"data":"not bytes!"}`), &out); err == nil {
+		t.Error("expected error for undecodable string")
+	}
+}
+
+func TestWithOmitZero(t *testing.T) {
+	if !serializer.JSONBackendAvailable(serializer.JSONBackendJSONIter) {
+		t.Skip("jsoniter backend not compiled in")
+	}
+
+	type address struct {
+		City string `json:"city"`
+	}
+	type record struct {
+		ID       int               `json:"id"`
+		Name     string            `json:"name"`
+		Created  time.Time         `json:"created"`
+		Address  address           `json:"address"`
+		Tags     []string          `json:"tags"`
+		Labels   map[string]string `json:"labels"`
+		Parent   *address          `json:"parent"`
+		Internal string            `json:"-"`
+		Untagged int
+	}
+
+	s := serializer.NewJSONSerializer(maxBufferSize, serializer.WithOmitZero())
+
+	data, err := s.Serialize(record{ID: 1, Tags: []string{}, Internal: "secret"})
+	if err != nil {
+		t.Fatalf("Serialize failed: %v", err)
+	}
+	if want := "{\"id\":1,\"tags\":[]}\n"; string(data) != want {
+		t.Errorf("expected %q, got %q", want, data)
+	}
+
+	created := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	full := record{
+		ID:       2,
+		Name:     "n",
+		Created:  created,
+		Address:  address{City: "x"},
+		Parent:   &address{},
+		Untagged: 7,
+	}
+	data, err = s.Serialize(full)
+	if err != nil {
+		t.Fatalf("Serialize failed: %v", err)
+	}
+	want := "{\"id\":2,\"name\":\"n\",\"created\":\"2024-01-02T03:04:05Z\",\"address\":{\"city\":\"x\"},\"parent\":{},\"Untagged\":7}\n"
+	if string(data) != want {
+		t.Errorf("expected %q, got %q", want, data)
+	}
+
+	var out record
+	if err := s.Deserialize(data, &out); err != nil {
+		t.Fatalf("Deserialize failed: %v", err)
+	}
+	if out.ID != 2 || !out.Created.Equal(created) || out.Address.City != "x" || out.Untagged != 7 {
+		t.Errorf("unexpected round trip %+v", out)
+	}
+
+	// Serializers without the option keep zero fields
+	plain, err := serializer.NewJSONSerializer(maxBufferSize).Serialize(address{})
+	if err != nil {
+		t.Fatalf("Serialize failed: %v", err)
+	}
+	if want := "{\"city\":\"\"}\n"; string(plain) != want {
+		t.Errorf("expected %q, got %q", want, plain)
+	}
+}
+
+func TestWithOmitZeroInterfaces(t *testing.T) {
+	if !serializer.JSONBackendAvailable(serializer.JSONBackendJSONIter) {
+		t.Skip("jsoniter backend not compiled in")
+	}
+
+	type record struct {
+		Value any   `json:"value"`
+		Err   error `json:"err"`
+	}
+	s := serializer.NewJSONSerializer(maxBufferSize, serializer.WithOmitZero())
+
+	data, err := s.Serialize(record{})
+	if err != nil || string(data) != "{}\n" {
+		t.Errorf("nil interfaces = %q, %v", data, err)
+	}
+	// An interface holding a zero value is not nil, as with encoding/json
+	data, err = s.Serialize(record{Value: 0})
+	if err != nil || string(data) != "{\"value\":0}\n" {
+		t.Errorf("interface holding 0 = %q, %v", data, err)
+	}
+}
+
+func TestWithInvalidUTF8(t *testing.T) {
+	if !serializer.JSONBackendAvailable(serializer.JSONBackendJSONIter) {
+		t.Skip("jsoniter backend not compiled in")
+	}
+
+	value := map[string][]string{"k\xff": {"a\xfe\xfdb", "ok"}}
+
+	replace := serializer.NewJSONSerializer(maxBufferSize, serializer.WithInvalidUTF8(serializer.InvalidUTF8Replace))
+	data, err := replace.Serialize(value)
+	if err != nil {
+		t.Fatalf("Serialize failed: %v", err)
+	}
+	if want := "{\"k\\ufffd\":[\"a\\ufffd\\ufffdb\",\"ok\"]}\n"; string(data) != want {
+		t.Errorf("expected %q, got %q", want, data)
+	}
+	var buf bytes.Buffer
+	if err := replace.SerializeTo(&bufferWriter{&buf}, value); err != nil {
+		t.Fatalf("SerializeTo failed: %v", err)
+	}
+	if !bytes.Equal(buf.Bytes(), data) {
+		t.Errorf("SerializeTo wrote %q, Serialize returned %q", buf.Bytes(), data)
+	}
+
+	reject := serializer.NewJSONSerializer(maxBufferSize, serializer.WithInvalidUTF8(serializer.InvalidUTF8Reject))
+	if _, err := reject.Serialize(value); !errors.Is(err, serializer.ErrInvalidUTF8) {
+		t.Errorf("expected ErrInvalidUTF8, got %v", err)
+	}
+	buf.Reset()
+	if err := reject.SerializeTo(&buf, value); !errors.Is(err, serializer.ErrInvalidUTF8) || buf.Len() != 0 {
+		t.Errorf("expected ErrInvalidUTF8 and no output, got %v and %q", err, buf.Bytes())
+	}
+	if _, err := reject.Serialize(map[string]string{"emoji": "\U0001F600"}); err != nil {
+		t.Errorf("valid UTF-8 must be accepted: %v", err)
+	}
+
+	pass := serializer.NewJSONSerializer(maxBufferSize, serializer.WithInvalidUTF8(serializer.InvalidUTF8PassThrough))
+	data, err = pass.Serialize(value)
+	if err != nil {
+		t.Fatalf("Serialize failed: %v", err)
+	}
+	if !bytes.Contains(data, []byte("a\xfe\xfdb")) {
+		t.Errorf("expected raw bytes to pass through, got %q", data)
+	}
+}
+
+// bufferWriter hides the *bytes.Buffer type from the serializer
+type bufferWriter struct {
+	buf *bytes.Buffer
+}
+
+func (w *bufferWriter) Write(p []byte) (int, error) {
+	return w.buf.Write(p)
+}
+
+func TestWithTrailingNewline(t *testing.T) {
+	value := map[string]int{"a": 1}
+
+	for _, backend := range []serializer.JSONBackend{serializer.JSONBackendJSONIter, serializer.JSONBackendStdlib, serializer.JSONBackendV2, serializer.JSONBackendLite} {
+		t.Run(string(backend), func(t *testing.T) {
+			if !serializer.JSONBackendAvailable(backend) {
+				t.Skipf("backend %q not compiled in", backend)
+			}
+			s := serializer.NewJSONSerializer(maxBufferSize, serializer.WithJSONBackend(backend), serializer.WithTrailingNewline(false))
+
+			data, err := s.Serialize(value)
+			if err != nil {
+				t.Fatalf("Serialize failed: %v", err)
+			}
+			if want := `{"a":1}`; string(data) != want {
+				t.Errorf("expected %q, got %q", want, data)
+			}
+
+			var buf bytes.Buffer
+			if err := s.SerializeTo(&bufferWriter{&buf}, value); err != nil {
+				t.Fatalf("SerializeTo failed: %v", err)
+			}
+			buf.WriteString("|")
+			if err := s.SerializeTo(&buf, value); err != nil {
+				t.Fatalf("SerializeTo failed: %v", err)
+			}
+			if want := `{"a":1}|{"a":1}`; buf.String() != want {
+				t.Errorf("expected %q, got %q", want, buf.String())
+			}
+
+			indented, err := s.(serializer.IndentSerializer).SerializeIndent(value, "", "  ")
+			if err != nil {
+				t.Fatalf("SerializeIndent failed: %v", err)
+			}
+			if want := "{\n  \"a\": 1\n}"; string(indented) != want {
+				t.Errorf("expected %q, got %q", want, indented)
+			}
+
+			var out map[string]int
+			if err := s.Deserialize(data, &out); err != nil || out["a"] != 1 {
+				t.Errorf("round trip failed: %v, %v", out, err)
+			}
+		})
+	}
+
+	data, err := serializer.NewJSONSerializer(maxBufferSize, serializer.WithTrailingNewline(true)).Serialize(value)
+	if err != nil {
+		t.Fatalf("Serialize failed: %v", err)
+	}
+	if want := "{\"a\":1}\n"; string(data) != want {
+		t.Errorf("expected %q, got %q", want, data)
+	}
+}
+
+type shape interface {
+	Area() float64
+}
+
+type circle struct {
+	Type   string  `json:"type"`
+	Radius float64 `json:"radius"`
+}
+
+func (c circle) Area() float64 { return 3 * c.Radius * c.Radius }
+
+type square struct {
+	Type string  `json:"type"`
+	Side float64 `json:"side"`
+}
+
+func (s *square) Area() float64 { return s.Side * s.Side }
+
+func TestWithDiscriminator(t *testing.T) {
+	if !serializer.JSONBackendAvailable(serializer.JSONBackendJSONIter) {
+		t.Skip("jsoniter backend not compiled in")
+	}
+
+	types := serializer.NewTypeRegistry()
+	types.MustRegister("circle", circle{})
+	types.MustRegister("square", square{})
+	s := serializer.NewJSONSerializer(maxBufferSize, serializer.WithDiscriminator((*shape)(nil), "type", types))
+
+	type drawing struct {
+		Main   shape   `json:"main"`
+		Shapes []shape `json:"shapes"`
+		None   shape   `json:"none"`
+	}
+	input := `{"main":{"radius":2,"type":"circle"},"shapes":[{"type":"square","side":3},{"type":"circle","radius":1}],"none":null}`
+
+	var d drawing
+	if err := s.Deserialize([]byte(input), &d); err != nil {
+		t.Fatalf("Deserialize failed: %v", err)
+	}
+	if c, ok := d.Main.(circle); !ok || c.Radius != 2 {
+		t.Errorf("expected circle with radius 2, got %#v", d.Main)
+	}
+	if len(d.Shapes) != 2 {
+		t.Fatalf("expected 2 shapes, got %d", len(d.Shapes))
+	}
+	if sq, ok := d.Shapes[0].(*square); !ok || sq.Side != 3 || sq.Type != "square" {
+		t.Errorf("expected *square with side 3, got %#v", d.Shapes[0])
+	}
+	if d.None != nil {
+		t.Errorf("expected nil for null, got %#v", d.None)
+	}
+
+	var top shape
+	if err := s.Deserialize([]byte(`{"type":"circle","radius":1}`), &top); err != nil {
+		t.Fatalf("Deserialize top-level failed: %v", err)
+	}
+	if _, ok := top.(circle); !ok {
+		t.Errorf("expected circle, got %#v", top)
+	}
+
+	// Encoding keeps the concrete type's own fields, so output round-trips
+	data, err := s.Serialize(d)
+	if err != nil {
+		t.Fatalf("Serialize failed: %v", err)
+	}
+	var again drawing
+	if err := s.Deserialize(data, &again); err != nil {
+		t.Fatalf("round trip failed: %v", err)
+	}
+	if again.Shapes[0].Area() != 9 {
+		t.Errorf("unexpected round trip %#v", again)
+	}
+
+	if err := s.Deserialize([]byte(`{"type":"hexagon"}`), &top); !errors.Is(err, serializer.ErrUnknownType) {
+		t.Errorf("expected ErrUnknownType, got %v", err)
+	}
+	if err := s.Deserialize([]byte(`{"main":{"type":"hexagon"}}`), &d); err == nil || !strings.Contains(err.Error(), `"hexagon"`) {
+		t.Errorf("expected unknown type error naming the type, got %v", err)
+	}
+	if err := s.Deserialize([]byte(`{"main":{"radius":1}}`), &d); err == nil || !strings.Contains(err.Error(), `missing discriminator "type"`) {
+		t.Errorf("expected missing discriminator error, got %v", err)
+	}
+	if err := s.Deserialize([]byte(`{"main":[1]}`), &d); err == nil {
+		t.Error("expected error for a non-object value")
+	}
+}
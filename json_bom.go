@@ -0,0 +1,75 @@
+package serializer
+
+import (
+	"bytes"
+	"errors"
+	"io"
+)
+
+// ErrUTF16Input is returned when JSON input is UTF-16 (or UTF-32) encoded rather than UTF-8
+var ErrUTF16Input = errors.New("JSON input is UTF-16 encoded; only UTF-8 is supported")
+
+var utf8BOM = []byte{0xEF, 0xBB, 0xBF}
+
+// stripBOM drops a leading UTF-8 byte order mark, as written by many Windows tools.
+// UTF-16 input is recognised by its byte order mark or, failing that, by a NUL in
+// the first two bytes, which RFC 8259 notes can never start UTF-8 JSON.
+func stripBOM(data []byte) ([]byte, error) {
+	switch {
+	case bytes.HasPrefix(data, utf8BOM):
+		return data[len(utf8BOM):], nil
+	case len(data) >= 2 && (data[0] == 0xFE && data[1] == 0xFF || data[0] == 0xFF && data[1] == 0xFE):
+		return nil, ErrUTF16Input
+	case len(data) >= 2 && (data[0] == 0 || data[1] == 0):
+		return nil, ErrUTF16Input
+	}
+	return data, nil
+}
+
+// bomUndecided reports whether head is too short to tell how the input is encoded
+func bomUndecided(head []byte) bool {
+	switch {
+	case len(head) == 0:
+		return true
+	case head[0] == 0xEF:
+		return len(head) < len(utf8BOM) && bytes.HasPrefix(utf8BOM, head)
+	case head[0] == 0xFE || head[0] == 0xFF || head[0] == 0:
+		return len(head) < 2
+	}
+	return false
+}
+
+// bomReader applies stripBOM to the start of a stream
+type bomReader struct {
+	r       io.Reader
+	checked bool
+	head    []byte
+	err     error
+}
+
+func (br *bomReader) Read(p []byte) (int, error) {
+	if !br.checked {
+		br.checked = true
+		// Read no further than needed, so short values on a live stream don't block
+		var head [3]byte
+		var n int
+		var err error
+		for err == nil && bomUndecided(head[:n]) {
+			var m int
+			m, err = br.r.Read(head[n:])
+			n += m
+		}
+		if br.head, br.err = stripBOM(head[:n]); br.err == nil {
+			br.err = err
+		}
+	}
+	if len(br.head) > 0 {
+		n := copy(p, br.head)
+		br.head = br.head[n:]
+		return n, nil
+	}
+	if br.err != nil {
+		return 0, br.err
+	}
+	return br.r.Read(p)
+}
@@ -0,0 +1,67 @@
+package serializer
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func TestJSONSkipsUTF8BOM(t *testing.T) {
+	input := "\xEF\xBB\xBF{\"name\":\"excel\"}"
+	s := NewJSONSerializer(maxBufferSize)
+
+	var got struct{ Name string }
+	if err := s.Deserialize([]byte(input), &got); err != nil || got.Name != "excel" {
+		t.Errorf("Deserialize: got %+v, %v", got, err)
+	}
+
+	got.Name = ""
+	if err := s.(StringDeserializer).DeserializeString(input, &got); err != nil || got.Name != "excel" {
+		t.Errorf("DeserializeString: got %+v, %v", got, err)
+	}
+
+	got.Name = ""
+	if err := s.DeserializeFrom(iotest.OneByteReader(strings.NewReader(input)), &got); err != nil || got.Name != "excel" {
+		t.Errorf("DeserializeFrom: got %+v, %v", got, err)
+	}
+
+	var n []int
+	if err := s.(*JSONSerializer).DecodeArrayStream(strings.NewReader("\xEF\xBB\xBF[1,2]"), func(e Element) error {
+		var v int
+		err := e.Decode(&v)
+		n = append(n, v)
+		return err
+	}); err != nil || len(n) != 2 {
+		t.Errorf("DecodeArrayStream: got %v, %v", n, err)
+	}
+}
+
+func TestJSONShortStreamValues(t *testing.T) {
+	s := NewJSONSerializer(maxBufferSize)
+	for _, input := range []string{"1", "\xEF\xBB\xBF1", "[]"} {
+		var v any
+		if err := s.DeserializeFrom(iotest.OneByteReader(strings.NewReader(input)), &v); err != nil {
+			t.Errorf("%q: unexpected error %v", input, err)
+		}
+	}
+}
+
+func TestJSONRejectsUTF16(t *testing.T) {
+	s := NewJSONSerializer(maxBufferSize)
+	inputs := map[string]string{
+		"utf16le bom": "\xFF\xFE{\x00}\x00",
+		"utf16be bom": "\xFE\xFF\x00{\x00}",
+		"utf16le":     "{\x00}\x00",
+		"utf16be":     "\x00{\x00}",
+	}
+	for name, input := range inputs {
+		var v any
+		if err := s.Deserialize([]byte(input), &v); !errors.Is(err, ErrUTF16Input) {
+			t.Errorf("%s: Deserialize expected ErrUTF16Input, got %v", name, err)
+		}
+		if err := s.DeserializeFrom(strings.NewReader(input), &v); !errors.Is(err, ErrUTF16Input) {
+			t.Errorf("%s: DeserializeFrom expected ErrUTF16Input, got %v", name, err)
+		}
+	}
+}
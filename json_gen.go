@@ -0,0 +1,146 @@
+package serializer
+
+import (
+	"bytes"
+	stdjson "encoding/json"
+	"io"
+	"reflect"
+)
+
+// GeneratedJSONEncoder is implemented by types with JSON encoders written by
+// serializer-gen (see cmd/serializer-gen). The JSON serializer prefers it over
+// reflection when it formats like encoding/json (the stdlib and lite backends)
+// and its options leave the encoded form unchanged.
+type GeneratedJSONEncoder interface {
+	// AppendJSON appends the JSON encoding of the value to dst
+	AppendJSON(dst []byte) ([]byte, error)
+}
+
+// GeneratedJSONDecoder is implemented by pointers to types with JSON decoders
+// written by serializer-gen
+type GeneratedJSONDecoder interface {
+	// ReadJSON decodes the next value from r into the receiver
+	ReadJSON(r *JSONReader) error
+}
+
+// generatedJSONEngine hands values with generated codecs to them and everything
+// else, including streams, to its engine
+type generatedJSONEngine struct {
+	jsonEngine
+}
+
+// withGeneratedJSON wraps engine unless options change what generated codecs would
+// write or accept. Generated codecs format like encoding/json, so backends that
+// format differently, such as jsoniter with its float precision and U+2028
+// handling, keep their reflection output.
+func withGeneratedJSON(engine jsonEngine, backend JSONBackend, o *options) jsonEngine {
+	if backend != JSONBackendStdlib && backend != JSONBackendLite {
+		return engine
+	}
+	if o.jsonEscapeHTML || o.jsonInt64AsString || o.jsonDurationFormat != nil || o.jsonBytesFormat != nil ||
+		o.jsonOmitZero || len(o.jsonDiscriminators) > 0 || o.jsonCaseSensitive != nil || o.jsonInvalidUTF8 != nil ||
+		o.omitsEmpty(false) || o.jsonTimeLayout != "" || o.canonical || o.jsonTagKey != "" {
+		return engine
+	}
+	return generatedJSONEngine{engine}
+}
+
+func (e generatedJSONEngine) encode(w io.Writer, v any) error {
+	g, ok := v.(GeneratedJSONEncoder)
+	if !ok || isNilPointer(v) {
+		return e.jsonEngine.encode(w, v)
+	}
+	return encodeBuffered(w, func(buf *bytes.Buffer) error {
+		b, err := g.AppendJSON(buf.AvailableBuffer())
+		if err != nil {
+			return err
+		}
+		buf.Write(b)
+		return buf.WriteByte('\n')
+	})
+}
+
+func (e generatedJSONEngine) unmarshal(data []byte, v any) error {
+	g, ok := v.(GeneratedJSONDecoder)
+	if !ok || isNilPointer(v) {
+		return e.jsonEngine.unmarshal(data, v)
+	}
+	r := NewJSONReader(data)
+	if err := g.ReadJSON(r); err != nil {
+		return err
+	}
+	return r.End()
+}
+
+// isNilPointer reports whether v is a nil pointer, whose value methods cannot be called
+func isNilPointer(v any) bool {
+	rv := reflect.ValueOf(v)
+	return rv.Kind() == reflect.Ptr && rv.IsNil()
+}
+
+// AppendJSONString appends s quoted as encoding/json writes it without HTML escaping.
+// It is used by generated code.
+func AppendJSONString(dst []byte, s string) []byte {
+	return appendJSONString(dst, s, false)
+}
+
+// AppendJSONFloat appends f formatted as encoding/json formats a float of the given
+// bit size. NaN and infinities are an error. It is used by generated code.
+func AppendJSONFloat(dst []byte, f float64, bits int) ([]byte, error) {
+	return appendJSONFloat(dst, f, bits)
+}
+
+// AppendJSONValue appends v encoded by encoding/json without HTML escaping.
+// Generated code uses it for types it has no encoder for, passing a pointer to
+// the field so that MarshalJSON methods on pointer receivers apply.
+func AppendJSONValue(dst []byte, v any) ([]byte, error) {
+	buf := bytes.NewBuffer(dst)
+	enc := stdjson.NewEncoder(buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		return dst, err
+	}
+	return buf.Bytes()[:buf.Len()-1], nil
+}
+
+// IsEmptyJSONValue reports whether encoding/json omits the field ptr points to
+// when it is tagged omitempty: false, 0, nil pointers and interfaces, and empty
+// strings, arrays, slices and maps. Generated code uses it for types it has no
+// encoder for.
+func IsEmptyJSONValue(ptr any) bool {
+	v := reflect.ValueOf(ptr).Elem()
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64, reflect.Interface, reflect.Ptr:
+		return v.IsZero()
+	}
+	return false
+}
+
+// isZeroer is implemented by types such as time.Time whose zero-ness is not
+// their memory representation
+type isZeroer interface {
+	IsZero() bool
+}
+
+var isZeroerType = reflect.TypeOf((*isZeroer)(nil)).Elem()
+
+// IsZeroJSONValue reports whether encoding/json omits the field ptr points to
+// when it is tagged omitzero: the field holds its type's zero value, or its IsZero
+// method reports true. Generated code uses it for types it has no encoder for.
+func IsZeroJSONValue(ptr any) bool {
+	v := reflect.ValueOf(ptr).Elem()
+	switch {
+	case v.Type().Implements(isZeroerType):
+		if (v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface) && v.IsNil() {
+			return true
+		}
+		return v.Interface().(isZeroer).IsZero()
+	case reflect.PointerTo(v.Type()).Implements(isZeroerType):
+		return v.Addr().Interface().(isZeroer).IsZero()
+	}
+	return v.IsZero()
+}
@@ -0,0 +1,99 @@
+//go:build !nojsoniter && !tinygo && !nounsafe
+
+package serializer
+
+import (
+	"io"
+	"sync"
+
+	jsoniter "github.com/json-iterator/go"
+)
+
+var json = jsoniter.ConfigFastest
+
+const defaultJSONBackend = JSONBackendJSONIter
+
+var taggedJSONEngines = map[JSONBackend]func(o *options) jsonEngine{
+	JSONBackendJSONIter: newJSONIterEngine,
+}
+
+// config returns the settings behind the predefined json-iterator API
+func (c JSONConfig) config() jsoniter.Config {
+	switch c {
+	case JSONConfigDefault:
+		return jsoniter.Config{EscapeHTML: true}
+	case JSONConfigCompatible:
+		return jsoniter.Config{EscapeHTML: true, SortMapKeys: true, ValidateJsonRawMessage: true}
+	default:
+		return jsoniter.Config{EscapeHTML: false, MarshalFloatWith6Digits: true, ObjectFieldMustBeSimpleString: true}
+	}
+}
+
+// jsoniterAPIs shares one frozen API (and its codec cache) per extension-free config
+var jsoniterAPIs = struct {
+	sync.Mutex
+	m map[jsoniter.Config]jsoniter.API
+}{m: map[jsoniter.Config]jsoniter.API{
+	JSONConfigFastest.config(): json,
+}}
+
+// sharedJSONIterAPI returns the shared API for cfg, freezing it on first use
+func sharedJSONIterAPI(cfg jsoniter.Config) jsoniter.API {
+	jsoniterAPIs.Lock()
+	defer jsoniterAPIs.Unlock()
+	api, ok := jsoniterAPIs.m[cfg]
+	if !ok {
+		api = cfg.Froze()
+		jsoniterAPIs.m[cfg] = api
+	}
+	return api
+}
+
+type jsoniterEngine struct {
+	api jsoniter.API
+}
+
+// newJSONIterEngine uses a shared API unless options require extensions, which
+// get a private API so they never leak into other serializers.
+// Every setting is baked into the frozen config: jsoniter's Encoder.SetEscapeHTML
+// re-freezes through a global cache that would carry our extensions along.
+func newJSONIterEngine(o *options) jsonEngine {
+	cfg := o.jsonConfig.config()
+	cfg.EscapeHTML = o.jsonEscapeHTML
+	if o.jsonCaseSensitive != nil {
+		cfg.CaseSensitive = *o.jsonCaseSensitive
+	}
+	if o.sortMapKeys {
+		cfg.SortMapKeys = true
+	}
+	if o.jsonTagKey != "" {
+		cfg.TagKey = o.jsonTagKey
+	}
+	if o.canonical {
+		// Its float encoders would take precedence over canonicalFloatExtension
+		cfg.MarshalFloatWith6Digits = false
+	}
+
+	var api jsoniter.API
+	if extensions := jsoniterExtensions(o); len(extensions) == 0 {
+		api = sharedJSONIterAPI(cfg)
+	} else {
+		api = cfg.Froze()
+		for _, ext := range extensions {
+			api.RegisterExtension(ext)
+		}
+	}
+	return withInvalidUTF8(jsoniterEngine{api: api}, o)
+}
+
+func (e jsoniterEngine) encode(w io.Writer, v any) error {
+	return e.api.NewEncoder(w).Encode(v)
+}
+
+func (e jsoniterEngine) unmarshal(data []byte, v any) error {
+	return e.api.Unmarshal(data, v)
+}
+
+func (e jsoniterEngine) decode(r io.Reader, v any) error {
+	return e.api.NewDecoder(r).Decode(v)
+}
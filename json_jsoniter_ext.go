@@ -0,0 +1,622 @@
+//go:build !nojsoniter && !tinygo && !nounsafe
+
+package serializer
+
+import (
+	"encoding"
+	"encoding/base64"
+	"encoding/hex"
+	stdjson "encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unsafe"
+
+	jsoniter "github.com/json-iterator/go"
+	"github.com/modern-go/reflect2"
+)
+
+var (
+	jsonMarshalerType   = reflect.TypeOf((*stdjson.Marshaler)(nil)).Elem()
+	jsonUnmarshalerType = reflect.TypeOf((*stdjson.Unmarshaler)(nil)).Elem()
+	textMarshalerType   = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+	durationType        = reflect.TypeOf(time.Duration(0))
+	timeType            = reflect.TypeOf(time.Time{})
+)
+
+// jsoniterExtensions returns the per-instance extensions required by o
+func jsoniterExtensions(o *options) []jsoniter.Extension {
+	var extensions []jsoniter.Extension
+	// time.Duration is an int64, so its extension must be consulted first
+	if o.jsonDurationFormat != nil {
+		extensions = append(extensions, &durationExtension{format: *o.jsonDurationFormat})
+	}
+	if o.jsonBytesFormat != nil {
+		extensions = append(extensions, &bytesExtension{format: *o.jsonBytesFormat})
+	}
+	tagKey := "json"
+	if o.jsonTagKey != "" {
+		tagKey = o.jsonTagKey
+	}
+	// Runs before the omitempty extension so it sees the unwrapped field codecs
+	if o.jsonInt64AsString {
+		extensions = append(extensions, &int64AsStringExtension{tagKey: tagKey})
+	}
+	switch {
+	case o.jsonOmitZero && o.omitsEmpty(true):
+		extensions = append(extensions, &omitZeroExtension{tagKey: tagKey})
+	case o.omitsEmpty(false):
+		extensions = append(extensions, &omitZeroExtension{tagKey: tagKey, keepEmptiness: true})
+	}
+	if o.jsonTimeLayout != "" {
+		extensions = append(extensions, &timeLayoutExtension{layout: o.jsonTimeLayout})
+	}
+	if o.canonical {
+		extensions = append(extensions, &canonicalFloatExtension{})
+	}
+	if len(o.jsonDiscriminators) > 0 {
+		ext := &discriminatorExtension{byType: make(map[reflect.Type]jsonDiscriminator)}
+		for _, d := range o.jsonDiscriminators {
+			ext.byType[d.iface] = d
+		}
+		extensions = append(extensions, ext)
+	}
+	return extensions
+}
+
+// hasCustomEncoding reports whether typ (or *typ) marshals itself, in which case
+// extensions leave it alone
+func hasCustomEncoding(typ reflect.Type) bool {
+	ptr := reflect.PointerTo(typ)
+	return typ.Implements(jsonMarshalerType) || typ.Implements(textMarshalerType) ||
+		ptr.Implements(jsonMarshalerType) || ptr.Implements(textMarshalerType)
+}
+
+// hasCustomDecoding reports whether *typ unmarshals itself
+func hasCustomDecoding(typ reflect.Type) bool {
+	ptr := reflect.PointerTo(typ)
+	return ptr.Implements(jsonUnmarshalerType) || ptr.Implements(textUnmarshalerType)
+}
+
+// int64AsStringExtension encodes int64 and uint64 values as JSON strings and
+// accepts either strings or numbers when decoding them. int and uint are
+// included where they are 64 bits wide.
+type int64AsStringExtension struct {
+	jsoniter.DummyExtension
+	tagKey string
+}
+
+func (e *int64AsStringExtension) CreateEncoder(typ reflect2.Type) jsoniter.ValEncoder {
+	if hasCustomEncoding(typ.Type1()) {
+		return nil
+	}
+	return int64StringCodecFor(typ.Kind())
+}
+
+func (e *int64AsStringExtension) CreateDecoder(typ reflect2.Type) jsoniter.ValDecoder {
+	if hasCustomDecoding(typ.Type1()) {
+		return nil
+	}
+	return int64StringCodecFor(typ.Kind())
+}
+
+// UpdateStructDescriptor leaves fields tagged ",string" to jsoniter, which quotes
+// them itself and would otherwise quote the value a second time. Decoding needs
+// no change, as jsoniter strips the quotes before the codec reads the number.
+func (e *int64AsStringExtension) UpdateStructDescriptor(desc *jsoniter.StructDescriptor) {
+	for _, binding := range desc.Fields {
+		_, opts, _ := strings.Cut(binding.Field.Tag().Get(e.tagKey), ",")
+		if slices.Contains(strings.Split(opts, ","), "string") {
+			binding.Encoder = bareInt64Encoder(binding.Encoder)
+		}
+	}
+}
+
+// bareInt64Encoder returns enc writing bare digits if it is one of the string codecs
+func bareInt64Encoder(enc jsoniter.ValEncoder) jsoniter.ValEncoder {
+	switch enc := enc.(type) {
+	case int64StringCodec:
+		return int64StringCodec{bare: true}
+	case uint64StringCodec:
+		return uint64StringCodec{bare: true}
+	case *jsoniter.OptionalEncoder:
+		return &jsoniter.OptionalEncoder{ValueEncoder: bareInt64Encoder(enc.ValueEncoder)}
+	}
+	return enc
+}
+
+// int64StringCodecFor returns the string codec for values of kind, or nil if they
+// are written as numbers
+func int64StringCodecFor(kind reflect.Kind) interface {
+	jsoniter.ValEncoder
+	jsoniter.ValDecoder
+} {
+	switch {
+	case kind == reflect.Int64, kind == reflect.Int && strconv.IntSize == 64:
+		return int64StringCodec{}
+	case kind == reflect.Uint64, kind == reflect.Uint && strconv.IntSize == 64:
+		return uint64StringCodec{}
+	}
+	return nil
+}
+
+type int64StringCodec struct {
+	// bare writes the digits without quotes, for fields jsoniter quotes itself
+	bare bool
+}
+
+func (int64StringCodec) IsEmpty(ptr unsafe.Pointer) bool {
+	return *(*int64)(ptr) == 0
+}
+
+func (c int64StringCodec) Encode(ptr unsafe.Pointer, stream *jsoniter.Stream) {
+	if c.bare {
+		stream.WriteInt64(*(*int64)(ptr))
+		return
+	}
+	stream.WriteRaw(`"` + strconv.FormatInt(*(*int64)(ptr), 10) + `"`)
+}
+
+func (int64StringCodec) Decode(ptr unsafe.Pointer, iter *jsoniter.Iterator) {
+	switch iter.WhatIsNext() {
+	case jsoniter.StringValue:
+		s := iter.ReadString()
+		n, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			iter.ReportError("decode int64", "invalid integer string "+strconv.Quote(s))
+			return
+		}
+		*(*int64)(ptr) = n
+	case jsoniter.NilValue:
+		iter.Skip()
+	default:
+		*(*int64)(ptr) = iter.ReadInt64()
+	}
+}
+
+type uint64StringCodec struct {
+	bare bool
+}
+
+func (uint64StringCodec) IsEmpty(ptr unsafe.Pointer) bool {
+	return *(*uint64)(ptr) == 0
+}
+
+func (c uint64StringCodec) Encode(ptr unsafe.Pointer, stream *jsoniter.Stream) {
+	if c.bare {
+		stream.WriteUint64(*(*uint64)(ptr))
+		return
+	}
+	stream.WriteRaw(`"` + strconv.FormatUint(*(*uint64)(ptr), 10) + `"`)
+}
+
+func (uint64StringCodec) Decode(ptr unsafe.Pointer, iter *jsoniter.Iterator) {
+	switch iter.WhatIsNext() {
+	case jsoniter.StringValue:
+		s := iter.ReadString()
+		n, err := strconv.ParseUint(s, 10, 64)
+		if err != nil {
+			iter.ReportError("decode uint64", "invalid integer string "+strconv.Quote(s))
+			return
+		}
+		*(*uint64)(ptr) = n
+	case jsoniter.NilValue:
+		iter.Skip()
+	default:
+		*(*uint64)(ptr) = iter.ReadUint64()
+	}
+}
+
+// durationExtension encodes time.Duration as seconds or as a duration string
+type durationExtension struct {
+	jsoniter.DummyExtension
+	format DurationFormat
+}
+
+func (e *durationExtension) CreateEncoder(typ reflect2.Type) jsoniter.ValEncoder {
+	if typ.Type1() != durationType {
+		return nil
+	}
+	return durationCodec{format: e.format}
+}
+
+func (e *durationExtension) CreateDecoder(typ reflect2.Type) jsoniter.ValDecoder {
+	if typ.Type1() != durationType {
+		return nil
+	}
+	return durationCodec{format: e.format}
+}
+
+type durationCodec struct {
+	format DurationFormat
+}
+
+func (c durationCodec) IsEmpty(ptr unsafe.Pointer) bool {
+	return *(*time.Duration)(ptr) == 0
+}
+
+func (c durationCodec) Encode(ptr unsafe.Pointer, stream *jsoniter.Stream) {
+	d := *(*time.Duration)(ptr)
+	switch c.format {
+	case DurationSeconds:
+		stream.WriteFloat64(d.Seconds())
+	case DurationString:
+		stream.WriteString(d.String())
+	default:
+		stream.WriteInt64(int64(d))
+	}
+}
+
+func (c durationCodec) Decode(ptr unsafe.Pointer, iter *jsoniter.Iterator) {
+	switch iter.WhatIsNext() {
+	case jsoniter.StringValue:
+		s := iter.ReadString()
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			iter.ReportError("decode duration", "invalid duration "+strconv.Quote(s))
+			return
+		}
+		*(*time.Duration)(ptr) = d
+	case jsoniter.NilValue:
+		iter.Skip()
+	default:
+		if c.format == DurationSeconds {
+			*(*time.Duration)(ptr) = time.Duration(iter.ReadFloat64() * float64(time.Second))
+			return
+		}
+		*(*time.Duration)(ptr) = time.Duration(iter.ReadInt64())
+	}
+}
+
+// bytesExtension encodes []byte (and named byte slices) as base64, hex or a
+// number array and accepts any of them when decoding
+type bytesExtension struct {
+	jsoniter.DummyExtension
+	format BytesFormat
+}
+
+func isByteSlice(typ reflect.Type) bool {
+	return typ.Kind() == reflect.Slice && typ.Elem().Kind() == reflect.Uint8
+}
+
+func (e *bytesExtension) CreateEncoder(typ reflect2.Type) jsoniter.ValEncoder {
+	if !isByteSlice(typ.Type1()) || hasCustomEncoding(typ.Type1()) {
+		return nil
+	}
+	return bytesCodec{format: e.format}
+}
+
+func (e *bytesExtension) CreateDecoder(typ reflect2.Type) jsoniter.ValDecoder {
+	if !isByteSlice(typ.Type1()) || hasCustomDecoding(typ.Type1()) {
+		return nil
+	}
+	return bytesCodec{format: e.format}
+}
+
+type bytesCodec struct {
+	format BytesFormat
+}
+
+func (c bytesCodec) IsEmpty(ptr unsafe.Pointer) bool {
+	return len(*(*[]byte)(ptr)) == 0
+}
+
+func (c bytesCodec) Encode(ptr unsafe.Pointer, stream *jsoniter.Stream) {
+	b := *(*[]byte)(ptr)
+	if b == nil {
+		stream.WriteNil()
+		return
+	}
+	switch c.format {
+	case BytesHex:
+		stream.WriteString(hex.EncodeToString(b))
+	case BytesArray:
+		stream.WriteArrayStart()
+		for i, v := range b {
+			if i > 0 {
+				stream.WriteMore()
+			}
+			stream.WriteUint8(v)
+		}
+		stream.WriteArrayEnd()
+	default:
+		stream.WriteString(base64.StdEncoding.EncodeToString(b))
+	}
+}
+
+func (c bytesCodec) Decode(ptr unsafe.Pointer, iter *jsoniter.Iterator) {
+	switch iter.WhatIsNext() {
+	case jsoniter.NilValue:
+		iter.Skip()
+		*(*[]byte)(ptr) = nil
+	case jsoniter.ArrayValue:
+		b := []byte{}
+		iter.ReadArrayCB(func(iter *jsoniter.Iterator) bool {
+			b = append(b, iter.ReadUint8())
+			return iter.Error == nil
+		})
+		*(*[]byte)(ptr) = b
+	case jsoniter.StringValue:
+		s := iter.ReadString()
+		b, err := c.decodeString(s)
+		if err != nil {
+			iter.ReportError("decode bytes", "string is neither base64 nor hex")
+			return
+		}
+		*(*[]byte)(ptr) = b
+	default:
+		iter.ReportError("decode bytes", "expected string, array or null")
+	}
+}
+
+// decodeString tries the configured string encoding before the other one
+func (c bytesCodec) decodeString(s string) ([]byte, error) {
+	if c.format == BytesHex {
+		if b, err := hex.DecodeString(s); err == nil {
+			return b, nil
+		}
+		return base64.StdEncoding.DecodeString(s)
+	}
+	if b, err := base64.StdEncoding.DecodeString(s); err == nil {
+		return b, nil
+	}
+	return hex.DecodeString(s)
+}
+
+// canonicalFloatExtension writes floats in their shortest round-tripping form, as
+// encoding/json does, and negative zero as 0
+type canonicalFloatExtension struct {
+	jsoniter.DummyExtension
+}
+
+func (e *canonicalFloatExtension) CreateEncoder(typ reflect2.Type) jsoniter.ValEncoder {
+	kind := typ.Kind()
+	if kind != reflect.Float32 && kind != reflect.Float64 || hasCustomEncoding(typ.Type1()) {
+		return nil
+	}
+	return canonicalFloatEncoder{bits: typ.Type1().Bits()}
+}
+
+type canonicalFloatEncoder struct {
+	bits int
+}
+
+func (e canonicalFloatEncoder) IsEmpty(ptr unsafe.Pointer) bool {
+	return e.float(ptr) == 0
+}
+
+func (e canonicalFloatEncoder) Encode(ptr unsafe.Pointer, stream *jsoniter.Stream) {
+	f := e.float(ptr)
+	if f == 0 {
+		f = 0 // drop the sign of negative zero
+	}
+	b, err := appendJSONFloat(stream.Buffer(), f, e.bits)
+	if err != nil {
+		stream.Error = err
+		return
+	}
+	stream.SetBuffer(b)
+}
+
+func (e canonicalFloatEncoder) float(ptr unsafe.Pointer) float64 {
+	if e.bits == 32 {
+		return float64(*(*float32)(ptr))
+	}
+	return *(*float64)(ptr)
+}
+
+// timeLayoutExtension encodes time.Time as a string formatted with layout
+type timeLayoutExtension struct {
+	jsoniter.DummyExtension
+	layout string
+}
+
+func (e *timeLayoutExtension) CreateEncoder(typ reflect2.Type) jsoniter.ValEncoder {
+	if typ.Type1() != timeType {
+		return nil
+	}
+	return timeLayoutCodec{layout: e.layout}
+}
+
+func (e *timeLayoutExtension) CreateDecoder(typ reflect2.Type) jsoniter.ValDecoder {
+	if typ.Type1() != timeType {
+		return nil
+	}
+	return timeLayoutCodec{layout: e.layout}
+}
+
+type timeLayoutCodec struct {
+	layout string
+}
+
+// IsEmpty reports false, as encoding/json never treats a struct as empty
+func (c timeLayoutCodec) IsEmpty(ptr unsafe.Pointer) bool {
+	return false
+}
+
+func (c timeLayoutCodec) Encode(ptr unsafe.Pointer, stream *jsoniter.Stream) {
+	stream.WriteString((*time.Time)(ptr).Format(c.layout))
+}
+
+func (c timeLayoutCodec) Decode(ptr unsafe.Pointer, iter *jsoniter.Iterator) {
+	if iter.ReadNil() {
+		return
+	}
+	s := iter.ReadString()
+	if iter.Error != nil {
+		return
+	}
+	t, err := time.Parse(c.layout, s)
+	if err != nil {
+		if t, err = time.Parse(time.RFC3339Nano, s); err != nil {
+			iter.ReportError("decode time.Time", fmt.Sprintf("%q matches neither %q nor RFC 3339", s, c.layout))
+			return
+		}
+	}
+	*(*time.Time)(ptr) = t
+}
+
+// omitZeroExtension marks every struct field omitempty and makes "empty" mean zero,
+// unless keepEmptiness leaves that to the field's encoder as plain omitempty does
+type omitZeroExtension struct {
+	jsoniter.DummyExtension
+	tagKey        string
+	keepEmptiness bool
+}
+
+func (e *omitZeroExtension) UpdateStructDescriptor(desc *jsoniter.StructDescriptor) {
+	for _, binding := range desc.Fields {
+		tag := binding.Field.Tag()
+		name, ok := tag.Lookup(e.tagKey)
+		if name == "-" {
+			continue
+		}
+		if !ok {
+			name = ""
+		}
+		// jsoniter reads omitempty from the tag after extensions run, and StructTag.Get
+		// returns the first match, so a prefixed entry takes precedence
+		binding.Field = omitZeroField{
+			StructField: binding.Field,
+			tag:         reflect.StructTag(e.tagKey + ":" + strconv.Quote(name+",omitempty") + " " + string(tag)),
+		}
+		if !e.keepEmptiness {
+			binding.Encoder = newOmitZeroEncoder(binding.Field.Type(), binding.Encoder)
+		}
+	}
+}
+
+type omitZeroField struct {
+	reflect2.StructField
+	tag reflect.StructTag
+}
+
+func (f omitZeroField) Tag() reflect.StructTag {
+	return f.tag
+}
+
+type omitZeroEncoder struct {
+	jsoniter.ValEncoder
+	typ       reflect2.Type
+	hasIsZero bool
+}
+
+func newOmitZeroEncoder(typ reflect2.Type, encoder jsoniter.ValEncoder) jsoniter.ValEncoder {
+	return &omitZeroEncoder{
+		ValEncoder: encoder,
+		typ:        typ,
+		hasIsZero:  typ.Type1().Implements(isZeroerType),
+	}
+}
+
+func (e *omitZeroEncoder) IsEmpty(ptr unsafe.Pointer) bool {
+	// Interfaces are zero only when nil, whatever they hold, so look at the
+	// field itself rather than what UnsafeIndirect unboxes
+	v := reflect.NewAt(e.typ.Type1(), ptr).Elem()
+	if e.hasIsZero {
+		if (v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface) && v.IsNil() {
+			return true
+		}
+		if z, ok := v.Interface().(isZeroer); ok {
+			return z.IsZero()
+		}
+	}
+	return v.IsZero()
+}
+
+// discriminatorExtension decodes registered interface types by reading the
+// discriminator key before decoding the whole object into the concrete type
+type discriminatorExtension struct {
+	jsoniter.DummyExtension
+	byType map[reflect.Type]jsonDiscriminator
+}
+
+func (e *discriminatorExtension) CreateDecoder(typ reflect2.Type) jsoniter.ValDecoder {
+	if d, ok := e.byType[typ.Type1()]; ok {
+		return discriminatorDecoder{d}
+	}
+	return nil
+}
+
+type discriminatorDecoder struct {
+	jsonDiscriminator
+}
+
+func (d discriminatorDecoder) Decode(ptr unsafe.Pointer, iter *jsoniter.Iterator) {
+	target := reflect.NewAt(d.iface, ptr).Elem()
+	if iter.ReadNil() {
+		target.Set(reflect.Zero(d.iface))
+		return
+	}
+	raw := iter.SkipAndReturnBytes()
+	if iter.Error != nil {
+		return
+	}
+	v, err := d.decode(iter.Pool(), raw)
+	if err != nil {
+		// Set directly rather than through ReportError so errors.Is works at the top level;
+		// jsoniter flattens errors from struct fields to text
+		if iter.Error == nil {
+			iter.Error = err
+		}
+		return
+	}
+	target.Set(v)
+}
+
+// decode reads the discriminator from raw and decodes raw into the registered type
+func (d discriminatorDecoder) decode(pool jsoniter.IteratorPool, raw []byte) (reflect.Value, error) {
+	var name string
+	found := false
+	err := readWith(pool, raw, func(it *jsoniter.Iterator) {
+		it.ReadObjectCB(func(it *jsoniter.Iterator, field string) bool {
+			if field != d.key {
+				it.Skip()
+				return true
+			}
+			found = true
+			name = it.ReadString()
+			return false
+		})
+	})
+	if err != nil {
+		return reflect.Value{}, err
+	}
+	if !found {
+		return reflect.Value{}, fmt.Errorf("decoding %s: missing discriminator %q", d.iface, d.key)
+	}
+
+	t, ok := d.types.TypeOf(name)
+	if !ok {
+		return reflect.Value{}, fmt.Errorf("decoding %s: %w: %q", d.iface, ErrUnknownType, name)
+	}
+	ptr := reflect.New(t)
+	result := ptr
+	switch {
+	case t.Implements(d.iface):
+		result = ptr.Elem()
+	case !ptr.Type().Implements(d.iface):
+		return reflect.Value{}, fmt.Errorf("decoding %s: type %s registered as %q does not implement it", d.iface, t, name)
+	}
+
+	if err := readWith(pool, raw, func(it *jsoniter.Iterator) { it.ReadVal(ptr.Interface()) }); err != nil {
+		return reflect.Value{}, err
+	}
+	return result, nil
+}
+
+// readWith runs read over data with a pooled iterator and returns its error
+func readWith(pool jsoniter.IteratorPool, data []byte, read func(it *jsoniter.Iterator)) error {
+	it := pool.BorrowIterator(data)
+	defer pool.ReturnIterator(it)
+	read(it)
+	if it.Error != nil && it.Error != io.EOF {
+		return it.Error
+	}
+	return nil
+}
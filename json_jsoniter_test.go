@@ -1,3 +1,5 @@
+//go:build !nojsoniter && !tinygo && !nounsafe
+
 package serializer
 
 import (
@@ -389,4 +391,123 @@ func TestJsoniterThreadSafety(t *testing.T) {
 	for g := 0; g < numGoroutines; g++ {
 		<-done
 	}
-}
\ No newline at end of file
+}
+
+// TestJsoniterErrorMessages tests error message consistency
+func TestJsoniterErrorMessages(t *testing.T) {
+	s := NewJSONSerializer(1024)
+
+	malformedCases := []struct {
+		name    string
+		input   string
+		checkError func(error) bool
+	}{
+		{
+			name:  "UnterminatedString",
+			input: `{"key": "unterminated`,
+			checkError: func(err error) bool {
+				return err != nil && strings.Contains(strings.ToLower(err.Error()), "string")
+			},
+		},
+		{
+			name:  "UnterminatedObject",
+			input: `{"key": "value"`,
+			checkError: func(err error) bool {
+				return err != nil // Any error is acceptable
+			},
+		},
+		{
+			name:  "InvalidNumber",
+			input: `{"key": 123.456.789}`,
+			checkError: func(err error) bool {
+				return err != nil
+			},
+		},
+		{
+			name:  "TrailingComma",
+			input: `{"key": "value",}`,
+			checkError: func(err error) bool {
+				return err != nil
+			},
+		},
+	}
+
+	for _, tc := range malformedCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var result interface{}
+			jsoniterErr := s.Deserialize([]byte(tc.input), &result)
+
+			// Also test with standard library for comparison
+			var stdResult interface{}
+			stdlibErr := stdjson.Unmarshal([]byte(tc.input), &stdResult)
+
+			// Both should produce errors
+			if jsoniterErr == nil {
+				t.Errorf("Jsoniter should have produced an error for input: %s", tc.input)
+			}
+
+			if stdlibErr == nil {
+				t.Errorf("Standard library should have produced an error for input: %s", tc.input)
+			}
+
+			// Check error using provided function
+			if jsoniterErr != nil && !tc.checkError(jsoniterErr) {
+				t.Errorf("Jsoniter error doesn't match expected pattern: %v", jsoniterErr)
+			}
+
+			if stdlibErr != nil && !tc.checkError(stdlibErr) {
+				t.Logf("Stdlib error: %v", stdlibErr)
+			}
+
+			// Log both errors for comparison
+			if jsoniterErr != nil && stdlibErr != nil {
+				t.Logf("Jsoniter error: %v", jsoniterErr)
+				t.Logf("Stdlib error: %v", stdlibErr)
+			}
+		})
+	}
+}
+
+// TestBinaryDataInString tests handling of binary data within JSON strings
+func TestBinaryDataInString(t *testing.T) {
+	s := NewJSONSerializer(1024)
+	stringDeser := s.(StringDeserializer)
+
+	// Create data with binary content (base64 encoded in JSON)
+	binaryData := []byte{0, 1, 2, 3, 255, 254, 253, 10, 13, 9}
+	
+	testData := map[string]interface{}{
+		"text":   "normal text",
+		"binary": string(binaryData), // This will be JSON-escaped
+		"number": 42,
+	}
+
+	// Serialize
+	serialized, err := s.Serialize(testData)
+	if err != nil {
+		t.Fatalf("Serialize failed: %v", err)
+	}
+
+	serializedStr := string(serialized)
+
+	// Deserialize using string method
+	var stringResult map[string]interface{}
+	if err := stringDeser.DeserializeString(serializedStr, &stringResult); err != nil {
+		t.Fatalf("DeserializeString failed: %v", err)
+	}
+
+	// Verify the binary data survived the round trip
+	if stringResult["binary"] != string(binaryData) {
+		t.Error("Binary data was corrupted during deserialization")
+	}
+
+	// Compare with bytes deserialization
+	var bytesResult map[string]interface{}
+	if err := s.Deserialize(serialized, &bytesResult); err != nil {
+		t.Fatalf("Deserialize failed: %v", err)
+	}
+
+	if stringResult["binary"] != bytesResult["binary"] {
+		t.Error("String and bytes deserialization produced different binary data")
+	}
+}
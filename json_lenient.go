@@ -0,0 +1,244 @@
+package serializer
+
+import (
+	"errors"
+	"io"
+)
+
+// errUnterminatedComment is returned when input ends inside a /* */ comment
+var errUnterminatedComment = errors.New("unterminated comment in JSON input")
+
+// WithJSONComments accepts // line and /* block */ comments (JSONC) when
+// deserializing, for human-maintained config files. Comments are blanked out
+// before parsing, so error offsets still point into the original input.
+// Serialized output is always standard JSON.
+func WithJSONComments() Option {
+	return func(o *options) {
+		o.jsonComments = true
+	}
+}
+
+// WithTrailingCommas accepts a comma after the last element of an array or
+// object when deserializing, as JavaScript tooling does. Trailing commas are
+// rejected by default; empty elements such as [1,,2] or [,] are always rejected.
+func WithTrailingCommas() Option {
+	return func(o *options) {
+		o.jsonTrailingCommas = true
+	}
+}
+
+type filterState int
+
+const (
+	filterValue filterState = iota
+	filterString
+	filterStringEscape
+	filterSlash
+	filterLineComment
+	filterBlockComment
+	filterBlockStar
+)
+
+// jsonFilter rewrites relaxed JSON into standard JSON on the fly.
+// It never changes the length of its input: removed syntax is replaced with spaces.
+type jsonFilter struct {
+	comments       bool
+	trailingCommas bool
+
+	state filterState
+	// prev is the last significant byte outside strings and comments
+	prev byte
+	// comma is set while a comma is held back; pending holds it along with the
+	// whitespace and blanked comments that follow it
+	comma   bool
+	pending []byte
+}
+
+// lenientEngine preprocesses input with a jsonFilter before handing it to the backend
+type lenientEngine struct {
+	jsonEngine
+	newFilter func() *jsonFilter
+}
+
+// withLenience wraps engine when options relax the accepted input syntax
+func withLenience(engine jsonEngine, o *options) jsonEngine {
+	if !o.lenientJSON() {
+		return engine
+	}
+	return lenientEngine{jsonEngine: engine, newFilter: o.newJSONFilter}
+}
+
+// lenientJSON reports whether options relax the accepted input syntax. Readers
+// that split a stream into values before the engine sees them must filter it
+// with newJSONFilter first.
+func (o *options) lenientJSON() bool {
+	return o.jsonComments || o.jsonTrailingCommas
+}
+
+// newJSONFilter returns a filter for the relaxed syntax options accept
+func (o *options) newJSONFilter() *jsonFilter {
+	return &jsonFilter{comments: o.jsonComments, trailingCommas: o.jsonTrailingCommas}
+}
+
+func (e lenientEngine) unmarshal(data []byte, v any) error {
+	f := e.newFilter()
+	clean, err := f.close(f.write(make([]byte, 0, len(data)), data))
+	if err != nil {
+		return err
+	}
+	return e.jsonEngine.unmarshal(clean, v)
+}
+
+func (e lenientEngine) decode(r io.Reader, v any) error {
+	return e.jsonEngine.decode(&jsonFilterReader{r: r, f: e.newFilter()}, v)
+}
+
+// write appends the filtered form of src to dst
+func (f *jsonFilter) write(dst, src []byte) []byte {
+	for _, c := range src {
+		switch f.state {
+		case filterString:
+			switch c {
+			case '\\':
+				f.state = filterStringEscape
+			case '"':
+				f.state = filterValue
+			}
+			dst = append(dst, c)
+		case filterStringEscape:
+			f.state = filterString
+			dst = append(dst, c)
+		case filterSlash:
+			switch c {
+			case '/':
+				f.state = filterLineComment
+				dst = f.blank(dst, ' ', ' ')
+			case '*':
+				f.state = filterBlockComment
+				dst = f.blank(dst, ' ', ' ')
+			default:
+				// Not a comment; let the parser report the stray slash
+				f.state = filterValue
+				dst = f.value(append(f.significant(dst, '/'), '/'), c)
+			}
+		case filterLineComment:
+			if c == '\n' || c == '\r' {
+				f.state = filterValue
+				dst = f.blank(dst, c)
+			} else {
+				dst = f.blank(dst, ' ')
+			}
+		case filterBlockComment, filterBlockStar:
+			switch {
+			case c == '/' && f.state == filterBlockStar:
+				f.state = filterValue
+			case c == '*':
+				f.state = filterBlockStar
+			default:
+				f.state = filterBlockComment
+			}
+			if c == '\n' || c == '\r' {
+				dst = f.blank(dst, c)
+			} else {
+				dst = f.blank(dst, ' ')
+			}
+		default:
+			dst = f.value(dst, c)
+		}
+	}
+	return dst
+}
+
+// value handles a byte outside strings and comments
+func (f *jsonFilter) value(dst []byte, c byte) []byte {
+	switch c {
+	case ' ', '\t', '\n', '\r':
+		return f.blank(dst, c)
+	case '/':
+		if f.comments {
+			// Held back until the next byte shows whether a comment starts
+			f.state = filterSlash
+			return dst
+		}
+	case '"':
+		f.state = filterString
+	case ',':
+		if f.trailingCommas && f.prev != ',' && f.prev != '[' && f.prev != '{' {
+			// Held back until the next significant byte shows whether it closes
+			// the array or object
+			dst = f.significant(dst, c)
+			f.comma = true
+			f.pending = append(f.pending[:0], c)
+			return dst
+		}
+	}
+	return append(f.significant(dst, c), c)
+}
+
+// significant releases a held-back comma ahead of c, blanking it when c closes
+// an array or object, and records c as the last significant byte
+func (f *jsonFilter) significant(dst []byte, c byte) []byte {
+	f.prev = c
+	if !f.comma {
+		return dst
+	}
+	f.comma = false
+	if c == ']' || c == '}' {
+		f.pending[0] = ' '
+	}
+	return append(dst, f.pending...)
+}
+
+// blank appends bytes that carry no syntax, queueing them behind a held-back comma
+func (f *jsonFilter) blank(dst []byte, b ...byte) []byte {
+	if f.comma {
+		f.pending = append(f.pending, b...)
+		return dst
+	}
+	return append(dst, b...)
+}
+
+// close flushes held-back input and reports input that ended inside a comment
+func (f *jsonFilter) close(dst []byte) ([]byte, error) {
+	switch f.state {
+	case filterSlash:
+		dst = append(f.significant(dst, '/'), '/')
+	case filterBlockComment, filterBlockStar:
+		return dst, errUnterminatedComment
+	}
+	dst = f.significant(dst, 0)
+	f.state = filterValue
+	return dst, nil
+}
+
+// jsonFilterReader applies a jsonFilter to a stream
+type jsonFilterReader struct {
+	r   io.Reader
+	f   *jsonFilter
+	buf []byte
+	out []byte
+	off int
+	err error
+}
+
+func (fr *jsonFilterReader) Read(p []byte) (int, error) {
+	for fr.off == len(fr.out) {
+		if fr.err != nil {
+			return 0, fr.err
+		}
+		if fr.buf == nil {
+			fr.buf = make([]byte, 4096)
+		}
+		n, err := fr.r.Read(fr.buf)
+		fr.out, fr.off = fr.f.write(fr.out[:0], fr.buf[:n]), 0
+		if err == io.EOF {
+			if fr.out, err = fr.f.close(fr.out); err == nil {
+				err = io.EOF
+			}
+		}
+		fr.err = err
+	}
+	n := copy(p, fr.out[fr.off:])
+	fr.off += n
+	return n, nil
+}
@@ -0,0 +1,228 @@
+package serializer
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+const jsoncInput = `{
+	// service name
+	"name": "api", /* inline */ "url": "http://example.com/a//b",
+	/* multi
+	   line */
+	"path": "/*not a comment*/", "escaped": "quote \" // still string"
+}`
+
+type jsoncConfig struct {
+	Name    string `json:"name"`
+	URL     string `json:"url"`
+	Path    string `json:"path"`
+	Escaped string `json:"escaped"`
+}
+
+func TestJSONFilterPreservesLength(t *testing.T) {
+	f := &jsonFilter{comments: true}
+	out, err := f.close(f.write(nil, []byte(jsoncInput)))
+	if err != nil {
+		t.Fatalf("filter failed: %v", err)
+	}
+	if len(out) != len(jsoncInput) {
+		t.Errorf("expected %d bytes, got %d", len(jsoncInput), len(out))
+	}
+	if bytes.Count(out, []byte("\n")) != strings.Count(jsoncInput, "\n") {
+		t.Error("newlines should be preserved")
+	}
+}
+
+func TestWithJSONComments(t *testing.T) {
+	want := jsoncConfig{
+		Name:    "api",
+		URL:     "http://example.com/a//b",
+		Path:    "/*not a comment*/",
+		Escaped: `quote " // still string`,
+	}
+
+	for _, backend := range []JSONBackend{JSONBackendJSONIter, JSONBackendStdlib} {
+		t.Run(string(backend), func(t *testing.T) {
+			if !JSONBackendAvailable(backend) {
+				t.Skipf("backend %q not compiled in", backend)
+			}
+			s := NewJSONSerializer(maxBufferSize, WithJSONBackend(backend), WithJSONComments())
+
+			var got jsoncConfig
+			if err := s.Deserialize([]byte(jsoncInput), &got); err != nil {
+				t.Fatalf("Deserialize failed: %v", err)
+			}
+			if got != want {
+				t.Errorf("expected %+v, got %+v", want, got)
+			}
+
+			got = jsoncConfig{}
+			if err := s.(StringDeserializer).DeserializeString(jsoncInput, &got); err != nil {
+				t.Fatalf("DeserializeString failed: %v", err)
+			}
+			if got != want {
+				t.Errorf("expected %+v, got %+v", want, got)
+			}
+
+			// One byte per read exercises state carried across reads
+			got = jsoncConfig{}
+			if err := s.DeserializeFrom(iotest.OneByteReader(strings.NewReader(jsoncInput)), &got); err != nil {
+				t.Fatalf("DeserializeFrom failed: %v", err)
+			}
+			if got != want {
+				t.Errorf("expected %+v, got %+v", want, got)
+			}
+
+			// Output stays standard JSON
+			data, err := s.Serialize(want)
+			if err != nil {
+				t.Fatalf("Serialize failed: %v", err)
+			}
+			plain, _ := NewJSONSerializer(maxBufferSize, WithJSONBackend(backend)).Serialize(want)
+			if !bytes.Equal(data, plain) {
+				t.Errorf("expected %s, got %s", plain, data)
+			}
+		})
+	}
+}
+
+func TestJSONCommentsErrors(t *testing.T) {
+	s := NewJSONSerializer(maxBufferSize, WithJSONComments())
+	var v map[string]any
+
+	if err := s.Deserialize([]byte(`{"a":1} /* open`), &v); err != errUnterminatedComment {
+		t.Errorf("expected unterminated comment error, got %v", err)
+	}
+	if err := s.Deserialize([]byte(`{"a":1 / 2}`), &v); err == nil {
+		t.Error("expected error for stray slash")
+	}
+	// A slash between digits must not vanish and join them
+	for _, input := range []string{`{"a":1/2}`, `{"a":1/2/3}`} {
+		if err := s.Deserialize([]byte(input), &v); err == nil {
+			t.Errorf("expected error for stray slash in %s, got %v", input, v)
+		}
+		if err := s.DeserializeFrom(strings.NewReader(input), &v); err == nil {
+			t.Errorf("expected error from stream for stray slash in %s", input)
+		}
+	}
+	if err := s.DeserializeFrom(strings.NewReader(`{"a":1 /* open`), &v); err == nil {
+		t.Errorf("expected error from stream, got %v", err)
+	}
+
+	strict := NewJSONSerializer(maxBufferSize)
+	if err := strict.Deserialize([]byte("{\"a\":1 // comment\n}"), &v); err == nil {
+		t.Error("comments must be rejected without WithJSONComments")
+	}
+}
+
+func TestDecodeArrayStreamWithComments(t *testing.T) {
+	s := NewJSONSerializer(maxBufferSize, WithJSONComments()).(*JSONSerializer)
+	var got []int
+	err := s.DecodeArrayStream(strings.NewReader("[1, // one\n 2 /* two */]"), func(e Element) error {
+		var n int
+		if err := e.Decode(&n); err != nil {
+			return err
+		}
+		got = append(got, n)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("DecodeArrayStream failed: %v", err)
+	}
+	if len(got) != 2 || got[1] != 2 {
+		t.Errorf("unexpected elements %v", got)
+	}
+}
+
+// Options that wrap the engine must not hide the comment filter from stream readers
+func TestStreamDecoderWithCommentsAndWrappers(t *testing.T) {
+	s := NewJSONSerializer(maxBufferSize, WithJSONComments(), WithTrailingNewline(false), WithMaxDepth(8)).(*JSONSerializer)
+	dec := s.NewStreamDecoder(strings.NewReader("{\"a\": 1} // first\n{\"a\": 2} /* second */"))
+	for want := 1; want <= 2; want++ {
+		var got map[string]int
+		if err := dec.Decode(&got); err != nil || got["a"] != want {
+			t.Fatalf("Decode = %v, %v, want a = %d", got, err, want)
+		}
+	}
+}
+
+func TestWithTrailingCommas(t *testing.T) {
+	input := "{\n\t\"tags\": [\"a\", \"b\",],\n\t\"nested\": {\"n\": 1, /* last */},\n\t\"text\": \"x,]\", // done\n}"
+	type config struct {
+		Tags   []string       `json:"tags"`
+		Nested map[string]int `json:"nested"`
+		Text   string         `json:"text"`
+	}
+
+	f := &jsonFilter{comments: true, trailingCommas: true}
+	out, err := f.close(f.write(nil, []byte(input)))
+	if err != nil {
+		t.Fatalf("filter failed: %v", err)
+	}
+	if len(out) != len(input) {
+		t.Errorf("expected %d bytes, got %d", len(input), len(out))
+	}
+
+	for _, backend := range []JSONBackend{JSONBackendJSONIter, JSONBackendStdlib} {
+		t.Run(string(backend), func(t *testing.T) {
+			if !JSONBackendAvailable(backend) {
+				t.Skipf("backend %q not compiled in", backend)
+			}
+			s := NewJSONSerializer(maxBufferSize, WithJSONBackend(backend), WithJSONComments(), WithTrailingCommas())
+
+			var got config
+			if err := s.Deserialize([]byte(input), &got); err != nil {
+				t.Fatalf("Deserialize failed: %v", err)
+			}
+			if len(got.Tags) != 2 || got.Nested["n"] != 1 || got.Text != "x,]" {
+				t.Errorf("unexpected result %+v", got)
+			}
+
+			got = config{}
+			if err := s.DeserializeFrom(iotest.OneByteReader(strings.NewReader(input)), &got); err != nil {
+				t.Fatalf("DeserializeFrom failed: %v", err)
+			}
+			if len(got.Tags) != 2 || got.Nested["n"] != 1 || got.Text != "x,]" {
+				t.Errorf("unexpected result %+v", got)
+			}
+		})
+	}
+}
+
+func TestTrailingCommasErrors(t *testing.T) {
+	s := NewJSONSerializer(maxBufferSize, WithTrailingCommas())
+	var v any
+
+	for _, input := range []string{`[1,,2]`, `[1,,]`, `[,]`, `{,}`, `[1] ,`} {
+		if err := s.Deserialize([]byte(input), &v); err == nil {
+			t.Errorf("expected error for %s", input)
+		}
+	}
+	if err := s.Deserialize([]byte("[1] // comment"), &v); err == nil {
+		t.Error("comments must still be rejected without WithJSONComments")
+	}
+
+	strict := NewJSONSerializer(maxBufferSize)
+	if err := strict.Deserialize([]byte(`[1, 2,]`), &v); err == nil {
+		t.Error("trailing commas must be rejected without WithTrailingCommas")
+	}
+}
+
+func TestDecodeArrayStreamWithTrailingComma(t *testing.T) {
+	s := NewJSONSerializer(maxBufferSize, WithTrailingCommas()).(*JSONSerializer)
+	count := 0
+	err := s.DecodeArrayStream(strings.NewReader(`[{"a":1,}, {"a":2},]`), func(e Element) error {
+		count++
+		var m map[string]int
+		return e.Decode(&m)
+	})
+	if err != nil {
+		t.Fatalf("DecodeArrayStream failed: %v", err)
+	}
+	if count != 2 {
+		t.Errorf("expected 2 elements, got %d", count)
+	}
+}
@@ -0,0 +1,151 @@
+package serializer
+
+import (
+	"errors"
+	"fmt"
+	"io"
+)
+
+// ErrTokenTooLarge is returned when a streamed JSON string or number exceeds the
+// size set with WithMaxTokenSize
+var ErrTokenTooLarge = errors.New("JSON token exceeds maximum size")
+
+// WithMaxTokenSize limits the size of a single string or number literal, including
+// its quotes, that DeserializeFrom and DecodeArrayStream will read, so one huge
+// string in an otherwise streamed document cannot exhaust memory. The check runs
+// as input is read, before the backend buffers the token. If n <= 0, tokens are
+// not limited (the default).
+func WithMaxTokenSize(n int) Option {
+	return func(o *options) {
+		o.jsonMaxTokenSize = n
+	}
+}
+
+// tokenLimitReader fails a stream as soon as a string or scalar literal grows past max bytes
+type tokenLimitReader struct {
+	r   io.Reader
+	max int
+
+	inString bool
+	escape   bool
+	size     int
+	err      error
+}
+
+func (tr *tokenLimitReader) Read(p []byte) (int, error) {
+	if tr.err != nil {
+		return 0, tr.err
+	}
+	n, err := tr.r.Read(p)
+	for i, c := range p[:n] {
+		switch {
+		case tr.inString:
+			tr.size++
+			switch {
+			case tr.escape:
+				tr.escape = false
+			case c == '\\':
+				tr.escape = true
+			case c == '"':
+				tr.inString = false
+				tr.size = 0
+				continue
+			}
+		case c == '"':
+			tr.inString = true
+			tr.size = 1
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r' ||
+			c == '{' || c == '}' || c == '[' || c == ']' || c == ',' || c == ':':
+			tr.size = 0
+		default:
+			tr.size++
+		}
+		if tr.size > tr.max {
+			// Hand over what precedes the token; the next Read reports the error
+			tr.err = fmt.Errorf("%w: more than %d bytes", ErrTokenTooLarge, tr.max)
+			return i, nil
+		}
+	}
+	return n, err
+}
+
+// streamReader prepares r for a streaming decode. Backends may report a failed read
+// as a bare EOF or syntax error, so readErr recovers the reader's own error.
+func (s *JSONSerializer) streamReader(r io.Reader) (stream io.Reader, readErr func(error) error) {
+	br := &bomReader{r: r}
+	stream = br
+	var tr *tokenLimitReader
+	if s.opts.jsonMaxTokenSize > 0 {
+		tr = &tokenLimitReader{r: br, max: s.opts.jsonMaxTokenSize}
+		stream = tr
+	}
+	return stream, func(err error) error {
+		switch {
+		case br.err == ErrUTF16Input:
+			return br.err
+		case err != nil && tr != nil && tr.err != nil:
+			return tr.err
+		}
+		return err
+	}
+}
+
+// jsonDepth follows the nesting depth of JSON text fed to it in pieces
+type jsonDepth struct {
+	max      int
+	depth    int
+	inString bool
+	escape   bool
+	// offset counts the bytes scanned before the current piece
+	offset int64
+}
+
+// scan advances over p and returns a *MaxDepthError if a bracket in it opens a
+// level past max, along with the index of that bracket
+func (d *jsonDepth) scan(p []byte) (int, error) {
+	for i, c := range p {
+		switch {
+		case d.inString:
+			switch {
+			case d.escape:
+				d.escape = false
+			case c == '\\':
+				d.escape = true
+			case c == '"':
+				d.inString = false
+			}
+		case c == '"':
+			d.inString = true
+		case c == '{' || c == '[':
+			d.depth++
+			if d.depth > d.max {
+				return i, &MaxDepthError{Limit: d.max, Offset: d.offset + int64(i)}
+			}
+		case c == '}' || c == ']':
+			// Unbalanced input is left for the backend to reject
+			d.depth = max(d.depth-1, 0)
+		}
+	}
+	d.offset += int64(len(p))
+	return len(p), nil
+}
+
+// depthLimitReader fails a stream as soon as it nests deeper than its limit
+type depthLimitReader struct {
+	r     io.Reader
+	depth jsonDepth
+	err   error
+}
+
+func (dr *depthLimitReader) Read(p []byte) (int, error) {
+	if dr.err != nil {
+		return 0, dr.err
+	}
+	n, err := dr.r.Read(p)
+	if i, depthErr := dr.depth.scan(p[:n]); depthErr != nil {
+		// Hand over what precedes the bracket; the next Read reports the error
+		dr.err = depthErr
+		return i, nil
+	}
+	return n, err
+}
@@ -0,0 +1,82 @@
+package serializer
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+// endlessString streams `"xxxx...` without ever closing the string
+type endlessString struct {
+	started bool
+	read    int
+}
+
+func (r *endlessString) Read(p []byte) (int, error) {
+	n := 0
+	if !r.started {
+		r.started = true
+		p[0] = '"'
+		n = 1
+	}
+	for ; n < len(p); n++ {
+		p[n] = 'x'
+	}
+	r.read += n
+	return n, nil
+}
+
+func TestWithMaxTokenSize(t *testing.T) {
+	for _, backend := range []JSONBackend{JSONBackendJSONIter, JSONBackendStdlib} {
+		t.Run(string(backend), func(t *testing.T) {
+			if !JSONBackendAvailable(backend) {
+				t.Skipf("backend %q not compiled in", backend)
+			}
+			s := NewJSONSerializer(maxBufferSize, WithJSONBackend(backend), WithMaxTokenSize(16)).(*JSONSerializer)
+
+			var v map[string]any
+			ok := `{"short": "0123456789abcd", "n": 12345678901234, "escaped": "\"\"\"\"\"\""}`
+			if err := s.DeserializeFrom(iotest.OneByteReader(strings.NewReader(ok)), &v); err != nil {
+				t.Fatalf("tokens within the limit should decode: %v", err)
+			}
+
+			long := `{"a": "` + strings.Repeat("x", 64) + `"}`
+			if err := s.DeserializeFrom(strings.NewReader(long), &v); !errors.Is(err, ErrTokenTooLarge) {
+				t.Errorf("expected ErrTokenTooLarge, got %v", err)
+			}
+			var list []int64
+			if err := s.DeserializeFrom(strings.NewReader(`[`+strings.Repeat("1", 20)+`]`), &list); !errors.Is(err, ErrTokenTooLarge) {
+				t.Errorf("expected ErrTokenTooLarge for a long number, got %v", err)
+			}
+
+			// The stream is abandoned long before it could be buffered
+			endless := &endlessString{}
+			var str string
+			if err := s.DeserializeFrom(endless, &str); !errors.Is(err, ErrTokenTooLarge) {
+				t.Errorf("expected ErrTokenTooLarge, got %v", err)
+			}
+			if endless.read > 64*1024 {
+				t.Errorf("read %d bytes before failing", endless.read)
+			}
+
+			count := 0
+			err := s.DecodeArrayStream(strings.NewReader(`[1, "ok", "`+strings.Repeat("y", 32)+`"]`), func(e Element) error {
+				count++
+				return nil
+			})
+			if !errors.Is(err, ErrTokenTooLarge) || count != 2 {
+				t.Errorf("expected ErrTokenTooLarge after 2 elements, got %v after %d", err, count)
+			}
+		})
+	}
+}
+
+func TestMaxTokenSizeUnlimitedByDefault(t *testing.T) {
+	s := NewJSONSerializer(maxBufferSize)
+	var v string
+	if err := s.DeserializeFrom(io.MultiReader(strings.NewReader(`"`), strings.NewReader(strings.Repeat("x", 1<<20)+`"`)), &v); err != nil || len(v) != 1<<20 {
+		t.Errorf("expected a 1MB string to decode without a limit, got %d bytes, %v", len(v), err)
+	}
+}
@@ -0,0 +1,267 @@
+package serializer
+
+import (
+	"bytes"
+	stdjson "encoding/json"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"unicode/utf8"
+)
+
+// liteEngine encodes the dynamic shapes common at the edge (strings, numbers, bools,
+// map[string]any, []any and friends) without reflection, and hands anything else
+// to encoding/json. Output matches encoding/json byte for byte. Decoding is
+// delegated to encoding/json.
+type liteEngine struct {
+	escapeHTML bool
+}
+
+func (e liteEngine) encode(w io.Writer, v any) error {
+	return encodeBuffered(w, func(buf *bytes.Buffer) error {
+		n := buf.Len()
+		if err := e.appendValue(buf, v); err != nil {
+			buf.Truncate(n)
+			return err
+		}
+		return buf.WriteByte('\n')
+	})
+}
+
+func (liteEngine) unmarshal(data []byte, v any) error {
+	return stdjson.Unmarshal(data, v)
+}
+
+func (liteEngine) decode(r io.Reader, v any) error {
+	return stdjson.NewDecoder(r).Decode(v)
+}
+
+// marshal is json.Marshal on the lite path
+func (e liteEngine) marshal(v any) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := e.appendValue(&buf, v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (e liteEngine) appendValue(buf *bytes.Buffer, v any) error {
+	var scratch [64]byte
+	switch v := v.(type) {
+	case nil:
+		buf.WriteString("null")
+	case string:
+		e.appendString(buf, v)
+	case bool:
+		buf.Write(strconv.AppendBool(scratch[:0], v))
+	case int:
+		buf.Write(strconv.AppendInt(scratch[:0], int64(v), 10))
+	case int8:
+		buf.Write(strconv.AppendInt(scratch[:0], int64(v), 10))
+	case int16:
+		buf.Write(strconv.AppendInt(scratch[:0], int64(v), 10))
+	case int32:
+		buf.Write(strconv.AppendInt(scratch[:0], int64(v), 10))
+	case int64:
+		buf.Write(strconv.AppendInt(scratch[:0], v, 10))
+	case uint:
+		buf.Write(strconv.AppendUint(scratch[:0], uint64(v), 10))
+	case uint8:
+		buf.Write(strconv.AppendUint(scratch[:0], uint64(v), 10))
+	case uint16:
+		buf.Write(strconv.AppendUint(scratch[:0], uint64(v), 10))
+	case uint32:
+		buf.Write(strconv.AppendUint(scratch[:0], uint64(v), 10))
+	case uint64:
+		buf.Write(strconv.AppendUint(scratch[:0], v, 10))
+	case float32:
+		return e.appendFloat(buf, float64(v), 32)
+	case float64:
+		return e.appendFloat(buf, v, 64)
+	case []any:
+		if v == nil {
+			buf.WriteString("null")
+			return nil
+		}
+		buf.WriteByte('[')
+		for i, elem := range v {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			if err := e.appendValue(buf, elem); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte(']')
+	case []string:
+		if v == nil {
+			buf.WriteString("null")
+			return nil
+		}
+		buf.WriteByte('[')
+		for i, elem := range v {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			e.appendString(buf, elem)
+		}
+		buf.WriteByte(']')
+	case map[string]any:
+		if v == nil {
+			buf.WriteString("null")
+			return nil
+		}
+		buf.WriteByte('{')
+		for i, key := range sortedKeys(v) {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			e.appendString(buf, key)
+			buf.WriteByte(':')
+			if err := e.appendValue(buf, v[key]); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte('}')
+	case map[string]string:
+		if v == nil {
+			buf.WriteString("null")
+			return nil
+		}
+		buf.WriteByte('{')
+		for i, key := range sortedKeys(v) {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			e.appendString(buf, key)
+			buf.WriteByte(':')
+			e.appendString(buf, v[key])
+		}
+		buf.WriteByte('}')
+	default:
+		return e.appendFallback(buf, v)
+	}
+	return nil
+}
+
+// appendFallback encodes v with encoding/json, which only writes on success
+func (e liteEngine) appendFallback(buf *bytes.Buffer, v any) error {
+	enc := stdjson.NewEncoder(buf)
+	enc.SetEscapeHTML(e.escapeHTML)
+	if err := enc.Encode(v); err != nil {
+		return err
+	}
+	buf.Truncate(buf.Len() - 1)
+	return nil
+}
+
+func (e liteEngine) appendFloat(buf *bytes.Buffer, f float64, bits int) error {
+	b, err := appendJSONFloat(buf.AvailableBuffer(), f, bits)
+	if err != nil {
+		return err
+	}
+	buf.Write(b)
+	return nil
+}
+
+func (e liteEngine) appendString(buf *bytes.Buffer, s string) {
+	buf.Write(appendJSONString(buf.AvailableBuffer(), s, e.escapeHTML))
+}
+
+// appendJSONFloat formats like encoding/json: plain notation between 1e-6 and 1e21,
+// exponent notation outside it with a single-digit negative exponent
+func appendJSONFloat(dst []byte, f float64, bits int) ([]byte, error) {
+	if math.IsInf(f, 0) || math.IsNaN(f) {
+		// Let encoding/json produce its UnsupportedValueError
+		var err error
+		if bits == 32 {
+			_, err = stdjson.Marshal(float32(f))
+		} else {
+			_, err = stdjson.Marshal(f)
+		}
+		return dst, err
+	}
+	format := byte('f')
+	if abs := math.Abs(f); abs != 0 {
+		if bits == 64 && (abs < 1e-6 || abs >= 1e21) || bits == 32 && (float32(abs) < 1e-6 || float32(abs) >= 1e21) {
+			format = 'e'
+		}
+	}
+	start := len(dst)
+	dst = strconv.AppendFloat(dst, f, format, -1, bits)
+	if format == 'e' {
+		if b := dst[start:]; len(b) >= 4 && b[len(b)-4] == 'e' && b[len(b)-3] == '-' && b[len(b)-2] == '0' {
+			b[len(b)-2] = b[len(b)-1]
+			dst = dst[:len(dst)-1]
+		}
+	}
+	return dst, nil
+}
+
+// appendJSONString quotes s like encoding/json
+func appendJSONString(dst []byte, s string, escapeHTML bool) []byte {
+	const hex = "0123456789abcdef"
+	if !utf8.ValidString(s) {
+		// How U+FFFD is written differs between encoding/json versions; strings
+		// always encode, so the error is nil
+		buf := bytes.NewBuffer(dst)
+		enc := stdjson.NewEncoder(buf)
+		enc.SetEscapeHTML(escapeHTML)
+		_ = enc.Encode(s)
+		return buf.Bytes()[:buf.Len()-1]
+	}
+	dst = append(dst, '"')
+	start := 0
+	for i := 0; i < len(s); {
+		if c := s[i]; c < utf8.RuneSelf {
+			if c >= 0x20 && c != '"' && c != '\\' && (!escapeHTML || c != '<' && c != '>' && c != '&') {
+				i++
+				continue
+			}
+			dst = append(dst, s[start:i]...)
+			switch c {
+			case '"', '\\':
+				dst = append(dst, '\\', c)
+			case '\b':
+				dst = append(dst, `\b`...)
+			case '\f':
+				dst = append(dst, `\f`...)
+			case '\n':
+				dst = append(dst, `\n`...)
+			case '\r':
+				dst = append(dst, `\r`...)
+			case '\t':
+				dst = append(dst, `\t`...)
+			default:
+				dst = append(dst, `\u00`...)
+				dst = append(dst, hex[c>>4], hex[c&0xF])
+			}
+			i++
+			start = i
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(s[i:])
+		// U+2028 and U+2029 break JSONP and some JavaScript parsers
+		if r == '\u2028' || r == '\u2029' {
+			dst = append(dst, s[start:i]...)
+			dst = append(dst, `\u202`...)
+			dst = append(dst, hex[r&0xF])
+			i += size
+			start = i
+			continue
+		}
+		i += size
+	}
+	dst = append(dst, s[start:]...)
+	return append(dst, '"')
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
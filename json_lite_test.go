@@ -0,0 +1,68 @@
+package serializer_test
+
+import (
+	"bytes"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/MichaelAJay/go-serializer"
+)
+
+func TestJSONBackendLiteMatchesStdlib(t *testing.T) {
+	type nested struct {
+		When time.Time `json:"when"`
+	}
+
+	values := []any{
+		"plain",
+		"quote \" backslash \\ <tag> & \b\f\n\r\t \x01 \x7f",
+		"invalid \xff utf-8, line sep \u2028 para sep \u2029, emoji 🎉",
+		true,
+		-42,
+		int8(-8), int16(16), int32(-32), int64(math.MinInt64),
+		uint(7), uint8(8), uint16(16), uint32(32), uint64(math.MaxUint64),
+		0.0, 1.5, -2.25, 1e-7, 123456789e15, 1e21, 5e-324,
+		float32(0.1), float32(1e-7), float32(3e21),
+		[]any{nil, "a", 1, 2.5, false, []any{}, map[string]any{}},
+		[]any(nil),
+		[]string{"x", "<y>"},
+		[]string(nil),
+		map[string]any{"b": 1, "a": []any{"c"}, "<k>": map[string]any{"z": nil}},
+		map[string]any(nil),
+		map[string]string{"b": "2", "a": "1"},
+		map[string]any{"struct": nested{When: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)}},
+		nested{},
+	}
+
+	for _, escape := range []bool{false, true} {
+		lite := serializer.NewJSONSerializer(maxBufferSize, serializer.WithJSONBackend(serializer.JSONBackendLite), serializer.WithEscapeHTML(escape))
+		std := serializer.NewJSONSerializer(maxBufferSize, serializer.WithStdlibJSON(), serializer.WithEscapeHTML(escape))
+		for _, v := range values {
+			got, err := lite.Serialize(v)
+			if err != nil {
+				t.Fatalf("lite Serialize(%#v): %v", v, err)
+			}
+			want, err := std.Serialize(v)
+			if err != nil {
+				t.Fatalf("stdlib Serialize(%#v): %v", v, err)
+			}
+			if !bytes.Equal(got, want) {
+				t.Errorf("escape=%v %#v:\n got %s\nwant %s", escape, v, got, want)
+			}
+		}
+	}
+}
+
+func TestJSONBackendLiteUnsupportedValue(t *testing.T) {
+	s := serializer.NewJSONSerializer(maxBufferSize, serializer.WithJSONBackend(serializer.JSONBackendLite))
+
+	var buf bytes.Buffer
+	buf.WriteString("kept")
+	if err := s.SerializeTo(&buf, map[string]any{"a": 1, "b": math.NaN()}); err == nil {
+		t.Fatal("expected an error for NaN")
+	}
+	if buf.String() != "kept" {
+		t.Errorf("failed encode left partial output: %q", buf.String())
+	}
+}
@@ -0,0 +1,24 @@
+//go:build (nojsoniter || nounsafe) && !tinygo
+
+package serializer
+
+import (
+	stdjson "encoding/json"
+)
+
+// json stands in for the jsoniter API used by the rest of the package
+var json stdlibAPI
+
+const defaultJSONBackend = JSONBackendStdlib
+
+var taggedJSONEngines map[JSONBackend]func(o *options) jsonEngine
+
+type stdlibAPI struct{}
+
+func (stdlibAPI) Marshal(v any) ([]byte, error) {
+	return stdjson.Marshal(v)
+}
+
+func (stdlibAPI) Unmarshal(data []byte, v any) error {
+	return stdjson.Unmarshal(data, v)
+}
@@ -0,0 +1,493 @@
+package serializer
+
+import (
+	"bytes"
+	stdjson "encoding/json"
+	"fmt"
+	"strconv"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// maxJSONReaderDepth matches the nesting limit of encoding/json
+const maxJSONReaderDepth = 10000
+
+// JSONReader decodes JSON without reflection, one value at a time. It backs the
+// decoders written by serializer-gen; values it has no method for are handed to
+// encoding/json through ReadValue.
+//
+// A null where a string, number or bool is expected is an error, as the decoders
+// check ReadNull first to leave the target unchanged, as encoding/json does.
+type JSONReader struct {
+	data    []byte
+	pos     int
+	depth   int
+	scratch []byte
+}
+
+// NewJSONReader creates a reader over data
+func NewJSONReader(data []byte) *JSONReader {
+	return &JSONReader{data: data}
+}
+
+// End checks that nothing but whitespace follows the values read so far
+func (r *JSONReader) End() error {
+	if r.skipSpace(); r.pos < len(r.data) {
+		return r.errorf("unexpected %q after top-level value", r.data[r.pos])
+	}
+	return nil
+}
+
+// ReadNull consumes a null if one comes next and reports whether it did
+func (r *JSONReader) ReadNull() bool {
+	if r.peek() == 'n' && bytes.HasPrefix(r.data[r.pos:], []byte("null")) {
+		r.pos += len("null")
+		return true
+	}
+	return false
+}
+
+// ReadObject reads an object, calling fn with the index of each member whose key
+// is in fields; fn must read the member's value. Keys match exactly or, failing
+// that, case-insensitively, as in encoding/json. Other members are skipped, and
+// null reads as an object without members.
+func (r *JSONReader) ReadObject(fields []string, fn func(field int) error) error {
+	return r.readMembers(func(key []byte) error {
+		if field := matchJSONField(fields, key); field >= 0 {
+			return fn(field)
+		}
+		return r.Skip()
+	})
+}
+
+// ReadMap reads an object, calling fn with each key; fn must read the member's value.
+// null reads as an object without members.
+func (r *JSONReader) ReadMap(fn func(key string) error) error {
+	return r.readMembers(func(key []byte) error {
+		return fn(string(key))
+	})
+}
+
+// ReadArray reads an array, calling fn to read each element. null reads as an
+// empty array.
+func (r *JSONReader) ReadArray(fn func() error) error {
+	if r.ReadNull() {
+		return nil
+	}
+	if r.peek() != '[' {
+		return r.unexpected("array")
+	}
+	r.pos++
+	if err := r.enter(); err != nil {
+		return err
+	}
+	defer r.leave()
+
+	if r.peek() == ']' {
+		r.pos++
+		return nil
+	}
+	for {
+		if err := fn(); err != nil {
+			return err
+		}
+		switch r.peek() {
+		case ',':
+			r.pos++
+		case ']':
+			r.pos++
+			return nil
+		default:
+			return r.unexpected("',' or ']'")
+		}
+	}
+}
+
+// ReadString reads a string
+func (r *JSONReader) ReadString() (string, error) {
+	if r.peek() != '"' {
+		return "", r.unexpected("string")
+	}
+	b, err := r.readString()
+	return string(b), err
+}
+
+// ReadBool reads true or false
+func (r *JSONReader) ReadBool() (bool, error) {
+	switch r.peek() {
+	case 't':
+		if bytes.HasPrefix(r.data[r.pos:], []byte("true")) {
+			r.pos += len("true")
+			return true, nil
+		}
+	case 'f':
+		if bytes.HasPrefix(r.data[r.pos:], []byte("false")) {
+			r.pos += len("false")
+			return false, nil
+		}
+	}
+	return false, r.unexpected("boolean")
+}
+
+// ReadInt reads a number that fits a signed integer of the given bit size
+func (r *JSONReader) ReadInt(bits int) (int64, error) {
+	num, err := r.readNumber()
+	if err != nil {
+		return 0, err
+	}
+	neg := num[0] == '-'
+	digits := num
+	if neg {
+		digits = num[1:]
+	}
+	u, ok := parseJSONDigits(digits)
+	limit := uint64(1)<<(bits-1) - 1
+	if neg {
+		limit++
+	}
+	if !ok || u > limit {
+		return 0, fmt.Errorf("cannot unmarshal number %s into int%d", num, bits)
+	}
+	if neg {
+		return -int64(u), nil
+	}
+	return int64(u), nil
+}
+
+// ReadUint reads a number that fits an unsigned integer of the given bit size
+func (r *JSONReader) ReadUint(bits int) (uint64, error) {
+	num, err := r.readNumber()
+	if err != nil {
+		return 0, err
+	}
+	u, ok := parseJSONDigits(num)
+	if !ok || bits < 64 && u >= 1<<bits {
+		return 0, fmt.Errorf("cannot unmarshal number %s into uint%d", num, bits)
+	}
+	return u, nil
+}
+
+// ReadFloat reads a number as a float of the given bit size
+func (r *JSONReader) ReadFloat(bits int) (float64, error) {
+	num, err := r.readNumber()
+	if err != nil {
+		return 0, err
+	}
+	f, err := strconv.ParseFloat(string(num), bits)
+	if err != nil {
+		return 0, fmt.Errorf("cannot unmarshal number %s into float%d", num, bits)
+	}
+	return f, nil
+}
+
+// ReadValue decodes the next value into v with encoding/json
+func (r *JSONReader) ReadValue(v any) error {
+	r.skipSpace()
+	start := r.pos
+	if err := r.Skip(); err != nil {
+		return err
+	}
+	return stdjson.Unmarshal(r.data[start:r.pos], v)
+}
+
+// Skip reads and discards the next value
+func (r *JSONReader) Skip() error {
+	switch r.peek() {
+	case '{':
+		return r.readMembers(func([]byte) error { return r.Skip() })
+	case '[':
+		return r.ReadArray(r.Skip)
+	case '"':
+		_, err := r.readString()
+		return err
+	case 't', 'f':
+		_, err := r.ReadBool()
+		return err
+	case 'n':
+		if r.ReadNull() {
+			return nil
+		}
+		return r.unexpected("value")
+	}
+	_, err := r.readNumber()
+	return err
+}
+
+func (r *JSONReader) readMembers(member func(key []byte) error) error {
+	if r.ReadNull() {
+		return nil
+	}
+	if r.peek() != '{' {
+		return r.unexpected("object")
+	}
+	r.pos++
+	if err := r.enter(); err != nil {
+		return err
+	}
+	defer r.leave()
+
+	if r.peek() == '}' {
+		r.pos++
+		return nil
+	}
+	for {
+		if r.peek() != '"' {
+			return r.unexpected("object key")
+		}
+		key, err := r.readString()
+		if err != nil {
+			return err
+		}
+		if r.peek() != ':' {
+			return r.unexpected("':'")
+		}
+		r.pos++
+		if err := member(key); err != nil {
+			return err
+		}
+		switch r.peek() {
+		case ',':
+			r.pos++
+		case '}':
+			r.pos++
+			return nil
+		default:
+			return r.unexpected("',' or '}'")
+		}
+	}
+}
+
+func (r *JSONReader) enter() error {
+	r.depth++
+	if r.depth > maxJSONReaderDepth {
+		return r.errorf("exceeded max depth")
+	}
+	return nil
+}
+
+func (r *JSONReader) leave() {
+	r.depth--
+}
+
+// readString reads the string starting at r.pos. The result aliases the input or
+// the reader's scratch buffer and is only valid until the next read.
+func (r *JSONReader) readString() ([]byte, error) {
+	start := r.pos + 1
+	ascii := true
+	for i := start; i < len(r.data); i++ {
+		switch c := r.data[i]; {
+		case c == '"':
+			if !ascii && !utf8.Valid(r.data[start:i]) {
+				return r.unescape(start, start)
+			}
+			r.pos = i + 1
+			return r.data[start:i], nil
+		case c == '\\' || c < 0x20:
+			return r.unescape(start, i)
+		case c >= utf8.RuneSelf:
+			ascii = false
+		}
+	}
+	r.pos = len(r.data)
+	return nil, r.errorf("unexpected end of input in string")
+}
+
+// unescape decodes the rest of a string whose plain prefix ends at i, replacing
+// invalid UTF-8 and unpaired surrogates with U+FFFD as encoding/json does
+func (r *JSONReader) unescape(start, i int) ([]byte, error) {
+	buf := append(r.scratch[:0], r.data[start:i]...)
+	for i < len(r.data) {
+		c := r.data[i]
+		switch {
+		case c == '"':
+			r.pos = i + 1
+			r.scratch = buf
+			return buf, nil
+		case c == '\\':
+			if i+1 >= len(r.data) {
+				i = len(r.data)
+				continue
+			}
+			switch e := r.data[i+1]; e {
+			case '"', '\\', '/':
+				buf = append(buf, e)
+			case 'b':
+				buf = append(buf, '\b')
+			case 'f':
+				buf = append(buf, '\f')
+			case 'n':
+				buf = append(buf, '\n')
+			case 'r':
+				buf = append(buf, '\r')
+			case 't':
+				buf = append(buf, '\t')
+			case 'u':
+				rr, ok := parseJSONHex4(r.data[i+2:])
+				if !ok {
+					r.pos = i
+					return nil, r.errorf("invalid escape in string")
+				}
+				i += 6
+				if utf16.IsSurrogate(rr) {
+					if bytes.HasPrefix(r.data[i:], []byte(`\u`)) {
+						low, ok := parseJSONHex4(r.data[i+2:])
+						if dec := utf16.DecodeRune(rr, low); ok && dec != utf8.RuneError {
+							buf = utf8.AppendRune(buf, dec)
+							i += 6
+							continue
+						}
+					}
+					rr = utf8.RuneError
+				}
+				buf = utf8.AppendRune(buf, rr)
+				continue
+			default:
+				r.pos = i
+				return nil, r.errorf("invalid escape in string")
+			}
+			i += 2
+		case c < 0x20:
+			r.pos = i
+			return nil, r.errorf("control character in string")
+		case c < utf8.RuneSelf:
+			buf = append(buf, c)
+			i++
+		default:
+			rr, size := utf8.DecodeRune(r.data[i:])
+			if rr == utf8.RuneError && size == 1 {
+				buf = utf8.AppendRune(buf, utf8.RuneError)
+			} else {
+				buf = append(buf, r.data[i:i+size]...)
+			}
+			i += size
+		}
+	}
+	r.pos = len(r.data)
+	return nil, r.errorf("unexpected end of input in string")
+}
+
+// readNumber reads a number token, checking it against the JSON grammar
+func (r *JSONReader) readNumber() ([]byte, error) {
+	r.skipSpace()
+	start, i, n := r.pos, r.pos, len(r.data)
+	digits := func() bool {
+		begin := i
+		for i < n && r.data[i] >= '0' && r.data[i] <= '9' {
+			i++
+		}
+		return i > begin
+	}
+	if i < n && r.data[i] == '-' {
+		i++
+	}
+	switch {
+	case i < n && r.data[i] == '0':
+		i++
+	case !digits():
+		r.pos = i
+		return nil, r.unexpected("number")
+	}
+	if i < n && r.data[i] == '.' {
+		i++
+		if !digits() {
+			r.pos = i
+			return nil, r.unexpected("digit")
+		}
+	}
+	if i < n && (r.data[i] == 'e' || r.data[i] == 'E') {
+		i++
+		if i < n && (r.data[i] == '+' || r.data[i] == '-') {
+			i++
+		}
+		if !digits() {
+			r.pos = i
+			return nil, r.unexpected("digit")
+		}
+	}
+	r.pos = i
+	return r.data[start:i], nil
+}
+
+func (r *JSONReader) skipSpace() {
+	for r.pos < len(r.data) {
+		switch r.data[r.pos] {
+		case ' ', '\t', '\n', '\r':
+			r.pos++
+		default:
+			return
+		}
+	}
+}
+
+// peek skips whitespace and returns the next byte, or 0 at the end of input
+func (r *JSONReader) peek() byte {
+	r.skipSpace()
+	if r.pos < len(r.data) {
+		return r.data[r.pos]
+	}
+	return 0
+}
+
+func (r *JSONReader) unexpected(want string) error {
+	if r.pos >= len(r.data) {
+		return r.errorf("unexpected end of input, expected %s", want)
+	}
+	return r.errorf("unexpected %q, expected %s", r.data[r.pos], want)
+}
+
+func (r *JSONReader) errorf(format string, args ...any) error {
+	return fmt.Errorf("invalid JSON at offset %d: %s", r.pos, fmt.Sprintf(format, args...))
+}
+
+// matchJSONField returns the index of key in fields, or -1
+func matchJSONField(fields []string, key []byte) int {
+	for i, field := range fields {
+		if field == string(key) {
+			return i
+		}
+	}
+	for i, field := range fields {
+		if bytes.EqualFold([]byte(field), key) {
+			return i
+		}
+	}
+	return -1
+}
+
+// parseJSONDigits parses an unsigned integer, failing on fractions, exponents and overflow
+func parseJSONDigits(num []byte) (uint64, bool) {
+	var u uint64
+	for _, c := range num {
+		if c < '0' || c > '9' {
+			return 0, false
+		}
+		d := uint64(c - '0')
+		if u > (1<<64-1-d)/10 {
+			return 0, false
+		}
+		u = u*10 + d
+	}
+	return u, len(num) > 0
+}
+
+// parseJSONHex4 parses the four hex digits of a \u escape
+func parseJSONHex4(b []byte) (rune, bool) {
+	if len(b) < 4 {
+		return 0, false
+	}
+	var r rune
+	for _, c := range b[:4] {
+		switch {
+		case c >= '0' && c <= '9':
+			c -= '0'
+		case c >= 'a' && c <= 'f':
+			c = c - 'a' + 10
+		case c >= 'A' && c <= 'F':
+			c = c - 'A' + 10
+		default:
+			return 0, false
+		}
+		r = r<<4 | rune(c)
+	}
+	return r, true
+}
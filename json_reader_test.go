@@ -0,0 +1,95 @@
+package serializer
+
+import (
+	stdjson "encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestJSONReaderStringsMatchStdlib(t *testing.T) {
+	inputs := []string{
+		`""`,
+		`"plain"`,
+		`"café 😀 \n\t\"\\\/"`,
+		`"lone \ud800 surrogate"`,
+		`"swapped \udc00\ud800"`,
+		"\"invalid \xff utf-8\"",
+		`"é€😀"`,
+	}
+	for _, in := range inputs {
+		var want string
+		if err := stdjson.Unmarshal([]byte(in), &want); err != nil {
+			t.Fatalf("encoding/json rejected %s: %v", in, err)
+		}
+		r := NewJSONReader([]byte(in))
+		got, err := r.ReadString()
+		if err == nil {
+			err = r.End()
+		}
+		if err != nil || got != want {
+			t.Errorf("ReadString(%s) = %q, %v; want %q", in, got, err, want)
+		}
+	}
+
+	for _, in := range []string{`"`, `"\u12"`, `"\q"`, "\"tab\t\"", `'x'`} {
+		if _, err := NewJSONReader([]byte(in)).ReadString(); err == nil {
+			t.Errorf("ReadString(%s) succeeded", in)
+		}
+	}
+}
+
+func TestJSONReaderNumbers(t *testing.T) {
+	ints := map[string]bool{
+		"0": true, "-0": true, "9223372036854775807": true, "-9223372036854775808": true,
+		"9223372036854775808": false, "1.0": false, "1e2": false, "01": false, "-": false, "+1": false,
+	}
+	for in, ok := range ints {
+		r := NewJSONReader([]byte(in))
+		_, err := r.ReadInt(64)
+		if err == nil {
+			err = r.End()
+		}
+		if (err == nil) != ok {
+			t.Errorf("ReadInt(%s) error = %v, want ok %v", in, err, ok)
+		}
+	}
+	if _, err := NewJSONReader([]byte("128")).ReadInt(8); err == nil {
+		t.Error("ReadInt(8) accepted 128")
+	}
+	if _, err := NewJSONReader([]byte("256")).ReadUint(8); err == nil {
+		t.Error("ReadUint(8) accepted 256")
+	}
+	if f, err := NewJSONReader([]byte("-1.5E+2")).ReadFloat(64); err != nil || f != -150 {
+		t.Errorf("ReadFloat = %v, %v", f, err)
+	}
+	if _, err := NewJSONReader([]byte("1.}")).ReadFloat(64); err == nil {
+		t.Error("ReadFloat accepted 1.")
+	}
+}
+
+func TestJSONReaderSkipDepthLimit(t *testing.T) {
+	deep := strings.Repeat("[", maxJSONReaderDepth+1) + strings.Repeat("]", maxJSONReaderDepth+1)
+	if err := NewJSONReader([]byte(deep)).Skip(); err == nil {
+		t.Error("Skip accepted input nested past the depth limit")
+	}
+	ok := strings.Repeat("[", 100) + strings.Repeat("]", 100)
+	if err := NewJSONReader([]byte(ok)).Skip(); err != nil {
+		t.Errorf("Skip failed: %v", err)
+	}
+}
+
+func TestJSONReaderObjectFields(t *testing.T) {
+	r := NewJSONReader([]byte(`{"b":2,"A":1,"skip":{"x":[1,"}"]},"a":3}`))
+	var got []int
+	err := r.ReadObject([]string{"a", "b"}, func(field int) error {
+		n, err := r.ReadInt(64)
+		got = append(got, field*10+int(n))
+		return err
+	})
+	if err != nil {
+		t.Fatalf("ReadObject failed: %v", err)
+	}
+	if want := []int{12, 1, 3}; len(got) != 3 || got[0] != want[0] || got[1] != want[1] || got[2] != want[2] {
+		t.Errorf("fields = %v, want %v", got, want)
+	}
+}
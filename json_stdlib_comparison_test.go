@@ -220,81 +220,6 @@ func TestJsoniterSpecificFeatures(t *testing.T) {
 	}
 }
 
-// TestJsoniterErrorMessages tests error message consistency
-func TestJsoniterErrorMessages(t *testing.T) {
-	s := NewJSONSerializer(1024)
-
-	malformedCases := []struct {
-		name    string
-		input   string
-		checkError func(error) bool
-	}{
-		{
-			name:  "UnterminatedString",
-			input: `{"key": "unterminated`,
-			checkError: func(err error) bool {
-				return err != nil && strings.Contains(strings.ToLower(err.Error()), "string")
-			},
-		},
-		{
-			name:  "UnterminatedObject",
-			input: `{"key": "value"`,
-			checkError: func(err error) bool {
-				return err != nil // Any error is acceptable
-			},
-		},
-		{
-			name:  "InvalidNumber",
-			input: `{"key": 123.456.789}`,
-			checkError: func(err error) bool {
-				return err != nil
-			},
-		},
-		{
-			name:  "TrailingComma",
-			input: `{"key": "value",}`,
-			checkError: func(err error) bool {
-				return err != nil
-			},
-		},
-	}
-
-	for _, tc := range malformedCases {
-		t.Run(tc.name, func(t *testing.T) {
-			var result interface{}
-			jsoniterErr := s.Deserialize([]byte(tc.input), &result)
-
-			// Also test with standard library for comparison
-			var stdResult interface{}
-			stdlibErr := stdjson.Unmarshal([]byte(tc.input), &stdResult)
-
-			// Both should produce errors
-			if jsoniterErr == nil {
-				t.Errorf("Jsoniter should have produced an error for input: %s", tc.input)
-			}
-
-			if stdlibErr == nil {
-				t.Errorf("Standard library should have produced an error for input: %s", tc.input)
-			}
-
-			// Check error using provided function
-			if jsoniterErr != nil && !tc.checkError(jsoniterErr) {
-				t.Errorf("Jsoniter error doesn't match expected pattern: %v", jsoniterErr)
-			}
-
-			if stdlibErr != nil && !tc.checkError(stdlibErr) {
-				t.Logf("Stdlib error: %v", stdlibErr)
-			}
-
-			// Log both errors for comparison
-			if jsoniterErr != nil && stdlibErr != nil {
-				t.Logf("Jsoniter error: %v", jsoniterErr)
-				t.Logf("Stdlib error: %v", stdlibErr)
-			}
-		})
-	}
-}
-
 // TestJsoniterSpecialFloatHandling tests handling of special float values
 func TestJsoniterSpecialFloatHandling(t *testing.T) {
 	s := NewJSONSerializer(1024)
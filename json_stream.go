@@ -0,0 +1,96 @@
+package serializer
+
+import (
+	stdjson "encoding/json"
+	"errors"
+	"io"
+)
+
+var (
+	_ StreamingSerializer = (*JSONSerializer)(nil)
+	_ StreamEncoder       = (*JSONStreamEncoder)(nil)
+	_ StreamDecoder       = (*JSONStreamDecoder)(nil)
+)
+
+// JSONStreamEncoder writes a sequence of JSON values to one stream, each followed
+// by a newline. A JSONStreamEncoder is not safe for concurrent use.
+type JSONStreamEncoder struct {
+	s *JSONSerializer
+	w io.Writer
+}
+
+// NewStreamEncoder implements StreamingSerializer with a *JSONStreamEncoder
+// writing to w with this serializer's settings
+func (s *JSONSerializer) NewStreamEncoder(w io.Writer) StreamEncoder {
+	return &JSONStreamEncoder{s: s, w: w}
+}
+
+// Encode writes v to the stream. Values are separated by a newline even when
+// WithTrailingNewline(false) is set, as back-to-back numbers would otherwise merge.
+func (e *JSONStreamEncoder) Encode(v any) error {
+	if e.w == nil {
+		return errors.New("writer is nil")
+	}
+	err := e.s.engine.encode(e.w, v)
+	if err == nil && e.s.opts.jsonOmitNewline {
+		_, err = e.w.Write([]byte{'\n'})
+	}
+	e.s.opts.logFailure("stream_encode", err)
+	return err
+}
+
+// Close implements StreamEncoder. Values are not buffered, so there is nothing to flush.
+func (e *JSONStreamEncoder) Close() error {
+	return nil
+}
+
+// JSONStreamDecoder reads a sequence of whitespace-separated JSON values from one
+// stream with a single decoder. It reads ahead of the value being decoded, so
+// nothing else should read from the stream. A JSONStreamDecoder is not safe for
+// concurrent use.
+type JSONStreamDecoder struct {
+	s       *JSONSerializer
+	dec     *stdjson.Decoder
+	readErr func(error) error
+	raw     stdjson.RawMessage
+}
+
+// NewStreamDecoder implements StreamingSerializer with a *JSONStreamDecoder
+// reading from r with this serializer's settings
+func (s *JSONSerializer) NewStreamDecoder(r io.Reader) StreamDecoder {
+	if r == nil {
+		return &JSONStreamDecoder{s: s}
+	}
+	stream, readErr := s.streamReader(r)
+	if s.opts.lenientJSON() {
+		stream = &jsonFilterReader{r: stream, f: s.opts.newJSONFilter()}
+	}
+	return &JSONStreamDecoder{s: s, dec: stdjson.NewDecoder(stream), readErr: readErr}
+}
+
+// Decode reads the next value into v using the serializer's backend. It returns
+// io.EOF when the stream ends cleanly between values.
+func (d *JSONStreamDecoder) Decode(v any) error {
+	if d.dec == nil {
+		return errors.New("reader is nil")
+	}
+	d.raw = d.raw[:0]
+	err := d.readErr(d.dec.Decode(&d.raw))
+	if err == nil {
+		err = d.s.engine.unmarshal(d.raw, v)
+	}
+	if err != io.EOF {
+		d.s.opts.logFailure("stream_decode", err)
+	}
+	return err
+}
+
+// More implements StreamDecoder
+func (d *JSONStreamDecoder) More() bool {
+	return d.dec != nil && d.dec.More()
+}
+
+// Close implements StreamDecoder
+func (d *JSONStreamDecoder) Close() error {
+	return nil
+}
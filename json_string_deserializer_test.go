@@ -261,50 +261,6 @@ func TestVeryLongStringDeserialization(t *testing.T) {
 	}
 }
 
-// TestBinaryDataInString tests handling of binary data within JSON strings
-func TestBinaryDataInString(t *testing.T) {
-	s := NewJSONSerializer(1024)
-	stringDeser := s.(StringDeserializer)
-
-	// Create data with binary content (base64 encoded in JSON)
-	binaryData := []byte{0, 1, 2, 3, 255, 254, 253, 10, 13, 9}
-	
-	testData := map[string]interface{}{
-		"text":   "normal text",
-		"binary": string(binaryData), // This will be JSON-escaped
-		"number": 42,
-	}
-
-	// Serialize
-	serialized, err := s.Serialize(testData)
-	if err != nil {
-		t.Fatalf("Serialize failed: %v", err)
-	}
-
-	serializedStr := string(serialized)
-
-	// Deserialize using string method
-	var stringResult map[string]interface{}
-	if err := stringDeser.DeserializeString(serializedStr, &stringResult); err != nil {
-		t.Fatalf("DeserializeString failed: %v", err)
-	}
-
-	// Verify the binary data survived the round trip
-	if stringResult["binary"] != string(binaryData) {
-		t.Error("Binary data was corrupted during deserialization")
-	}
-
-	// Compare with bytes deserialization
-	var bytesResult map[string]interface{}
-	if err := s.Deserialize(serialized, &bytesResult); err != nil {
-		t.Fatalf("Deserialize failed: %v", err)
-	}
-
-	if stringResult["binary"] != bytesResult["binary"] {
-		t.Error("String and bytes deserialization produced different binary data")
-	}
-}
-
 // TestStringDeserializerConcurrency tests concurrent usage of string deserialization
 func TestStringDeserializerConcurrency(t *testing.T) {
 	s := NewJSONSerializer(4096)
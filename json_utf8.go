@@ -0,0 +1,60 @@
+package serializer
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"unicode/utf8"
+)
+
+// ErrInvalidUTF8 is returned when encoding a string holding invalid UTF-8 under InvalidUTF8Reject
+var ErrInvalidUTF8 = errors.New("JSON string contains invalid UTF-8")
+
+// utf8Engine checks the output of a backend that passes invalid UTF-8 through.
+// Outside strings JSON output is plain ASCII, so any invalid byte belongs to a string.
+type utf8Engine struct {
+	jsonEngine
+	policy InvalidUTF8Policy
+}
+
+// withInvalidUTF8 wraps a pass-through engine to apply the configured policy
+func withInvalidUTF8(engine jsonEngine, o *options) jsonEngine {
+	if o.jsonInvalidUTF8 == nil || *o.jsonInvalidUTF8 == InvalidUTF8PassThrough {
+		return engine
+	}
+	return utf8Engine{jsonEngine: engine, policy: *o.jsonInvalidUTF8}
+}
+
+func (e utf8Engine) encode(w io.Writer, v any) error {
+	return encodeBuffered(w, func(buf *bytes.Buffer) error {
+		start := buf.Len()
+		if err := e.jsonEngine.encode(buf, v); err != nil {
+			return err
+		}
+		if out := buf.Bytes()[start:]; !utf8.Valid(out) {
+			if e.policy == InvalidUTF8Reject {
+				buf.Truncate(start)
+				return ErrInvalidUTF8
+			}
+			fixed := replaceInvalidUTF8(out)
+			buf.Truncate(start)
+			buf.Write(fixed)
+		}
+		return nil
+	})
+}
+
+// replaceInvalidUTF8 returns a copy of data with each invalid byte escaped as \ufffd
+func replaceInvalidUTF8(data []byte) []byte {
+	out := make([]byte, 0, len(data)+16)
+	for len(data) > 0 {
+		r, size := utf8.DecodeRune(data)
+		if r == utf8.RuneError && size == 1 {
+			out = append(out, `\ufffd`...)
+		} else {
+			out = append(out, data[:size]...)
+		}
+		data = data[size:]
+	}
+	return out
+}
@@ -0,0 +1,46 @@
+//go:build goexperiment.jsonv2 && go1.27
+
+package serializer
+
+import (
+	"encoding/json/jsontext"
+	"io"
+
+	jsonv2 "encoding/json/v2"
+)
+
+func init() {
+	jsonEngines[JSONBackendV2] = func(o *options) jsonEngine {
+		e := jsonV2Engine{escapeHTML: o.jsonEscapeHTML, deterministic: o.sortMapKeys}
+		if o.jsonInvalidUTF8 != nil {
+			e.allowInvalidUTF8 = *o.jsonInvalidUTF8 != InvalidUTF8Reject
+		}
+		if o.jsonCaseSensitive != nil {
+			e.unmarshalOpts = append(e.unmarshalOpts, jsonv2.MatchCaseInsensitiveNames(!*o.jsonCaseSensitive))
+		}
+		return e
+	}
+}
+
+// jsonV2Engine uses encoding/json/v2 with its default (v2) semantics unless an
+// option overrides them, so differences from the jsoniter backend surface while
+// migrating
+type jsonV2Engine struct {
+	escapeHTML       bool
+	deterministic    bool
+	allowInvalidUTF8 bool
+	unmarshalOpts    []jsonv2.Options
+}
+
+func (e jsonV2Engine) encode(w io.Writer, v any) error {
+	// jsontext.Encoder terminates each top-level value with a newline
+	return jsonv2.MarshalEncode(jsontext.NewEncoder(w, jsontext.EscapeForHTML(e.escapeHTML), jsontext.AllowInvalidUTF8(e.allowInvalidUTF8)), v, jsonv2.Deterministic(e.deterministic))
+}
+
+func (e jsonV2Engine) unmarshal(data []byte, v any) error {
+	return jsonv2.Unmarshal(data, v, e.unmarshalOpts...)
+}
+
+func (e jsonV2Engine) decode(r io.Reader, v any) error {
+	return jsonv2.UnmarshalDecode(jsontext.NewDecoder(r), v, e.unmarshalOpts...)
+}
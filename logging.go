@@ -0,0 +1,48 @@
+package serializer
+
+import (
+	"context"
+	"log/slog"
+)
+
+// bindLogger scopes the configured logger to a format so every record can be attributed
+func (o *options) bindLogger(format Format) {
+	if o.logger != nil {
+		o.logger = o.logger.With(slog.String("format", string(format)))
+	}
+}
+
+// logFailure records a failed operation
+func (o *options) logFailure(op string, err error) {
+	if o.logger == nil || err == nil {
+		return
+	}
+	o.logger.LogAttrs(context.Background(), slog.LevelDebug, "serialization failed",
+		slog.String("op", op),
+		slog.Any("error", err),
+	)
+}
+
+// logSize records a payload that exceeds the oversize threshold
+func (o *options) logSize(op string, size int) {
+	if o.logger == nil || o.oversizeThreshold <= 0 || size <= o.oversizeThreshold {
+		return
+	}
+	o.logger.LogAttrs(context.Background(), slog.LevelDebug, "oversized payload",
+		slog.String("op", op),
+		slog.Int("size", size),
+		slog.Int("threshold", o.oversizeThreshold),
+	)
+}
+
+// logPoolDiscard records a buffer dropped from a pool because it grew too large
+func (o *options) logPoolDiscard(pool string, capacity, limit int) {
+	if o.logger == nil {
+		return
+	}
+	o.logger.LogAttrs(context.Background(), slog.LevelDebug, "pool buffer discarded",
+		slog.String("pool", pool),
+		slog.Int("capacity", capacity),
+		slog.Int("limit", limit),
+	)
+}
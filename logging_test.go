@@ -0,0 +1,71 @@
+package serializer
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func newDebugLogger(buf *bytes.Buffer) *slog.Logger {
+	return slog.New(slog.NewTextHandler(buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+}
+
+func TestLoggingFailures(t *testing.T) {
+	var logs bytes.Buffer
+	logger := newDebugLogger(&logs)
+
+	serializers := []Serializer{
+		NewJSONSerializer(maxBufferSize, WithLogger(logger)),
+		NewMsgpackSerializer(WithLogger(logger)),
+	}
+	for _, s := range serializers {
+		logs.Reset()
+		var v map[string]any
+		if err := s.Deserialize([]byte{0xc1, 0xff}, &v); err == nil {
+			t.Fatalf("%s: expected deserialize error", s.ContentType())
+		}
+		out := logs.String()
+		if !strings.Contains(out, "serialization failed") || !strings.Contains(out, "op=deserialize") {
+			t.Errorf("%s: failure not logged: %q", s.ContentType(), out)
+		}
+	}
+}
+
+func TestLoggingOversizedPayloads(t *testing.T) {
+	var logs bytes.Buffer
+	s := NewMsgpackSerializer(WithLogger(newDebugLogger(&logs)), WithOversizeThreshold(16))
+
+	if _, err := s.Serialize("short"); err != nil {
+		t.Fatalf("Serialize failed: %v", err)
+	}
+	if logs.Len() != 0 {
+		t.Errorf("unexpected log for small payload: %q", logs.String())
+	}
+
+	if _, err := s.Serialize(strings.Repeat("x", 64)); err != nil {
+		t.Fatalf("Serialize failed: %v", err)
+	}
+	if !strings.Contains(logs.String(), "oversized payload") || !strings.Contains(logs.String(), "format=msgpack") {
+		t.Errorf("oversized payload not logged: %q", logs.String())
+	}
+}
+
+func TestLoggingPoolDiscards(t *testing.T) {
+	var logs bytes.Buffer
+	s := NewJSONSerializer(64, WithLogger(newDebugLogger(&logs)))
+
+	if _, err := s.Serialize(strings.Repeat("x", 1024)); err != nil {
+		t.Fatalf("Serialize failed: %v", err)
+	}
+	if !strings.Contains(logs.String(), "pool buffer discarded") {
+		t.Errorf("pool discard not logged: %q", logs.String())
+	}
+}
+
+func TestLoggingDisabledByDefault(t *testing.T) {
+	s := NewJSONSerializer(maxBufferSize)
+	var v any
+	// Must not panic without a logger
+	_ = s.Deserialize([]byte("{"), &v)
+}
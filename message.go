@@ -0,0 +1,109 @@
+package serializer
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+
+	"github.com/MichaelAJay/go-serializer/internal/contenttype"
+)
+
+// Message is an envelope for queues carrying heterogeneous messages.
+// Consumers route on Type and decode Payload with the serializer matching ContentType.
+type Message struct {
+	Type          string `json:"type" msgpack:"type"`
+	ContentType   string `json:"content_type" msgpack:"content_type"`
+	SchemaVersion int    `json:"schema_version" msgpack:"schema_version"`
+	Payload       []byte `json:"payload" msgpack:"payload"`
+}
+
+// MessageCodec encodes values into Message envelopes and decodes them back into
+// the Go type registered for the message's type name.
+// The envelope and payload are both serialized with the codec's serializer; payloads
+// produced with a different content type are decoded with the DefaultRegistry serializer
+// for that content type.
+type MessageCodec struct {
+	s     Serializer
+	types *TypeRegistry
+}
+
+// NewMessageCodec creates a message codec.
+// If types is nil, DefaultTypeRegistry is used.
+func NewMessageCodec(s Serializer, types *TypeRegistry) *MessageCodec {
+	if types == nil {
+		types = DefaultTypeRegistry
+	}
+	return &MessageCodec{s: s, types: types}
+}
+
+// Encode wraps v in a Message tagged with its registered type name and serializes the envelope
+func (c *MessageCodec) Encode(v any, schemaVersion int) ([]byte, error) {
+	if v == nil {
+		return nil, errors.New("cannot serialize nil value")
+	}
+	name, ok := c.types.NameOf(v)
+	if !ok {
+		return nil, fmt.Errorf("%w: %T", ErrUnknownType, v)
+	}
+
+	payload, err := c.s.Serialize(v)
+	if err != nil {
+		return nil, fmt.Errorf("message %q: %w", name, err)
+	}
+	return c.s.Serialize(&Message{
+		Type:          name,
+		ContentType:   c.s.ContentType(),
+		SchemaVersion: schemaVersion,
+		Payload:       payload,
+	})
+}
+
+// DecodeMessage decodes only the envelope, leaving the payload undecoded.
+// Use it to route or filter messages before paying for payload decoding.
+func (c *MessageCodec) DecodeMessage(data []byte) (*Message, error) {
+	var msg Message
+	if err := c.s.Deserialize(data, &msg); err != nil {
+		return nil, fmt.Errorf("invalid message envelope: %w", err)
+	}
+	if msg.Type == "" {
+		return nil, errors.New("message envelope has no type")
+	}
+	return &msg, nil
+}
+
+// DecodePayload deserializes the message payload into v
+func (c *MessageCodec) DecodePayload(msg *Message, v any) error {
+	s, err := c.payloadSerializer(msg.ContentType)
+	if err != nil {
+		return err
+	}
+	return s.Deserialize(msg.Payload, v)
+}
+
+// Decode decodes the envelope and its payload into a new value of the registered type.
+// The returned value has the registered type itself, not a pointer to it.
+func (c *MessageCodec) Decode(data []byte) (any, *Message, error) {
+	msg, err := c.DecodeMessage(data)
+	if err != nil {
+		return nil, nil, err
+	}
+	target, err := c.types.New(msg.Type)
+	if err != nil {
+		return nil, msg, err
+	}
+	if err := c.DecodePayload(msg, target); err != nil {
+		return nil, msg, fmt.Errorf("message %q: %w", msg.Type, err)
+	}
+	return reflect.ValueOf(target).Elem().Interface(), msg, nil
+}
+
+// payloadSerializer resolves the serializer for a payload content type
+func (c *MessageCodec) payloadSerializer(contentType string) (Serializer, error) {
+	if contentType == "" || contenttype.MediaType(contentType) == contenttype.MediaType(c.s.ContentType()) {
+		return c.s, nil
+	}
+	if s, ok := DefaultRegistry.GetByContentType(contentType); ok {
+		return s, nil
+	}
+	return nil, fmt.Errorf("no serializer registered for content type %q", contentType)
+}
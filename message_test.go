@@ -0,0 +1,87 @@
+package serializer
+
+import (
+	"errors"
+	"testing"
+)
+
+type userSignedUp struct {
+	UserID string `json:"user_id" msgpack:"user_id"`
+}
+
+type orderPlaced struct {
+	OrderID string `json:"order_id" msgpack:"order_id"`
+	Amount  int    `json:"amount" msgpack:"amount"`
+}
+
+func TestMessageCodecRoutesTypes(t *testing.T) {
+	types := NewTypeRegistry()
+	types.MustRegister("user.signed_up", userSignedUp{})
+	types.MustRegister("order.placed", orderPlaced{})
+
+	for _, s := range append([]Serializer{NewJSONSerializer(maxBufferSize), NewMsgpackSerializer()}, gobTestSerializers()...) {
+		t.Run(s.ContentType(), func(t *testing.T) {
+			codec := NewMessageCodec(s, types)
+
+			var queue [][]byte
+			for _, v := range []any{userSignedUp{UserID: "u1"}, &orderPlaced{OrderID: "o1", Amount: 5}} {
+				data, err := codec.Encode(v, 2)
+				if err != nil {
+					t.Fatalf("Encode failed: %v", err)
+				}
+				queue = append(queue, data)
+			}
+
+			first, msg, err := codec.Decode(queue[0])
+			if err != nil {
+				t.Fatalf("Decode failed: %v", err)
+			}
+			if got, ok := first.(userSignedUp); !ok || got.UserID != "u1" {
+				t.Errorf("unexpected first message %#v", first)
+			}
+			if msg.SchemaVersion != 2 || msg.ContentType != s.ContentType() {
+				t.Errorf("unexpected envelope metadata %+v", msg)
+			}
+
+			second, _, err := codec.Decode(queue[1])
+			if err != nil {
+				t.Fatalf("Decode failed: %v", err)
+			}
+			if got, ok := second.(orderPlaced); !ok || got.Amount != 5 {
+				t.Errorf("unexpected second message %#v", second)
+			}
+		})
+	}
+}
+
+func TestMessageCodecForeignContentType(t *testing.T) {
+	types := NewTypeRegistry()
+	types.MustRegister("user.signed_up", userSignedUp{})
+
+	payload, _ := NewMsgpackSerializer().Serialize(userSignedUp{UserID: "u2"})
+	envelope, _ := NewJSONSerializer(maxBufferSize).Serialize(Message{
+		Type:        "user.signed_up",
+		ContentType: "application/x-msgpack",
+		Payload:     payload,
+	})
+
+	v, _, err := NewMessageCodec(NewJSONSerializer(maxBufferSize), types).Decode(envelope)
+	if err != nil {
+		t.Fatalf("Decode failed: %v", err)
+	}
+	if got := v.(userSignedUp); got.UserID != "u2" {
+		t.Errorf("unexpected message %#v", got)
+	}
+}
+
+func TestMessageCodecUnknownType(t *testing.T) {
+	codec := NewMessageCodec(NewMsgpackSerializer(), NewTypeRegistry())
+	if _, err := codec.Encode(userSignedUp{}, 1); !errors.Is(err, ErrUnknownType) {
+		t.Errorf("expected ErrUnknownType encoding unregistered type, got %v", err)
+	}
+
+	data, _ := NewMsgpackSerializer().Serialize(Message{Type: "nope"})
+	if _, _, err := codec.Decode(data); !errors.Is(err, ErrUnknownType) {
+		t.Errorf("expected ErrUnknownType decoding unregistered type, got %v", err)
+	}
+}
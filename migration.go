@@ -0,0 +1,226 @@
+package serializer
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"sync"
+)
+
+// ErrNoMigration is returned when a payload's schema version cannot be brought
+// up to date with the registered migrations
+var ErrNoMigration = errors.New("no migration path")
+
+// MigrateFunc upgrades a decoded payload in place. Numbers in doc have the types
+// the format decodes into interfaces, e.g. float64 for JSON.
+type MigrateFunc func(old map[string]any) error
+
+// migrationStep is a registered migration out of one version
+type migrationStep struct {
+	to int
+	fn MigrateFunc
+}
+
+// Migrations holds the functions that upgrade stored payloads from older schema
+// versions to the current one. It is safe for concurrent use.
+type Migrations struct {
+	mu      sync.RWMutex
+	current int
+	steps   map[int]migrationStep
+}
+
+// NewMigrations creates a migration registry for payloads whose current schema
+// version is current. Versions start at 1; version 0 stands for payloads written
+// without a version.
+func NewMigrations(current int) *Migrations {
+	return &Migrations{current: current, steps: make(map[int]migrationStep)}
+}
+
+// Current returns the schema version payloads are written with and upgraded to
+func (m *Migrations) Current() int {
+	return m.current
+}
+
+// Migrate registers fn to upgrade payloads of version from to version to. Steps
+// may skip versions, but only one step can start at each version.
+func (m *Migrations) Migrate(from, to int, fn MigrateFunc) error {
+	if fn == nil {
+		return fmt.Errorf("migration %d -> %d has no function", from, to)
+	}
+	if from < 0 || to <= from || to > m.current {
+		return fmt.Errorf("invalid migration %d -> %d with current version %d", from, to, m.current)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if existing, ok := m.steps[from]; ok {
+		return fmt.Errorf("migration from version %d already registered (to %d)", from, existing.to)
+	}
+	m.steps[from] = migrationStep{to: to, fn: fn}
+	return nil
+}
+
+// MustMigrate is like Migrate but panics on error
+func (m *Migrations) MustMigrate(from, to int, fn MigrateFunc) *Migrations {
+	if err := m.Migrate(from, to, fn); err != nil {
+		panic(err)
+	}
+	return m
+}
+
+// Upgrade applies the chain of migrations from version to the current version to doc
+func (m *Migrations) Upgrade(doc map[string]any, version int) error {
+	if version > m.current {
+		return fmt.Errorf("payload schema version %d is newer than %d", version, m.current)
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	for version < m.current {
+		step, ok := m.steps[version]
+		if !ok {
+			return fmt.Errorf("%w from schema version %d to %d", ErrNoMigration, version, m.current)
+		}
+		if err := step.fn(doc); err != nil {
+			return fmt.Errorf("migrate schema version %d -> %d: %w", version, step.to, err)
+		}
+		version = step.to
+	}
+	return nil
+}
+
+// NewMigratingSerializer wraps s so payloads carry the current schema version of
+// migrations, and payloads of older versions are upgraded while they are decoded.
+//
+// Values are written as {"schema_version": N, "payload": value}. When a payload's
+// version is current it is decoded straight into the target; otherwise it is decoded
+// into a map[string]any, passed through the migrations in turn, and decoded into the
+// target from there. Payloads without a schema_version field, written before
+// migrations were adopted, are treated as version 0 in their entirety.
+//
+// Migrating old payloads requires a format that decodes objects into maps, such as
+// JSON or MessagePack. DeserializeFrom reads r to the end.
+func NewMigratingSerializer(s Serializer, migrations *Migrations) Serializer {
+	return &migratingSerializer{inner: s, migrations: migrations}
+}
+
+// migratingSerializer is a Serializer decorator that versions and migrates payloads
+type migratingSerializer struct {
+	inner      Serializer
+	migrations *Migrations
+}
+
+// schemaHeader reads only the version of an enveloped payload
+type schemaHeader struct {
+	SchemaVersion int `json:"schema_version" msgpack:"schema_version"`
+}
+
+// schemaEnvelopes caches the envelope struct types built for payload types
+var schemaEnvelopes sync.Map // reflect.Type -> reflect.Type
+
+// schemaEnvelope returns struct{ SchemaVersion int; Payload payload } with the
+// field names the envelope is written with
+func schemaEnvelope(payload reflect.Type) reflect.Type {
+	if t, ok := schemaEnvelopes.Load(payload); ok {
+		return t.(reflect.Type)
+	}
+	t := reflect.StructOf([]reflect.StructField{
+		{Name: "SchemaVersion", Type: reflect.TypeFor[int](), Tag: `json:"schema_version" msgpack:"schema_version"`},
+		{Name: "Payload", Type: payload, Tag: `json:"payload" msgpack:"payload"`},
+	})
+	actual, _ := schemaEnvelopes.LoadOrStore(payload, t)
+	return actual.(reflect.Type)
+}
+
+// wrap puts v in an envelope carrying the current version
+func (s *migratingSerializer) wrap(v any) (any, error) {
+	if v == nil {
+		return nil, errors.New("cannot serialize nil value")
+	}
+	env := reflect.New(schemaEnvelope(reflect.TypeOf(v))).Elem()
+	env.Field(0).SetInt(int64(s.migrations.Current()))
+	env.Field(1).Set(reflect.ValueOf(v))
+	return env.Addr().Interface(), nil
+}
+
+func (s *migratingSerializer) Serialize(v any) ([]byte, error) {
+	env, err := s.wrap(v)
+	if err != nil {
+		return nil, err
+	}
+	return s.inner.Serialize(env)
+}
+
+func (s *migratingSerializer) SerializeTo(w io.Writer, v any) error {
+	env, err := s.wrap(v)
+	if err != nil {
+		return err
+	}
+	return s.inner.SerializeTo(w, env)
+}
+
+func (s *migratingSerializer) Deserialize(data []byte, v any) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return errors.New("target must be a non-nil pointer")
+	}
+
+	// A payload that is not an envelope, or whose envelope cannot be read, is
+	// treated as unversioned; decoding it below reports any real damage
+	var header schemaHeader
+	if err := s.inner.Deserialize(data, &header); err != nil {
+		header.SchemaVersion = 0
+	}
+
+	if header.SchemaVersion == s.migrations.Current() {
+		// Decode through a pointer to v so the payload fills it in place
+		env := reflect.New(schemaEnvelope(rv.Type()))
+		env.Elem().Field(1).Set(rv)
+		return s.inner.Deserialize(data, env.Interface())
+	}
+
+	var old any
+	if header.SchemaVersion == 0 {
+		if err := s.inner.Deserialize(data, &old); err != nil {
+			return err
+		}
+	} else {
+		var env struct {
+			Payload any `json:"payload" msgpack:"payload"`
+		}
+		if err := s.inner.Deserialize(data, &env); err != nil {
+			return err
+		}
+		old = env.Payload
+	}
+
+	if old != nil {
+		doc, ok := old.(map[string]any)
+		if !ok {
+			return fmt.Errorf("payload of schema version %d is %T, not an object", header.SchemaVersion, old)
+		}
+		if err := s.migrations.Upgrade(doc, header.SchemaVersion); err != nil {
+			return err
+		}
+	}
+	upgraded, err := s.inner.Serialize(old)
+	if err != nil {
+		return fmt.Errorf("re-encode migrated payload: %w", err)
+	}
+	return s.inner.Deserialize(upgraded, v)
+}
+
+func (s *migratingSerializer) DeserializeFrom(r io.Reader, v any) error {
+	if r == nil {
+		return errors.New("reader is nil")
+	}
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	return s.Deserialize(data, v)
+}
+
+func (s *migratingSerializer) ContentType() string {
+	return s.inner.ContentType()
+}
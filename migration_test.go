@@ -0,0 +1,131 @@
+package serializer
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// profileV3 is the current shape; v1 had "name", v2 split it into first/last and
+// v3 added a default plan
+type profileV3 struct {
+	First string `json:"first" msgpack:"first"`
+	Last  string `json:"last" msgpack:"last"`
+	Plan  string `json:"plan" msgpack:"plan"`
+}
+
+func profileMigrations() *Migrations {
+	return NewMigrations(3).
+		MustMigrate(0, 1, func(doc map[string]any) error {
+			doc["name"] = doc["full_name"]
+			delete(doc, "full_name")
+			return nil
+		}).
+		MustMigrate(1, 2, func(doc map[string]any) error {
+			name, _ := doc["name"].(string)
+			doc["first"], doc["last"], _ = strings.Cut(name, " ")
+			delete(doc, "name")
+			return nil
+		}).
+		MustMigrate(2, 3, func(doc map[string]any) error {
+			if _, ok := doc["plan"]; !ok {
+				doc["plan"] = "free"
+			}
+			return nil
+		})
+}
+
+func TestMigratingSerializer(t *testing.T) {
+	for _, inner := range []Serializer{NewJSONSerializer(0), NewMsgpackSerializer()} {
+		t.Run(inner.ContentType(), func(t *testing.T) {
+			s := NewMigratingSerializer(inner, profileMigrations())
+			want := profileV3{First: "Ada", Last: "Lovelace", Plan: "free"}
+
+			// Current payloads round-trip and carry the version
+			data, err := s.Serialize(profileV3{First: "Ada", Last: "Lovelace", Plan: "free"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			var header schemaHeader
+			if err := inner.Deserialize(data, &header); err != nil || header.SchemaVersion != 3 {
+				t.Fatalf("version not written: %+v, %v", header, err)
+			}
+			var got profileV3
+			if err := s.Deserialize(data, &got); err != nil || got != want {
+				t.Fatalf("current payload: got %+v, %v", got, err)
+			}
+
+			old := map[string][]byte{}
+			for name, v := range map[string]any{
+				"v1":          map[string]any{"schema_version": 1, "payload": map[string]any{"name": "Ada Lovelace"}},
+				"v2":          map[string]any{"schema_version": 2, "payload": map[string]any{"first": "Ada", "last": "Lovelace"}},
+				"unversioned": map[string]any{"full_name": "Ada Lovelace"},
+			} {
+				if old[name], err = inner.Serialize(v); err != nil {
+					t.Fatal(err)
+				}
+			}
+			for name, data := range old {
+				var got profileV3
+				if err := s.Deserialize(data, &got); err != nil || got != want {
+					t.Errorf("%s: got %+v, %v", name, got, err)
+				}
+			}
+
+			var buf bytes.Buffer
+			if err := s.SerializeTo(&buf, &want); err != nil {
+				t.Fatal(err)
+			}
+			got = profileV3{}
+			if err := s.DeserializeFrom(&buf, &got); err != nil || got != want {
+				t.Errorf("stream: got %+v, %v", got, err)
+			}
+		})
+	}
+}
+
+func TestMigrationErrors(t *testing.T) {
+	m := NewMigrations(3)
+	cases := []struct {
+		from, to int
+		fn       MigrateFunc
+	}{
+		{1, 2, nil},
+		{2, 2, func(map[string]any) error { return nil }},
+		{2, 4, func(map[string]any) error { return nil }},
+		{-1, 1, func(map[string]any) error { return nil }},
+	}
+	for _, tc := range cases {
+		if err := m.Migrate(tc.from, tc.to, tc.fn); err == nil {
+			t.Errorf("Migrate(%d, %d) succeeded", tc.from, tc.to)
+		}
+	}
+
+	errBad := errors.New("bad data")
+	m.MustMigrate(1, 3, func(map[string]any) error { return errBad })
+	if err := m.Migrate(1, 2, func(map[string]any) error { return nil }); err == nil {
+		t.Error("second migration out of version 1 was accepted")
+	}
+
+	s := NewMigratingSerializer(NewJSONSerializer(0), m)
+	var v map[string]any
+	if err := s.Deserialize([]byte(`{"schema_version":2,"payload":{}}`), &v); !errors.Is(err, ErrNoMigration) {
+		t.Errorf("missing step: got %v, want ErrNoMigration", err)
+	}
+	if err := s.Deserialize([]byte(`{"schema_version":1,"payload":{}}`), &v); !errors.Is(err, errBad) {
+		t.Errorf("failing step: got %v, want %v", err, errBad)
+	}
+	if err := s.Deserialize([]byte(`{"schema_version":4,"payload":{}}`), &v); err == nil || !strings.Contains(err.Error(), "newer") {
+		t.Errorf("newer version: got %v", err)
+	}
+	if err := s.Deserialize([]byte(`{"schema_version":1,"payload":[1]}`), &v); err == nil || !strings.Contains(err.Error(), "not an object") {
+		t.Errorf("non-object payload: got %v", err)
+	}
+	if err := s.Deserialize([]byte(`{"schema_version":3,"payload":{}}`), v); err == nil {
+		t.Error("non-pointer target was accepted")
+	}
+	if _, err := s.Serialize(nil); err == nil {
+		t.Error("nil value was accepted")
+	}
+}
@@ -3,7 +3,9 @@ package serializer
 import (
 	"bytes"
 	"errors"
+	"fmt"
 	"io"
+	"reflect"
 	"sync"
 
 	"github.com/vmihailenco/msgpack/v5"
@@ -21,38 +23,86 @@ type pooledEncoder struct {
 	buf *bytes.Buffer
 }
 
-// encoderPool is the global pool for reusing encoders and their buffers
-var encoderPool = sync.Pool{
-	New: func() any {
-		buf := &bytes.Buffer{}
+// encoderPool pools encoders and their buffers
+type encoderPool struct {
+	pool sync.Pool
+	// maxCap is the largest buffer capacity kept for reuse; if <= 0, buffers are never capped
+	maxCap int
+	// disabled makes every get allocate a new encoder and every put drop it
+	disabled bool
+
+	newEncoder func() *pooledEncoder
+
+	stats poolCounters
+}
+
+// newEncoderPool creates an encoder pool whose new buffers start with initialSize bytes
+func newEncoderPool(maxCap, initialSize int, disabled bool) *encoderPool {
+	p := &encoderPool{maxCap: maxCap, disabled: disabled}
+	p.newEncoder = func() *pooledEncoder {
+		buf := bytes.NewBuffer(make([]byte, 0, initialSize))
 		return &pooledEncoder{
 			enc: msgpack.NewEncoder(buf),
 			buf: buf,
 		}
-	},
+	}
+	return p
 }
 
-// getPooledEncoder retrieves a pooled encoder from the pool
-func getPooledEncoder() *pooledEncoder {
-	return encoderPool.Get().(*pooledEncoder)
+// defaultEncoderPool is shared by the MessagePack serializers that keep the default pool settings
+var defaultEncoderPool = newEncoderPool(MAX_BUF_CAP, 0, false)
+
+// get retrieves an encoder from the pool and reports whether it was reused
+func (p *encoderPool) get() (*pooledEncoder, bool) {
+	p.stats.gets.Add(1)
+	p.stats.inUse.Add(1)
+	if !p.disabled {
+		if pe, ok := p.pool.Get().(*pooledEncoder); ok {
+			return pe, true
+		}
+	}
+	p.stats.misses.Add(1)
+	return p.newEncoder(), false
 }
 
-// putPooledEncoder returns a pooled encoder to the pool
-// If the buffer capacity exceeds MAX_BUF_CAP, the entire encoder is discarded to prevent memory bloat
-func putPooledEncoder(pe *pooledEncoder) {
-	if pe.buf.Cap() > MAX_BUF_CAP {
+// put returns an encoder to the pool.
+// If the buffer capacity exceeds maxCap, the entire encoder is discarded to prevent memory bloat.
+// It reports whether the encoder was discarded for its size.
+func (p *encoderPool) put(pe *pooledEncoder) bool {
+	p.stats.inUse.Add(-1)
+	if p.disabled {
+		return false
+	}
+	if p.maxCap > 0 && pe.buf.Cap() > p.maxCap {
 		// Discard the entire encoder - don't return it to the pool
-
-		// @TODO - this needs observability
-		return
+		p.stats.discards.Add(1)
+		return true
 	}
-	encoderPool.Put(pe)
+	p.stats.puts.Add(1)
+	p.pool.Put(pe)
+	return false
+}
+
+// getPooledEncoder retrieves a pooled encoder from the default pool
+func getPooledEncoder() *pooledEncoder {
+	pe, _ := defaultEncoderPool.get()
+	return pe
+}
+
+// putPooledEncoder returns a pooled encoder to the default pool
+// If the buffer capacity exceeds MAX_BUF_CAP, the entire encoder is discarded to prevent memory bloat.
+// It reports whether the encoder was discarded.
+func putPooledEncoder(pe *pooledEncoder) bool {
+	return defaultEncoderPool.put(pe)
 }
 
 // pooledDecoder contains a reusable msgpack decoder and bytes reader
 type pooledDecoder struct {
 	dec    *msgpack.Decoder
 	reader *bytes.Reader
+
+	// input wraps reader when the decoder needs to know its position in the input
+	input inputReader
 }
 
 // decoderPool is the global pool for reusing decoders and their readers
@@ -78,15 +128,234 @@ func getPooledDecoder(data []byte) *pooledDecoder {
 func putPooledDecoder(pd *pooledDecoder) {
 	// Reset the reader to nil to release reference to data
 	pd.reader.Reset(nil)
+	pd.input = inputReader{}
 	decoderPool.Put(pd)
 }
 
+var (
+	_ PooledSerializer  = (*MsgPackSerializer)(nil)
+	_ OptionsSerializer = (*MsgPackSerializer)(nil)
+)
+
 // MsgPackSerializer implements Serializer using MessagePack encoding
-type MsgPackSerializer struct{}
+type MsgPackSerializer struct {
+	opts     options
+	encoders *encoderPool
+
+	// observePool, if set, is called with every event of the encoder pool
+	observePool func(event PoolEvent, capacity int)
+}
 
 // NewMsgpackSerializer creates a new MessagePack serializer
-func NewMsgpackSerializer() Serializer {
-	return &MsgPackSerializer{}
+func NewMsgpackSerializer(opts ...Option) Serializer {
+	s := &MsgPackSerializer{opts: newOptions(opts)}
+	s.encoders = newMsgpackEncoderPool(&s.opts)
+	s.observePool = s.opts.poolObserver("msgpack_encoder", s.encoders.maxCap)
+	s.opts.bindLogger(Msgpack)
+	return s
+}
+
+// resetEncoder empties pe's buffer and binds its encoder to it with this serializer's settings
+func (s *MsgPackSerializer) resetEncoder(pe *pooledEncoder) {
+	pe.buf.Reset()
+	pe.enc.Reset(pe.buf)
+	s.configureEncoder(pe.enc)
+}
+
+// encode writes v with enc. Values with generated encoders use them.
+func (s *MsgPackSerializer) encode(enc *msgpack.Encoder, v any) error {
+	if err := checkDepth(v, s.opts.maxDepth); err != nil {
+		return err
+	}
+	if g, ok := v.(GeneratedMsgpackEncoder); ok && s.opts.generatedMsgpack() && !isNilPointer(v) {
+		return g.WriteMsgpack(enc)
+	}
+	return enc.Encode(v)
+}
+
+// encodePooled writes v to pe's buffer, formatting times, normalizing floats,
+// sorting map keys and compacting floats when configured
+func (s *MsgPackSerializer) encodePooled(pe *pooledEncoder, v any) error {
+	if err := s.encode(pe.enc, v); err != nil {
+		return err
+	}
+	if s.opts.msgpackTimeFormat != MsgpackTimeNanoseconds {
+		// Before sorting, as RFC 3339 strings sort differently
+		if err := rewriteMsgpackTimes(pe.buf, s.opts.msgpackTimeFormat); err != nil {
+			return err
+		}
+	}
+	if s.opts.canonical {
+		// Before sorting, as float keys may change
+		if err := normalizeMsgpackFloats(pe.buf.Bytes()); err != nil {
+			return err
+		}
+	}
+	if s.opts.sortMapKeys {
+		if err := sortMsgpackMaps(pe.buf.Bytes()); err != nil {
+			return err
+		}
+	}
+	if !s.opts.msgpackCompactFloats {
+		return nil
+	}
+	n, err := compactMsgpackFloats(pe.buf.Bytes())
+	if err != nil {
+		return err
+	}
+	pe.buf.Truncate(n)
+	return nil
+}
+
+// rewritesMsgpack reports whether encoded values are rewritten after encoding,
+// which requires buffering them
+func (o *options) rewritesMsgpack() bool {
+	return o.msgpackCompactFloats || o.sortMapKeys || o.canonical ||
+		o.msgpackTimeFormat != MsgpackTimeNanoseconds
+}
+
+// newEncoder creates an encoder writing to w with this serializer's settings
+func (s *MsgPackSerializer) newEncoder(w io.Writer) *msgpack.Encoder {
+	enc := msgpack.NewEncoder(w)
+	s.configureEncoder(enc)
+	return enc
+}
+
+// configureEncoder applies this serializer's encoding flags, which Reset clears
+func (s *MsgPackSerializer) configureEncoder(enc *msgpack.Encoder) {
+	enc.UseArrayEncodedStructs(s.opts.msgpackStructAsArray)
+	enc.SetOmitEmpty(s.opts.omitsEmpty(s.opts.msgpackOmitEmpty))
+	enc.UseCompactInts(s.opts.canonical)
+	if s.opts.msgpackFallbackTag != "" {
+		enc.SetCustomStructTag(s.opts.msgpackFallbackTag)
+	}
+}
+
+// getDecoder returns a pooled decoder reading data with this serializer's
+// settings. extMaps are the offsets checkMsgpackLengths returned for data.
+func (s *MsgPackSerializer) getDecoder(data []byte, extMaps []int) *pooledDecoder {
+	pd := getPooledDecoder(data)
+	pd.watchInput(data, s.opts.msgpackZeroCopy, extMaps)
+	s.configureDecoder(pd.dec)
+	return pd
+}
+
+// configureDecoder applies this serializer's decoding flags, which Reset clears
+func (s *MsgPackSerializer) configureDecoder(dec *msgpack.Decoder) {
+	if s.opts.msgpackFallbackTag != "" {
+		dec.SetCustomStructTag(s.opts.msgpackFallbackTag)
+	}
+	dec.UseLooseInterfaceDecoding(s.opts.msgpackNumberMode != MsgpackNumbersSized)
+	if s.opts.msgpackStringKeys {
+		dec.SetMapDecoder(s.decodeStringKeyMap)
+	}
+}
+
+// decodeStringKeyMap decodes a map into map[string]any, converting its keys to strings
+func (s *MsgPackSerializer) decodeStringKeyMap(dec *msgpack.Decoder) (any, error) {
+	n, err := dec.DecodeMapLen()
+	if err != nil || n == -1 {
+		return nil, err
+	}
+	m := make(map[string]any, n)
+	for i := 0; i < n; i++ {
+		k, err := dec.DecodeInterfaceLoose()
+		if err != nil {
+			return nil, err
+		}
+		key, err := msgpackKeyString(k)
+		if err != nil {
+			return nil, err
+		}
+		var v any
+		if s.opts.msgpackNumberMode == MsgpackNumbersSized {
+			v, err = dec.DecodeInterface()
+		} else {
+			v, err = dec.DecodeInterfaceLoose()
+		}
+		if err != nil {
+			return nil, err
+		}
+		m[key] = v
+	}
+	return m, nil
+}
+
+// decode reads v with dec, then converts numbers in interface values when the
+// number mode asks for more than loose decoding provides. A top-level []byte is
+// decoded like MsgpackBytes, and values with generated decoders use them.
+func (s *MsgPackSerializer) decode(dec *msgpack.Decoder, v any) error {
+	if b, ok := v.(*[]byte); ok && b != nil {
+		var err error
+		*b, err = decodeMsgpackBytes(dec, *b)
+		return err
+	}
+	var err error
+	if g, ok := v.(GeneratedMsgpackDecoder); ok && s.opts.generatedMsgpack() && !isNilPointer(v) {
+		err = g.ReadMsgpack(dec)
+	} else {
+		err = decodeValue(dec, v)
+	}
+	if err != nil {
+		return err
+	}
+	if s.opts.msgpackNumberMode >= MsgpackNumbersInt64 {
+		if err := convertMsgpackNumbers(reflect.ValueOf(v), s.opts.msgpackNumberMode); err != nil {
+			return err
+		}
+	}
+	return s.opts.validate(v)
+}
+
+// decodeValue decodes v with dec. The library panics when it decodes nil into a
+// struct with an extension codec, such as a time.Time field, which is returned
+// as an error instead; other panics are left to the caller.
+func decodeValue(dec *msgpack.Decoder, v any) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			ve, ok := r.(*reflect.ValueError)
+			if !ok {
+				panic(r)
+			}
+			err = fmt.Errorf("msgpack: cannot decode nil into a %s: %w", ve.Kind, ve)
+		}
+	}()
+	return dec.Decode(v)
+}
+
+// getEncoder retrieves an encoder from this serializer's pool
+func (s *MsgPackSerializer) getEncoder() *pooledEncoder {
+	pe, hit := s.encoderPool().get()
+	if s.observePool != nil {
+		if hit {
+			s.observePool(PoolHit, pe.buf.Cap())
+		} else {
+			s.observePool(PoolMiss, 0)
+		}
+	}
+	return pe
+}
+
+// releaseEncoder returns pe to the pool, reporting whether it was kept or discarded for size
+func (s *MsgPackSerializer) releaseEncoder(pe *pooledEncoder) {
+	capacity := pe.buf.Cap()
+	p := s.encoderPool()
+	discarded := p.put(pe)
+	switch {
+	case s.observePool == nil || p.disabled:
+	case discarded:
+		s.observePool(PoolDiscard, capacity)
+	default:
+		s.observePool(PoolPut, capacity)
+	}
+}
+
+// encoderPool returns the pool this serializer takes encoders from
+func (s *MsgPackSerializer) encoderPool() *encoderPool {
+	if s.encoders == nil {
+		return defaultEncoderPool
+	}
+	return s.encoders
 }
 
 // SerializeSafe uses pooled encoders to reduce allocations while returning an owned []byte slice.
@@ -97,21 +366,22 @@ func (s *MsgPackSerializer) SerializeSafe(v any) ([]byte, error) {
 	}
 
 	// Acquire pooled encoder
-	pe := getPooledEncoder()
-	defer putPooledEncoder(pe)
+	pe := s.getEncoder()
+	defer s.releaseEncoder(pe)
 
 	// Reset buffer and bind encoder to it
-	pe.buf.Reset()
-	pe.enc.Reset(pe.buf)
+	s.resetEncoder(pe)
 
 	// Encode the value
-	if err := pe.enc.Encode(v); err != nil {
+	if err := s.encodePooled(pe, v); err != nil {
+		s.opts.logFailure("serialize", err)
 		return nil, err
 	}
 
 	// Copy to owned slice
 	out := make([]byte, pe.buf.Len())
 	copy(out, pe.buf.Bytes())
+	s.opts.logSize("serialize", len(out))
 
 	return out, nil
 }
@@ -121,6 +391,30 @@ func (s *MsgPackSerializer) Serialize(v any) ([]byte, error) {
 	return s.SerializeSafe(v)
 }
 
+// SerializeWithOptions implements OptionsSerializer. Encoders still come from
+// this serializer's pool, so pool options are ignored.
+func (s *MsgPackSerializer) SerializeWithOptions(v any, opts ...Option) ([]byte, error) {
+	if len(opts) == 0 {
+		return s.Serialize(v)
+	}
+	c := *s
+	c.opts = s.opts.withCallOptions(opts, Msgpack)
+	return c.Serialize(v)
+}
+
+// checkInput rejects data that is over the size limit or declares lengths it
+// cannot back, returning the offsets checkMsgpackLengths found
+func (s *MsgPackSerializer) checkInput(data []byte) ([]int, error) {
+	if err := s.opts.checkInputSize(len(data)); err != nil {
+		return nil, err
+	}
+	return checkMsgpackLengths(data)
+}
+
+func (s *MsgPackSerializer) maxInputSize() int64 {
+	return s.opts.maxInputSize
+}
+
 func (s *MsgPackSerializer) Deserialize(data []byte, v any) error {
 	if data == nil {
 		return errors.New("data is nil")
@@ -129,25 +423,77 @@ func (s *MsgPackSerializer) Deserialize(data []byte, v any) error {
 		return errors.New("output parameter is nil")
 	}
 
+	s.opts.logSize("deserialize", len(data))
+	extMaps, err := s.checkInput(data)
+	if err != nil {
+		s.opts.logFailure("deserialize", err)
+		return err
+	}
+
 	// Use pooled decoder to reduce allocations
-	pd := getPooledDecoder(data)
+	pd := s.getDecoder(data, extMaps)
 	defer putPooledDecoder(pd)
 
-	return pd.dec.Decode(v)
+	err = s.decode(pd.dec, v)
+	s.opts.logFailure("deserialize", err)
+	return err
+}
+
+// SerializeWithTypeInfo implements TypedSerializer interface. MessagePack needs no
+// type information to encode, so it is the same as Serialize.
+func (s *MsgPackSerializer) SerializeWithTypeInfo(v any, typeInfo TypeInfo) ([]byte, error) {
+	data, err := s.Serialize(v)
+	if err != nil {
+		return nil, fmt.Errorf("msgpack serialization failed for type %s: %w", typeInfo.TypeName, err)
+	}
+	return data, nil
+}
+
+// DeserializeWithTypeInfo implements TypedSerializer interface. It decodes into a
+// new value of typeInfo.Type and returns that value.
+func (s *MsgPackSerializer) DeserializeWithTypeInfo(data []byte, typeInfo TypeInfo) (any, error) {
+	target, err := newTypedTarget(typeInfo)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.Deserialize(data, target.Interface()); err != nil {
+		return nil, fmt.Errorf("msgpack deserialization failed for type %s: %w", typeInfo.TypeName, err)
+	}
+	return target.Elem().Interface(), nil
 }
 
 func (s *MsgPackSerializer) SerializeTo(w io.Writer, v any) error {
 	if w == nil {
 		return errors.New("writer is nil")
 	}
-	return msgpack.NewEncoder(w).Encode(v)
+	if s.opts.rewritesMsgpack() {
+		// Times, floats and maps are rewritten after encoding, so the value is buffered first
+		pe := s.getEncoder()
+		defer s.releaseEncoder(pe)
+		s.resetEncoder(pe)
+		err := s.encodePooled(pe, v)
+		if err == nil {
+			_, err = w.Write(pe.buf.Bytes())
+		}
+		s.opts.logFailure("serialize_to", err)
+		return err
+	}
+	err := s.encode(s.newEncoder(w), v)
+	s.opts.logFailure("serialize_to", err)
+	return err
 }
 
 func (s *MsgPackSerializer) DeserializeFrom(r io.Reader, v any) error {
 	if r == nil {
 		return errors.New("reader is nil")
 	}
-	return msgpack.NewDecoder(r).Decode(v)
+	limited, limitErr := s.opts.limitInput(r)
+	sr := s.newStreamDecoder(limited)
+	defer sr.Close()
+	err := limitErr(s.decodeStream(sr.sd.dec, v))
+	sr.failed = err != nil
+	s.opts.logFailure("deserialize_from", err)
+	return err
 }
 
 // DeserializeString implements StringDeserializer interface
@@ -156,7 +502,28 @@ func (s *MsgPackSerializer) DeserializeString(data string, v any) error {
 	if data == "" {
 		return errors.New("data is empty")
 	}
-	return msgpack.Unmarshal(stringToReadOnlyBytes(data), v)
+	s.opts.logSize("deserialize_string", len(data))
+	b := stringToReadOnlyBytes(data)
+	extMaps, err := s.checkInput(b)
+	if err != nil {
+		s.opts.logFailure("deserialize_string", err)
+		return err
+	}
+	// Never aliased: the bytes belong to an immutable string
+	pd := getPooledDecoder(b)
+	defer putPooledDecoder(pd)
+	pd.watchInput(b, false, extMaps)
+	s.configureDecoder(pd.dec)
+	err = s.decode(pd.dec, v)
+	s.opts.logFailure("deserialize_string", err)
+	return err
+}
+
+// PoolStats implements PoolStatsProvider.
+// Unless pool settings are configured, the encoder pool is shared by all
+// MessagePack serializers, so the stats are package-wide.
+func (s *MsgPackSerializer) PoolStats() PoolStats {
+	return s.encoderPool().stats.snapshot()
 }
 
 func (s *MsgPackSerializer) ContentType() string {
@@ -166,34 +533,57 @@ func (s *MsgPackSerializer) ContentType() string {
 // PooledBuf owns a pointer to an encoder's buffer. Caller must call Release()
 // after the buffer is no longer needed to return the pooled encoder to the pool.
 type PooledBuf struct {
-	pe *pooledEncoder // holds the complete pooled encoder for release
+	pe    *pooledEncoder     // holds the complete pooled encoder for release
+	owner *MsgPackSerializer // serializer that produced the buffer, if any
+
+	// buf and pool are set instead of pe for buffers taken from a pooledBufferPool
+	buf  *bytes.Buffer
+	pool *pooledBufferPool
+}
+
+// buffer returns the buffer holding the encoded bytes, or nil after Release
+func (p *PooledBuf) buffer() *bytes.Buffer {
+	if p.pe != nil {
+		return p.pe.buf
+	}
+	return p.buf
 }
 
 // Bytes returns the encoded bytes from the pooled buffer.
 // The returned slice is valid until Release() is called.
 func (p *PooledBuf) Bytes() []byte {
-	if p.pe == nil || p.pe.buf == nil {
+	buf := p.buffer()
+	if buf == nil {
 		return nil
 	}
-	return p.pe.buf.Bytes()
+	return buf.Bytes()
 }
 
 // Len returns the length of the encoded data.
 func (p *PooledBuf) Len() int {
-	if p.pe == nil || p.pe.buf == nil {
+	buf := p.buffer()
+	if buf == nil {
 		return 0
 	}
-	return p.pe.buf.Len()
+	return buf.Len()
 }
 
-// Release returns the underlying pooledEncoder back to the pool.
+// Release returns the underlying pooledEncoder or buffer back to the pool.
 // After calling Release(), the PooledBuf should not be used anymore.
 // The bytes returned by Bytes() become invalid after Release().
 func (p *PooledBuf) Release() {
 	if p.pe != nil {
-		putPooledEncoder(p.pe)
+		if p.owner != nil {
+			p.owner.releaseEncoder(p.pe)
+		} else {
+			putPooledEncoder(p.pe)
+		}
 		p.pe = nil // Prevent accidental reuse
 	}
+	if p.buf != nil {
+		p.pool.Put(p.buf)
+		p.buf = nil
+	}
 }
 
 // SerializePooled encodes the value using a pooled encoder and returns a PooledBuf
@@ -209,22 +599,23 @@ func (s *MsgPackSerializer) SerializePooled(v any) (*PooledBuf, error) {
 	}
 
 	// Acquire pooled encoder
-	pe := getPooledEncoder()
+	pe := s.getEncoder()
 
 	// Reset buffer and bind encoder to it
-	pe.buf.Reset()
-	pe.enc.Reset(pe.buf)
+	s.resetEncoder(pe)
 
 	// Encode the value
-	if err := pe.enc.Encode(v); err != nil {
+	if err := s.encodePooled(pe, v); err != nil {
 		// On error, return encoder to pool immediately
-		putPooledEncoder(pe)
+		s.releaseEncoder(pe)
+		s.opts.logFailure("serialize_pooled", err)
 		return nil, err
 	}
+	s.opts.logSize("serialize_pooled", pe.buf.Len())
 
 	// Return PooledBuf with ownership of the encoder
 	// Do NOT put the encoder back in the pool - ownership is transferred to caller
-	return &PooledBuf{pe: pe}, nil
+	return &PooledBuf{pe: pe, owner: s}, nil
 }
 
 // DeserializeFromPooled decodes directly from a pooled buffer without copying the bytes.
@@ -244,11 +635,19 @@ func (s *MsgPackSerializer) DeserializeFromPooled(pb *PooledBuf, v any) error {
 		return errors.New("PooledBuf contains no data")
 	}
 
+	extMaps, err := checkMsgpackLengths(data)
+	if err != nil {
+		s.opts.logFailure("deserialize_pooled", err)
+		return err
+	}
+
 	// Use pooled decoder to decode the data
-	pd := getPooledDecoder(data)
+	pd := s.getDecoder(data, extMaps)
 	defer putPooledDecoder(pd)
 
-	return pd.dec.Decode(v)
+	err = s.decode(pd.dec, v)
+	s.opts.logFailure("deserialize_pooled", err)
+	return err
 }
 
 // CopyAndRelease is a convenience helper that copies the bytes from a PooledBuf
@@ -0,0 +1,80 @@
+package serializer
+
+import (
+	"io"
+
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// WithMsgpackZeroCopy makes decoded MsgpackBytes fields and top-level []byte
+// targets alias the input instead of copying it, which avoids large copies when
+// payloads embed binary blobs.
+//
+// The decoded slices share memory with the data passed to Deserialize, or with
+// the PooledBuf passed to DeserializeFromPooled: they are only valid while that
+// data is, must not be modified unless the caller owns the data, and keep the
+// whole input reachable for the garbage collector. DeserializeString and
+// DeserializeFrom always copy. Plain []byte fields are decoded by the msgpack
+// library and always copied.
+func WithMsgpackZeroCopy() Option {
+	return func(o *options) {
+		o.msgpackZeroCopy = true
+	}
+}
+
+var (
+	_ msgpack.CustomEncoder = MsgpackBytes(nil)
+	_ msgpack.CustomDecoder = (*MsgpackBytes)(nil)
+)
+
+// MsgpackBytes is a []byte that a serializer with WithMsgpackZeroCopy decodes by
+// aliasing the input. Otherwise it decodes like []byte, reusing the capacity the
+// field already has. It encodes like []byte.
+type MsgpackBytes []byte
+
+// EncodeMsgpack implements msgpack.CustomEncoder
+func (b MsgpackBytes) EncodeMsgpack(enc *msgpack.Encoder) error {
+	return enc.EncodeBytes(b)
+}
+
+// DecodeMsgpack implements msgpack.CustomDecoder
+func (b *MsgpackBytes) DecodeMsgpack(dec *msgpack.Decoder) error {
+	v, err := decodeMsgpackBytes(dec, *b)
+	if err != nil {
+		return err
+	}
+	*b = v
+	return nil
+}
+
+// decodeMsgpackBytes decodes a []byte value, aliasing the input when the decoder
+// reads from an aliasing inputReader and otherwise reusing b's capacity as the
+// library's own codec does
+func decodeMsgpackBytes(d *msgpack.Decoder, b []byte) ([]byte, error) {
+	n, err := d.DecodeBytesLen()
+	if err != nil {
+		return nil, err
+	}
+	if n == -1 {
+		return nil, nil
+	}
+	if r, ok := d.Buffered().(*inputReader); ok && r.alias {
+		pos := len(r.data) - r.Len()
+		if n > r.Len() {
+			return nil, io.ErrUnexpectedEOF
+		}
+		if _, err := r.Seek(int64(n), io.SeekCurrent); err != nil {
+			return nil, err
+		}
+		return r.data[pos : pos+n : pos+n], nil
+	}
+	if cap(b) >= n && b != nil {
+		b = b[:n]
+	} else {
+		b = make([]byte, n)
+	}
+	if err := d.ReadFull(b); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
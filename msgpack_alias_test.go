@@ -0,0 +1,105 @@
+package serializer
+
+import (
+	"bytes"
+	"testing"
+	"unsafe"
+)
+
+type blobRecord struct {
+	Name  string       `msgpack:"name"`
+	Blob  MsgpackBytes `msgpack:"blob"`
+	Empty MsgpackBytes `msgpack:"empty"`
+	Nil   MsgpackBytes `msgpack:"nil"`
+	Plain []byte       `msgpack:"plain"`
+}
+
+// sharesMemory reports whether b points into data
+func sharesMemory(b, data []byte) bool {
+	if len(b) == 0 || len(data) == 0 {
+		return false
+	}
+	start := uintptr(unsafe.Pointer(unsafe.SliceData(data)))
+	p := uintptr(unsafe.Pointer(unsafe.SliceData(b)))
+	return p >= start && p < start+uintptr(len(data))
+}
+
+func TestWithMsgpackZeroCopy(t *testing.T) {
+	in := blobRecord{Name: "img", Blob: bytes.Repeat([]byte{0xab}, 4096), Empty: []byte{}, Plain: []byte("copied")}
+	data, err := NewMsgpackSerializer().Serialize(in)
+	if err != nil {
+		t.Fatalf("Serialize failed: %v", err)
+	}
+
+	var copied blobRecord
+	if err := NewMsgpackSerializer().Deserialize(data, &copied); err != nil {
+		t.Fatalf("Deserialize failed: %v", err)
+	}
+	if sharesMemory(copied.Blob, data) {
+		t.Error("default decoding should copy MsgpackBytes fields")
+	}
+
+	s := NewMsgpackSerializer(WithMsgpackZeroCopy())
+	var aliased blobRecord
+	if err := s.Deserialize(data, &aliased); err != nil {
+		t.Fatalf("Deserialize failed: %v", err)
+	}
+	if !bytes.Equal(aliased.Blob, in.Blob) || aliased.Name != "img" {
+		t.Fatalf("unexpected round trip: %q %d bytes", aliased.Name, len(aliased.Blob))
+	}
+	if !sharesMemory(aliased.Blob, data) {
+		t.Error("expected Blob to alias the input")
+	}
+	if cap(aliased.Blob) != len(aliased.Blob) {
+		t.Error("aliased slices must not expose the rest of the input through their capacity")
+	}
+	if aliased.Empty == nil || len(aliased.Empty) != 0 || aliased.Nil != nil {
+		t.Errorf("expected empty and nil slices to be preserved, got %#v %#v", aliased.Empty, aliased.Nil)
+	}
+	if string(aliased.Plain) != "copied" || sharesMemory(aliased.Plain, data) {
+		t.Error("expected []byte fields to be copied")
+	}
+
+	var top []byte
+	blobData, _ := NewMsgpackSerializer().Serialize(in.Blob)
+	if err := s.Deserialize(blobData, &top); err != nil || !sharesMemory(top, blobData) {
+		t.Errorf("expected a top-level []byte to alias the input (err %v)", err)
+	}
+
+	var fromString blobRecord
+	if err := s.(*MsgPackSerializer).DeserializeString(string(data), &fromString); err != nil {
+		t.Fatalf("DeserializeString failed: %v", err)
+	}
+	if !bytes.Equal(fromString.Blob, in.Blob) {
+		t.Error("DeserializeString mismatch")
+	}
+
+	pb, err := s.(*MsgPackSerializer).SerializePooled(in)
+	if err != nil {
+		t.Fatalf("SerializePooled failed: %v", err)
+	}
+	defer pb.Release()
+	var pooled blobRecord
+	if err := s.(*MsgPackSerializer).DeserializeFromPooled(pb, &pooled); err != nil {
+		t.Fatalf("DeserializeFromPooled failed: %v", err)
+	}
+	if !sharesMemory(pooled.Blob, pb.Bytes()) {
+		t.Error("expected Blob to alias the PooledBuf")
+	}
+
+	if err := s.Deserialize(data[:len(data)/2], &aliased); err == nil {
+		t.Error("expected error for truncated input")
+	}
+}
+
+func TestMsgpackBytesReuseCapacity(t *testing.T) {
+	data, _ := NewMsgpackSerializer().Serialize(blobRecord{Blob: []byte("abc")})
+	buf := make([]byte, 0, 16)
+	out := blobRecord{Blob: buf}
+	if err := NewMsgpackSerializer().Deserialize(data, &out); err != nil {
+		t.Fatalf("Deserialize failed: %v", err)
+	}
+	if string(out.Blob) != "abc" || unsafe.SliceData(out.Blob) != unsafe.SliceData(buf) {
+		t.Error("expected decoding to reuse the field's existing capacity")
+	}
+}
@@ -0,0 +1,144 @@
+package serializer
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/vmihailenco/msgpack/v5/msgpcode"
+)
+
+// errTruncatedMsgpack reports encoder output that ends inside a value
+var errTruncatedMsgpack = errors.New("msgpack: truncated value")
+
+// compactMsgpackFloats rewrites, in place, every float64 in the encoded data that
+// float32 represents exactly as a float32, and returns the new length. MessagePack
+// headers announce element counts rather than byte sizes, so shrinking a value
+// never invalidates the containers around it.
+func compactMsgpackFloats(data []byte) (int, error) {
+	r, w := 0, 0
+	for r < len(data) {
+		c := data[r]
+		if c == msgpcode.Double {
+			if r+9 > len(data) {
+				return 0, errTruncatedMsgpack
+			}
+			f := math.Float64frombits(binary.BigEndian.Uint64(data[r+1:]))
+			if f32 := float32(f); float64(f32) == f {
+				data[w] = msgpcode.Float
+				binary.BigEndian.PutUint32(data[w+1:], math.Float32bits(f32))
+				r += 9
+				w += 5
+				continue
+			}
+		}
+		n, err := msgpackTokenLen(data[r:])
+		if err != nil {
+			return 0, err
+		}
+		w += copy(data[w:], data[r:r+n])
+		r += n
+	}
+	return w, nil
+}
+
+// normalizeMsgpackFloats rewrites, in place, negative zero as zero and every NaN
+// as the same quiet NaN, so equal floats encode to equal bytes
+func normalizeMsgpackFloats(data []byte) error {
+	for r := 0; r < len(data); {
+		n, err := msgpackTokenLen(data[r:])
+		if err != nil {
+			return err
+		}
+		switch data[r] {
+		case msgpcode.Double:
+			switch f := math.Float64frombits(binary.BigEndian.Uint64(data[r+1:])); {
+			case f == 0:
+				binary.BigEndian.PutUint64(data[r+1:], 0)
+			case math.IsNaN(f):
+				binary.BigEndian.PutUint64(data[r+1:], quietNaN64)
+			}
+		case msgpcode.Float:
+			switch f := math.Float32frombits(binary.BigEndian.Uint32(data[r+1:])); {
+			case f == 0:
+				binary.BigEndian.PutUint32(data[r+1:], 0)
+			case math.IsNaN(float64(f)):
+				binary.BigEndian.PutUint32(data[r+1:], quietNaN32)
+			}
+		}
+		r += n
+	}
+	return nil
+}
+
+// Bits of the quiet NaNs normalizeMsgpackFloats writes
+const (
+	quietNaN64 = 0x7ff8000000000000
+	quietNaN32 = 0x7fc00000
+)
+
+// msgpackTokenLen returns the length of the token at the start of data: a scalar
+// with its payload, or just the header of an array or map
+func msgpackTokenLen(data []byte) (int, error) {
+	c := data[0]
+	var n int
+	switch {
+	case msgpcode.IsFixedNum(c), msgpcode.IsFixedMap(c), msgpcode.IsFixedArray(c),
+		c == msgpcode.Nil, c == msgpcode.False, c == msgpcode.True:
+		n = 1
+	case msgpcode.IsFixedString(c):
+		n = 1 + int(c&0x1f)
+	case c == msgpcode.Uint8, c == msgpcode.Int8:
+		n = 2
+	case c == msgpcode.Uint16, c == msgpcode.Int16, c == msgpcode.Array16, c == msgpcode.Map16:
+		n = 3
+	case c == msgpcode.Uint32, c == msgpcode.Int32, c == msgpcode.Float, c == msgpcode.Array32, c == msgpcode.Map32:
+		n = 5
+	case c == msgpcode.Uint64, c == msgpcode.Int64, c == msgpcode.Double:
+		n = 9
+	case c == msgpcode.Str8, c == msgpcode.Bin8:
+		n = 2 + lengthAt(data, 1, 1)
+	case c == msgpcode.Str16, c == msgpcode.Bin16:
+		n = 3 + lengthAt(data, 1, 2)
+	case c == msgpcode.Str32, c == msgpcode.Bin32:
+		n = 5 + lengthAt(data, 1, 4)
+	case c == msgpcode.FixExt1:
+		n = 3
+	case c == msgpcode.FixExt2:
+		n = 4
+	case c == msgpcode.FixExt4:
+		n = 6
+	case c == msgpcode.FixExt8:
+		n = 10
+	case c == msgpcode.FixExt16:
+		n = 18
+	case c == msgpcode.Ext8:
+		n = 3 + lengthAt(data, 1, 1)
+	case c == msgpcode.Ext16:
+		n = 4 + lengthAt(data, 1, 2)
+	case c == msgpcode.Ext32:
+		n = 6 + lengthAt(data, 1, 4)
+	default:
+		return 0, fmt.Errorf("msgpack: unexpected code %x", c)
+	}
+	if n < 0 || n > len(data) {
+		return 0, errTruncatedMsgpack
+	}
+	return n, nil
+}
+
+// lengthAt reads a big-endian length of size bytes at data[off:]. When data is
+// too short to hold it, the length returned runs past the end of data.
+func lengthAt(data []byte, off, size int) int {
+	if len(data) < off+size {
+		return len(data)
+	}
+	switch size {
+	case 1:
+		return int(data[off])
+	case 2:
+		return int(binary.BigEndian.Uint16(data[off:]))
+	}
+	return int(binary.BigEndian.Uint32(data[off:]))
+}
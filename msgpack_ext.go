@@ -0,0 +1,121 @@
+package serializer
+
+import (
+	"encoding"
+	"fmt"
+	"math/big"
+	"reflect"
+	"sync"
+
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// msgpackExts records extension IDs registered through RegisterMsgpackExt
+var msgpackExts = struct {
+	sync.Mutex
+	byID map[int8]reflect.Type
+}{byID: make(map[int8]reflect.Type)}
+
+// RegisterMsgpackExt encodes values of type T as MessagePack extension id, so
+// types such as UUIDs or decimals take a few bytes instead of a map. encode
+// returns the extension payload and decode parses it back; both directions
+// also apply to *T fields and to T values decoded into any.
+//
+// The msgpack library keeps a single extension table, so registrations are
+// process-wide and affect every MessagePack serializer. Register during program
+// initialization, before any encoding starts. IDs 0 to 127 are available to
+// applications; negative IDs are reserved by the MessagePack spec. Registering
+// the same ID for the same type again replaces its codec.
+func RegisterMsgpackExt[T any](id int8, encode func(T) ([]byte, error), decode func([]byte) (T, error)) error {
+	if id < 0 {
+		return fmt.Errorf("msgpack extension id %d is reserved", id)
+	}
+	if encode == nil || decode == nil {
+		return fmt.Errorf("msgpack extension %d needs both encode and decode functions", id)
+	}
+	var zero T
+	typ := reflect.TypeOf(&zero).Elem()
+	if typ.Kind() == reflect.Interface {
+		return fmt.Errorf("msgpack extension %d: %s is an interface type", id, typ)
+	}
+
+	msgpackExts.Lock()
+	defer msgpackExts.Unlock()
+	if existing, ok := msgpackExts.byID[id]; ok && existing != typ {
+		return fmt.Errorf("msgpack extension id %d already registered for %s", id, existing)
+	}
+	for otherID, existing := range msgpackExts.byID {
+		if existing == typ && otherID != id {
+			return fmt.Errorf("type %s already registered as msgpack extension %d", typ, otherID)
+		}
+	}
+
+	msgpack.RegisterExtEncoder(id, zero, func(_ *msgpack.Encoder, v reflect.Value) ([]byte, error) {
+		return encode(v.Interface().(T))
+	})
+	msgpack.RegisterExtDecoder(id, zero, func(d *msgpack.Decoder, v reflect.Value, extLen int) error {
+		data := make([]byte, extLen)
+		if err := d.ReadFull(data); err != nil {
+			return err
+		}
+		value, err := decode(data)
+		if err != nil {
+			return err
+		}
+		v.Set(reflect.ValueOf(&value).Elem())
+		return nil
+	})
+	msgpackExts.byID[id] = typ
+	return nil
+}
+
+// MustRegisterMsgpackExt is like RegisterMsgpackExt but panics on error
+func MustRegisterMsgpackExt[T any](id int8, encode func(T) ([]byte, error), decode func([]byte) (T, error)) {
+	if err := RegisterMsgpackExt(id, encode, decode); err != nil {
+		panic(err)
+	}
+}
+
+// RegisterMsgpackBigInt encodes big.Int values as extension id, in the binary form
+// of big.Int.GobEncode, so integers beyond 64 bits round-trip exactly
+func RegisterMsgpackBigInt(id int8) error {
+	return RegisterMsgpackExt(id, func(n big.Int) ([]byte, error) {
+		return n.GobEncode()
+	}, func(data []byte) (big.Int, error) {
+		var n big.Int
+		err := n.GobDecode(data)
+		return n, err
+	})
+}
+
+// RegisterMsgpackBigRat encodes big.Rat values as extension id, in the binary form
+// of big.Rat.GobEncode, so fractions round-trip exactly instead of through float64
+func RegisterMsgpackBigRat(id int8) error {
+	return RegisterMsgpackExt(id, func(r big.Rat) ([]byte, error) {
+		return r.GobEncode()
+	}, func(data []byte) (big.Rat, error) {
+		var r big.Rat
+		err := r.GobDecode(data)
+		return r, err
+	})
+}
+
+// RegisterMsgpackDecimal encodes a decimal type as extension id holding its text
+// form, for types such as shopspring's decimal.Decimal or apd.Decimal whose
+// pointers implement encoding.TextMarshaler and encoding.TextUnmarshaler. Amounts
+// keep every digit instead of passing through float64:
+//
+//	serializer.RegisterMsgpackDecimal[decimal.Decimal](10)
+func RegisterMsgpackDecimal[T any, PT interface {
+	*T
+	encoding.TextMarshaler
+	encoding.TextUnmarshaler
+}](id int8) error {
+	return RegisterMsgpackExt(id, func(d T) ([]byte, error) {
+		return PT(&d).MarshalText()
+	}, func(data []byte) (T, error) {
+		var d T
+		err := PT(&d).UnmarshalText(data)
+		return d, err
+	})
+}
@@ -0,0 +1,170 @@
+package serializer
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"testing"
+)
+
+type extUUID [16]byte
+
+type extPoint struct {
+	X, Y int16
+}
+
+func TestRegisterMsgpackExt(t *testing.T) {
+	MustRegisterMsgpackExt(70, func(u extUUID) ([]byte, error) {
+		return u[:], nil
+	}, func(data []byte) (extUUID, error) {
+		var u extUUID
+		if len(data) != len(u) {
+			return u, errors.New("bad uuid length")
+		}
+		copy(u[:], data)
+		return u, nil
+	})
+
+	type record struct {
+		ID     extUUID  `msgpack:"id"`
+		Parent *extUUID `msgpack:"parent"`
+	}
+	id := extUUID{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16}
+	in := record{ID: id, Parent: &id}
+
+	s := NewMsgpackSerializer()
+	data, err := s.Serialize(in)
+	if err != nil {
+		t.Fatalf("Serialize failed: %v", err)
+	}
+	// fixext16 header (0xd8), extension type, then the raw 16 bytes
+	if !bytes.Contains(data, append([]byte{0xd8, 70}, id[:]...)) {
+		t.Errorf("expected compact ext encoding, got %x", data)
+	}
+
+	var out record
+	if err := s.Deserialize(data, &out); err != nil {
+		t.Fatalf("Deserialize failed: %v", err)
+	}
+	if out.ID != id || out.Parent == nil || *out.Parent != id {
+		t.Errorf("unexpected round trip %+v", out)
+	}
+
+	var generic map[string]any
+	if err := s.Deserialize(data, &generic); err != nil {
+		t.Fatalf("Deserialize into map failed: %v", err)
+	}
+	if got, ok := generic["id"].(extUUID); !ok || got != id {
+		t.Errorf("expected extUUID in interface target, got %#v", generic["id"])
+	}
+}
+
+func TestRegisterMsgpackExtErrors(t *testing.T) {
+	enc := func(p extPoint) ([]byte, error) { return []byte{byte(p.X), byte(p.Y)}, nil }
+	dec := func(b []byte) (extPoint, error) { return extPoint{int16(b[0]), int16(b[1])}, nil }
+
+	if err := RegisterMsgpackExt(-1, enc, dec); err == nil {
+		t.Error("expected error for a reserved id")
+	}
+	if err := RegisterMsgpackExt[extPoint](71, nil, dec); err == nil {
+		t.Error("expected error for a missing encoder")
+	}
+	if err := RegisterMsgpackExt(71, enc, dec); err != nil {
+		t.Fatalf("RegisterMsgpackExt failed: %v", err)
+	}
+	if err := RegisterMsgpackExt(71, enc, dec); err != nil {
+		t.Errorf("re-registering the same id and type should succeed, got %v", err)
+	}
+	if err := RegisterMsgpackExt(72, enc, dec); err == nil {
+		t.Error("expected error registering a type under a second id")
+	}
+	if err := RegisterMsgpackExt(71, func(int8) ([]byte, error) { return nil, nil }, func([]byte) (int8, error) { return 0, nil }); err == nil {
+		t.Error("expected error reusing an id for another type")
+	}
+}
+
+// testDecimal stands in for a third-party decimal type: an unscaled value and a
+// number of fractional digits, with a text form
+type testDecimal struct {
+	unscaled big.Int
+	scale    int
+}
+
+func (d *testDecimal) MarshalText() ([]byte, error) {
+	digits := d.unscaled.String()
+	if d.scale == 0 {
+		return []byte(digits), nil
+	}
+	for len(digits) <= d.scale {
+		digits = "0" + digits
+	}
+	return []byte(digits[:len(digits)-d.scale] + "." + digits[len(digits)-d.scale:]), nil
+}
+
+func (d *testDecimal) UnmarshalText(text []byte) error {
+	whole, frac, _ := strings.Cut(string(text), ".")
+	if _, ok := d.unscaled.SetString(whole+frac, 10); !ok {
+		return fmt.Errorf("invalid decimal %q", text)
+	}
+	d.scale = len(frac)
+	return nil
+}
+
+func TestMsgpackBigNumberExts(t *testing.T) {
+	for id, register := range map[int8]func(int8) error{72: RegisterMsgpackBigInt, 73: RegisterMsgpackBigRat} {
+		if err := register(id); err != nil {
+			t.Fatalf("register %d failed: %v", id, err)
+		}
+	}
+	if err := RegisterMsgpackDecimal[testDecimal](74); err != nil {
+		t.Fatalf("RegisterMsgpackDecimal failed: %v", err)
+	}
+
+	type ledgerEntry struct {
+		Balance *big.Int    `msgpack:"balance"`
+		Debt    big.Int     `msgpack:"debt"`
+		Share   *big.Rat    `msgpack:"share"`
+		Amount  testDecimal `msgpack:"amount"`
+	}
+	balance, _ := new(big.Int).SetString("123456789012345678901234567890", 10)
+	debt, _ := new(big.Int).SetString("-98765432109876543210", 10)
+	var amount testDecimal
+	if err := amount.UnmarshalText([]byte("19.99")); err != nil {
+		t.Fatal(err)
+	}
+	in := ledgerEntry{Balance: balance, Debt: *debt, Share: big.NewRat(1, 3), Amount: amount}
+
+	s := NewMsgpackSerializer()
+	data, err := s.Serialize(&in)
+	if err != nil {
+		t.Fatalf("Serialize failed: %v", err)
+	}
+	var out ledgerEntry
+	if err := s.Deserialize(data, &out); err != nil {
+		t.Fatalf("Deserialize failed: %v", err)
+	}
+	if out.Balance == nil || out.Balance.Cmp(balance) != 0 || out.Debt.Cmp(debt) != 0 {
+		t.Errorf("big.Int mismatch: %v %v", out.Balance, &out.Debt)
+	}
+	if out.Share == nil || out.Share.Cmp(big.NewRat(1, 3)) != 0 {
+		t.Errorf("big.Rat mismatch: %v", out.Share)
+	}
+	if text, _ := out.Amount.MarshalText(); string(text) != "19.99" {
+		t.Errorf("decimal mismatch: %s", text)
+	}
+
+	// A nil pointer stays nil
+	var empty ledgerEntry
+	data, err = s.Serialize(&ledgerEntry{})
+	if err != nil {
+		t.Fatalf("Serialize failed: %v", err)
+	}
+	if err := s.Deserialize(data, &empty); err != nil {
+		t.Fatalf("Deserialize failed: %v", err)
+	}
+	if empty.Balance != nil || empty.Share != nil || empty.Debt.Sign() != 0 {
+		t.Errorf("expected empty values, got %+v", empty)
+	}
+}
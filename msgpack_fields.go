@@ -0,0 +1,151 @@
+package serializer
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"math"
+
+	"github.com/vmihailenco/msgpack/v5"
+	"github.com/vmihailenco/msgpack/v5/msgpcode"
+)
+
+// DeserializeFields decodes only the named top-level fields of a map-encoded
+// document into v, skipping every other entry at the wire level without
+// materializing it, and stops reading once all named fields are found. Names are
+// map keys as they appear on the wire, i.e. tag names. Fields of v that are not
+// named, or not present, are left untouched. Documents that are not maps, such
+// as structs encoded as arrays, are decoded in full.
+//
+// It suits hot loops that need a few fields of wide records; for most fields,
+// Deserialize is faster.
+func (s *MsgPackSerializer) DeserializeFields(data []byte, v any, fields ...string) error {
+	if data == nil {
+		return errors.New("data is nil")
+	}
+	if v == nil {
+		return errors.New("output parameter is nil")
+	}
+	s.opts.logSize("deserialize_fields", len(data))
+	extMaps, err := checkMsgpackLengths(data)
+	if err != nil {
+		s.opts.logFailure("deserialize_fields", err)
+		return err
+	}
+
+	buf := scratchBufferPool.Get().(*bytes.Buffer)
+	defer func() {
+		buf.Reset()
+		scratchBufferPool.Put(buf)
+	}()
+	selected, err := selectMsgpackFields(data, fields, buf)
+	if err != nil {
+		s.opts.logFailure("deserialize_fields", err)
+		return err
+	}
+	if selected == nil {
+		selected = data
+	} else {
+		if s.opts.msgpackZeroCopy {
+			// Decoded MsgpackBytes fields alias their input, which must outlive the call
+			selected = bytes.Clone(selected)
+		}
+		if len(extMaps) > 0 {
+			// The offsets moved with the selected entries
+			extMaps, _ = checkMsgpackLengths(selected)
+		}
+	}
+
+	pd := s.getDecoder(selected, extMaps)
+	defer putPooledDecoder(pd)
+	err = s.decode(pd.dec, v)
+	s.opts.logFailure("deserialize_fields", err)
+	return err
+}
+
+// selectMsgpackFields copies the entries of the map in data whose keys are in
+// fields into buf as a new map, and returns it. It returns nil when data does not
+// hold a map.
+func selectMsgpackFields(data []byte, fields []string, buf *bytes.Buffer) ([]byte, error) {
+	pd := getPooledDecoder(data)
+	defer putPooledDecoder(pd)
+	dec, r := pd.dec, pd.reader
+
+	c, err := dec.PeekCode()
+	if err != nil {
+		return nil, err
+	}
+	if !msgpcode.IsFixedMap(c) && c != msgpcode.Map16 && c != msgpcode.Map32 {
+		return nil, nil
+	}
+	n, err := dec.DecodeMapLen()
+	if err != nil {
+		return nil, err
+	}
+
+	// The header is written last, once the count is known, so leave room for the largest one
+	var room [5]byte
+	buf.Write(room[:])
+	found := 0
+	for i := 0; i < n && found < len(fields); i++ {
+		start := len(data) - r.Len()
+		wanted, err := readMsgpackKey(dec, r, data, fields)
+		if err != nil {
+			return nil, err
+		}
+		if err := dec.Skip(); err != nil {
+			return nil, err
+		}
+		if wanted {
+			buf.Write(data[start : len(data)-r.Len()])
+			found++
+		}
+	}
+
+	header := appendMsgpackMapHeader(room[:0], found)
+	out := buf.Bytes()[len(room)-len(header):]
+	copy(out, header)
+	return out, nil
+}
+
+// appendMsgpackMapHeader appends the smallest header for a map of n entries
+func appendMsgpackMapHeader(b []byte, n int) []byte {
+	switch {
+	case n < 16:
+		return append(b, msgpcode.FixedMapLow|byte(n))
+	case n <= math.MaxUint16:
+		return binary.BigEndian.AppendUint16(append(b, msgpcode.Map16), uint16(n))
+	}
+	return binary.BigEndian.AppendUint32(append(b, msgpcode.Map32), uint32(n))
+}
+
+// readMsgpackKey consumes a map key and reports whether it is one of fields.
+// String keys are compared in place; other keys never match.
+func readMsgpackKey(dec *msgpack.Decoder, r *bytes.Reader, data []byte, fields []string) (bool, error) {
+	c, err := dec.PeekCode()
+	if err != nil {
+		return false, err
+	}
+	if !msgpcode.IsString(c) {
+		return false, dec.Skip()
+	}
+	n, err := dec.DecodeBytesLen()
+	if err != nil {
+		return false, err
+	}
+	pos := len(data) - r.Len()
+	if n > r.Len() {
+		return false, io.ErrUnexpectedEOF
+	}
+	if _, err := r.Seek(int64(n), io.SeekCurrent); err != nil {
+		return false, err
+	}
+	key := data[pos : pos+n]
+	for _, field := range fields {
+		if string(key) == field {
+			return true, nil
+		}
+	}
+	return false, nil
+}
@@ -0,0 +1,123 @@
+package serializer
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+)
+
+type selectiveRecord struct {
+	ID      string            `msgpack:"id"`
+	Status  int               `msgpack:"status"`
+	Payload []byte            `msgpack:"payload"`
+	Labels  map[string]string `msgpack:"labels"`
+	Notes   []string          `msgpack:"notes"`
+}
+
+func TestDeserializeFields(t *testing.T) {
+	in := selectiveRecord{
+		ID:      "r-1",
+		Status:  3,
+		Payload: bytes.Repeat([]byte{1}, 1024),
+		Labels:  map[string]string{"env": "prod"},
+		Notes:   []string{"a", "b"},
+	}
+	s := NewMsgpackSerializer().(*MsgPackSerializer)
+	data, err := s.Serialize(in)
+	if err != nil {
+		t.Fatalf("Serialize failed: %v", err)
+	}
+
+	out := selectiveRecord{Notes: []string{"kept"}}
+	if err := s.DeserializeFields(data, &out, "status", "id"); err != nil {
+		t.Fatalf("DeserializeFields failed: %v", err)
+	}
+	if out.ID != "r-1" || out.Status != 3 {
+		t.Errorf("wanted fields not decoded: %+v", out)
+	}
+	if out.Payload != nil || out.Labels != nil || len(out.Notes) != 1 {
+		t.Errorf("unwanted fields were decoded: %+v", out)
+	}
+
+	var m map[string]any
+	if err := s.DeserializeFields(data, &m, "labels", "missing"); err != nil {
+		t.Fatalf("DeserializeFields into map failed: %v", err)
+	}
+	if len(m) != 1 || m["labels"].(map[string]any)["env"] != "prod" {
+		t.Errorf("unexpected map: %#v", m)
+	}
+
+	// Options such as JSON tag fallback and zero copy still apply
+	type jsonTagged struct {
+		Status  int          `json:"status"`
+		Payload MsgpackBytes `json:"payload"`
+	}
+	zero := NewMsgpackSerializer(WithMsgpackJSONTags(), WithMsgpackZeroCopy()).(*MsgPackSerializer)
+	var tagged jsonTagged
+	if err := zero.DeserializeFields(data, &tagged, "status", "payload"); err != nil {
+		t.Fatalf("DeserializeFields failed: %v", err)
+	}
+	if tagged.Status != 3 || !bytes.Equal(tagged.Payload, in.Payload) {
+		t.Errorf("unexpected result: %d %d bytes", tagged.Status, len(tagged.Payload))
+	}
+
+	// Array-encoded structs are decoded in full
+	arrays := NewMsgpackSerializer(WithMsgpackStructAsArray()).(*MsgPackSerializer)
+	arrayData, _ := arrays.Serialize(in)
+	var full selectiveRecord
+	if err := arrays.DeserializeFields(arrayData, &full, "id"); err != nil || full.Status != 3 {
+		t.Errorf("expected a full decode, got %+v (%v)", full, err)
+	}
+
+	if err := s.DeserializeFields(data[:len(data)-10], &out, "notes"); err == nil {
+		t.Error("expected error for truncated input")
+	}
+}
+
+func TestDeserializeFieldsWideMap(t *testing.T) {
+	wide := make(map[string]int, 40)
+	for i := 0; i < 40; i++ {
+		wide[fmt.Sprintf("f%02d", i)] = i
+	}
+	s := NewMsgpackSerializer().(*MsgPackSerializer)
+	data, _ := s.Serialize(wide)
+
+	names := make([]string, 20)
+	for i := range names {
+		names[i] = fmt.Sprintf("f%02d", i*2)
+	}
+	var got map[string]int
+	if err := s.DeserializeFields(data, &got, names...); err != nil {
+		t.Fatalf("DeserializeFields failed: %v", err)
+	}
+	if len(got) != 20 || got["f38"] != 38 {
+		t.Errorf("unexpected result: %v", got)
+	}
+}
+
+func BenchmarkDeserializeFields(b *testing.B) {
+	type record struct {
+		ID     string         `msgpack:"id"`
+		Status int            `msgpack:"status"`
+		Attrs  map[string]int `msgpack:"attrs"`
+	}
+	attrs := make(map[string]int, 40)
+	for i := 0; i < 40; i++ {
+		attrs[fmt.Sprintf("attr%d", i)] = i
+	}
+	s := NewMsgpackSerializer().(*MsgPackSerializer)
+	data, _ := s.Serialize(record{ID: "x", Status: 1, Attrs: attrs})
+
+	b.Run("Deserialize", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			var r record
+			_ = s.Deserialize(data, &r)
+		}
+	})
+	b.Run("DeserializeFields", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			var r record
+			_ = s.DeserializeFields(data, &r, "id", "status")
+		}
+	})
+}
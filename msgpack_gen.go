@@ -0,0 +1,103 @@
+package serializer
+
+import (
+	"reflect"
+	"strings"
+
+	"github.com/vmihailenco/msgpack/v5"
+	"github.com/vmihailenco/msgpack/v5/msgpcode"
+)
+
+// GeneratedMsgpackEncoder is implemented by types with MessagePack encoders written
+// by serializer-gen (see cmd/serializer-gen). The MessagePack serializer prefers it
+// over reflection when its options leave the encoded form unchanged.
+type GeneratedMsgpackEncoder interface {
+	// WriteMsgpack encodes the value with enc
+	WriteMsgpack(enc *msgpack.Encoder) error
+}
+
+// GeneratedMsgpackDecoder is implemented by pointers to types with MessagePack
+// decoders written by serializer-gen
+type GeneratedMsgpackDecoder interface {
+	// ReadMsgpack decodes the next value from dec into the receiver
+	ReadMsgpack(dec *msgpack.Decoder) error
+}
+
+// generatedMsgpack reports whether generated codecs write what reflection would
+// with these options
+func (o *options) generatedMsgpack() bool {
+	return !o.msgpackStructAsArray && !o.omitsEmpty(o.msgpackOmitEmpty) && o.msgpackFallbackTag == "" && !o.canonical
+}
+
+// DecodeMsgpackNil consumes a nil if one comes next and reports whether it did.
+// It is used by generated code.
+func DecodeMsgpackNil(dec *msgpack.Decoder) (bool, error) {
+	c, err := dec.PeekCode()
+	if err != nil || c != msgpcode.Nil {
+		return false, err
+	}
+	return true, dec.DecodeNil()
+}
+
+// DecodeMsgpackKey decodes a map key into buf, reusing its storage, so that generated
+// decoders can match field names without allocating
+func DecodeMsgpackKey(dec *msgpack.Decoder, buf []byte) ([]byte, error) {
+	n, err := dec.DecodeBytesLen()
+	if err != nil || n <= 0 {
+		return buf[:0], err
+	}
+	if cap(buf) < n {
+		buf = make([]byte, n)
+	}
+	buf = buf[:n]
+	return buf, dec.ReadFull(buf)
+}
+
+// IsEmptyMsgpackValue reports whether msgpack omits the field ptr points to when
+// it is tagged omitempty. Generated code uses it for types it has no encoder for.
+func IsEmptyMsgpackValue(ptr any) bool {
+	return isEmptyMsgpack(reflect.ValueOf(ptr).Elem())
+}
+
+// isEmptyMsgpack mirrors the omitempty rules of msgpack's encoder
+func isEmptyMsgpack(v reflect.Value) bool {
+	for v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return true
+		}
+		v = v.Elem()
+	}
+	if z, ok := v.Interface().(isZeroer); ok {
+		switch v.Kind() {
+		case reflect.Chan, reflect.Func, reflect.Map, reflect.Ptr, reflect.Slice:
+			if v.IsNil() {
+				return true
+			}
+		}
+		return z.IsZero()
+	}
+
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64, reflect.Ptr:
+		return v.IsZero()
+	case reflect.Struct:
+		// A struct is empty when every field it would write is omitted
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			name, opts, _ := strings.Cut(f.Tag.Get("msgpack"), ",")
+			if name == "-" || !f.IsExported() {
+				continue
+			}
+			if !strings.Contains(","+opts+",", ",omitempty,") || !isEmptyMsgpack(v.Field(i)) {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
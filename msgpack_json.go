@@ -0,0 +1,308 @@
+package serializer
+
+import (
+	"bytes"
+	"encoding/base64"
+	stdjson "encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/vmihailenco/msgpack/v5"
+	"github.com/vmihailenco/msgpack/v5/msgpcode"
+)
+
+// MsgpackToJSON transcodes one MessagePack document to JSON without knowing its
+// Go type. Map entries keep their order. Binary values become base64 strings, as
+// encoding/json writes []byte; integer and boolean map keys become strings.
+// Extension values are decoded with their registered codec (timestamps become
+// RFC 3339 strings) and written as encoding/json would write them.
+func MsgpackToJSON(data []byte) ([]byte, error) {
+	dec := msgpack.NewDecoder(bytes.NewReader(data))
+	t := msgpackTranscoder{dec: dec}
+	t.enc = stdjson.NewEncoder(&t.out)
+	t.enc.SetEscapeHTML(false)
+	if err := t.value(); err != nil {
+		return nil, err
+	}
+	if _, err := dec.PeekCode(); err != io.EOF {
+		return nil, errors.New("msgpack: unexpected data after top-level value")
+	}
+	return t.out.Bytes(), nil
+}
+
+// msgpackTranscoder writes the MessagePack values read from dec as JSON to out
+type msgpackTranscoder struct {
+	dec *msgpack.Decoder
+	out bytes.Buffer
+	enc *stdjson.Encoder
+}
+
+func (t *msgpackTranscoder) value() error {
+	c, err := t.dec.PeekCode()
+	if err != nil {
+		return err
+	}
+	switch {
+	case c == msgpcode.Nil:
+		t.out.WriteString("null")
+		return t.dec.Skip()
+	case c == msgpcode.False || c == msgpcode.True:
+		b, err := t.dec.DecodeBool()
+		if err != nil {
+			return err
+		}
+		t.out.WriteString(strconv.FormatBool(b))
+		return nil
+	case c == msgpcode.Uint8 || c == msgpcode.Uint16 || c == msgpcode.Uint32 || c == msgpcode.Uint64:
+		n, err := t.dec.DecodeUint64()
+		if err != nil {
+			return err
+		}
+		t.out.Write(strconv.AppendUint(t.out.AvailableBuffer(), n, 10))
+		return nil
+	case msgpcode.IsFixedNum(c) || c == msgpcode.Int8 || c == msgpcode.Int16 || c == msgpcode.Int32 || c == msgpcode.Int64:
+		n, err := t.dec.DecodeInt64()
+		if err != nil {
+			return err
+		}
+		t.out.Write(strconv.AppendInt(t.out.AvailableBuffer(), n, 10))
+		return nil
+	case c == msgpcode.Float:
+		f, err := t.dec.DecodeFloat32()
+		if err != nil {
+			return err
+		}
+		return t.encode(f)
+	case c == msgpcode.Double:
+		f, err := t.dec.DecodeFloat64()
+		if err != nil {
+			return err
+		}
+		return t.encode(f)
+	case msgpcode.IsString(c):
+		s, err := t.dec.DecodeString()
+		if err != nil {
+			return err
+		}
+		return t.encode(s)
+	case msgpcode.IsBin(c):
+		b, err := t.dec.DecodeBytes()
+		if err != nil {
+			return err
+		}
+		return t.encode(base64.StdEncoding.EncodeToString(b))
+	case msgpcode.IsFixedArray(c) || c == msgpcode.Array16 || c == msgpcode.Array32:
+		return t.array()
+	case msgpcode.IsFixedMap(c) || c == msgpcode.Map16 || c == msgpcode.Map32:
+		return t.object()
+	case msgpcode.IsExt(c):
+		v, err := t.dec.DecodeInterface()
+		if err != nil {
+			return err
+		}
+		return t.encode(v)
+	}
+	return fmt.Errorf("msgpack: unexpected code %x", c)
+}
+
+func (t *msgpackTranscoder) array() error {
+	n, err := t.dec.DecodeArrayLen()
+	if err != nil {
+		return err
+	}
+	t.out.WriteByte('[')
+	for i := 0; i < n; i++ {
+		if i > 0 {
+			t.out.WriteByte(',')
+		}
+		if err := t.value(); err != nil {
+			return err
+		}
+	}
+	t.out.WriteByte(']')
+	return nil
+}
+
+func (t *msgpackTranscoder) object() error {
+	n, err := t.dec.DecodeMapLen()
+	if err != nil {
+		return err
+	}
+	t.out.WriteByte('{')
+	for i := 0; i < n; i++ {
+		if i > 0 {
+			t.out.WriteByte(',')
+		}
+		key, err := t.key()
+		if err != nil {
+			return err
+		}
+		if err := t.encode(key); err != nil {
+			return err
+		}
+		t.out.WriteByte(':')
+		if err := t.value(); err != nil {
+			return err
+		}
+	}
+	t.out.WriteByte('}')
+	return nil
+}
+
+// key reads a map key, formatting integer and boolean keys as strings
+func (t *msgpackTranscoder) key() (string, error) {
+	k, err := t.dec.DecodeInterfaceLoose()
+	if err != nil {
+		return "", err
+	}
+	return msgpackKeyString(k)
+}
+
+// msgpackKeyString formats a loosely decoded map key as a string
+func msgpackKeyString(k any) (string, error) {
+	switch k := k.(type) {
+	case string:
+		return k, nil
+	case int64:
+		return strconv.FormatInt(k, 10), nil
+	case uint64:
+		return strconv.FormatUint(k, 10), nil
+	case float64:
+		return strconv.FormatFloat(k, 'g', -1, 64), nil
+	case bool:
+		return strconv.FormatBool(k), nil
+	}
+	return "", fmt.Errorf("msgpack: map key of type %T cannot be converted to a string", k)
+}
+
+// encode writes v as encoding/json would, without the trailing newline
+func (t *msgpackTranscoder) encode(v any) error {
+	if err := t.enc.Encode(v); err != nil {
+		return err
+	}
+	t.out.Truncate(t.out.Len() - 1)
+	return nil
+}
+
+// JSONToMsgpack transcodes one JSON document to MessagePack without knowing its
+// Go type. Object members keep their order. Integers that fit int64 or uint64
+// are written in their most compact integer form and other numbers as float64.
+func JSONToMsgpack(data []byte) ([]byte, error) {
+	data, err := stripBOM(data)
+	if err != nil {
+		return nil, err
+	}
+	dec := stdjson.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	var out bytes.Buffer
+	enc := msgpack.NewEncoder(&out)
+	v, err := readJSONValue(dec)
+	if err != nil {
+		return nil, err
+	}
+	if err := writeJSONValue(enc, v); err != nil {
+		return nil, err
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return nil, errors.New("json: unexpected data after top-level value")
+	}
+	return out.Bytes(), nil
+}
+
+// jsonMember is one member of a JSON object, kept in document order
+type jsonMember struct {
+	key   string
+	value any
+}
+
+// readJSONValue reads one JSON value, representing objects as []jsonMember and
+// arrays as []any so that member order survives. Containers are read whole
+// because MessagePack headers carry their length.
+func readJSONValue(dec *stdjson.Decoder) (any, error) {
+	tok, err := dec.Token()
+	if err == io.EOF {
+		return nil, io.ErrUnexpectedEOF
+	}
+	if err != nil {
+		return nil, err
+	}
+	switch tok {
+	case stdjson.Delim('{'):
+		members := []jsonMember{}
+		for dec.More() {
+			key, err := dec.Token()
+			if err != nil {
+				return nil, err
+			}
+			value, err := readJSONValue(dec)
+			if err != nil {
+				return nil, err
+			}
+			members = append(members, jsonMember{key: key.(string), value: value})
+		}
+		_, err := dec.Token()
+		return members, err
+	case stdjson.Delim('['):
+		elems := []any{}
+		for dec.More() {
+			value, err := readJSONValue(dec)
+			if err != nil {
+				return nil, err
+			}
+			elems = append(elems, value)
+		}
+		_, err := dec.Token()
+		return elems, err
+	}
+	return tok, nil
+}
+
+func writeJSONValue(enc *msgpack.Encoder, v any) error {
+	switch v := v.(type) {
+	case nil:
+		return enc.EncodeNil()
+	case bool:
+		return enc.EncodeBool(v)
+	case string:
+		return enc.EncodeString(v)
+	case stdjson.Number:
+		if n, err := strconv.ParseInt(string(v), 10, 64); err == nil {
+			return enc.EncodeInt(n)
+		}
+		if n, err := strconv.ParseUint(string(v), 10, 64); err == nil {
+			return enc.EncodeUint(n)
+		}
+		f, err := v.Float64()
+		if err != nil {
+			return err
+		}
+		return enc.EncodeFloat64(f)
+	case []jsonMember:
+		if err := enc.EncodeMapLen(len(v)); err != nil {
+			return err
+		}
+		for _, m := range v {
+			if err := enc.EncodeString(m.key); err != nil {
+				return err
+			}
+			if err := writeJSONValue(enc, m.value); err != nil {
+				return err
+			}
+		}
+		return nil
+	case []any:
+		if err := enc.EncodeArrayLen(len(v)); err != nil {
+			return err
+		}
+		for _, elem := range v {
+			if err := writeJSONValue(enc, elem); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return fmt.Errorf("json: unexpected token %v", v)
+}
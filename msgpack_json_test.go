@@ -0,0 +1,93 @@
+package serializer
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestMsgpackToJSON(t *testing.T) {
+	type record struct {
+		Name    string         `msgpack:"name"`
+		Count   int64          `msgpack:"count"`
+		Big     uint64         `msgpack:"big"`
+		Ratio   float64        `msgpack:"ratio"`
+		Small   float32        `msgpack:"small"`
+		Payload []byte         `msgpack:"payload"`
+		Tags    []string       `msgpack:"tags"`
+		Nested  map[int]bool   `msgpack:"nested"`
+		When    time.Time      `msgpack:"when"`
+		Extra   map[string]any `msgpack:"extra"`
+	}
+	data, err := NewMsgpackSerializer().Serialize(record{
+		Name:    "<a&b>",
+		Count:   -42,
+		Big:     1 << 63,
+		Ratio:   0.1,
+		Small:   0.1,
+		Payload: []byte("hi"),
+		Tags:    []string{"x"},
+		Nested:  map[int]bool{7: true},
+		When:    time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+	})
+	if err != nil {
+		t.Fatalf("Serialize failed: %v", err)
+	}
+
+	got, err := MsgpackToJSON(data)
+	if err != nil {
+		t.Fatalf("MsgpackToJSON failed: %v", err)
+	}
+	want := `{"name":"<a&b>","count":-42,"big":9223372036854775808,"ratio":0.1,"small":0.1,"payload":"aGk=",` +
+		`"tags":["x"],"nested":{"7":true},"when":"2024-01-02T03:04:05Z","extra":null}`
+	if string(got) != want {
+		t.Errorf("unexpected JSON:\n got %s\nwant %s", got, want)
+	}
+
+	if _, err := MsgpackToJSON(append(data, 0xc0)); err == nil {
+		t.Error("expected error for trailing data")
+	}
+	if _, err := MsgpackToJSON(data[:len(data)-2]); err == nil {
+		t.Error("expected error for truncated input")
+	}
+}
+
+func TestJSONToMsgpack(t *testing.T) {
+	in := `{"z":1,"a":[true,null,"s",-5,18446744073709551615,1.5,{}],"n":{"k":"v"}}`
+	data, err := JSONToMsgpack([]byte(in))
+	if err != nil {
+		t.Fatalf("JSONToMsgpack failed: %v", err)
+	}
+
+	// Member order survives the round trip
+	back, err := MsgpackToJSON(data)
+	if err != nil {
+		t.Fatalf("MsgpackToJSON failed: %v", err)
+	}
+	if string(back) != in {
+		t.Errorf("round trip mismatch:\n got %s\nwant %s", back, in)
+	}
+
+	// Integers take their compact msgpack form
+	small, err := JSONToMsgpack([]byte("5"))
+	if err != nil || !bytes.Equal(small, []byte{0x05}) {
+		t.Errorf("expected positive fixint, got %x (%v)", small, err)
+	}
+
+	var decoded struct {
+		Z int               `msgpack:"z"`
+		N map[string]string `msgpack:"n"`
+	}
+	if err := NewMsgpackSerializer().Deserialize(data, &decoded); err != nil {
+		t.Fatalf("Deserialize failed: %v", err)
+	}
+	if decoded.Z != 1 || decoded.N["k"] != "v" {
+		t.Errorf("unexpected decoded value: %+v", decoded)
+	}
+
+	for _, bad := range []string{`{"a":}`, `[1,2`, `1 2`, ``} {
+		if _, err := JSONToMsgpack([]byte(bad)); err == nil {
+			t.Errorf("expected error for %q", bad)
+		}
+	}
+}
@@ -0,0 +1,184 @@
+package serializer
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"slices"
+
+	"github.com/vmihailenco/msgpack/v5"
+	"github.com/vmihailenco/msgpack/v5/msgpcode"
+)
+
+// checkMsgpackLengths reports an error when a header in the first value of data
+// declares more bytes or elements than the rest of data could hold. The msgpack
+// library preallocates []any and map[string]any values to their declared length,
+// as do extension decoders, so a few forged bytes could otherwise ask for gigabytes.
+//
+// Every element takes at least one byte, so the check walks the value keeping
+// only a count of the elements still owed. Malformed input it does not look into,
+// such as unknown codes, is left for the decoder to reject.
+//
+// The library also reads a map out of an extension when it decodes a map type,
+// trusting the length behind the extension header. Extension data is opaque, so
+// rather than rejecting extensions whose data opens with a map header that data
+// could not back, it returns their offsets for an inputReader to stop at.
+func checkMsgpackLengths(data []byte) (extMaps []int, err error) {
+	owed := uint64(1)
+	for pos := 0; owed > 0 && pos < len(data); {
+		c := data[pos]
+		pos++
+		owed--
+
+		var elems, skip, sized uint64
+		switch {
+		case c <= 0x7f || c >= 0xe0 || c == 0xc0 || c == 0xc1 || c == 0xc2 || c == 0xc3:
+		case c <= 0x8f: // fixmap
+			elems = 2 * uint64(c&0x0f)
+		case c <= 0x9f: // fixarray
+			elems = uint64(c & 0x0f)
+		case c <= 0xbf: // fixstr
+			skip = uint64(c & 0x1f)
+		case c == 0xc4 || c == 0xd9: // bin8, str8
+			sized = 1
+		case c == 0xc5 || c == 0xda: // bin16, str16
+			sized = 2
+		case c == 0xc6 || c == 0xdb: // bin32, str32
+			sized = 4
+		case c == 0xc7, c == 0xc8, c == 0xc9: // ext8, ext16, ext32
+			sized = 1 << (c - 0xc7)
+			skip = 1 // type byte
+		case c == 0xca: // float32
+			skip = 4
+		case c == 0xcb: // float64
+			skip = 8
+		case c >= 0xcc && c <= 0xcf: // uint8..uint64
+			skip = 1 << (c - 0xcc)
+		case c >= 0xd0 && c <= 0xd3: // int8..int64
+			skip = 1 << (c - 0xd0)
+		case c >= 0xd4 && c <= 0xd8: // fixext1..fixext16
+			skip = 1 + 1<<(c-0xd4)
+		case c == 0xdc || c == 0xdd: // array16, array32
+			n, ok := readMsgpackLength(data[pos:], 2<<(c-0xdc))
+			if !ok {
+				return extMaps, nil
+			}
+			pos += 2 << (c - 0xdc)
+			elems = n
+		case c == 0xde || c == 0xdf: // map16, map32
+			n, ok := readMsgpackLength(data[pos:], 2<<(c-0xde))
+			if !ok {
+				return extMaps, nil
+			}
+			pos += 2 << (c - 0xde)
+			elems = 2 * n
+		}
+
+		if sized > 0 {
+			n, ok := readMsgpackLength(data[pos:], int(sized))
+			if !ok {
+				return extMaps, nil
+			}
+			pos += int(sized)
+			skip += n
+		}
+		if skip > uint64(len(data)-pos) {
+			return nil, fmt.Errorf("msgpack: %d bytes declared with %d left: %w", skip, len(data)-pos, io.ErrUnexpectedEOF)
+		}
+		if c >= 0xc7 && c <= 0xc9 || c >= 0xd4 && c <= 0xd8 {
+			// The type byte comes first
+			if skip > 1 && unbackedMsgpackMap(data[pos+1:]) {
+				extMaps = append(extMaps, pos+1)
+			}
+		}
+		pos += int(skip)
+
+		owed += elems
+		if owed > uint64(len(data)-pos) {
+			return nil, fmt.Errorf("msgpack: %d elements declared with %d bytes left: %w", owed, len(data)-pos, io.ErrUnexpectedEOF)
+		}
+	}
+	return extMaps, nil
+}
+
+// unbackedMsgpackMap reports whether data opens with a map header declaring more
+// entries than the rest of data could hold
+func unbackedMsgpackMap(data []byte) bool {
+	var n uint64
+	size := 1
+	switch c := data[0]; {
+	case msgpcode.IsFixedMap(c):
+		n = uint64(c & 0x0f)
+	case c == msgpcode.Map16 || c == msgpcode.Map32:
+		var ok bool
+		if n, ok = readMsgpackLength(data[1:], 2<<(c-0xde)); !ok {
+			return false
+		}
+		size += 2 << (c - 0xde)
+	default:
+		return false
+	}
+	return 2*n > uint64(len(data)-size)
+}
+
+// readMsgpackLength reads a big-endian length of size bytes
+func readMsgpackLength(b []byte, size int) (uint64, bool) {
+	if len(b) < size {
+		return 0, false
+	}
+	switch size {
+	case 1:
+		return uint64(b[0]), true
+	case 2:
+		return uint64(binary.BigEndian.Uint16(b)), true
+	default:
+		return uint64(binary.BigEndian.Uint32(b)), true
+	}
+}
+
+// decodeStream reads the next value from a stream. The value is read whole
+// before it is decoded, which bounds its lengths by the stream, then checked
+// like any other input.
+func (s *MsgPackSerializer) decodeStream(dec *msgpack.Decoder, v any) error {
+	raw, err := dec.DecodeRaw()
+	if err != nil {
+		return err
+	}
+	extMaps, err := checkMsgpackLengths(raw)
+	if err != nil {
+		return err
+	}
+	pd := s.getDecoder(raw, extMaps)
+	defer putPooledDecoder(pd)
+	return s.decode(pd.dec, v)
+}
+
+// inputReader reads a pooled decoder's input when the decoder needs to know its
+// position in it: to alias MsgpackBytes values, or to fail instead of reading a
+// map header at one of the offsets checkMsgpackLengths returned, where the
+// library would size a map it cannot fill. Decoders read codes with ReadByte
+// and other data with Read, so extensions read as such are not affected.
+type inputReader struct {
+	*bytes.Reader
+	data    []byte
+	alias   bool
+	extMaps []int
+}
+
+func (r *inputReader) ReadByte() (byte, error) {
+	if pos := len(r.data) - r.Len(); slices.Contains(r.extMaps, pos) {
+		return 0, fmt.Errorf("msgpack: map in extension at offset %d declares more entries than the input holds: %w", pos, io.ErrUnexpectedEOF)
+	}
+	return r.Reader.ReadByte()
+}
+
+// watchInput makes pd's decoder read data through an inputReader when aliasing
+// or extMaps need one
+func (pd *pooledDecoder) watchInput(data []byte, alias bool, extMaps []int) {
+	if !alias && len(extMaps) == 0 {
+		return
+	}
+	pd.input = inputReader{Reader: pd.reader, data: data, alias: alias, extMaps: extMaps}
+	pd.dec.Reset(&pd.input)
+}
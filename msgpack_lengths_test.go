@@ -0,0 +1,138 @@
+package serializer
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"runtime"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+func TestCheckMsgpackLengths(t *testing.T) {
+	valid := []any{
+		map[string]any{"a": []any{1, "x", nil}, "b": map[string]any{}},
+		[]any{time.Unix(1, 935_000_000).UTC(), time.Unix(1<<40, 0).UTC(), []byte{0xdf, 0xff}},
+		strings.Repeat("s", 70000),
+		[]int64{-1, 1 << 40},
+		3.5,
+	}
+	for _, v := range valid {
+		data, err := msgpack.Marshal(v)
+		if err != nil {
+			t.Fatalf("Marshal failed: %v", err)
+		}
+		if _, err := checkMsgpackLengths(data); err != nil {
+			t.Errorf("checkMsgpackLengths rejected %T: %v", v, err)
+		}
+		// Trailing data and truncated headers are left to the decoder
+		if _, err := checkMsgpackLengths(append(data, 0xdd)); err != nil {
+			t.Errorf("checkMsgpackLengths rejected trailing data after %T: %v", v, err)
+		}
+	}
+
+	forged := map[string][]byte{
+		"array32":        []byte("\xdd\x7f\xff\xff\xff\x10"),
+		"map16":          []byte("\xde\x00\x02\xa1a\x01"),
+		"nested array":   []byte("\x91\x91\xdc\x00\x05\x01\x02"),
+		"str32":          []byte("\xdb\x00\x10\x00\x00abc"),
+		"ext32":          []byte("\xc9\x7f\xff\xff\x65\x01"),
+		"fixext too big": []byte("\xd8\x01abc"),
+	}
+	for name, data := range forged {
+		if _, err := checkMsgpackLengths(data); !errors.Is(err, io.ErrUnexpectedEOF) {
+			t.Errorf("%s: checkMsgpackLengths = %v, want io.ErrUnexpectedEOF", name, err)
+		}
+	}
+
+	// Extensions are accepted, but data opening with a map header it cannot back is reported
+	extMaps := map[string]struct {
+		data []byte
+		want []int
+	}{
+		"map32":          {[]byte("\xd7h\xdfell,k \x81\x1c"), []int{2}},
+		"nested":         {[]byte("\x92\x01\xc7\x03h\x83\x01\x02"), []int{5}},
+		"backed fixmap":  {[]byte("\xd6h\x81\x01\x02\x03"), nil},
+		"not a map":      {[]byte("\xd6habcd"), nil},
+		"empty ext data": {[]byte("\xc7\x00h"), nil},
+	}
+	for name, tt := range extMaps {
+		got, err := checkMsgpackLengths(tt.data)
+		if err != nil || !slices.Equal(got, tt.want) {
+			t.Errorf("%s: checkMsgpackLengths = %v, %v, want %v", name, got, err, tt.want)
+		}
+	}
+}
+
+// Each input declares gigabytes of content in a few bytes. Decoding must fail
+// without allocating it on every path.
+func TestMsgpackForgedLengthsDoNotAllocate(t *testing.T) {
+	inputs := [][]byte{
+		[]byte("\xdd\x7f\xff\xff\xff\x10\xf9\xff"),      // []any of 2^31 elements
+		[]byte("\x81\xa1a\xdf\x7f\xff\xff\xff\x01\x02"), // map[string]any of 2^31 entries
+		[]byte("\xd7h\xdfell,k \x81\x1c"),               // map after an extension header
+		[]byte("\x81\xa1M\xd7h\xdfell,k \x81\x1c"),      // the same in a field
+		[]byte("\xc9\x7f\xff\xffe\x01"),                 // 2GB extension
+	}
+	targets := []func() any{
+		func() any { return new(any) },
+		func() any { return new(map[string]any) },
+		func() any { return new(map[any]any) },
+		func() any { return new(struct{ M map[string]any }) },
+	}
+	s := NewMsgpackSerializer().(*MsgPackSerializer)
+	for _, data := range inputs {
+		for _, newTarget := range targets {
+			var before, after runtime.MemStats
+			runtime.ReadMemStats(&before)
+			if err := s.Deserialize(data, newTarget()); err == nil {
+				t.Errorf("Deserialize(%q) into %T succeeded", data, newTarget())
+			}
+			if err := s.DeserializeString(string(data), newTarget()); err == nil {
+				t.Errorf("DeserializeString(%q) into %T succeeded", data, newTarget())
+			}
+			if err := s.DeserializeFrom(bytes.NewReader(data), newTarget()); err == nil {
+				t.Errorf("DeserializeFrom(%q) into %T succeeded", data, newTarget())
+			}
+			runtime.ReadMemStats(&after)
+			if n := after.TotalAlloc - before.TotalAlloc; n > 16<<20 {
+				t.Errorf("decoding %q into %T allocated %d bytes", data, newTarget(), n)
+			}
+		}
+	}
+}
+
+// Extension data that only looks like an oversized map header decodes as usual
+func TestMsgpackExtensionsResemblingMaps(t *testing.T) {
+	// The 8-byte timestamp of these nanoseconds opens with 0xde, a map16 header
+	at := time.Unix(1, 935_000_000).UTC()
+	data, err := msgpack.Marshal(struct {
+		At time.Time
+		M  map[string]any
+	}{At: at, M: map[string]any{"a": 1}})
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	if extMaps, _ := checkMsgpackLengths(data); len(extMaps) != 1 {
+		t.Fatalf("expected the timestamp to be reported, got %v", extMaps)
+	}
+
+	var out struct {
+		At time.Time
+		M  map[string]any
+	}
+	s := NewMsgpackSerializer()
+	if err := s.Deserialize(data, &out); err != nil {
+		t.Fatalf("Deserialize failed: %v", err)
+	}
+	if !out.At.Equal(at) || out.M["a"] != int8(1) {
+		t.Errorf("unexpected result: %+v", out)
+	}
+	if err := s.DeserializeFrom(bytes.NewReader(data), &out); err != nil {
+		t.Errorf("DeserializeFrom failed: %v", err)
+	}
+}
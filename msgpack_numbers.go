@@ -0,0 +1,142 @@
+package serializer
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+)
+
+// ErrLossyNumber is returned when a decoded MessagePack number cannot be
+// represented exactly in the type selected by WithMsgpackNumberMode
+var ErrLossyNumber = errors.New("msgpack number cannot be represented exactly")
+
+// MsgpackNumberMode selects the Go types MessagePack numbers decode to when the
+// target is an interface, such as the values of a map[string]any
+type MsgpackNumberMode int
+
+const (
+	// MsgpackNumbersSized keeps the width of the wire encoding: int8 through int64,
+	// uint8 through uint64, float32 or float64 (the default). Small values often
+	// arrive as int8 or uint8.
+	MsgpackNumbersSized MsgpackNumberMode = iota
+	// MsgpackNumbersWide decodes signed integers as int64, unsigned integers as
+	// uint64 and floats as float64. Which integer type a value gets depends on how
+	// the writer encoded it.
+	MsgpackNumbersWide
+	// MsgpackNumbersInt64 decodes all integers as int64 and floats as float64.
+	// Unsigned values above math.MaxInt64 fail with ErrLossyNumber.
+	MsgpackNumbersInt64
+	// MsgpackNumbersFloat64 decodes every number as float64, as encoding/json does.
+	// Integers beyond ±2^53, which float64 cannot hold exactly, fail with ErrLossyNumber.
+	MsgpackNumbersFloat64
+)
+
+// WithMsgpackNumberMode sets the types numbers decode to in interface targets, so
+// code handling both JSON and MessagePack payloads sees the same types.
+// Typed targets such as int or float32 fields are unaffected.
+func WithMsgpackNumberMode(mode MsgpackNumberMode) Option {
+	return func(o *options) {
+		o.msgpackNumberMode = mode
+	}
+}
+
+// maxExactFloat is the largest magnitude up to which float64 holds every integer
+const maxExactFloat = 1 << 53
+
+// convertMsgpackNumbers rewrites the int64 and uint64 values that loose interface
+// decoding left in interface values reachable from v to the types of mode
+func convertMsgpackNumbers(v reflect.Value, mode MsgpackNumberMode) error {
+	switch v.Kind() {
+	case reflect.Pointer:
+		if !v.IsNil() {
+			return convertMsgpackNumbers(v.Elem(), mode)
+		}
+	case reflect.Interface:
+		if v.IsNil() {
+			return nil
+		}
+		elem := v.Elem()
+		switch elem.Kind() {
+		case reflect.Int64, reflect.Uint64:
+			converted, err := convertMsgpackNumber(elem, mode)
+			if err != nil {
+				return err
+			}
+			if v.CanSet() {
+				v.Set(converted)
+			}
+		case reflect.Map, reflect.Slice, reflect.Pointer:
+			return convertMsgpackNumbers(elem, mode)
+		}
+	case reflect.Map:
+		if isScalarKind(v.Type().Elem().Kind()) {
+			return nil
+		}
+		iter := v.MapRange()
+		for iter.Next() {
+			value := reflect.New(v.Type().Elem()).Elem()
+			value.Set(iter.Value())
+			if err := convertMsgpackNumbers(value, mode); err != nil {
+				return err
+			}
+			v.SetMapIndex(iter.Key(), value)
+		}
+	case reflect.Slice, reflect.Array:
+		if isScalarKind(v.Type().Elem().Kind()) {
+			return nil
+		}
+		for i := 0; i < v.Len(); i++ {
+			if err := convertMsgpackNumbers(v.Index(i), mode); err != nil {
+				return err
+			}
+		}
+	case reflect.Struct:
+		t := v.Type()
+		for i := 0; i < v.NumField(); i++ {
+			if !t.Field(i).IsExported() {
+				continue
+			}
+			if err := convertMsgpackNumbers(v.Field(i), mode); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// convertMsgpackNumber converts an int64 or uint64 to the type mode asks for
+func convertMsgpackNumber(n reflect.Value, mode MsgpackNumberMode) (reflect.Value, error) {
+	if n.Kind() == reflect.Uint64 {
+		u := n.Uint()
+		switch {
+		case mode == MsgpackNumbersInt64 && u > math.MaxInt64:
+			return reflect.Value{}, fmt.Errorf("%w: %d as int64", ErrLossyNumber, u)
+		case mode == MsgpackNumbersInt64:
+			return reflect.ValueOf(int64(u)), nil
+		case u > maxExactFloat:
+			return reflect.Value{}, fmt.Errorf("%w: %d as float64", ErrLossyNumber, u)
+		}
+		return reflect.ValueOf(float64(u)), nil
+	}
+	i := n.Int()
+	if mode == MsgpackNumbersInt64 {
+		return n, nil
+	}
+	if i > maxExactFloat || i < -maxExactFloat {
+		return reflect.Value{}, fmt.Errorf("%w: %d as float64", ErrLossyNumber, i)
+	}
+	return reflect.ValueOf(float64(i)), nil
+}
+
+// isScalarKind reports whether values of kind k cannot contain interface values
+func isScalarKind(k reflect.Kind) bool {
+	switch k {
+	case reflect.Bool, reflect.String,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64, reflect.Complex64, reflect.Complex128:
+		return true
+	}
+	return false
+}
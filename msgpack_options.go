@@ -0,0 +1,205 @@
+package serializer
+
+import (
+	"bytes"
+	"encoding/binary"
+	"time"
+
+	"github.com/vmihailenco/msgpack/v5"
+	"github.com/vmihailenco/msgpack/v5/msgpcode"
+)
+
+// MsgpackTimeFormat selects how time.Time values are written to MessagePack
+type MsgpackTimeFormat int
+
+const (
+	// MsgpackTimeNanoseconds writes the timestamp extension (type -1) with full precision (the default)
+	MsgpackTimeNanoseconds MsgpackTimeFormat = iota
+	// MsgpackTimeMilliseconds truncates to milliseconds before writing the timestamp extension
+	MsgpackTimeMilliseconds
+	// MsgpackTimeSeconds truncates to whole seconds, which keeps timestamps before
+	// 2106 in the 4-byte form of the extension
+	MsgpackTimeSeconds
+	// MsgpackTimeRFC3339 writes an RFC 3339 string in UTC with nanoseconds, for
+	// consumers without timestamp extension support
+	MsgpackTimeRFC3339
+)
+
+// WithMsgpackTimeFormat sets how time.Time values are encoded, wherever they
+// appear. Top-level time.Time targets and MsgpackTime fields decode every format
+// whatever the setting; time.Time fields decode the timestamp extension only.
+func WithMsgpackTimeFormat(format MsgpackTimeFormat) Option {
+	return func(o *options) {
+		o.msgpackTimeFormat = format
+	}
+}
+
+// WithMsgpackStructAsArray encodes structs as arrays of field values in declaration
+// order instead of maps keyed by field name, as if every struct were tagged
+// `msgpack:",as_array"`. Wide structs shrink considerably, but readers must share
+// the exact field order, so only use it when struct layouts are stable. Decoding
+// accepts both forms.
+func WithMsgpackStructAsArray() Option {
+	return func(o *options) {
+		o.msgpackStructAsArray = true
+	}
+}
+
+// WithMsgpackJSONTags names struct fields after their `json` tags when they have no
+// `msgpack` tag, so structs don't need both. Fields with neither tag keep their Go
+// name. Tag options such as omitempty and "-" are honoured from whichever tag is used.
+func WithMsgpackJSONTags() Option {
+	return func(o *options) {
+		o.msgpackFallbackTag = "json"
+	}
+}
+
+// WithMsgpackOmitEmpty leaves out struct fields holding empty values, as if every
+// field were tagged omitempty: false, 0, "", nil pointers and interfaces, empty
+// slices and maps, and values whose IsZero method reports true (such as time.Time).
+// It suits sparse records whose struct tags can't be changed.
+func WithMsgpackOmitEmpty() Option {
+	return func(o *options) {
+		o.msgpackOmitEmpty = true
+	}
+}
+
+// WithMsgpackCompactFloats writes float64 values that float32 holds exactly, such
+// as 0.5 or 1024, as 5-byte float32s instead of 9-byte float64s. Sensor readings
+// and other telemetry with few significant digits shrink the most. Typed float64
+// targets decode them unchanged; interface targets get a float32 unless the reader
+// uses WithMsgpackNumberMode.
+func WithMsgpackCompactFloats() Option {
+	return func(o *options) {
+		o.msgpackCompactFloats = true
+	}
+}
+
+// WithMsgpackStringKeys decodes maps in interface targets as map[string]any even
+// when their keys are not strings, formatting integer, float and boolean keys as
+// strings, so decoded data can always be re-encoded as JSON. Nested maps are
+// converted as well. Without it, such maps fail to decode.
+func WithMsgpackStringKeys() Option {
+	return func(o *options) {
+		o.msgpackStringKeys = true
+	}
+}
+
+var (
+	_ msgpack.CustomEncoder = MsgpackTime{}
+	_ msgpack.CustomDecoder = (*MsgpackTime)(nil)
+)
+
+// MsgpackTime is a time.Time that also decodes from RFC 3339 strings and from
+// extension type 13, as written by WithMsgpackTimeFormat(MsgpackTimeRFC3339) and
+// some JavaScript libraries. The msgpack library decodes time.Time fields from
+// the timestamp extension only, so declare fields that may receive other forms as
+// MsgpackTime. It encodes like time.Time, in the serializer's time format.
+type MsgpackTime struct {
+	time.Time
+}
+
+// EncodeMsgpack implements msgpack.CustomEncoder
+func (t MsgpackTime) EncodeMsgpack(enc *msgpack.Encoder) error {
+	return enc.EncodeTime(t.Time)
+}
+
+// DecodeMsgpack implements msgpack.CustomDecoder
+func (t *MsgpackTime) DecodeMsgpack(dec *msgpack.Decoder) error {
+	c, err := dec.PeekCode()
+	if err != nil {
+		return err
+	}
+	if c == msgpcode.Nil {
+		t.Time = time.Time{}
+		return dec.DecodeNil()
+	}
+	tm, err := dec.DecodeTime()
+	if err != nil {
+		return err
+	}
+	t.Time = tm
+	return nil
+}
+
+// rewriteMsgpackTimes rewrites every timestamp extension in the encoded data in
+// buf in format. The msgpack library writes time.Time values with nanoseconds
+// wherever they appear, and only its process-wide type table could change that,
+// so the format is applied to its output instead. Containers count elements
+// rather than bytes, so values may change size.
+func rewriteMsgpackTimes(buf *bytes.Buffer, format MsgpackTimeFormat) error {
+	scratch := scratchBufferPool.Get().(*bytes.Buffer)
+	defer func() {
+		scratch.Reset()
+		scratchBufferPool.Put(scratch)
+	}()
+
+	data := buf.Bytes()
+	copied := 0
+	for r := 0; r < len(data); {
+		n, err := msgpackTokenLen(data[r:])
+		if err != nil {
+			return err
+		}
+		if tm, ok := msgpackTimestamp(data[r : r+n]); ok {
+			scratch.Write(data[copied:r])
+			scratch.Write(appendMsgpackTime(scratch.AvailableBuffer(), tm, format))
+			copied = r + n
+		}
+		r += n
+	}
+	if copied == 0 {
+		return nil
+	}
+	scratch.Write(data[copied:])
+	buf.Reset()
+	buf.Write(scratch.Bytes())
+	return nil
+}
+
+// msgpackTimestamp decodes tok if it is a timestamp extension (type -1)
+func msgpackTimestamp(tok []byte) (time.Time, bool) {
+	switch {
+	case len(tok) == 6 && tok[0] == msgpcode.FixExt4 && tok[1] == 0xff:
+		return time.Unix(int64(binary.BigEndian.Uint32(tok[2:])), 0), true
+	case len(tok) == 10 && tok[0] == msgpcode.FixExt8 && tok[1] == 0xff:
+		data := binary.BigEndian.Uint64(tok[2:])
+		return time.Unix(int64(data&0x3ffffffff), int64(data>>34)), true
+	case len(tok) == 15 && tok[0] == msgpcode.Ext8 && tok[1] == 12 && tok[2] == 0xff:
+		return time.Unix(int64(binary.BigEndian.Uint64(tok[7:])), int64(binary.BigEndian.Uint32(tok[3:]))), true
+	}
+	return time.Time{}, false
+}
+
+// appendMsgpackTime appends tm in format: an RFC 3339 string in UTC, or the
+// smallest timestamp extension holding it, as the library writes them
+func appendMsgpackTime(b []byte, tm time.Time, format MsgpackTimeFormat) []byte {
+	switch format {
+	case MsgpackTimeMilliseconds:
+		tm = tm.Truncate(time.Millisecond)
+	case MsgpackTimeSeconds:
+		tm = tm.Truncate(time.Second)
+	case MsgpackTimeRFC3339:
+		s := tm.UTC().Format(time.RFC3339Nano)
+		if len(s) <= 31 {
+			b = append(b, msgpcode.FixedStrLow|byte(len(s)))
+		} else {
+			b = append(b, msgpcode.Str8, byte(len(s)))
+		}
+		return append(b, s...)
+	}
+
+	secs := uint64(tm.Unix())
+	if secs>>34 == 0 {
+		data := uint64(tm.Nanosecond())<<34 | secs
+		if data>>32 == 0 {
+			b = append(b, msgpcode.FixExt4, 0xff)
+			return binary.BigEndian.AppendUint32(b, uint32(data))
+		}
+		b = append(b, msgpcode.FixExt8, 0xff)
+		return binary.BigEndian.AppendUint64(b, data)
+	}
+	b = append(b, msgpcode.Ext8, 12, 0xff)
+	b = binary.BigEndian.AppendUint32(b, uint32(tm.Nanosecond()))
+	return binary.BigEndian.AppendUint64(b, secs)
+}
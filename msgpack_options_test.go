@@ -0,0 +1,489 @@
+package serializer
+
+import (
+	"bytes"
+	stdjson "encoding/json"
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+type timedEvent struct {
+	At   time.Time  `msgpack:"at"`
+	Seen *time.Time `msgpack:"seen"`
+}
+
+type lenientTimedEvent struct {
+	At   MsgpackTime  `msgpack:"at"`
+	Seen *MsgpackTime `msgpack:"seen"`
+}
+
+func TestWithMsgpackTimeFormat(t *testing.T) {
+	at := time.Date(2024, 5, 6, 7, 8, 9, 123456789, time.UTC)
+	in := timedEvent{At: at, Seen: &at}
+
+	tests := []struct {
+		format MsgpackTimeFormat
+		want   time.Time
+	}{
+		{MsgpackTimeNanoseconds, at},
+		{MsgpackTimeMilliseconds, at.Truncate(time.Millisecond)},
+		{MsgpackTimeSeconds, at.Truncate(time.Second)},
+		{MsgpackTimeRFC3339, at},
+	}
+	for _, tt := range tests {
+		s := NewMsgpackSerializer(WithMsgpackTimeFormat(tt.format)).(*MsgPackSerializer)
+
+		data, err := s.Serialize(in)
+		if err != nil {
+			t.Fatalf("format %d: Serialize failed: %v", tt.format, err)
+		}
+		var buf bytes.Buffer
+		if err := s.SerializeTo(&buf, in); err != nil {
+			t.Fatalf("format %d: SerializeTo failed: %v", tt.format, err)
+		}
+		if !bytes.Equal(buf.Bytes(), data) {
+			t.Errorf("format %d: SerializeTo and Serialize disagree", tt.format)
+		}
+		pb, err := s.SerializePooled(in)
+		if err != nil {
+			t.Fatalf("format %d: SerializePooled failed: %v", tt.format, err)
+		}
+		if !bytes.Equal(pb.Bytes(), data) {
+			t.Errorf("format %d: SerializePooled and Serialize disagree", tt.format)
+		}
+		pb.Release()
+
+		hasString := bytes.Contains(data, []byte(at.Format(time.RFC3339Nano)))
+		if hasString != (tt.format == MsgpackTimeRFC3339) {
+			t.Errorf("format %d: unexpected encoding %x", tt.format, data)
+		}
+
+		// Any serializer decodes every format into MsgpackTime fields
+		var out lenientTimedEvent
+		if err := NewMsgpackSerializer().Deserialize(data, &out); err != nil {
+			t.Fatalf("format %d: Deserialize failed: %v", tt.format, err)
+		}
+		if !out.At.Equal(tt.want) || out.Seen == nil || !out.Seen.Equal(tt.want) {
+			t.Errorf("format %d: expected %v, got %+v", tt.format, tt.want, out)
+		}
+
+		// and timestamps into time.Time fields
+		var plain timedEvent
+		err = NewMsgpackSerializer().Deserialize(data, &plain)
+		if tt.format == MsgpackTimeRFC3339 {
+			if err == nil {
+				t.Error("expected time.Time fields to reject strings")
+			}
+		} else if err != nil || !plain.At.Equal(tt.want) {
+			t.Errorf("format %d: expected %v, got %+v (%v)", tt.format, tt.want, plain, err)
+		}
+	}
+
+	// Values are formatted wherever they appear
+	nested := map[string]any{"events": []any{in, at}}
+	data, err := NewMsgpackSerializer(WithMsgpackTimeFormat(MsgpackTimeRFC3339)).Serialize(nested)
+	if err != nil {
+		t.Fatalf("Serialize failed: %v", err)
+	}
+	if n := bytes.Count(data, []byte(at.Format(time.RFC3339Nano))); n != 3 {
+		t.Errorf("expected 3 RFC 3339 strings, got %d in %x", n, data)
+	}
+
+	// Seconds fit the 4-byte timestamp: fixext4 (0xd6) followed by type -1
+	data, err = NewMsgpackSerializer(WithMsgpackTimeFormat(MsgpackTimeSeconds)).Serialize(at)
+	if err != nil {
+		t.Fatalf("Serialize failed: %v", err)
+	}
+	if len(data) != 6 || data[0] != 0xd6 || data[1] != 0xff {
+		t.Errorf("expected 4-byte timestamp, got %x", data)
+	}
+}
+
+func TestMsgpackTimeDefaultsUnchanged(t *testing.T) {
+	at := time.Date(2024, 5, 6, 7, 8, 9, 123456789, time.UTC)
+	ours, err := NewMsgpackSerializer().Serialize(timedEvent{At: at})
+	if err != nil {
+		t.Fatalf("Serialize failed: %v", err)
+	}
+	library, err := msgpack.Marshal(timedEvent{At: at})
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	if !bytes.Equal(ours, library) {
+		t.Errorf("expected library encoding %x, got %x", library, ours)
+	}
+
+	// The library's own codecs are left alone
+	s := NewMsgpackSerializer(WithMsgpackTimeFormat(MsgpackTimeRFC3339))
+	if _, err := s.Serialize(timedEvent{At: at}); err != nil {
+		t.Fatalf("Serialize failed: %v", err)
+	}
+	if again, _ := msgpack.Marshal(timedEvent{At: at}); !bytes.Equal(again, library) {
+		t.Errorf("expected library encoding %x after using the serializer, got %x", library, again)
+	}
+
+	// The library cannot decode nil into time.Time fields, MsgpackTime can
+	nilSeen, _ := msgpack.Marshal(map[string]any{"at": nil, "seen": nil})
+	var out timedEvent
+	if err := NewMsgpackSerializer().Deserialize(nilSeen, &out); err == nil {
+		t.Error("expected an error decoding nil into a time.Time field")
+	}
+	lenient := lenientTimedEvent{At: MsgpackTime{at}}
+	if err := NewMsgpackSerializer().Deserialize(nilSeen, &lenient); err != nil {
+		t.Fatalf("Deserialize nil times failed: %v", err)
+	}
+	if !lenient.At.IsZero() || lenient.Seen != nil {
+		t.Errorf("expected zero values, got %+v", lenient)
+	}
+}
+
+func TestMsgpackTimeDecodesOtherForms(t *testing.T) {
+	at := time.Date(2024, 5, 6, 7, 8, 9, 123456789, time.UTC)
+	timestamp, _ := msgpack.Marshal(at)
+	// Type 13 holds the same payload as the timestamp extension
+	type13 := append([]byte{timestamp[0], 13}, timestamp[2:]...)
+	rfc3339, _ := msgpack.Marshal(at.Format(time.RFC3339Nano))
+
+	for _, data := range [][]byte{timestamp, type13, rfc3339} {
+		var top time.Time
+		if err := NewMsgpackSerializer().Deserialize(data, &top); err != nil || !top.Equal(at) {
+			t.Errorf("%x: expected %v, got %v (%v)", data, at, top, err)
+		}
+		var field struct{ At MsgpackTime }
+		wrapped, _ := msgpack.Marshal(map[string]msgpack.RawMessage{"At": data})
+		if err := NewMsgpackSerializer().Deserialize(wrapped, &field); err != nil || !field.At.Equal(at) {
+			t.Errorf("%x: expected %v, got %v (%v)", data, at, field.At, err)
+		}
+	}
+
+	// MsgpackTime encodes like time.Time
+	ours, _ := NewMsgpackSerializer().Serialize(MsgpackTime{at})
+	if !bytes.Equal(ours, timestamp) {
+		t.Errorf("expected %x, got %x", timestamp, ours)
+	}
+}
+
+type wideRecord struct {
+	Identifier  int      `msgpack:"identifier"`
+	Description string   `msgpack:"description"`
+	Temperature float64  `msgpack:"temperature"`
+	Labels      []string `msgpack:"labels"`
+}
+
+func TestWithMsgpackStructAsArray(t *testing.T) {
+	in := []wideRecord{{1, "a", 20.5, []string{"x"}}, {2, "b", 21, nil}}
+
+	compact := NewMsgpackSerializer(WithMsgpackStructAsArray())
+	data, err := compact.Serialize(in)
+	if err != nil {
+		t.Fatalf("Serialize failed: %v", err)
+	}
+	plain, err := NewMsgpackSerializer().Serialize(in)
+	if err != nil {
+		t.Fatalf("Serialize failed: %v", err)
+	}
+	if len(data) >= len(plain)*7/10 {
+		t.Errorf("expected at least 30%% savings, got %d vs %d bytes", len(data), len(plain))
+	}
+	if bytes.Contains(data, []byte("description")) {
+		t.Error("field names should not be encoded")
+	}
+
+	var buf bytes.Buffer
+	if err := compact.SerializeTo(&buf, in); err != nil {
+		t.Fatalf("SerializeTo failed: %v", err)
+	}
+	if !bytes.Equal(buf.Bytes(), data) {
+		t.Error("SerializeTo and Serialize disagree")
+	}
+
+	// Both layouts decode with any serializer
+	for _, payload := range [][]byte{data, plain} {
+		var out []wideRecord
+		if err := NewMsgpackSerializer().Deserialize(payload, &out); err != nil {
+			t.Fatalf("Deserialize failed: %v", err)
+		}
+		if len(out) != 2 || out[0].Description != "a" || out[1].Temperature != 21 || out[0].Labels[0] != "x" {
+			t.Errorf("unexpected round trip %+v", out)
+		}
+	}
+
+	// Settings don't leak to other serializers through the shared encoder pool
+	again, err := NewMsgpackSerializer().Serialize(in)
+	if err != nil || !bytes.Equal(again, plain) {
+		t.Errorf("default serializer output changed after struct-as-array use: %v", err)
+	}
+}
+
+func TestWithMsgpackJSONTags(t *testing.T) {
+	type account struct {
+		ID       int    `json:"id"`
+		Email    string `json:"email,omitempty"`
+		Override string `json:"json_name" msgpack:"mp_name"`
+		Secret   string `json:"-"`
+		Plain    string
+	}
+	in := account{ID: 7, Override: "o", Secret: "s", Plain: "p"}
+
+	s := NewMsgpackSerializer(WithMsgpackJSONTags())
+	data, err := s.Serialize(in)
+	if err != nil {
+		t.Fatalf("Serialize failed: %v", err)
+	}
+
+	var fields map[string]any
+	if err := msgpack.Unmarshal(data, &fields); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	want := map[string]any{"id": int8(7), "mp_name": "o", "Plain": "p"}
+	if len(fields) != len(want) {
+		t.Errorf("expected fields %v, got %v", want, fields)
+	}
+	for k, v := range want {
+		if fields[k] != v {
+			t.Errorf("field %q: expected %v, got %v", k, v, fields[k])
+		}
+	}
+
+	var out account
+	if err := s.Deserialize(data, &out); err != nil {
+		t.Fatalf("Deserialize failed: %v", err)
+	}
+	if out.ID != 7 || out.Override != "o" || out.Plain != "p" || out.Secret != "" {
+		t.Errorf("unexpected round trip %+v", out)
+	}
+	out = account{}
+	if err := s.(StringDeserializer).DeserializeString(string(data), &out); err != nil || out.ID != 7 {
+		t.Errorf("DeserializeString: got %+v, %v", out, err)
+	}
+	out = account{}
+	if err := s.DeserializeFrom(bytes.NewReader(data), &out); err != nil || out.ID != 7 {
+		t.Errorf("DeserializeFrom: got %+v, %v", out, err)
+	}
+
+	// Without the option Go field names are used
+	plain, _ := NewMsgpackSerializer().Serialize(in)
+	if !bytes.Contains(plain, []byte("ID")) {
+		t.Errorf("expected Go field names without the option, got %q", plain)
+	}
+}
+
+func TestWithMsgpackOmitEmpty(t *testing.T) {
+	type sparse struct {
+		ID      int               `msgpack:"id"`
+		Name    string            `msgpack:"name"`
+		Created time.Time         `msgpack:"created"`
+		Tags    []string          `msgpack:"tags"`
+		Attrs   map[string]string `msgpack:"attrs"`
+		Parent  *sparse           `msgpack:"parent"`
+	}
+
+	s := NewMsgpackSerializer(WithMsgpackOmitEmpty())
+	data, err := s.Serialize(sparse{ID: 1, Tags: []string{}})
+	if err != nil {
+		t.Fatalf("Serialize failed: %v", err)
+	}
+	var fields map[string]any
+	if err := msgpack.Unmarshal(data, &fields); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if len(fields) != 1 || fields["id"] != int8(1) {
+		t.Errorf("expected only id, got %v", fields)
+	}
+
+	full := sparse{ID: 2, Name: "n", Created: time.Unix(1, 0).UTC(), Tags: []string{"t"}, Parent: &sparse{}}
+	data, err = s.Serialize(full)
+	if err != nil {
+		t.Fatalf("Serialize failed: %v", err)
+	}
+	var out sparse
+	if err := s.Deserialize(data, &out); err != nil {
+		t.Fatalf("Deserialize failed: %v", err)
+	}
+	if out.Name != "n" || !out.Created.Equal(full.Created) || out.Parent == nil || len(out.Tags) != 1 {
+		t.Errorf("unexpected round trip %+v", out)
+	}
+
+	plain, _ := NewMsgpackSerializer().Serialize(sparse{})
+	if !bytes.Contains(plain, []byte("attrs")) {
+		t.Error("serializers without the option must keep empty fields")
+	}
+}
+
+func TestWithMsgpackNumberMode(t *testing.T) {
+	payload := map[string]any{
+		"small":  int8(5),
+		"neg":    int64(-300),
+		"big":    uint64(1 << 60),
+		"ratio":  float32(0.5),
+		"nested": []any{uint8(7), map[string]any{"n": int16(-2)}},
+	}
+	data, err := NewMsgpackSerializer().Serialize(payload)
+	if err != nil {
+		t.Fatalf("Serialize failed: %v", err)
+	}
+
+	tests := []struct {
+		mode  MsgpackNumberMode
+		small any
+		big   any
+		ratio any
+		inner any
+	}{
+		{MsgpackNumbersSized, int8(5), uint64(1 << 60), float32(0.5), int16(-2)},
+		{MsgpackNumbersWide, int64(5), uint64(1 << 60), float64(0.5), int64(-2)},
+		{MsgpackNumbersInt64, int64(5), int64(1 << 60), float64(0.5), int64(-2)},
+	}
+	for _, tt := range tests {
+		var got map[string]any
+		if err := NewMsgpackSerializer(WithMsgpackNumberMode(tt.mode)).Deserialize(data, &got); err != nil {
+			t.Fatalf("mode %d: Deserialize failed: %v", tt.mode, err)
+		}
+		inner := got["nested"].([]any)[1].(map[string]any)["n"]
+		if got["small"] != tt.small || got["big"] != tt.big || got["ratio"] != tt.ratio || inner != tt.inner {
+			t.Errorf("mode %d: got small=%T(%v) big=%T ratio=%T inner=%T", tt.mode, got["small"], got["small"], got["big"], got["ratio"], inner)
+		}
+	}
+
+	// Float64 mode matches encoding/json, and refuses integers it would round
+	float64s := NewMsgpackSerializer(WithMsgpackNumberMode(MsgpackNumbersFloat64))
+	var got any
+	if err := float64s.Deserialize(data, &got); !errors.Is(err, ErrLossyNumber) {
+		t.Errorf("expected ErrLossyNumber for 2^60, got %v", err)
+	}
+	exact, _ := NewMsgpackSerializer().Serialize(map[string]any{"n": int64(-300), "list": []any{uint8(1)}})
+	var wrapper struct {
+		Value any
+		Typed int
+	}
+	wrapperData, _ := NewMsgpackSerializer().Serialize(map[string]any{"Value": int64(9), "Typed": 3})
+	var m map[string]any
+	if err := float64s.Deserialize(exact, &m); err != nil {
+		t.Fatalf("Deserialize failed: %v", err)
+	}
+	if m["n"] != float64(-300) || m["list"].([]any)[0] != float64(1) {
+		t.Errorf("expected float64 values, got %#v", m)
+	}
+	if err := float64s.DeserializeFrom(bytes.NewReader(wrapperData), &wrapper); err != nil {
+		t.Fatalf("DeserializeFrom failed: %v", err)
+	}
+	if wrapper.Value != float64(9) || wrapper.Typed != 3 {
+		t.Errorf("unexpected struct: %#v", wrapper)
+	}
+
+	// Int64 mode refuses unsigned values beyond MaxInt64
+	huge, _ := NewMsgpackSerializer().Serialize([]any{uint64(math.MaxUint64)})
+	var list []any
+	if err := NewMsgpackSerializer(WithMsgpackNumberMode(MsgpackNumbersInt64)).Deserialize(huge, &list); !errors.Is(err, ErrLossyNumber) {
+		t.Errorf("expected ErrLossyNumber for MaxUint64, got %v", err)
+	}
+}
+
+func TestWithMsgpackCompactFloats(t *testing.T) {
+	type reading struct {
+		Sensor string             `msgpack:"sensor"`
+		Values []float64          `msgpack:"values"`
+		Extra  map[string]any     `msgpack:"extra"`
+		Raw    MsgpackRawMessage  `msgpack:"raw"`
+		Blob   []byte             `msgpack:"blob"`
+		ByName map[string]float64 `msgpack:"by_name"`
+	}
+	raw, _ := NewMsgpackSerializer().Serialize(2.5)
+	in := reading{
+		Sensor: "t1",
+		Values: []float64{0.5, 0.1, 1024, math.Inf(-1), math.NaN()},
+		Extra:  map[string]any{"v": 21.25},
+		Raw:    raw,
+		Blob:   []byte{0xcb, 0, 0, 0},
+		ByName: map[string]float64{"a": -0.75},
+	}
+
+	plain, err := NewMsgpackSerializer().Serialize(in)
+	if err != nil {
+		t.Fatalf("Serialize failed: %v", err)
+	}
+	s := NewMsgpackSerializer(WithMsgpackCompactFloats())
+	compact, err := s.Serialize(in)
+	if err != nil {
+		t.Fatalf("Serialize failed: %v", err)
+	}
+	// 0.5, 1024, -Inf, 21.25, 2.5 and -0.75 each save four bytes; 0.1 and NaN stay float64
+	if want := len(plain) - 6*4; len(compact) != want {
+		t.Errorf("expected %d bytes, got %d (plain %d)", want, len(compact), len(plain))
+	}
+
+	var out reading
+	if err := NewMsgpackSerializer().Deserialize(compact, &out); err != nil {
+		t.Fatalf("Deserialize failed: %v", err)
+	}
+	if out.Values[0] != 0.5 || out.Values[1] != 0.1 || out.Values[2] != 1024 || !math.IsInf(out.Values[3], -1) || !math.IsNaN(out.Values[4]) {
+		t.Errorf("values changed: %v", out.Values)
+	}
+	if out.Extra["v"] != float32(21.25) || out.ByName["a"] != -0.75 || !bytes.Equal(out.Blob, in.Blob) {
+		t.Errorf("unexpected decoded value: %+v", out)
+	}
+
+	var streamed bytes.Buffer
+	if err := s.SerializeTo(&streamed, in); err != nil {
+		t.Fatalf("SerializeTo failed: %v", err)
+	}
+	if !bytes.Equal(streamed.Bytes(), compact) {
+		t.Error("SerializeTo output differs from Serialize")
+	}
+	pb, err := s.(*MsgPackSerializer).SerializePooled(in)
+	if err != nil {
+		t.Fatalf("SerializePooled failed: %v", err)
+	}
+	defer pb.Release()
+	if !bytes.Equal(pb.Bytes(), compact) {
+		t.Error("SerializePooled output differs from Serialize")
+	}
+}
+
+func TestWithMsgpackStringKeys(t *testing.T) {
+	data, err := NewMsgpackSerializer().Serialize(map[int]any{
+		1: "one",
+		2: map[bool]any{true: []any{map[uint8]string{7: "seven"}}},
+	})
+	if err != nil {
+		t.Fatalf("Serialize failed: %v", err)
+	}
+
+	var plain any
+	if err := NewMsgpackSerializer().Deserialize(data, &plain); err == nil {
+		t.Fatal("expected the default decoder to reject integer keys")
+	}
+
+	var got any
+	if err := NewMsgpackSerializer(WithMsgpackStringKeys()).Deserialize(data, &got); err != nil {
+		t.Fatalf("Deserialize failed: %v", err)
+	}
+	top, ok := got.(map[string]any)
+	if !ok {
+		t.Fatalf("expected map[string]any, got %T", got)
+	}
+	nested := top["2"].(map[string]any)["true"].([]any)[0].(map[string]any)
+	if top["1"] != "one" || nested["7"] != "seven" {
+		t.Errorf("unexpected result: %#v", got)
+	}
+	if _, err := stdjson.Marshal(got); err != nil {
+		t.Errorf("decoded value does not re-encode as JSON: %v", err)
+	}
+
+	// Values follow the number mode
+	numbers, _ := NewMsgpackSerializer().Serialize(map[string]any{"n": int8(1)})
+	var m map[string]any
+	var wrapped struct{ M any }
+	wrappedData, _ := NewMsgpackSerializer().Serialize(map[string]any{"M": map[string]any{"n": int8(1)}})
+	s := NewMsgpackSerializer(WithMsgpackStringKeys(), WithMsgpackNumberMode(MsgpackNumbersWide))
+	if err := s.Deserialize(numbers, &m); err != nil || m["n"] != int64(1) {
+		t.Errorf("expected int64 value, got %#v (%v)", m, err)
+	}
+	if err := s.Deserialize(wrappedData, &wrapped); err != nil || wrapped.M.(map[string]any)["n"] != int64(1) {
+		t.Errorf("expected int64 nested value, got %#v (%v)", wrapped, err)
+	}
+}
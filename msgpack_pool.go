@@ -0,0 +1,45 @@
+package serializer
+
+// WithMaxBufferCap sets the largest buffer capacity a MessagePack serializer keeps
+// in its encoder pool, in place of MAX_BUF_CAP. Encoders whose buffers grew past
+// it are dropped after use, so services that routinely serialize multi-megabyte
+// values should raise it to keep reusing their buffers. If n <= 0, buffers are
+// never capped. Only the MessagePack serializer supports it.
+func WithMaxBufferCap(n int) Option {
+	return func(o *options) {
+		o.msgpackMaxBufferCap = &n
+	}
+}
+
+// WithInitialBufferSize preallocates n bytes for each buffer the MessagePack
+// encoder pool creates, which saves regrowing new buffers when typical payloads
+// are known to be large. Only the MessagePack serializer supports it.
+func WithInitialBufferSize(n int) Option {
+	return func(o *options) {
+		o.msgpackInitialBufferSize = max(n, 0)
+	}
+}
+
+// WithPoolDisabled turns off encoder pooling for a MessagePack serializer: every
+// call allocates a new encoder and buffer and leaves it to the garbage collector.
+// Decoders, which hold no buffers, are still pooled. Only the MessagePack
+// serializer supports it.
+func WithPoolDisabled() Option {
+	return func(o *options) {
+		o.msgpackPoolDisabled = true
+	}
+}
+
+// newMsgpackEncoderPool returns the encoder pool for a serializer configured by o.
+// Serializers with the default settings share defaultEncoderPool; others get
+// their own pool, so their buffers never reach serializers with other limits.
+func newMsgpackEncoderPool(o *options) *encoderPool {
+	if o.msgpackMaxBufferCap == nil && o.msgpackInitialBufferSize == 0 && !o.msgpackPoolDisabled {
+		return defaultEncoderPool
+	}
+	maxCap := MAX_BUF_CAP
+	if o.msgpackMaxBufferCap != nil {
+		maxCap = *o.msgpackMaxBufferCap
+	}
+	return newEncoderPool(maxCap, o.msgpackInitialBufferSize, o.msgpackPoolDisabled)
+}
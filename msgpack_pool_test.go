@@ -0,0 +1,80 @@
+package serializer
+
+import (
+	"testing"
+)
+
+func TestMsgpackMaxBufferCap(t *testing.T) {
+	large := make([]byte, MAX_BUF_CAP+1)
+
+	raised := NewMsgpackSerializer(WithMaxBufferCap(4 * MAX_BUF_CAP)).(*MsgPackSerializer)
+	for i := 0; i < 2; i++ {
+		if _, err := raised.Serialize(large); err != nil {
+			t.Fatalf("Serialize failed: %v", err)
+		}
+	}
+	if stats := raised.PoolStats(); stats.Discards != 0 || stats.Puts != 2 {
+		t.Errorf("buffers under the raised cap were discarded: %+v", stats)
+	}
+	if raised.encoders == defaultEncoderPool {
+		t.Error("a serializer with its own limit shares the default pool")
+	}
+
+	lowered := NewMsgpackSerializer(WithMaxBufferCap(64)).(*MsgPackSerializer)
+	if _, err := lowered.Serialize(make([]byte, 128)); err != nil {
+		t.Fatalf("Serialize failed: %v", err)
+	}
+	if stats := lowered.PoolStats(); stats.Discards != 1 {
+		t.Errorf("buffer over the lowered cap was kept: %+v", stats)
+	}
+
+	uncapped := NewMsgpackSerializer(WithMaxBufferCap(0)).(*MsgPackSerializer)
+	if _, err := uncapped.Serialize(large); err != nil {
+		t.Fatalf("Serialize failed: %v", err)
+	}
+	if stats := uncapped.PoolStats(); stats.Discards != 0 {
+		t.Errorf("uncapped pool discarded a buffer: %+v", stats)
+	}
+}
+
+func TestMsgpackInitialBufferSize(t *testing.T) {
+	s := NewMsgpackSerializer(WithInitialBufferSize(64 << 10)).(*MsgPackSerializer)
+	pe := s.getEncoder()
+	defer s.releaseEncoder(pe)
+	if pe.buf.Cap() < 64<<10 {
+		t.Errorf("new buffer has capacity %d, want at least %d", pe.buf.Cap(), 64<<10)
+	}
+}
+
+func TestMsgpackPoolDisabled(t *testing.T) {
+	s := NewMsgpackSerializer(WithPoolDisabled()).(*MsgPackSerializer)
+	for i := 0; i < 3; i++ {
+		data, err := s.Serialize(map[string]int{"n": i})
+		if err != nil {
+			t.Fatalf("Serialize failed: %v", err)
+		}
+		var got map[string]int
+		if err := s.Deserialize(data, &got); err != nil || got["n"] != i {
+			t.Fatalf("round trip %d: got %v, %v", i, got, err)
+		}
+	}
+
+	pb, err := s.SerializePooled("value")
+	if err != nil {
+		t.Fatal(err)
+	}
+	pb.Release()
+
+	stats := s.PoolStats()
+	if stats.Misses != stats.Gets || stats.Puts != 0 {
+		t.Errorf("disabled pool reused encoders: %+v", stats)
+	}
+}
+
+func TestMsgpackDefaultPoolShared(t *testing.T) {
+	a := NewMsgpackSerializer().(*MsgPackSerializer)
+	b := NewMsgpackSerializer(WithMsgpackOmitEmpty()).(*MsgPackSerializer)
+	if a.encoders != defaultEncoderPool || b.encoders != defaultEncoderPool {
+		t.Error("serializers with default pool settings should share the default pool")
+	}
+}
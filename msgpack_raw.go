@@ -0,0 +1,36 @@
+package serializer
+
+import (
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+var (
+	_ msgpack.CustomEncoder = MsgpackRawMessage(nil)
+	_ msgpack.CustomDecoder = (*MsgpackRawMessage)(nil)
+)
+
+// MsgpackRawMessage holds an encoded MessagePack value. Decoding captures the
+// value's bytes without interpreting them and encoding writes them back verbatim,
+// so services that route messages by their envelope fields can pass bodies through
+// untouched. An empty message encodes as nil. Decoded bytes are a copy and stay
+// valid after the input is reused.
+type MsgpackRawMessage []byte
+
+// EncodeMsgpack implements msgpack.CustomEncoder
+func (m MsgpackRawMessage) EncodeMsgpack(enc *msgpack.Encoder) error {
+	if len(m) == 0 {
+		return enc.EncodeNil()
+	}
+	_, err := enc.Writer().Write(m)
+	return err
+}
+
+// DecodeMsgpack implements msgpack.CustomDecoder
+func (m *MsgpackRawMessage) DecodeMsgpack(dec *msgpack.Decoder) error {
+	raw, err := dec.DecodeRaw()
+	if err != nil {
+		return err
+	}
+	*m = MsgpackRawMessage(raw)
+	return nil
+}
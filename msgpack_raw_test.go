@@ -0,0 +1,66 @@
+package serializer
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestMsgpackRawMessage(t *testing.T) {
+	type body struct {
+		Items []int           `msgpack:"items"`
+		Meta  map[string]bool `msgpack:"meta"`
+	}
+	type message struct {
+		Route string            `msgpack:"route"`
+		Body  MsgpackRawMessage `msgpack:"body"`
+	}
+	type typedMessage struct {
+		Route string `msgpack:"route"`
+		Body  body   `msgpack:"body"`
+	}
+
+	s := NewMsgpackSerializer()
+	want := body{Items: []int{1, 2, 3}, Meta: map[string]bool{"ok": true}}
+	data, err := s.Serialize(typedMessage{Route: "orders", Body: want})
+	if err != nil {
+		t.Fatalf("Serialize failed: %v", err)
+	}
+
+	var routed message
+	if err := s.Deserialize(data, &routed); err != nil {
+		t.Fatalf("Deserialize failed: %v", err)
+	}
+	if routed.Route != "orders" {
+		t.Errorf("expected route orders, got %q", routed.Route)
+	}
+	encodedBody, _ := s.Serialize(want)
+	if !bytes.Equal(routed.Body, encodedBody) {
+		t.Errorf("expected raw body %x, got %x", encodedBody, routed.Body)
+	}
+
+	// Re-encoding passes the body through byte for byte
+	forwarded, err := s.Serialize(routed)
+	if err != nil {
+		t.Fatalf("Serialize failed: %v", err)
+	}
+	if !bytes.Equal(forwarded, data) {
+		t.Errorf("expected forwarded message to match the original\nwant %x\ngot  %x", data, forwarded)
+	}
+
+	var typed typedMessage
+	if err := s.Deserialize(forwarded, &typed); err != nil {
+		t.Fatalf("Deserialize failed: %v", err)
+	}
+	if len(typed.Body.Items) != 3 || !typed.Body.Meta["ok"] {
+		t.Errorf("unexpected body %+v", typed.Body)
+	}
+
+	// An empty raw message is written as nil, keeping the stream valid
+	empty, err := s.Serialize(message{Route: "r"})
+	if err != nil {
+		t.Fatalf("Serialize failed: %v", err)
+	}
+	if err := s.Deserialize(empty, &typed); err != nil {
+		t.Errorf("expected nil body to decode, got %v", err)
+	}
+}
@@ -0,0 +1,130 @@
+package serializer
+
+import (
+	"bytes"
+	"sort"
+
+	"github.com/vmihailenco/msgpack/v5/msgpcode"
+)
+
+// msgpackEntry locates one key/value pair of an encoded map
+type msgpackEntry struct {
+	start, keyEnd, end int
+}
+
+// sortMsgpackMaps reorders, in place, the entries of every map in the encoded data
+// by key: string keys by their contents, ahead of other keys, which are ordered by
+// their encoding. The msgpack library only sorts a few map types itself, so this
+// runs on the encoder output instead. No bytes are added or removed.
+func sortMsgpackMaps(data []byte) error {
+	for off := 0; off < len(data); {
+		end, err := sortMsgpackValue(data, off)
+		if err != nil {
+			return err
+		}
+		off = end
+	}
+	return nil
+}
+
+// sortMsgpackValue sorts the maps inside the value at data[off:] and returns the
+// offset just past the value
+func sortMsgpackValue(data []byte, off int) (int, error) {
+	if off >= len(data) {
+		return 0, errTruncatedMsgpack
+	}
+	n, err := msgpackTokenLen(data[off:])
+	if err != nil {
+		return 0, err
+	}
+	end := off + n
+	count, isMap := msgpackContainerLen(data[off:])
+	if !isMap {
+		for i := 0; i < count; i++ {
+			if end, err = sortMsgpackValue(data, end); err != nil {
+				return 0, err
+			}
+		}
+		return end, nil
+	}
+
+	entries := make([]msgpackEntry, count)
+	for i := range entries {
+		keyEnd, err := sortMsgpackValue(data, end)
+		if err != nil {
+			return 0, err
+		}
+		valueEnd, err := sortMsgpackValue(data, keyEnd)
+		if err != nil {
+			return 0, err
+		}
+		entries[i] = msgpackEntry{start: end, keyEnd: keyEnd, end: valueEnd}
+		end = valueEnd
+	}
+	if count < 2 {
+		return end, nil
+	}
+
+	start := entries[0].start
+	sort.SliceStable(entries, func(i, j int) bool {
+		return compareMsgpackKeys(data[entries[i].start:entries[i].keyEnd], data[entries[j].start:entries[j].keyEnd]) < 0
+	})
+	sorted := make([]byte, 0, end-start)
+	for _, e := range entries {
+		sorted = append(sorted, data[e.start:e.end]...)
+	}
+	copy(data[start:end], sorted)
+	return end, nil
+}
+
+// msgpackContainerLen returns the element count of the array or map header at the
+// start of data and whether it is a map. Other values have no elements.
+func msgpackContainerLen(data []byte) (int, bool) {
+	c := data[0]
+	switch {
+	case msgpcode.IsFixedMap(c):
+		return int(c & 0x0f), true
+	case msgpcode.IsFixedArray(c):
+		return int(c & 0x0f), false
+	case c == msgpcode.Map16:
+		return lengthAt(data, 1, 2), true
+	case c == msgpcode.Map32:
+		return lengthAt(data, 1, 4), true
+	case c == msgpcode.Array16:
+		return lengthAt(data, 1, 2), false
+	case c == msgpcode.Array32:
+		return lengthAt(data, 1, 4), false
+	}
+	return 0, false
+}
+
+// compareMsgpackKeys orders two encoded map keys
+func compareMsgpackKeys(a, b []byte) int {
+	as, aok := msgpackStringPayload(a)
+	bs, bok := msgpackStringPayload(b)
+	switch {
+	case aok && bok:
+		return bytes.Compare(as, bs)
+	case aok:
+		return -1
+	case bok:
+		return 1
+	}
+	return bytes.Compare(a, b)
+}
+
+// msgpackStringPayload returns the contents of an encoded string
+func msgpackStringPayload(data []byte) ([]byte, bool) {
+	c := data[0]
+	switch {
+	case msgpcode.IsFixedString(c):
+		return data[1:], true
+	case c == msgpcode.Str8:
+		return data[2:], true
+	case c == msgpcode.Str16:
+		return data[3:], true
+	case c == msgpcode.Str32:
+		return data[5:], true
+	}
+	return nil, false
+}
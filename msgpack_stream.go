@@ -0,0 +1,163 @@
+package serializer
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"iter"
+	"sync"
+
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// streamDecoder is a reusable msgpack decoder with its own read buffer
+type streamDecoder struct {
+	dec *msgpack.Decoder
+	br  *bufio.Reader
+}
+
+// streamDecoderPool reuses stream decoders and their read buffers across readers
+var streamDecoderPool = sync.Pool{
+	New: func() any {
+		br := bufio.NewReader(nil)
+		return &streamDecoder{
+			dec: msgpack.NewDecoder(br),
+			br:  br,
+		}
+	},
+}
+
+// MsgpackStreamReader decodes back-to-back MessagePack values from one stream,
+// such as an append-only event file or a connection, with a single pooled decoder.
+// Unless the stream implements io.ByteScanner, as bufio.Reader and bytes.Reader
+// do, it reads ahead of the value being decoded, so nothing else should read from
+// the stream. Close returns the decoder to the pool.
+// A MsgpackStreamReader is not safe for concurrent use.
+type MsgpackStreamReader struct {
+	s  *MsgPackSerializer
+	sd *streamDecoder
+
+	// failed is set once a value fails to decode. The decoder's read buffer may
+	// then have grown to a length the stream declared but never sent, so it is
+	// not returned to the pool.
+	failed bool
+}
+
+var (
+	_ StreamingSerializer = (*MsgPackSerializer)(nil)
+	_ StreamEncoder       = (*MsgpackStreamEncoder)(nil)
+	_ StreamDecoder       = (*MsgpackStreamReader)(nil)
+)
+
+// NewMsgpackStreamReader creates a reader decoding values from r with a serializer configured by opts
+func NewMsgpackStreamReader(r io.Reader, opts ...Option) *MsgpackStreamReader {
+	return NewMsgpackSerializer(opts...).(*MsgPackSerializer).newStreamDecoder(r)
+}
+
+// NewStreamDecoder implements StreamingSerializer with a *MsgpackStreamReader
+func (s *MsgPackSerializer) NewStreamDecoder(r io.Reader) StreamDecoder {
+	return s.newStreamDecoder(r)
+}
+
+// newStreamDecoder binds a pooled decoder to r with this serializer's settings, so
+// many values can be read from one connection without building a decoder per value
+func (s *MsgPackSerializer) newStreamDecoder(r io.Reader) *MsgpackStreamReader {
+	sd := streamDecoderPool.Get().(*streamDecoder)
+	if _, ok := r.(io.ByteScanner); ok {
+		sd.dec.Reset(r)
+	} else {
+		sd.br.Reset(r)
+		sd.dec.Reset(sd.br)
+	}
+	s.configureDecoder(sd.dec)
+	return &MsgpackStreamReader{s: s, sd: sd}
+}
+
+// Decode reads the next value into v.
+// It returns io.EOF when the stream ends cleanly between values and
+// io.ErrUnexpectedEOF when it ends in the middle of one.
+func (sr *MsgpackStreamReader) Decode(v any) error {
+	if sr.sd == nil {
+		return errors.New("stream reader is closed")
+	}
+	if _, err := sr.sd.dec.PeekCode(); err != nil {
+		return err
+	}
+	err := sr.s.decodeStream(sr.sd.dec, v)
+	if err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	}
+	if err != nil {
+		sr.failed = true
+	}
+	return err
+}
+
+// More implements StreamDecoder
+func (sr *MsgpackStreamReader) More() bool {
+	if sr.sd == nil {
+		return false
+	}
+	_, err := sr.sd.dec.PeekCode()
+	return err == nil
+}
+
+// Close returns the decoder to the pool. It does not close the underlying reader.
+func (sr *MsgpackStreamReader) Close() error {
+	if sr.sd != nil && !sr.failed {
+		sr.sd.br.Reset(nil)
+		sr.sd.dec.Reset(sr.sd.br)
+		streamDecoderPool.Put(sr.sd)
+	}
+	sr.sd = nil
+	return nil
+}
+
+// MsgpackStreamEncoder writes back-to-back MessagePack values to one stream with
+// a single encoder. A MsgpackStreamEncoder is not safe for concurrent use.
+type MsgpackStreamEncoder struct {
+	s   *MsgPackSerializer
+	w   io.Writer
+	enc *msgpack.Encoder
+}
+
+// NewStreamEncoder implements StreamingSerializer with a *MsgpackStreamEncoder
+// writing to w with this serializer's settings
+func (s *MsgPackSerializer) NewStreamEncoder(w io.Writer) StreamEncoder {
+	return &MsgpackStreamEncoder{s: s, w: w, enc: s.newEncoder(w)}
+}
+
+// Encode writes v to the stream
+func (e *MsgpackStreamEncoder) Encode(v any) error {
+	if e.s.opts.rewritesMsgpack() {
+		// Encoded values are rewritten, which SerializeTo buffers for
+		return e.s.SerializeTo(e.w, v)
+	}
+	err := e.s.encode(e.enc, v)
+	e.s.opts.logFailure("stream_encode", err)
+	return err
+}
+
+// Close implements StreamEncoder. Values are not buffered, so there is nothing to flush.
+func (e *MsgpackStreamEncoder) Close() error {
+	return nil
+}
+
+// MsgpackValues iterates over the MessagePack values in r, decoding each into a
+// new T. Iteration ends at the end of the stream or after yielding the first error.
+func MsgpackValues[T any](r io.Reader, opts ...Option) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		sr := NewMsgpackStreamReader(r, opts...)
+		defer sr.Close()
+		for {
+			var v T
+			err := sr.Decode(&v)
+			if err == io.EOF {
+				return
+			}
+			if !yield(v, err) || err != nil {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,131 @@
+package serializer
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"testing"
+	"testing/iotest"
+)
+
+type streamEvent struct {
+	Seq  int    `msgpack:"seq" json:"seq"`
+	Kind string `msgpack:"kind" json:"kind"`
+}
+
+func appendEvents(t *testing.T, s Serializer, n int) []byte {
+	t.Helper()
+	var log bytes.Buffer
+	for i := 0; i < n; i++ {
+		if err := s.SerializeTo(&log, streamEvent{Seq: i, Kind: "created"}); err != nil {
+			t.Fatalf("SerializeTo failed: %v", err)
+		}
+	}
+	return log.Bytes()
+}
+
+func TestMsgpackStreamReader(t *testing.T) {
+	log := appendEvents(t, NewMsgpackSerializer(), 5)
+
+	sr := NewMsgpackStreamReader(iotest.OneByteReader(bytes.NewReader(log)))
+	defer sr.Close()
+	for i := 0; i < 5; i++ {
+		var ev streamEvent
+		if err := sr.Decode(&ev); err != nil {
+			t.Fatalf("Decode %d failed: %v", i, err)
+		}
+		if ev.Seq != i || ev.Kind != "created" {
+			t.Errorf("event %d mismatch: %+v", i, ev)
+		}
+	}
+	var extra streamEvent
+	if err := sr.Decode(&extra); err != io.EOF {
+		t.Errorf("expected io.EOF, got %v", err)
+	}
+
+	truncated := NewMsgpackStreamReader(bytes.NewReader(log[:len(log)-3]))
+	defer truncated.Close()
+	var err error
+	for err == nil {
+		err = truncated.Decode(&extra)
+	}
+	if err != io.ErrUnexpectedEOF {
+		t.Errorf("expected io.ErrUnexpectedEOF for a truncated stream, got %v", err)
+	}
+
+	sr.Close()
+	if err := sr.Decode(&extra); err == nil {
+		t.Error("expected error decoding after Close")
+	}
+}
+
+func TestMsgpackStreamReaderOptions(t *testing.T) {
+	log := appendEvents(t, NewMsgpackSerializer(WithMsgpackStructAsArray()), 2)
+	type jsonTagged struct {
+		Seq int `json:"seq"`
+	}
+
+	sr := NewMsgpackStreamReader(bytes.NewReader(appendEvents(t, NewMsgpackSerializer(), 1)), WithMsgpackJSONTags())
+	defer sr.Close()
+	var tagged jsonTagged
+	if err := sr.Decode(&tagged); err != nil {
+		t.Fatalf("Decode failed: %v", err)
+	}
+
+	n := 0
+	for ev, err := range MsgpackValues[streamEvent](bytes.NewReader(log)) {
+		if err != nil {
+			t.Fatalf("iteration failed: %v", err)
+		}
+		if ev.Seq != n {
+			t.Errorf("expected seq %d, got %d", n, ev.Seq)
+		}
+		n++
+	}
+	if n != 2 {
+		t.Errorf("expected 2 values, got %d", n)
+	}
+
+	// Iteration stops at the first error
+	errs := 0
+	for _, err := range MsgpackValues[streamEvent](bytes.NewReader(log[:len(log)-1])) {
+		if err != nil {
+			errs++
+		}
+	}
+	if errs != 1 {
+		t.Errorf("expected exactly one error, got %d", errs)
+	}
+}
+
+func TestMsgpackNewStreamDecoder(t *testing.T) {
+	s := NewMsgpackSerializer(WithMsgpackNumberMode(MsgpackNumbersWide)).(*MsgPackSerializer)
+	log := appendEvents(t, s, 3)
+
+	// A bufio.Reader is read directly, so values decoded through the session and
+	// through other readers of the same stream stay in step
+	br := bufio.NewReader(bytes.NewReader(log))
+	dec := s.NewStreamDecoder(br)
+	var first streamEvent
+	if err := dec.Decode(&first); err != nil || first.Seq != 0 {
+		t.Fatalf("Decode failed: %+v %v", first, err)
+	}
+	dec.Close()
+	var second streamEvent
+	if err := s.DeserializeFrom(br, &second); err != nil || second.Seq != 1 {
+		t.Fatalf("DeserializeFrom after the session failed: %+v %v", second, err)
+	}
+
+	dec = s.NewStreamDecoder(br)
+	defer dec.Close()
+	var generic map[string]any
+	if err := dec.Decode(&generic); err != nil {
+		t.Fatalf("Decode failed: %v", err)
+	}
+	if generic["seq"] != int64(2) {
+		t.Errorf("expected the serializer's number mode, got %T", generic["seq"])
+	}
+	if err := dec.Decode(&generic); err != io.EOF {
+		t.Errorf("expected io.EOF, got %v", err)
+	}
+}
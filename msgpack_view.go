@@ -0,0 +1,317 @@
+package serializer
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+
+	"github.com/vmihailenco/msgpack/v5/msgpcode"
+)
+
+var _ ZeroCopyDeserializer = (*MsgPackSerializer)(nil)
+
+// View implements ZeroCopyDeserializer. data must hold exactly one value; it is
+// walked once, without decoding, to check that every declared length is backed by
+// data. Decode honours this serializer's options.
+func (s *MsgPackSerializer) View(data []byte) (View, error) {
+	if len(data) == 0 {
+		return nil, io.ErrUnexpectedEOF
+	}
+	n, err := msgpackValueLen(data)
+	if err != nil {
+		return nil, err
+	}
+	if n != len(data) {
+		return nil, fmt.Errorf("msgpack: %d bytes after the value", len(data)-n)
+	}
+	return msgpackView{s: s, data: data}, nil
+}
+
+// msgpackHead describes the header of an encoded MessagePack value
+type msgpackHead struct {
+	kind ViewKind
+	// size is the length of the header, including fixed-size scalars
+	size int
+	// n is the number of payload bytes of strings, byte slices and extensions,
+	// or of elements of arrays and maps (two per map entry)
+	n int
+}
+
+// readMsgpackHead parses the header of the value at the start of data
+func readMsgpackHead(data []byte) (msgpackHead, error) {
+	if len(data) == 0 {
+		return msgpackHead{}, io.ErrUnexpectedEOF
+	}
+	c := data[0]
+	switch {
+	case msgpcode.IsFixedNum(c):
+		return msgpackHead{kind: ViewInt, size: 1}, nil
+	case msgpcode.IsFixedMap(c):
+		return msgpackHead{kind: ViewMap, size: 1, n: 2 * int(c&0x0f)}, nil
+	case msgpcode.IsFixedArray(c):
+		return msgpackHead{kind: ViewArray, size: 1, n: int(c & 0x0f)}, nil
+	case msgpcode.IsFixedString(c):
+		return msgpackHead{kind: ViewString, size: 1, n: int(c & 0x1f)}, nil
+	}
+
+	var head msgpackHead
+	lenSize := 0
+	switch c {
+	case msgpcode.Nil:
+		return msgpackHead{kind: ViewNil, size: 1}, nil
+	case msgpcode.False, msgpcode.True:
+		return msgpackHead{kind: ViewBool, size: 1}, nil
+	case msgpcode.Float:
+		return msgpackHead{kind: ViewFloat, size: 5}, nil
+	case msgpcode.Double:
+		return msgpackHead{kind: ViewFloat, size: 9}, nil
+	case msgpcode.Uint8, msgpcode.Uint16, msgpcode.Uint32, msgpcode.Uint64:
+		return msgpackHead{kind: ViewUint, size: 1 + 1<<(c-msgpcode.Uint8)}, nil
+	case msgpcode.Int8, msgpcode.Int16, msgpcode.Int32, msgpcode.Int64:
+		return msgpackHead{kind: ViewInt, size: 1 + 1<<(c-msgpcode.Int8)}, nil
+	case msgpcode.FixExt1, msgpcode.FixExt2, msgpcode.FixExt4, msgpcode.FixExt8, msgpcode.FixExt16:
+		return msgpackHead{kind: ViewExt, size: 2, n: 1 << (c - msgpcode.FixExt1)}, nil
+	case msgpcode.Str8, msgpcode.Str16, msgpcode.Str32:
+		head.kind, lenSize = ViewString, 1<<(c-msgpcode.Str8)
+	case msgpcode.Bin8, msgpcode.Bin16, msgpcode.Bin32:
+		head.kind, lenSize = ViewBytes, 1<<(c-msgpcode.Bin8)
+	case msgpcode.Ext8, msgpcode.Ext16, msgpcode.Ext32:
+		head.kind, lenSize = ViewExt, 1<<(c-msgpcode.Ext8)
+	case msgpcode.Array16, msgpcode.Array32:
+		head.kind, lenSize = ViewArray, 2<<(c-msgpcode.Array16)
+	case msgpcode.Map16, msgpcode.Map32:
+		head.kind, lenSize = ViewMap, 2<<(c-msgpcode.Map16)
+	default:
+		return msgpackHead{}, fmt.Errorf("msgpack: invalid code %#x", c)
+	}
+
+	n, ok := readMsgpackLength(data[1:], lenSize)
+	if !ok {
+		return msgpackHead{}, io.ErrUnexpectedEOF
+	}
+	head.size = 1 + lenSize
+	head.n = int(n)
+	switch head.kind {
+	case ViewExt:
+		head.size++ // type byte
+	case ViewMap:
+		head.n *= 2
+	}
+	return head, nil
+}
+
+// msgpackValueLen returns the length of the value at the start of data
+func msgpackValueLen(data []byte) (int, error) {
+	pos, owed := 0, 1
+	for owed > 0 {
+		head, err := readMsgpackHead(data[pos:])
+		if err != nil {
+			return 0, err
+		}
+		owed--
+		pos += head.size
+		if head.kind == ViewArray || head.kind == ViewMap {
+			// Every element takes at least a byte, which bounds owed
+			if head.n > len(data)-pos {
+				return 0, io.ErrUnexpectedEOF
+			}
+			owed += head.n
+		} else {
+			pos += head.n
+		}
+		if pos > len(data) {
+			return 0, io.ErrUnexpectedEOF
+		}
+	}
+	return pos, nil
+}
+
+// msgpackView is a View of one MessagePack value; data holds exactly that value
+type msgpackView struct {
+	s    *MsgPackSerializer
+	data []byte
+}
+
+func (v msgpackView) head() msgpackHead {
+	// The value was checked when the view was created
+	head, _ := readMsgpackHead(v.data)
+	return head
+}
+
+func (v msgpackView) kindError(want ViewKind) error {
+	return fmt.Errorf("%w: %s, not %s", ErrViewType, v.Kind(), want)
+}
+
+func (v msgpackView) Kind() ViewKind {
+	return v.head().kind
+}
+
+// elements calls fn with each element of an array, or each key and value of a
+// map in turn, until fn returns false
+func (v msgpackView) elements(kind ViewKind, fn func(elem []byte) bool) error {
+	head := v.head()
+	if head.kind != kind {
+		return v.kindError(kind)
+	}
+	rest := v.data[head.size:]
+	for i := 0; i < head.n; i++ {
+		n, err := msgpackValueLen(rest)
+		if err != nil {
+			return err
+		}
+		if !fn(rest[:n:n]) {
+			return nil
+		}
+		rest = rest[n:]
+	}
+	return nil
+}
+
+func (v msgpackView) Field(key string) (View, error) {
+	var value []byte
+	var isKey, found bool
+	err := v.elements(ViewMap, func(elem []byte) bool {
+		isKey = !isKey
+		if isKey {
+			head, _ := readMsgpackHead(elem)
+			found = head.kind == ViewString && string(elem[head.size:]) == key
+			return true
+		}
+		if found {
+			value = elem
+		}
+		return !found
+	})
+	if err != nil {
+		return nil, err
+	}
+	if value == nil {
+		return nil, fmt.Errorf("%w: key %q", ErrViewNotFound, key)
+	}
+	return msgpackView{s: v.s, data: value}, nil
+}
+
+func (v msgpackView) Index(i int) (View, error) {
+	var value []byte
+	j := 0
+	err := v.elements(ViewArray, func(elem []byte) bool {
+		if j == i {
+			value = elem
+			return false
+		}
+		j++
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	if value == nil {
+		return nil, fmt.Errorf("%w: index %d", ErrViewNotFound, i)
+	}
+	return msgpackView{s: v.s, data: value}, nil
+}
+
+func (v msgpackView) Len() (int, error) {
+	head := v.head()
+	switch head.kind {
+	case ViewMap:
+		return head.n / 2, nil
+	case ViewArray, ViewString, ViewBytes:
+		return head.n, nil
+	}
+	return 0, fmt.Errorf("%w: %s has no length", ErrViewType, head.kind)
+}
+
+func (v msgpackView) Str() (string, error) {
+	head := v.head()
+	if head.kind != ViewString {
+		return "", v.kindError(ViewString)
+	}
+	return bytesToString(v.data[head.size:]), nil
+}
+
+func (v msgpackView) Bytes() ([]byte, error) {
+	head := v.head()
+	if head.kind != ViewBytes {
+		return nil, v.kindError(ViewBytes)
+	}
+	return v.data[head.size:len(v.data):len(v.data)], nil
+}
+
+func (v msgpackView) Int() (int64, error) {
+	c := v.data[0]
+	b := v.data[1:]
+	switch {
+	case msgpcode.IsFixedNum(c):
+		return int64(int8(c)), nil
+	case c == msgpcode.Int8:
+		return int64(int8(b[0])), nil
+	case c == msgpcode.Int16:
+		return int64(int16(binary.BigEndian.Uint16(b))), nil
+	case c == msgpcode.Int32:
+		return int64(int32(binary.BigEndian.Uint32(b))), nil
+	case c == msgpcode.Int64:
+		return int64(binary.BigEndian.Uint64(b)), nil
+	}
+	u, err := v.uint()
+	if err != nil {
+		return 0, err
+	}
+	if u > math.MaxInt64 {
+		return 0, fmt.Errorf("%w: %d overflows int64", ErrViewType, u)
+	}
+	return int64(u), nil
+}
+
+// uint reads an unsigned integer
+func (v msgpackView) uint() (uint64, error) {
+	b := v.data[1:]
+	switch v.data[0] {
+	case msgpcode.Uint8:
+		return uint64(b[0]), nil
+	case msgpcode.Uint16:
+		return uint64(binary.BigEndian.Uint16(b)), nil
+	case msgpcode.Uint32:
+		return uint64(binary.BigEndian.Uint32(b)), nil
+	case msgpcode.Uint64:
+		return binary.BigEndian.Uint64(b), nil
+	}
+	return 0, v.kindError(ViewInt)
+}
+
+func (v msgpackView) Float() (float64, error) {
+	switch v.data[0] {
+	case msgpcode.Float:
+		return float64(math.Float32frombits(binary.BigEndian.Uint32(v.data[1:]))), nil
+	case msgpcode.Double:
+		return math.Float64frombits(binary.BigEndian.Uint64(v.data[1:])), nil
+	}
+	switch v.Kind() {
+	case ViewUint:
+		u, err := v.uint()
+		return float64(u), err
+	case ViewInt:
+		i, err := v.Int()
+		return float64(i), err
+	}
+	return 0, v.kindError(ViewFloat)
+}
+
+func (v msgpackView) Bool() (bool, error) {
+	switch v.data[0] {
+	case msgpcode.True:
+		return true, nil
+	case msgpcode.False:
+		return false, nil
+	}
+	return false, v.kindError(ViewBool)
+}
+
+func (v msgpackView) Raw() ([]byte, error) {
+	return v.data[:len(v.data):len(v.data)], nil
+}
+
+func (v msgpackView) Decode(target any) error {
+	return v.s.Deserialize(v.data, target)
+}
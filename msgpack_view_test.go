@@ -0,0 +1,176 @@
+package serializer
+
+import (
+	"errors"
+	"io"
+	"math"
+	"strings"
+	"testing"
+	"time"
+)
+
+type viewProfile struct {
+	Name    string         `msgpack:"name"`
+	Avatar  []byte         `msgpack:"avatar"`
+	Age     int            `msgpack:"age"`
+	Score   float64        `msgpack:"score"`
+	Active  bool           `msgpack:"active"`
+	Tags    []string       `msgpack:"tags"`
+	Extra   map[string]any `msgpack:"extra"`
+	Updated time.Time      `msgpack:"updated"`
+}
+
+func TestMsgpackView(t *testing.T) {
+	s := NewMsgpackSerializer().(*MsgPackSerializer)
+	profile := viewProfile{
+		Name:    "ada",
+		Avatar:  []byte{1, 2, 3},
+		Age:     36,
+		Score:   9.5,
+		Active:  true,
+		Tags:    []string{"a", strings.Repeat("b", 40)},
+		Extra:   map[string]any{"big": uint64(math.MaxUint64), "neg": -200, "nil": nil},
+		Updated: time.Unix(1700000000, 0).UTC(),
+	}
+	data, err := s.Serialize(profile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	view, err := s.View(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if view.Kind() != ViewMap {
+		t.Fatalf("Kind = %s", view.Kind())
+	}
+	if n, _ := view.Len(); n != 8 {
+		t.Errorf("Len = %d", n)
+	}
+
+	field := func(path ...any) View {
+		t.Helper()
+		v := view
+		for _, p := range path {
+			var err error
+			switch p := p.(type) {
+			case string:
+				v, err = v.Field(p)
+			case int:
+				v, err = v.Index(p)
+			}
+			if err != nil {
+				t.Fatalf("%v: %v", path, err)
+			}
+		}
+		return v
+	}
+
+	name, err := field("name").Str()
+	if err != nil || name != "ada" {
+		t.Errorf("name = %q, %v", name, err)
+	}
+	avatar, err := field("avatar").Bytes()
+	if err != nil || string(avatar) != "\x01\x02\x03" {
+		t.Errorf("avatar = %v, %v", avatar, err)
+	}
+	// Views share memory with the input
+	if &avatar[0] != &data[strings.Index(string(data), "\x01\x02\x03")] {
+		t.Error("Bytes copied the input")
+	}
+	if age, err := field("age").Int(); err != nil || age != 36 {
+		t.Errorf("age = %d, %v", age, err)
+	}
+	if age, err := field("age").Float(); err != nil || age != 36 {
+		t.Errorf("age as float = %v, %v", age, err)
+	}
+	if score, err := field("score").Float(); err != nil || score != 9.5 {
+		t.Errorf("score = %v, %v", score, err)
+	}
+	if active, err := field("active").Bool(); err != nil || !active {
+		t.Errorf("active = %t, %v", active, err)
+	}
+	if tag, err := field("tags", 1).Str(); err != nil || len(tag) != 40 {
+		t.Errorf("tags[1] = %q, %v", tag, err)
+	}
+	if neg, err := field("extra", "neg").Int(); err != nil || neg != -200 {
+		t.Errorf("extra.neg = %d, %v", neg, err)
+	}
+	if field("extra", "nil").Kind() != ViewNil {
+		t.Error("extra.nil is not nil")
+	}
+	if _, err := field("extra", "big").Int(); !errors.Is(err, ErrViewType) {
+		t.Errorf("overflow: got %v", err)
+	}
+	if big, err := field("extra", "big").Float(); err != nil || big != math.MaxUint64 {
+		t.Errorf("extra.big = %v, %v", big, err)
+	}
+
+	var updated time.Time
+	if err := field("updated").Decode(&updated); err != nil || !updated.Equal(profile.Updated) {
+		t.Errorf("updated = %v, %v", updated, err)
+	}
+	var tags []string
+	if err := field("tags").Decode(&tags); err != nil || len(tags) != 2 {
+		t.Errorf("tags = %v, %v", tags, err)
+	}
+	raw, _ := field("age").Raw()
+	if len(raw) != 1 {
+		t.Errorf("raw age = % x", raw)
+	}
+
+	if _, err := view.Field("missing"); !errors.Is(err, ErrViewNotFound) {
+		t.Errorf("missing key: got %v", err)
+	}
+	if _, err := field("tags").Index(2); !errors.Is(err, ErrViewNotFound) {
+		t.Errorf("missing index: got %v", err)
+	}
+	if _, err := field("name").Int(); !errors.Is(err, ErrViewType) {
+		t.Errorf("wrong kind: got %v", err)
+	}
+	if _, err := view.Index(0); !errors.Is(err, ErrViewType) {
+		t.Errorf("index into map: got %v", err)
+	}
+}
+
+func TestMsgpackViewInvalid(t *testing.T) {
+	s := NewMsgpackSerializer().(*MsgPackSerializer)
+	data, err := s.Serialize(map[string]any{"a": []int{1, 2, 3}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range data {
+		if _, err := s.View(data[:i]); err == nil {
+			t.Errorf("truncated to %d bytes: no error", i)
+		}
+	}
+	if _, err := s.View(append(data, 0xc0)); err == nil {
+		t.Error("trailing data was accepted")
+	}
+	if _, err := s.View([]byte{0xc1}); err == nil {
+		t.Error("invalid code was accepted")
+	}
+	// A forged length is rejected before anything is read
+	if _, err := s.View([]byte{0xdd, 0xff, 0xff, 0xff, 0xff}); !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("forged array length: got %v", err)
+	}
+}
+
+func BenchmarkMsgpackViewField(b *testing.B) {
+	s := NewMsgpackSerializer().(*MsgPackSerializer)
+	data, _ := s.Serialize(viewProfile{Name: "ada", Tags: []string{"a", "b"}, Extra: map[string]any{"k": 1}})
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		view, err := s.View(data)
+		if err != nil {
+			b.Fatal(err)
+		}
+		name, err := view.Field("name")
+		if err != nil {
+			b.Fatal(err)
+		}
+		if _, err := name.Str(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
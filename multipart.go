@@ -0,0 +1,69 @@
+package serializer
+
+import (
+	"errors"
+	"fmt"
+	"mime/multipart"
+	"net/textproto"
+	"strings"
+)
+
+// quoteEscaper matches mime/multipart's escaping of form field names
+var quoteEscaper = strings.NewReplacer("\\", "\\\\", `"`, "\\\"")
+
+// WriteMultipartField serializes v with s as a form-data part named name.
+// The part carries the serializer's content type so ReadMultipartField can
+// decode it without knowing the format in advance, which lets structured
+// metadata travel alongside file parts in the same upload.
+func WriteMultipartField(mw *multipart.Writer, name string, s Serializer, v any) error {
+	if mw == nil {
+		return errors.New("multipart writer is nil")
+	}
+	if s == nil {
+		return errors.New("serializer is nil")
+	}
+	h := make(textproto.MIMEHeader)
+	h.Set("Content-Disposition", fmt.Sprintf(`form-data; name="%s"`, quoteEscaper.Replace(name)))
+	h.Set("Content-Type", s.ContentType())
+	part, err := mw.CreatePart(h)
+	if err != nil {
+		return err
+	}
+	if err := s.SerializeTo(part, v); err != nil {
+		return fmt.Errorf("multipart field %q: %w", name, err)
+	}
+	return nil
+}
+
+// ReadMultipartField decodes a part written by WriteMultipartField into v.
+// The serializer is chosen from the part's Content-Type among the formats in
+// DefaultRegistry.
+func ReadMultipartField(part *multipart.Part, v any) error {
+	if part == nil {
+		return errors.New("multipart part is nil")
+	}
+	contentType := part.Header.Get("Content-Type")
+	if contentType == "" {
+		return fmt.Errorf("multipart field %q has no content type", part.FormName())
+	}
+	s, ok := DefaultRegistry.GetByContentType(contentType)
+	if !ok {
+		return fmt.Errorf("multipart field %q: no serializer registered for content type %q", part.FormName(), contentType)
+	}
+	return ReadMultipartFieldWith(part, s, v)
+}
+
+// ReadMultipartFieldWith decodes a part into v with an explicit serializer,
+// ignoring the part's Content-Type
+func ReadMultipartFieldWith(part *multipart.Part, s Serializer, v any) error {
+	if part == nil {
+		return errors.New("multipart part is nil")
+	}
+	if s == nil {
+		return errors.New("serializer is nil")
+	}
+	if err := s.DeserializeFrom(part, v); err != nil {
+		return fmt.Errorf("multipart field %q: %w", part.FormName(), err)
+	}
+	return nil
+}
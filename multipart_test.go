@@ -0,0 +1,91 @@
+package serializer
+
+import (
+	"bytes"
+	"io"
+	"mime/multipart"
+	"testing"
+)
+
+type uploadMeta struct {
+	Title string   `json:"title" msgpack:"title"`
+	Tags  []string `json:"tags" msgpack:"tags"`
+}
+
+func TestMultipartFieldRoundTrip(t *testing.T) {
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+
+	meta := uploadMeta{Title: "report", Tags: []string{"q1", "finance"}}
+	if err := WriteMultipartField(mw, "meta", NewMsgpackSerializer(), meta); err != nil {
+		t.Fatalf("WriteMultipartField failed: %v", err)
+	}
+	file, err := mw.CreateFormFile("file", "report.csv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	io.WriteString(file, "a,b\n1,2\n")
+	if err := mw.WriteField("note", "plain"); err != nil {
+		t.Fatal(err)
+	}
+	if err := mw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	mr := multipart.NewReader(&body, mw.Boundary())
+	part, err := mr.NextPart()
+	if err != nil {
+		t.Fatalf("NextPart failed: %v", err)
+	}
+	if got := part.Header.Get("Content-Type"); got != "application/x-msgpack" {
+		t.Errorf("expected msgpack content type, got %q", got)
+	}
+	if part.FormName() != "meta" {
+		t.Errorf("expected field name meta, got %q", part.FormName())
+	}
+
+	var decoded uploadMeta
+	if err := ReadMultipartField(part, &decoded); err != nil {
+		t.Fatalf("ReadMultipartField failed: %v", err)
+	}
+	if decoded.Title != meta.Title || len(decoded.Tags) != 2 || decoded.Tags[1] != "finance" {
+		t.Errorf("expected %+v, got %+v", meta, decoded)
+	}
+
+	part, err = mr.NextPart()
+	if err != nil {
+		t.Fatalf("NextPart failed: %v", err)
+	}
+	if part.FileName() != "report.csv" {
+		t.Errorf("expected file part, got %q", part.FileName())
+	}
+
+	part, err = mr.NextPart()
+	if err != nil {
+		t.Fatalf("NextPart failed: %v", err)
+	}
+	if err := ReadMultipartField(part, &decoded); err == nil {
+		t.Error("expected error for part without a serializer content type")
+	}
+}
+
+func TestReadMultipartFieldWith(t *testing.T) {
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	if err := mw.WriteField("meta", `{"title":"raw"}`); err != nil {
+		t.Fatal(err)
+	}
+	mw.Close()
+
+	part, err := multipart.NewReader(&body, mw.Boundary()).NextPart()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded uploadMeta
+	if err := ReadMultipartFieldWith(part, NewJSONSerializer(0), &decoded); err != nil {
+		t.Fatalf("ReadMultipartFieldWith failed: %v", err)
+	}
+	if decoded.Title != "raw" {
+		t.Errorf("expected title raw, got %q", decoded.Title)
+	}
+}
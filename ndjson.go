@@ -0,0 +1,192 @@
+package serializer
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// NDJSONContentType is the content type of newline-delimited JSON
+const NDJSONContentType = "application/x-ndjson"
+
+var (
+	_ StreamingSerializer = (*NDJSONSerializer)(nil)
+	_ StringDeserializer  = (*NDJSONSerializer)(nil)
+	_ OptionsSerializer   = (*NDJSONSerializer)(nil)
+)
+
+// NDJSONSerializer implements Serializer for newline-delimited JSON (NDJSON, also
+// known as JSON Lines), one record per line. Slices and arrays are written one
+// element per line and other values as a single line; decoding into a pointer to
+// a slice appends one element per line, so whole exports can be read and written
+// in one call. Records are encoded with a JSONSerializer.
+type NDJSONSerializer struct {
+	json *JSONSerializer
+}
+
+// NewNDJSONSerializer creates an NDJSON serializer whose records are encoded by a
+// JSON serializer created with maxBufferSize and opts. Records always stay on one
+// line, so WithIndent and WithIndentPrefix are ignored.
+func NewNDJSONSerializer(maxBufferSize int, opts ...Option) Serializer {
+	return &NDJSONSerializer{json: NewJSONSerializer(maxBufferSize, ndjsonOptions(opts)...).(*JSONSerializer)}
+}
+
+// ndjsonOptions appends options undoing any indentation in opts
+func ndjsonOptions(opts []Option) []Option {
+	return append(slices.Clip(opts), WithIndent(""), WithIndentPrefix(""))
+}
+
+func (s *NDJSONSerializer) Serialize(v any) ([]byte, error) {
+	if v == nil {
+		return nil, errors.New("cannot serialize nil value")
+	}
+	buf := s.json.bufferPool.Get()
+	defer s.json.bufferPool.Put(buf)
+
+	if err := s.encodeRecords(buf, v); err != nil {
+		return nil, err
+	}
+	data := make([]byte, buf.Len())
+	copy(data, buf.Bytes())
+	return data, nil
+}
+
+// SerializeWithOptions implements OptionsSerializer. Records always stay on one
+// line, so WithIndent and WithIndentPrefix are ignored.
+func (s *NDJSONSerializer) SerializeWithOptions(v any, opts ...Option) ([]byte, error) {
+	if len(opts) == 0 {
+		return s.Serialize(v)
+	}
+	c := &NDJSONSerializer{json: s.json.withCallOptions(ndjsonOptions(opts))}
+	return c.Serialize(v)
+}
+
+func (s *NDJSONSerializer) Deserialize(data []byte, v any) error {
+	if data == nil {
+		return errors.New("data is nil")
+	}
+	if err := s.json.opts.checkInputSize(len(data)); err != nil {
+		return err
+	}
+	return s.decodeRecords(bytes.NewReader(data), v)
+}
+
+// DeserializeString implements StringDeserializer interface
+func (s *NDJSONSerializer) DeserializeString(data string, v any) error {
+	if data == "" {
+		return errors.New("data is empty")
+	}
+	if err := s.json.opts.checkInputSize(len(data)); err != nil {
+		return err
+	}
+	return s.decodeRecords(strings.NewReader(data), v)
+}
+
+// SerializeTo writes the records of v to w as they are encoded
+func (s *NDJSONSerializer) SerializeTo(w io.Writer, v any) error {
+	if w == nil {
+		return errors.New("writer is nil")
+	}
+	if v == nil {
+		return errors.New("cannot serialize nil value")
+	}
+	return s.encodeRecords(w, v)
+}
+
+// DeserializeFrom reads records from r until it ends. WithMaxInputSize limits
+// the whole stream, not each record.
+func (s *NDJSONSerializer) DeserializeFrom(r io.Reader, v any) error {
+	if r == nil {
+		return errors.New("reader is nil")
+	}
+	limited, limitErr := s.json.opts.limitInput(r)
+	return limitErr(s.decodeRecords(limited, v))
+}
+
+func (s *NDJSONSerializer) maxInputSize() int64 {
+	return s.json.opts.maxInputSize
+}
+
+// NewStreamEncoder implements StreamingSerializer with a *DocumentWriter writing
+// one record per line
+func (s *NDJSONSerializer) NewStreamEncoder(w io.Writer) StreamEncoder {
+	return NewDocumentWriter(w, s.json, DelimiterNewline)
+}
+
+// NewStreamDecoder implements StreamingSerializer with a *DocumentReader reading
+// one record per line
+func (s *NDJSONSerializer) NewStreamDecoder(r io.Reader) StreamDecoder {
+	return NewDocumentReader(r, s.json, DelimiterNewline)
+}
+
+func (s *NDJSONSerializer) ContentType() string {
+	return NDJSONContentType
+}
+
+// encodeRecords writes each element of a slice or array, or v itself, as a line
+func (s *NDJSONSerializer) encodeRecords(w io.Writer, v any) error {
+	dw := NewDocumentWriter(w, s.json, DelimiterNewline)
+	rv := reflect.Indirect(reflect.ValueOf(v))
+	if !rv.IsValid() || !isNDJSONList(rv.Type()) {
+		return dw.Encode(v)
+	}
+	for i := 0; i < rv.Len(); i++ {
+		if err := dw.Encode(rv.Index(i).Interface()); err != nil {
+			return fmt.Errorf("ndjson record %d: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// decodeRecords reads every line of r into the slice v points to, or the only
+// line of r into v
+func (s *NDJSONSerializer) decodeRecords(r io.Reader, v any) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return errors.New("output parameter must be a non-nil pointer")
+	}
+	dr := NewDocumentReader(r, s.json, DelimiterNewline)
+
+	target := rv.Elem()
+	if target.Kind() != reflect.Slice || !isNDJSONList(target.Type()) {
+		if err := dr.Decode(v); err != nil {
+			if err == io.EOF {
+				return errors.New("ndjson: no record")
+			}
+			return err
+		}
+		if dr.More() {
+			return errors.New("ndjson: more than one record for a non-slice value")
+		}
+		return nil
+	}
+
+	records := reflect.MakeSlice(target.Type(), 0, 0)
+	for i := 0; ; i++ {
+		elem := reflect.New(target.Type().Elem())
+		err := dr.Decode(elem.Interface())
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("ndjson record %d: %w", i, err)
+		}
+		records = reflect.Append(records, elem.Elem())
+	}
+	target.Set(records)
+	return nil
+}
+
+// isNDJSONList reports whether values of t are written one element per line.
+// Byte slices encode as a single base64 string.
+func isNDJSONList(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Slice, reflect.Array:
+		return t.Elem().Kind() != reflect.Uint8
+	}
+	return false
+}
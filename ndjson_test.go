@@ -0,0 +1,84 @@
+package serializer
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestNDJSONSerializer(t *testing.T) {
+	s := NewNDJSONSerializer(0)
+	if got := s.ContentType(); got != NDJSONContentType {
+		t.Errorf("ContentType = %q", got)
+	}
+
+	events := []streamEvent{{Seq: 1, Kind: "created"}, {Seq: 2, Kind: "updated"}}
+	data, err := s.Serialize(events)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "{\"seq\":1,\"kind\":\"created\"}\n{\"seq\":2,\"kind\":\"updated\"}\n"; string(data) != want {
+		t.Errorf("Serialize = %q, want %q", data, want)
+	}
+
+	// Blank lines and CRLF line endings are accepted
+	var got []streamEvent
+	input := "{\"seq\":1,\"kind\":\"created\"}\r\n\n{\"seq\":2,\"kind\":\"updated\"}"
+	if err := s.DeserializeFrom(strings.NewReader(input), &got); err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 || got[0] != events[0] || got[1] != events[1] {
+		t.Errorf("DeserializeFrom = %+v", got)
+	}
+
+	if err := s.Deserialize([]byte{}, &got); err != nil || got == nil || len(got) != 0 {
+		t.Errorf("empty input: got %#v, %v", got, err)
+	}
+
+	err = s.Deserialize([]byte("{\"seq\":1}\n{\"seq\":\n"), &got)
+	if err == nil || !strings.Contains(err.Error(), "record 1") {
+		t.Errorf("bad record: got %v, want an error naming record 1", err)
+	}
+}
+
+func TestNDJSONSerializerSingleRecord(t *testing.T) {
+	s := NewNDJSONSerializer(0)
+
+	var buf bytes.Buffer
+	if err := s.SerializeTo(&buf, streamEvent{Seq: 7}); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Count(buf.String(), "\n") != 1 {
+		t.Errorf("SerializeTo wrote %q, want one line", buf.String())
+	}
+	var ev streamEvent
+	if err := s.Deserialize(buf.Bytes(), &ev); err != nil || ev.Seq != 7 {
+		t.Errorf("Deserialize: got %+v, %v", ev, err)
+	}
+
+	if err := s.Deserialize([]byte("{\"seq\":1}\n{\"seq\":2}\n"), &ev); err == nil {
+		t.Error("two records were decoded into one value")
+	}
+	if err := s.Deserialize([]byte("\n"), &ev); err == nil {
+		t.Error("input without records was accepted")
+	}
+
+	// Byte slices are a single record
+	data, err := s.Serialize([]byte("hi"))
+	if err != nil || string(data) != "\"aGk=\"\n" {
+		t.Errorf("Serialize([]byte) = %q, %v", data, err)
+	}
+}
+
+func TestNDJSONRegistered(t *testing.T) {
+	s, ok := DefaultRegistry.GetByContentType(NDJSONContentType)
+	if !ok {
+		t.Fatal("NDJSON serializer not in DefaultRegistry")
+	}
+	if _, ok := s.(*NDJSONSerializer); !ok {
+		t.Errorf("DefaultRegistry NDJSON serializer is %T", s)
+	}
+	if format, ok := FormatForPath("export.jsonl"); !ok || format != NDJSON {
+		t.Errorf("FormatForPath(.jsonl) = %q, %v", format, ok)
+	}
+}
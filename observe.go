@@ -0,0 +1,127 @@
+package serializer
+
+import (
+	"io"
+	"time"
+)
+
+// Operation names reported to an OperationObserver
+const (
+	OpSerialize         = "serialize"
+	OpDeserialize       = "deserialize"
+	OpSerializeTo       = "serialize_to"
+	OpDeserializeFrom   = "deserialize_from"
+	OpDeserializeString = "deserialize_string"
+)
+
+// OperationObserver receives a record of every operation performed by an observed serializer.
+// bytes is the size of the produced or consumed payload; for streaming operations it counts
+// the bytes that passed through the writer or reader.
+type OperationObserver interface {
+	ObserveOperation(format Format, op string, bytes int, d time.Duration, err error)
+}
+
+// Observe wraps s so every operation is reported to obs under the given format.
+// The wrapper implements StringDeserializer and PoolStatsProvider by delegating to s
+// when it supports them.
+func Observe(s Serializer, format Format, obs OperationObserver) Serializer {
+	return &observedSerializer{inner: s, format: format, obs: obs}
+}
+
+// observedSerializer is a Serializer decorator that reports operations to an observer
+type observedSerializer struct {
+	inner  Serializer
+	format Format
+	obs    OperationObserver
+}
+
+func (s *observedSerializer) Serialize(v any) ([]byte, error) {
+	start := time.Now()
+	data, err := s.inner.Serialize(v)
+	s.obs.ObserveOperation(s.format, OpSerialize, len(data), time.Since(start), err)
+	return data, err
+}
+
+func (s *observedSerializer) Deserialize(data []byte, v any) error {
+	start := time.Now()
+	err := s.inner.Deserialize(data, v)
+	s.obs.ObserveOperation(s.format, OpDeserialize, len(data), time.Since(start), err)
+	return err
+}
+
+func (s *observedSerializer) SerializeTo(w io.Writer, v any) error {
+	start := time.Now()
+	if w == nil {
+		// Let the inner serializer report the nil writer
+		err := s.inner.SerializeTo(w, v)
+		s.obs.ObserveOperation(s.format, OpSerializeTo, 0, time.Since(start), err)
+		return err
+	}
+	cw := &countingWriter{w: w}
+	err := s.inner.SerializeTo(cw, v)
+	s.obs.ObserveOperation(s.format, OpSerializeTo, cw.n, time.Since(start), err)
+	return err
+}
+
+func (s *observedSerializer) DeserializeFrom(r io.Reader, v any) error {
+	start := time.Now()
+	if r == nil {
+		// Let the inner serializer report the nil reader
+		err := s.inner.DeserializeFrom(r, v)
+		s.obs.ObserveOperation(s.format, OpDeserializeFrom, 0, time.Since(start), err)
+		return err
+	}
+	cr := &countingReader{r: r}
+	err := s.inner.DeserializeFrom(cr, v)
+	s.obs.ObserveOperation(s.format, OpDeserializeFrom, cr.n, time.Since(start), err)
+	return err
+}
+
+// DeserializeString delegates to the inner StringDeserializer when available
+func (s *observedSerializer) DeserializeString(data string, v any) error {
+	start := time.Now()
+	var err error
+	if sd, ok := s.inner.(StringDeserializer); ok {
+		err = sd.DeserializeString(data, v)
+	} else {
+		err = s.inner.Deserialize([]byte(data), v)
+	}
+	s.obs.ObserveOperation(s.format, OpDeserializeString, len(data), time.Since(start), err)
+	return err
+}
+
+func (s *observedSerializer) ContentType() string {
+	return s.inner.ContentType()
+}
+
+// PoolStats forwards to the inner serializer when it pools buffers
+func (s *observedSerializer) PoolStats() PoolStats {
+	if provider, ok := s.inner.(PoolStatsProvider); ok {
+		return provider.PoolStats()
+	}
+	return PoolStats{}
+}
+
+// countingWriter counts the bytes written through it
+type countingWriter struct {
+	w io.Writer
+	n int
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += n
+	return n, err
+}
+
+// countingReader counts the bytes read through it
+type countingReader struct {
+	r io.Reader
+	n int
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += n
+	return n, err
+}
@@ -0,0 +1,105 @@
+package serializer
+
+import (
+	"log/slog"
+	"reflect"
+)
+
+// Option configures a serializer.
+// Options that do not apply to a serializer's format are ignored.
+type Option func(*options)
+
+// options holds the configuration shared by the built-in serializers
+type options struct {
+	logger            *slog.Logger
+	oversizeThreshold int
+	poolMetrics       PoolMetrics
+
+	// Shared by the formats they apply to; see serialize_options.go
+	indent       string
+	indentPrefix string
+	sortMapKeys  bool
+	canonical    bool
+	omitEmpty    *bool
+	maxDepth     int
+	maxInputSize int64
+
+	// Deserialization checks; see validation.go
+	validation bool
+	validator  Validator
+
+	// JSON only
+	jsonBackend        JSONBackend
+	jsonConfig         JSONConfig
+	jsonEscapeHTML     bool
+	jsonComments       bool
+	jsonTrailingCommas bool
+	jsonOmitNewline    bool
+	jsonMaxTokenSize   int
+
+	// jsonCaseSensitive and jsonInvalidUTF8 are nil unless set, so each backend
+	// keeps its own default
+	jsonCaseSensitive *bool
+	jsonInvalidUTF8   *InvalidUTF8Policy
+
+	// JSON, jsoniter backend only
+	jsonInt64AsString  bool
+	jsonDurationFormat *DurationFormat
+	jsonBytesFormat    *BytesFormat
+	jsonOmitZero       bool
+	jsonDiscriminators []jsonDiscriminator
+	jsonTimeLayout     string
+	jsonTagKey         string
+
+	// MessagePack only
+	msgpackTimeFormat    MsgpackTimeFormat
+	msgpackStructAsArray bool
+	msgpackFallbackTag   string
+	msgpackOmitEmpty     bool
+	msgpackNumberMode    MsgpackNumberMode
+	msgpackCompactFloats bool
+	msgpackStringKeys    bool
+	msgpackZeroCopy      bool
+
+	// msgpackMaxBufferCap is nil unless set, so MAX_BUF_CAP applies
+	msgpackMaxBufferCap      *int
+	msgpackInitialBufferSize int
+	msgpackPoolDisabled      bool
+
+	// Gob only
+	gobEnvelope      bool
+	gobStrict        bool
+	gobBufferSize    int
+	gobTypeNamer     func(reflect.Type) string
+	gobUnknown       func(name string) any
+	gobDeterministic bool
+	gobTypeHeaders   bool
+	gobRegistry      *TypeRegistry
+}
+
+// newOptions applies opts over the defaults
+func newOptions(opts []Option) options {
+	var o options
+	for _, opt := range opts {
+		if opt != nil {
+			opt(&o)
+		}
+	}
+	return o
+}
+
+// WithLogger enables debug logging of serialization failures, oversized
+// payloads and pool discards to logger
+func WithLogger(logger *slog.Logger) Option {
+	return func(o *options) {
+		o.logger = logger
+	}
+}
+
+// WithOversizeThreshold logs a debug record whenever a serialized or
+// deserialized payload is larger than n bytes. It requires WithLogger.
+func WithOversizeThreshold(n int) Option {
+	return func(o *options) {
+		o.oversizeThreshold = n
+	}
+}
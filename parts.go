@@ -0,0 +1,106 @@
+package serializer
+
+import (
+	"errors"
+	"fmt"
+)
+
+const (
+	// S3MinPartSize is the smallest part size accepted by S3 multipart uploads
+	// for every part except the last one
+	S3MinPartSize = 5 << 20 // 5MB
+)
+
+// PartSink receives consecutive parts of a serialized payload.
+// The part slice is reused for the next part, so the sink must copy it if it
+// needs to retain the data after returning.
+type PartSink func(part []byte) error
+
+// PartWriter is an io.Writer that splits everything written to it into fixed-size
+// parts and hands each full part to a sink. Only one part is buffered at a time.
+// Close must be called to flush the final, possibly shorter, part.
+type PartWriter struct {
+	sink  PartSink
+	buf   []byte
+	parts int
+	err   error
+}
+
+// NewPartWriter creates a PartWriter that emits parts of partSize bytes to sink
+func NewPartWriter(partSize int, sink PartSink) (*PartWriter, error) {
+	if partSize <= 0 {
+		return nil, fmt.Errorf("invalid part size %d", partSize)
+	}
+	if sink == nil {
+		return nil, errors.New("part sink is nil")
+	}
+	return &PartWriter{
+		sink: sink,
+		buf:  make([]byte, 0, partSize),
+	}, nil
+}
+
+// Write implements io.Writer
+func (pw *PartWriter) Write(p []byte) (int, error) {
+	if pw.err != nil {
+		return 0, pw.err
+	}
+
+	written := 0
+	for len(p) > 0 {
+		n := copy(pw.buf[len(pw.buf):cap(pw.buf)], p)
+		pw.buf = pw.buf[:len(pw.buf)+n]
+		p = p[n:]
+		written += n
+
+		if len(pw.buf) == cap(pw.buf) {
+			if err := pw.flush(); err != nil {
+				return written, err
+			}
+		}
+	}
+	return written, nil
+}
+
+// Close flushes the final part. It emits nothing if the last part was already full.
+func (pw *PartWriter) Close() error {
+	if pw.err != nil {
+		return pw.err
+	}
+	if len(pw.buf) > 0 {
+		return pw.flush()
+	}
+	return nil
+}
+
+// Parts returns the number of parts handed to the sink so far
+func (pw *PartWriter) Parts() int {
+	return pw.parts
+}
+
+func (pw *PartWriter) flush() error {
+	if err := pw.sink(pw.buf); err != nil {
+		pw.err = fmt.Errorf("part %d: %w", pw.parts+1, err)
+		return pw.err
+	}
+	pw.parts++
+	pw.buf = pw.buf[:0]
+	return nil
+}
+
+// SerializeToParts streams v through s into fixed-size parts delivered to sink,
+// so large exports can feed S3 multipart uploads (or any chunked sink) without
+// holding the whole payload in memory. It returns the number of parts emitted.
+func SerializeToParts(s Serializer, v any, partSize int, sink PartSink) (int, error) {
+	pw, err := NewPartWriter(partSize, sink)
+	if err != nil {
+		return 0, err
+	}
+	if err := s.SerializeTo(pw, v); err != nil {
+		return pw.Parts(), err
+	}
+	if err := pw.Close(); err != nil {
+		return pw.Parts(), err
+	}
+	return pw.Parts(), nil
+}
@@ -0,0 +1,76 @@
+package serializer
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestSerializeToParts(t *testing.T) {
+	values := make([]int, 1000)
+	for i := range values {
+		values[i] = i
+	}
+
+	serializers := append([]Serializer{
+		NewJSONSerializer(maxBufferSize),
+		NewMsgpackSerializer(),
+	}, gobTestSerializers()...)
+
+	for _, s := range serializers {
+		t.Run(s.ContentType(), func(t *testing.T) {
+			const partSize = 256
+			var assembled bytes.Buffer
+			var sizes []int
+
+			parts, err := SerializeToParts(s, values, partSize, func(part []byte) error {
+				sizes = append(sizes, len(part))
+				assembled.Write(part)
+				return nil
+			})
+			if err != nil {
+				t.Fatalf("SerializeToParts failed: %v", err)
+			}
+			if parts != len(sizes) || parts < 2 {
+				t.Fatalf("expected multiple parts, got %d (sink saw %d)", parts, len(sizes))
+			}
+			for i, size := range sizes[:len(sizes)-1] {
+				if size != partSize {
+					t.Errorf("part %d has size %d, want %d", i, size, partSize)
+				}
+			}
+
+			var got []int
+			if err := s.Deserialize(assembled.Bytes(), &got); err != nil {
+				t.Fatalf("Deserialize of reassembled parts failed: %v", err)
+			}
+			if len(got) != len(values) || got[999] != 999 {
+				t.Errorf("reassembled payload mismatch")
+			}
+		})
+	}
+}
+
+func TestSerializeToPartsSinkError(t *testing.T) {
+	sinkErr := errors.New("upload failed")
+	calls := 0
+	_, err := SerializeToParts(NewMsgpackSerializer(), make([]byte, 4096), 512, func(part []byte) error {
+		calls++
+		return sinkErr
+	})
+	if !errors.Is(err, sinkErr) {
+		t.Errorf("expected sink error, got %v", err)
+	}
+	if calls != 1 {
+		t.Errorf("expected writing to stop after the first failure, sink called %d times", calls)
+	}
+}
+
+func TestNewPartWriterValidation(t *testing.T) {
+	if _, err := NewPartWriter(0, func([]byte) error { return nil }); err == nil {
+		t.Error("expected error for non-positive part size")
+	}
+	if _, err := NewPartWriter(10, nil); err == nil {
+		t.Error("expected error for nil sink")
+	}
+}
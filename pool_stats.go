@@ -0,0 +1,114 @@
+package serializer
+
+import (
+	"fmt"
+	"sync/atomic"
+)
+
+// PoolStats is a snapshot of buffer pool activity
+type PoolStats struct {
+	// Gets is the number of buffers taken from the pool
+	Gets uint64
+	// Misses is the number of gets that had to allocate a new buffer
+	Misses uint64
+	// Puts is the number of buffers returned to the pool for reuse
+	Puts uint64
+	// Discards is the number of buffers dropped for exceeding the size limit
+	Discards uint64
+	// InUse is the number of buffers taken from the pool and not yet returned
+	// or discarded, a measure of current pool pressure
+	InUse int64
+}
+
+// Hits returns the number of gets served by a reused buffer
+func (s PoolStats) Hits() uint64 {
+	if s.Misses > s.Gets {
+		return 0
+	}
+	return s.Gets - s.Misses
+}
+
+// PoolStatsProvider is implemented by serializers that pool buffers
+type PoolStatsProvider interface {
+	PoolStats() PoolStats
+}
+
+// PoolEvent is a pool activity reported to PoolMetrics
+type PoolEvent int
+
+const (
+	// PoolHit is a get served by a reused buffer
+	PoolHit PoolEvent = iota
+	// PoolMiss is a get that allocated a new buffer
+	PoolMiss
+	// PoolPut is a buffer returned to the pool for reuse
+	PoolPut
+	// PoolDiscard is a buffer dropped for exceeding the size limit
+	PoolDiscard
+)
+
+func (e PoolEvent) String() string {
+	switch e {
+	case PoolHit:
+		return "hit"
+	case PoolMiss:
+		return "miss"
+	case PoolPut:
+		return "put"
+	case PoolDiscard:
+		return "discard"
+	}
+	return fmt.Sprintf("PoolEvent(%d)", int(e))
+}
+
+// PoolMetrics receives pool events as they happen, for metrics systems that
+// count events rather than read PoolStats. pool names the pool, such as
+// "json_buffer" or "msgpack_encoder", and capacity is the capacity of the buffer
+// involved, which is 0 for misses. ObservePool is called on the hot path of
+// every operation, so it must be fast and safe for concurrent use.
+type PoolMetrics interface {
+	ObservePool(pool string, event PoolEvent, capacity int)
+}
+
+// WithPoolMetrics reports every event of the serializer's buffer or encoder pool
+// to m. The JSON, MessagePack, BSON and Protocol Buffers serializers support it.
+func WithPoolMetrics(m PoolMetrics) Option {
+	return func(o *options) {
+		o.poolMetrics = m
+	}
+}
+
+// poolObserver returns the hook reporting the events of the pool named pool,
+// which keeps buffers up to limit bytes, or nil when nothing listens
+func (o *options) poolObserver(pool string, limit int) func(event PoolEvent, capacity int) {
+	if o.logger == nil && o.poolMetrics == nil {
+		return nil
+	}
+	return func(event PoolEvent, capacity int) {
+		if event == PoolDiscard {
+			o.logPoolDiscard(pool, capacity, limit)
+		}
+		if o.poolMetrics != nil {
+			o.poolMetrics.ObservePool(pool, event, capacity)
+		}
+	}
+}
+
+// poolCounters tracks pool activity with atomic counters
+type poolCounters struct {
+	gets     atomic.Uint64
+	misses   atomic.Uint64
+	puts     atomic.Uint64
+	discards atomic.Uint64
+	inUse    atomic.Int64
+}
+
+func (c *poolCounters) snapshot() PoolStats {
+	return PoolStats{
+		Gets:     c.gets.Load(),
+		Misses:   c.misses.Load(),
+		Puts:     c.puts.Load(),
+		Discards: c.discards.Load(),
+		InUse:    c.inUse.Load(),
+	}
+}
@@ -0,0 +1,112 @@
+package serializer
+
+import (
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestJSONPoolStats(t *testing.T) {
+	s := NewJSONSerializer(64).(*JSONSerializer)
+
+	for i := 0; i < 3; i++ {
+		if _, err := s.Serialize("small"); err != nil {
+			t.Fatalf("Serialize failed: %v", err)
+		}
+	}
+	if _, err := s.Serialize(strings.Repeat("x", 1024)); err != nil {
+		t.Fatalf("Serialize failed: %v", err)
+	}
+
+	stats := s.PoolStats()
+	if stats.Gets != 4 {
+		t.Errorf("expected 4 gets, got %d", stats.Gets)
+	}
+	if stats.Discards != 1 {
+		t.Errorf("expected 1 discard, got %d", stats.Discards)
+	}
+	if stats.Puts+stats.Discards != stats.Gets {
+		t.Errorf("every get should end in a put or discard: %+v", stats)
+	}
+	if stats.Hits()+stats.Misses != stats.Gets {
+		t.Errorf("hits and misses should add up to gets: %+v", stats)
+	}
+}
+
+func TestMsgpackPoolStatsDiscards(t *testing.T) {
+	s := NewMsgpackSerializer().(*MsgPackSerializer)
+	before := s.PoolStats()
+
+	if _, err := s.Serialize(make([]byte, MAX_BUF_CAP+1)); err != nil {
+		t.Fatalf("Serialize failed: %v", err)
+	}
+
+	after := s.PoolStats()
+	if after.Gets <= before.Gets {
+		t.Errorf("expected gets to increase: before %+v, after %+v", before, after)
+	}
+	if after.Discards != before.Discards+1 {
+		t.Errorf("expected one additional discard: before %+v, after %+v", before, after)
+	}
+}
+
+// recordingPoolMetrics records the pool events it observes
+type recordingPoolMetrics struct {
+	mu     sync.Mutex
+	events map[string][]PoolEvent
+}
+
+func (m *recordingPoolMetrics) ObservePool(pool string, event PoolEvent, capacity int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.events == nil {
+		m.events = make(map[string][]PoolEvent)
+	}
+	m.events[pool] = append(m.events[pool], event)
+}
+
+func TestPoolMetrics(t *testing.T) {
+	m := &recordingPoolMetrics{}
+	js := NewJSONSerializer(64, WithPoolMetrics(m))
+	mp := NewMsgpackSerializer(WithPoolMetrics(m), WithMaxBufferCap(64))
+
+	for _, s := range []Serializer{js, mp} {
+		if _, err := s.Serialize("small"); err != nil {
+			t.Fatalf("Serialize failed: %v", err)
+		}
+		if _, err := s.Serialize("small"); err != nil {
+			t.Fatalf("Serialize failed: %v", err)
+		}
+		if _, err := s.Serialize(strings.Repeat("x", 1024)); err != nil {
+			t.Fatalf("Serialize failed: %v", err)
+		}
+	}
+
+	for _, pool := range []string{"json_buffer", "msgpack_encoder"} {
+		events := m.events[pool]
+		// The reused buffer of the second call may have been collected, so only
+		// the first and last calls are certain
+		if len(events) != 6 || events[0] != PoolMiss || events[1] != PoolPut || events[5] != PoolDiscard {
+			t.Errorf("%s: got events %v", pool, events)
+		}
+	}
+}
+
+func TestPoolStatsInUse(t *testing.T) {
+	s := NewJSONSerializer(0).(*JSONSerializer)
+	pb, err := s.SerializePooled("held")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := s.PoolStats().InUse; got != 1 {
+		t.Errorf("InUse with a buffer held = %d, want 1", got)
+	}
+	pb.Release()
+	if got := s.PoolStats().InUse; got != 0 {
+		t.Errorf("InUse after release = %d, want 0", got)
+	}
+
+	if got := PoolDiscard.String(); got != "discard" {
+		t.Errorf("PoolDiscard.String() = %q", got)
+	}
+}
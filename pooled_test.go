@@ -0,0 +1,76 @@
+package serializer
+
+import (
+	"testing"
+)
+
+func TestPooledSerializers(t *testing.T) {
+	serializers := []struct {
+		name string
+		s    Serializer
+	}{
+		{"JSON", NewJSONSerializer(0)},
+		{"MsgPack", NewMsgpackSerializer()},
+	}
+	for _, tc := range serializers {
+		t.Run(tc.name, func(t *testing.T) {
+			testPooledSerializer(t, tc.s)
+		})
+	}
+}
+
+// testPooledSerializer checks that the pooled methods of s agree with Serialize
+// and reject nil input
+func testPooledSerializer(t *testing.T, s Serializer) {
+	t.Helper()
+	ps, ok := s.(PooledSerializer)
+	if !ok {
+		t.Fatalf("%T does not implement PooledSerializer", s)
+	}
+
+	want := streamEvent{Seq: 3, Kind: "created"}
+	pb, err := ps.SerializePooled(want)
+	if err != nil {
+		t.Fatalf("SerializePooled failed: %v", err)
+	}
+	defer pb.Release()
+
+	data, err := s.Serialize(want)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(pb.Bytes()) != string(data) || pb.Len() != len(data) {
+		t.Errorf("pooled bytes %q differ from Serialize %q", pb.Bytes(), data)
+	}
+
+	var got streamEvent
+	if err := ps.DeserializeFromPooled(pb, &got); err != nil || got != want {
+		t.Errorf("DeserializeFromPooled: got %+v, %v", got, err)
+	}
+
+	if _, err := ps.SerializePooled(nil); err == nil {
+		t.Error("nil value was accepted")
+	}
+	if err := ps.DeserializeFromPooled(nil, &got); err == nil {
+		t.Error("nil PooledBuf was accepted")
+	}
+}
+
+func TestPooledBufRelease(t *testing.T) {
+	s := NewJSONSerializer(0).(*JSONSerializer)
+	before := s.PoolStats()
+
+	pb, err := s.SerializePooled(map[string]int{"a": 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	pb.Release()
+	pb.Release() // a second release is a no-op
+
+	if pb.Bytes() != nil || pb.Len() != 0 {
+		t.Errorf("released buffer still has %d bytes", pb.Len())
+	}
+	if got := s.PoolStats().Puts - before.Puts; got != 1 {
+		t.Errorf("buffer returned %d times, want once", got)
+	}
+}
@@ -0,0 +1,198 @@
+package serializer
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+
+	"google.golang.org/protobuf/proto"
+)
+
+// ErrNotProtoMessage is returned when a protobuf serializer is given a value that
+// is not a proto.Message
+var ErrNotProtoMessage = errors.New("protobuf: value is not a proto.Message")
+
+// ProtoSerializer implements Serializer for compiled protobuf messages. Values must
+// implement proto.Message; anything else fails with ErrNotProtoMessage. Use
+// DynamicProtoSerializer for message types only known from a descriptor set.
+type ProtoSerializer struct {
+	bufferPool *pooledBufferPool
+	opts       options
+}
+
+// NewProtoSerializer creates a new Protocol Buffers serializer
+func NewProtoSerializer(opts ...Option) Serializer {
+	s := &ProtoSerializer{
+		bufferPool: newPooledBufferPool(MAX_BUF_CAP),
+		opts:       newOptions(opts),
+	}
+	s.opts.bindLogger(Protobuf)
+	s.bufferPool.observe = s.opts.poolObserver("protobuf_buffer", MAX_BUF_CAP)
+	return s
+}
+
+// protoMessage returns v as a proto.Message
+func protoMessage(v any) (proto.Message, error) {
+	if v == nil {
+		return nil, errors.New("cannot serialize nil value")
+	}
+	m, ok := v.(proto.Message)
+	if !ok {
+		return nil, fmt.Errorf("%w: %T", ErrNotProtoMessage, v)
+	}
+	return m, nil
+}
+
+// Serialize encodes a proto.Message. proto.Marshal sizes the message first and
+// allocates the result once, so no pooled buffer is needed here.
+func (s *ProtoSerializer) Serialize(v any) ([]byte, error) {
+	m, err := protoMessage(v)
+	if err != nil {
+		return nil, err
+	}
+	data, err := proto.Marshal(m)
+	if err != nil {
+		s.opts.logFailure("serialize", err)
+		return nil, err
+	}
+	s.opts.logSize("serialize", len(data))
+	return data, nil
+}
+
+// Deserialize decodes data into v, which must be a non-nil proto.Message
+func (s *ProtoSerializer) Deserialize(data []byte, v any) error {
+	if data == nil {
+		return errors.New("data is nil")
+	}
+	if v == nil {
+		return errors.New("output parameter is nil")
+	}
+	m, err := protoMessage(v)
+	if err != nil {
+		return err
+	}
+	s.opts.logSize("deserialize", len(data))
+	err = s.opts.checkInputSize(len(data))
+	if err == nil {
+		err = proto.Unmarshal(data, m)
+	}
+	if err == nil {
+		err = s.opts.validate(v)
+	}
+	s.opts.logFailure("deserialize", err)
+	return err
+}
+
+// DeserializeString implements StringDeserializer interface
+// Uses unsafe string-to-bytes conversion to avoid allocation; decoded strings and
+// bytes fields are copied, so nothing aliases data
+func (s *ProtoSerializer) DeserializeString(data string, v any) error {
+	if data == "" {
+		return errors.New("data is empty")
+	}
+	return s.Deserialize(stringToReadOnlyBytes(data), v)
+}
+
+// SerializeWithTypeInfo implements TypedSerializer interface. Protobuf needs no type
+// information to encode, so it is the same as Serialize.
+func (s *ProtoSerializer) SerializeWithTypeInfo(v any, typeInfo TypeInfo) ([]byte, error) {
+	data, err := s.Serialize(v)
+	if err != nil {
+		return nil, fmt.Errorf("protobuf serialization failed for type %s: %w", typeInfo.TypeName, err)
+	}
+	return data, nil
+}
+
+// DeserializeWithTypeInfo implements TypedSerializer interface. typeInfo.Type is
+// normally a pointer to a generated message struct; a new message is allocated,
+// filled and returned.
+func (s *ProtoSerializer) DeserializeWithTypeInfo(data []byte, typeInfo TypeInfo) (any, error) {
+	target, err := newTypedTarget(typeInfo)
+	if err != nil {
+		return nil, err
+	}
+	// Generated messages implement proto.Message on the pointer, which
+	// newTypedTarget has already allocated for pointer types
+	msg := target
+	if typeInfo.Type.Kind() == reflect.Ptr {
+		msg = target.Elem()
+	}
+	if err := s.Deserialize(data, msg.Interface()); err != nil {
+		return nil, fmt.Errorf("protobuf deserialization failed for type %s: %w", typeInfo.TypeName, err)
+	}
+	return target.Elem().Interface(), nil
+}
+
+// SerializeTo encodes v into a pooled buffer and writes it to w in a single Write
+func (s *ProtoSerializer) SerializeTo(w io.Writer, v any) error {
+	if w == nil {
+		return errors.New("writer is nil")
+	}
+	m, err := protoMessage(v)
+	if err != nil {
+		return err
+	}
+
+	buf := s.bufferPool.Get()
+	defer s.bufferPool.Put(buf)
+
+	data, err := proto.MarshalOptions{}.MarshalAppend(buf.AvailableBuffer(), m)
+	if err == nil {
+		// Keep the encoding in buf so the buffer grows to fit for the next use
+		buf.Write(data)
+		_, err = w.Write(buf.Bytes())
+	}
+	s.opts.logFailure("serialize_to", err)
+	return err
+}
+
+// DeserializeFrom reads r to EOF into a pooled buffer and decodes it.
+// Protobuf messages are not self-delimiting, so r must contain exactly one message.
+func (s *ProtoSerializer) DeserializeFrom(r io.Reader, v any) error {
+	if r == nil {
+		return errors.New("reader is nil")
+	}
+
+	buf := s.bufferPool.Get()
+	defer s.bufferPool.Put(buf)
+
+	limited, limitErr := s.opts.limitInput(r)
+	if _, err := buf.ReadFrom(limited); err != nil {
+		err = limitErr(err)
+		s.opts.logFailure("deserialize_from", err)
+		return err
+	}
+	data := buf.Bytes()
+	if data == nil {
+		// An empty stream is a valid empty message, not missing data
+		data = []byte{}
+	}
+	return s.Deserialize(data, v)
+}
+
+// NewStreamEncoder implements StreamingSerializer with a *FrameWriter, writing
+// each message with a varint length prefix as protodelim and Java's
+// writeDelimitedTo do
+func (s *ProtoSerializer) NewStreamEncoder(w io.Writer) StreamEncoder {
+	return NewFrameWriter(w, s)
+}
+
+// NewStreamDecoder implements StreamingSerializer with a *FrameReader reading
+// varint length-delimited messages
+func (s *ProtoSerializer) NewStreamDecoder(r io.Reader) StreamDecoder {
+	return NewFrameReader(r, s)
+}
+
+// PoolStats implements PoolStatsProvider for this serializer's buffer pool
+func (s *ProtoSerializer) PoolStats() PoolStats {
+	return s.bufferPool.stats.snapshot()
+}
+
+func (s *ProtoSerializer) maxInputSize() int64 {
+	return s.opts.maxInputSize
+}
+
+func (s *ProtoSerializer) ContentType() string {
+	return ProtobufContentType
+}
@@ -0,0 +1,173 @@
+package serializer
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// ProtobufContentType is the content type used for protobuf payloads
+const ProtobufContentType = "application/x-protobuf"
+
+// DynamicProtoSerializer serializes protobuf messages whose Go types are not compiled
+// into the binary. The message type is resolved at runtime from a FileDescriptorSet
+// (as produced by `protoc --descriptor_set_out --include_imports`) and values are
+// represented as *dynamicpb.Message.
+type DynamicProtoSerializer struct {
+	desc  protoreflect.MessageDescriptor
+	files *protoregistry.Files
+}
+
+// NewDynamicProtoSerializer resolves messageName (a fully-qualified protobuf name such as
+// "acme.v1.Order") from fds and returns a serializer for it
+func NewDynamicProtoSerializer(fds *descriptorpb.FileDescriptorSet, messageName string) (*DynamicProtoSerializer, error) {
+	if fds == nil {
+		return nil, errors.New("file descriptor set is nil")
+	}
+	files, err := protodesc.NewFiles(fds)
+	if err != nil {
+		return nil, fmt.Errorf("invalid file descriptor set: %w", err)
+	}
+	d, err := files.FindDescriptorByName(protoreflect.FullName(messageName))
+	if err != nil {
+		return nil, fmt.Errorf("message %q: %w", messageName, err)
+	}
+	desc, ok := d.(protoreflect.MessageDescriptor)
+	if !ok {
+		return nil, fmt.Errorf("%q is not a message type", messageName)
+	}
+	return &DynamicProtoSerializer{desc: desc, files: files}, nil
+}
+
+// Descriptor returns the message descriptor handled by this serializer
+func (s *DynamicProtoSerializer) Descriptor() protoreflect.MessageDescriptor {
+	return s.desc
+}
+
+// NewMessage returns an empty dynamic message of the serializer's type
+func (s *DynamicProtoSerializer) NewMessage() *dynamicpb.Message {
+	return dynamicpb.NewMessage(s.desc)
+}
+
+// Serialize encodes a proto.Message of the serializer's type
+func (s *DynamicProtoSerializer) Serialize(v any) ([]byte, error) {
+	m, err := s.message(v)
+	if err != nil {
+		return nil, err
+	}
+	return proto.Marshal(m)
+}
+
+// Deserialize decodes data into v, which must be a proto.Message of the serializer's
+// type or a *any that receives a new *dynamicpb.Message
+func (s *DynamicProtoSerializer) Deserialize(data []byte, v any) error {
+	if data == nil {
+		return errors.New("data is nil")
+	}
+	if target, ok := v.(*any); ok {
+		m := s.NewMessage()
+		if err := proto.Unmarshal(data, m); err != nil {
+			return err
+		}
+		*target = m
+		return nil
+	}
+	m, err := s.message(v)
+	if err != nil {
+		return err
+	}
+	return proto.Unmarshal(data, m)
+}
+
+func (s *DynamicProtoSerializer) SerializeTo(w io.Writer, v any) error {
+	if w == nil {
+		return errors.New("writer is nil")
+	}
+	data, err := s.Serialize(v)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+// DeserializeFrom reads r to EOF and decodes it.
+// Protobuf messages are not self-delimiting, so r must contain exactly one message.
+func (s *DynamicProtoSerializer) DeserializeFrom(r io.Reader, v any) error {
+	if r == nil {
+		return errors.New("reader is nil")
+	}
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	return s.Deserialize(data, v)
+}
+
+// NewStreamEncoder implements StreamingSerializer with a *FrameWriter writing
+// varint length-delimited messages
+func (s *DynamicProtoSerializer) NewStreamEncoder(w io.Writer) StreamEncoder {
+	return NewFrameWriter(w, s)
+}
+
+// NewStreamDecoder implements StreamingSerializer with a *FrameReader reading
+// varint length-delimited messages
+func (s *DynamicProtoSerializer) NewStreamDecoder(r io.Reader) StreamDecoder {
+	return NewFrameReader(r, s)
+}
+
+// DeserializeString implements StringDeserializer interface
+// Uses unsafe string-to-bytes conversion to avoid allocation
+func (s *DynamicProtoSerializer) DeserializeString(data string, v any) error {
+	if data == "" {
+		return errors.New("data is empty")
+	}
+	return s.Deserialize(stringToReadOnlyBytes(data), v)
+}
+
+func (s *DynamicProtoSerializer) ContentType() string {
+	return ProtobufContentType
+}
+
+// ToJSON transcodes a binary protobuf payload to its canonical JSON mapping
+func (s *DynamicProtoSerializer) ToJSON(data []byte) ([]byte, error) {
+	m := s.NewMessage()
+	if err := proto.Unmarshal(data, m); err != nil {
+		return nil, err
+	}
+	return protojson.MarshalOptions{Resolver: s.resolver()}.Marshal(m)
+}
+
+// FromJSON transcodes the canonical JSON mapping of a message to binary protobuf
+func (s *DynamicProtoSerializer) FromJSON(data []byte) ([]byte, error) {
+	m := s.NewMessage()
+	if err := (protojson.UnmarshalOptions{Resolver: s.resolver()}).Unmarshal(data, m); err != nil {
+		return nil, err
+	}
+	return proto.Marshal(m)
+}
+
+// resolver resolves Any and extension types against the descriptor set
+func (s *DynamicProtoSerializer) resolver() *dynamicpb.Types {
+	return dynamicpb.NewTypes(s.files)
+}
+
+// message checks that v is a proto.Message of the serializer's type
+func (s *DynamicProtoSerializer) message(v any) (proto.Message, error) {
+	m, err := protoMessage(v)
+	if err != nil {
+		return nil, err
+	}
+	if got := m.ProtoReflect().Descriptor().FullName(); got != s.desc.FullName() {
+		return nil, fmt.Errorf("protobuf: message type %s does not match %s", got, s.desc.FullName())
+	}
+	return m, nil
+}
@@ -0,0 +1,114 @@
+package serializer
+
+import (
+	"strings"
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// testDescriptorSet describes:
+//
+//	package acme.v1;
+//	message Order { string id = 1; int64 quantity = 2; }
+func testDescriptorSet() *descriptorpb.FileDescriptorSet {
+	return &descriptorpb.FileDescriptorSet{
+		File: []*descriptorpb.FileDescriptorProto{{
+			Name:    proto.String("acme/v1/order.proto"),
+			Package: proto.String("acme.v1"),
+			Syntax:  proto.String("proto3"),
+			MessageType: []*descriptorpb.DescriptorProto{{
+				Name: proto.String("Order"),
+				Field: []*descriptorpb.FieldDescriptorProto{
+					{
+						Name:     proto.String("id"),
+						JsonName: proto.String("id"),
+						Number:   proto.Int32(1),
+						Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+						Type:     descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum(),
+					},
+					{
+						Name:     proto.String("quantity"),
+						JsonName: proto.String("quantity"),
+						Number:   proto.Int32(2),
+						Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+						Type:     descriptorpb.FieldDescriptorProto_TYPE_INT64.Enum(),
+					},
+				},
+			}},
+		}},
+	}
+}
+
+func TestDynamicProtoRoundTrip(t *testing.T) {
+	s, err := NewDynamicProtoSerializer(testDescriptorSet(), "acme.v1.Order")
+	if err != nil {
+		t.Fatalf("NewDynamicProtoSerializer failed: %v", err)
+	}
+
+	order := s.NewMessage()
+	fields := s.Descriptor().Fields()
+	order.Set(fields.ByName("id"), protoreflect.ValueOfString("o-1"))
+	order.Set(fields.ByName("quantity"), protoreflect.ValueOfInt64(3))
+
+	data, err := s.Serialize(order)
+	if err != nil {
+		t.Fatalf("Serialize failed: %v", err)
+	}
+
+	var decoded any
+	if err := s.Deserialize(data, &decoded); err != nil {
+		t.Fatalf("Deserialize failed: %v", err)
+	}
+	m, ok := decoded.(*dynamicpb.Message)
+	if !ok {
+		t.Fatalf("expected *dynamicpb.Message, got %T", decoded)
+	}
+	if !proto.Equal(m, order) {
+		t.Errorf("round trip mismatch: %v vs %v", m, order)
+	}
+
+	fromString := s.NewMessage()
+	if err := s.DeserializeString(string(data), fromString); err != nil {
+		t.Fatalf("DeserializeString failed: %v", err)
+	}
+	if !proto.Equal(fromString, order) {
+		t.Errorf("DeserializeString mismatch")
+	}
+}
+
+func TestDynamicProtoJSONTranscoding(t *testing.T) {
+	s, err := NewDynamicProtoSerializer(testDescriptorSet(), "acme.v1.Order")
+	if err != nil {
+		t.Fatalf("NewDynamicProtoSerializer failed: %v", err)
+	}
+
+	binary, err := s.FromJSON([]byte(`{"id":"o-2","quantity":"7"}`))
+	if err != nil {
+		t.Fatalf("FromJSON failed: %v", err)
+	}
+	out, err := s.ToJSON(binary)
+	if err != nil {
+		t.Fatalf("ToJSON failed: %v", err)
+	}
+	if !strings.Contains(string(out), `"o-2"`) || !strings.Contains(string(out), `"7"`) {
+		t.Errorf("unexpected JSON %s", out)
+	}
+}
+
+func TestDynamicProtoErrors(t *testing.T) {
+	if _, err := NewDynamicProtoSerializer(testDescriptorSet(), "acme.v1.Missing"); err == nil {
+		t.Error("expected error for unknown message name")
+	}
+
+	s, _ := NewDynamicProtoSerializer(testDescriptorSet(), "acme.v1.Order")
+	if _, err := s.Serialize(map[string]any{"id": "x"}); err == nil {
+		t.Error("expected error serializing a non-proto value")
+	}
+	if _, err := s.Serialize(&descriptorpb.FileDescriptorProto{}); err == nil {
+		t.Error("expected error serializing a message of another type")
+	}
+}
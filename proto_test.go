@@ -0,0 +1,140 @@
+package serializer
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"google.golang.org/protobuf/encoding/protodelim"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+func TestProtoSerializer(t *testing.T) {
+	s := NewProtoSerializer()
+	if got := s.ContentType(); got != ProtobufContentType {
+		t.Errorf("ContentType = %q", got)
+	}
+
+	want, err := structpb.NewStruct(map[string]any{"id": "o-1", "quantity": 3, "tags": []any{"a"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := s.Serialize(want)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := &structpb.Struct{}
+	if err := s.Deserialize(data, got); err != nil {
+		t.Fatal(err)
+	}
+	if !proto.Equal(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	got = &structpb.Struct{}
+	if err := s.(StringDeserializer).DeserializeString(string(data), got); err != nil || !proto.Equal(got, want) {
+		t.Errorf("DeserializeString: got %v, %v", got, err)
+	}
+
+	var buf bytes.Buffer
+	if err := s.SerializeTo(&buf, want); err != nil {
+		t.Fatal(err)
+	}
+	if buf.Len() != len(data) {
+		t.Errorf("SerializeTo wrote %d bytes, Serialize %d", buf.Len(), len(data))
+	}
+	got = &structpb.Struct{}
+	if err := s.DeserializeFrom(&buf, got); err != nil || !proto.Equal(got, want) {
+		t.Errorf("DeserializeFrom: got %v, %v", got, err)
+	}
+
+	// An empty payload is a message with every field unset
+	ts := timestamppb.New(time.Unix(1, 0))
+	if err := s.DeserializeFrom(bytes.NewReader(nil), ts); err != nil || ts.Seconds != 0 {
+		t.Errorf("empty stream: got %v, %v", ts, err)
+	}
+
+	typed, err := Unmarshal[*structpb.Struct](s, data)
+	if err != nil || !proto.Equal(typed, want) {
+		t.Errorf("Unmarshal: got %v, %v", typed, err)
+	}
+
+	if stats := s.(PoolStatsProvider).PoolStats(); stats.Gets < 2 {
+		t.Errorf("pool not used: %+v", stats)
+	}
+}
+
+func TestProtoSerializerErrors(t *testing.T) {
+	s := NewProtoSerializer()
+
+	type notProto struct{ ID string }
+	if _, err := s.Serialize(notProto{ID: "x"}); !errors.Is(err, ErrNotProtoMessage) {
+		t.Errorf("Serialize: got %v, want ErrNotProtoMessage", err)
+	}
+	if err := s.SerializeTo(&bytes.Buffer{}, "text"); !errors.Is(err, ErrNotProtoMessage) {
+		t.Errorf("SerializeTo: got %v, want ErrNotProtoMessage", err)
+	}
+	var m map[string]any
+	if err := s.Deserialize([]byte{}, &m); !errors.Is(err, ErrNotProtoMessage) {
+		t.Errorf("Deserialize: got %v, want ErrNotProtoMessage", err)
+	}
+	if _, err := s.Serialize(nil); err == nil {
+		t.Error("nil value was accepted")
+	}
+	if err := s.Deserialize([]byte{0xff}, wrapperspb.String("")); err == nil {
+		t.Error("invalid data was accepted")
+	}
+}
+
+func TestProtoRegistered(t *testing.T) {
+	s, err := DefaultRegistry.New(Protobuf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := s.(*ProtoSerializer); !ok {
+		t.Errorf("DefaultRegistry protobuf serializer is %T", s)
+	}
+	if format, ok := FormatForPath("orders.binpb"); !ok || format != Protobuf {
+		t.Errorf("FormatForPath = %q, %t", format, ok)
+	}
+}
+
+func TestProtoStream(t *testing.T) {
+	s := NewProtoSerializer()
+
+	var buf bytes.Buffer
+	enc := NewStreamEncoder(s, &buf)
+	for i := int64(0); i < 3; i++ {
+		if err := enc.Encode(wrapperspb.Int64(i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Messages are length-delimited as protodelim writes them
+	r := bufio.NewReader(bytes.NewReader(buf.Bytes()))
+	first := &wrapperspb.Int64Value{}
+	if err := protodelim.UnmarshalFrom(r, first); err != nil || first.Value != 0 {
+		t.Fatalf("protodelim: got %v, %v", first, err)
+	}
+
+	dec := NewStreamDecoder(s, r)
+	for i := int64(1); i < 3; i++ {
+		got := &wrapperspb.Int64Value{}
+		if !dec.More() {
+			t.Fatalf("More reported the end before message %d", i)
+		}
+		if err := dec.Decode(got); err != nil || got.Value != i {
+			t.Fatalf("message %d: got %v, %v", i, got, err)
+		}
+	}
+	if err := dec.Decode(&wrapperspb.Int64Value{}); err != io.EOF {
+		t.Errorf("end of stream: got %v, want io.EOF", err)
+	}
+}
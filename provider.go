@@ -0,0 +1,138 @@
+package serializer
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Provider describes a serialization format implemented outside this package.
+// Packages implementing a format register a Provider from an init function, so
+// importing them (for side effects, like database/sql drivers) is enough to make
+// the format available:
+//
+//	func init() {
+//		serializer.MustRegisterProvider(serializer.Provider{
+//			Format:       "yaml",
+//			Extensions:   []string{".yaml", ".yml"},
+//			Capabilities: serializer.Capabilities{Streaming: true},
+//			New:          func() serializer.Serializer { return NewYAMLSerializer() },
+//		})
+//	}
+type Provider struct {
+	// Format is the name the serializer is registered under in DefaultRegistry
+	Format Format
+	// ContentType is the MIME type of the format. When empty, the ContentType of
+	// a serializer returned by New is used.
+	ContentType string
+	// Extensions are file extensions, such as ".yaml", that FormatForPath maps
+	// to the format
+	Extensions []string
+	// Capabilities describes what the format supports
+	Capabilities Capabilities
+	// New returns a serializer with default settings
+	New func() Serializer
+}
+
+// Capabilities describes what a format supports, so callers can choose between
+// registered formats without trying them
+type Capabilities struct {
+	// Binary is set when the output is not text
+	Binary bool
+	// Streaming is set when values can be written one after another with
+	// SerializeTo and read back in turn with DeserializeFrom on the same stream
+	Streaming bool
+	// Deterministic is set when equal values always encode to identical bytes
+	Deterministic bool
+	// SchemaRequired is set when values cannot be decoded without a schema or
+	// registered types, as with Protocol Buffers or Avro
+	SchemaRequired bool
+}
+
+// providers records the formats registered through RegisterProvider. The lock
+// also guards extensionFormats, which registration extends.
+var providers = struct {
+	sync.RWMutex
+	byFormat map[Format]Provider
+}{byFormat: make(map[Format]Provider)}
+
+// RegisterProvider adds a format implemented outside this package: a serializer
+// from p.New is registered in DefaultRegistry under p.Format, and p.Extensions
+// are recognized by FormatForPath. Formats and extensions already in use, by this
+// package or another provider, are an error.
+//
+// Registrations are process-wide. Register during program initialization, before
+// DefaultRegistry is used.
+func RegisterProvider(p Provider) error {
+	if p.Format == "" {
+		return errors.New("serializer provider needs a format name")
+	}
+	if p.New == nil {
+		return fmt.Errorf("serializer provider %q needs a New function", p.Format)
+	}
+
+	providers.Lock()
+	defer providers.Unlock()
+	if _, ok := DefaultRegistry.Get(p.Format); ok {
+		return fmt.Errorf("serializer format %q already registered", p.Format)
+	}
+	exts := make([]string, len(p.Extensions))
+	for i, ext := range p.Extensions {
+		ext = strings.ToLower(ext)
+		if !strings.HasPrefix(ext, ".") || len(ext) < 2 {
+			return fmt.Errorf("serializer provider %q: invalid extension %q", p.Format, p.Extensions[i])
+		}
+		if existing, ok := extensionFormats[ext]; ok {
+			return fmt.Errorf("serializer provider %q: extension %s already used by %q", p.Format, ext, existing)
+		}
+		exts[i] = ext
+	}
+
+	s := p.New()
+	if s == nil {
+		return fmt.Errorf("serializer provider %q: New returned nil", p.Format)
+	}
+	if p.ContentType == "" {
+		p.ContentType = s.ContentType()
+	}
+	p.Extensions = exts
+
+	DefaultRegistry.Register(p.Format, s)
+	for _, ext := range exts {
+		extensionFormats[ext] = p.Format
+	}
+	providers.byFormat[p.Format] = p
+	return nil
+}
+
+// MustRegisterProvider is like RegisterProvider but panics on error
+func MustRegisterProvider(p Provider) {
+	if err := RegisterProvider(p); err != nil {
+		panic(err)
+	}
+}
+
+// LookupProvider returns the provider registered for format
+func LookupProvider(format Format) (Provider, bool) {
+	providers.RLock()
+	defer providers.RUnlock()
+	p, ok := providers.byFormat[format]
+	p.Extensions = slices.Clone(p.Extensions)
+	return p, ok
+}
+
+// Providers returns the registered providers sorted by format
+func Providers() []Provider {
+	providers.RLock()
+	defer providers.RUnlock()
+	list := make([]Provider, 0, len(providers.byFormat))
+	for _, p := range providers.byFormat {
+		p.Extensions = slices.Clone(p.Extensions)
+		list = append(list, p)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Format < list[j].Format })
+	return list
+}
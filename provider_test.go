@@ -0,0 +1,116 @@
+package serializer
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+// textSerializer stands in for a format implemented in another package
+type textSerializer struct {
+	Serializer
+}
+
+func (textSerializer) ContentType() string { return "text/x-test" }
+
+// withProvider registers p and removes it again when the test ends
+func withProvider(t *testing.T, p Provider) error {
+	t.Helper()
+	err := RegisterProvider(p)
+	t.Cleanup(func() {
+		providers.Lock()
+		defer providers.Unlock()
+		if _, ok := providers.byFormat[p.Format]; !ok {
+			return
+		}
+		delete(providers.byFormat, p.Format)
+		delete(DefaultRegistry.serializers, p.Format)
+		DefaultRegistry.order = slices.DeleteFunc(DefaultRegistry.order, func(f Format) bool { return f == p.Format })
+		for ext, format := range extensionFormats {
+			if format == p.Format {
+				delete(extensionFormats, ext)
+			}
+		}
+	})
+	return err
+}
+
+func TestRegisterProvider(t *testing.T) {
+	err := withProvider(t, Provider{
+		Format:       "test-text",
+		Extensions:   []string{".TXT", ".text"},
+		Capabilities: Capabilities{Streaming: true},
+		New:          func() Serializer { return textSerializer{NewJSONSerializer(0)} },
+	})
+	if err != nil {
+		t.Fatalf("RegisterProvider failed: %v", err)
+	}
+
+	s, ok := DefaultRegistry.Get("test-text")
+	if !ok || s.ContentType() != "text/x-test" {
+		t.Fatalf("provider serializer not in DefaultRegistry: %v, %v", s, ok)
+	}
+	if format, ok := FormatForPath("notes.txt"); !ok || format != "test-text" {
+		t.Errorf("FormatForPath(notes.txt) = %q, %v", format, ok)
+	}
+
+	p, ok := LookupProvider("test-text")
+	if !ok {
+		t.Fatal("LookupProvider did not find the provider")
+	}
+	if p.ContentType != "text/x-test" || !p.Capabilities.Streaming || p.Capabilities.Binary {
+		t.Errorf("unexpected provider metadata: %+v", p)
+	}
+	if strings.Join(p.Extensions, ",") != ".txt,.text" {
+		t.Errorf("extensions not normalized: %v", p.Extensions)
+	}
+
+	found := false
+	for _, p := range Providers() {
+		found = found || p.Format == "test-text"
+	}
+	if !found {
+		t.Error("Providers does not list the provider")
+	}
+	if _, ok := LookupProvider(JSON); ok {
+		t.Error("built-in formats are not providers")
+	}
+}
+
+func TestRegisterProviderErrors(t *testing.T) {
+	newText := func() Serializer { return textSerializer{NewJSONSerializer(0)} }
+	if err := withProvider(t, Provider{Format: "test-first", Extensions: []string{".first"}, New: newText}); err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		name string
+		p    Provider
+		want string
+	}{
+		{"no format", Provider{New: newText}, "format name"},
+		{"no constructor", Provider{Format: "test-x"}, "New function"},
+		{"nil serializer", Provider{Format: "test-x", New: func() Serializer { return nil }}, "returned nil"},
+		{"built-in format", Provider{Format: JSON, New: newText}, "already registered"},
+		{"duplicate format", Provider{Format: "test-first", New: newText}, "already registered"},
+		{"built-in extension", Provider{Format: "test-x", Extensions: []string{".json"}, New: newText}, `used by "json"`},
+		{"duplicate extension", Provider{Format: "test-x", Extensions: []string{".FIRST"}, New: newText}, `used by "test-first"`},
+		{"invalid extension", Provider{Format: "test-x", Extensions: []string{"x"}, New: newText}, "invalid extension"},
+	}
+	for _, tc := range cases {
+		err := withProvider(t, tc.p)
+		if err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("%s: got %v, want error containing %q", tc.name, err, tc.want)
+		}
+	}
+	if _, ok := DefaultRegistry.Get("test-x"); ok {
+		t.Error("a failed registration left a serializer behind")
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("MustRegisterProvider did not panic")
+		}
+	}()
+	MustRegisterProvider(Provider{})
+}
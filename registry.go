@@ -5,5 +5,9 @@ var DefaultRegistry = func() *Registry {
 	r := NewRegistry()
 	r.Register(JSON, NewJSONSerializer(32*1024))
 	r.Register(Msgpack, NewMsgpackSerializer())
+	r.Register(CBOR, NewCBORSerializer())
+	r.Register(Protobuf, NewProtoSerializer())
+	r.Register(BSON, NewBSONSerializer())
+	r.Register(NDJSON, NewNDJSONSerializer(32*1024))
 	return r
 }()
@@ -0,0 +1,19 @@
+//go:build tinygo || nounsafe
+
+package serializer
+
+// stringToReadOnlyBytes copies s into a new []byte.
+// This is the fallback for builds without unsafe (TinyGo, or the nounsafe tag); the
+// result may be modified, but callers must not rely on that.
+func stringToReadOnlyBytes(s string) []byte {
+	if len(s) == 0 {
+		return nil
+	}
+	return []byte(s)
+}
+
+// bytesToString copies b into a new string.
+// This is the fallback for builds without unsafe, where strings cannot alias b.
+func bytesToString(b []byte) string {
+	return string(b)
+}
@@ -0,0 +1,238 @@
+package serializer
+
+import (
+	"encoding"
+	stdjson "encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+)
+
+// ErrMaxDepth is returned when a value nests arrays and objects deeper than
+// WithMaxDepth allows. The error returned is a *MaxDepthError, which matches it.
+var ErrMaxDepth = errors.New("maximum nesting depth exceeded")
+
+// MaxDepthError is returned when a value or its input nests arrays and objects
+// deeper than WithMaxDepth allows
+type MaxDepthError struct {
+	// Limit is the depth allowed, as set with WithMaxDepth
+	Limit int
+	// Offset is the position in the JSON input of the bracket that went past
+	// the limit, or -1 when it is not known, as when encoding
+	Offset int64
+}
+
+func (e *MaxDepthError) Error() string {
+	if e.Offset < 0 {
+		return fmt.Sprintf("%v (limit %d)", ErrMaxDepth, e.Limit)
+	}
+	return fmt.Sprintf("%v (limit %d) at offset %d", ErrMaxDepth, e.Limit, e.Offset)
+}
+
+// Is makes errors.Is(err, ErrMaxDepth) report true
+func (e *MaxDepthError) Is(target error) bool {
+	return target == ErrMaxDepth
+}
+
+// WithIndent makes the JSON serializer indent its output, with one copy of indent
+// per nesting level, as SerializeIndent does, for config files and debug
+// endpoints. NDJSON records stay on one line; binary formats ignore it.
+func WithIndent(indent string) Option {
+	return func(o *options) {
+		o.indent = indent
+	}
+}
+
+// WithIndentPrefix starts every line of indented JSON with prefix. It only has an
+// effect together with WithIndent.
+func WithIndentPrefix(prefix string) Option {
+	return func(o *options) {
+		o.indentPrefix = prefix
+	}
+}
+
+// WithSortedKeys writes map entries in increasing key order, so equal maps always
+// encode to the same bytes. The JSON (jsoniter and json/v2 backends; encoding/json
+// and the lite backend always sort), MessagePack and CBOR serializers support it.
+// Struct fields keep their declaration order.
+func WithSortedKeys() Option {
+	return func(o *options) {
+		o.sortMapKeys = true
+	}
+}
+
+// WithOmitEmpty overrides whether struct fields holding empty values are left out.
+// With true every field is treated as tagged omitempty; with false the JSON and
+// MessagePack serializers ignore WithOmitZero and WithMsgpackOmitEmpty, leaving
+// only fields tagged omitempty out. JSON support requires the jsoniter backend.
+func WithOmitEmpty(enabled bool) Option {
+	return func(o *options) {
+		o.omitEmpty = &enabled
+	}
+}
+
+// WithTimeLayout writes time.Time values as strings formatted with layout, such
+// as time.DateOnly or time.RFC1123. Decoding parses layout first and falls back to
+// RFC 3339. Only the jsoniter backend of the JSON serializer supports it; use
+// WithMsgpackTimeFormat for MessagePack.
+func WithTimeLayout(layout string) Option {
+	return func(o *options) {
+		o.jsonTimeLayout = layout
+	}
+}
+
+// WithStructTag names struct fields after the tag under key, such as "serializer"
+// for `serializer:"name,omitempty"`, so one tag serves every format instead of
+// separate json and msgpack tags. Its options (omitempty, "-", string for JSON)
+// apply as they would under json or msgpack, and fields without the tag keep
+// their Go name. MessagePack fields with a msgpack tag keep using it.
+//
+// The JSON serializer supports it with the jsoniter backend. CBOR has no setting
+// for the tag key and keeps reading cbor tags, then json tags; gob always names
+// fields by their Go name and leaves out zero values.
+func WithStructTag(key string) Option {
+	return func(o *options) {
+		o.jsonTagKey = key
+		o.msgpackFallbackTag = key
+	}
+}
+
+// WithMaxDepth makes serialization fail with a *MaxDepthError when a value nests
+// arrays and objects (slices, arrays, maps and structs) more than n levels deep,
+// which also stops pointer cycles from overflowing the stack. Values that marshal
+// themselves count as scalars. The JSON, NDJSON, MessagePack and CBOR serializers
+// support it; n <= 0 means no limit, the default.
+//
+// The JSON and CBOR serializers also limit the input they decode, so deeply
+// nested documents from untrusted sources fail before the decoder recurses into
+// them. JSON counts brackets as they are read. CBOR counts tags as levels as
+// well, and cannot be limited to fewer than 4 levels; without the option it
+// allows 32.
+func WithMaxDepth(n int) Option {
+	return func(o *options) {
+		o.maxDepth = n
+	}
+}
+
+// withCallOptions applies opts over a copy of o for a single call, binding a
+// logger given in opts to format
+func (o options) withCallOptions(opts []Option, format Format) options {
+	logger := o.logger
+	// Options append to slices; make sure they never write into o's backing arrays
+	o.jsonDiscriminators = slices.Clip(o.jsonDiscriminators)
+	for _, opt := range opts {
+		if opt != nil {
+			opt(&o)
+		}
+	}
+	if o.logger != logger {
+		o.bindLogger(format)
+	}
+	return o
+}
+
+// omitsEmpty resolves the omit-empty setting of a format against WithOmitEmpty
+func (o *options) omitsEmpty(formatDefault bool) bool {
+	if o.omitEmpty != nil {
+		return *o.omitEmpty
+	}
+	return formatDefault
+}
+
+// checkDepth returns a *MaxDepthError if v nests more than max levels deep. A
+// max <= 0 means no limit.
+func checkDepth(v any, max int) error {
+	if max <= 0 || withinDepth(reflect.ValueOf(v), max) {
+		return nil
+	}
+	return &MaxDepthError{Limit: max, Offset: -1}
+}
+
+// selfMarshalerTypes are the interfaces of values that encode themselves
+var selfMarshalerTypes = []reflect.Type{
+	reflect.TypeFor[stdjson.Marshaler](),
+	reflect.TypeFor[encoding.TextMarshaler](),
+	reflect.TypeFor[encoding.BinaryMarshaler](),
+}
+
+// marshalsItself reports whether t or *t encodes itself
+func marshalsItself(t reflect.Type) bool {
+	ptr := reflect.PointerTo(t)
+	for _, iface := range selfMarshalerTypes {
+		if t.Implements(iface) || ptr.Implements(iface) {
+			return true
+		}
+	}
+	return false
+}
+
+// withinDepth reports whether v nests at most remaining levels of arrays and objects
+func withinDepth(v reflect.Value, remaining int) bool {
+	v, ok := derefValue(v)
+	if !ok || marshalsItself(v.Type()) {
+		return true
+	}
+	switch v.Kind() {
+	case reflect.Struct:
+		return remaining > 0 && structWithinDepth(v, remaining-1)
+	case reflect.Map:
+		if v.IsNil() {
+			return true
+		}
+		if remaining == 0 {
+			return false
+		}
+		iter := v.MapRange()
+		for iter.Next() {
+			if !withinDepth(iter.Value(), remaining-1) {
+				return false
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		if v.Type().Elem().Kind() == reflect.Uint8 || (v.Kind() == reflect.Slice && v.IsNil()) {
+			return true
+		}
+		if remaining == 0 {
+			return false
+		}
+		for i := 0; i < v.Len(); i++ {
+			if !withinDepth(v.Index(i), remaining-1) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// structWithinDepth checks the fields of v, whose own level is already counted.
+// Embedded structs are flattened into v, as the encoders do.
+func structWithinDepth(v reflect.Value, remaining int) bool {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.Anonymous {
+			if embedded, ok := derefValue(v.Field(i)); ok && embedded.Kind() == reflect.Struct && !marshalsItself(embedded.Type()) {
+				if !structWithinDepth(embedded, remaining) {
+					return false
+				}
+				continue
+			}
+		}
+		if field.IsExported() && !withinDepth(v.Field(i), remaining) {
+			return false
+		}
+	}
+	return true
+}
+
+// derefValue follows pointers and interfaces, reporting false for nil ones
+func derefValue(v reflect.Value) (reflect.Value, bool) {
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return v, false
+		}
+		v = v.Elem()
+	}
+	return v, v.IsValid()
+}
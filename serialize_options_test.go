@@ -0,0 +1,290 @@
+package serializer
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"testing"
+	"testing/iotest"
+	"time"
+
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+type optionsRecord struct {
+	Name  string            `json:"name" msgpack:"name"`
+	Notes string            `json:"notes" msgpack:"notes"`
+	Tags  map[string]string `json:"tags,omitempty" msgpack:"tags,omitempty"`
+}
+
+type depthNode struct {
+	Name string
+	Next *depthNode
+}
+
+func TestSerializeWithOptionsIndent(t *testing.T) {
+	s := NewJSONSerializer(0).(*JSONSerializer)
+	v := map[string]int{"a": 1}
+
+	data, err := s.SerializeWithOptions(v, WithIndent("  "), WithIndentPrefix("//"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "{\n//  \"a\": 1\n//}\n"; string(data) != want {
+		t.Errorf("indented = %q, want %q", data, want)
+	}
+
+	// The options only apply to that call
+	if data, _ := s.Serialize(v); string(data) != "{\"a\":1}\n" {
+		t.Errorf("Serialize after the call = %q", data)
+	}
+
+	// NDJSON records stay on one line
+	data, err = NewNDJSONSerializer(0).(OptionsSerializer).SerializeWithOptions([]map[string]int{v, v}, WithIndent("  "))
+	if err != nil || string(data) != "{\"a\":1}\n{\"a\":1}\n" {
+		t.Errorf("NDJSON = %q, %v", data, err)
+	}
+}
+
+func TestSerializeWithOptionsSortedKeys(t *testing.T) {
+	m := make(map[string]int)
+	keys := make([]string, 32)
+	for i := range keys {
+		keys[i] = fmt.Sprintf("key%02d", 31-i)
+		m[keys[i]] = i
+	}
+	sort.Strings(keys)
+
+	data, err := NewJSONSerializer(0).(OptionsSerializer).SerializeWithOptions(m, WithSortedKeys())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 1; i < len(keys); i++ {
+		if bytes.Index(data, []byte(keys[i-1])) > bytes.Index(data, []byte(keys[i])) {
+			t.Fatalf("JSON keys out of order: %s", data)
+		}
+	}
+
+	data, err = NewMsgpackSerializer().(OptionsSerializer).SerializeWithOptions(m, WithSortedKeys())
+	if err != nil {
+		t.Fatal(err)
+	}
+	dec := msgpack.NewDecoder(bytes.NewReader(data))
+	n, err := dec.DecodeMapLen()
+	if err != nil || n != len(keys) {
+		t.Fatalf("DecodeMapLen = %d, %v", n, err)
+	}
+	for i := 0; i < n; i++ {
+		key, err := dec.DecodeString()
+		if err != nil || key != keys[i] {
+			t.Fatalf("msgpack key %d = %q, %v, want %q", i, key, err, keys[i])
+		}
+		if _, err := dec.DecodeInt(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Nested maps with non-string keys are sorted without changing what decodes
+	nested := map[int][]map[string]int{3: {m}, -1: {m, nil}, 200: nil}
+	data, err = SerializeWithOptions(NewMsgpackSerializer(), nested, WithSortedKeys())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got map[int][]map[string]int
+	if err := NewMsgpackSerializer().Deserialize(data, &got); err != nil || len(got) != 3 || len(got[-1]) != 2 || got[3][0]["key07"] != m["key07"] {
+		t.Errorf("sorted nested maps decoded to %v, %v", got, err)
+	}
+
+	cs := NewCBORSerializer().(OptionsSerializer)
+	first, err := cs.SerializeWithOptions(m, WithSortedKeys())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 5; i++ {
+		if data, _ := cs.SerializeWithOptions(m, WithSortedKeys()); !bytes.Equal(data, first) {
+			t.Fatal("sorted CBOR encodings differ")
+		}
+	}
+}
+
+func TestSerializeWithOptionsOmitEmpty(t *testing.T) {
+	v := optionsRecord{Name: "a"}
+
+	if js := NewJSONSerializer(0).(*JSONSerializer); js.Backend() == JSONBackendJSONIter {
+		data, err := js.SerializeWithOptions(v, WithOmitEmpty(true))
+		if err != nil || string(data) != "{\"name\":\"a\"}\n" {
+			t.Errorf("JSON WithOmitEmpty(true) = %q, %v", data, err)
+		}
+	}
+
+	s := NewMsgpackSerializer(WithMsgpackOmitEmpty()).(*MsgPackSerializer)
+	data, err := s.SerializeWithOptions(v, WithOmitEmpty(false))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got map[string]any
+	if err := s.Deserialize(data, &got); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := got["notes"]; !ok {
+		t.Errorf("WithOmitEmpty(false) left out notes: %v", got)
+	}
+	if _, ok := got["tags"]; ok {
+		t.Errorf("WithOmitEmpty(false) wrote a field tagged omitempty: %v", got)
+	}
+}
+
+func TestSerializeWithOptionsTimeLayout(t *testing.T) {
+	type event struct {
+		On time.Time `json:"on"`
+	}
+	s := NewJSONSerializer(0).(*JSONSerializer)
+	if s.Backend() != JSONBackendJSONIter {
+		t.Skip("WithTimeLayout needs the jsoniter backend")
+	}
+
+	day := time.Date(2024, 3, 9, 0, 0, 0, 0, time.UTC)
+	data, err := s.SerializeWithOptions(event{On: day}, WithTimeLayout(time.DateOnly))
+	if err != nil || string(data) != "{\"on\":\"2024-03-09\"}\n" {
+		t.Fatalf("SerializeWithOptions = %q, %v", data, err)
+	}
+
+	var got event
+	ds := NewJSONSerializer(0, WithTimeLayout(time.DateOnly))
+	if err := ds.Deserialize(data, &got); err != nil || !got.On.Equal(day) {
+		t.Errorf("Deserialize = %v, %v", got.On, err)
+	}
+	if err := ds.Deserialize([]byte(`{"on":"2024-03-09T10:00:00Z"}`), &got); err != nil || got.On.Hour() != 10 {
+		t.Errorf("RFC 3339 fallback = %v, %v", got.On, err)
+	}
+	if err := ds.Deserialize([]byte(`{"on":"March"}`), &got); err == nil {
+		t.Error("unparseable time was accepted")
+	}
+}
+
+func TestWithMaxDepth(t *testing.T) {
+	nested := &depthNode{Name: "a", Next: &depthNode{Name: "b", Next: &depthNode{Name: "c"}}}
+	cycle := &depthNode{Name: "loop"}
+	cycle.Next = cycle
+
+	for _, s := range []Serializer{NewJSONSerializer(0), NewNDJSONSerializer(0), NewMsgpackSerializer(), NewCBORSerializer()} {
+		name := s.ContentType()
+		if _, err := SerializeWithOptions(s, nested, WithMaxDepth(3)); err != nil {
+			t.Errorf("%s: value within the limit failed: %v", name, err)
+		}
+		if _, err := SerializeWithOptions(s, nested, WithMaxDepth(2)); !errors.Is(err, ErrMaxDepth) {
+			t.Errorf("%s: got %v, want ErrMaxDepth", name, err)
+		}
+		if _, err := SerializeWithOptions(s, cycle, WithMaxDepth(64)); !errors.Is(err, ErrMaxDepth) {
+			t.Errorf("%s: cycle got %v, want ErrMaxDepth", name, err)
+		}
+	}
+
+	// Times and byte slices are scalars, and the option applies to every call
+	s := NewMsgpackSerializer(WithMaxDepth(1))
+	if _, err := s.Serialize(map[string]any{"at": time.Now(), "raw": []byte("x")}); err != nil {
+		t.Errorf("scalar values counted as nesting: %v", err)
+	}
+	var buf bytes.Buffer
+	if err := s.SerializeTo(&buf, [][]int{{1}}); !errors.Is(err, ErrMaxDepth) {
+		t.Errorf("SerializeTo got %v, want ErrMaxDepth", err)
+	}
+}
+
+func TestWithMaxDepthDecode(t *testing.T) {
+	deep, err := NewJSONSerializer(0).Serialize(createNestedObject(1000))
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := NewJSONSerializer(0, WithMaxDepth(64))
+	var got any
+	err = s.Deserialize(deep, &got)
+	var depthErr *MaxDepthError
+	if !errors.As(err, &depthErr) || !errors.Is(err, ErrMaxDepth) || depthErr.Limit != 64 || deep[depthErr.Offset] != '{' {
+		t.Fatalf("Deserialize = %v, want a *MaxDepthError at a bracket", err)
+	}
+	if err := s.(StringDeserializer).DeserializeString(string(deep), &got); !errors.As(err, &depthErr) {
+		t.Errorf("DeserializeString = %v, want a *MaxDepthError", err)
+	}
+	if err := s.DeserializeFrom(iotest.OneByteReader(bytes.NewReader(deep)), &got); !errors.As(err, &depthErr) || deep[depthErr.Offset] != '{' {
+		t.Errorf("DeserializeFrom = %v, want a *MaxDepthError at a bracket", err)
+	}
+	if err := s.(StreamingSerializer).NewStreamDecoder(bytes.NewReader(deep)).Decode(&got); !errors.As(err, &depthErr) {
+		t.Errorf("stream Decode = %v, want a *MaxDepthError", err)
+	}
+
+	// Brackets in strings and comments are not nesting
+	shallow := NewJSONSerializer(0, WithMaxDepth(1), WithJSONComments())
+	input := "{\"a\": \"[[{{\\\"[[\"} // ]]{{[[\n"
+	if err := shallow.Deserialize([]byte(input), &got); err != nil {
+		t.Errorf("Deserialize(%q) = %v", input, err)
+	}
+	if err := shallow.DeserializeFrom(strings.NewReader(input), &got); err != nil {
+		t.Errorf("DeserializeFrom(%q) = %v", input, err)
+	}
+	if err := shallow.Deserialize([]byte(`[[1]]`), &got); !errors.Is(err, ErrMaxDepth) {
+		t.Errorf("Deserialize([[1]]) = %v, want ErrMaxDepth", err)
+	}
+
+	// CBOR counts the same levels, but cannot go below 4
+	nested := []any{[]any{[]any{[]any{[]any{[]any{"x"}}}}}}
+	data, err := NewCBORSerializer().Serialize(nested)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := NewCBORSerializer(WithMaxDepth(6)).Deserialize(data, &got); err != nil {
+		t.Errorf("CBOR within the limit: %v", err)
+	}
+	for _, limit := range []int{2, 5} {
+		cs := NewCBORSerializer(WithMaxDepth(limit))
+		err := cs.Deserialize(data, &got)
+		if !errors.As(err, &depthErr) || depthErr.Limit != max(limit, 4) {
+			t.Errorf("CBOR WithMaxDepth(%d) = %v, want a *MaxDepthError", limit, err)
+		}
+		if err := cs.DeserializeFrom(bytes.NewReader(data), &got); !errors.Is(err, ErrMaxDepth) {
+			t.Errorf("CBOR DeserializeFrom = %v, want ErrMaxDepth", err)
+		}
+	}
+}
+
+type taggedRecord struct {
+	ID      int    `serializer:"id"`
+	Name    string `serializer:"display_name,omitempty"`
+	Secret  string `serializer:"-"`
+	Comment string
+}
+
+func TestWithStructTag(t *testing.T) {
+	in := taggedRecord{ID: 4, Secret: "s", Comment: "c"}
+
+	js := NewJSONSerializer(0, WithStructTag("serializer")).(*JSONSerializer)
+	if js.Backend() == JSONBackendJSONIter {
+		data, err := js.Serialize(in)
+		if err != nil || string(data) != "{\"id\":4,\"Comment\":\"c\"}\n" {
+			t.Errorf("JSON = %q, %v", data, err)
+		}
+		var out taggedRecord
+		if err := js.Deserialize([]byte(`{"id":5,"display_name":"n","Secret":"x"}`), &out); err != nil || out.ID != 5 || out.Name != "n" || out.Secret != "" {
+			t.Errorf("JSON decode = %+v, %v", out, err)
+		}
+	}
+
+	ms := NewMsgpackSerializer(WithStructTag("serializer"))
+	data, err := ms.Serialize(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var fields map[string]any
+	if err := ms.Deserialize(data, &fields); err != nil {
+		t.Fatal(err)
+	}
+	if len(fields) != 2 || fields["id"] == nil || fields["Comment"] != "c" {
+		t.Errorf("MessagePack fields = %v", fields)
+	}
+	var out taggedRecord
+	if err := ms.Deserialize(data, &out); err != nil || out.ID != 4 || out.Comment != "c" || out.Secret != "" {
+		t.Errorf("MessagePack decode = %+v, %v", out, err)
+	}
+}
@@ -1,9 +1,13 @@
 package serializer
 
 import (
+	"errors"
 	"fmt"
 	"io"
 	"reflect"
+	"sort"
+
+	"github.com/MichaelAJay/go-serializer/internal/contenttype"
 )
 
 const (
@@ -36,12 +40,61 @@ type TypeInfo struct {
 	TypeName string
 }
 
+// newTypedTarget allocates a pointer to a zero value of typeInfo.Type to decode
+// into. For pointer types the element is allocated too, so it is filled in place.
+func newTypedTarget(typeInfo TypeInfo) (reflect.Value, error) {
+	if typeInfo.Type == nil {
+		return reflect.Value{}, errors.New("typeInfo.Type is nil")
+	}
+	target := reflect.New(typeInfo.Type)
+	if typeInfo.Type.Kind() == reflect.Ptr {
+		target.Elem().Set(reflect.New(typeInfo.Type.Elem()))
+	}
+	return target, nil
+}
+
 // StringDeserializer provides optimized string-to-object deserialization
 // Implementations can avoid string->[]byte allocation through unsafe conversion
 type StringDeserializer interface {
 	DeserializeString(data string, v any) error
 }
 
+// IndentSerializer provides human-readable output for text formats
+type IndentSerializer interface {
+	SerializeIndent(v any, prefix, indent string) ([]byte, error)
+	SerializeIndentTo(w io.Writer, v any, prefix, indent string) error
+}
+
+// PooledSerializer is implemented by serializers that can hand out their encoding
+// in a pooled buffer instead of copying it. The JSON, gob and MessagePack
+// serializers implement it.
+type PooledSerializer interface {
+	// SerializePooled encodes v into a pooled buffer. The caller must call
+	// Release on the returned PooledBuf once done with its bytes.
+	SerializePooled(v any) (*PooledBuf, error)
+
+	// DeserializeFromPooled decodes the bytes of pb into v without copying them.
+	// pb is not released.
+	DeserializeFromPooled(pb *PooledBuf, v any) error
+}
+
+// OptionsSerializer is implemented by serializers that accept options for a single
+// call. The JSON, NDJSON, MessagePack and CBOR serializers implement it.
+type OptionsSerializer interface {
+	// SerializeWithOptions is like Serialize with opts applied over the
+	// serializer's own options for this call only
+	SerializeWithOptions(v any, opts ...Option) ([]byte, error)
+}
+
+// SerializeWithOptions serializes v with s, applying opts for this call if s
+// implements OptionsSerializer. Other serializers ignore opts.
+func SerializeWithOptions(s Serializer, v any, opts ...Option) ([]byte, error) {
+	if ser, ok := s.(OptionsSerializer); ok {
+		return ser.SerializeWithOptions(v, opts...)
+	}
+	return s.Serialize(v)
+}
+
 // TypedSerializer extends Serializer with type-aware operations
 // This allows the serializer to know the exact target type for deserialization
 type TypedSerializer interface {
@@ -59,14 +112,25 @@ type TypedSerializer interface {
 type Format string
 
 const (
-	JSON    Format = "json"
-	Binary  Format = "binary"
-	Msgpack Format = "msgpack"
+	JSON     Format = "json"
+	Binary   Format = "binary"
+	Msgpack  Format = "msgpack"
+	CBOR     Format = "cbor"
+	Protobuf Format = "protobuf"
+	BSON     Format = "bson"
+	Avro     Format = "avro"
+	NDJSON   Format = "ndjson"
 )
 
+// ErrNotAcceptable is returned by Registry.Negotiate when no registered
+// serializer satisfies the Accept header
+var ErrNotAcceptable = errors.New("no registered serializer is acceptable")
+
 // Registry for managing serializers
 type Registry struct {
 	serializers map[Format]Serializer
+	// order holds the formats in the order they were first registered
+	order []Format
 }
 
 // NewRegistry creates a new serializer registry
@@ -78,6 +142,9 @@ func NewRegistry() *Registry {
 
 // Register adds a serializer to the registry
 func (r *Registry) Register(format Format, serializer Serializer) {
+	if _, ok := r.serializers[format]; !ok {
+		r.order = append(r.order, format)
+	}
 	r.serializers[format] = serializer
 }
 
@@ -87,6 +154,66 @@ func (r *Registry) Get(format Format) (Serializer, bool) {
 	return serializer, ok
 }
 
+// HasPooled reports whether the serializer registered for format implements
+// PooledSerializer
+func (r *Registry) HasPooled(format Format) bool {
+	_, ok := r.Pooled(format)
+	return ok
+}
+
+// Pooled retrieves the serializer registered for format as a PooledSerializer.
+// It reports false when no serializer is registered for format or the one that
+// is does not implement PooledSerializer.
+func (r *Registry) Pooled(format Format) (PooledSerializer, bool) {
+	ps, ok := r.serializers[format].(PooledSerializer)
+	return ps, ok
+}
+
+// GetByContentType retrieves the serializer whose content type matches ct.
+// Parameters such as charset and the case of ct are ignored. When several
+// serializers share a content type, the one registered first is returned.
+func (r *Registry) GetByContentType(ct string) (Serializer, bool) {
+	mediaType := contenttype.MediaType(ct)
+	if mediaType == "" {
+		return nil, false
+	}
+	for _, format := range r.order {
+		s := r.serializers[format]
+		if contenttype.MediaType(s.ContentType()) == mediaType {
+			return s, true
+		}
+	}
+	return nil, false
+}
+
+// Negotiate picks the serializer preferred by an HTTP Accept header, honouring
+// q-values and wildcards such as application/* and */*. Equal q-values go to the
+// format matched by the most specific range, then to the format registered
+// first, which an empty header also selects. It returns
+// ErrNotAcceptable when no registered content type is acceptable.
+func (r *Registry) Negotiate(accept string) (Serializer, Format, error) {
+	offered := make([]string, len(r.order))
+	for i, format := range r.order {
+		offered[i] = r.serializers[format].ContentType()
+	}
+	i, ok := contenttype.Negotiate(accept, offered)
+	if !ok {
+		return nil, "", fmt.Errorf("%w: %q", ErrNotAcceptable, accept)
+	}
+	format := r.order[i]
+	return r.serializers[format], format, nil
+}
+
+// Formats returns the registered formats in sorted order
+func (r *Registry) Formats() []Format {
+	formats := make([]Format, 0, len(r.serializers))
+	for format := range r.serializers {
+		formats = append(formats, format)
+	}
+	sort.Slice(formats, func(i, j int) bool { return formats[i] < formats[j] })
+	return formats
+}
+
 // New creates a new serializer instance
 func (r *Registry) New(format Format) (Serializer, error) {
 	serializer, ok := r.serializers[format]
@@ -99,8 +226,12 @@ func (r *Registry) New(format Format) (Serializer, error) {
 // RegisterDefaultSerializers registers all available serializers
 func RegisterDefaultSerializers() {
 	DefaultRegistry.Register(JSON, NewJSONSerializer(maxBufferSize))
-	DefaultRegistry.Register(Binary, NewGobSerializer())
+	registerDefaultGob()
 	DefaultRegistry.Register(Msgpack, NewMsgpackSerializer())
+	DefaultRegistry.Register(CBOR, NewCBORSerializer())
+	DefaultRegistry.Register(Protobuf, NewProtoSerializer())
+	DefaultRegistry.Register(BSON, NewBSONSerializer())
+	DefaultRegistry.Register(NDJSON, NewNDJSONSerializer(maxBufferSize))
 }
 
 // Initialize default serializers
@@ -2,6 +2,7 @@ package serializer_test
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"reflect"
 	"testing"
@@ -118,14 +119,24 @@ var testCases = []struct {
 	},
 }
 
-// testSerializers contains all serializer implementations to test
-var testSerializers = []struct {
+// namedSerializer is a serializer under test with the name its subtests use
+type namedSerializer struct {
 	name       string
 	serializer serializer.Serializer
-}{
+}
+
+// testSerializers contains all serializer implementations to test
+var testSerializers = withGob([]namedSerializer{
 	{"JSON", serializer.NewJSONSerializer(maxBufferSize)},
-	{"Gob", serializer.NewGobSerializer()},
 	{"MsgPack", serializer.NewMsgpackSerializer()},
+})
+
+// withGob appends the gob serializers to serializers under the name "Gob"
+func withGob(serializers []namedSerializer) []namedSerializer {
+	for _, s := range gobSerializers() {
+		serializers = append(serializers, namedSerializer{"Gob", s})
+	}
+	return serializers
 }
 
 func TestSerialization(t *testing.T) {
@@ -337,6 +348,85 @@ func TestRegistry(t *testing.T) {
 	}
 }
 
+func TestRegistryHasPooled(t *testing.T) {
+	registry := serializer.NewRegistry()
+	registry.Register(serializer.JSON, serializer.NewJSONSerializer(maxBufferSize))
+	registry.Register(serializer.Msgpack, serializer.NewMsgpackSerializer())
+	registry.Register(serializer.CBOR, serializer.NewCBORSerializer())
+	pooled := []serializer.Format{serializer.JSON, serializer.Msgpack}
+	for _, s := range gobSerializers() {
+		registry.Register(serializer.Binary, s)
+		pooled = append(pooled, serializer.Binary)
+	}
+
+	for _, format := range pooled {
+		if !registry.HasPooled(format) {
+			t.Errorf("HasPooled(%s) = false, want true", format)
+		}
+		ps, ok := registry.Pooled(format)
+		if !ok {
+			t.Fatalf("Pooled(%s) reported false", format)
+		}
+		pb, err := ps.SerializePooled(map[string]int{"a": 1})
+		if err != nil {
+			t.Fatalf("SerializePooled(%s) failed: %v", format, err)
+		}
+		pb.Release()
+	}
+
+	for _, format := range []serializer.Format{serializer.CBOR, "nonexistent"} {
+		if registry.HasPooled(format) {
+			t.Errorf("HasPooled(%s) = true, want false", format)
+		}
+		if ps, ok := registry.Pooled(format); ok || ps != nil {
+			t.Errorf("Pooled(%s) = %v, %v", format, ps, ok)
+		}
+	}
+}
+
+func TestRegistryNegotiate(t *testing.T) {
+	registry := serializer.NewRegistry()
+	registry.Register(serializer.JSON, serializer.NewJSONSerializer(0))
+	registry.Register(serializer.Msgpack, serializer.NewMsgpackSerializer())
+	registry.Register(serializer.CBOR, serializer.NewCBORSerializer())
+
+	if s, ok := registry.GetByContentType("Application/CBOR; charset=binary"); !ok || s.ContentType() != "application/cbor" {
+		t.Errorf("GetByContentType(cbor) = %v, %v", s, ok)
+	}
+	for _, ct := range []string{"", "text/html"} {
+		if _, ok := registry.GetByContentType(ct); ok {
+			t.Errorf("GetByContentType(%q) found a serializer", ct)
+		}
+	}
+
+	tests := []struct {
+		accept string
+		want   serializer.Format
+	}{
+		{"", serializer.JSON},
+		{"*/*", serializer.JSON},
+		{"application/x-msgpack", serializer.Msgpack},
+		{"application/json;q=0.5, application/cbor", serializer.CBOR},
+		{"application/*;q=0.2, application/x-msgpack;q=0.9", serializer.Msgpack},
+		{"text/html, application/*;q=0.1", serializer.JSON},
+		{"application/json;q=0, */*", serializer.Msgpack},
+	}
+	for _, tt := range tests {
+		s, format, err := registry.Negotiate(tt.accept)
+		if err != nil || format != tt.want {
+			t.Errorf("Negotiate(%q) = %s, %v; want %s", tt.accept, format, err, tt.want)
+			continue
+		}
+		if got, _ := registry.Get(format); got != s {
+			t.Errorf("Negotiate(%q) returned another format's serializer", tt.accept)
+		}
+	}
+
+	if _, _, err := registry.Negotiate("text/html"); !errors.Is(err, serializer.ErrNotAcceptable) {
+		t.Errorf("Negotiate(text/html): got %v, want ErrNotAcceptable", err)
+	}
+}
+
 // Helper functions for comparing values
 func compareValues(expected, got any) bool {
 	if expected == nil && got == nil {
@@ -805,3 +895,51 @@ func TestStringDeserializerComplexStruct(t *testing.T) {
 		})
 	}
 }
+
+func TestTypedSerializer(t *testing.T) {
+	type record struct {
+		Name  string
+		Count int
+		Tags  []string
+	}
+	serializers := map[string]serializer.Serializer{
+		"json":    serializer.NewJSONSerializer(1024),
+		"msgpack": serializer.NewMsgpackSerializer(),
+	}
+	for _, s := range gobSerializers() {
+		serializers["gob"] = s
+	}
+	for name, s := range serializers {
+		t.Run(name, func(t *testing.T) {
+			ts, ok := s.(serializer.TypedSerializer)
+			if !ok {
+				t.Fatal("expected a TypedSerializer")
+			}
+			in := record{Name: "a", Count: 2, Tags: []string{"x"}}
+
+			data, err := ts.SerializeWithTypeInfo(in, serializer.TypeInfo{Type: reflect.TypeOf(record{}), TypeName: "record"})
+			if err != nil {
+				t.Fatalf("SerializeWithTypeInfo failed: %v", err)
+			}
+			got, err := ts.DeserializeWithTypeInfo(data, serializer.TypeInfo{Type: reflect.TypeOf(record{}), TypeName: "record"})
+			if err != nil {
+				t.Fatalf("DeserializeWithTypeInfo failed: %v", err)
+			}
+			if !reflect.DeepEqual(got, in) {
+				t.Errorf("expected %#v, got %#v", in, got)
+			}
+
+			ptr, err := ts.DeserializeWithTypeInfo(data, serializer.TypeInfo{Type: reflect.TypeOf(&record{}), TypeName: "*record"})
+			if err != nil {
+				t.Fatalf("DeserializeWithTypeInfo failed for a pointer type: %v", err)
+			}
+			if p, ok := ptr.(*record); !ok || !reflect.DeepEqual(*p, in) {
+				t.Errorf("expected *record, got %#v", ptr)
+			}
+
+			if _, err := ts.DeserializeWithTypeInfo(data, serializer.TypeInfo{}); err == nil {
+				t.Error("expected an error for a missing type")
+			}
+		})
+	}
+}
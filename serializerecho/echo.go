@@ -0,0 +1,71 @@
+// Package serializerecho integrates a serializer.Registry with echo, so
+// handlers can bind and render msgpack, gob or any registered format in
+// addition to echo's built-in JSON support.
+//
+//	e := echo.New()
+//	e.Binder = &serializerecho.Binder{Registry: registry}
+//
+//	e.GET("/users/:id", func(c echo.Context) error {
+//		return serializerecho.Render(c, registry, http.StatusOK, user)
+//	})
+package serializerecho
+
+import (
+	"net/http"
+
+	"github.com/MichaelAJay/go-serializer"
+	"github.com/labstack/echo/v4"
+)
+
+// Binder binds request bodies whose Content-Type has a registered serializer
+// and delegates everything else to Fallback (echo.DefaultBinder when nil).
+// Path parameters are bound before the body, as echo.DefaultBinder does.
+type Binder struct {
+	Registry *serializer.Registry
+	Fallback echo.Binder
+}
+
+var _ echo.Binder = (*Binder)(nil)
+
+// Bind implements echo.Binder
+func (b *Binder) Bind(i any, c echo.Context) error {
+	req := c.Request()
+	s, ok := b.Registry.GetByContentType(req.Header.Get(echo.HeaderContentType))
+	if !ok || req.ContentLength == 0 {
+		return b.fallback().Bind(i, c)
+	}
+
+	if err := (&echo.DefaultBinder{}).BindPathParams(c, i); err != nil {
+		return err
+	}
+	if err := s.DeserializeFrom(req.Body, i); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error()).SetInternal(err)
+	}
+	return nil
+}
+
+func (b *Binder) fallback() echo.Binder {
+	if b.Fallback != nil {
+		return b.Fallback
+	}
+	return &echo.DefaultBinder{}
+}
+
+// Render writes v with the registered serializer preferred by the request's Accept header.
+// It returns a 406 HTTP error when no registered format is acceptable.
+func Render(c echo.Context, registry *serializer.Registry, code int, v any) error {
+	s, _, err := registry.Negotiate(c.Request().Header.Get(echo.HeaderAccept))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusNotAcceptable).SetInternal(err)
+	}
+	return Write(c, s, code, v)
+}
+
+// Write serializes v with s and writes it as the response body
+func Write(c echo.Context, s serializer.Serializer, code int, v any) error {
+	data, err := s.Serialize(v)
+	if err != nil {
+		return err
+	}
+	return c.Blob(code, s.ContentType(), data)
+}
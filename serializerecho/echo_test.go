@@ -0,0 +1,84 @@
+package serializerecho
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/MichaelAJay/go-serializer"
+	"github.com/labstack/echo/v4"
+)
+
+type user struct {
+	ID   string `param:"id" json:"-" msgpack:"-"`
+	Name string `json:"name" msgpack:"name"`
+}
+
+func newRegistry() *serializer.Registry {
+	r := serializer.NewRegistry()
+	r.Register(serializer.JSON, serializer.NewJSONSerializer(32*1024))
+	r.Register(serializer.Msgpack, serializer.NewMsgpackSerializer())
+	return r
+}
+
+func TestBinderUsesRegisteredSerializer(t *testing.T) {
+	e := echo.New()
+	body, _ := serializer.NewMsgpackSerializer().Serialize(user{Name: "ada"})
+
+	req := httptest.NewRequest(http.MethodPost, "/users/42", bytes.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, "application/x-msgpack")
+	c := e.NewContext(req, httptest.NewRecorder())
+	c.SetParamNames("id")
+	c.SetParamValues("42")
+
+	var got user
+	if err := (&Binder{Registry: newRegistry()}).Bind(&got, c); err != nil {
+		t.Fatalf("Bind failed: %v", err)
+	}
+	if got.Name != "ada" || got.ID != "42" {
+		t.Errorf("unexpected bound value: %+v", got)
+	}
+}
+
+func TestBinderFallsBack(t *testing.T) {
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader("name=grace"))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationForm)
+	c := e.NewContext(req, httptest.NewRecorder())
+
+	var got struct {
+		Name string `form:"name"`
+	}
+	if err := (&Binder{Registry: newRegistry()}).Bind(&got, c); err != nil {
+		t.Fatalf("Bind failed: %v", err)
+	}
+	if got.Name != "grace" {
+		t.Errorf("fallback binder not used: %+v", got)
+	}
+}
+
+func TestRenderNegotiates(t *testing.T) {
+	e := echo.New()
+	registry := newRegistry()
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set(echo.HeaderAccept, "application/x-msgpack")
+	rec := httptest.NewRecorder()
+	if err := Render(e.NewContext(req, rec), registry, http.StatusOK, user{Name: "linus"}); err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	if ct := rec.Header().Get(echo.HeaderContentType); ct != "application/x-msgpack" {
+		t.Errorf("unexpected content type %q", ct)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set(echo.HeaderAccept, "text/html")
+	err := Render(e.NewContext(req, httptest.NewRecorder()), registry, http.StatusOK, user{})
+	var httpErr *echo.HTTPError
+	if !errors.As(err, &httpErr) || httpErr.Code != http.StatusNotAcceptable {
+		t.Errorf("expected 406 error, got %v", err)
+	}
+}
@@ -0,0 +1,125 @@
+// Package serializergin lets gin negotiate and bind request and response bodies
+// through a serializer.Registry, so handlers can serve msgpack, gob or any
+// registered format instead of JSON only.
+//
+// The types implement gin's render.Render and binding.Binding/BindingBody
+// interfaces:
+//
+//	r, err := serializergin.Negotiate(registry, c.GetHeader("Accept"), data)
+//	if err != nil {
+//		c.AbortWithStatus(http.StatusNotAcceptable)
+//		return
+//	}
+//	c.Render(http.StatusOK, r)
+//
+//	var req CreateUser
+//	err := c.ShouldBindWith(&req, serializergin.Binding{Registry: registry})
+package serializergin
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/MichaelAJay/go-serializer"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/gin-gonic/gin/render"
+)
+
+// ErrNotAcceptable is returned when no registered serializer satisfies the Accept header
+var ErrNotAcceptable = serializer.ErrNotAcceptable
+
+// ErrUnsupportedMediaType is returned when the request Content-Type has no registered serializer
+var ErrUnsupportedMediaType = errors.New("unsupported request content type")
+
+// Render writes Data using Serializer. It implements gin's render.Render.
+type Render struct {
+	Serializer serializer.Serializer
+	Data       any
+}
+
+var _ render.Render = Render{}
+
+// Render writes the serialized data to the response
+func (r Render) Render(w http.ResponseWriter) error {
+	r.WriteContentType(w)
+	return r.Serializer.SerializeTo(w, r.Data)
+}
+
+// WriteContentType sets the Content-Type header unless the handler already set one
+func (r Render) WriteContentType(w http.ResponseWriter) {
+	header := w.Header()
+	if header.Get("Content-Type") == "" {
+		header.Set("Content-Type", r.Serializer.ContentType())
+	}
+}
+
+// Negotiate picks the registered serializer preferred by the Accept header and
+// returns a Render for data. It returns ErrNotAcceptable when none matches.
+func Negotiate(registry *serializer.Registry, accept string, data any) (Render, error) {
+	s, _, err := registry.Negotiate(accept)
+	if err != nil {
+		return Render{}, err
+	}
+	return Render{Serializer: s, Data: data}, nil
+}
+
+// Binding decodes request bodies with the registered serializer matching the
+// request Content-Type. It implements gin's binding.Binding.
+type Binding struct {
+	Registry *serializer.Registry
+}
+
+var _ binding.Binding = Binding{}
+
+// Name implements binding.Binding
+func (Binding) Name() string {
+	return "serializer"
+}
+
+// Bind decodes the request body into obj
+func (b Binding) Bind(req *http.Request, obj any) error {
+	if req == nil || req.Body == nil {
+		return errors.New("invalid request")
+	}
+	s, err := serializerForContentType(b.Registry, req.Header.Get("Content-Type"))
+	if err != nil {
+		return err
+	}
+	return s.DeserializeFrom(req.Body, obj)
+}
+
+// BodyBinding decodes request bodies with a fixed serializer.
+// It implements gin's binding.Binding and binding.BindingBody, so it also works
+// with ShouldBindBodyWith.
+type BodyBinding struct {
+	Serializer serializer.Serializer
+}
+
+var _ binding.BindingBody = BodyBinding{}
+
+// Name implements binding.Binding
+func (b BodyBinding) Name() string {
+	return b.Serializer.ContentType()
+}
+
+// Bind decodes the request body into obj
+func (b BodyBinding) Bind(req *http.Request, obj any) error {
+	if req == nil || req.Body == nil {
+		return errors.New("invalid request")
+	}
+	return b.Serializer.DeserializeFrom(req.Body, obj)
+}
+
+// BindBody decodes an already read body into obj
+func (b BodyBinding) BindBody(body []byte, obj any) error {
+	return b.Serializer.Deserialize(body, obj)
+}
+
+// serializerForContentType finds the registered serializer for a request Content-Type
+func serializerForContentType(registry *serializer.Registry, ct string) (serializer.Serializer, error) {
+	if s, ok := registry.GetByContentType(ct); ok {
+		return s, nil
+	}
+	return nil, fmt.Errorf("%w: %q", ErrUnsupportedMediaType, ct)
+}
@@ -0,0 +1,81 @@
+package serializergin
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/MichaelAJay/go-serializer"
+)
+
+type user struct {
+	Name string `json:"name" msgpack:"name"`
+}
+
+func newRegistry() *serializer.Registry {
+	r := serializer.NewRegistry()
+	r.Register(serializer.JSON, serializer.NewJSONSerializer(32*1024))
+	r.Register(serializer.Msgpack, serializer.NewMsgpackSerializer())
+	return r
+}
+
+func TestNegotiateRender(t *testing.T) {
+	registry := newRegistry()
+
+	r, err := Negotiate(registry, "application/x-msgpack, application/json;q=0.5", user{Name: "ada"})
+	if err != nil {
+		t.Fatalf("Negotiate failed: %v", err)
+	}
+
+	w := httptest.NewRecorder()
+	if err := r.Render(w); err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	if ct := w.Header().Get("Content-Type"); ct != "application/x-msgpack" {
+		t.Errorf("expected msgpack content type, got %q", ct)
+	}
+
+	var got user
+	if err := serializer.NewMsgpackSerializer().Deserialize(w.Body.Bytes(), &got); err != nil || got.Name != "ada" {
+		t.Errorf("unexpected body: %+v, %v", got, err)
+	}
+
+	if _, err := Negotiate(registry, "text/html", nil); !errors.Is(err, ErrNotAcceptable) {
+		t.Errorf("expected ErrNotAcceptable, got %v", err)
+	}
+}
+
+func TestBinding(t *testing.T) {
+	registry := newRegistry()
+	body, _ := serializer.NewMsgpackSerializer().Serialize(user{Name: "grace"})
+
+	req := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/x-msgpack")
+
+	var got user
+	if err := (Binding{Registry: registry}).Bind(req, &got); err != nil {
+		t.Fatalf("Bind failed: %v", err)
+	}
+	if got.Name != "grace" {
+		t.Errorf("unexpected bound value: %+v", got)
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/xml")
+	if err := (Binding{Registry: registry}).Bind(req, &got); !errors.Is(err, ErrUnsupportedMediaType) {
+		t.Errorf("expected ErrUnsupportedMediaType, got %v", err)
+	}
+}
+
+func TestBodyBinding(t *testing.T) {
+	b := BodyBinding{Serializer: serializer.NewJSONSerializer(32 * 1024)}
+	var got user
+	if err := b.BindBody([]byte(`{"name":"linus"}`), &got); err != nil || got.Name != "linus" {
+		t.Errorf("BindBody = %+v, %v", got, err)
+	}
+	if b.Name() != "application/json" {
+		t.Errorf("unexpected binding name %q", b.Name())
+	}
+}
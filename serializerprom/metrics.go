@@ -0,0 +1,161 @@
+// Package serializerprom exports Prometheus metrics for go-serializer.
+//
+// Wrap a serializer to count operations, bytes, durations and errors per format,
+// and watch serializers that pool buffers to export their pool activity:
+//
+//	m, err := serializerprom.NewMetrics(prometheus.DefaultRegisterer, "myapp")
+//	s := m.Wrap(serializer.JSON, serializer.NewJSONSerializer(32*1024))
+//
+// Metrics also counts pool events as they happen when passed to WithPoolMetrics:
+//
+//	s := serializer.NewMsgpackSerializer(serializer.WithPoolMetrics(m))
+package serializerprom
+
+import (
+	"sync"
+	"time"
+
+	"github.com/MichaelAJay/go-serializer"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Metrics holds the collectors shared by every wrapped serializer
+type Metrics struct {
+	operations *prometheus.CounterVec
+	bytes      *prometheus.CounterVec
+	duration   *prometheus.HistogramVec
+	poolEvents *prometheus.CounterVec
+	pools      *poolCollector
+}
+
+var (
+	_ serializer.OperationObserver = (*Metrics)(nil)
+	_ serializer.PoolMetrics       = (*Metrics)(nil)
+)
+
+// NewMetrics creates the serializer collectors and registers them on reg.
+// namespace prefixes every metric name and may be empty.
+func NewMetrics(reg prometheus.Registerer, namespace string) (*Metrics, error) {
+	m := &Metrics{
+		operations: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "serializer",
+			Name:      "operations_total",
+			Help:      "Serialization operations by format, operation and result.",
+		}, []string{"format", "op", "result"}),
+		bytes: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "serializer",
+			Name:      "bytes_total",
+			Help:      "Bytes produced by serialization or consumed by deserialization.",
+		}, []string{"format", "op"}),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "serializer",
+			Name:      "duration_seconds",
+			Help:      "Duration of serialization operations.",
+			Buckets:   prometheus.ExponentialBuckets(1e-6, 4, 10),
+		}, []string{"format", "op"}),
+		poolEvents: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "serializer_pool",
+			Name:      "events_total",
+			Help:      "Pool events reported through serializer.WithPoolMetrics, by pool and event.",
+		}, []string{"pool", "event"}),
+		pools: newPoolCollector(namespace),
+	}
+
+	for _, c := range []prometheus.Collector{m.operations, m.bytes, m.duration, m.poolEvents, m.pools} {
+		if err := reg.Register(c); err != nil {
+			return nil, err
+		}
+	}
+	return m, nil
+}
+
+// Wrap returns a serializer that records metrics for every call to s under the given format label.
+// If s pools buffers, its pool activity is exported as well.
+func (m *Metrics) Wrap(format serializer.Format, s serializer.Serializer) serializer.Serializer {
+	if provider, ok := s.(serializer.PoolStatsProvider); ok {
+		m.WatchPool(format, provider)
+	}
+	return serializer.Observe(s, format, m)
+}
+
+// WatchPool exports the pool statistics of provider under the given format label.
+// Watching the same format twice replaces the previous provider.
+func (m *Metrics) WatchPool(format serializer.Format, provider serializer.PoolStatsProvider) {
+	m.pools.watch(string(format), provider)
+}
+
+// ObserveOperation implements serializer.OperationObserver
+func (m *Metrics) ObserveOperation(format serializer.Format, op string, bytes int, d time.Duration, err error) {
+	result := "success"
+	if err != nil {
+		result = "error"
+	}
+	m.operations.WithLabelValues(string(format), op, result).Inc()
+	m.duration.WithLabelValues(string(format), op).Observe(d.Seconds())
+	if bytes > 0 {
+		m.bytes.WithLabelValues(string(format), op).Add(float64(bytes))
+	}
+}
+
+// ObservePool implements serializer.PoolMetrics, so m can be passed to
+// serializer.WithPoolMetrics to count pool events as they happen
+func (m *Metrics) ObservePool(pool string, event serializer.PoolEvent, capacity int) {
+	m.poolEvents.WithLabelValues(pool, event.String()).Inc()
+}
+
+// poolCollector exports pool statistics gathered at scrape time
+type poolCollector struct {
+	gets     *prometheus.Desc
+	hits     *prometheus.Desc
+	misses   *prometheus.Desc
+	discards *prometheus.Desc
+	inUse    *prometheus.Desc
+
+	mu        sync.RWMutex
+	providers map[string]serializer.PoolStatsProvider
+}
+
+func newPoolCollector(namespace string) *poolCollector {
+	desc := func(name, help string) *prometheus.Desc {
+		return prometheus.NewDesc(prometheus.BuildFQName(namespace, "serializer_pool", name), help, []string{"format"}, nil)
+	}
+	return &poolCollector{
+		gets:      desc("gets_total", "Buffers taken from the pool."),
+		hits:      desc("hits_total", "Pool gets served by a reused buffer."),
+		misses:    desc("misses_total", "Pool gets that allocated a new buffer."),
+		discards:  desc("discards_total", "Buffers dropped from the pool for exceeding the size limit."),
+		inUse:     desc("in_use", "Buffers taken from the pool and not yet returned."),
+		providers: make(map[string]serializer.PoolStatsProvider),
+	}
+}
+
+func (c *poolCollector) watch(format string, provider serializer.PoolStatsProvider) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.providers[format] = provider
+}
+
+func (c *poolCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.gets
+	ch <- c.hits
+	ch <- c.misses
+	ch <- c.discards
+	ch <- c.inUse
+}
+
+func (c *poolCollector) Collect(ch chan<- prometheus.Metric) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	for format, provider := range c.providers {
+		stats := provider.PoolStats()
+		ch <- prometheus.MustNewConstMetric(c.gets, prometheus.CounterValue, float64(stats.Gets), format)
+		ch <- prometheus.MustNewConstMetric(c.hits, prometheus.CounterValue, float64(stats.Hits()), format)
+		ch <- prometheus.MustNewConstMetric(c.misses, prometheus.CounterValue, float64(stats.Misses), format)
+		ch <- prometheus.MustNewConstMetric(c.discards, prometheus.CounterValue, float64(stats.Discards), format)
+		ch <- prometheus.MustNewConstMetric(c.inUse, prometheus.GaugeValue, float64(stats.InUse), format)
+	}
+}
@@ -0,0 +1,115 @@
+package serializerprom
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/MichaelAJay/go-serializer"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestWrapRecordsOperations(t *testing.T) {
+	reg := prometheus.NewPedanticRegistry()
+	m, err := NewMetrics(reg, "test")
+	if err != nil {
+		t.Fatalf("NewMetrics failed: %v", err)
+	}
+	s := m.Wrap(serializer.JSON, serializer.NewJSONSerializer(32*1024))
+
+	data, err := s.Serialize(map[string]int{"a": 1})
+	if err != nil {
+		t.Fatalf("Serialize failed: %v", err)
+	}
+	var out map[string]int
+	if err := s.Deserialize(data, &out); err != nil {
+		t.Fatalf("Deserialize failed: %v", err)
+	}
+	if err := s.Deserialize([]byte("{"), &out); err == nil {
+		t.Fatal("expected deserialize error")
+	}
+	var buf bytes.Buffer
+	if err := s.SerializeTo(&buf, "x"); err != nil {
+		t.Fatalf("SerializeTo failed: %v", err)
+	}
+
+	if got := testutil.ToFloat64(m.operations.WithLabelValues("json", "deserialize", "error")); got != 1 {
+		t.Errorf("expected 1 deserialize error, got %v", got)
+	}
+	if got := testutil.ToFloat64(m.operations.WithLabelValues("json", "deserialize", "success")); got != 1 {
+		t.Errorf("expected 1 deserialize success, got %v", got)
+	}
+	if got := testutil.ToFloat64(m.bytes.WithLabelValues("json", "serialize")); got != float64(len(data)) {
+		t.Errorf("expected %d serialized bytes, got %v", len(data), got)
+	}
+	if got := testutil.ToFloat64(m.bytes.WithLabelValues("json", "serialize_to")); got != float64(buf.Len()) {
+		t.Errorf("expected %d streamed bytes, got %v", buf.Len(), got)
+	}
+}
+
+func TestWrapExportsPoolStats(t *testing.T) {
+	reg := prometheus.NewPedanticRegistry()
+	m, err := NewMetrics(reg, "")
+	if err != nil {
+		t.Fatalf("NewMetrics failed: %v", err)
+	}
+	s := m.Wrap(serializer.JSON, serializer.NewJSONSerializer(16))
+
+	if _, err := s.Serialize(strings.Repeat("x", 100)); err != nil {
+		t.Fatalf("Serialize failed: %v", err)
+	}
+
+	expected := `
+# HELP serializer_pool_discards_total Buffers dropped from the pool for exceeding the size limit.
+# TYPE serializer_pool_discards_total counter
+serializer_pool_discards_total{format="json"} 1
+`
+	if err := testutil.GatherAndCompare(reg, strings.NewReader(expected), "serializer_pool_discards_total"); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestWrapPreservesStringDeserializer(t *testing.T) {
+	m, err := NewMetrics(prometheus.NewRegistry(), "")
+	if err != nil {
+		t.Fatalf("NewMetrics failed: %v", err)
+	}
+	s := m.Wrap(serializer.Msgpack, serializer.NewMsgpackSerializer())
+
+	data, _ := s.Serialize("hello")
+	sd, ok := s.(serializer.StringDeserializer)
+	if !ok {
+		t.Fatal("wrapped serializer does not implement StringDeserializer")
+	}
+	var out string
+	if err := sd.DeserializeString(string(data), &out); err != nil || out != "hello" {
+		t.Errorf("DeserializeString = %q, %v", out, err)
+	}
+}
+
+func TestPoolMetricsCountsEvents(t *testing.T) {
+	reg := prometheus.NewPedanticRegistry()
+	m, err := NewMetrics(reg, "")
+	if err != nil {
+		t.Fatalf("NewMetrics failed: %v", err)
+	}
+	s := serializer.NewJSONSerializer(16, serializer.WithPoolMetrics(m))
+	m.WatchPool(serializer.JSON, s.(serializer.PoolStatsProvider))
+
+	if _, err := s.Serialize(strings.Repeat("x", 100)); err != nil {
+		t.Fatalf("Serialize failed: %v", err)
+	}
+
+	if got := testutil.ToFloat64(m.poolEvents.WithLabelValues("json_buffer", "discard")); got != 1 {
+		t.Errorf("expected 1 discard event, got %v", got)
+	}
+	expected := `
+# HELP serializer_pool_in_use Buffers taken from the pool and not yet returned.
+# TYPE serializer_pool_in_use gauge
+serializer_pool_in_use{format="json"} 0
+`
+	if err := testutil.GatherAndCompare(reg, strings.NewReader(expected), "serializer_pool_in_use"); err != nil {
+		t.Error(err)
+	}
+}
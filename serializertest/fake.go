@@ -0,0 +1,344 @@
+package serializertest
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/MichaelAJay/go-serializer"
+)
+
+// Method names a serializer method for FakeSerializer failure injection and
+// call recording
+type Method string
+
+const (
+	MethodSerialize               Method = "Serialize"
+	MethodDeserialize             Method = "Deserialize"
+	MethodSerializeTo             Method = "SerializeTo"
+	MethodDeserializeFrom         Method = "DeserializeFrom"
+	MethodDeserializeString       Method = "DeserializeString"
+	MethodSerializeIndent         Method = "SerializeIndent"
+	MethodSerializeIndentTo       Method = "SerializeIndentTo"
+	MethodSerializeWithTypeInfo   Method = "SerializeWithTypeInfo"
+	MethodDeserializeWithTypeInfo Method = "DeserializeWithTypeInfo"
+)
+
+// Call records one call made to a FakeSerializer
+type Call struct {
+	Method Method
+	// Value is the value serialized or the target deserialized into. For
+	// DeserializeWithTypeInfo it is the decoded value.
+	Value any
+	// Data is the output of serializing calls and the input of deserializing
+	// ones. For DeserializeFrom it holds what the delegate read from the stream.
+	Data []byte
+	Err  error
+}
+
+// FakeSerializer is a Serializer for unit tests of code that uses one. It passes
+// calls to a delegate, a JSON serializer unless WithDelegate replaces it, after
+// optionally sleeping and injecting failures, and records every call.
+//
+// It implements StringDeserializer, IndentSerializer, TypedSerializer and
+// PoolStatsProvider whether or not the delegate does, falling back on the
+// delegate's Serializer methods, so code that checks for them takes the same
+// path it does with the built-in serializers. It is safe for concurrent use.
+//
+//	fake := serializertest.NewFakeSerializer().FailNext(serializertest.MethodSerialize, errBoom)
+//	store := NewStore(fake)
+//	if err := store.Save(item); !errors.Is(err, errBoom) { ... }
+type FakeSerializer struct {
+	mu          sync.Mutex
+	delegate    serializer.Serializer
+	contentType string
+	latency     time.Duration
+	failAlways  map[Method]error
+	failNext    map[Method][]error
+	calls       []Call
+}
+
+var (
+	_ serializer.StringDeserializer = (*FakeSerializer)(nil)
+	_ serializer.IndentSerializer   = (*FakeSerializer)(nil)
+	_ serializer.TypedSerializer    = (*FakeSerializer)(nil)
+	_ serializer.PoolStatsProvider  = (*FakeSerializer)(nil)
+)
+
+// NewFakeSerializer creates a FakeSerializer delegating to a JSON serializer
+func NewFakeSerializer() *FakeSerializer {
+	return &FakeSerializer{
+		delegate:   serializer.NewJSONSerializer(0),
+		failAlways: make(map[Method]error),
+		failNext:   make(map[Method][]error),
+	}
+}
+
+// WithDelegate makes f pass calls to s
+func (f *FakeSerializer) WithDelegate(s serializer.Serializer) *FakeSerializer {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.delegate = s
+	return f
+}
+
+// WithContentType makes ContentType return contentType instead of the delegate's
+func (f *FakeSerializer) WithContentType(contentType string) *FakeSerializer {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.contentType = contentType
+	return f
+}
+
+// WithLatency makes every call sleep for d before doing anything else
+func (f *FakeSerializer) WithLatency(d time.Duration) *FakeSerializer {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.latency = d
+	return f
+}
+
+// FailWith makes every call to method return err, until ClearFailures.
+// A nil err removes the failure.
+func (f *FakeSerializer) FailWith(method Method, err error) *FakeSerializer {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err == nil {
+		delete(f.failAlways, method)
+	} else {
+		f.failAlways[method] = err
+	}
+	return f
+}
+
+// FailNext makes the next call to method return err. Queued failures are used in
+// order, before any set by FailWith.
+func (f *FakeSerializer) FailNext(method Method, err error) *FakeSerializer {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.failNext[method] = append(f.failNext[method], err)
+	return f
+}
+
+// ClearFailures removes all injected failures
+func (f *FakeSerializer) ClearFailures() *FakeSerializer {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	clear(f.failAlways)
+	clear(f.failNext)
+	return f
+}
+
+// Calls returns the recorded calls in the order they completed
+func (f *FakeSerializer) Calls() []Call {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]Call(nil), f.calls...)
+}
+
+// CallCount returns the number of recorded calls to method
+func (f *FakeSerializer) CallCount(method Method) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	n := 0
+	for _, c := range f.calls {
+		if c.Method == method {
+			n++
+		}
+	}
+	return n
+}
+
+// ResetCalls forgets the recorded calls
+func (f *FakeSerializer) ResetCalls() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls = nil
+}
+
+// begin applies latency and returns the delegate and the failure to inject, if any
+func (f *FakeSerializer) begin(method Method) (serializer.Serializer, error) {
+	f.mu.Lock()
+	latency := f.latency
+	delegate := f.delegate
+	var err error
+	if queued := f.failNext[method]; len(queued) > 0 {
+		err = queued[0]
+		f.failNext[method] = queued[1:]
+	} else {
+		err = f.failAlways[method]
+	}
+	f.mu.Unlock()
+
+	if latency > 0 {
+		time.Sleep(latency)
+	}
+	return delegate, err
+}
+
+func (f *FakeSerializer) record(c Call) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls = append(f.calls, c)
+}
+
+// Serialize implements serializer.Serializer
+func (f *FakeSerializer) Serialize(v any) ([]byte, error) {
+	d, err := f.begin(MethodSerialize)
+	var data []byte
+	if err == nil {
+		data, err = d.Serialize(v)
+	}
+	f.record(Call{Method: MethodSerialize, Value: v, Data: bytes.Clone(data), Err: err})
+	return data, err
+}
+
+// Deserialize implements serializer.Serializer
+func (f *FakeSerializer) Deserialize(data []byte, v any) error {
+	d, err := f.begin(MethodDeserialize)
+	if err == nil {
+		err = d.Deserialize(data, v)
+	}
+	f.record(Call{Method: MethodDeserialize, Value: v, Data: bytes.Clone(data), Err: err})
+	return err
+}
+
+// SerializeTo implements serializer.Serializer
+func (f *FakeSerializer) SerializeTo(w io.Writer, v any) error {
+	d, err := f.begin(MethodSerializeTo)
+	var written bytes.Buffer
+	if err == nil {
+		err = d.SerializeTo(io.MultiWriter(w, &written), v)
+	}
+	f.record(Call{Method: MethodSerializeTo, Value: v, Data: written.Bytes(), Err: err})
+	return err
+}
+
+// DeserializeFrom implements serializer.Serializer
+func (f *FakeSerializer) DeserializeFrom(r io.Reader, v any) error {
+	d, err := f.begin(MethodDeserializeFrom)
+	var read bytes.Buffer
+	if err == nil {
+		err = d.DeserializeFrom(io.TeeReader(r, &read), v)
+	}
+	f.record(Call{Method: MethodDeserializeFrom, Value: v, Data: read.Bytes(), Err: err})
+	return err
+}
+
+// DeserializeString implements serializer.StringDeserializer
+func (f *FakeSerializer) DeserializeString(data string, v any) error {
+	d, err := f.begin(MethodDeserializeString)
+	if err == nil {
+		if sd, ok := d.(serializer.StringDeserializer); ok {
+			err = sd.DeserializeString(data, v)
+		} else {
+			err = d.Deserialize([]byte(data), v)
+		}
+	}
+	f.record(Call{Method: MethodDeserializeString, Value: v, Data: []byte(data), Err: err})
+	return err
+}
+
+// SerializeIndent implements serializer.IndentSerializer. Delegates without
+// indented output serialize normally.
+func (f *FakeSerializer) SerializeIndent(v any, prefix, indent string) ([]byte, error) {
+	d, err := f.begin(MethodSerializeIndent)
+	var data []byte
+	if err == nil {
+		if is, ok := d.(serializer.IndentSerializer); ok {
+			data, err = is.SerializeIndent(v, prefix, indent)
+		} else {
+			data, err = d.Serialize(v)
+		}
+	}
+	f.record(Call{Method: MethodSerializeIndent, Value: v, Data: bytes.Clone(data), Err: err})
+	return data, err
+}
+
+// SerializeIndentTo implements serializer.IndentSerializer
+func (f *FakeSerializer) SerializeIndentTo(w io.Writer, v any, prefix, indent string) error {
+	d, err := f.begin(MethodSerializeIndentTo)
+	var written bytes.Buffer
+	if err == nil {
+		mw := io.MultiWriter(w, &written)
+		if is, ok := d.(serializer.IndentSerializer); ok {
+			err = is.SerializeIndentTo(mw, v, prefix, indent)
+		} else {
+			err = d.SerializeTo(mw, v)
+		}
+	}
+	f.record(Call{Method: MethodSerializeIndentTo, Value: v, Data: written.Bytes(), Err: err})
+	return err
+}
+
+// SerializeWithTypeInfo implements serializer.TypedSerializer
+func (f *FakeSerializer) SerializeWithTypeInfo(v any, typeInfo serializer.TypeInfo) ([]byte, error) {
+	d, err := f.begin(MethodSerializeWithTypeInfo)
+	var data []byte
+	if err == nil {
+		if ts, ok := d.(serializer.TypedSerializer); ok {
+			data, err = ts.SerializeWithTypeInfo(v, typeInfo)
+		} else {
+			data, err = d.Serialize(v)
+		}
+	}
+	f.record(Call{Method: MethodSerializeWithTypeInfo, Value: v, Data: bytes.Clone(data), Err: err})
+	return data, err
+}
+
+// DeserializeWithTypeInfo implements serializer.TypedSerializer
+func (f *FakeSerializer) DeserializeWithTypeInfo(data []byte, typeInfo serializer.TypeInfo) (any, error) {
+	d, err := f.begin(MethodDeserializeWithTypeInfo)
+	var v any
+	if err == nil {
+		if ts, ok := d.(serializer.TypedSerializer); ok {
+			v, err = ts.DeserializeWithTypeInfo(data, typeInfo)
+		} else {
+			v, err = deserializeTyped(d, data, typeInfo)
+		}
+	}
+	f.record(Call{Method: MethodDeserializeWithTypeInfo, Value: v, Data: bytes.Clone(data), Err: err})
+	return v, err
+}
+
+var errNilType = errors.New("typeInfo.Type is nil")
+
+// deserializeTyped decodes data into a new value of typeInfo.Type with Deserialize
+func deserializeTyped(s serializer.Serializer, data []byte, typeInfo serializer.TypeInfo) (any, error) {
+	if typeInfo.Type == nil {
+		return nil, errNilType
+	}
+	target := reflect.New(typeInfo.Type)
+	if typeInfo.Type.Kind() == reflect.Ptr {
+		target.Elem().Set(reflect.New(typeInfo.Type.Elem()))
+	}
+	if err := s.Deserialize(data, target.Interface()); err != nil {
+		return nil, err
+	}
+	return target.Elem().Interface(), nil
+}
+
+// PoolStats implements serializer.PoolStatsProvider, reporting the delegate's
+// statistics when it has any
+func (f *FakeSerializer) PoolStats() serializer.PoolStats {
+	f.mu.Lock()
+	d := f.delegate
+	f.mu.Unlock()
+	if p, ok := d.(serializer.PoolStatsProvider); ok {
+		return p.PoolStats()
+	}
+	return serializer.PoolStats{}
+}
+
+// ContentType implements serializer.Serializer
+func (f *FakeSerializer) ContentType() string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.contentType != "" {
+		return f.contentType
+	}
+	return f.delegate.ContentType()
+}
@@ -0,0 +1,152 @@
+package serializertest
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/MichaelAJay/go-serializer"
+)
+
+func TestFakeSerializerDelegatesAndRecords(t *testing.T) {
+	f := NewFakeSerializer()
+	data, err := f.Serialize(map[string]int{"a": 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got map[string]int
+	if err := f.DeserializeString(string(data), &got); err != nil || got["a"] != 1 {
+		t.Fatalf("DeserializeString = %v, %v", got, err)
+	}
+
+	var buf bytes.Buffer
+	if err := f.SerializeTo(&buf, "x"); err != nil {
+		t.Fatal(err)
+	}
+	var s string
+	if err := f.DeserializeFrom(&buf, &s); err != nil || s != "x" {
+		t.Fatalf("DeserializeFrom = %q, %v", s, err)
+	}
+
+	calls := f.Calls()
+	methods := make([]Method, len(calls))
+	for i, c := range calls {
+		methods[i] = c.Method
+	}
+	want := []Method{MethodSerialize, MethodDeserializeString, MethodSerializeTo, MethodDeserializeFrom}
+	if !reflect.DeepEqual(methods, want) {
+		t.Fatalf("recorded %v, want %v", methods, want)
+	}
+	if string(calls[0].Data) != string(data) || string(calls[1].Data) != string(data) {
+		t.Errorf("recorded data %q and %q, want %q", calls[0].Data, calls[1].Data, data)
+	}
+	if !strings.Contains(string(calls[2].Data), `"x"`) || !bytes.HasPrefix(calls[2].Data, calls[3].Data) || len(calls[3].Data) == 0 {
+		t.Errorf("stream data not recorded: %q, %q", calls[2].Data, calls[3].Data)
+	}
+
+	f.ResetCalls()
+	if len(f.Calls()) != 0 {
+		t.Error("ResetCalls kept calls")
+	}
+}
+
+func TestFakeSerializerFailures(t *testing.T) {
+	errOnce := errors.New("once")
+	errAlways := errors.New("always")
+	f := NewFakeSerializer().
+		FailNext(MethodSerialize, errOnce).
+		FailWith(MethodSerialize, errAlways).
+		FailWith(MethodDeserializeWithTypeInfo, errAlways)
+
+	if _, err := f.Serialize(1); !errors.Is(err, errOnce) {
+		t.Errorf("first call: got %v, want %v", err, errOnce)
+	}
+	if _, err := f.Serialize(1); !errors.Is(err, errAlways) {
+		t.Errorf("second call: got %v, want %v", err, errAlways)
+	}
+	if _, err := f.SerializeWithTypeInfo(1, serializer.TypeInfo{}); err != nil {
+		t.Errorf("other methods should not fail: %v", err)
+	}
+	if _, err := f.DeserializeWithTypeInfo([]byte("1"), serializer.TypeInfo{Type: reflect.TypeFor[int]()}); !errors.Is(err, errAlways) {
+		t.Errorf("got %v, want %v", err, errAlways)
+	}
+	if c := f.Calls()[0]; c.Err != errOnce || c.Data != nil {
+		t.Errorf("failed call recorded as %+v", c)
+	}
+
+	f.FailWith(MethodSerialize, nil)
+	if _, err := f.Serialize(1); err != nil {
+		t.Errorf("FailWith(nil) did not remove the failure: %v", err)
+	}
+	f.ClearFailures()
+	if _, err := f.DeserializeWithTypeInfo([]byte("1"), serializer.TypeInfo{Type: reflect.TypeFor[int]()}); err != nil {
+		t.Errorf("ClearFailures did not remove the failure: %v", err)
+	}
+	if n := f.CallCount(MethodSerialize); n != 3 {
+		t.Errorf("CallCount(Serialize) = %d, want 3", n)
+	}
+}
+
+func TestFakeSerializerLatency(t *testing.T) {
+	f := NewFakeSerializer().WithLatency(20 * time.Millisecond)
+	start := time.Now()
+	if _, err := f.Serialize(1); err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed < 20*time.Millisecond {
+		t.Errorf("call took %v, want at least 20ms", elapsed)
+	}
+}
+
+func TestFakeSerializerFallbacks(t *testing.T) {
+	// A delegate with none of the optional interfaces
+	f := NewFakeSerializer().
+		WithDelegate(struct{ serializer.Serializer }{serializer.NewMsgpackSerializer()}).
+		WithContentType("application/x-fake")
+
+	if f.ContentType() != "application/x-fake" {
+		t.Errorf("ContentType = %q", f.ContentType())
+	}
+	data, err := f.SerializeIndent([]int{1, 2}, "", "  ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	v, err := f.DeserializeWithTypeInfo(data, serializer.TypeInfo{Type: reflect.TypeFor[*[]int]()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := v.(*[]int); !reflect.DeepEqual(*got, []int{1, 2}) {
+		t.Errorf("decoded %v", *got)
+	}
+	var s []int
+	if err := f.DeserializeString(string(data), &s); err != nil || len(s) != 2 {
+		t.Errorf("DeserializeString = %v, %v", s, err)
+	}
+	if f.PoolStats() != (serializer.PoolStats{}) {
+		t.Error("expected empty pool stats")
+	}
+}
+
+func TestFakeSerializerConcurrent(t *testing.T) {
+	f := NewFakeSerializer()
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				f.FailNext(MethodDeserialize, errors.New("x"))
+				_, _ = f.Serialize(j)
+				_ = f.Deserialize([]byte("1"), new(int))
+			}
+		}()
+	}
+	wg.Wait()
+	if n := len(f.Calls()); n != 800 {
+		t.Errorf("recorded %d calls, want 800", n)
+	}
+}
@@ -0,0 +1,180 @@
+package serializertest
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/MichaelAJay/go-serializer"
+)
+
+// Corpus collects seed inputs for fuzz targets. Seeds are usually valid encodings
+// of representative values, which the fuzzer then mutates.
+type Corpus struct {
+	entries [][]byte
+	seen    map[string]bool
+}
+
+// NewCorpus creates an empty corpus
+func NewCorpus() *Corpus {
+	return &Corpus{seen: make(map[string]bool)}
+}
+
+// SeedCorpus returns a corpus of values covering the shapes every format handles
+// (scalars, strings, nested maps and slices) encoded with s, together with their
+// truncations. Values the format cannot represent are left out.
+func SeedCorpus(s serializer.Serializer) *Corpus {
+	c := NewCorpus()
+	for _, v := range seedValues() {
+		_ = c.AddValues(s, v)
+	}
+	return c.AddTruncations()
+}
+
+func seedValues() []any {
+	return []any{
+		true,
+		0,
+		-1,
+		math.MaxInt64,
+		uint64(math.MaxUint64),
+		3.25,
+		"",
+		"hello, world",
+		"ünïcödé   \x00 \"quoted\" \\ <html>",
+		strings.Repeat("x", 300),
+		[]byte{0, 1, 2, 255},
+		[]any{1, "two", 3.5, nil, []any{}},
+		[]string{"a", "b"},
+		map[string]any{},
+		map[string]any{"name": "Ada", "age": 36, "tags": []any{"a"}, "nested": map[string]any{"ok": true}},
+		map[string]string{"k": "v"},
+		map[string]int{"one": 1, "two": 2},
+	}
+}
+
+// Add adds data to the corpus unless it is already present
+func (c *Corpus) Add(data []byte) *Corpus {
+	if !c.seen[string(data)] {
+		c.seen[string(data)] = true
+		c.entries = append(c.entries, bytes.Clone(data))
+	}
+	return c
+}
+
+// AddValues adds the encoding of each value by s. Values s cannot encode are an error.
+func (c *Corpus) AddValues(s serializer.Serializer, values ...any) error {
+	for _, v := range values {
+		data, err := s.Serialize(v)
+		if err != nil {
+			return fmt.Errorf("serializertest: encode seed %T: %w", v, err)
+		}
+		c.Add(data)
+	}
+	return nil
+}
+
+// AddTruncations adds every proper prefix of each entry up to 64 bytes long, and
+// a sample of longer prefixes, since truncated input is the most common way real
+// payloads go wrong
+func (c *Corpus) AddTruncations() *Corpus {
+	for _, data := range c.Entries() {
+		step := 1 + len(data)/64
+		for n := 0; n < len(data); n++ {
+			if n < 64 || n%step == 0 {
+				c.Add(data[:n])
+			}
+		}
+	}
+	return c
+}
+
+// Entries returns the inputs in the order they were added
+func (c *Corpus) Entries() [][]byte {
+	return c.entries
+}
+
+// Seed adds every entry to f's seed corpus
+func (c *Corpus) Seed(f *testing.F) {
+	for _, data := range c.entries {
+		f.Add(data)
+	}
+}
+
+// WriteDir writes every entry to dir in the format go test reads seed corpora in,
+// one file per entry named by its hash. Pass testdata/fuzz/<FuzzTarget> to check
+// a corpus in next to the fuzz target.
+func (c *Corpus) WriteDir(dir string) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	for _, data := range c.entries {
+		sum := sha256.Sum256(data)
+		name := hex.EncodeToString(sum[:8])
+		content := fmt.Sprintf("go test fuzz v1\n[]byte(%q)\n", data)
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// FuzzDeserialize fuzzes the decoding paths of s: Deserialize, DeserializeFrom and,
+// when s implements it, DeserializeString. Each input is decoded into a fresh value
+// from every newTarget, or into an any when none are given. It seeds f with
+// SeedCorpus(s) and fails when
+//
+//   - a decoder panics
+//   - Deserialize and DeserializeString disagree on whether the input is valid
+//   - a value Deserialize accepted cannot be serialized and decoded again
+//
+// Call it from a fuzz target:
+//
+//	func FuzzOrders(f *testing.F) {
+//		serializertest.FuzzDeserialize(f, serializer.NewJSONSerializer(0), func() any { return new(Order) })
+//	}
+func FuzzDeserialize(f *testing.F, s serializer.Serializer, newTargets ...func() any) {
+	f.Helper()
+	if len(newTargets) == 0 {
+		newTargets = []func() any{func() any { return new(any) }}
+	}
+	SeedCorpus(s).Seed(f)
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		for _, newTarget := range newTargets {
+			checkDecode(t, s, data, newTarget)
+		}
+	})
+}
+
+// checkDecode runs data through every decoding path of s into values from newTarget
+func checkDecode(t testing.TB, s serializer.Serializer, data []byte, newTarget func() any) {
+	t.Helper()
+	v := newTarget()
+	err := s.Deserialize(data, v)
+
+	_ = s.DeserializeFrom(bytes.NewReader(data), newTarget())
+
+	if sd, ok := s.(serializer.StringDeserializer); ok {
+		if serr := sd.DeserializeString(string(data), newTarget()); (serr == nil) != (err == nil) {
+			t.Fatalf("Deserialize and DeserializeString disagree on %q into %T: %v vs %v", data, v, err, serr)
+		}
+	}
+	if err != nil {
+		return
+	}
+
+	again, err := s.Serialize(v)
+	if err != nil {
+		t.Fatalf("cannot serialize %#v decoded from %q: %v", v, data, err)
+	}
+	if err := s.Deserialize(again, newTarget()); err != nil {
+		t.Fatalf("cannot decode %q, the re-encoding of %q into %T: %v", again, data, v, err)
+	}
+}
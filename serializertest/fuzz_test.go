@@ -0,0 +1,100 @@
+package serializertest
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/MichaelAJay/go-serializer"
+)
+
+func TestSeedCorpus(t *testing.T) {
+	s := serializer.NewJSONSerializer(0)
+	c := SeedCorpus(s)
+	entries := c.Entries()
+	if len(entries) < len(seedValues()) {
+		t.Fatalf("got %d entries, want at least %d", len(entries), len(seedValues()))
+	}
+	seen := make(map[string]bool)
+	for _, e := range entries {
+		if seen[string(e)] {
+			t.Errorf("duplicate entry %q", e)
+		}
+		seen[string(e)] = true
+	}
+	if !seen[`"hello, world"`] && !seen["\"hello, world\"\n"] {
+		t.Error("corpus is missing the encoded string seed")
+	}
+	truncated := false
+	for _, e := range entries {
+		for _, other := range entries {
+			if len(e) > 0 && len(e) < len(other) && bytes.HasPrefix(other, e) {
+				truncated = true
+			}
+		}
+	}
+	if !truncated {
+		t.Error("corpus is missing truncations")
+	}
+}
+
+func TestCorpusAddValues(t *testing.T) {
+	c := NewCorpus().Add([]byte("a")).Add([]byte("a"))
+	if err := c.AddValues(serializer.NewJSONSerializer(0), "x", 1); err != nil {
+		t.Fatalf("AddValues failed: %v", err)
+	}
+	if got := len(c.Entries()); got != 3 {
+		t.Errorf("got %d entries, want 3", got)
+	}
+	if err := c.AddValues(serializer.NewJSONSerializer(0), func() {}); err == nil {
+		t.Error("AddValues accepted a value JSON cannot encode")
+	}
+}
+
+func TestCorpusWriteDir(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "testdata", "fuzz", "FuzzX")
+	c := NewCorpus().Add([]byte("{\"a\":1}\n")).Add([]byte{0, 0xff})
+	if err := c.WriteDir(dir); err != nil {
+		t.Fatalf("WriteDir failed: %v", err)
+	}
+	files, err := os.ReadDir(dir)
+	if err != nil || len(files) != 2 {
+		t.Fatalf("got %d files (%v), want 2", len(files), err)
+	}
+	for _, f := range files {
+		b, err := os.ReadFile(filepath.Join(dir, f.Name()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.HasPrefix(string(b), "go test fuzz v1\n[]byte(") {
+			t.Errorf("%s is not in the go test corpus format: %q", f.Name(), b)
+		}
+	}
+}
+
+func TestCheckDecodeReportsDisagreement(t *testing.T) {
+	r := record(t, func(tb testing.TB) {
+		checkDecode(tb, disagreeing{serializer.NewJSONSerializer(0)}, []byte(`{"a":1}`), func() any { return new(any) })
+	})
+	if !r.failed || !strings.Contains(r.msg, "disagree") {
+		t.Errorf("disagreement not reported: %q", r.msg)
+	}
+
+	r = record(t, func(tb testing.TB) {
+		checkDecode(tb, serializer.NewJSONSerializer(0), []byte(`{"a":[1,"x"]}`), func() any { return new(any) })
+	})
+	if r.failed {
+		t.Errorf("valid input reported: %s", r.msg)
+	}
+}
+
+// disagreeing rejects in DeserializeString what Deserialize accepts
+type disagreeing struct {
+	serializer.Serializer
+}
+
+func (d disagreeing) DeserializeString(data string, v any) error {
+	return bytes.ErrTooLarge
+}
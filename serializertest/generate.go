@@ -0,0 +1,195 @@
+package serializertest
+
+import (
+	"math"
+	"math/rand"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// Generator produces random values of arbitrary types for round-trip tests.
+// Values stay within what every format can represent: strings are valid UTF-8,
+// floats are finite and times fall between 1970 and 2100.
+type Generator struct {
+	// MaxLen bounds the length of generated strings, slices and maps
+	MaxLen int
+	// MaxDepth bounds how deeply pointers, interfaces and containers nest,
+	// which keeps recursive types finite
+	MaxDepth int
+
+	seed int64
+	rand *rand.Rand
+}
+
+// NewGenerator creates a generator whose values are determined by seed
+func NewGenerator(seed int64) *Generator {
+	return &Generator{
+		MaxLen:   8,
+		MaxDepth: 4,
+		seed:     seed,
+		rand:     rand.New(rand.NewSource(seed)),
+	}
+}
+
+// Seed returns the seed the generator was created with
+func (g *Generator) Seed() int64 {
+	return g.seed
+}
+
+// Generate returns a random value of type T
+func Generate[T any](g *Generator) T {
+	var v T
+	g.fill(reflect.ValueOf(&v).Elem(), 0)
+	return v
+}
+
+// Value returns a random value of type typ
+func (g *Generator) Value(typ reflect.Type) any {
+	v := reflect.New(typ).Elem()
+	g.fill(v, 0)
+	return v.Interface()
+}
+
+// fill sets v, which must be settable, to a random value. Types no format can
+// encode, such as channels and functions, are left as their zero value.
+func (g *Generator) fill(v reflect.Value, depth int) {
+	r := g.rand
+	if v.Type() == timeType {
+		sec := r.Int63n(130 * 365 * 24 * 60 * 60)
+		v.Set(reflect.ValueOf(time.Unix(sec, r.Int63n(1e9)).UTC()))
+		return
+	}
+
+	// SetInt, SetUint and SetFloat truncate to the width of v
+	switch v.Kind() {
+	case reflect.Bool:
+		v.SetBool(r.Intn(2) == 1)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		v.SetInt(g.int64())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		v.SetUint(uint64(g.int64()))
+	case reflect.Float32:
+		v.SetFloat(float64(float32(max(min(g.float64(), math.MaxFloat32), -math.MaxFloat32))))
+	case reflect.Float64:
+		v.SetFloat(g.float64())
+	case reflect.String:
+		v.SetString(g.string())
+	case reflect.Ptr:
+		if depth >= g.MaxDepth || r.Intn(4) == 0 {
+			return
+		}
+		elem := reflect.New(v.Type().Elem())
+		g.fill(elem.Elem(), depth+1)
+		v.Set(elem)
+	case reflect.Interface:
+		if v.NumMethod() > 0 || depth >= g.MaxDepth {
+			return
+		}
+		if x := g.any(depth + 1); x != nil {
+			v.Set(reflect.ValueOf(x))
+		}
+	case reflect.Slice:
+		if depth >= g.MaxDepth {
+			return
+		}
+		n := r.Intn(g.MaxLen + 1)
+		s := reflect.MakeSlice(v.Type(), n, n)
+		for i := 0; i < n; i++ {
+			g.fill(s.Index(i), depth+1)
+		}
+		v.Set(s)
+	case reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			g.fill(v.Index(i), depth+1)
+		}
+	case reflect.Map:
+		if depth >= g.MaxDepth {
+			return
+		}
+		n := r.Intn(g.MaxLen + 1)
+		m := reflect.MakeMapWithSize(v.Type(), n)
+		for i := 0; i < n; i++ {
+			key := reflect.New(v.Type().Key()).Elem()
+			elem := reflect.New(v.Type().Elem()).Elem()
+			g.fill(key, depth+1)
+			g.fill(elem, depth+1)
+			m.SetMapIndex(key, elem)
+		}
+		v.Set(m)
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			if v.Type().Field(i).IsExported() {
+				g.fill(v.Field(i), depth)
+			}
+		}
+	}
+}
+
+// int64 favours small magnitudes and boundary values over uniform ones, which
+// exercise the variable-width encodings
+func (g *Generator) int64() int64 {
+	r := g.rand
+	switch r.Intn(4) {
+	case 0:
+		return int64(r.Intn(256)) - 128
+	case 1:
+		return []int64{0, -1, math.MaxInt64, math.MinInt64, math.MaxInt32, math.MinInt32}[r.Intn(6)]
+	default:
+		return int64(r.Uint64())
+	}
+}
+
+func (g *Generator) float64() float64 {
+	r := g.rand
+	switch r.Intn(3) {
+	case 0:
+		return float64(r.Intn(2000) - 1000)
+	case 1:
+		return r.NormFloat64() * 1e6
+	default:
+		return []float64{0, 0.1, -2.5, math.MaxFloat64, math.SmallestNonzeroFloat64, 1 << 53}[r.Intn(6)]
+	}
+}
+
+// runes mixes characters that need escaping in text formats with multi-byte ones
+var runes = []rune("abcxyzABC019 _-.\"\\/<>&'\x00\t\n\u007fé中\U0001F600")
+
+func (g *Generator) string() string {
+	var b strings.Builder
+	for n := g.rand.Intn(g.MaxLen*2 + 1); n > 0; n-- {
+		b.WriteRune(runes[g.rand.Intn(len(runes))])
+	}
+	return b.String()
+}
+
+// any returns a value of one of the types every format decodes into an interface
+func (g *Generator) any(depth int) any {
+	r := g.rand
+	kinds := 6
+	if depth >= g.MaxDepth {
+		kinds = 4
+	}
+	switch r.Intn(kinds) {
+	case 0:
+		return nil
+	case 1:
+		return r.Intn(2) == 1
+	case 2:
+		return g.float64()
+	case 3:
+		return g.string()
+	case 4:
+		s := make([]any, r.Intn(g.MaxLen+1))
+		for i := range s {
+			s[i] = g.any(depth + 1)
+		}
+		return s
+	default:
+		m := make(map[string]any)
+		for n := r.Intn(g.MaxLen + 1); n > 0; n-- {
+			m[g.string()] = g.any(depth + 1)
+		}
+		return m
+	}
+}
@@ -0,0 +1,346 @@
+package serializertest
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/big"
+	"reflect"
+	"sort"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/MichaelAJay/go-serializer"
+)
+
+// RoundTrip serializes value with s, decodes the result into a new value of the
+// same type and fails t unless the two are equivalent as reported by Diff.
+// Pass a value rather than a pointer to it unless the pointer is what your code
+// serializes.
+func RoundTrip(t testing.TB, s serializer.Serializer, value any) {
+	t.Helper()
+	if err := roundTrip(s, value); err != nil {
+		t.Errorf("serializertest: %s round trip of %T: %v", s.ContentType(), value, err)
+	}
+}
+
+// RoundTripAll runs RoundTrip for every format in r, each in a subtest named
+// after the format
+func RoundTripAll(t *testing.T, r *serializer.Registry, value any) {
+	t.Helper()
+	for _, format := range r.Formats() {
+		s, _ := r.Get(format)
+		t.Run(string(format), func(t *testing.T) {
+			RoundTrip(t, s, value)
+		})
+	}
+}
+
+// RoundTripGenerated round-trips n values of type T produced by g through s,
+// stopping at the first failure. The generator's seed is logged on failure so
+// the run can be reproduced with NewGenerator.
+func RoundTripGenerated[T any](t testing.TB, s serializer.Serializer, g *Generator, n int) {
+	t.Helper()
+	for i := 0; i < n; i++ {
+		value := Generate[T](g)
+		if err := roundTrip(s, value); err != nil {
+			t.Fatalf("serializertest: %s round trip of generated %T (value %d, seed %d): %v\nvalue: %#v",
+				s.ContentType(), value, i, g.Seed(), err, value)
+		}
+	}
+}
+
+func roundTrip(s serializer.Serializer, value any) error {
+	data, err := s.Serialize(value)
+	if err != nil {
+		return fmt.Errorf("serialize: %w", err)
+	}
+	var target reflect.Value
+	if value == nil {
+		target = reflect.New(reflect.TypeFor[any]())
+	} else {
+		target = reflect.New(reflect.TypeOf(value))
+	}
+	if err := s.Deserialize(data, target.Interface()); err != nil {
+		return fmt.Errorf("deserialize: %w", err)
+	}
+	if d := Diff(value, target.Elem().Interface()); d != "" {
+		return fmt.Errorf("decoded value differs: %s", d)
+	}
+	return nil
+}
+
+// Diff describes the first difference between want and got, or returns "" when
+// they are equivalent. It allows for what the formats are known to change on the
+// way through:
+//
+//   - numbers compare by value whatever their type, so an int decoded into an
+//     interface as JSON's float64 or MessagePack's int8 still matches. Integers
+//     beyond ±2^53 compared with a float64 only need to match at float64 precision,
+//     and float32 values at float32 precision.
+//   - nil pointers, nil interfaces, nil maps and nil slices match zero and empty
+//     values, since gob omits zero values and JSON turns nil slices into null
+//   - times compare with time.Time.Equal, so locations and monotonic readings
+//     are ignored, and match RFC 3339 strings
+//   - []byte matches its base64 encoding, which is what JSON decodes into an interface
+//   - maps and slices compare element by element regardless of their static
+//     type, and map keys compare by their printed form
+//
+// Unexported struct fields, which no format transmits, are ignored.
+func Diff(want, got any) string {
+	return diff("value", reflect.ValueOf(want), reflect.ValueOf(got))
+}
+
+var (
+	timeType       = reflect.TypeFor[time.Time]()
+	jsonNumberType = reflect.TypeFor[json.Number]()
+)
+
+func diff(path string, want, got reflect.Value) string {
+	want, got = indirect(want), indirect(got)
+	if !want.IsValid() || !got.IsValid() {
+		if isZero(want) && isZero(got) {
+			return ""
+		}
+		return fmt.Sprintf("%s: want %s, got %s", path, show(want), show(got))
+	}
+
+	if want.Type() == timeType {
+		return diffTime(path, want.Interface().(time.Time), got)
+	}
+	if isNumber(want) && isNumber(got) {
+		if !numbersEqual(want, got) {
+			return fmt.Sprintf("%s: want %s, got %s", path, show(want), show(got))
+		}
+		return ""
+	}
+	if isBytes(want) && got.Kind() == reflect.String {
+		if base64.StdEncoding.EncodeToString(want.Bytes()) != got.String() {
+			return fmt.Sprintf("%s: want %s, got %s", path, show(want), show(got))
+		}
+		return ""
+	}
+
+	switch want.Kind() {
+	case reflect.Slice, reflect.Array:
+		if got.Kind() != reflect.Slice && got.Kind() != reflect.Array {
+			break
+		}
+		if want.Len() != got.Len() {
+			return fmt.Sprintf("%s: want length %d, got %d", path, want.Len(), got.Len())
+		}
+		for i := 0; i < want.Len(); i++ {
+			if d := diff(fmt.Sprintf("%s[%d]", path, i), want.Index(i), got.Index(i)); d != "" {
+				return d
+			}
+		}
+		return ""
+	case reflect.Map:
+		if got.Kind() != reflect.Map {
+			break
+		}
+		return diffMap(path, want, got)
+	case reflect.Struct:
+		if got.Type() != want.Type() {
+			break
+		}
+		for i := 0; i < want.NumField(); i++ {
+			f := want.Type().Field(i)
+			if !f.IsExported() {
+				continue
+			}
+			if d := diff(path+"."+f.Name, want.Field(i), got.Field(i)); d != "" {
+				return d
+			}
+		}
+		return ""
+	case reflect.String:
+		if got.Kind() != reflect.String {
+			break
+		}
+		if want.String() != got.String() {
+			return fmt.Sprintf("%s: want %q, got %q", path, want.String(), got.String())
+		}
+		return ""
+	case reflect.Bool:
+		if got.Kind() != reflect.Bool {
+			break
+		}
+		if want.Bool() != got.Bool() {
+			return fmt.Sprintf("%s: want %t, got %t", path, want.Bool(), got.Bool())
+		}
+		return ""
+	default:
+		if got.Type() == want.Type() && want.Comparable() && want.Equal(got) {
+			return ""
+		}
+	}
+	return fmt.Sprintf("%s: want %s, got %s", path, show(want), show(got))
+}
+
+func diffTime(path string, want time.Time, got reflect.Value) string {
+	var t time.Time
+	switch {
+	case got.Type() == timeType:
+		t = got.Interface().(time.Time)
+	case got.Kind() == reflect.String:
+		parsed, err := time.Parse(time.RFC3339Nano, got.String())
+		if err != nil {
+			return fmt.Sprintf("%s: want %s, got %q", path, want.Format(time.RFC3339Nano), got.String())
+		}
+		t = parsed
+	default:
+		return fmt.Sprintf("%s: want time.Time, got %s", path, show(got))
+	}
+	if !want.Equal(t) {
+		return fmt.Sprintf("%s: want %s, got %s", path, want.Format(time.RFC3339Nano), t.Format(time.RFC3339Nano))
+	}
+	return ""
+}
+
+func diffMap(path string, want, got reflect.Value) string {
+	gotByKey := make(map[string]reflect.Value, got.Len())
+	iter := got.MapRange()
+	for iter.Next() {
+		gotByKey[keyString(iter.Key())] = iter.Value()
+	}
+
+	keys := make([]string, 0, want.Len())
+	wantByKey := make(map[string]reflect.Value, want.Len())
+	iter = want.MapRange()
+	for iter.Next() {
+		k := keyString(iter.Key())
+		keys = append(keys, k)
+		wantByKey[k] = iter.Value()
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		g, ok := gotByKey[k]
+		if !ok {
+			return fmt.Sprintf("%s: missing key %q", path, k)
+		}
+		if d := diff(fmt.Sprintf("%s[%q]", path, k), wantByKey[k], g); d != "" {
+			return d
+		}
+	}
+	if len(gotByKey) != len(keys) {
+		for k := range gotByKey {
+			if _, ok := wantByKey[k]; !ok {
+				return fmt.Sprintf("%s: unexpected key %q", path, k)
+			}
+		}
+		// Two keys printed the same way, such as 1 and 1.0
+		return fmt.Sprintf("%s: want %d keys, got %d", path, len(keys), len(gotByKey))
+	}
+	return ""
+}
+
+// indirect follows pointers and interfaces, returning the zero Value for nil
+func indirect(v reflect.Value) reflect.Value {
+	for v.IsValid() && (v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface) {
+		if v.IsNil() {
+			return reflect.Value{}
+		}
+		v = v.Elem()
+	}
+	return v
+}
+
+// isZero reports whether v is nil, a zero value or an empty map or slice
+func isZero(v reflect.Value) bool {
+	if !v.IsValid() || v.IsZero() {
+		return true
+	}
+	switch v.Kind() {
+	case reflect.Map, reflect.Slice:
+		return v.Len() == 0
+	}
+	return false
+}
+
+func isNumber(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return v.Type() == jsonNumberType
+}
+
+func isBytes(v reflect.Value) bool {
+	return v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.Uint8
+}
+
+// numbersEqual compares integers exactly and anything involving a float at the
+// precision of the narrowest float involved
+func numbersEqual(a, b reflect.Value) bool {
+	ai, af, aBits := number(a)
+	bi, bf, bBits := number(b)
+	if ai != nil && bi != nil {
+		return ai.Cmp(bi) == 0
+	}
+	if ai != nil {
+		af, _ = new(big.Float).SetInt(ai).Float64()
+	}
+	if bi != nil {
+		bf, _ = new(big.Float).SetInt(bi).Float64()
+	}
+	if math.IsNaN(af) && math.IsNaN(bf) {
+		return true
+	}
+	if aBits == 32 || bBits == 32 {
+		return float32(af) == float32(bf)
+	}
+	return af == bf
+}
+
+// number returns v as an integer, or as a float along with its size in bits
+func number(v reflect.Value) (*big.Int, float64, int) {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return big.NewInt(v.Int()), 0, 0
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return new(big.Int).SetUint64(v.Uint()), 0, 0
+	case reflect.Float32:
+		return nil, v.Float(), 32
+	case reflect.Float64:
+		return nil, v.Float(), 64
+	}
+	// json.Number
+	s := v.String()
+	if i, ok := new(big.Int).SetString(s, 10); ok {
+		return i, 0, 0
+	}
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		f = math.NaN()
+	}
+	return nil, f, 64
+}
+
+// keyString prints a map key so that keys of different types holding the same
+// value, like "1", 1 and 1.0, match
+func keyString(k reflect.Value) string {
+	k = indirect(k)
+	if !k.IsValid() {
+		return "<nil>"
+	}
+	if k.Kind() == reflect.String {
+		return k.String()
+	}
+	return fmt.Sprint(k.Interface())
+}
+
+func show(v reflect.Value) string {
+	if !v.IsValid() {
+		return "nil"
+	}
+	if v.CanInterface() {
+		return fmt.Sprintf("%#v (%s)", v.Interface(), v.Type())
+	}
+	return v.Type().String()
+}
@@ -0,0 +1,23 @@
+//go:build !tinygo
+
+package serializertest
+
+import (
+	"testing"
+
+	"github.com/MichaelAJay/go-serializer"
+)
+
+func TestRoundTripGeneratedGob(t *testing.T) {
+	// gob needs the concrete types held in interfaces registered
+	s := serializer.NewGobSerializerWithTypes(map[string]any{}, []any{})
+	g := NewGenerator(1)
+	RoundTripGenerated[rtRecord](t, s, g, 200)
+	RoundTripGenerated[map[string]rtInner](t, s, g, 200)
+
+	// gob cannot encode nil elements, which RoundTrip reports
+	rec := record(t, func(tb testing.TB) { RoundTrip(tb, s, []*rtInner{nil}) })
+	if !rec.failed {
+		t.Error("expected the nil element to be reported")
+	}
+}
@@ -0,0 +1,154 @@
+package serializertest
+
+import (
+	"encoding/json"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/MichaelAJay/go-serializer"
+)
+
+type rtInner struct {
+	Scores map[string]float64
+	Labels []string
+}
+
+type rtRecord struct {
+	ID       int64
+	Count    uint16
+	Ratio    float32
+	Name     string
+	Active   bool
+	Created  time.Time
+	Raw      []byte
+	Tags     []string
+	Attrs    map[string]string
+	Inner    rtInner
+	Parent   *rtRecord
+	Extra    any
+	internal int
+}
+
+func TestRoundTripGenerated(t *testing.T) {
+	serializers := map[string]serializer.Serializer{
+		// The default configuration rounds floats to 6 decimal places
+		"json":    serializer.NewJSONSerializer(0, serializer.WithJSONConfig(serializer.JSONConfigDefault)),
+		"stdlib":  serializer.NewJSONSerializer(0, serializer.WithStdlibJSON()),
+		"msgpack": serializer.NewMsgpackSerializer(),
+	}
+	for name, s := range serializers {
+		t.Run(name, func(t *testing.T) {
+			g := NewGenerator(1)
+			RoundTripGenerated[rtRecord](t, s, g, 200)
+			RoundTripGenerated[map[string]any](t, s, g, 200)
+			RoundTripGenerated[[]*rtInner](t, s, g, 200)
+		})
+	}
+}
+
+func TestRoundTripAll(t *testing.T) {
+	r := serializer.NewRegistry()
+	r.Register(serializer.JSON, serializer.NewJSONSerializer(0))
+	r.Register(serializer.Msgpack, serializer.NewMsgpackSerializer())
+	RoundTripAll(t, r, map[string]any{"id": 7, "big": int64(1) << 60, "when": time.Unix(5, 6), "raw": []byte("x")})
+}
+
+func TestRoundTripReportsLoss(t *testing.T) {
+	rec := record(t, func(tb testing.TB) {
+		RoundTrip(tb, serializer.NewJSONSerializer(0, serializer.WithJSONConfig(serializer.JSONConfigFastest)), 0.1234567891)
+	})
+	if serializer.DefaultJSONBackend() == serializer.JSONBackendJSONIter && !rec.failed {
+		t.Error("expected float rounding to be reported")
+	}
+
+	type lossy struct {
+		Kept    string
+		Dropped string `json:"-"`
+	}
+	rec = record(t, func(tb testing.TB) {
+		RoundTrip(tb, serializer.NewJSONSerializer(0), lossy{Kept: "a", Dropped: "b"})
+	})
+	if !rec.failed || !strings.Contains(rec.msg, "value.Dropped") {
+		t.Errorf("expected the dropped field to be reported, got %q", rec.msg)
+	}
+}
+
+func TestDiff(t *testing.T) {
+	equivalent := []struct {
+		name      string
+		want, got any
+	}{
+		{"int as float64", map[string]any{"n": 3}, map[string]any{"n": 3.0}},
+		{"int as int8", []any{int64(-5)}, []any{int8(-5)}},
+		{"uint64 as int64", uint64(1 << 62), int64(1 << 62)},
+		{"int64 beyond 2^53 as float64", int64(1<<60 + 1), float64(1 << 60)},
+		{"float32 as float64", float32(0.1), 0.1},
+		{"json.Number", json.Number("12"), 12},
+		{"NaN", math.NaN(), math.NaN()},
+		{"nil slice as empty", []string(nil), []string{}},
+		{"nil pointer as zero", (*int)(nil), new(int)},
+		{"time in another location", time.Unix(10, 0).UTC(), time.Unix(10, 0).In(time.FixedZone("x", 3600))},
+		{"time as string", time.Unix(10, 5).UTC(), "1970-01-01T00:00:10.000000005Z"},
+		{"bytes as base64", []byte("hi"), "aGk="},
+		{"typed map as any map", map[string]int{"a": 1}, map[string]any{"a": 1.0}},
+		{"int keys as strings", map[int]string{1: "x"}, map[string]any{"1": "x"}},
+		{"typed slice as any slice", []string{"a"}, []any{"a"}},
+		{"unexported fields", rtRecord{internal: 1}, rtRecord{}},
+	}
+	for _, tc := range equivalent {
+		if d := Diff(tc.want, tc.got); d != "" {
+			t.Errorf("%s: unexpected difference: %s", tc.name, d)
+		}
+	}
+
+	different := []struct {
+		name      string
+		want, got any
+		path      string
+	}{
+		{"int", map[string]any{"n": 3}, map[string]any{"n": 4.0}, `value["n"]`},
+		{"int64 exact", int64(1<<60 + 1), int64(1 << 60), "value"},
+		{"missing key", map[string]int{"a": 1}, map[string]int{}, `missing key "a"`},
+		{"extra key", map[string]int{}, map[string]int{"a": 1}, `unexpected key "a"`},
+		{"length", []int{1, 2}, []int{1}, "want length 2"},
+		{"nested field", rtRecord{Inner: rtInner{Labels: []string{"x"}}}, rtRecord{Inner: rtInner{Labels: []string{"y"}}}, "value.Inner.Labels[0]"},
+		{"nil and value", (*int)(nil), 1, "value"},
+		{"time", time.Unix(1, 0), time.Unix(2, 0), "value"},
+		{"kind", "1", 1, "value"},
+	}
+	for _, tc := range different {
+		d := Diff(tc.want, tc.got)
+		if !strings.Contains(d, tc.path) {
+			t.Errorf("%s: expected a difference mentioning %q, got %q", tc.name, tc.path, d)
+		}
+	}
+}
+
+func TestGenerator(t *testing.T) {
+	a := Generate[rtRecord](NewGenerator(42))
+	b := Generate[rtRecord](NewGenerator(42))
+	if !reflect.DeepEqual(a, b) {
+		t.Error("generators with the same seed produced different values")
+	}
+
+	g := NewGenerator(3)
+	g.MaxDepth = 2
+	for i := 0; i < 100; i++ {
+		v := g.Value(reflect.TypeFor[rtRecord]()).(rtRecord)
+		if v.internal != 0 {
+			t.Fatal("unexported field was set")
+		}
+		if v.Parent != nil && v.Parent.Parent != nil && v.Parent.Parent.Parent != nil {
+			t.Fatalf("MaxDepth %d exceeded", g.MaxDepth)
+		}
+		if math.IsInf(float64(v.Ratio), 0) {
+			t.Fatal("generated an infinite float32")
+		}
+		if v.Created.Year() < 1970 || v.Created.Year() > 2100 {
+			t.Fatalf("generated time %v out of range", v.Created)
+		}
+	}
+}
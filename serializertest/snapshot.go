@@ -0,0 +1,136 @@
+// Package serializertest provides helpers for testing code that uses go-serializer.
+package serializertest
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"mime"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/MichaelAJay/go-serializer"
+)
+
+// update rewrites golden files instead of comparing against them:
+//
+//	go test ./... -update-golden
+var update = flag.Bool("update-golden", false, "rewrite serializertest golden files")
+
+// GoldenDir is the directory, relative to the package under test, that holds golden files
+const GoldenDir = "testdata"
+
+// Snapshot serializes value with s and compares the result against the golden
+// file testdata/<name>.golden. Run the tests with -update-golden to create or
+// rewrite golden files after an intentional wire-format change.
+//
+// JSON output is canonicalized (sorted keys, indented) before it is stored, so
+// golden files are stable and diff well. Other formats are stored byte for byte;
+// values containing maps need a serializer with deterministic map ordering.
+func Snapshot(t testing.TB, s serializer.Serializer, name string, value any) {
+	t.Helper()
+	snapshot(t, GoldenDir, s, name, value)
+}
+
+func snapshot(t testing.TB, dir string, s serializer.Serializer, name string, value any) {
+	t.Helper()
+
+	text := isJSON(s.ContentType())
+	got, err := s.Serialize(value)
+	if err != nil {
+		t.Fatalf("serializertest: serialize %s: %v", name, err)
+	}
+	if text {
+		if got, err = canonicalJSON(got); err != nil {
+			t.Fatalf("serializertest: canonicalize %s: %v", name, err)
+		}
+	}
+
+	path := filepath.Join(dir, filepath.FromSlash(name)+".golden")
+	if *update {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("serializertest: %v", err)
+		}
+		if err := os.WriteFile(path, got, 0o644); err != nil {
+			t.Fatalf("serializertest: %v", err)
+		}
+		return
+	}
+
+	want, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		t.Fatalf("serializertest: golden file %s does not exist; run with -update-golden to create it", path)
+	}
+	if err != nil {
+		t.Fatalf("serializertest: %v", err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("serializertest: %s does not match %s (run with -update-golden to accept)\n%s",
+			name, path, describeDiff(want, got, text))
+	}
+}
+
+// canonicalJSON re-encodes data with sorted object keys and stable indentation.
+// Numbers are kept as written so no precision is lost.
+func canonicalJSON(data []byte) ([]byte, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	out, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(out, '\n'), nil
+}
+
+// describeDiff reports the first difference between want and got,
+// by line for text formats and by byte offset otherwise
+func describeDiff(want, got []byte, text bool) string {
+	if text {
+		wantLines := strings.Split(string(want), "\n")
+		gotLines := strings.Split(string(got), "\n")
+		for i := 0; i < len(wantLines) || i < len(gotLines); i++ {
+			var w, g string
+			if i < len(wantLines) {
+				w = wantLines[i]
+			}
+			if i < len(gotLines) {
+				g = gotLines[i]
+			}
+			if w != g {
+				return fmt.Sprintf("first difference at line %d:\n  want: %s\n   got: %s", i+1, w, g)
+			}
+		}
+	}
+
+	i := 0
+	for i < len(want) && i < len(got) && want[i] == got[i] {
+		i++
+	}
+	return fmt.Sprintf("first difference at byte %d (want %d bytes, got %d bytes)\n  want: %s\n   got: %s",
+		i, len(want), len(got), hexWindow(want, i), hexWindow(got, i))
+}
+
+// hexWindow renders up to 16 bytes of data starting at offset
+func hexWindow(data []byte, offset int) string {
+	if offset >= len(data) {
+		return "<end>"
+	}
+	end := min(offset+16, len(data))
+	return hex.EncodeToString(data[offset:end])
+}
+
+func isJSON(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	return mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
+}
@@ -0,0 +1,106 @@
+package serializertest
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/MichaelAJay/go-serializer"
+)
+
+// recorder captures failures instead of failing the enclosing test
+type recorder struct {
+	testing.TB
+	failed bool
+	msg    string
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Errorf(format string, args ...any) {
+	r.failed = true
+	r.msg = fmt.Sprintf(format, args...)
+}
+
+func (r *recorder) Fatalf(format string, args ...any) {
+	r.Errorf(format, args...)
+	runtime.Goexit()
+}
+
+// record runs fn against a recorder on its own goroutine so Fatalf can stop it
+func record(t *testing.T, fn func(tb testing.TB)) *recorder {
+	r := &recorder{TB: t}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		fn(r)
+	}()
+	<-done
+	return r
+}
+
+func withUpdate(t *testing.T, v bool) {
+	old := *update
+	*update = v
+	t.Cleanup(func() { *update = old })
+}
+
+func TestSnapshotJSONIsCanonical(t *testing.T) {
+	dir := t.TempDir()
+	s := serializer.NewJSONSerializer(0)
+	value := map[string]any{"b": 2, "a": 1.5, "c": []string{"x"}}
+
+	withUpdate(t, true)
+	snapshot(t, dir, s, "nested/value", value)
+
+	data, err := os.ReadFile(filepath.Join(dir, "nested", "value.golden"))
+	if err != nil {
+		t.Fatalf("golden file not written: %v", err)
+	}
+	want := "{\n  \"a\": 1.5,\n  \"b\": 2,\n  \"c\": [\n    \"x\"\n  ]\n}\n"
+	if string(data) != want {
+		t.Errorf("expected canonical JSON %q, got %q", want, data)
+	}
+
+	withUpdate(t, false)
+	for i := 0; i < 10; i++ {
+		r := record(t, func(tb testing.TB) { snapshot(tb, dir, s, "nested/value", value) })
+		if r.failed {
+			t.Fatalf("unexpected mismatch: %s", r.msg)
+		}
+	}
+
+	r := record(t, func(tb testing.TB) { snapshot(tb, dir, s, "nested/value", map[string]any{"a": 2}) })
+	if !r.failed || !strings.Contains(r.msg, "first difference at line 2") {
+		t.Errorf("expected line diff, got %q", r.msg)
+	}
+}
+
+func TestSnapshotMismatch(t *testing.T) {
+	dir := t.TempDir()
+	s := serializer.NewMsgpackSerializer()
+
+	withUpdate(t, true)
+	snapshot(t, dir, s, "item", []int{1, 2, 3})
+
+	withUpdate(t, false)
+	r := record(t, func(tb testing.TB) { snapshot(tb, dir, s, "item", []int{1, 2, 4}) })
+	if !r.failed {
+		t.Fatal("expected mismatch to be reported")
+	}
+	if !strings.Contains(r.msg, "first difference at byte 3") {
+		t.Errorf("expected byte offset in message, got %q", r.msg)
+	}
+}
+
+func TestSnapshotMissingGolden(t *testing.T) {
+	withUpdate(t, false)
+	dir := t.TempDir()
+	r := record(t, func(tb testing.TB) { snapshot(tb, dir, serializer.NewJSONSerializer(0), "missing", 1) })
+	if !r.failed || !strings.Contains(r.msg, "-update-golden") {
+		t.Errorf("expected missing golden hint, got %q", r.msg)
+	}
+}
@@ -0,0 +1,92 @@
+package serializer
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"reflect"
+)
+
+// SQLField stores a structured value of type T in a BLOB or TEXT column.
+// Value serializes V with Format and Scan deserializes the column back into V.
+// An empty Format uses JSON, so a zero SQLField can be used directly as a scan target.
+type SQLField[T any] struct {
+	V      T
+	Format Format
+}
+
+var (
+	_ driver.Valuer = SQLField[any]{}
+	_ sql.Scanner   = (*SQLField[any])(nil)
+)
+
+// NewSQLField wraps v for storage using the given format
+func NewSQLField[T any](format Format, v T) SQLField[T] {
+	return SQLField[T]{V: v, Format: format}
+}
+
+// Value implements driver.Valuer
+// A nil pointer, map, slice or interface is stored as SQL NULL.
+func (f SQLField[T]) Value() (driver.Value, error) {
+	if isNilValue(f.V) {
+		return nil, nil
+	}
+	s, err := f.serializer()
+	if err != nil {
+		return nil, err
+	}
+	data, err := s.Serialize(f.V)
+	if err != nil {
+		return nil, fmt.Errorf("sql field: serialize %T: %w", f.V, err)
+	}
+	return data, nil
+}
+
+// Scan implements sql.Scanner
+// SQL NULL resets V to the zero value of T.
+func (f *SQLField[T]) Scan(src any) error {
+	var zero T
+	f.V = zero
+
+	var data []byte
+	switch v := src.(type) {
+	case nil:
+		return nil
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return fmt.Errorf("sql field: unsupported column type %T", src)
+	}
+
+	s, err := f.serializer()
+	if err != nil {
+		return err
+	}
+	if err := s.Deserialize(data, &f.V); err != nil {
+		return fmt.Errorf("sql field: deserialize into %T: %w", f.V, err)
+	}
+	return nil
+}
+
+func (f SQLField[T]) serializer() (Serializer, error) {
+	format := f.Format
+	if format == "" {
+		format = JSON
+	}
+	return DefaultRegistry.New(format)
+}
+
+// isNilValue reports whether v is nil or a nil pointer, map, slice or interface
+func isNilValue(v any) bool {
+	if v == nil {
+		return true
+	}
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Ptr, reflect.Map, reflect.Slice, reflect.Interface:
+		return rv.IsNil()
+	}
+	return false
+}
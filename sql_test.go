@@ -0,0 +1,79 @@
+package serializer_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/MichaelAJay/go-serializer"
+)
+
+type sqlAddress struct {
+	Street string `json:"street" msgpack:"street"`
+	Zip    string `json:"zip" msgpack:"zip"`
+}
+
+func TestSQLFieldRoundTrip(t *testing.T) {
+	formats := append([]serializer.Format{"", serializer.JSON, serializer.Msgpack}, gobFormats()...)
+
+	for _, format := range formats {
+		t.Run(string(format), func(t *testing.T) {
+			in := serializer.NewSQLField(format, sqlAddress{Street: "1 Main St", Zip: "12345"})
+
+			value, err := in.Value()
+			if err != nil {
+				t.Fatalf("Value failed: %v", err)
+			}
+			if _, ok := value.([]byte); !ok {
+				t.Fatalf("expected []byte driver value, got %T", value)
+			}
+
+			out := serializer.SQLField[sqlAddress]{Format: format}
+			if err := out.Scan(value); err != nil {
+				t.Fatalf("Scan failed: %v", err)
+			}
+			if !reflect.DeepEqual(in.V, out.V) {
+				t.Errorf("round trip mismatch: got %+v, want %+v", out.V, in.V)
+			}
+		})
+	}
+}
+
+func TestSQLFieldScanString(t *testing.T) {
+	var field serializer.SQLField[map[string]int]
+	if err := field.Scan(`{"a":1}`); err != nil {
+		t.Fatalf("Scan failed: %v", err)
+	}
+	if field.V["a"] != 1 {
+		t.Errorf("expected a=1, got %v", field.V)
+	}
+}
+
+func TestSQLFieldNull(t *testing.T) {
+	field := serializer.SQLField[*sqlAddress]{V: &sqlAddress{Street: "x"}}
+	if err := field.Scan(nil); err != nil {
+		t.Fatalf("Scan(nil) failed: %v", err)
+	}
+	if field.V != nil {
+		t.Errorf("expected nil after scanning NULL, got %+v", field.V)
+	}
+
+	value, err := field.Value()
+	if err != nil {
+		t.Fatalf("Value failed: %v", err)
+	}
+	if value != nil {
+		t.Errorf("expected NULL driver value, got %v", value)
+	}
+}
+
+func TestSQLFieldErrors(t *testing.T) {
+	var field serializer.SQLField[sqlAddress]
+	if err := field.Scan(42); err == nil {
+		t.Error("expected error scanning unsupported column type")
+	}
+
+	field.Format = "unknown"
+	if _, err := field.Value(); err == nil {
+		t.Error("expected error for unregistered format")
+	}
+}
@@ -0,0 +1,115 @@
+package serializer
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+)
+
+// SSEContentType is the media type of a Server-Sent Events stream
+const SSEContentType = "text/event-stream"
+
+// SSEEncoder writes values as Server-Sent Events, one event per value, so long
+// running result streams reach clients as each element is produced. After every
+// event it calls the flush hook; by default that is w's own Flush method when it
+// has one, which covers http.ResponseWriter and bufio.Writer.
+// An SSEEncoder is not safe for concurrent use.
+type SSEEncoder struct {
+	w     io.Writer
+	s     Serializer
+	flush func() error
+	buf   []byte
+}
+
+// NewSSEEncoder creates an encoder that serializes values with s and writes them to w
+func NewSSEEncoder(w io.Writer, s Serializer) *SSEEncoder {
+	e := &SSEEncoder{w: w, s: s}
+	switch f := w.(type) {
+	case interface{ Flush() error }:
+		e.flush = f.Flush
+	case interface{ Flush() }:
+		e.flush = func() error {
+			f.Flush()
+			return nil
+		}
+	}
+	return e
+}
+
+// OnFlush replaces the hook called after each event is written.
+// A nil fn disables flushing.
+func (e *SSEEncoder) OnFlush(fn func() error) {
+	e.flush = fn
+}
+
+// Encode writes v as an unnamed event
+func (e *SSEEncoder) Encode(v any) error {
+	return e.EncodeEvent("", "", v)
+}
+
+// EncodeEvent writes v as an event with the given event name and id; empty
+// values are left out. The serialized value becomes the event's data, split
+// into one data line per line of output.
+func (e *SSEEncoder) EncodeEvent(event, id string, v any) error {
+	if e.w == nil {
+		return errors.New("writer is nil")
+	}
+	if strings.ContainsAny(event, "\r\n") || strings.ContainsAny(id, "\r\n") {
+		return errors.New("SSE event name and id must not contain line breaks")
+	}
+	data, err := e.s.Serialize(v)
+	if err != nil {
+		return err
+	}
+
+	e.buf = e.buf[:0]
+	if event != "" {
+		e.buf = append(append(append(e.buf, "event: "...), event...), '\n')
+	}
+	if id != "" {
+		e.buf = append(append(append(e.buf, "id: "...), id...), '\n')
+	}
+	e.buf = appendSSELines(e.buf, "data: ", bytes.TrimRight(data, "\r\n"))
+	e.buf = append(e.buf, '\n')
+	return e.write()
+}
+
+// Comment writes an SSE comment line, which clients ignore. Periodic comments
+// keep idle connections from being closed by proxies.
+func (e *SSEEncoder) Comment(text string) error {
+	if e.w == nil {
+		return errors.New("writer is nil")
+	}
+	e.buf = appendSSELines(e.buf[:0], ": ", stringToReadOnlyBytes(text))
+	e.buf = append(e.buf, '\n')
+	return e.write()
+}
+
+// appendSSELines appends a line starting with prefix for each line of text.
+// Lines end at "\r\n", "\r" or "\n", as clients split them, so text cannot
+// start a field of its own.
+func appendSSELines(buf []byte, prefix string, text []byte) []byte {
+	for {
+		i := bytes.IndexAny(text, "\r\n")
+		if i < 0 {
+			return append(append(append(buf, prefix...), text...), '\n')
+		}
+		buf = append(append(append(buf, prefix...), text[:i]...), '\n')
+		if text[i] == '\r' && i+1 < len(text) && text[i+1] == '\n' {
+			i++
+		}
+		text = text[i+1:]
+	}
+}
+
+// write sends the buffered event in a single write and flushes it
+func (e *SSEEncoder) write() error {
+	if _, err := e.w.Write(e.buf); err != nil {
+		return err
+	}
+	if e.flush != nil {
+		return e.flush()
+	}
+	return nil
+}
@@ -0,0 +1,129 @@
+package serializer
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestSSEEncoder(t *testing.T) {
+	rec := httptest.NewRecorder()
+	enc := NewSSEEncoder(rec, NewJSONSerializer(maxBufferSize))
+
+	if err := enc.Encode(map[string]int{"n": 1}); err != nil {
+		t.Fatalf("Encode failed: %v", err)
+	}
+	if !rec.Flushed {
+		t.Error("expected the response to be flushed after the first event")
+	}
+	if err := enc.EncodeEvent("progress", "7", []int{1, 2}); err != nil {
+		t.Fatalf("EncodeEvent failed: %v", err)
+	}
+	if err := enc.Comment("keep-alive"); err != nil {
+		t.Fatalf("Comment failed: %v", err)
+	}
+
+	want := "data: {\"n\":1}\n\n" +
+		"event: progress\nid: 7\ndata: [1,2]\n\n" +
+		": keep-alive\n\n"
+	if got := rec.Body.String(); got != want {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+
+	if err := enc.EncodeEvent("bad\nname", "", 1); err == nil {
+		t.Error("expected error for event name with a line break")
+	}
+}
+
+func TestSSEEncoderMultilineData(t *testing.T) {
+	var buf bytes.Buffer
+	js := NewJSONSerializer(maxBufferSize).(*JSONSerializer)
+	enc := NewSSEEncoder(&buf, indentSerializer{js})
+	if err := enc.Encode(map[string]int{"n": 1}); err != nil {
+		t.Fatalf("Encode failed: %v", err)
+	}
+	if want := "data: {\ndata:   \"n\": 1\ndata: }\n\n"; buf.String() != want {
+		t.Errorf("expected %q, got %q", want, buf.String())
+	}
+}
+
+// Every line break a client recognizes starts a new comment or data line, so
+// text cannot inject fields
+func TestSSEEncoderLineBreaks(t *testing.T) {
+	var buf bytes.Buffer
+	enc := NewSSEEncoder(&buf, NewJSONSerializer(maxBufferSize))
+	for _, text := range []string{"x\rdata: injected", "x\ndata: injected", "x\r\ndata: injected"} {
+		buf.Reset()
+		if err := enc.Comment(text); err != nil {
+			t.Fatalf("Comment failed: %v", err)
+		}
+		if want := ": x\n: data: injected\n\n"; buf.String() != want {
+			t.Errorf("Comment(%q) = %q, want %q", text, buf.String(), want)
+		}
+	}
+
+	buf.Reset()
+	js := NewJSONSerializer(maxBufferSize).(*JSONSerializer)
+	enc = NewSSEEncoder(&buf, rawSerializer{js, "a\rdata: b\r\nc\r\n"})
+	if err := enc.Encode(nil); err != nil {
+		t.Fatalf("Encode failed: %v", err)
+	}
+	if want := "data: a\ndata: data: b\ndata: c\n\n"; buf.String() != want {
+		t.Errorf("expected %q, got %q", want, buf.String())
+	}
+}
+
+func TestSSEEncoderFlushHook(t *testing.T) {
+	var buf bytes.Buffer
+	bw := bufio.NewWriter(&buf)
+	enc := NewSSEEncoder(bw, NewJSONSerializer(maxBufferSize))
+
+	// bufio.Writer's Flush is picked up by default
+	if err := enc.Encode(1); err != nil {
+		t.Fatalf("Encode failed: %v", err)
+	}
+	if buf.String() != "data: 1\n\n" {
+		t.Errorf("expected event to reach the underlying writer, got %q", buf.String())
+	}
+
+	flushes := 0
+	enc.OnFlush(func() error {
+		flushes++
+		return bw.Flush()
+	})
+	for i := 0; i < 3; i++ {
+		if err := enc.Encode(i); err != nil {
+			t.Fatalf("Encode failed: %v", err)
+		}
+	}
+	if flushes != 3 {
+		t.Errorf("expected 3 flushes, got %d", flushes)
+	}
+
+	errStop := errors.New("client gone")
+	enc.OnFlush(func() error { return errStop })
+	if err := enc.Encode(1); !errors.Is(err, errStop) {
+		t.Errorf("expected flush error, got %v", err)
+	}
+}
+
+// indentSerializer produces multi-line JSON
+type indentSerializer struct {
+	*JSONSerializer
+}
+
+func (s indentSerializer) Serialize(v any) ([]byte, error) {
+	return s.SerializeIndent(v, "", "  ")
+}
+
+// rawSerializer produces the same output for every value
+type rawSerializer struct {
+	*JSONSerializer
+	out string
+}
+
+func (s rawSerializer) Serialize(any) ([]byte, error) {
+	return []byte(s.out), nil
+}
@@ -0,0 +1,124 @@
+package serializer
+
+import (
+	"bufio"
+	"errors"
+	"io"
+)
+
+// StreamEncoder writes a sequence of values to one stream with a single encoder,
+// instead of setting one up per value as SerializeTo does.
+// A StreamEncoder is not safe for concurrent use.
+type StreamEncoder interface {
+	// Encode writes v to the stream
+	Encode(v any) error
+	// Close flushes buffered values and releases the encoder. It does not close
+	// the underlying writer.
+	Close() error
+}
+
+// StreamDecoder reads a sequence of values from one stream with a single decoder.
+// A StreamDecoder is not safe for concurrent use.
+type StreamDecoder interface {
+	// Decode reads the next value into v. It returns io.EOF when the stream ends
+	// cleanly between values.
+	Decode(v any) error
+	// More reports whether another value follows. It is false at the end of the
+	// stream and after a read error, which the next Decode returns.
+	More() bool
+	// Close releases the decoder. It does not close the underlying reader.
+	Close() error
+}
+
+// StreamingSerializer is implemented by serializers with their own stream
+// encoding. All built-in formats implement it; wrapping serializers such as
+// NewCompressedSerializer do not.
+type StreamingSerializer interface {
+	Serializer
+	NewStreamEncoder(w io.Writer) StreamEncoder
+	NewStreamDecoder(r io.Reader) StreamDecoder
+}
+
+// NewStreamEncoder returns a stream encoder writing to w with s. Serializers that
+// do not implement StreamingSerializer write each value with SerializeTo.
+func NewStreamEncoder(s Serializer, w io.Writer) StreamEncoder {
+	if ss, ok := s.(StreamingSerializer); ok {
+		return ss.NewStreamEncoder(w)
+	}
+	return &valueStreamEncoder{s: s, w: w}
+}
+
+// NewStreamDecoder returns a stream decoder reading from r with s. Serializers
+// that do not implement StreamingSerializer read each value with
+// DeserializeFrom, which must then stop at the end of the value.
+func NewStreamDecoder(s Serializer, r io.Reader) StreamDecoder {
+	if ss, ok := s.(StreamingSerializer); ok {
+		return ss.NewStreamDecoder(r)
+	}
+	return &valueStreamDecoder{s: s, r: newStreamReader(r)}
+}
+
+// streamReader is a reader that can look one byte ahead
+type streamReader interface {
+	io.Reader
+	io.ByteScanner
+}
+
+// newStreamReader returns r if it can look ahead already, and otherwise r behind
+// a buffer
+func newStreamReader(r io.Reader) streamReader {
+	if r == nil {
+		return nil
+	}
+	if sr, ok := r.(streamReader); ok {
+		return sr
+	}
+	return bufio.NewReader(r)
+}
+
+// peekStream returns nil if r has more input, io.EOF at its end, and otherwise
+// the read error
+func peekStream(r io.ByteScanner) error {
+	if _, err := r.ReadByte(); err != nil {
+		return err
+	}
+	return r.UnreadByte()
+}
+
+// valueStreamEncoder writes each value of a stream with SerializeTo
+type valueStreamEncoder struct {
+	s Serializer
+	w io.Writer
+}
+
+func (e *valueStreamEncoder) Encode(v any) error {
+	return e.s.SerializeTo(e.w, v)
+}
+
+func (e *valueStreamEncoder) Close() error {
+	return nil
+}
+
+// valueStreamDecoder reads each value of a stream with DeserializeFrom
+type valueStreamDecoder struct {
+	s Serializer
+	r streamReader
+}
+
+func (d *valueStreamDecoder) Decode(v any) error {
+	if d.r == nil {
+		return errors.New("reader is nil")
+	}
+	if err := peekStream(d.r); err != nil {
+		return err
+	}
+	return d.s.DeserializeFrom(d.r, v)
+}
+
+func (d *valueStreamDecoder) More() bool {
+	return d.r != nil && peekStream(d.r) == nil
+}
+
+func (d *valueStreamDecoder) Close() error {
+	return nil
+}
@@ -0,0 +1,88 @@
+package serializer
+
+import (
+	"bytes"
+	"io"
+	"testing"
+	"testing/iotest"
+)
+
+// plainSerializer hides the stream methods of the serializer it wraps
+type plainSerializer struct {
+	Serializer
+}
+
+func TestStreamEncoderDecoder(t *testing.T) {
+	serializers := []struct {
+		name string
+		s    Serializer
+	}{
+		{"JSON", NewJSONSerializer(0)},
+		{"JSONNoNewline", NewJSONSerializer(0, WithTrailingNewline(false))},
+		{"MsgPack", NewMsgpackSerializer()},
+		{"CBOR", NewCBORSerializer()},
+		{"BSON", NewBSONSerializer()},
+		{"Fallback", plainSerializer{NewBSONSerializer()}},
+	}
+	for _, s := range gobTestSerializers() {
+		serializers = append(serializers, struct {
+			name string
+			s    Serializer
+		}{"Gob", s})
+	}
+	for _, tc := range serializers {
+		t.Run(tc.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			enc := NewStreamEncoder(tc.s, &buf)
+			for i := 0; i < 3; i++ {
+				if err := enc.Encode(streamEvent{Seq: i, Kind: "created"}); err != nil {
+					t.Fatalf("Encode %d failed: %v", i, err)
+				}
+			}
+			if err := enc.Close(); err != nil {
+				t.Fatalf("Close failed: %v", err)
+			}
+
+			dec := NewStreamDecoder(tc.s, iotest.OneByteReader(bytes.NewReader(buf.Bytes())))
+			defer dec.Close()
+			for i := 0; i < 3; i++ {
+				if !dec.More() {
+					t.Fatalf("More reported the end before event %d", i)
+				}
+				var ev streamEvent
+				if err := dec.Decode(&ev); err != nil || ev.Seq != i || ev.Kind != "created" {
+					t.Fatalf("event %d: got %+v, %v", i, ev, err)
+				}
+			}
+			if dec.More() {
+				t.Error("More reported a value after the last one")
+			}
+			var ev streamEvent
+			if err := dec.Decode(&ev); err != io.EOF {
+				t.Errorf("end of stream: got %v, want io.EOF", err)
+			}
+		})
+	}
+}
+
+func TestStreamDecoderTruncated(t *testing.T) {
+	for _, s := range []Serializer{NewJSONSerializer(0), NewMsgpackSerializer(), NewBSONSerializer()} {
+		var buf bytes.Buffer
+		enc := NewStreamEncoder(s, &buf)
+		for i := 0; i < 2; i++ {
+			if err := enc.Encode(streamEvent{Seq: i, Kind: "created"}); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		dec := NewStreamDecoder(s, bytes.NewReader(buf.Bytes()[:buf.Len()-3]))
+		var ev streamEvent
+		if err := dec.Decode(&ev); err != nil {
+			t.Fatalf("%s: first event: %v", s.ContentType(), err)
+		}
+		if err := dec.Decode(&ev); err == nil || err == io.EOF {
+			t.Errorf("%s: truncated event: got %v, want an error", s.ContentType(), err)
+		}
+		dec.Close()
+	}
+}
@@ -12,11 +12,10 @@ func TestStringDeserializer(t *testing.T) {
 
 	original := testStruct{Name: "test", Value: 42}
 
-	serializers := []Serializer{
+	serializers := append([]Serializer{
 		NewJSONSerializer(maxBufferSize),
 		NewMsgpackSerializer(),
-		NewGobSerializer(),
-	}
+	}, gobTestSerializers()...)
 
 	for _, serializer := range serializers {
 		t.Run(serializer.ContentType(), func(t *testing.T) {
@@ -59,11 +58,10 @@ func TestStringDeserializer(t *testing.T) {
 }
 
 func TestStringDeserializerEdgeCases(t *testing.T) {
-	serializers := []Serializer{
+	serializers := append([]Serializer{
 		NewJSONSerializer(maxBufferSize),
 		NewMsgpackSerializer(),
-		NewGobSerializer(),
-	}
+	}, gobTestSerializers()...)
 
 	for _, serializer := range serializers {
 		t.Run(serializer.ContentType(), func(t *testing.T) {
@@ -0,0 +1,26 @@
+//go:build tinygo
+
+package serializer
+
+// TinyGo builds leave out gob, json-iterator and unsafe. JSON defaults to the
+// lite backend; MessagePack is unchanged.
+
+var json liteAPI
+
+const defaultJSONBackend = JSONBackendLite
+
+var taggedJSONEngines map[JSONBackend]func(o *options) jsonEngine
+
+// liteAPI stands in for the jsoniter API used by the rest of the package
+type liteAPI struct{}
+
+func (liteAPI) Marshal(v any) ([]byte, error) {
+	return liteEngine{}.marshal(v)
+}
+
+func (liteAPI) Unmarshal(data []byte, v any) error {
+	return liteEngine{}.unmarshal(data, v)
+}
+
+// registerDefaultGob is a no-op: gob is not available with TinyGo
+func registerDefaultGob() {}
@@ -0,0 +1,108 @@
+package serializer
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// ErrUnknownType is returned when a type name or Go type has not been registered
+var ErrUnknownType = errors.New("type not registered")
+
+// TypeRegistry maps stable names to Go types, so payloads can carry a type name
+// and readers can decode them into the right concrete type without knowing it in advance.
+// A TypeRegistry is safe for concurrent use.
+type TypeRegistry struct {
+	mu     sync.RWMutex
+	byName map[string]reflect.Type
+	byType map[reflect.Type]string
+}
+
+// DefaultTypeRegistry is the type registry used when none is supplied
+var DefaultTypeRegistry = NewTypeRegistry()
+
+// NewTypeRegistry creates an empty type registry
+func NewTypeRegistry() *TypeRegistry {
+	return &TypeRegistry{
+		byName: make(map[string]reflect.Type),
+		byType: make(map[reflect.Type]string),
+	}
+}
+
+// Register associates name with the type of sample.
+// Registering the same name and type again is a no-op; reusing a name or type
+// for something different is an error.
+func (r *TypeRegistry) Register(name string, sample any) error {
+	if name == "" {
+		return errors.New("type name is empty")
+	}
+	if sample == nil {
+		return errors.New("type sample is nil")
+	}
+	t := reflect.TypeOf(sample)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if existing, ok := r.byName[name]; ok {
+		if existing == t {
+			return nil
+		}
+		return fmt.Errorf("type name %q already registered for %s", name, existing)
+	}
+	if existing, ok := r.byType[t]; ok {
+		return fmt.Errorf("type %s already registered as %q", t, existing)
+	}
+	r.byName[name] = t
+	r.byType[t] = name
+	return nil
+}
+
+// MustRegister is like Register but panics on error
+func (r *TypeRegistry) MustRegister(name string, sample any) {
+	if err := r.Register(name, sample); err != nil {
+		panic(err)
+	}
+}
+
+// TypeOf returns the Go type registered under name
+func (r *TypeRegistry) TypeOf(name string) (reflect.Type, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	t, ok := r.byName[name]
+	return t, ok
+}
+
+// NameOf returns the registered name for the type of v.
+// A pointer to a registered value type resolves to the value type's name.
+func (r *TypeRegistry) NameOf(v any) (string, bool) {
+	if v == nil {
+		return "", false
+	}
+	return r.nameOfType(reflect.TypeOf(v))
+}
+
+// nameOfType is NameOf for a reflect.Type
+func (r *TypeRegistry) nameOfType(t reflect.Type) (string, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if name, ok := r.byType[t]; ok {
+		return name, true
+	}
+	if t.Kind() == reflect.Ptr {
+		if name, ok := r.byType[t.Elem()]; ok {
+			return name, true
+		}
+	}
+	return "", false
+}
+
+// New returns a pointer to a new zero value of the type registered under name
+func (r *TypeRegistry) New(name string) (any, error) {
+	t, ok := r.TypeOf(name)
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownType, name)
+	}
+	return reflect.New(t).Interface(), nil
+}
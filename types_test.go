@@ -0,0 +1,45 @@
+package serializer
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+type registeredWidget struct {
+	Name string
+}
+
+func TestTypeRegistry(t *testing.T) {
+	r := NewTypeRegistry()
+	if err := r.Register("widget", registeredWidget{}); err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+	if err := r.Register("widget", registeredWidget{}); err != nil {
+		t.Errorf("re-registering the same type should succeed, got %v", err)
+	}
+	if err := r.Register("widget", 0); err == nil {
+		t.Error("expected error reusing a name for another type")
+	}
+	if err := r.Register("gadget", registeredWidget{}); err == nil {
+		t.Error("expected error registering a type under a second name")
+	}
+
+	if name, ok := r.NameOf(&registeredWidget{}); !ok || name != "widget" {
+		t.Errorf("NameOf(pointer) = %q, %v", name, ok)
+	}
+	if typ, ok := r.TypeOf("widget"); !ok || typ != reflect.TypeOf(registeredWidget{}) {
+		t.Errorf("TypeOf = %v, %v", typ, ok)
+	}
+
+	v, err := r.New("widget")
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	if _, ok := v.(*registeredWidget); !ok {
+		t.Errorf("New returned %T, want *registeredWidget", v)
+	}
+	if _, err := r.New("missing"); !errors.Is(err, ErrUnknownType) {
+		t.Errorf("expected ErrUnknownType, got %v", err)
+	}
+}
@@ -0,0 +1,369 @@
+package serializer
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"sync"
+)
+
+// Keys of the object that NewTypeTaggedSerializer writes in place of a registered
+// value held in an interface
+const (
+	TypeTagKey   = "$type"
+	TypeValueKey = "$value"
+)
+
+// NewTypeTaggedSerializer wraps s so values of types registered in types keep their
+// concrete type when they are held in an interface, such as the values of a
+// map[string]any or the elements of a []any, at any depth. Each such value is written
+// as {"$type": name, "$value": value} and read back as the registered type instead
+// of as a map. If types is nil, DefaultTypeRegistry is used.
+//
+// It works with any format, since a tagged value is decoded by re-encoding its
+// generic form with s and decoding that into the registered type. Only interfaces
+// without methods are tagged; use WithDiscriminator for JSON interface types. A
+// pointer to a registered type is tagged with the type's name and decodes to a
+// value of the registered type.
+func NewTypeTaggedSerializer(s Serializer, types *TypeRegistry) Serializer {
+	if types == nil {
+		types = DefaultTypeRegistry
+	}
+	return &typeTaggedSerializer{inner: s, types: types}
+}
+
+// typeTaggedSerializer is a Serializer decorator that tags registered values held
+// in interfaces with their type name
+type typeTaggedSerializer struct {
+	inner Serializer
+	types *TypeRegistry
+}
+
+func (s *typeTaggedSerializer) Serialize(v any) ([]byte, error) {
+	tagged, err := s.tag(v)
+	if err != nil {
+		return nil, err
+	}
+	return s.inner.Serialize(tagged)
+}
+
+func (s *typeTaggedSerializer) SerializeTo(w io.Writer, v any) error {
+	tagged, err := s.tag(v)
+	if err != nil {
+		return err
+	}
+	return s.inner.SerializeTo(w, tagged)
+}
+
+func (s *typeTaggedSerializer) Deserialize(data []byte, v any) error {
+	if err := s.inner.Deserialize(data, v); err != nil {
+		return err
+	}
+	return s.untag(v)
+}
+
+func (s *typeTaggedSerializer) DeserializeFrom(r io.Reader, v any) error {
+	if err := s.inner.DeserializeFrom(r, v); err != nil {
+		return err
+	}
+	return s.untag(v)
+}
+
+func (s *typeTaggedSerializer) ContentType() string {
+	return s.inner.ContentType()
+}
+
+// tag returns v, or a copy of it in which registered values held in interfaces
+// are replaced by tagged objects. v itself is never modified.
+func (s *typeTaggedSerializer) tag(v any) (any, error) {
+	if v == nil {
+		return nil, errors.New("cannot serialize nil value")
+	}
+	rv, changed, err := s.tagValue(reflect.ValueOf(v), 0)
+	if err != nil || !changed {
+		return v, err
+	}
+	return rv.Interface(), nil
+}
+
+// maxTagDepth stops tagging cyclic values, which no format can encode anyway
+const maxTagDepth = 1000
+
+// tagValue returns v with registered interface values tagged, and whether
+// anything changed. Unchanged containers are returned as they are; changed ones
+// are shallow copies.
+func (s *typeTaggedSerializer) tagValue(v reflect.Value, depth int) (reflect.Value, bool, error) {
+	if !mayHoldAny(v.Type()) {
+		return v, false, nil
+	}
+	if depth > maxTagDepth {
+		return v, false, fmt.Errorf("type tagging exceeded depth %d; is the value cyclic?", maxTagDepth)
+	}
+
+	switch v.Kind() {
+	case reflect.Interface:
+		if v.IsNil() {
+			return v, false, nil
+		}
+		elem := v.Elem()
+		inner, changed, err := s.tagValue(elem, depth+1)
+		if err != nil {
+			return v, false, err
+		}
+		name, registered := s.types.nameOfType(elem.Type())
+		if !registered && !changed {
+			return v, false, nil
+		}
+		out := reflect.New(v.Type()).Elem()
+		if registered {
+			out.Set(reflect.ValueOf(map[string]any{TypeTagKey: name, TypeValueKey: inner.Interface()}))
+		} else {
+			out.Set(inner)
+		}
+		return out, true, nil
+
+	case reflect.Ptr:
+		if v.IsNil() {
+			return v, false, nil
+		}
+		elem, changed, err := s.tagValue(v.Elem(), depth+1)
+		if err != nil || !changed {
+			return v, false, err
+		}
+		out := reflect.New(elem.Type())
+		out.Elem().Set(elem)
+		return out, true, nil
+
+	case reflect.Struct:
+		var out reflect.Value
+		for i := 0; i < v.NumField(); i++ {
+			if !v.Type().Field(i).IsExported() {
+				continue
+			}
+			field, changed, err := s.tagValue(v.Field(i), depth+1)
+			if err != nil {
+				return v, false, err
+			}
+			if changed {
+				if !out.IsValid() {
+					out = reflect.New(v.Type()).Elem()
+					out.Set(v)
+				}
+				out.Field(i).Set(field)
+			}
+		}
+		if !out.IsValid() {
+			return v, false, nil
+		}
+		return out, true, nil
+
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			return v, false, nil
+		}
+		var out reflect.Value
+		for i := 0; i < v.Len(); i++ {
+			elem, changed, err := s.tagValue(v.Index(i), depth+1)
+			if err != nil {
+				return v, false, err
+			}
+			if changed {
+				if !out.IsValid() {
+					if v.Kind() == reflect.Slice {
+						out = reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+						reflect.Copy(out, v)
+					} else {
+						out = reflect.New(v.Type()).Elem()
+						out.Set(v)
+					}
+				}
+				out.Index(i).Set(elem)
+			}
+		}
+		if !out.IsValid() {
+			return v, false, nil
+		}
+		return out, true, nil
+
+	case reflect.Map:
+		if v.IsNil() {
+			return v, false, nil
+		}
+		var out reflect.Value
+		iter := v.MapRange()
+		for iter.Next() {
+			elem, changed, err := s.tagValue(iter.Value(), depth+1)
+			if err != nil {
+				return v, false, err
+			}
+			if changed {
+				if !out.IsValid() {
+					out = reflect.MakeMapWithSize(v.Type(), v.Len())
+					copyIter := v.MapRange()
+					for copyIter.Next() {
+						out.SetMapIndex(copyIter.Key(), copyIter.Value())
+					}
+				}
+				out.SetMapIndex(iter.Key(), elem)
+			}
+		}
+		if !out.IsValid() {
+			return v, false, nil
+		}
+		return out, true, nil
+	}
+	return v, false, nil
+}
+
+// untag replaces tagged objects reachable from the decoded value v with values of
+// their registered types
+func (s *typeTaggedSerializer) untag(v any) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return nil
+	}
+	return s.untagValue(rv.Elem(), 0)
+}
+
+// untagValue resolves tagged objects within v, which must be settable
+func (s *typeTaggedSerializer) untagValue(v reflect.Value, depth int) error {
+	if !mayHoldAny(v.Type()) {
+		return nil
+	}
+	if depth > maxTagDepth {
+		return fmt.Errorf("type tag resolution exceeded depth %d", maxTagDepth)
+	}
+
+	switch v.Kind() {
+	case reflect.Interface:
+		if v.IsNil() {
+			return nil
+		}
+		if m, ok := v.Elem().Interface().(map[string]any); ok {
+			if name, payload, ok := typeTag(m); ok {
+				resolved, err := s.resolveTag(name, payload, depth)
+				if err != nil {
+					return err
+				}
+				if !resolved.Type().AssignableTo(v.Type()) {
+					return fmt.Errorf("type %q (%s) cannot be stored in %s", name, resolved.Type(), v.Type())
+				}
+				v.Set(resolved)
+				return nil
+			}
+		}
+		// Copy the held value out so it is settable, then put it back
+		elem := reflect.New(v.Elem().Type()).Elem()
+		elem.Set(v.Elem())
+		if err := s.untagValue(elem, depth+1); err != nil {
+			return err
+		}
+		v.Set(elem)
+
+	case reflect.Ptr:
+		if !v.IsNil() {
+			return s.untagValue(v.Elem(), depth+1)
+		}
+
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			if v.Type().Field(i).IsExported() {
+				if err := s.untagValue(v.Field(i), depth+1); err != nil {
+					return err
+				}
+			}
+		}
+
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			if err := s.untagValue(v.Index(i), depth+1); err != nil {
+				return err
+			}
+		}
+
+	case reflect.Map:
+		iter := v.MapRange()
+		for iter.Next() {
+			elem := reflect.New(v.Type().Elem()).Elem()
+			elem.Set(iter.Value())
+			if err := s.untagValue(elem, depth+1); err != nil {
+				return err
+			}
+			v.SetMapIndex(iter.Key(), elem)
+		}
+	}
+	return nil
+}
+
+// resolveTag decodes the generic payload of a tagged object into the type registered as name
+func (s *typeTaggedSerializer) resolveTag(name string, payload any, depth int) (reflect.Value, error) {
+	target, err := s.types.New(name)
+	if err != nil {
+		return reflect.Value{}, err
+	}
+	data, err := s.inner.Serialize(payload)
+	if err != nil {
+		return reflect.Value{}, fmt.Errorf("type %q: %w", name, err)
+	}
+	if err := s.inner.Deserialize(data, target); err != nil {
+		return reflect.Value{}, fmt.Errorf("type %q: %w", name, err)
+	}
+	resolved := reflect.ValueOf(target).Elem()
+	if err := s.untagValue(resolved, depth+1); err != nil {
+		return reflect.Value{}, err
+	}
+	return resolved, nil
+}
+
+// typeTag reports whether m is a tagged object and returns its parts
+func typeTag(m map[string]any) (string, any, bool) {
+	if len(m) != 2 {
+		return "", nil, false
+	}
+	name, ok := m[TypeTagKey].(string)
+	if !ok {
+		return "", nil, false
+	}
+	payload, ok := m[TypeValueKey]
+	return name, payload, ok
+}
+
+// anyHolders caches whether values of a type can hold an empty interface
+var anyHolders sync.Map // reflect.Type -> bool
+
+// mayHoldAny reports whether a value of type t can contain an interface without
+// methods, which is where tagged values live
+func mayHoldAny(t reflect.Type) bool {
+	if held, ok := anyHolders.Load(t); ok {
+		return held.(bool)
+	}
+	held := holdsAny(t, make(map[reflect.Type]bool))
+	anyHolders.Store(t, held)
+	return held
+}
+
+func holdsAny(t reflect.Type, visiting map[reflect.Type]bool) bool {
+	if visiting[t] {
+		// A recursive type holds an interface only through its other parts
+		return false
+	}
+	visiting[t] = true
+	defer delete(visiting, t)
+
+	switch t.Kind() {
+	case reflect.Interface:
+		return t.NumMethod() == 0
+	case reflect.Ptr, reflect.Slice, reflect.Array:
+		return holdsAny(t.Elem(), visiting)
+	case reflect.Map:
+		return holdsAny(t.Elem(), visiting)
+	case reflect.Struct:
+		for i := 0; i < t.NumField(); i++ {
+			if t.Field(i).IsExported() && holdsAny(t.Field(i).Type, visiting) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,156 @@
+package serializer
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type httpPluginConfig struct {
+	URL     string         `json:"url" msgpack:"url"`
+	Timeout time.Duration  `json:"timeout" msgpack:"timeout"`
+	Headers []string       `json:"headers" msgpack:"headers"`
+	Extra   map[string]any `json:"extra" msgpack:"extra"`
+}
+
+type retryPluginConfig struct {
+	Attempts int `json:"attempts" msgpack:"attempts"`
+}
+
+type pluginSettings struct {
+	Name    string         `json:"name" msgpack:"name"`
+	Plugins map[string]any `json:"plugins" msgpack:"plugins"`
+	Chain   []any          `json:"chain" msgpack:"chain"`
+}
+
+func pluginTypes() *TypeRegistry {
+	types := NewTypeRegistry()
+	types.MustRegister("plugin.http", httpPluginConfig{})
+	types.MustRegister("plugin.retry", retryPluginConfig{})
+	return types
+}
+
+func TestTypeTaggedSerializerRoundTrip(t *testing.T) {
+	settings := pluginSettings{
+		Name: "edge",
+		Plugins: map[string]any{
+			"http": httpPluginConfig{
+				URL:     "https://example.com",
+				Timeout: 3 * time.Second,
+				Headers: []string{"a"},
+				Extra:   map[string]any{"retry": &retryPluginConfig{Attempts: 2}},
+			},
+			"enabled": true,
+		},
+		Chain: []any{retryPluginConfig{Attempts: 5}, "plain"},
+	}
+	want := settings
+	want.Plugins = map[string]any{
+		"http": httpPluginConfig{
+			URL:     "https://example.com",
+			Timeout: 3 * time.Second,
+			Headers: []string{"a"},
+			// Pointers decode to values of the registered type
+			Extra: map[string]any{"retry": retryPluginConfig{Attempts: 2}},
+		},
+		"enabled": true,
+	}
+
+	for _, inner := range []Serializer{NewJSONSerializer(0), NewMsgpackSerializer()} {
+		t.Run(inner.ContentType(), func(t *testing.T) {
+			s := NewTypeTaggedSerializer(inner, pluginTypes())
+			data, err := s.Serialize(settings)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var got pluginSettings
+			if err := s.Deserialize(data, &got); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("got %#v\nwant %#v", got, want)
+			}
+
+			var generic any
+			if err := s.DeserializeFrom(bytes.NewReader(data), &generic); err != nil {
+				t.Fatal(err)
+			}
+			chain := generic.(map[string]any)["chain"].([]any)
+			if chain[0] != (retryPluginConfig{Attempts: 5}) {
+				t.Errorf("chain[0] = %#v", chain[0])
+			}
+
+			// Without the wrapper the tags are plain objects
+			var raw map[string]any
+			if err := inner.Deserialize(data, &raw); err != nil {
+				t.Fatal(err)
+			}
+			tag := raw["chain"].([]any)[0].(map[string]any)
+			if tag[TypeTagKey] != "plugin.retry" {
+				t.Errorf("unexpected tag object %#v", tag)
+			}
+		})
+	}
+
+	// The original value is left untouched
+	if _, ok := settings.Plugins["http"].(httpPluginConfig); !ok {
+		t.Error("Serialize modified its input")
+	}
+}
+
+func TestTypeTaggedSerializerErrors(t *testing.T) {
+	s := NewTypeTaggedSerializer(NewJSONSerializer(0), pluginTypes())
+
+	var v map[string]any
+	err := s.Deserialize([]byte(`{"x":{"$type":"plugin.missing","$value":{}}}`), &v)
+	if err == nil || !strings.Contains(err.Error(), "plugin.missing") {
+		t.Errorf("unknown type: got %v", err)
+	}
+	err = s.Deserialize([]byte(`{"x":{"$type":"plugin.retry","$value":{"attempts":"many"}}}`), &v)
+	if err == nil || !strings.Contains(err.Error(), "plugin.retry") {
+		t.Errorf("invalid payload: got %v", err)
+	}
+
+	// Objects that merely contain a $type key are left alone
+	if err := s.Deserialize([]byte(`{"x":{"$type":"plugin.retry","other":1}}`), &v); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := v["x"].(map[string]any); !ok {
+		t.Errorf("non-tag object was converted: %#v", v["x"])
+	}
+
+	if _, err := s.Serialize(nil); err == nil {
+		t.Error("nil value was accepted")
+	}
+}
+
+func TestMayHoldAny(t *testing.T) {
+	type node struct {
+		Next *node
+		Val  int
+	}
+	type anyNode struct {
+		Next *anyNode
+		Val  any
+	}
+	cases := map[reflect.Type]bool{
+		reflect.TypeFor[int]():                  false,
+		reflect.TypeFor[[]string]():             false,
+		reflect.TypeFor[node]():                 false,
+		reflect.TypeFor[map[string]any]():       true,
+		reflect.TypeFor[*anyNode]():             true,
+		reflect.TypeFor[pluginSettings]():       true,
+		reflect.TypeFor[retryPluginConfig]():    false,
+		reflect.TypeFor[interface{ M() }]():     false,
+		reflect.TypeFor[[2]map[int][]any]():     true,
+		reflect.TypeFor[struct{ hidden any }](): false,
+	}
+	for typ, want := range cases {
+		if got := mayHoldAny(typ); got != want {
+			t.Errorf("mayHoldAny(%s) = %t, want %t", typ, got, want)
+		}
+	}
+}
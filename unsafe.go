@@ -1,4 +1,4 @@
-//go:build go1.20
+//go:build go1.20 && !tinygo && !nounsafe
 
 package serializer
 
@@ -24,4 +24,13 @@ func stringToReadOnlyBytes(s string) []byte {
 	// unsafe.StringData returns a pointer to the underlying string data
 	// unsafe.Slice creates a slice from the pointer with the specified length
 	return unsafe.Slice(unsafe.StringData(s), len(s))
-}
\ No newline at end of file
+}
+
+// bytesToString converts b to a string that shares its memory, avoiding the copy
+// that string(b) makes. b MUST NOT be modified while the string is in use.
+func bytesToString(b []byte) string {
+	if len(b) == 0 {
+		return ""
+	}
+	return unsafe.String(unsafe.SliceData(b), len(b))
+}
@@ -0,0 +1,124 @@
+package serializer
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// Validator checks values after they are deserialized. Validate receives the
+// pointer that was passed to Deserialize and returns nil if the value is acceptable.
+type Validator interface {
+	Validate(v any) error
+}
+
+// ValidatorFunc adapts a function to a Validator, such as one checking values
+// against a JSON Schema document with a schema library
+type ValidatorFunc func(v any) error
+
+// Validate calls f(v)
+func (f ValidatorFunc) Validate(v any) error {
+	return f(v)
+}
+
+// SelfValidator is implemented by values that check their own invariants, such as
+// *CloudEvent
+type SelfValidator interface {
+	Validate() error
+}
+
+// WithValidation checks every value once it has been deserialized. Values that
+// implement SelfValidator are checked with their Validate method, then v, if it
+// is not nil, checks the value as well. Deserialization fails with a
+// *ValidationError holding what they reported; the value is left decoded.
+//
+// All built-in serializers that take options support it, for each call of
+// Deserialize, DeserializeString and DeserializeFrom and for each value read by
+// their stream decoders. NDJSON validates each record.
+func WithValidation(v Validator) Option {
+	return func(o *options) {
+		o.validation = true
+		o.validator = v
+	}
+}
+
+// ValidationError is returned when a deserialized value fails validation
+type ValidationError struct {
+	// Type is the type of the value, without the pointer it was decoded through
+	Type reflect.Type
+	// Err holds the errors the value's Validate method and the Validator returned
+	Err error
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("validation failed for %v: %v", e.Type, e.Err)
+}
+
+func (e *ValidationError) Unwrap() error {
+	return e.Err
+}
+
+// Fields returns every FieldError in Err, including those combined with
+// errors.Join or wrapped with %w, in the order they were reported
+func (e *ValidationError) Fields() []*FieldError {
+	var fields []*FieldError
+	var walk func(err error)
+	walk = func(err error) {
+		switch err := err.(type) {
+		case *FieldError:
+			fields = append(fields, err)
+		case interface{ Unwrap() []error }:
+			for _, inner := range err.Unwrap() {
+				walk(inner)
+			}
+		case interface{ Unwrap() error }:
+			walk(err.Unwrap())
+		}
+	}
+	walk(e.Err)
+	return fields
+}
+
+// FieldError reports an invalid field. Validators return one per field, combined
+// with errors.Join, so callers can map failures back to their input.
+type FieldError struct {
+	// Field is the path to the field, such as "address.zip" or "items[2]"
+	Field   string
+	Message string
+}
+
+func (e *FieldError) Error() string {
+	return e.Field + ": " + e.Message
+}
+
+// validate checks a deserialized value as WithValidation configures
+func (o *options) validate(v any) error {
+	if !o.validation {
+		return nil
+	}
+	var errs []error
+	if sv, ok := v.(SelfValidator); ok {
+		if err := sv.Validate(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if o.validator != nil {
+		if err := o.validator.Validate(v); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	var err error
+	switch len(errs) {
+	case 0:
+		return nil
+	case 1:
+		err = errs[0]
+	default:
+		err = errors.Join(errs...)
+	}
+	t := reflect.TypeOf(v)
+	if t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return &ValidationError{Type: t, Err: err}
+}
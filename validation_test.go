@@ -0,0 +1,115 @@
+package serializer
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type signup struct {
+	Email string `json:"email" msgpack:"email" bson:"email"`
+	Age   int    `json:"age" msgpack:"age" bson:"age"`
+}
+
+func (s *signup) Validate() error {
+	var errs []error
+	if !strings.Contains(s.Email, "@") {
+		errs = append(errs, &FieldError{Field: "email", Message: "must be an email address"})
+	}
+	if s.Age < 0 {
+		errs = append(errs, &FieldError{Field: "age", Message: "must not be negative"})
+	}
+	return errors.Join(errs...)
+}
+
+func TestWithValidation(t *testing.T) {
+	bad := signup{Email: "nobody", Age: -1}
+	serializers := []Serializer{
+		NewJSONSerializer(0, WithValidation(nil)),
+		NewMsgpackSerializer(WithValidation(nil)),
+		NewCBORSerializer(WithValidation(nil)),
+		NewBSONSerializer(WithValidation(nil)),
+	}
+	for _, newGob := range gobTestConstructors() {
+		serializers = append(serializers, newGob(WithValidation(nil)))
+	}
+	for _, s := range serializers {
+		name := s.ContentType()
+		data, err := s.Serialize(bad)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+
+		var got signup
+		err = s.Deserialize(data, &got)
+		var verr *ValidationError
+		if !errors.As(err, &verr) {
+			t.Fatalf("%s: Deserialize = %v, want a *ValidationError", name, err)
+		}
+		if verr.Type != reflect.TypeFor[signup]() || got != bad {
+			t.Errorf("%s: Type = %v, value = %+v", name, verr.Type, got)
+		}
+		if fields := verr.Fields(); len(fields) != 2 || fields[0].Field != "email" || fields[1].Field != "age" {
+			t.Errorf("%s: Fields = %v", name, fields)
+		}
+
+		if err := s.DeserializeFrom(bytes.NewReader(data), &got); !errors.As(err, &verr) {
+			t.Errorf("%s: DeserializeFrom = %v, want a *ValidationError", name, err)
+		}
+		if sd, ok := s.(StringDeserializer); ok {
+			if err := sd.DeserializeString(string(data), &got); !errors.As(err, &verr) {
+				t.Errorf("%s: DeserializeString = %v, want a *ValidationError", name, err)
+			}
+		}
+	}
+
+	// Without the option values are not checked
+	data, _ := NewJSONSerializer(0).Serialize(bad)
+	var got signup
+	if err := NewJSONSerializer(0).Deserialize(data, &got); err != nil {
+		t.Errorf("Deserialize without WithValidation = %v", err)
+	}
+}
+
+func TestWithValidationValidator(t *testing.T) {
+	errTooLarge := errors.New("too many keys")
+	v := ValidatorFunc(func(v any) error {
+		if m, ok := v.(*map[string]int); ok && len(*m) > 1 {
+			return errTooLarge
+		}
+		return nil
+	})
+	s := NewMsgpackSerializer(WithValidation(v))
+	data, _ := s.Serialize(map[string]int{"a": 1, "b": 2})
+
+	var m map[string]int
+	if err := s.Deserialize(data, &m); !errors.Is(err, errTooLarge) {
+		t.Errorf("Deserialize = %v, want errTooLarge", err)
+	}
+	data, _ = s.Serialize(map[string]int{"a": 1})
+	m = nil
+	if err := s.Deserialize(data, &m); err != nil {
+		t.Errorf("valid value failed: %v", err)
+	}
+
+	// A value's own Validate method runs first, and both errors are kept
+	js := NewJSONSerializer(0, WithValidation(ValidatorFunc(func(any) error { return errTooLarge })))
+	var got signup
+	err := js.Deserialize([]byte(`{"email":"x","age":1}`), &got)
+	var verr *ValidationError
+	if !errors.As(err, &verr) || !errors.Is(err, errTooLarge) || len(verr.Fields()) != 1 {
+		t.Errorf("Deserialize = %v", err)
+	}
+}
+
+func TestWithValidationNDJSON(t *testing.T) {
+	s := NewNDJSONSerializer(0, WithValidation(nil))
+	var got []signup
+	err := s.Deserialize([]byte("{\"email\":\"a@b\"}\n{\"email\":\"b\"}\n"), &got)
+	var verr *ValidationError
+	if !errors.As(err, &verr) || !strings.Contains(err.Error(), "record 1") {
+		t.Errorf("Deserialize = %v, want a *ValidationError for record 1", err)
+	}
+}
@@ -0,0 +1,90 @@
+package serializer
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// WebSocket data frame opcodes as defined by RFC 6455.
+// They match the TextMessage and BinaryMessage constants of gorilla/websocket.
+const (
+	WebSocketTextMessage   = 1
+	WebSocketBinaryMessage = 2
+)
+
+// WebSocketConn is the subset of a websocket connection used by WebSocketCodec.
+// *websocket.Conn from gorilla/websocket satisfies it, and other libraries can be
+// adapted with a small wrapper.
+type WebSocketConn interface {
+	WriteMessage(messageType int, data []byte) error
+	ReadMessage() (messageType int, data []byte, err error)
+}
+
+// WebSocketCodec encodes and decodes websocket messages with a serializer.
+// Text formats such as JSON are sent as text messages and everything else as
+// binary messages. The codec keeps a per-connection buffer so steady-state
+// writes do not allocate a new payload slice for every message.
+//
+// Encode is safe for concurrent use; Decode must only be called from one goroutine,
+// matching the concurrency rules of typical websocket libraries.
+type WebSocketCodec struct {
+	conn        WebSocketConn
+	s           Serializer
+	messageType int
+
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+// NewWebSocketCodec creates a codec that sends and receives values on conn using s
+func NewWebSocketCodec(conn WebSocketConn, s Serializer) *WebSocketCodec {
+	return &WebSocketCodec{
+		conn:        conn,
+		s:           s,
+		messageType: websocketMessageType(s.ContentType()),
+	}
+}
+
+// MessageType returns the websocket opcode used for outgoing messages
+func (c *WebSocketCodec) MessageType() int {
+	return c.messageType
+}
+
+// Encode serializes v and sends it as a single websocket message
+func (c *WebSocketCodec) Encode(v any) error {
+	if v == nil {
+		return errors.New("cannot serialize nil value")
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.buf.Reset()
+	if err := c.s.SerializeTo(&c.buf, v); err != nil {
+		return err
+	}
+	return c.conn.WriteMessage(c.messageType, c.buf.Bytes())
+}
+
+// Decode reads the next websocket message and deserializes it into v
+func (c *WebSocketCodec) Decode(v any) error {
+	messageType, data, err := c.conn.ReadMessage()
+	if err != nil {
+		return err
+	}
+	if messageType != WebSocketTextMessage && messageType != WebSocketBinaryMessage {
+		return fmt.Errorf("unsupported websocket message type %d", messageType)
+	}
+	return c.s.Deserialize(data, v)
+}
+
+// websocketMessageType picks the websocket opcode for a serializer content type
+func websocketMessageType(contentType string) int {
+	if strings.Contains(contentType, "json") || strings.HasPrefix(contentType, "text/") {
+		return WebSocketTextMessage
+	}
+	return WebSocketBinaryMessage
+}
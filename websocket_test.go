@@ -0,0 +1,82 @@
+package serializer
+
+import (
+	"testing"
+)
+
+// fakeWebSocketConn records written messages and replays them on read
+type fakeWebSocketConn struct {
+	types    []int
+	messages [][]byte
+}
+
+func (c *fakeWebSocketConn) WriteMessage(messageType int, data []byte) error {
+	c.types = append(c.types, messageType)
+	c.messages = append(c.messages, append([]byte(nil), data...))
+	return nil
+}
+
+func (c *fakeWebSocketConn) ReadMessage() (int, []byte, error) {
+	messageType, data := c.types[0], c.messages[0]
+	c.types, c.messages = c.types[1:], c.messages[1:]
+	return messageType, data, nil
+}
+
+func TestWebSocketCodecRoundTrip(t *testing.T) {
+	type event struct {
+		Name  string `json:"name" msgpack:"name"`
+		Count int    `json:"count" msgpack:"count"`
+	}
+
+	tests := []struct {
+		serializer  Serializer
+		messageType int
+	}{
+		{NewJSONSerializer(maxBufferSize), WebSocketTextMessage},
+		{NewMsgpackSerializer(), WebSocketBinaryMessage},
+	}
+	for _, s := range gobTestSerializers() {
+		tests = append(tests, struct {
+			serializer  Serializer
+			messageType int
+		}{s, WebSocketBinaryMessage})
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.serializer.ContentType(), func(t *testing.T) {
+			conn := &fakeWebSocketConn{}
+			codec := NewWebSocketCodec(conn, tt.serializer)
+
+			for i := 0; i < 2; i++ {
+				if err := codec.Encode(event{Name: "tick", Count: i}); err != nil {
+					t.Fatalf("Encode failed: %v", err)
+				}
+			}
+			for _, mt := range conn.types {
+				if mt != tt.messageType {
+					t.Errorf("expected message type %d, got %d", tt.messageType, mt)
+				}
+			}
+
+			for i := 0; i < 2; i++ {
+				var got event
+				if err := codec.Decode(&got); err != nil {
+					t.Fatalf("Decode failed: %v", err)
+				}
+				if got.Name != "tick" || got.Count != i {
+					t.Errorf("message %d mismatch: %+v", i, got)
+				}
+			}
+		})
+	}
+}
+
+func TestWebSocketCodecRejectsControlFrames(t *testing.T) {
+	conn := &fakeWebSocketConn{types: []int{8}, messages: [][]byte{nil}}
+	codec := NewWebSocketCodec(conn, NewMsgpackSerializer())
+
+	var v any
+	if err := codec.Decode(&v); err == nil {
+		t.Error("expected error for non-data websocket message")
+	}
+}
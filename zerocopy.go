@@ -0,0 +1,77 @@
+package serializer
+
+import "errors"
+
+// Errors returned by View accessors
+var (
+	// ErrViewType is returned when a value is read as a kind it does not have
+	ErrViewType = errors.New("view: value has a different kind")
+	// ErrViewNotFound is returned for map keys and array indexes that do not exist
+	ErrViewNotFound = errors.New("view: no such element")
+)
+
+// ViewKind is the kind of value a View points at
+type ViewKind int
+
+const (
+	ViewInvalid ViewKind = iota
+	ViewNil
+	ViewBool
+	ViewInt
+	ViewUint
+	ViewFloat
+	ViewString
+	ViewBytes
+	ViewArray
+	ViewMap
+	ViewExt
+)
+
+var viewKindNames = [...]string{"invalid", "nil", "bool", "int", "uint", "float", "string", "bytes", "array", "map", "ext"}
+
+func (k ViewKind) String() string {
+	if k < 0 || int(k) >= len(viewKindNames) {
+		return "invalid"
+	}
+	return viewKindNames[k]
+}
+
+// View reads one encoded value in place, without decoding the document around it.
+// Map and array elements are found by walking the encoding, and strings and byte
+// slices are returned as views into it rather than copies.
+//
+// Everything a View returns shares memory with the data it was created from: it
+// is valid only while that data is unchanged, must not be modified, and keeps the
+// whole input reachable for the garbage collector. Copy what must outlive it.
+type View interface {
+	// Kind reports the kind of the value
+	Kind() ViewKind
+	// Field returns the value stored under key in a map with string keys
+	Field(key string) (View, error)
+	// Index returns element i of an array
+	Index(i int) (View, error)
+	// Len returns the number of elements of an array, entries of a map, or bytes
+	// of a string or byte slice
+	Len() (int, error)
+	// Str returns a string value, sharing memory with the input
+	Str() (string, error)
+	// Bytes returns a byte slice value, sharing memory with the input
+	Bytes() ([]byte, error)
+	// Int returns an integer value that fits in an int64
+	Int() (int64, error)
+	// Float returns a floating-point or integer value as a float64
+	Float() (float64, error)
+	// Bool returns a boolean value
+	Bool() (bool, error)
+	// Raw returns the encoding of the value
+	Raw() ([]byte, error)
+	// Decode decodes the value into v, copying as Deserialize does
+	Decode(v any) error
+}
+
+// ZeroCopyDeserializer is implemented by serializers that can read values in
+// place, for read-heavy caches that look at a few fields of large payloads
+type ZeroCopyDeserializer interface {
+	// View returns a View of the value encoded in data
+	View(data []byte) (View, error)
+}